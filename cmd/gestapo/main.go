@@ -0,0 +1,16 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/akmal4410/gestapo/pkg/grpc_api/all_in_one"
+)
+
+func main() {
+	err := all_in_one.RunServer()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintf(os.Stderr, "usage: %s %s|%s\n", os.Args[0], product_service.StatsBackfill, product_service.StatsCheck)
+		os.Exit(2)
+	}
+	err := product_service.RunStats(os.Args[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
@@ -1,8 +1,9 @@
 module github.com/akmal4410/gestapo
 
-go 1.20
+go 1.22.2
 
 require (
+	github.com/HugoSmits86/nativewebp v0.9.3
 	github.com/aws/aws-sdk-go v1.50.34
 	github.com/go-playground/validator/v10 v10.18.0
 	github.com/golang-jwt/jwt v3.2.2+incompatible
@@ -19,6 +20,7 @@ require (
 	github.com/spf13/viper v1.18.2
 	github.com/twilio/twilio-go v1.18.0
 	golang.org/x/crypto v0.19.0
+	golang.org/x/image v0.18.0
 	google.golang.org/api v0.167.0
 	google.golang.org/genproto/googleapis/api v0.0.0-20240125205218-1f4bbc51befe
 	google.golang.org/grpc v1.61.1
@@ -75,9 +77,9 @@ require (
 	golang.org/x/exp v0.0.0-20240213143201-ec583247a57a // indirect
 	golang.org/x/net v0.21.0 // indirect
 	golang.org/x/oauth2 v0.17.0 // indirect
-	golang.org/x/sync v0.6.0 // indirect
+	golang.org/x/sync v0.7.0 // indirect
 	golang.org/x/sys v0.17.0 // indirect
-	golang.org/x/text v0.14.0 // indirect
+	golang.org/x/text v0.16.0 // indirect
 	google.golang.org/appengine v1.6.8 // indirect
 	google.golang.org/genproto v0.0.0-20240205150955-31a09d347014 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20240213162025-012b6fc9bca9 // indirect
@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
@@ -19,6 +20,12 @@ type Config struct {
 	Redis             *Redis         `mapstructure:"REDIS_SERVER" json:"REDIS_SERVER"`
 	OAuth             *OAuth         `mapstructure:"OAUTH" json:"OAUTH"`
 	AwsS3             *AWSS3         `mapstructure:"AWSS3" json:"AWSS3"`
+	Storage           *Storage       `mapstructure:"STORAGE" json:"STORAGE"`
+	GRPCClient        *GRPCClient    `mapstructure:"GRPC_CLIENT" json:"GRPC_CLIENT"`
+	AllInOne          *AllInOne      `mapstructure:"ALL_IN_ONE" json:"ALL_IN_ONE"`
+	Moderation        *Moderation    `mapstructure:"MODERATION" json:"MODERATION"`
+	PriceAlerts       *PriceAlerts   `mapstructure:"PRICE_ALERTS" json:"PRICE_ALERTS"`
+	StockAlerts       *StockAlerts   `mapstructure:"STOCK_ALERTS" json:"STOCK_ALERTS"`
 }
 
 type ServerAddress struct {
@@ -39,6 +46,10 @@ type Address struct {
 type Database struct {
 	DBDriver string `mapstructure:"DB_DRIVER" json:"DB_DRIVER"`
 	DBSource string `mapstructure:"DB_SOURCE" json:"DB_SOURCE"`
+	// StatementTimeout aborts any statement running longer than it, 0 disables the timeout.
+	StatementTimeout time.Duration `mapstructure:"STATEMENT_TIMEOUT" json:"STATEMENT_TIMEOUT"`
+	// StatementTimeouts overrides StatementTimeout per service, keyed by the service log file name (eg: user_service).
+	StatementTimeouts map[string]time.Duration `mapstructure:"STATEMENT_TIMEOUTS" json:"STATEMENT_TIMEOUTS"`
 }
 
 type Twilio struct {
@@ -73,6 +84,85 @@ type AWSS3 struct {
 	SecretKey  string `mapstructure:"SECRET_KEY" json:"SECRET_KEY"`
 }
 
+// GRPCClient configures the connections used to call the other services.
+// Zero values fall back to the defaults of service_helper.ClientRegistry.
+type GRPCClient struct {
+	Timeout         time.Duration `mapstructure:"TIMEOUT" json:"TIMEOUT"`
+	MaxAttempts     int           `mapstructure:"MAX_ATTEMPTS" json:"MAX_ATTEMPTS"`
+	BreakerFailures int           `mapstructure:"BREAKER_FAILURES" json:"BREAKER_FAILURES"`
+	BreakerCooldown time.Duration `mapstructure:"BREAKER_COOLDOWN" json:"BREAKER_COOLDOWN"`
+}
+
+// Storage selects where the uploaded files are kept. The s3 and minio
+// backends use the bucket and the credentials of AWSS3.
+type Storage struct {
+	// Backend is "s3" (default), "minio", "local" or "memory"
+	Backend string `mapstructure:"BACKEND" json:"BACKEND"`
+	// Endpoint is the url of the minio server
+	Endpoint string `mapstructure:"ENDPOINT" json:"ENDPOINT"`
+	// Directory keeps the files of the local backend
+	Directory string `mapstructure:"DIRECTORY" json:"DIRECTORY"`
+	// PublicURL is the gateway address the local backend builds its URLs on
+	PublicURL string `mapstructure:"PUBLIC_URL" json:"PUBLIC_URL"`
+	// SigningKey signs the URLs of the local backend
+	SigningKey string `mapstructure:"SIGNING_KEY" json:"SIGNING_KEY"`
+	// URLExpiry is how long the download and upload URLs stay valid, 15m by default
+	URLExpiry time.Duration `mapstructure:"URL_EXPIRY" json:"URL_EXPIRY"`
+	// UploadMaxSize is the biggest image the clients can upload in bytes, 10 MB by default
+	UploadMaxSize int64 `mapstructure:"UPLOAD_MAX_SIZE" json:"UPLOAD_MAX_SIZE"`
+	// CDNURL is the public base url of a CDN in front of the files, the images are served from it when set
+	CDNURL string `mapstructure:"CDN_URL" json:"CDN_URL"`
+	// CDNSigningKey signs the CDN urls, which are not signed without it
+	CDNSigningKey string `mapstructure:"CDN_SIGNING_KEY" json:"CDN_SIGNING_KEY"`
+}
+
+// AllInOne selects the external services used by cmd/gestapo, which runs every
+// service in one process. Empty values keep the in-memory fakes, the files are
+// kept in memory unless STORAGE is set.
+type AllInOne struct {
+	// Email is "memory" or "gmail" (uses EMAIL)
+	Email string `mapstructure:"EMAIL" json:"EMAIL"`
+	// SMS is "memory" or "twilio" (uses TWILIO)
+	SMS string `mapstructure:"SMS" json:"SMS"`
+}
+
+// Moderation configures the automatic checks of the reviews, a review failing one
+// waits in the moderation queue of the admins. Zero values fall back to the defaults.
+type Moderation struct {
+	// BannedWords hold the reviews containing one of them, regardless of the case
+	BannedWords []string `mapstructure:"BANNED_WORDS" json:"BANNED_WORDS"`
+	// MaxLinks is how many links a review can have, none by default
+	MaxLinks int `mapstructure:"MAX_LINKS" json:"MAX_LINKS"`
+	// DuplicateWindow holds the reviews with the text of another review added within it, 30 days by default
+	DuplicateWindow time.Duration `mapstructure:"DUPLICATE_WINDOW" json:"DUPLICATE_WINDOW"`
+	// BurstWindow and BurstSize hold the reviews of a product which got BurstSize reviews
+	// within BurstWindow, 10 reviews in 1h by default
+	BurstWindow time.Duration `mapstructure:"BURST_WINDOW" json:"BURST_WINDOW"`
+	BurstSize   int           `mapstructure:"BURST_SIZE" json:"BURST_SIZE"`
+	// ReportThreshold sends a review back to the queue once it has that many open reports, 3 by default
+	ReportThreshold int `mapstructure:"REPORT_THRESHOLD" json:"REPORT_THRESHOLD"`
+}
+
+// PriceAlerts configures the notifications of the users wishlisting a product
+// whose price drops. Zero values fall back to the defaults.
+type PriceAlerts struct {
+	// DropPercent is how much the price has to drop to notify, 10 percent by default
+	DropPercent float64 `mapstructure:"DROP_PERCENT" json:"DROP_PERCENT"`
+}
+
+// StockAlerts configures the notifications of the users waiting for a variant
+// back in stock. The oldest subscriptions are notified first, in batches sized
+// by the stock so the subscribers do not all race for a few units. Zero values
+// fall back to the defaults.
+type StockAlerts struct {
+	// BatchSize is how many subscribers a batch notifies at most, 100 by default
+	BatchSize int `mapstructure:"BATCH_SIZE" json:"BATCH_SIZE"`
+	// PerUnit is how many subscribers a batch notifies for each unit in stock, 2 by default
+	PerUnit int `mapstructure:"PER_UNIT" json:"PER_UNIT"`
+	// BatchInterval is how long a variant still in stock waits for its next batch, 30m by default
+	BatchInterval time.Duration `mapstructure:"BATCH_INTERVAL" json:"BATCH_INTERVAL"`
+}
+
 // LoadConfig reads configuration from file or environment variables.
 func LoadConfig(path string) (config Config, err error) {
 	viper.AddConfigPath(path)
@@ -0,0 +1,50 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// cartMigration moves the items of the extra carts of a user into its oldest
+// cart, adds up the quantities of a variant added more than once to a cart and
+// then adds the unique indexes keeping one cart per user or device and one
+// item per variant in a cart. The carts have a user, or a device for a guest.
+const cartMigration = `
+UPDATE cart_items ci SET cart_id = k.keep_id
+FROM (
+	SELECT id, FIRST_VALUE(id) OVER (PARTITION BY user_id ORDER BY created_at, id) AS keep_id
+	FROM carts WHERE user_id IS NOT NULL
+) k
+WHERE ci.cart_id = k.id AND k.id <> k.keep_id;
+
+DELETE FROM carts c
+WHERE c.user_id IS NOT NULL AND EXISTS (
+	SELECT 1 FROM carts o WHERE o.user_id = c.user_id AND (o.created_at, o.id) < (c.created_at, c.id)
+);
+
+UPDATE cart_items ci SET quantity = d.quantity
+FROM (
+	SELECT MIN(id::text)::uuid AS id, SUM(quantity) AS quantity
+	FROM cart_items GROUP BY cart_id, inventory_id HAVING COUNT(*) > 1
+) d
+WHERE ci.id = d.id;
+
+DELETE FROM cart_items ci
+USING cart_items o
+WHERE o.cart_id = ci.cart_id AND o.inventory_id = ci.inventory_id AND o.id::text < ci.id::text;
+
+UPDATE carts c SET price = COALESCE((SELECT SUM(quantity * price) FROM cart_items WHERE cart_id = c.id), 0);
+
+ALTER TABLE carts ALTER COLUMN user_id DROP NOT NULL;
+ALTER TABLE carts DROP CONSTRAINT IF EXISTS chk_carts_owner;
+ALTER TABLE carts ADD CONSTRAINT chk_carts_owner CHECK (user_id IS NOT NULL OR device_id IS NOT NULL);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_carts_user ON carts (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_carts_device ON carts (device_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cart_items_variant ON cart_items (cart_id, inventory_id);
+`
+
+// MigrateCarts merges the duplicate carts and cart items and adds the unique
+// indexes of the carts. It runs after the carts and the cart_items tables are
+// migrated and can run again safely.
+func MigrateCarts(gormDB *gorm.DB) error {
+	return gormDB.Exec(cartMigration).Error
+}
@@ -0,0 +1,26 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// categoryTreeMigration moves the unique category names to unique names among
+// the children of a parent, and gives the categories created before the tree
+// their path: they are roots, their path is their id.
+const categoryTreeMigration = `
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_category_name_key;
+DROP INDEX IF EXISTS idx_categories_category_name;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_parent_name
+ON categories (COALESCE(parent_id, '00000000-0000-0000-0000-000000000000'::uuid), LOWER(category_name))
+WHERE deleted_at IS NULL;
+
+UPDATE categories SET path = id::text WHERE path = '';
+`
+
+// MigrateCategoryTree adds the constraints of the category tree and the paths of
+// the existing categories. It runs after the categories table is migrated and
+// can run again safely.
+func MigrateCategoryTree(gormDB *gorm.DB) error {
+	return gormDB.Exec(categoryTreeMigration).Error
+}
@@ -24,6 +24,21 @@ type DBMigration struct {
 	tracking_details models.Tracking_Details
 	tracking_items   models.Tracking_Items
 	reviews          models.Reviews
+	review_votes     models.Review_Votes
+	review_reports   models.Review_Reports
+	upload_sessions  models.Upload_Sessions
+	search_queries   models.Search_Queries
+	stock_movements  models.Stock_Movements
+	import_jobs      models.Import_Jobs
+	product_stats    models.Product_Stats
+	recommendations  models.Product_Recommendations
+	product_views    models.Product_Views
+	price_histories  models.Price_Histories
+	notifications    models.Notifications
+	subscriptions    models.Stock_Subscriptions
+	questions        models.Product_Questions
+	answers          models.Product_Answers
+	answer_votes     models.Answer_Votes
 }
 
 var migrate DBMigration
@@ -92,4 +107,100 @@ func AutoMigrateTables(gormDB *gorm.DB) {
 	if err := gormDB.AutoMigrate(&migrate.reviews); err != nil {
 		fmt.Println(err.Error())
 	}
+
+	if err := gormDB.AutoMigrate(&migrate.review_votes); err != nil {
+		fmt.Println(err.Error())
+	}
+
+	if err := gormDB.AutoMigrate(&migrate.review_reports); err != nil {
+		fmt.Println(err.Error())
+	}
+
+	if err := gormDB.AutoMigrate(&migrate.upload_sessions); err != nil {
+		fmt.Println(err.Error())
+	}
+
+	if err := gormDB.AutoMigrate(&migrate.search_queries); err != nil {
+		fmt.Println(err.Error())
+	}
+
+	if err := gormDB.AutoMigrate(&migrate.stock_movements); err != nil {
+		fmt.Println(err.Error())
+	}
+
+	if err := gormDB.AutoMigrate(&migrate.import_jobs); err != nil {
+		fmt.Println(err.Error())
+	}
+
+	if err := gormDB.AutoMigrate(&migrate.product_stats); err != nil {
+		fmt.Println(err.Error())
+	}
+
+	if err := gormDB.AutoMigrate(&migrate.recommendations); err != nil {
+		fmt.Println(err.Error())
+	}
+
+	if err := gormDB.AutoMigrate(&migrate.product_views); err != nil {
+		fmt.Println(err.Error())
+	}
+
+	if err := gormDB.AutoMigrate(&migrate.price_histories); err != nil {
+		fmt.Println(err.Error())
+	}
+
+	if err := gormDB.AutoMigrate(&migrate.notifications); err != nil {
+		fmt.Println(err.Error())
+	}
+
+	if err := gormDB.AutoMigrate(&migrate.subscriptions); err != nil {
+		fmt.Println(err.Error())
+	}
+
+	if err := gormDB.AutoMigrate(&migrate.questions); err != nil {
+		fmt.Println(err.Error())
+	}
+
+	if err := gormDB.AutoMigrate(&migrate.answers); err != nil {
+		fmt.Println(err.Error())
+	}
+
+	if err := gormDB.AutoMigrate(&migrate.answer_votes); err != nil {
+		fmt.Println(err.Error())
+	}
+
+	if err := MigrateProductSearch(gormDB); err != nil {
+		fmt.Println(err.Error())
+	}
+
+	if err := MigrateProductVariants(gormDB); err != nil {
+		fmt.Println(err.Error())
+	}
+
+	if err := MigrateStockLedger(gormDB); err != nil {
+		fmt.Println(err.Error())
+	}
+
+	if err := MigrateCategoryTree(gormDB); err != nil {
+		fmt.Println(err.Error())
+	}
+
+	if err := MigrateUploadPurposes(gormDB); err != nil {
+		fmt.Println(err.Error())
+	}
+
+	if err := MigrateProductStats(gormDB); err != nil {
+		fmt.Println(err.Error())
+	}
+
+	if err := MigratePriceHistory(gormDB); err != nil {
+		fmt.Println(err.Error())
+	}
+
+	if err := MigrateProductLifecycle(gormDB); err != nil {
+		fmt.Println(err.Error())
+	}
+
+	if err := MigrateCarts(gormDB); err != nil {
+		fmt.Println(err.Error())
+	}
 }
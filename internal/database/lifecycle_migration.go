@@ -0,0 +1,24 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// productLifecycleMigration adds listed, which tells whether a product is
+// listed to the users: published, within its publish and unpublish times and
+// not deleted. The queries of the listings call it as listed(p) so they agree
+// on what a listed product is.
+const productLifecycleMigration = `
+CREATE OR REPLACE FUNCTION listed(p products) RETURNS boolean
+LANGUAGE sql STABLE AS $$
+	SELECT p.status = 'PUBLISHED' AND p.deleted_at IS NULL
+	AND (p.publish_at IS NULL OR p.publish_at <= NOW())
+	AND (p.unpublish_at IS NULL OR p.unpublish_at > NOW())
+$$;
+`
+
+// MigrateProductLifecycle creates the listed function of the products. It runs
+// after the products table is migrated and can run again safely.
+func MigrateProductLifecycle(gormDB *gorm.DB) error {
+	return gormDB.Exec(productLifecycleMigration).Error
+}
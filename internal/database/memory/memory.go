@@ -0,0 +1,561 @@
+// Package memory keeps the tables of the marketplace in memory.
+//
+// Every service shares one Postgres database in production, so the in-memory
+// stores of the services share one Database as well. It is used by the tests
+// and by the all-in-one mode, never by the deployed services.
+package memory
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+type User struct {
+	ID           string     `db:"id"`
+	ProfileImage *string    `db:"profile_image"`
+	FullName     *string    `db:"full_name"`
+	UserName     string     `db:"user_name"`
+	Phone        *string    `db:"phone"`
+	Email        *string    `db:"email"`
+	DOB          *time.Time `db:"dob"`
+	Gender       *string    `db:"gender"`
+	UserType     string     `db:"user_type"`
+	Password     string     `db:"password"`
+	CreatedAt    time.Time  `db:"created_at"`
+	UpdatedAt    time.Time  `db:"updated_at"`
+}
+
+type Category struct {
+	ID           string              `db:"id"`
+	ParentID     *string             `db:"parent_id"`
+	Path         string              `db:"path"`
+	Position     int32               `db:"position"`
+	CategoryName string              `db:"category_name"`
+	Image        *string             `db:"image"`
+	Attributes   []CategoryAttribute `db:"attributes"`
+	ArchivedAt   *time.Time          `db:"archived_at"`
+	CreatedAt    time.Time           `db:"created_at"`
+	UpdatedAt    time.Time           `db:"updated_at"`
+}
+
+type CategoryAttribute struct {
+	Name     string
+	Type     string
+	Required bool
+	Values   []string
+}
+
+type Product struct {
+	ID          string            `db:"id"`
+	MerchantID  string            `db:"merchent_id"`
+	CategoryID  string            `db:"category_id"`
+	DiscountID  *string           `db:"discount_id"`
+	ProductName string            `db:"product_name"`
+	Description string            `db:"description"`
+	Images      []string          `db:"images"`
+	Size        []float64         `db:"size"`
+	OptionTypes []string          `db:"option_types"`
+	Price       float64           `db:"price"`
+	Attributes  map[string]string `db:"attributes"`
+	CreatedAt   time.Time         `db:"created_at"`
+	UpdatedAt   time.Time         `db:"updated_at"`
+	DeletedAt   *time.Time        `db:"deleted_at"`
+
+	Status       string     `db:"status"`
+	StatusReason *string    `db:"status_reason"`
+	PublishAt    *time.Time `db:"publish_at"`
+	UnpublishAt  *time.Time `db:"unpublish_at"`
+}
+
+type Discount struct {
+	ID          string    `db:"id"`
+	MerchantID  string    `db:"merchent_id"`
+	Name        string    `db:"name"`
+	Description string    `db:"description"`
+	Percent     float64   `db:"percent"`
+	CardColor   string    `db:"card_color"`
+	StartTime   time.Time `db:"start_time"`
+	EndTime     time.Time `db:"end_time"`
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+type Inventory struct {
+	ID                string            `db:"id"`
+	ProductID         string            `db:"product_id"`
+	SKU               *string           `db:"sku"`
+	Options           map[string]string `db:"options"`
+	Size              float64           `db:"size"`
+	Price             *float64          `db:"price"`
+	Images            []string          `db:"images"`
+	Quantity          int32             `db:"quantity"`
+	LowStockThreshold *int32            `db:"low_stock_threshold"`
+	CreatedAt         time.Time         `db:"created_at"`
+	UpdatedAt         time.Time         `db:"updated_at"`
+}
+
+type Wishlist struct {
+	ID        string    `db:"id"`
+	UserID    string    `db:"user_id"`
+	ProductID string    `db:"product_id"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// Cart is the cart of a user, or of a guest device when UserID is empty.
+type Cart struct {
+	ID        string    `db:"id"`
+	UserID    string    `db:"user_id"`
+	DeviceID  *string   `db:"device_id"`
+	Price     float64   `db:"price"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+type CartItem struct {
+	ID          string    `db:"id"`
+	CartID      string    `db:"cart_id"`
+	ProductID   string    `db:"product_id"`
+	InventoryID string    `db:"inventory_id"`
+	Quantity    int32     `db:"quantity"`
+	Price       float64   `db:"price"`
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+type Address struct {
+	ID          string    `db:"id"`
+	UserID      string    `db:"user_id"`
+	Title       string    `db:"title"`
+	AddressLine string    `db:"address_line"`
+	Country     *string   `db:"country"`
+	City        *string   `db:"city"`
+	PostalCode  *int64    `db:"postal_code"`
+	Landmark    *string   `db:"landmark"`
+	IsDefault   *bool     `db:"is_default"`
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+type PromoCode struct {
+	ID          string    `db:"id"`
+	Code        string    `db:"code"`
+	Title       string    `db:"title"`
+	Description string    `db:"description"`
+	Percent     float64   `db:"percent"`
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+type Payment struct {
+	ID            string    `db:"id"`
+	Amount        float64   `db:"amount"`
+	Provider      string    `db:"provider"`
+	Status        string    `db:"status"`
+	TransactionID *string   `db:"transaction_id"`
+	CreatedAt     time.Time `db:"created_at"`
+	UpdatedAt     time.Time `db:"updated_at"`
+}
+
+type OrderDetail struct {
+	ID        string    `db:"id"`
+	UserID    string    `db:"user_id"`
+	PaymentID string    `db:"payment_id"`
+	AddressID string    `db:"address_id"`
+	PromoID   *string   `db:"promo_id"`
+	Amount    float64   `db:"amount"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+type OrderItem struct {
+	ID          string            `db:"id"`
+	OrderID     string            `db:"order_id"`
+	ProductID   string            `db:"product_id"`
+	InventoryID *string           `db:"inventory_id"`
+	SKU         *string           `db:"sku"`
+	Options     map[string]string `db:"options"`
+	Size        float64           `db:"size"`
+	Quantity    int32             `db:"quantity"`
+	Amount      float64           `db:"amount"`
+	Status      string            `db:"status"`
+	CreatedAt   time.Time         `db:"created_at"`
+	UpdatedAt   time.Time         `db:"updated_at"`
+}
+
+type TrackingDetail struct {
+	ID          string    `db:"id"`
+	OrderItemID string    `db:"order_item_id"`
+	Status      int       `db:"status"`
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+type TrackingItem struct {
+	ID         string    `db:"id"`
+	TrackingID string    `db:"tracking_id"`
+	Title      string    `db:"title"`
+	Summary    string    `db:"summary"`
+	CreatedAt  time.Time `db:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
+
+type Review struct {
+	ID               string     `db:"id"`
+	ProductID        string     `db:"product_id"`
+	UserID           string     `db:"user_id"`
+	Star             float32    `db:"star"`
+	Review           string     `db:"review"`
+	Images           []string   `db:"images"`
+	Reply            *string    `db:"reply"`
+	RepliedAt        *time.Time `db:"replied_at"`
+	ReplyUpdatedAt   *time.Time `db:"reply_updated_at"`
+	EditedAt         *time.Time `db:"edited_at"`
+	Status           string     `db:"status"`
+	Flags            []string   `db:"flags"`
+	ModerationReason *string    `db:"moderation_reason"`
+	ModeratedBy      *string    `db:"moderated_by"`
+	ModeratedAt      *time.Time `db:"moderated_at"`
+	CreatedAt        time.Time  `db:"created_at"`
+	UpdatedAt        time.Time  `db:"updated_at"`
+}
+
+type ReviewReport struct {
+	ID         string     `db:"id"`
+	ReviewID   string     `db:"review_id"`
+	UserID     string     `db:"user_id"`
+	Reason     string     `db:"reason"`
+	Details    string     `db:"details"`
+	ResolvedAt *time.Time `db:"resolved_at"`
+	CreatedAt  time.Time  `db:"created_at"`
+	UpdatedAt  time.Time  `db:"updated_at"`
+}
+
+type ReviewVote struct {
+	ID        string    `db:"id"`
+	ReviewID  string    `db:"review_id"`
+	UserID    string    `db:"user_id"`
+	Helpful   bool      `db:"helpful"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+type UploadSession struct {
+	ID          string    `db:"id"`
+	UserID      string    `db:"user_id"`
+	Purpose     string    `db:"purpose"`
+	TargetID    *string   `db:"target_id"`
+	Key         string    `db:"key"`
+	ContentType string    `db:"content_type"`
+	Size        int64     `db:"size"`
+	Status      string    `db:"status"`
+	ExpiresAt   time.Time `db:"expires_at"`
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+type SearchQuery struct {
+	ID         string    `db:"id"`
+	Query      string    `db:"query"`
+	UserID     *string   `db:"user_id"`
+	CategoryID *string   `db:"category_id"`
+	Results    int64     `db:"results"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+type StockMovement struct {
+	ID          string    `db:"id"`
+	InventoryID string    `db:"inventory_id"`
+	ProductID   string    `db:"product_id"`
+	Type        string    `db:"type"`
+	Quantity    int32     `db:"quantity"`
+	Balance     int32     `db:"balance"`
+	Reason      string    `db:"reason"`
+	OrderItemID *string   `db:"order_item_id"`
+	UserID      *string   `db:"user_id"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+type ImportJob struct {
+	ID              string           `db:"id"`
+	MerchantID      string           `db:"merchant_id"`
+	Format          string           `db:"format"`
+	DryRun          bool             `db:"dry_run"`
+	Status          string           `db:"status"`
+	TotalRows       int32            `db:"total_rows"`
+	ProcessedRows   int32            `db:"processed_rows"`
+	CreatedProducts int32            `db:"created_products"`
+	FailedRows      int32            `db:"failed_rows"`
+	Errors          []ImportRowError `db:"errors"`
+	FinishedAt      *time.Time       `db:"finished_at"`
+	CreatedAt       time.Time        `db:"created_at"`
+	UpdatedAt       time.Time        `db:"updated_at"`
+}
+
+type ProductRecommendation struct {
+	ProductID string    `db:"product_id"`
+	RelatedID string    `db:"related_id"`
+	Score     float64   `db:"score"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+type ProductView struct {
+	ID        string    `db:"id"`
+	ProductID string    `db:"product_id"`
+	UserID    *string   `db:"user_id"`
+	DeviceID  *string   `db:"device_id"`
+	ViewedAt  time.Time `db:"viewed_at"`
+}
+
+type PriceHistory struct {
+	ID              string     `db:"id"`
+	ProductID       string     `db:"product_id"`
+	Price           float64    `db:"price"`
+	DiscountPercent *float64   `db:"discount_percent"`
+	EffectivePrice  float64    `db:"effective_price"`
+	ChangedAt       time.Time  `db:"changed_at"`
+	AlertCheckedAt  *time.Time `db:"alert_checked_at"`
+}
+
+type StockSubscription struct {
+	ID          string     `db:"id"`
+	UserID      string     `db:"user_id"`
+	ProductID   string     `db:"product_id"`
+	InventoryID string     `db:"inventory_id"`
+	CreatedAt   time.Time  `db:"created_at"`
+	NotifiedAt  *time.Time `db:"notified_at"`
+}
+
+type Notification struct {
+	ID        string     `db:"id"`
+	UserID    string     `db:"user_id"`
+	Type      string     `db:"type"`
+	Title     string     `db:"title"`
+	Body      string     `db:"body"`
+	ProductID *string    `db:"product_id"`
+	ReadAt    *time.Time `db:"read_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+type Question struct {
+	ID               string     `db:"id"`
+	ProductID        string     `db:"product_id"`
+	UserID           string     `db:"user_id"`
+	Question         string     `db:"question"`
+	Status           string     `db:"status"`
+	Flags            []string   `db:"flags"`
+	ModerationReason *string    `db:"moderation_reason"`
+	ModeratedBy      *string    `db:"moderated_by"`
+	ModeratedAt      *time.Time `db:"moderated_at"`
+	CreatedAt        time.Time  `db:"created_at"`
+	UpdatedAt        time.Time  `db:"updated_at"`
+}
+
+type Answer struct {
+	ID               string     `db:"id"`
+	QuestionID       string     `db:"question_id"`
+	UserID           string     `db:"user_id"`
+	Answer           string     `db:"answer"`
+	ByMerchant       bool       `db:"by_merchant"`
+	Status           string     `db:"status"`
+	Flags            []string   `db:"flags"`
+	ModerationReason *string    `db:"moderation_reason"`
+	ModeratedBy      *string    `db:"moderated_by"`
+	ModeratedAt      *time.Time `db:"moderated_at"`
+	CreatedAt        time.Time  `db:"created_at"`
+	UpdatedAt        time.Time  `db:"updated_at"`
+}
+
+type AnswerVote struct {
+	ID        string    `db:"id"`
+	AnswerID  string    `db:"answer_id"`
+	UserID    string    `db:"user_id"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type ImportRowError struct {
+	Row     int32
+	Field   string
+	Message string
+}
+
+// Database holds every table, keyed by the id of the rows.
+// Stores lock it for the whole operation, which makes each operation a transaction.
+type Database struct {
+	sync.Mutex
+
+	Users           map[string]*User
+	Categories      map[string]*Category
+	Products        map[string]*Product
+	Discounts       map[string]*Discount
+	Inventories     map[string]*Inventory
+	Wishlists       map[string]*Wishlist
+	Carts           map[string]*Cart
+	CartItems       map[string]*CartItem
+	Addresses       map[string]*Address
+	PromoCodes      map[string]*PromoCode
+	Payments        map[string]*Payment
+	OrderDetails    map[string]*OrderDetail
+	OrderItems      map[string]*OrderItem
+	TrackingDetails map[string]*TrackingDetail
+	TrackingItems   map[string]*TrackingItem
+	Reviews         map[string]*Review
+	ReviewVotes     map[string]*ReviewVote
+	ReviewReports   map[string]*ReviewReport
+	UploadSessions  map[string]*UploadSession
+	SearchQueries   map[string]*SearchQuery
+	StockMovements  map[string]*StockMovement
+	ImportJobs      map[string]*ImportJob
+	// Recommendations are keyed by RecommendationKey
+	Recommendations map[string]*ProductRecommendation
+	ProductViews    map[string]*ProductView
+	PriceHistories  map[string]*PriceHistory
+	Notifications   map[string]*Notification
+	Subscriptions   map[string]*StockSubscription
+	Questions       map[string]*Question
+	Answers         map[string]*Answer
+	AnswerVotes     map[string]*AnswerVote
+}
+
+// NewDatabase creates an empty database.
+func NewDatabase() *Database {
+	return &Database{
+		Users:           map[string]*User{},
+		Categories:      map[string]*Category{},
+		Products:        map[string]*Product{},
+		Discounts:       map[string]*Discount{},
+		Inventories:     map[string]*Inventory{},
+		Wishlists:       map[string]*Wishlist{},
+		Carts:           map[string]*Cart{},
+		CartItems:       map[string]*CartItem{},
+		Addresses:       map[string]*Address{},
+		PromoCodes:      map[string]*PromoCode{},
+		Payments:        map[string]*Payment{},
+		OrderDetails:    map[string]*OrderDetail{},
+		OrderItems:      map[string]*OrderItem{},
+		TrackingDetails: map[string]*TrackingDetail{},
+		TrackingItems:   map[string]*TrackingItem{},
+		Reviews:         map[string]*Review{},
+		ReviewVotes:     map[string]*ReviewVote{},
+		ReviewReports:   map[string]*ReviewReport{},
+		UploadSessions:  map[string]*UploadSession{},
+		SearchQueries:   map[string]*SearchQuery{},
+		StockMovements:  map[string]*StockMovement{},
+		ImportJobs:      map[string]*ImportJob{},
+		Recommendations: map[string]*ProductRecommendation{},
+		ProductViews:    map[string]*ProductView{},
+		PriceHistories:  map[string]*PriceHistory{},
+		Notifications:   map[string]*Notification{},
+		Subscriptions:   map[string]*StockSubscription{},
+		Questions:       map[string]*Question{},
+		Answers:         map[string]*Answer{},
+		AnswerVotes:     map[string]*AnswerVote{},
+	}
+}
+
+// table returns the rows of the table with the given SQL name.
+func (db *Database) table(name string) (interface{}, error) {
+	switch name {
+	case "user_data":
+		return db.Users, nil
+	case "categories":
+		return db.Categories, nil
+	case "products":
+		return db.Products, nil
+	case "discounts":
+		return db.Discounts, nil
+	case "inventories":
+		return db.Inventories, nil
+	case "wishlists":
+		return db.Wishlists, nil
+	case "carts":
+		return db.Carts, nil
+	case "cart_items":
+		return db.CartItems, nil
+	case "addresses":
+		return db.Addresses, nil
+	case "promo_codes":
+		return db.PromoCodes, nil
+	case "payment_details":
+		return db.Payments, nil
+	case "order_details":
+		return db.OrderDetails, nil
+	case "order_items":
+		return db.OrderItems, nil
+	case "tracking_details":
+		return db.TrackingDetails, nil
+	case "tracking_items":
+		return db.TrackingItems, nil
+	case "reviews":
+		return db.Reviews, nil
+	case "review_votes":
+		return db.ReviewVotes, nil
+	case "review_reports":
+		return db.ReviewReports, nil
+	case "upload_sessions":
+		return db.UploadSessions, nil
+	case "search_queries":
+		return db.SearchQueries, nil
+	case "stock_movements":
+		return db.StockMovements, nil
+	case "import_jobs":
+		return db.ImportJobs, nil
+	case "product_recommendations":
+		return db.Recommendations, nil
+	case "product_views":
+		return db.ProductViews, nil
+	case "price_histories":
+		return db.PriceHistories, nil
+	case "notifications":
+		return db.Notifications, nil
+	case "stock_subscriptions":
+		return db.Subscriptions, nil
+	case "product_questions":
+		return db.Questions, nil
+	case "product_answers":
+		return db.Answers, nil
+	case "answer_votes":
+		return db.AnswerVotes, nil
+	}
+	return nil, fmt.Errorf("relation \"%s\" does not exist", name)
+}
+
+// Exists reports whether a row of table has value in column, the in-memory
+// version of the `SELECT * FROM table WHERE column = $1` used by the stores.
+// The caller must hold the lock.
+func (db *Database) Exists(table, column, value string) (bool, error) {
+	rows, err := db.table(table)
+	if err != nil {
+		return false, err
+	}
+	iter := reflect.ValueOf(rows).MapRange()
+	for iter.Next() {
+		row := iter.Value().Elem()
+		field, ok := fieldByColumn(row, column)
+		if !ok {
+			return false, fmt.Errorf("column \"%s\" does not exist", column)
+		}
+		if field.Kind() == reflect.Pointer {
+			if field.IsNil() {
+				continue
+			}
+			field = field.Elem()
+		}
+		if fmt.Sprint(field.Interface()) == value {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func fieldByColumn(row reflect.Value, column string) (reflect.Value, bool) {
+	rowType := row.Type()
+	for i := 0; i < rowType.NumField(); i++ {
+		if rowType.Field(i).Tag.Get("db") == column {
+			return row.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
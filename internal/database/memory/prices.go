@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecordPrices adds a price history row for the product, every product when
+// productID is empty, whose price or running discount differs from its last
+// row and returns how many it added, like record_product_prices does on
+// Postgres. The stores call it after changing a price or a discount.
+// The caller must hold the lock.
+func (db *Database) RecordPrices(productID string, now time.Time) int64 {
+	var recorded int64
+	for _, product := range db.Products {
+		if product.DeletedAt != nil || productID != "" && product.ID != productID {
+			continue
+		}
+		var percent *float64
+		effectivePrice := product.Price
+		if discountPrice := db.DiscountPrice(product); discountPrice != nil {
+			discount := db.Discounts[*product.DiscountID].Percent
+			percent = &discount
+			effectivePrice = *discountPrice
+		}
+		last := db.LastPrice(product.ID, nil)
+		if last != nil && last.Price == product.Price && samePercent(last.DiscountPercent, percent) {
+			continue
+		}
+		history := &PriceHistory{
+			ID:              uuid.NewString(),
+			ProductID:       product.ID,
+			Price:           product.Price,
+			DiscountPercent: percent,
+			EffectivePrice:  effectivePrice,
+			ChangedAt:       now,
+		}
+		db.PriceHistories[history.ID] = history
+		recorded++
+	}
+	return recorded
+}
+
+// LastPrice returns the last price history row of the product, changed before
+// the given time when it is set.
+func (db *Database) LastPrice(productID string, before *time.Time) *PriceHistory {
+	var last *PriceHistory
+	for _, history := range db.PriceHistories {
+		if history.ProductID != productID || (before != nil && !history.ChangedAt.Before(*before)) {
+			continue
+		}
+		if last == nil || history.ChangedAt.After(last.ChangedAt) {
+			last = history
+		}
+	}
+	return last
+}
+
+func samePercent(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
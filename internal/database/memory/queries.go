@@ -0,0 +1,159 @@
+package memory
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/utils"
+)
+
+// The helpers below compute the values the SQL queries join or aggregate.
+// The caller must hold the lock.
+
+// CategoryName returns the name of the category of the product.
+func (db *Database) CategoryName(product *Product) *string {
+	category, ok := db.Categories[product.CategoryID]
+	if !ok {
+		return nil
+	}
+	name := category.CategoryName
+	return &name
+}
+
+// InSubtree tells whether the category is the root or one of its descendants.
+func (db *Database) InSubtree(categoryID, rootID string) bool {
+	category, ok := db.Categories[categoryID]
+	root, found := db.Categories[rootID]
+	if !ok || !found {
+		return false
+	}
+	return category.Path == root.Path || strings.HasPrefix(category.Path, root.Path+"/")
+}
+
+// Listed tells whether the product is listed to the users: published, within
+// its publish and unpublish times and not deleted, like listed(p) on Postgres.
+func Listed(product *Product) bool {
+	now := time.Now()
+	return product.Status == utils.ProductPublished && product.DeletedAt == nil &&
+		(product.PublishAt == nil || !product.PublishAt.After(now)) &&
+		(product.UnpublishAt == nil || product.UnpublishAt.After(now))
+}
+
+// DiscountPrice returns the price after the running discount of the product, if any.
+func (db *Database) DiscountPrice(product *Product) *float64 {
+	return db.discounted(product, product.Price)
+}
+
+// VariantPrice returns the price of the variant, its own or the one of the product,
+// and the price after the running discount of the product, if any.
+func (db *Database) VariantPrice(product *Product, inventory *Inventory) (float64, *float64) {
+	price := product.Price
+	if inventory.Price != nil {
+		price = *inventory.Price
+	}
+	return price, db.discounted(product, price)
+}
+
+func (db *Database) discounted(product *Product, price float64) *float64 {
+	if product.DiscountID == nil {
+		return nil
+	}
+	discount, ok := db.Discounts[*product.DiscountID]
+	if !ok || !discount.EndTime.After(time.Now()) {
+		return nil
+	}
+	price = price - (price * discount.Percent / 100)
+	return &price
+}
+
+// Variants returns the inventories of the product ordered by size and SKU.
+func (db *Database) Variants(productID string) []*Inventory {
+	var variants []*Inventory
+	for _, inventory := range db.Inventories {
+		if inventory.ProductID == productID {
+			variants = append(variants, inventory)
+		}
+	}
+	sort.Slice(variants, func(i, j int) bool {
+		if variants[i].Size != variants[j].Size {
+			return variants[i].Size < variants[j].Size
+		}
+		return sku(variants[i]) < sku(variants[j])
+	})
+	return variants
+}
+
+func sku(inventory *Inventory) string {
+	if inventory.SKU == nil {
+		return ""
+	}
+	return *inventory.SKU
+}
+
+// ProductStats are the aggregates of a product the product_stats table keeps.
+type ProductStats struct {
+	RatingAverage *float64
+	RatingCount   int64
+	UnitsSold     int64
+	WishlistCount int64
+	ViewCount     int64
+}
+
+// Stats returns the stats of the product: its published reviews, the units of its
+// order items not cancelled, its wishlists and its views. RatingAverage is nil
+// without reviews.
+func (db *Database) Stats(productID string) ProductStats {
+	var stats ProductStats
+	var total float64
+	for _, review := range db.Reviews {
+		if review.ProductID == productID && review.Status == utils.ReviewPublished {
+			total += float64(review.Star)
+			stats.RatingCount++
+		}
+	}
+	if stats.RatingCount != 0 {
+		average := total / float64(stats.RatingCount)
+		stats.RatingAverage = &average
+	}
+	for _, item := range db.OrderItems {
+		if item.ProductID == productID && item.Status != utils.OrderCancelled {
+			stats.UnitsSold += int64(item.Quantity)
+		}
+	}
+	for _, wishlist := range db.Wishlists {
+		if wishlist.ProductID == productID {
+			stats.WishlistCount++
+		}
+	}
+	for _, view := range db.ProductViews {
+		if view.ProductID == productID {
+			stats.ViewCount++
+		}
+	}
+	return stats
+}
+
+// RecommendationKey returns the key of the recommendation of related for the product.
+func RecommendationKey(productID, relatedID string) string {
+	return productID + "/" + relatedID
+}
+
+// WishlistID returns the id of the wishlist entry of the product for the user.
+func (db *Database) WishlistID(productID, userID string) *string {
+	for _, wishlist := range db.Wishlists {
+		if wishlist.ProductID == productID && wishlist.UserID == userID {
+			id := wishlist.ID
+			return &id
+		}
+	}
+	return nil
+}
+
+// FirstImage returns the first image of the product, like images[1] in SQL.
+func FirstImage(product *Product) string {
+	if len(product.Images) == 0 {
+		return ""
+	}
+	return product.Images[0]
+}
@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+// MoveStock changes the stock of the variant by quantity and appends the
+// movement to the ledger, like the stores do in one transaction on Postgres.
+// Like the trigger of the ledger, it notifies the merchant when the stock falls
+// from above the low stock threshold of the variant to or below it.
+// The caller must hold the lock and check the stock does not go below zero.
+func (db *Database) MoveStock(inventory *Inventory, movementType string, quantity int32, reason string, orderItemID, userID *string, now time.Time) *StockMovement {
+	inventory.Quantity += quantity
+	inventory.UpdatedAt = now
+	movement := &StockMovement{
+		ID:          uuid.NewString(),
+		InventoryID: inventory.ID,
+		ProductID:   inventory.ProductID,
+		Type:        movementType,
+		Quantity:    quantity,
+		Balance:     inventory.Quantity,
+		Reason:      reason,
+		OrderItemID: orderItemID,
+		UserID:      userID,
+		CreatedAt:   now,
+	}
+	db.StockMovements[movement.ID] = movement
+
+	threshold := inventory.LowStockThreshold
+	product, ok := db.Products[inventory.ProductID]
+	if ok && threshold != nil && movement.Balance <= *threshold && movement.Balance-quantity > *threshold {
+		name := product.ProductName
+		if inventory.SKU != nil {
+			name += " (" + *inventory.SKU + ")"
+		}
+		productID := product.ID
+		notification := &Notification{
+			ID:        uuid.NewString(),
+			UserID:    product.MerchantID,
+			Type:      utils.NotificationLowStock,
+			Title:     "Low stock",
+			Body:      fmt.Sprintf("%s is down to %d in stock", name, movement.Balance),
+			ProductID: &productID,
+			CreatedAt: now,
+		}
+		db.Notifications[notification.ID] = notification
+	}
+	return movement
+}
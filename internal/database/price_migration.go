@@ -0,0 +1,79 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// priceHistoryMigration records the prices of the products in price_histories.
+// The product_prices view is the current price of every product, after its
+// running discount. record_product_prices adds a row for the products of pid,
+// every product when it is NULL, whose price or discount differs from their
+// last row, and returns how many it added. The triggers call it in the
+// transaction changing the price or the discount of a product, or a discount,
+// and the price tracker of the product service calls it for the discounts
+// ending. The rows are stamped with clock_timestamp so the changes of one
+// transaction keep their order.
+const priceHistoryMigration = `
+CREATE OR REPLACE VIEW product_prices AS
+SELECT
+	p.id AS product_id,
+	p.price,
+	d.percent AS discount_percent,
+	p.price - COALESCE(p.price * d.percent / 100, 0) AS effective_price
+FROM products p
+LEFT JOIN discounts d ON d.id = p.discount_id AND d.end_time > NOW()
+WHERE p.deleted_at IS NULL;
+
+CREATE OR REPLACE FUNCTION record_product_prices(pid uuid) RETURNS bigint
+LANGUAGE sql AS $$
+	WITH recorded AS (
+		INSERT INTO price_histories (id, product_id, price, discount_percent, effective_price, changed_at)
+		SELECT gen_random_uuid(), c.product_id, c.price, c.discount_percent, c.effective_price, clock_timestamp()
+		FROM product_prices c
+		LEFT JOIN LATERAL (
+			SELECT h.price, h.discount_percent FROM price_histories h
+			WHERE h.product_id = c.product_id
+			ORDER BY h.changed_at DESC LIMIT 1
+		) l ON TRUE
+		WHERE (pid IS NULL OR c.product_id = pid)
+		AND (l.price IS NULL OR l.price <> c.price OR l.discount_percent IS DISTINCT FROM c.discount_percent)
+		RETURNING 1
+	)
+	SELECT COUNT(*) FROM recorded
+$$;
+
+CREATE OR REPLACE FUNCTION products_price_histories() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+	PERFORM record_product_prices(NEW.id);
+	RETURN NULL;
+END
+$$;
+
+DROP TRIGGER IF EXISTS products_price_histories ON products;
+CREATE TRIGGER products_price_histories
+AFTER INSERT OR UPDATE OF price, discount_id ON products
+FOR EACH ROW EXECUTE FUNCTION products_price_histories();
+
+CREATE OR REPLACE FUNCTION discounts_price_histories() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+	PERFORM record_product_prices(p.id) FROM products p WHERE p.discount_id = NEW.id;
+	RETURN NULL;
+END
+$$;
+
+DROP TRIGGER IF EXISTS discounts_price_histories ON discounts;
+CREATE TRIGGER discounts_price_histories
+AFTER UPDATE OF percent, end_time ON discounts
+FOR EACH ROW EXECUTE FUNCTION discounts_price_histories();
+
+SELECT record_product_prices(NULL);
+`
+
+// MigratePriceHistory creates the view, the function and the triggers keeping
+// the price history, and the first price of the existing products. It runs after
+// the price_histories table is migrated and can run again safely.
+func MigratePriceHistory(gormDB *gorm.DB) error {
+	return gormDB.Exec(priceHistoryMigration).Error
+}
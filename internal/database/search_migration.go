@@ -0,0 +1,63 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// productSearchMigration keeps products.search_vector up to date for the
+// product search. The vector weighs the name (A) over the category name (B)
+// and the description (C). It is computed by the database, so the products
+// inserted and edited by the merchants are searchable right away, and it is
+// refreshed when a category is renamed. The trigram index on the name lets
+// the search find the products despite a typo.
+const productSearchMigration = `
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_products_name_trgm ON products USING GIN (product_name gin_trgm_ops);
+
+CREATE OR REPLACE FUNCTION products_search_vector(name text, category text, description text) RETURNS tsvector
+LANGUAGE sql IMMUTABLE AS $$
+	SELECT setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+		setweight(to_tsvector('english', coalesce(category, '')), 'B') ||
+		setweight(to_tsvector('english', coalesce(description, '')), 'C')
+$$;
+
+CREATE OR REPLACE FUNCTION products_search_vector_update() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+	NEW.search_vector := products_search_vector(NEW.product_name,
+		(SELECT category_name FROM categories WHERE id = NEW.category_id), NEW.description);
+	RETURN NEW;
+END
+$$;
+
+DROP TRIGGER IF EXISTS products_search_vector_update ON products;
+CREATE TRIGGER products_search_vector_update
+BEFORE INSERT OR UPDATE OF product_name, description, category_id ON products
+FOR EACH ROW EXECUTE FUNCTION products_search_vector_update();
+
+CREATE OR REPLACE FUNCTION categories_search_vector_update() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+	UPDATE products SET search_vector = products_search_vector(product_name, NEW.category_name, description)
+	WHERE category_id = NEW.id;
+	RETURN NEW;
+END
+$$;
+
+DROP TRIGGER IF EXISTS categories_search_vector_update ON categories;
+CREATE TRIGGER categories_search_vector_update
+AFTER UPDATE OF category_name ON categories
+FOR EACH ROW EXECUTE FUNCTION categories_search_vector_update();
+
+UPDATE products p SET search_vector = products_search_vector(p.product_name, c.category_name, p.description)
+FROM categories c
+WHERE p.category_id = c.id AND p.search_vector IS NULL;
+`
+
+// MigrateProductSearch creates the extension, the indexes and the triggers of
+// the product search, and fills the vector of the products created before.
+// It runs after the products table is migrated and can run again safely.
+func MigrateProductSearch(gormDB *gorm.DB) error {
+	return gormDB.Exec(productSearchMigration).Error
+}
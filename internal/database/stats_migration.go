@@ -0,0 +1,150 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// productStatsMigration keeps product_stats up to date in the transaction
+// changing a review, an order item, a wishlist or a view. The triggers add the change
+// of the row to the stats of its product, so concurrent changes add up instead
+// of overwriting each other. The product_stats_expected view computes the stats
+// from the tables, for the backfill and the consistency check. A view is only
+// counted, clearing the history of a viewer keeps its views. The products
+// created before the stats get theirs from the view.
+const productStatsMigration = `
+CREATE OR REPLACE VIEW product_stats_expected AS
+SELECT
+	p.id AS product_id,
+	COALESCE(r.rating_count, 0) AS rating_count,
+	COALESCE(r.rating_sum, 0) AS rating_sum,
+	r.rating_average,
+	COALESCE(o.units_sold, 0) AS units_sold,
+	COALESCE(w.wishlist_count, 0) AS wishlist_count,
+	COALESCE(v.view_count, 0) AS view_count
+FROM products p
+LEFT JOIN (
+	SELECT product_id, COUNT(*) AS rating_count, SUM(star::float8) AS rating_sum, AVG(star::float8) AS rating_average
+	FROM reviews WHERE status = 'PUBLISHED' GROUP BY product_id
+) r ON r.product_id = p.id
+LEFT JOIN (
+	SELECT product_id, SUM(quantity)::bigint AS units_sold
+	FROM order_items WHERE status <> 'Cancelled' AND deleted_at IS NULL GROUP BY product_id
+) o ON o.product_id = p.id
+LEFT JOIN (
+	SELECT product_id, COUNT(*) AS wishlist_count FROM wishlists GROUP BY product_id
+) w ON w.product_id = p.id
+LEFT JOIN (
+	SELECT product_id, COUNT(*) AS view_count FROM product_views GROUP BY product_id
+) v ON v.product_id = p.id;
+
+CREATE OR REPLACE FUNCTION product_stats_add(pid uuid, d_rating_count bigint, d_rating_sum float8, d_units_sold bigint, d_wishlist_count bigint) RETURNS void
+LANGUAGE sql AS $$
+	INSERT INTO product_stats AS s (product_id, rating_count, rating_sum, rating_average, units_sold, wishlist_count, updated_at)
+	VALUES (pid, d_rating_count, d_rating_sum, d_rating_sum / NULLIF(d_rating_count, 0), d_units_sold, d_wishlist_count, NOW())
+	ON CONFLICT (product_id) DO UPDATE SET
+	rating_count = s.rating_count + EXCLUDED.rating_count,
+	rating_sum = s.rating_sum + EXCLUDED.rating_sum,
+	rating_average = (s.rating_sum + EXCLUDED.rating_sum) / NULLIF(s.rating_count + EXCLUDED.rating_count, 0),
+	units_sold = s.units_sold + EXCLUDED.units_sold,
+	wishlist_count = s.wishlist_count + EXCLUDED.wishlist_count,
+	updated_at = NOW()
+$$;
+
+CREATE OR REPLACE FUNCTION products_product_stats() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+	PERFORM product_stats_add(NEW.id, 0, 0, 0, 0);
+	RETURN NULL;
+END
+$$;
+
+DROP TRIGGER IF EXISTS products_product_stats ON products;
+CREATE TRIGGER products_product_stats
+AFTER INSERT ON products
+FOR EACH ROW EXECUTE FUNCTION products_product_stats();
+
+CREATE OR REPLACE FUNCTION reviews_product_stats() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+	IF TG_OP <> 'INSERT' AND OLD.status = 'PUBLISHED' THEN
+		PERFORM product_stats_add(OLD.product_id, -1, -OLD.star::float8, 0, 0);
+	END IF;
+	IF TG_OP <> 'DELETE' AND NEW.status = 'PUBLISHED' THEN
+		PERFORM product_stats_add(NEW.product_id, 1, NEW.star::float8, 0, 0);
+	END IF;
+	RETURN NULL;
+END
+$$;
+
+DROP TRIGGER IF EXISTS reviews_product_stats ON reviews;
+CREATE TRIGGER reviews_product_stats
+AFTER INSERT OR DELETE OR UPDATE OF product_id, star, status ON reviews
+FOR EACH ROW EXECUTE FUNCTION reviews_product_stats();
+
+CREATE OR REPLACE FUNCTION order_items_product_stats() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+	IF TG_OP <> 'INSERT' AND OLD.status <> 'Cancelled' AND OLD.deleted_at IS NULL THEN
+		PERFORM product_stats_add(OLD.product_id, 0, 0, -OLD.quantity, 0);
+	END IF;
+	IF TG_OP <> 'DELETE' AND NEW.status <> 'Cancelled' AND NEW.deleted_at IS NULL THEN
+		PERFORM product_stats_add(NEW.product_id, 0, 0, NEW.quantity, 0);
+	END IF;
+	RETURN NULL;
+END
+$$;
+
+DROP TRIGGER IF EXISTS order_items_product_stats ON order_items;
+CREATE TRIGGER order_items_product_stats
+AFTER INSERT OR DELETE OR UPDATE OF product_id, quantity, status, deleted_at ON order_items
+FOR EACH ROW EXECUTE FUNCTION order_items_product_stats();
+
+CREATE OR REPLACE FUNCTION wishlists_product_stats() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+	IF TG_OP <> 'INSERT' THEN
+		PERFORM product_stats_add(OLD.product_id, 0, 0, 0, -1);
+	END IF;
+	IF TG_OP <> 'DELETE' THEN
+		PERFORM product_stats_add(NEW.product_id, 0, 0, 0, 1);
+	END IF;
+	RETURN NULL;
+END
+$$;
+
+DROP TRIGGER IF EXISTS wishlists_product_stats ON wishlists;
+CREATE TRIGGER wishlists_product_stats
+AFTER INSERT OR DELETE OR UPDATE OF product_id ON wishlists
+FOR EACH ROW EXECUTE FUNCTION wishlists_product_stats();
+
+CREATE OR REPLACE FUNCTION product_views_product_stats() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+	IF TG_OP = 'INSERT' THEN
+		INSERT INTO product_stats AS s (product_id, view_count, updated_at)
+		VALUES (NEW.product_id, 1, NOW())
+		ON CONFLICT (product_id) DO UPDATE SET view_count = s.view_count + 1, updated_at = NOW();
+	ELSE
+		UPDATE product_stats SET view_count = view_count - 1, updated_at = NOW() WHERE product_id = OLD.product_id;
+	END IF;
+	RETURN NULL;
+END
+$$;
+
+DROP TRIGGER IF EXISTS product_views_product_stats ON product_views;
+CREATE TRIGGER product_views_product_stats
+AFTER INSERT OR DELETE ON product_views
+FOR EACH ROW EXECUTE FUNCTION product_views_product_stats();
+
+INSERT INTO product_stats (product_id, rating_count, rating_sum, rating_average, units_sold, wishlist_count, view_count, updated_at)
+SELECT e.product_id, e.rating_count, e.rating_sum, e.rating_average, e.units_sold, e.wishlist_count, e.view_count, NOW()
+FROM product_stats_expected e
+WHERE NOT EXISTS (SELECT 1 FROM product_stats s WHERE s.product_id = e.product_id);
+`
+
+// MigrateProductStats creates the view, the function and the triggers keeping
+// the product stats, and the stats of the existing products. It runs after the
+// product_stats table is migrated and can run again safely.
+func MigrateProductStats(gormDB *gorm.DB) error {
+	return gormDB.Exec(productStatsMigration).Error
+}
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// stockLedgerMigration makes stock_movements append-only, the stock of a
+// variant is then explained by its movements. The variants in stock before the
+// ledger get an opening movement of their stock. A movement taking the stock of
+// a variant from above its low stock threshold to or below it notifies the
+// merchant, in the transaction of the movement.
+const stockLedgerMigration = `
+CREATE OR REPLACE FUNCTION stock_movements_append_only() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+	RAISE EXCEPTION 'stock_movements is append-only';
+END
+$$;
+
+DROP TRIGGER IF EXISTS stock_movements_append_only ON stock_movements;
+CREATE TRIGGER stock_movements_append_only
+BEFORE UPDATE OR DELETE ON stock_movements
+FOR EACH ROW EXECUTE FUNCTION stock_movements_append_only();
+
+INSERT INTO stock_movements (id, inventory_id, product_id, type, quantity, balance, reason, created_at)
+SELECT gen_random_uuid(), i.id, i.product_id, 'RESTOCK', i.quantity, i.quantity, 'Opening stock', NOW()
+FROM inventories i
+WHERE i.quantity > 0 AND NOT EXISTS (SELECT 1 FROM stock_movements m WHERE m.inventory_id = i.id);
+
+CREATE OR REPLACE FUNCTION stock_movements_low_stock() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+	INSERT INTO notifications (id, user_id, type, title, body, product_id, created_at)
+	SELECT gen_random_uuid(), p.merchent_id, 'LOW_STOCK', 'Low stock',
+		format('%s%s is down to %s in stock', p.product_name, COALESCE(' (' || i.sku || ')', ''), NEW.balance),
+		p.id, NEW.created_at
+	FROM inventories i
+	JOIN products p ON p.id = i.product_id
+	WHERE i.id = NEW.inventory_id
+	AND NEW.balance <= i.low_stock_threshold AND NEW.balance - NEW.quantity > i.low_stock_threshold;
+	RETURN NULL;
+END
+$$;
+
+DROP TRIGGER IF EXISTS stock_movements_low_stock ON stock_movements;
+CREATE TRIGGER stock_movements_low_stock
+AFTER INSERT ON stock_movements
+FOR EACH ROW EXECUTE FUNCTION stock_movements_low_stock();
+`
+
+// MigrateStockLedger creates the triggers keeping the ledger append-only and
+// raising the low stock alerts, and the opening movements. It runs after the stock_movements table is migrated and
+// can run again safely.
+func MigrateStockLedger(gormDB *gorm.DB) error {
+	return gormDB.Exec(stockLedgerMigration).Error
+}
@@ -2,7 +2,10 @@ package database
 
 import (
 	"database/sql"
+	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/akmal4410/gestapo/internal/config"
 	_ "github.com/lib/pq"
@@ -14,9 +17,12 @@ type Storage struct {
 	DB *sql.DB
 }
 
-func NewStorage(database *config.Database) (*Storage, error) {
+// NewStorage connects to the database for the given service.
+// The statement timeout configured for the service is set on every connection, as a safety net
+// for the queries that are not cancelled through their context.
+func NewStorage(database *config.Database, serviceName string) (*Storage, error) {
 	log.Print(database.DBSource)
-	db, err := sql.Open(database.DBDriver, database.DBSource)
+	db, err := sql.Open(database.DBDriver, withStatementTimeout(database.DBSource, statementTimeout(database, serviceName)))
 	if err != nil {
 		return nil, err
 	}
@@ -34,3 +40,26 @@ func NewStorage(database *config.Database) (*Storage, error) {
 	AutoMigrateTables(gormDB)
 	return &Storage{DB: db}, nil
 }
+
+func statementTimeout(database *config.Database, serviceName string) time.Duration {
+	if timeout, ok := database.StatementTimeouts[serviceName]; ok {
+		return timeout
+	}
+	return database.StatementTimeout
+}
+
+// withStatementTimeout adds the statement_timeout run-time parameter to the data source name.
+// lib/pq sends the parameters it doesn't know to the server, in both URL and key=value forms.
+func withStatementTimeout(source string, timeout time.Duration) string {
+	if timeout <= 0 {
+		return source
+	}
+	param := fmt.Sprintf("statement_timeout=%d", timeout.Milliseconds())
+	if strings.HasPrefix(source, "postgres://") || strings.HasPrefix(source, "postgresql://") {
+		if strings.Contains(source, "?") {
+			return source + "&" + param
+		}
+		return source + "?" + param
+	}
+	return source + " " + param
+}
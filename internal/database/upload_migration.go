@@ -0,0 +1,19 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// uploadPurposesMigration lets the upload sessions hold review photos and category
+// images. The check constraint created with the table is not updated by the auto migration.
+const uploadPurposesMigration = `
+ALTER TABLE upload_sessions DROP CONSTRAINT IF EXISTS chk_upload_sessions_purpose;
+ALTER TABLE upload_sessions ADD CONSTRAINT chk_upload_sessions_purpose
+CHECK (purpose = 'PRODUCT_IMAGE' OR purpose = 'PROFILE_IMAGE' OR purpose = 'REVIEW_IMAGE' OR purpose = 'CATEGORY_IMAGE');
+`
+
+// MigrateUploadPurposes updates the purposes of the upload sessions. It runs after
+// the upload_sessions table is migrated and can run again safely.
+func MigrateUploadPurposes(gormDB *gorm.DB) error {
+	return gormDB.Exec(uploadPurposesMigration).Error
+}
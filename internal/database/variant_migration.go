@@ -0,0 +1,23 @@
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// productVariantMigration turns the sizes of the products added before the
+// variants into the size option of their inventories, so every inventory is a
+// variant with options. The variants added since then are left as they are.
+const productVariantMigration = `
+UPDATE products SET option_types = '{size}'
+WHERE option_types IS NULL AND EXISTS (SELECT 1 FROM inventories i WHERE i.product_id = products.id);
+
+UPDATE inventories SET options = jsonb_build_object('size', size::text)
+WHERE options = '{}'::jsonb AND product_id IN (SELECT id FROM products WHERE option_types = '{size}');
+`
+
+// MigrateProductVariants fills the option types and the options of the products
+// created before the variants. It runs after the products and the inventories
+// tables are migrated and can run again safely.
+func MigrateProductVariants(gormDB *gorm.DB) error {
+	return gormDB.Exec(productVariantMigration).Error
+}
@@ -26,9 +26,21 @@ type User_Data struct {
 
 // PRODUCTS------------------------------
 
+// Categories form a tree: Path holds the ids from the root down to the
+// category, separated by "/", so a subtree is the categories whose path starts
+// with the path of its root. Position orders the children of a parent and
+// Attributes is the jsonb schema of the attributes of its products. The names
+// are unique among the children of a parent.
 type Categories struct {
-	ID            uuid.UUID `gorm:"NOT NULL;PRIMARY_KEY"`
-	Category_Name string    `gorm:"NOT NULL;UNIQUE"`
+	ID            uuid.UUID   `gorm:"NOT NULL;PRIMARY_KEY"`
+	Parent        *Categories `gorm:"foreignKey:ParentID;references:ID"`
+	ParentID      *uuid.UUID  `gorm:"index"`
+	Path          string      `gorm:"NOT NULL;default:'';index"`
+	Position      int         `gorm:"NOT NULL;default:0"`
+	Category_Name string      `gorm:"NOT NULL"`
+	Image         *string
+	Attributes    string `gorm:"type:jsonb;NOT NULL;default:'[]'"`
+	ArchivedAt    *time.Time
 	CreatedAt     time.Time `gorm:"NOT NULL"`
 	UpdatedAt     time.Time `gorm:"NOT NULL"`
 	DeletedAt     gorm.DeletedAt
@@ -45,10 +57,21 @@ type Products struct {
 	Description string          `gorm:"NOT NULL"`
 	Images      pq.StringArray  `gorm:"type:text[]"`
 	Size        pq.Float64Array `gorm:"type:float[]"`
+	OptionTypes pq.StringArray  `gorm:"type:text[]"`
 	Price       float64         `gorm:"NOT NULL"`
+	Attributes  string          `gorm:"type:jsonb;NOT NULL;default:'{}'"`
 	CreatedAt   time.Time       `gorm:"NOT NULL"`
 	UpdatedAt   time.Time       `gorm:"NOT NULL"`
 	DeletedAt   gorm.DeletedAt
+	// SearchVector is kept up to date by a trigger, see MigrateProductSearch.
+	SearchVector string `gorm:"type:tsvector;index:idx_products_search,type:gin;->"`
+
+	// Status is the lifecycle of the product. Only the published products are
+	// listed to the users, within their schedule, see MigrateProductLifecycle.
+	Status       string `gorm:"NOT NULL;default:'PUBLISHED';index;CHECK:status = 'DRAFT' OR status = 'PENDING_REVIEW' OR status = 'PUBLISHED' OR status = 'ARCHIVED'"`
+	StatusReason *string
+	PublishAt    *time.Time
+	UnpublishAt  *time.Time `gorm:"index"`
 }
 
 type Discounts struct {
@@ -63,14 +86,39 @@ type Discounts struct {
 	CreatedAt   time.Time `gorm:"NOT NULL"`
 	UpdatedAt   time.Time `gorm:"NOT NULL"`
 }
+
+// Inventories are the variants of the products, one row for each combination
+// of options. Size is the size option, 0 for the products without one, and
+// Price overrides the price of the product when it is set. The merchant is
+// alerted when Quantity falls to LowStockThreshold.
 type Inventories struct {
-	ID        uuid.UUID `gorm:"NOT NULL;PRIMARY_KEY"`
-	Product   Products  `gorm:"foreignKey:ProductID;references:ID"`
-	ProductID uuid.UUID `gorm:"NOT NULL;index"`
-	Size      float64   `gorm:"NOT NULL"`
-	Quantity  int       `gorm:"NOT NULL"`
-	CreatedAt time.Time `gorm:"NOT NULL"`
-	UpdatedAt time.Time `gorm:"NOT NULL"`
+	ID                uuid.UUID `gorm:"NOT NULL;PRIMARY_KEY"`
+	Product           Products  `gorm:"foreignKey:ProductID;references:ID"`
+	ProductID         uuid.UUID `gorm:"NOT NULL;index"`
+	SKU               *string   `gorm:"UNIQUE"`
+	Options           string    `gorm:"type:jsonb;NOT NULL;DEFAULT:'{}'"`
+	Size              float64   `gorm:"NOT NULL"`
+	Price             *float64
+	Images            pq.StringArray `gorm:"type:text[]"`
+	Quantity          int            `gorm:"NOT NULL"`
+	LowStockThreshold *int
+	CreatedAt         time.Time `gorm:"NOT NULL"`
+	UpdatedAt         time.Time `gorm:"NOT NULL"`
+}
+
+// Stock_Movements is the append-only ledger of the stock of the variants:
+// Quantity is the change of the stock and Balance the stock after it.
+type Stock_Movements struct {
+	ID          uuid.UUID `gorm:"NOT NULL;PRIMARY_KEY"`
+	InventoryID uuid.UUID `gorm:"NOT NULL;index"`
+	ProductID   uuid.UUID `gorm:"NOT NULL;index"`
+	Type        string    `gorm:"NOT NULL;CHECK:type = 'SALE' OR type = 'RESTOCK' OR type = 'RETURN' OR type = 'ADJUSTMENT'"`
+	Quantity    int       `gorm:"NOT NULL"`
+	Balance     int       `gorm:"NOT NULL"`
+	Reason      string
+	OrderItemID *uuid.UUID `gorm:"index"`
+	UserID      *uuid.UUID
+	CreatedAt   time.Time `gorm:"NOT NULL"`
 }
 
 type Wishlists struct {
@@ -83,15 +131,21 @@ type Wishlists struct {
 	UpdatedAt time.Time `gorm:"NOT NULL"`
 }
 
+// Carts are the carts of the users, or of the devices of the guests until they
+// sign in and the guest cart is merged into the one of the user. A user or a
+// device has one cart, see MigrateCarts for the unique indexes.
 type Carts struct {
 	ID        uuid.UUID `gorm:"NOT NULL;PRIMARY_KEY"`
 	User      User_Data `gorm:"foreignKey:UserID;references:ID"`
-	UserID    uuid.UUID `gorm:"NOT NULL"`
+	UserID    *uuid.UUID
+	DeviceID  *string
 	Price     float64   `gorm:"NOT NULL"`
 	CreatedAt time.Time `gorm:"NOT NULL"`
 	UpdatedAt time.Time `gorm:"NOT NULL"`
 }
 
+// Cart_Items are the variants in a cart, a variant is in a cart once and adding
+// it again adds to its quantity.
 type Cart_Items struct {
 	ID          uuid.UUID   `gorm:"NOT NULL;PRIMARY_KEY"`
 	Cart        Carts       `gorm:"foreignKey:CartID;references:ID"`
@@ -159,19 +213,24 @@ type Order_Details struct {
 	DeletedAt gorm.DeletedAt
 }
 
+// Order_Items keep the SKU and the options of the variant ordered, the variant
+// itself may change or go away after the order.
 type Order_Items struct {
-	ID        uuid.UUID     `gorm:"NOT NULL;PRIMARY_KEY"`
-	Order     Order_Details `gorm:"foreignKey:OrderID;references:ID"`
-	OrderID   uuid.UUID     `gorm:"NOT NULL"`
-	Product   Products      `gorm:"foreignKey:ProductID;references:ID"`
-	ProductID uuid.UUID     `gorm:"NOT NULL;index"`
-	Size      float64       `gorm:"NOT NULL"`
-	Quantity  int           `gorm:"NOT NULL"`
-	Amount    float64       `gorm:"NOT NULL"`
-	Status    string        `gorm:"NOT NULL"`
-	CreatedAt time.Time     `gorm:"NOT NULL"`
-	UpdatedAt time.Time     `gorm:"NOT NULL"`
-	DeletedAt gorm.DeletedAt
+	ID          uuid.UUID     `gorm:"NOT NULL;PRIMARY_KEY"`
+	Order       Order_Details `gorm:"foreignKey:OrderID;references:ID"`
+	OrderID     uuid.UUID     `gorm:"NOT NULL"`
+	Product     Products      `gorm:"foreignKey:ProductID;references:ID"`
+	ProductID   uuid.UUID     `gorm:"NOT NULL;index"`
+	InventoryID *uuid.UUID
+	SKU         *string
+	Options     string    `gorm:"type:jsonb;NOT NULL;DEFAULT:'{}'"`
+	Size        float64   `gorm:"NOT NULL"`
+	Quantity    int       `gorm:"NOT NULL"`
+	Amount      float64   `gorm:"NOT NULL"`
+	Status      string    `gorm:"NOT NULL"`
+	CreatedAt   time.Time `gorm:"NOT NULL"`
+	UpdatedAt   time.Time `gorm:"NOT NULL"`
+	DeletedAt   gorm.DeletedAt
 }
 
 type Tracking_Details struct {
@@ -194,14 +253,245 @@ type Tracking_Items struct {
 	UpdatedAt      time.Time        `gorm:"NOT NULL"`
 }
 
+// Reviews hold the photos of the review and the one public reply of the merchant
+// of the product. EditedAt is set when the author edits the review. Only the
+// PUBLISHED reviews are listed and rate the product, the PENDING ones wait in the
+// moderation queue for the Flags of the automatic checks or the reports.
 type Reviews struct {
+	ID             uuid.UUID      `gorm:"NOT NULL;PRIMARY_KEY"`
+	Product        Products       `gorm:"foreignKey:ProductID;references:ID"`
+	ProductID      uuid.UUID      `gorm:"NOT NULL;index"`
+	User           User_Data      `gorm:"foreignKey:UserID;references:ID"`
+	UserID         uuid.UUID      `gorm:"NOT NULL"`
+	Star           float32        `gorm:"NOT NULL"`
+	Review         string         `gorm:"NOT NULL"`
+	Images         pq.StringArray `gorm:"type:text[];NOT NULL;default:'{}'"`
+	Reply          *string
+	RepliedAt      *time.Time
+	ReplyUpdatedAt *time.Time
+	EditedAt       *time.Time
+	Status         string         `gorm:"NOT NULL;default:'PUBLISHED';index;CHECK:status = 'PUBLISHED' OR status = 'PENDING' OR status = 'HIDDEN'"`
+	Flags          pq.StringArray `gorm:"type:text[];NOT NULL;default:'{}'"`
+	// ModerationReason is the reason the admin ModeratedBy gave for the last moderation
+	ModerationReason *string
+	ModeratedBy      *uuid.UUID
+	ModeratedAt      *time.Time
+	CreatedAt        time.Time `gorm:"NOT NULL"`
+	UpdatedAt        time.Time `gorm:"NOT NULL"`
+}
+
+// Review_Reports are the reports of the users on the reviews, one per user and
+// review. A report is open until an admin moderates the review.
+type Review_Reports struct {
+	ID         uuid.UUID `gorm:"NOT NULL;PRIMARY_KEY"`
+	Review     Reviews   `gorm:"foreignKey:ReviewID;references:ID;constraint:OnDelete:CASCADE"`
+	ReviewID   uuid.UUID `gorm:"NOT NULL;uniqueIndex:idx_review_reports_review_user"`
+	User       User_Data `gorm:"foreignKey:UserID;references:ID"`
+	UserID     uuid.UUID `gorm:"NOT NULL;uniqueIndex:idx_review_reports_review_user"`
+	Reason     string    `gorm:"NOT NULL"`
+	Details    string    `gorm:"NOT NULL;default:''"`
+	ResolvedAt *time.Time
+	CreatedAt  time.Time `gorm:"NOT NULL"`
+	UpdatedAt  time.Time `gorm:"NOT NULL"`
+}
+
+// Review_Votes are the helpful and not helpful votes of the users on the
+// reviews, one per user and review.
+type Review_Votes struct {
 	ID        uuid.UUID `gorm:"NOT NULL;PRIMARY_KEY"`
-	Product   Products  `gorm:"foreignKey:ProductID;references:ID"`
-	ProductID uuid.UUID `gorm:"NOT NULL;index"`
+	Review    Reviews   `gorm:"foreignKey:ReviewID;references:ID;constraint:OnDelete:CASCADE"`
+	ReviewID  uuid.UUID `gorm:"NOT NULL;uniqueIndex:idx_review_votes_review_user"`
 	User      User_Data `gorm:"foreignKey:UserID;references:ID"`
-	UserID    uuid.UUID `gorm:"NOT NULL"`
-	Star      float32   `gorm:"NOT NULL"`
-	Review    string    `gorm:"NOT NULL"`
+	UserID    uuid.UUID `gorm:"NOT NULL;uniqueIndex:idx_review_votes_review_user"`
+	Helpful   bool      `gorm:"NOT NULL"`
 	CreatedAt time.Time `gorm:"NOT NULL"`
 	UpdatedAt time.Time `gorm:"NOT NULL"`
 }
+
+// Upload_Sessions are the images the clients upload straight to the object store.
+// A session is PENDING until the upload is verified and ATTACHED to its target.
+type Upload_Sessions struct {
+	ID          uuid.UUID `gorm:"NOT NULL;PRIMARY_KEY"`
+	User        User_Data `gorm:"foreignKey:UserID;references:ID"`
+	UserID      uuid.UUID `gorm:"NOT NULL;index"`
+	Purpose     string    `gorm:"NOT NULL;CHECK:purpose = 'PRODUCT_IMAGE' OR purpose = 'PROFILE_IMAGE' OR purpose = 'REVIEW_IMAGE' OR purpose = 'CATEGORY_IMAGE'"`
+	TargetID    *uuid.UUID
+	Key         string    `gorm:"NOT NULL;UNIQUE"`
+	ContentType string    `gorm:"NOT NULL"`
+	Size        int64     `gorm:"NOT NULL"`
+	Status      string    `gorm:"NOT NULL;index;CHECK:status = 'PENDING' OR status = 'ATTACHED'"`
+	ExpiresAt   time.Time `gorm:"NOT NULL;index"`
+	CreatedAt   time.Time `gorm:"NOT NULL"`
+	UpdatedAt   time.Time `gorm:"NOT NULL"`
+}
+
+// Search_Queries log the product searches, to list the trending searches and
+// the queries finding nothing. CategoryID is the category searched, or the one
+// of the best match.
+type Search_Queries struct {
+	ID         uuid.UUID  `gorm:"NOT NULL;PRIMARY_KEY"`
+	Query      string     `gorm:"NOT NULL;index"`
+	UserID     *uuid.UUID `gorm:"index"`
+	CategoryID *uuid.UUID `gorm:"index"`
+	Results    int64      `gorm:"NOT NULL"`
+	CreatedAt  time.Time  `gorm:"NOT NULL;index"`
+}
+
+// Import_Jobs are the bulk imports of the catalog of the merchants, Errors
+// holds the validation errors of the rows as jsonb.
+type Import_Jobs struct {
+	ID              uuid.UUID `gorm:"NOT NULL;PRIMARY_KEY"`
+	Merchant        User_Data `gorm:"foreignKey:MerchantID;references:ID"`
+	MerchantID      uuid.UUID `gorm:"NOT NULL;index"`
+	Format          string    `gorm:"NOT NULL;CHECK:format = 'CSV' OR format = 'JSON'"`
+	DryRun          bool      `gorm:"NOT NULL"`
+	Status          string    `gorm:"NOT NULL;CHECK:status = 'PENDING' OR status = 'RUNNING' OR status = 'COMPLETED' OR status = 'FAILED'"`
+	TotalRows       int       `gorm:"NOT NULL"`
+	ProcessedRows   int       `gorm:"NOT NULL;default:0"`
+	CreatedProducts int       `gorm:"NOT NULL;default:0"`
+	FailedRows      int       `gorm:"NOT NULL;default:0"`
+	Errors          string    `gorm:"type:jsonb;NOT NULL;default:'[]'"`
+	FinishedAt      *time.Time
+	CreatedAt       time.Time `gorm:"NOT NULL"`
+	UpdatedAt       time.Time `gorm:"NOT NULL"`
+}
+
+// Product_Stats are the aggregates of a product read by the product listings:
+// the published reviews, the units of the order items not cancelled, the
+// wishlists and the views. They are kept up to date by triggers, see
+// MigrateProductStats.
+type Product_Stats struct {
+	Product       Products  `gorm:"foreignKey:ProductID;references:ID;constraint:OnDelete:CASCADE"`
+	ProductID     uuid.UUID `gorm:"NOT NULL;PRIMARY_KEY"`
+	RatingCount   int64     `gorm:"NOT NULL;default:0"`
+	RatingSum     float64   `gorm:"NOT NULL;default:0"`
+	RatingAverage *float64
+	UnitsSold     int64     `gorm:"NOT NULL;default:0;index"`
+	WishlistCount int64     `gorm:"NOT NULL;default:0"`
+	ViewCount     int64     `gorm:"NOT NULL;default:0"`
+	UpdatedAt     time.Time `gorm:"NOT NULL"`
+}
+
+// Product_Recommendations are the products bought or kept together with a
+// product: Score adds up the orders, the carts and the wishlists holding both,
+// weighted by the signal. They are recomputed by the recommender of the
+// product service, keeping the best ones of each product.
+type Product_Recommendations struct {
+	Product   Products  `gorm:"foreignKey:ProductID;references:ID;constraint:OnDelete:CASCADE"`
+	ProductID uuid.UUID `gorm:"NOT NULL;PRIMARY_KEY"`
+	Related   Products  `gorm:"foreignKey:RelatedID;references:ID;constraint:OnDelete:CASCADE"`
+	RelatedID uuid.UUID `gorm:"NOT NULL;PRIMARY_KEY"`
+	Score     float64   `gorm:"NOT NULL"`
+	UpdatedAt time.Time `gorm:"NOT NULL"`
+}
+
+// Product_Views are the views of the product pages, by a user or by the device
+// of a guest. A viewer viewing a product again shortly after moves its last view
+// instead of adding one. Clearing the history removes the viewer from the views,
+// which are still counted.
+type Product_Views struct {
+	ID        uuid.UUID  `gorm:"NOT NULL;PRIMARY_KEY"`
+	Product   Products   `gorm:"foreignKey:ProductID;references:ID;constraint:OnDelete:CASCADE"`
+	ProductID uuid.UUID  `gorm:"NOT NULL;index:idx_product_views_product,priority:1"`
+	UserID    *uuid.UUID `gorm:"index:idx_product_views_user,priority:1"`
+	DeviceID  *string    `gorm:"index:idx_product_views_device,priority:1"`
+	ViewedAt  time.Time  `gorm:"NOT NULL;index:idx_product_views_product,priority:2;index:idx_product_views_user,priority:2;index:idx_product_views_device,priority:2"`
+}
+
+// Price_Histories are the prices of a product over time: Price is the price of
+// the product, DiscountPercent the running discount and EffectivePrice the price
+// after it. A row is added by triggers when the price or the discount changes,
+// and by the price tracker of the product service when a discount ends, see
+// MigratePriceHistory. AlertCheckedAt is set once the users wishlisting the
+// product were notified of a price drop, if any.
+type Price_Histories struct {
+	ID              uuid.UUID `gorm:"NOT NULL;PRIMARY_KEY"`
+	Product         Products  `gorm:"foreignKey:ProductID;references:ID;constraint:OnDelete:CASCADE"`
+	ProductID       uuid.UUID `gorm:"NOT NULL;index:idx_price_histories_product,priority:1"`
+	Price           float64   `gorm:"NOT NULL"`
+	DiscountPercent *float64
+	EffectivePrice  float64   `gorm:"NOT NULL"`
+	ChangedAt       time.Time `gorm:"NOT NULL;index:idx_price_histories_product,priority:2"`
+	AlertCheckedAt  *time.Time
+}
+
+// Notifications are the in-app notifications of a user, like the price drops
+// of the wishlisted products. ProductID is the product it is about, if any.
+type Notifications struct {
+	ID        uuid.UUID `gorm:"NOT NULL;PRIMARY_KEY"`
+	User      User_Data `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE"`
+	UserID    uuid.UUID `gorm:"NOT NULL;index:idx_notifications_user,priority:1"`
+	Type      string    `gorm:"NOT NULL"`
+	Title     string    `gorm:"NOT NULL"`
+	Body      string    `gorm:"NOT NULL"`
+	Product   *Products `gorm:"foreignKey:ProductID;references:ID;constraint:OnDelete:CASCADE"`
+	ProductID *uuid.UUID
+	ReadAt    *time.Time
+	CreatedAt time.Time `gorm:"NOT NULL;index:idx_notifications_user,priority:2"`
+}
+
+// Stock_Subscriptions are the users waiting for a sold out variant. They are
+// notified in batches, the oldest subscriptions first, once the variant is back
+// in stock, and NotifiedAt is set. Subscribing again after a notification goes
+// back to the end of the queue.
+type Stock_Subscriptions struct {
+	ID          uuid.UUID   `gorm:"NOT NULL;PRIMARY_KEY"`
+	User        User_Data   `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE"`
+	UserID      uuid.UUID   `gorm:"NOT NULL;uniqueIndex:idx_stock_subscriptions_inventory_user,priority:2"`
+	Product     Products    `gorm:"foreignKey:ProductID;references:ID;constraint:OnDelete:CASCADE"`
+	ProductID   uuid.UUID   `gorm:"NOT NULL"`
+	Inventory   Inventories `gorm:"foreignKey:InventoryID;references:ID;constraint:OnDelete:CASCADE"`
+	InventoryID uuid.UUID   `gorm:"NOT NULL;uniqueIndex:idx_stock_subscriptions_inventory_user,priority:1;index:idx_stock_subscriptions_queue,priority:1"`
+	CreatedAt   time.Time   `gorm:"NOT NULL;index:idx_stock_subscriptions_queue,priority:2"`
+	NotifiedAt  *time.Time
+}
+
+// Product_Questions are the questions of the users on the products. Like the
+// reviews, a question failing the automatic checks is PENDING until an admin
+// moderates it, and only the PUBLISHED ones are listed.
+type Product_Questions struct {
+	ID        uuid.UUID      `gorm:"NOT NULL;PRIMARY_KEY"`
+	Product   Products       `gorm:"foreignKey:ProductID;references:ID;constraint:OnDelete:CASCADE"`
+	ProductID uuid.UUID      `gorm:"NOT NULL;index:idx_product_questions_product,priority:1"`
+	User      User_Data      `gorm:"foreignKey:UserID;references:ID"`
+	UserID    uuid.UUID      `gorm:"NOT NULL"`
+	Question  string         `gorm:"NOT NULL"`
+	Status    string         `gorm:"NOT NULL;default:'PUBLISHED';index;CHECK:status = 'PUBLISHED' OR status = 'PENDING' OR status = 'HIDDEN'"`
+	Flags     pq.StringArray `gorm:"type:text[];NOT NULL;default:'{}'"`
+	// ModerationReason is the reason the admin ModeratedBy gave for the last moderation
+	ModerationReason *string
+	ModeratedBy      *uuid.UUID
+	ModeratedAt      *time.Time
+	CreatedAt        time.Time `gorm:"NOT NULL;index:idx_product_questions_product,priority:2"`
+	UpdatedAt        time.Time `gorm:"NOT NULL"`
+}
+
+// Product_Answers are the answers to the questions, by the merchant of the
+// product or by the users who bought it. They are moderated like the questions.
+type Product_Answers struct {
+	ID         uuid.UUID         `gorm:"NOT NULL;PRIMARY_KEY"`
+	Question   Product_Questions `gorm:"foreignKey:QuestionID;references:ID;constraint:OnDelete:CASCADE"`
+	QuestionID uuid.UUID         `gorm:"NOT NULL;index"`
+	User       User_Data         `gorm:"foreignKey:UserID;references:ID"`
+	UserID     uuid.UUID         `gorm:"NOT NULL"`
+	Answer     string            `gorm:"NOT NULL"`
+	// ByMerchant tells the answer is the one of the merchant, the others are of verified buyers
+	ByMerchant       bool           `gorm:"NOT NULL"`
+	Status           string         `gorm:"NOT NULL;default:'PUBLISHED';index;CHECK:status = 'PUBLISHED' OR status = 'PENDING' OR status = 'HIDDEN'"`
+	Flags            pq.StringArray `gorm:"type:text[];NOT NULL;default:'{}'"`
+	ModerationReason *string
+	ModeratedBy      *uuid.UUID
+	ModeratedAt      *time.Time
+	CreatedAt        time.Time `gorm:"NOT NULL"`
+	UpdatedAt        time.Time `gorm:"NOT NULL"`
+}
+
+// Answer_Votes are the upvotes of the users on the answers, one per user and answer.
+type Answer_Votes struct {
+	ID        uuid.UUID       `gorm:"NOT NULL;PRIMARY_KEY"`
+	Answer    Product_Answers `gorm:"foreignKey:AnswerID;references:ID;constraint:OnDelete:CASCADE"`
+	AnswerID  uuid.UUID       `gorm:"NOT NULL;uniqueIndex:idx_answer_votes_answer_user"`
+	User      User_Data       `gorm:"foreignKey:UserID;references:ID"`
+	UserID    uuid.UUID       `gorm:"NOT NULL;uniqueIndex:idx_answer_votes_answer_user"`
+	CreatedAt time.Time       `gorm:"NOT NULL"`
+}
@@ -0,0 +1,243 @@
+package testenv
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/akmal4410/gestapo/internal/database/memory"
+	"github.com/akmal4410/gestapo/pkg/service/password"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+// Password of the users added by AddUser.
+const Password = "password123"
+
+// AddUser adds a user of the given type and returns it with an access token.
+func (env *Env) AddUser(t testing.TB, userName, userType string) (*memory.User, string) {
+	t.Helper()
+	hashed, err := password.HashPassword(Password)
+	if err != nil {
+		t.Fatalf("HashPassword: %v", err)
+	}
+	email := userName + "@example.com"
+	fullName := userName
+	now := time.Now()
+	user := &memory.User{
+		ID:        uuid.NewString(),
+		FullName:  &fullName,
+		UserName:  userName,
+		Email:     &email,
+		UserType:  userType,
+		Password:  hashed,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	env.DB.Lock()
+	env.DB.Users[user.ID] = user
+	env.DB.Unlock()
+
+	accessToken, err := env.Token.CreateAccessToken(user.ID, user.UserName, user.UserType)
+	if err != nil {
+		t.Fatalf("CreateAccessToken: %v", err)
+	}
+	return user, accessToken
+}
+
+// AddCategory adds a root category.
+func (env *Env) AddCategory(t testing.TB, name string) *memory.Category {
+	t.Helper()
+	return env.AddSubcategory(t, nil, name)
+}
+
+// AddSubcategory adds a child of the parent, or a root category without a parent.
+func (env *Env) AddSubcategory(t testing.TB, parent *memory.Category, name string) *memory.Category {
+	t.Helper()
+	now := time.Now()
+	category := &memory.Category{ID: uuid.NewString(), CategoryName: name, CreatedAt: now, UpdatedAt: now}
+	category.Path = category.ID
+	if parent != nil {
+		category.ParentID = &parent.ID
+		category.Path = parent.Path + "/" + category.ID
+	}
+	env.DB.Lock()
+	env.DB.Categories[category.ID] = category
+	env.DB.Unlock()
+	return category
+}
+
+// AddProduct adds a product of the merchant with one image and the given
+// quantity in stock for every size.
+func (env *Env) AddProduct(t testing.TB, merchantID, categoryID, name string, price float64, quantity int32, sizes ...float64) *memory.Product {
+	t.Helper()
+	now := time.Now()
+	product := &memory.Product{
+		ID:          uuid.NewString(),
+		MerchantID:  merchantID,
+		CategoryID:  categoryID,
+		ProductName: name,
+		Description: name + " description",
+		Images:      []string{"products/" + name + ".jpg"},
+		Size:        sizes,
+		Price:       price,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Status:      utils.ProductPublished,
+	}
+	if len(sizes) != 0 {
+		product.OptionTypes = []string{"size"}
+	}
+	env.DB.Lock()
+	defer env.DB.Unlock()
+	env.DB.Products[product.ID] = product
+	env.DB.RecordPrices(product.ID, now)
+	for _, size := range sizes {
+		id := uuid.NewString()
+		env.DB.Inventories[id] = &memory.Inventory{
+			ID:        id,
+			ProductID: product.ID,
+			Options:   map[string]string{"size": strconv.FormatFloat(size, 'f', -1, 64)},
+			Size:      size,
+			Quantity:  quantity,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+	}
+	return product
+}
+
+// AddVariant adds a variant to the product with the given options, and the price
+// of the product when price is nil. The options missing from the option types of
+// the product are added to them.
+func (env *Env) AddVariant(t testing.TB, product *memory.Product, sku string, options map[string]string, price *float64, quantity int32) *memory.Inventory {
+	t.Helper()
+	now := time.Now()
+	size, _ := strconv.ParseFloat(options["size"], 64)
+	variant := &memory.Inventory{
+		ID:        uuid.NewString(),
+		ProductID: product.ID,
+		SKU:       &sku,
+		Options:   options,
+		Size:      size,
+		Price:     price,
+		Images:    []string{"products/" + sku + ".jpg"},
+		Quantity:  quantity,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	var optionTypes []string
+	for optionType := range options {
+		if !slices.Contains(product.OptionTypes, optionType) {
+			optionTypes = append(optionTypes, optionType)
+		}
+	}
+	slices.Sort(optionTypes)
+	env.DB.Lock()
+	product.OptionTypes = append(product.OptionTypes, optionTypes...)
+	env.DB.Inventories[variant.ID] = variant
+	env.DB.Unlock()
+	return variant
+}
+
+// AddPromoCode adds a promo code.
+func (env *Env) AddPromoCode(t testing.TB, code string, percent float64) *memory.PromoCode {
+	t.Helper()
+	now := time.Now()
+	promo := &memory.PromoCode{
+		ID:          uuid.NewString(),
+		Code:        code,
+		Title:       code,
+		Description: code,
+		Percent:     percent,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	env.DB.Lock()
+	env.DB.PromoCodes[promo.ID] = promo
+	env.DB.Unlock()
+	return promo
+}
+
+// AddOrderItem adds an order of the user holding one item of the first size of the product in
+// the given status, with its tracking at the first step.
+func (env *Env) AddOrderItem(t testing.TB, userID string, product *memory.Product, itemStatus string) *memory.OrderItem {
+	t.Helper()
+	now := time.Now()
+	order := &memory.OrderDetail{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		PaymentID: uuid.NewString(),
+		AddressID: uuid.NewString(),
+		Amount:    product.Price,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	item := &memory.OrderItem{
+		ID:        uuid.NewString(),
+		OrderID:   order.ID,
+		ProductID: product.ID,
+		Size:      product.Size[0],
+		Quantity:  1,
+		Amount:    product.Price,
+		Status:    itemStatus,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	tracking := &memory.TrackingDetail{
+		ID:          uuid.NewString(),
+		OrderItemID: item.ID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	env.DB.Lock()
+	for _, inventory := range env.DB.Variants(product.ID) {
+		if inventory.Size == item.Size {
+			inventoryID := inventory.ID
+			item.InventoryID = &inventoryID
+			break
+		}
+	}
+	env.DB.OrderDetails[order.ID] = order
+	env.DB.OrderItems[item.ID] = item
+	env.DB.TrackingDetails[tracking.ID] = tracking
+	env.DB.Unlock()
+	return item
+}
+
+// AddCartItem adds quantity of the variant to the cart of the user, creating the
+// cart when the user has none, and returns the item.
+func (env *Env) AddCartItem(t testing.TB, userID string, variant *memory.Inventory, quantity int32) *memory.CartItem {
+	t.Helper()
+	now := time.Now()
+	env.DB.Lock()
+	defer env.DB.Unlock()
+	var cart *memory.Cart
+	for _, userCart := range env.DB.Carts {
+		if userCart.UserID == userID {
+			cart = userCart
+		}
+	}
+	if cart == nil {
+		cart = &memory.Cart{ID: uuid.NewString(), UserID: userID, CreatedAt: now, UpdatedAt: now}
+		env.DB.Carts[cart.ID] = cart
+	}
+	price, discountPrice := env.DB.VariantPrice(env.DB.Products[variant.ProductID], variant)
+	if discountPrice != nil {
+		price = *discountPrice
+	}
+	item := &memory.CartItem{
+		ID:          uuid.NewString(),
+		CartID:      cart.ID,
+		ProductID:   variant.ProductID,
+		InventoryID: variant.ID,
+		Quantity:    quantity,
+		Price:       price,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	env.DB.CartItems[item.ID] = item
+	cart.Price += float64(quantity) * price
+	return item
+}
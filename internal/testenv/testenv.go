@@ -0,0 +1,167 @@
+// Package testenv runs the whole marketplace inside a test on top of
+// all_in_one: every gRPC service listens on an in-memory bufconn listener, the
+// stores share one in-memory database and S3, mail, Twilio, Redis and Google
+// sign-in are replaced by fakes.
+package testenv
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/akmal4410/gestapo/internal/config"
+	"github.com/akmal4410/gestapo/internal/database/memory"
+	"github.com/akmal4410/gestapo/pkg/api/proto"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/all_in_one"
+	"github.com/akmal4410/gestapo/pkg/helpers/logger"
+	"github.com/akmal4410/gestapo/pkg/helpers/token"
+	"github.com/akmal4410/gestapo/pkg/service/cache"
+	"github.com/akmal4410/gestapo/pkg/service/mail"
+	objectstore "github.com/akmal4410/gestapo/pkg/service/object_store"
+	"github.com/akmal4410/gestapo/pkg/service/sso"
+	"github.com/akmal4410/gestapo/pkg/service/twilio"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Names of the services, also used as their bufconn addresses.
+const (
+	Authentication = all_in_one.Authentication
+	Admin          = all_in_one.Admin
+	User           = all_in_one.User
+	Merchant       = all_in_one.Merchant
+	Product        = all_in_one.Product
+	Order          = all_in_one.Order
+)
+
+// Client IDs accepted by the SSO fake.
+const (
+	AndroidClientID = "android-client"
+	IOSClientID     = "ios-client"
+)
+
+// Env is a running marketplace. The fakes are exported so tests can seed
+// data and read what the services sent, like the OTP of an email.
+type Env struct {
+	Config *config.Config
+	Log    logger.Logger
+	Token  token.Maker
+
+	DB     *memory.Database
+	Files  *objectstore.MemoryStore
+	Email  *mail.MemoryEmailService
+	Twilio *twilio.MemoryOTPService
+	Cache  cache.Cache
+	SSO    *sso.MemoryVerifier
+
+	marketplace *all_in_one.Marketplace
+}
+
+// New starts every service and stops them when the test ends.
+func New(t testing.TB) *Env {
+	t.Helper()
+	tokenMaker, err := token.NewJWTMaker("01234567890123456789012345678901")
+	if err != nil {
+		t.Fatalf("NewJWTMaker: %v", err)
+	}
+	env := &Env{
+		Log:    logger.NewWriterLogger("testenv", io.Discard),
+		Token:  tokenMaker,
+		DB:     memory.NewDatabase(),
+		Files:  objectstore.NewMemoryStore(),
+		Email:  mail.NewMemoryEmailService(),
+		Twilio: twilio.NewMemoryOTPService(),
+		Cache:  cache.NewMemoryCache(),
+		SSO:    sso.NewMemoryVerifier(),
+	}
+	appConfig := &config.Config{
+		OAuth: &config.OAuth{
+			AndroidClientId: AndroidClientID,
+			IOSClientId:     IOSClientID,
+		},
+		GRPCClient: &config.GRPCClient{Timeout: 5 * time.Second},
+	}
+	env.marketplace = all_in_one.NewMarketplace(appConfig, all_in_one.Dependencies{
+		DB:     env.DB,
+		Files:  env.Files,
+		Email:  env.Email,
+		Twilio: env.Twilio,
+		Cache:  env.Cache,
+		SSO:    env.SSO,
+		// the catalog images are served by httptest servers on loopback
+		ImageClient: &http.Client{Timeout: 30 * time.Second},
+	}, env.Log, env.Token)
+	env.Config = env.marketplace.Config
+	t.Cleanup(env.marketplace.Stop)
+	return env
+}
+
+// DialOptions connect the clients to the bufconn listeners of the services.
+func (env *Env) DialOptions() []grpc.DialOption {
+	return env.marketplace.DialOptions()
+}
+
+// Conn dials the service, the connection is closed when the test ends.
+func (env *Env) Conn(t testing.TB, name string) *grpc.ClientConn {
+	t.Helper()
+	conn, err := env.marketplace.Dial(name)
+	if err != nil {
+		t.Fatalf("dial %s: %v", name, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func (env *Env) AuthClient(t testing.TB) proto.AuthenticationServiceClient {
+	return proto.NewAuthenticationServiceClient(env.Conn(t, Authentication))
+}
+
+func (env *Env) AdminClient(t testing.TB) proto.AdminServiceClient {
+	return proto.NewAdminServiceClient(env.Conn(t, Admin))
+}
+
+func (env *Env) UserClient(t testing.TB) proto.UserServieClient {
+	return proto.NewUserServieClient(env.Conn(t, User))
+}
+
+func (env *Env) MerchantClient(t testing.TB) proto.MerchantServiceClient {
+	return proto.NewMerchantServiceClient(env.Conn(t, Merchant))
+}
+
+func (env *Env) ProductClient(t testing.TB) proto.ProductServiceClient {
+	return proto.NewProductServiceClient(env.Conn(t, Product))
+}
+
+func (env *Env) OrderClient(t testing.TB) proto.OrderServiceClient {
+	return proto.NewOrderServiceClient(env.Conn(t, Order))
+}
+
+// Gateway returns the http handler of the gateway, talking to the services of env.
+func (env *Env) Gateway(t testing.TB) http.Handler {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	handler, err := env.marketplace.Handler(ctx)
+	if err != nil {
+		t.Fatalf("gateway: %v", err)
+	}
+	return handler
+}
+
+// WithToken returns a context sending token as the bearer authorization.
+func WithToken(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, utils.AuthorizationKey, utils.AuthorizationTypeBearer+" "+token)
+}
+
+// WithServiceToken returns a context sending a service token of the user, like the calls between services.
+func (env *Env) WithServiceToken(t testing.TB, ctx context.Context, userID, userType, serviceName string) context.Context {
+	t.Helper()
+	serviceToken, err := env.Token.CreateServiceToken(userID, userType, serviceName)
+	if err != nil {
+		t.Fatalf("CreateServiceToken: %v", err)
+	}
+	return metadata.AppendToOutgoingContext(ctx, token.ServiceToken, utils.AuthorizationTypeBearer+" "+serviceToken)
+}
@@ -19,11 +19,24 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	AdminService_CreateCategory_FullMethodName  = "/pb.AdminService/CreateCategory"
-	AdminService_GetCategories_FullMethodName   = "/pb.AdminService/GetCategories"
-	AdminService_GetUsers_FullMethodName        = "/pb.AdminService/GetUsers"
-	AdminService_CreatePromocode_FullMethodName = "/pb.AdminService/CreatePromocode"
-	AdminService_GetPromocodes_FullMethodName   = "/pb.AdminService/GetPromocodes"
+	AdminService_CreateCategory_FullMethodName       = "/pb.AdminService/CreateCategory"
+	AdminService_GetCategories_FullMethodName        = "/pb.AdminService/GetCategories"
+	AdminService_UpdateCategory_FullMethodName       = "/pb.AdminService/UpdateCategory"
+	AdminService_MoveCategory_FullMethodName         = "/pb.AdminService/MoveCategory"
+	AdminService_ReorderCategories_FullMethodName    = "/pb.AdminService/ReorderCategories"
+	AdminService_ArchiveCategory_FullMethodName      = "/pb.AdminService/ArchiveCategory"
+	AdminService_RestoreCategory_FullMethodName      = "/pb.AdminService/RestoreCategory"
+	AdminService_GetUsers_FullMethodName             = "/pb.AdminService/GetUsers"
+	AdminService_CreatePromocode_FullMethodName      = "/pb.AdminService/CreatePromocode"
+	AdminService_GetPromocodes_FullMethodName        = "/pb.AdminService/GetPromocodes"
+	AdminService_GetZeroResultQueries_FullMethodName = "/pb.AdminService/GetZeroResultQueries"
+	AdminService_GetReviewQueue_FullMethodName       = "/pb.AdminService/GetReviewQueue"
+	AdminService_ModerateReview_FullMethodName       = "/pb.AdminService/ModerateReview"
+	AdminService_GetProductQueue_FullMethodName      = "/pb.AdminService/GetProductQueue"
+	AdminService_ReviewProduct_FullMethodName        = "/pb.AdminService/ReviewProduct"
+	AdminService_GetQnAQueue_FullMethodName          = "/pb.AdminService/GetQnAQueue"
+	AdminService_ModerateQuestion_FullMethodName     = "/pb.AdminService/ModerateQuestion"
+	AdminService_ModerateAnswer_FullMethodName       = "/pb.AdminService/ModerateAnswer"
 )
 
 // AdminServiceClient is the client API for AdminService service.
@@ -31,10 +44,23 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type AdminServiceClient interface {
 	CreateCategory(ctx context.Context, in *AddCategoryRequest, opts ...grpc.CallOption) (*Response, error)
-	GetCategories(ctx context.Context, in *Request, opts ...grpc.CallOption) (*GetCategoryResponse, error)
+	GetCategories(ctx context.Context, in *GetCategoriesRequest, opts ...grpc.CallOption) (*GetCategoryResponse, error)
+	UpdateCategory(ctx context.Context, in *UpdateCategoryRequest, opts ...grpc.CallOption) (*Response, error)
+	MoveCategory(ctx context.Context, in *MoveCategoryRequest, opts ...grpc.CallOption) (*Response, error)
+	ReorderCategories(ctx context.Context, in *ReorderCategoriesRequest, opts ...grpc.CallOption) (*Response, error)
+	ArchiveCategory(ctx context.Context, in *CategoryIdRequest, opts ...grpc.CallOption) (*Response, error)
+	RestoreCategory(ctx context.Context, in *CategoryIdRequest, opts ...grpc.CallOption) (*Response, error)
 	GetUsers(ctx context.Context, in *Request, opts ...grpc.CallOption) (*GetUsersResponse, error)
 	CreatePromocode(ctx context.Context, in *CreatePromocodeRequest, opts ...grpc.CallOption) (*Response, error)
 	GetPromocodes(ctx context.Context, in *Request, opts ...grpc.CallOption) (*GetPromocodeResponse, error)
+	GetZeroResultQueries(ctx context.Context, in *GetZeroResultQueriesRequest, opts ...grpc.CallOption) (*GetZeroResultQueriesResponse, error)
+	GetReviewQueue(ctx context.Context, in *GetReviewQueueRequest, opts ...grpc.CallOption) (*GetReviewQueueResponse, error)
+	ModerateReview(ctx context.Context, in *ModerateReviewRequest, opts ...grpc.CallOption) (*Response, error)
+	GetProductQueue(ctx context.Context, in *GetProductQueueRequest, opts ...grpc.CallOption) (*GetProductQueueResponse, error)
+	ReviewProduct(ctx context.Context, in *ReviewProductRequest, opts ...grpc.CallOption) (*Response, error)
+	GetQnAQueue(ctx context.Context, in *GetQnAQueueRequest, opts ...grpc.CallOption) (*GetQnAQueueResponse, error)
+	ModerateQuestion(ctx context.Context, in *ModerateQnARequest, opts ...grpc.CallOption) (*Response, error)
+	ModerateAnswer(ctx context.Context, in *ModerateQnARequest, opts ...grpc.CallOption) (*Response, error)
 }
 
 type adminServiceClient struct {
@@ -54,7 +80,7 @@ func (c *adminServiceClient) CreateCategory(ctx context.Context, in *AddCategory
 	return out, nil
 }
 
-func (c *adminServiceClient) GetCategories(ctx context.Context, in *Request, opts ...grpc.CallOption) (*GetCategoryResponse, error) {
+func (c *adminServiceClient) GetCategories(ctx context.Context, in *GetCategoriesRequest, opts ...grpc.CallOption) (*GetCategoryResponse, error) {
 	out := new(GetCategoryResponse)
 	err := c.cc.Invoke(ctx, AdminService_GetCategories_FullMethodName, in, out, opts...)
 	if err != nil {
@@ -63,6 +89,51 @@ func (c *adminServiceClient) GetCategories(ctx context.Context, in *Request, opt
 	return out, nil
 }
 
+func (c *adminServiceClient) UpdateCategory(ctx context.Context, in *UpdateCategoryRequest, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, AdminService_UpdateCategory_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) MoveCategory(ctx context.Context, in *MoveCategoryRequest, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, AdminService_MoveCategory_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) ReorderCategories(ctx context.Context, in *ReorderCategoriesRequest, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, AdminService_ReorderCategories_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) ArchiveCategory(ctx context.Context, in *CategoryIdRequest, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, AdminService_ArchiveCategory_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) RestoreCategory(ctx context.Context, in *CategoryIdRequest, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, AdminService_RestoreCategory_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *adminServiceClient) GetUsers(ctx context.Context, in *Request, opts ...grpc.CallOption) (*GetUsersResponse, error) {
 	out := new(GetUsersResponse)
 	err := c.cc.Invoke(ctx, AdminService_GetUsers_FullMethodName, in, out, opts...)
@@ -90,15 +161,100 @@ func (c *adminServiceClient) GetPromocodes(ctx context.Context, in *Request, opt
 	return out, nil
 }
 
+func (c *adminServiceClient) GetZeroResultQueries(ctx context.Context, in *GetZeroResultQueriesRequest, opts ...grpc.CallOption) (*GetZeroResultQueriesResponse, error) {
+	out := new(GetZeroResultQueriesResponse)
+	err := c.cc.Invoke(ctx, AdminService_GetZeroResultQueries_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) GetReviewQueue(ctx context.Context, in *GetReviewQueueRequest, opts ...grpc.CallOption) (*GetReviewQueueResponse, error) {
+	out := new(GetReviewQueueResponse)
+	err := c.cc.Invoke(ctx, AdminService_GetReviewQueue_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) ModerateReview(ctx context.Context, in *ModerateReviewRequest, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, AdminService_ModerateReview_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) GetProductQueue(ctx context.Context, in *GetProductQueueRequest, opts ...grpc.CallOption) (*GetProductQueueResponse, error) {
+	out := new(GetProductQueueResponse)
+	err := c.cc.Invoke(ctx, AdminService_GetProductQueue_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) ReviewProduct(ctx context.Context, in *ReviewProductRequest, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, AdminService_ReviewProduct_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) GetQnAQueue(ctx context.Context, in *GetQnAQueueRequest, opts ...grpc.CallOption) (*GetQnAQueueResponse, error) {
+	out := new(GetQnAQueueResponse)
+	err := c.cc.Invoke(ctx, AdminService_GetQnAQueue_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) ModerateQuestion(ctx context.Context, in *ModerateQnARequest, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, AdminService_ModerateQuestion_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) ModerateAnswer(ctx context.Context, in *ModerateQnARequest, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, AdminService_ModerateAnswer_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AdminServiceServer is the server API for AdminService service.
 // All implementations must embed UnimplementedAdminServiceServer
 // for forward compatibility
 type AdminServiceServer interface {
 	CreateCategory(context.Context, *AddCategoryRequest) (*Response, error)
-	GetCategories(context.Context, *Request) (*GetCategoryResponse, error)
+	GetCategories(context.Context, *GetCategoriesRequest) (*GetCategoryResponse, error)
+	UpdateCategory(context.Context, *UpdateCategoryRequest) (*Response, error)
+	MoveCategory(context.Context, *MoveCategoryRequest) (*Response, error)
+	ReorderCategories(context.Context, *ReorderCategoriesRequest) (*Response, error)
+	ArchiveCategory(context.Context, *CategoryIdRequest) (*Response, error)
+	RestoreCategory(context.Context, *CategoryIdRequest) (*Response, error)
 	GetUsers(context.Context, *Request) (*GetUsersResponse, error)
 	CreatePromocode(context.Context, *CreatePromocodeRequest) (*Response, error)
 	GetPromocodes(context.Context, *Request) (*GetPromocodeResponse, error)
+	GetZeroResultQueries(context.Context, *GetZeroResultQueriesRequest) (*GetZeroResultQueriesResponse, error)
+	GetReviewQueue(context.Context, *GetReviewQueueRequest) (*GetReviewQueueResponse, error)
+	ModerateReview(context.Context, *ModerateReviewRequest) (*Response, error)
+	GetProductQueue(context.Context, *GetProductQueueRequest) (*GetProductQueueResponse, error)
+	ReviewProduct(context.Context, *ReviewProductRequest) (*Response, error)
+	GetQnAQueue(context.Context, *GetQnAQueueRequest) (*GetQnAQueueResponse, error)
+	ModerateQuestion(context.Context, *ModerateQnARequest) (*Response, error)
+	ModerateAnswer(context.Context, *ModerateQnARequest) (*Response, error)
 	mustEmbedUnimplementedAdminServiceServer()
 }
 
@@ -109,9 +265,24 @@ type UnimplementedAdminServiceServer struct {
 func (UnimplementedAdminServiceServer) CreateCategory(context.Context, *AddCategoryRequest) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateCategory not implemented")
 }
-func (UnimplementedAdminServiceServer) GetCategories(context.Context, *Request) (*GetCategoryResponse, error) {
+func (UnimplementedAdminServiceServer) GetCategories(context.Context, *GetCategoriesRequest) (*GetCategoryResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetCategories not implemented")
 }
+func (UnimplementedAdminServiceServer) UpdateCategory(context.Context, *UpdateCategoryRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateCategory not implemented")
+}
+func (UnimplementedAdminServiceServer) MoveCategory(context.Context, *MoveCategoryRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MoveCategory not implemented")
+}
+func (UnimplementedAdminServiceServer) ReorderCategories(context.Context, *ReorderCategoriesRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReorderCategories not implemented")
+}
+func (UnimplementedAdminServiceServer) ArchiveCategory(context.Context, *CategoryIdRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ArchiveCategory not implemented")
+}
+func (UnimplementedAdminServiceServer) RestoreCategory(context.Context, *CategoryIdRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreCategory not implemented")
+}
 func (UnimplementedAdminServiceServer) GetUsers(context.Context, *Request) (*GetUsersResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetUsers not implemented")
 }
@@ -121,6 +292,30 @@ func (UnimplementedAdminServiceServer) CreatePromocode(context.Context, *CreateP
 func (UnimplementedAdminServiceServer) GetPromocodes(context.Context, *Request) (*GetPromocodeResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetPromocodes not implemented")
 }
+func (UnimplementedAdminServiceServer) GetZeroResultQueries(context.Context, *GetZeroResultQueriesRequest) (*GetZeroResultQueriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetZeroResultQueries not implemented")
+}
+func (UnimplementedAdminServiceServer) GetReviewQueue(context.Context, *GetReviewQueueRequest) (*GetReviewQueueResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReviewQueue not implemented")
+}
+func (UnimplementedAdminServiceServer) ModerateReview(context.Context, *ModerateReviewRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ModerateReview not implemented")
+}
+func (UnimplementedAdminServiceServer) GetProductQueue(context.Context, *GetProductQueueRequest) (*GetProductQueueResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProductQueue not implemented")
+}
+func (UnimplementedAdminServiceServer) ReviewProduct(context.Context, *ReviewProductRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReviewProduct not implemented")
+}
+func (UnimplementedAdminServiceServer) GetQnAQueue(context.Context, *GetQnAQueueRequest) (*GetQnAQueueResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetQnAQueue not implemented")
+}
+func (UnimplementedAdminServiceServer) ModerateQuestion(context.Context, *ModerateQnARequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ModerateQuestion not implemented")
+}
+func (UnimplementedAdminServiceServer) ModerateAnswer(context.Context, *ModerateQnARequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ModerateAnswer not implemented")
+}
 func (UnimplementedAdminServiceServer) mustEmbedUnimplementedAdminServiceServer() {}
 
 // UnsafeAdminServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -153,7 +348,7 @@ func _AdminService_CreateCategory_Handler(srv interface{}, ctx context.Context,
 }
 
 func _AdminService_GetCategories_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Request)
+	in := new(GetCategoriesRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -165,7 +360,97 @@ func _AdminService_GetCategories_Handler(srv interface{}, ctx context.Context, d
 		FullMethod: AdminService_GetCategories_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(AdminServiceServer).GetCategories(ctx, req.(*Request))
+		return srv.(AdminServiceServer).GetCategories(ctx, req.(*GetCategoriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_UpdateCategory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateCategoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).UpdateCategory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_UpdateCategory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).UpdateCategory(ctx, req.(*UpdateCategoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_MoveCategory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MoveCategoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).MoveCategory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_MoveCategory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).MoveCategory(ctx, req.(*MoveCategoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_ReorderCategories_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReorderCategoriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).ReorderCategories(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_ReorderCategories_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).ReorderCategories(ctx, req.(*ReorderCategoriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_ArchiveCategory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CategoryIdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).ArchiveCategory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_ArchiveCategory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).ArchiveCategory(ctx, req.(*CategoryIdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_RestoreCategory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CategoryIdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).RestoreCategory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_RestoreCategory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).RestoreCategory(ctx, req.(*CategoryIdRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -224,6 +509,150 @@ func _AdminService_GetPromocodes_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AdminService_GetZeroResultQueries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetZeroResultQueriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).GetZeroResultQueries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_GetZeroResultQueries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).GetZeroResultQueries(ctx, req.(*GetZeroResultQueriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_GetReviewQueue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReviewQueueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).GetReviewQueue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_GetReviewQueue_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).GetReviewQueue(ctx, req.(*GetReviewQueueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_ModerateReview_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ModerateReviewRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).ModerateReview(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_ModerateReview_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).ModerateReview(ctx, req.(*ModerateReviewRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_GetProductQueue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProductQueueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).GetProductQueue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_GetProductQueue_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).GetProductQueue(ctx, req.(*GetProductQueueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_ReviewProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReviewProductRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).ReviewProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_ReviewProduct_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).ReviewProduct(ctx, req.(*ReviewProductRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_GetQnAQueue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetQnAQueueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).GetQnAQueue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_GetQnAQueue_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).GetQnAQueue(ctx, req.(*GetQnAQueueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_ModerateQuestion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ModerateQnARequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).ModerateQuestion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_ModerateQuestion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).ModerateQuestion(ctx, req.(*ModerateQnARequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_ModerateAnswer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ModerateQnARequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).ModerateAnswer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_ModerateAnswer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).ModerateAnswer(ctx, req.(*ModerateQnARequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // AdminService_ServiceDesc is the grpc.ServiceDesc for AdminService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -239,6 +668,26 @@ var AdminService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetCategories",
 			Handler:    _AdminService_GetCategories_Handler,
 		},
+		{
+			MethodName: "UpdateCategory",
+			Handler:    _AdminService_UpdateCategory_Handler,
+		},
+		{
+			MethodName: "MoveCategory",
+			Handler:    _AdminService_MoveCategory_Handler,
+		},
+		{
+			MethodName: "ReorderCategories",
+			Handler:    _AdminService_ReorderCategories_Handler,
+		},
+		{
+			MethodName: "ArchiveCategory",
+			Handler:    _AdminService_ArchiveCategory_Handler,
+		},
+		{
+			MethodName: "RestoreCategory",
+			Handler:    _AdminService_RestoreCategory_Handler,
+		},
 		{
 			MethodName: "GetUsers",
 			Handler:    _AdminService_GetUsers_Handler,
@@ -251,6 +700,38 @@ var AdminService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetPromocodes",
 			Handler:    _AdminService_GetPromocodes_Handler,
 		},
+		{
+			MethodName: "GetZeroResultQueries",
+			Handler:    _AdminService_GetZeroResultQueries_Handler,
+		},
+		{
+			MethodName: "GetReviewQueue",
+			Handler:    _AdminService_GetReviewQueue_Handler,
+		},
+		{
+			MethodName: "ModerateReview",
+			Handler:    _AdminService_ModerateReview_Handler,
+		},
+		{
+			MethodName: "GetProductQueue",
+			Handler:    _AdminService_GetProductQueue_Handler,
+		},
+		{
+			MethodName: "ReviewProduct",
+			Handler:    _AdminService_ReviewProduct_Handler,
+		},
+		{
+			MethodName: "GetQnAQueue",
+			Handler:    _AdminService_GetQnAQueue_Handler,
+		},
+		{
+			MethodName: "ModerateQuestion",
+			Handler:    _AdminService_ModerateQuestion_Handler,
+		},
+		{
+			MethodName: "ModerateAnswer",
+			Handler:    _AdminService_ModerateAnswer_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/proto/admin_service.proto",
@@ -19,14 +19,29 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	MerchantService_GetProfile_FullMethodName          = "/pb.MerchantService/GetProfile"
-	MerchantService_GetProducts_FullMethodName         = "/pb.MerchantService/GetProducts"
-	MerchantService_DeleteProduct_FullMethodName       = "/pb.MerchantService/DeleteProduct"
-	MerchantService_AddProductDiscount_FullMethodName  = "/pb.MerchantService/AddProductDiscount"
-	MerchantService_EditProductDiscount_FullMethodName = "/pb.MerchantService/EditProductDiscount"
-	MerchantService_GetAllDiscounts_FullMethodName     = "/pb.MerchantService/GetAllDiscounts"
-	MerchantService_GetMerchantOrders_FullMethodName   = "/pb.MerchantService/GetMerchantOrders"
-	MerchantService_UpdateOrderStatus_FullMethodName   = "/pb.MerchantService/UpdateOrderStatus"
+	MerchantService_GetProfile_FullMethodName            = "/pb.MerchantService/GetProfile"
+	MerchantService_GetProducts_FullMethodName           = "/pb.MerchantService/GetProducts"
+	MerchantService_DeleteProduct_FullMethodName         = "/pb.MerchantService/DeleteProduct"
+	MerchantService_PublishProduct_FullMethodName        = "/pb.MerchantService/PublishProduct"
+	MerchantService_ArchiveProduct_FullMethodName        = "/pb.MerchantService/ArchiveProduct"
+	MerchantService_RestoreProduct_FullMethodName        = "/pb.MerchantService/RestoreProduct"
+	MerchantService_AddProductDiscount_FullMethodName    = "/pb.MerchantService/AddProductDiscount"
+	MerchantService_EditProductDiscount_FullMethodName   = "/pb.MerchantService/EditProductDiscount"
+	MerchantService_GetAllDiscounts_FullMethodName       = "/pb.MerchantService/GetAllDiscounts"
+	MerchantService_CreateUploadSession_FullMethodName   = "/pb.MerchantService/CreateUploadSession"
+	MerchantService_CompleteUploadSession_FullMethodName = "/pb.MerchantService/CompleteUploadSession"
+	MerchantService_GetInventory_FullMethodName          = "/pb.MerchantService/GetInventory"
+	MerchantService_Restock_FullMethodName               = "/pb.MerchantService/Restock"
+	MerchantService_AdjustStock_FullMethodName           = "/pb.MerchantService/AdjustStock"
+	MerchantService_SetLowStockThreshold_FullMethodName  = "/pb.MerchantService/SetLowStockThreshold"
+	MerchantService_GetStockMovements_FullMethodName     = "/pb.MerchantService/GetStockMovements"
+	MerchantService_GetLowStockAlerts_FullMethodName     = "/pb.MerchantService/GetLowStockAlerts"
+	MerchantService_ImportCatalog_FullMethodName         = "/pb.MerchantService/ImportCatalog"
+	MerchantService_GetImportJob_FullMethodName          = "/pb.MerchantService/GetImportJob"
+	MerchantService_ExportCatalog_FullMethodName         = "/pb.MerchantService/ExportCatalog"
+	MerchantService_GetProductAnalytics_FullMethodName   = "/pb.MerchantService/GetProductAnalytics"
+	MerchantService_GetMerchantOrders_FullMethodName     = "/pb.MerchantService/GetMerchantOrders"
+	MerchantService_UpdateOrderStatus_FullMethodName     = "/pb.MerchantService/UpdateOrderStatus"
 )
 
 // MerchantServiceClient is the client API for MerchantService service.
@@ -36,9 +51,29 @@ type MerchantServiceClient interface {
 	GetProfile(ctx context.Context, in *GetMerchantProfileRequest, opts ...grpc.CallOption) (*GetMerchantProfileResponse, error)
 	GetProducts(ctx context.Context, in *GetProductRequest, opts ...grpc.CallOption) (*GetProductsResponse, error)
 	DeleteProduct(ctx context.Context, in *DeleteProductRequest, opts ...grpc.CallOption) (*Response, error)
+	// ------ Product Lifecycle------------
+	PublishProduct(ctx context.Context, in *PublishProductRequest, opts ...grpc.CallOption) (*ProductStatusResponse, error)
+	ArchiveProduct(ctx context.Context, in *ProductStatusRequest, opts ...grpc.CallOption) (*ProductStatusResponse, error)
+	RestoreProduct(ctx context.Context, in *ProductStatusRequest, opts ...grpc.CallOption) (*ProductStatusResponse, error)
 	AddProductDiscount(ctx context.Context, in *AddDiscountRequest, opts ...grpc.CallOption) (*Response, error)
 	EditProductDiscount(ctx context.Context, in *EditDiscountRequest, opts ...grpc.CallOption) (*Response, error)
 	GetAllDiscounts(ctx context.Context, in *GetDiscountsRequest, opts ...grpc.CallOption) (*GetDiscountsResponse, error)
+	// ------ Image uploads, straight to the object store------------
+	CreateUploadSession(ctx context.Context, in *CreateUploadSessionRequest, opts ...grpc.CallOption) (*CreateUploadSessionResponse, error)
+	CompleteUploadSession(ctx context.Context, in *CompleteUploadSessionRequest, opts ...grpc.CallOption) (*Response, error)
+	// ------ Inventory Related------------
+	GetInventory(ctx context.Context, in *GetInventoryRequest, opts ...grpc.CallOption) (*GetInventoryResponse, error)
+	Restock(ctx context.Context, in *RestockRequest, opts ...grpc.CallOption) (*StockMovementResponse, error)
+	AdjustStock(ctx context.Context, in *AdjustStockRequest, opts ...grpc.CallOption) (*StockMovementResponse, error)
+	SetLowStockThreshold(ctx context.Context, in *SetLowStockThresholdRequest, opts ...grpc.CallOption) (*Response, error)
+	GetStockMovements(ctx context.Context, in *GetStockMovementsRequest, opts ...grpc.CallOption) (*GetStockMovementsResponse, error)
+	GetLowStockAlerts(ctx context.Context, in *Request, opts ...grpc.CallOption) (*GetInventoryResponse, error)
+	// ------ Catalog Related------------
+	ImportCatalog(ctx context.Context, in *ImportCatalogRequest, opts ...grpc.CallOption) (*ImportJobResponse, error)
+	GetImportJob(ctx context.Context, in *GetImportJobRequest, opts ...grpc.CallOption) (*ImportJobResponse, error)
+	ExportCatalog(ctx context.Context, in *ExportCatalogRequest, opts ...grpc.CallOption) (*ExportCatalogResponse, error)
+	// ------ Analytics Related------------
+	GetProductAnalytics(ctx context.Context, in *GetProductAnalyticsRequest, opts ...grpc.CallOption) (*GetProductAnalyticsResponse, error)
 	// ------ Order Related------------
 	GetMerchantOrders(ctx context.Context, in *GetOrdersRequest, opts ...grpc.CallOption) (*GetOrderResponse, error)
 	UpdateOrderStatus(ctx context.Context, in *UpdateOrderRequest, opts ...grpc.CallOption) (*Response, error)
@@ -79,6 +114,33 @@ func (c *merchantServiceClient) DeleteProduct(ctx context.Context, in *DeletePro
 	return out, nil
 }
 
+func (c *merchantServiceClient) PublishProduct(ctx context.Context, in *PublishProductRequest, opts ...grpc.CallOption) (*ProductStatusResponse, error) {
+	out := new(ProductStatusResponse)
+	err := c.cc.Invoke(ctx, MerchantService_PublishProduct_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *merchantServiceClient) ArchiveProduct(ctx context.Context, in *ProductStatusRequest, opts ...grpc.CallOption) (*ProductStatusResponse, error) {
+	out := new(ProductStatusResponse)
+	err := c.cc.Invoke(ctx, MerchantService_ArchiveProduct_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *merchantServiceClient) RestoreProduct(ctx context.Context, in *ProductStatusRequest, opts ...grpc.CallOption) (*ProductStatusResponse, error) {
+	out := new(ProductStatusResponse)
+	err := c.cc.Invoke(ctx, MerchantService_RestoreProduct_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *merchantServiceClient) AddProductDiscount(ctx context.Context, in *AddDiscountRequest, opts ...grpc.CallOption) (*Response, error) {
 	out := new(Response)
 	err := c.cc.Invoke(ctx, MerchantService_AddProductDiscount_FullMethodName, in, out, opts...)
@@ -106,6 +168,114 @@ func (c *merchantServiceClient) GetAllDiscounts(ctx context.Context, in *GetDisc
 	return out, nil
 }
 
+func (c *merchantServiceClient) CreateUploadSession(ctx context.Context, in *CreateUploadSessionRequest, opts ...grpc.CallOption) (*CreateUploadSessionResponse, error) {
+	out := new(CreateUploadSessionResponse)
+	err := c.cc.Invoke(ctx, MerchantService_CreateUploadSession_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *merchantServiceClient) CompleteUploadSession(ctx context.Context, in *CompleteUploadSessionRequest, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, MerchantService_CompleteUploadSession_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *merchantServiceClient) GetInventory(ctx context.Context, in *GetInventoryRequest, opts ...grpc.CallOption) (*GetInventoryResponse, error) {
+	out := new(GetInventoryResponse)
+	err := c.cc.Invoke(ctx, MerchantService_GetInventory_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *merchantServiceClient) Restock(ctx context.Context, in *RestockRequest, opts ...grpc.CallOption) (*StockMovementResponse, error) {
+	out := new(StockMovementResponse)
+	err := c.cc.Invoke(ctx, MerchantService_Restock_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *merchantServiceClient) AdjustStock(ctx context.Context, in *AdjustStockRequest, opts ...grpc.CallOption) (*StockMovementResponse, error) {
+	out := new(StockMovementResponse)
+	err := c.cc.Invoke(ctx, MerchantService_AdjustStock_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *merchantServiceClient) SetLowStockThreshold(ctx context.Context, in *SetLowStockThresholdRequest, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, MerchantService_SetLowStockThreshold_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *merchantServiceClient) GetStockMovements(ctx context.Context, in *GetStockMovementsRequest, opts ...grpc.CallOption) (*GetStockMovementsResponse, error) {
+	out := new(GetStockMovementsResponse)
+	err := c.cc.Invoke(ctx, MerchantService_GetStockMovements_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *merchantServiceClient) GetLowStockAlerts(ctx context.Context, in *Request, opts ...grpc.CallOption) (*GetInventoryResponse, error) {
+	out := new(GetInventoryResponse)
+	err := c.cc.Invoke(ctx, MerchantService_GetLowStockAlerts_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *merchantServiceClient) ImportCatalog(ctx context.Context, in *ImportCatalogRequest, opts ...grpc.CallOption) (*ImportJobResponse, error) {
+	out := new(ImportJobResponse)
+	err := c.cc.Invoke(ctx, MerchantService_ImportCatalog_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *merchantServiceClient) GetImportJob(ctx context.Context, in *GetImportJobRequest, opts ...grpc.CallOption) (*ImportJobResponse, error) {
+	out := new(ImportJobResponse)
+	err := c.cc.Invoke(ctx, MerchantService_GetImportJob_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *merchantServiceClient) ExportCatalog(ctx context.Context, in *ExportCatalogRequest, opts ...grpc.CallOption) (*ExportCatalogResponse, error) {
+	out := new(ExportCatalogResponse)
+	err := c.cc.Invoke(ctx, MerchantService_ExportCatalog_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *merchantServiceClient) GetProductAnalytics(ctx context.Context, in *GetProductAnalyticsRequest, opts ...grpc.CallOption) (*GetProductAnalyticsResponse, error) {
+	out := new(GetProductAnalyticsResponse)
+	err := c.cc.Invoke(ctx, MerchantService_GetProductAnalytics_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *merchantServiceClient) GetMerchantOrders(ctx context.Context, in *GetOrdersRequest, opts ...grpc.CallOption) (*GetOrderResponse, error) {
 	out := new(GetOrderResponse)
 	err := c.cc.Invoke(ctx, MerchantService_GetMerchantOrders_FullMethodName, in, out, opts...)
@@ -131,9 +301,29 @@ type MerchantServiceServer interface {
 	GetProfile(context.Context, *GetMerchantProfileRequest) (*GetMerchantProfileResponse, error)
 	GetProducts(context.Context, *GetProductRequest) (*GetProductsResponse, error)
 	DeleteProduct(context.Context, *DeleteProductRequest) (*Response, error)
+	// ------ Product Lifecycle------------
+	PublishProduct(context.Context, *PublishProductRequest) (*ProductStatusResponse, error)
+	ArchiveProduct(context.Context, *ProductStatusRequest) (*ProductStatusResponse, error)
+	RestoreProduct(context.Context, *ProductStatusRequest) (*ProductStatusResponse, error)
 	AddProductDiscount(context.Context, *AddDiscountRequest) (*Response, error)
 	EditProductDiscount(context.Context, *EditDiscountRequest) (*Response, error)
 	GetAllDiscounts(context.Context, *GetDiscountsRequest) (*GetDiscountsResponse, error)
+	// ------ Image uploads, straight to the object store------------
+	CreateUploadSession(context.Context, *CreateUploadSessionRequest) (*CreateUploadSessionResponse, error)
+	CompleteUploadSession(context.Context, *CompleteUploadSessionRequest) (*Response, error)
+	// ------ Inventory Related------------
+	GetInventory(context.Context, *GetInventoryRequest) (*GetInventoryResponse, error)
+	Restock(context.Context, *RestockRequest) (*StockMovementResponse, error)
+	AdjustStock(context.Context, *AdjustStockRequest) (*StockMovementResponse, error)
+	SetLowStockThreshold(context.Context, *SetLowStockThresholdRequest) (*Response, error)
+	GetStockMovements(context.Context, *GetStockMovementsRequest) (*GetStockMovementsResponse, error)
+	GetLowStockAlerts(context.Context, *Request) (*GetInventoryResponse, error)
+	// ------ Catalog Related------------
+	ImportCatalog(context.Context, *ImportCatalogRequest) (*ImportJobResponse, error)
+	GetImportJob(context.Context, *GetImportJobRequest) (*ImportJobResponse, error)
+	ExportCatalog(context.Context, *ExportCatalogRequest) (*ExportCatalogResponse, error)
+	// ------ Analytics Related------------
+	GetProductAnalytics(context.Context, *GetProductAnalyticsRequest) (*GetProductAnalyticsResponse, error)
 	// ------ Order Related------------
 	GetMerchantOrders(context.Context, *GetOrdersRequest) (*GetOrderResponse, error)
 	UpdateOrderStatus(context.Context, *UpdateOrderRequest) (*Response, error)
@@ -153,6 +343,15 @@ func (UnimplementedMerchantServiceServer) GetProducts(context.Context, *GetProdu
 func (UnimplementedMerchantServiceServer) DeleteProduct(context.Context, *DeleteProductRequest) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteProduct not implemented")
 }
+func (UnimplementedMerchantServiceServer) PublishProduct(context.Context, *PublishProductRequest) (*ProductStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PublishProduct not implemented")
+}
+func (UnimplementedMerchantServiceServer) ArchiveProduct(context.Context, *ProductStatusRequest) (*ProductStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ArchiveProduct not implemented")
+}
+func (UnimplementedMerchantServiceServer) RestoreProduct(context.Context, *ProductStatusRequest) (*ProductStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreProduct not implemented")
+}
 func (UnimplementedMerchantServiceServer) AddProductDiscount(context.Context, *AddDiscountRequest) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AddProductDiscount not implemented")
 }
@@ -162,6 +361,42 @@ func (UnimplementedMerchantServiceServer) EditProductDiscount(context.Context, *
 func (UnimplementedMerchantServiceServer) GetAllDiscounts(context.Context, *GetDiscountsRequest) (*GetDiscountsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetAllDiscounts not implemented")
 }
+func (UnimplementedMerchantServiceServer) CreateUploadSession(context.Context, *CreateUploadSessionRequest) (*CreateUploadSessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateUploadSession not implemented")
+}
+func (UnimplementedMerchantServiceServer) CompleteUploadSession(context.Context, *CompleteUploadSessionRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompleteUploadSession not implemented")
+}
+func (UnimplementedMerchantServiceServer) GetInventory(context.Context, *GetInventoryRequest) (*GetInventoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInventory not implemented")
+}
+func (UnimplementedMerchantServiceServer) Restock(context.Context, *RestockRequest) (*StockMovementResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Restock not implemented")
+}
+func (UnimplementedMerchantServiceServer) AdjustStock(context.Context, *AdjustStockRequest) (*StockMovementResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdjustStock not implemented")
+}
+func (UnimplementedMerchantServiceServer) SetLowStockThreshold(context.Context, *SetLowStockThresholdRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLowStockThreshold not implemented")
+}
+func (UnimplementedMerchantServiceServer) GetStockMovements(context.Context, *GetStockMovementsRequest) (*GetStockMovementsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStockMovements not implemented")
+}
+func (UnimplementedMerchantServiceServer) GetLowStockAlerts(context.Context, *Request) (*GetInventoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLowStockAlerts not implemented")
+}
+func (UnimplementedMerchantServiceServer) ImportCatalog(context.Context, *ImportCatalogRequest) (*ImportJobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImportCatalog not implemented")
+}
+func (UnimplementedMerchantServiceServer) GetImportJob(context.Context, *GetImportJobRequest) (*ImportJobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetImportJob not implemented")
+}
+func (UnimplementedMerchantServiceServer) ExportCatalog(context.Context, *ExportCatalogRequest) (*ExportCatalogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportCatalog not implemented")
+}
+func (UnimplementedMerchantServiceServer) GetProductAnalytics(context.Context, *GetProductAnalyticsRequest) (*GetProductAnalyticsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProductAnalytics not implemented")
+}
 func (UnimplementedMerchantServiceServer) GetMerchantOrders(context.Context, *GetOrdersRequest) (*GetOrderResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetMerchantOrders not implemented")
 }
@@ -235,6 +470,60 @@ func _MerchantService_DeleteProduct_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MerchantService_PublishProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PublishProductRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MerchantServiceServer).PublishProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MerchantService_PublishProduct_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MerchantServiceServer).PublishProduct(ctx, req.(*PublishProductRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MerchantService_ArchiveProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProductStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MerchantServiceServer).ArchiveProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MerchantService_ArchiveProduct_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MerchantServiceServer).ArchiveProduct(ctx, req.(*ProductStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MerchantService_RestoreProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProductStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MerchantServiceServer).RestoreProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MerchantService_RestoreProduct_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MerchantServiceServer).RestoreProduct(ctx, req.(*ProductStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _MerchantService_AddProductDiscount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(AddDiscountRequest)
 	if err := dec(in); err != nil {
@@ -289,6 +578,222 @@ func _MerchantService_GetAllDiscounts_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MerchantService_CreateUploadSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateUploadSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MerchantServiceServer).CreateUploadSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MerchantService_CreateUploadSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MerchantServiceServer).CreateUploadSession(ctx, req.(*CreateUploadSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MerchantService_CompleteUploadSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompleteUploadSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MerchantServiceServer).CompleteUploadSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MerchantService_CompleteUploadSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MerchantServiceServer).CompleteUploadSession(ctx, req.(*CompleteUploadSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MerchantService_GetInventory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInventoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MerchantServiceServer).GetInventory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MerchantService_GetInventory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MerchantServiceServer).GetInventory(ctx, req.(*GetInventoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MerchantService_Restock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MerchantServiceServer).Restock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MerchantService_Restock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MerchantServiceServer).Restock(ctx, req.(*RestockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MerchantService_AdjustStock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdjustStockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MerchantServiceServer).AdjustStock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MerchantService_AdjustStock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MerchantServiceServer).AdjustStock(ctx, req.(*AdjustStockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MerchantService_SetLowStockThreshold_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLowStockThresholdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MerchantServiceServer).SetLowStockThreshold(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MerchantService_SetLowStockThreshold_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MerchantServiceServer).SetLowStockThreshold(ctx, req.(*SetLowStockThresholdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MerchantService_GetStockMovements_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStockMovementsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MerchantServiceServer).GetStockMovements(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MerchantService_GetStockMovements_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MerchantServiceServer).GetStockMovements(ctx, req.(*GetStockMovementsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MerchantService_GetLowStockAlerts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MerchantServiceServer).GetLowStockAlerts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MerchantService_GetLowStockAlerts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MerchantServiceServer).GetLowStockAlerts(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MerchantService_ImportCatalog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportCatalogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MerchantServiceServer).ImportCatalog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MerchantService_ImportCatalog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MerchantServiceServer).ImportCatalog(ctx, req.(*ImportCatalogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MerchantService_GetImportJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetImportJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MerchantServiceServer).GetImportJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MerchantService_GetImportJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MerchantServiceServer).GetImportJob(ctx, req.(*GetImportJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MerchantService_ExportCatalog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportCatalogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MerchantServiceServer).ExportCatalog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MerchantService_ExportCatalog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MerchantServiceServer).ExportCatalog(ctx, req.(*ExportCatalogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MerchantService_GetProductAnalytics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProductAnalyticsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MerchantServiceServer).GetProductAnalytics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MerchantService_GetProductAnalytics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MerchantServiceServer).GetProductAnalytics(ctx, req.(*GetProductAnalyticsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _MerchantService_GetMerchantOrders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetOrdersRequest)
 	if err := dec(in); err != nil {
@@ -344,6 +849,18 @@ var MerchantService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteProduct",
 			Handler:    _MerchantService_DeleteProduct_Handler,
 		},
+		{
+			MethodName: "PublishProduct",
+			Handler:    _MerchantService_PublishProduct_Handler,
+		},
+		{
+			MethodName: "ArchiveProduct",
+			Handler:    _MerchantService_ArchiveProduct_Handler,
+		},
+		{
+			MethodName: "RestoreProduct",
+			Handler:    _MerchantService_RestoreProduct_Handler,
+		},
 		{
 			MethodName: "AddProductDiscount",
 			Handler:    _MerchantService_AddProductDiscount_Handler,
@@ -356,6 +873,54 @@ var MerchantService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetAllDiscounts",
 			Handler:    _MerchantService_GetAllDiscounts_Handler,
 		},
+		{
+			MethodName: "CreateUploadSession",
+			Handler:    _MerchantService_CreateUploadSession_Handler,
+		},
+		{
+			MethodName: "CompleteUploadSession",
+			Handler:    _MerchantService_CompleteUploadSession_Handler,
+		},
+		{
+			MethodName: "GetInventory",
+			Handler:    _MerchantService_GetInventory_Handler,
+		},
+		{
+			MethodName: "Restock",
+			Handler:    _MerchantService_Restock_Handler,
+		},
+		{
+			MethodName: "AdjustStock",
+			Handler:    _MerchantService_AdjustStock_Handler,
+		},
+		{
+			MethodName: "SetLowStockThreshold",
+			Handler:    _MerchantService_SetLowStockThreshold_Handler,
+		},
+		{
+			MethodName: "GetStockMovements",
+			Handler:    _MerchantService_GetStockMovements_Handler,
+		},
+		{
+			MethodName: "GetLowStockAlerts",
+			Handler:    _MerchantService_GetLowStockAlerts_Handler,
+		},
+		{
+			MethodName: "ImportCatalog",
+			Handler:    _MerchantService_ImportCatalog_Handler,
+		},
+		{
+			MethodName: "GetImportJob",
+			Handler:    _MerchantService_GetImportJob_Handler,
+		},
+		{
+			MethodName: "ExportCatalog",
+			Handler:    _MerchantService_ExportCatalog_Handler,
+		},
+		{
+			MethodName: "GetProductAnalytics",
+			Handler:    _MerchantService_GetProductAnalytics_Handler,
+		},
 		{
 			MethodName: "GetMerchantOrders",
 			Handler:    _MerchantService_GetMerchantOrders_Handler,
@@ -19,10 +19,29 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	ProductService_GetProducts_FullMethodName       = "/pb.ProductService/GetProducts"
-	ProductService_AddProductReview_FullMethodName  = "/pb.ProductService/AddProductReview"
-	ProductService_GetProductById_FullMethodName    = "/pb.ProductService/GetProductById"
-	ProductService_GetProductReviews_FullMethodName = "/pb.ProductService/GetProductReviews"
+	ProductService_GetProducts_FullMethodName             = "/pb.ProductService/GetProducts"
+	ProductService_AddProductReview_FullMethodName        = "/pb.ProductService/AddProductReview"
+	ProductService_GetProductById_FullMethodName          = "/pb.ProductService/GetProductById"
+	ProductService_SearchProducts_FullMethodName          = "/pb.ProductService/SearchProducts"
+	ProductService_SuggestQueries_FullMethodName          = "/pb.ProductService/SuggestQueries"
+	ProductService_GetTrendingSearches_FullMethodName     = "/pb.ProductService/GetTrendingSearches"
+	ProductService_GetProductReviews_FullMethodName       = "/pb.ProductService/GetProductReviews"
+	ProductService_CreateReviewPhotoUpload_FullMethodName = "/pb.ProductService/CreateReviewPhotoUpload"
+	ProductService_EditProductReview_FullMethodName       = "/pb.ProductService/EditProductReview"
+	ProductService_DeleteProductReview_FullMethodName     = "/pb.ProductService/DeleteProductReview"
+	ProductService_VoteReview_FullMethodName              = "/pb.ProductService/VoteReview"
+	ProductService_ReplyToReview_FullMethodName           = "/pb.ProductService/ReplyToReview"
+	ProductService_ReportReview_FullMethodName            = "/pb.ProductService/ReportReview"
+	ProductService_GetRelatedProducts_FullMethodName      = "/pb.ProductService/GetRelatedProducts"
+	ProductService_GetRecommendedForUser_FullMethodName   = "/pb.ProductService/GetRecommendedForUser"
+	ProductService_GetRecentlyViewed_FullMethodName       = "/pb.ProductService/GetRecentlyViewed"
+	ProductService_ClearRecentlyViewed_FullMethodName     = "/pb.ProductService/ClearRecentlyViewed"
+	ProductService_GetPriceHistory_FullMethodName         = "/pb.ProductService/GetPriceHistory"
+	ProductService_NotifyWhenAvailable_FullMethodName     = "/pb.ProductService/NotifyWhenAvailable"
+	ProductService_AskQuestion_FullMethodName             = "/pb.ProductService/AskQuestion"
+	ProductService_GetProductQuestions_FullMethodName     = "/pb.ProductService/GetProductQuestions"
+	ProductService_AnswerQuestion_FullMethodName          = "/pb.ProductService/AnswerQuestion"
+	ProductService_UpvoteAnswer_FullMethodName            = "/pb.ProductService/UpvoteAnswer"
 )
 
 // ProductServiceClient is the client API for ProductService service.
@@ -32,7 +51,26 @@ type ProductServiceClient interface {
 	GetProducts(ctx context.Context, in *GetProductRequest, opts ...grpc.CallOption) (*GetProductsResponse, error)
 	AddProductReview(ctx context.Context, in *AddReviewRequest, opts ...grpc.CallOption) (*Response, error)
 	GetProductById(ctx context.Context, in *ProductIdRequest, opts ...grpc.CallOption) (*GetProductByIdResponse, error)
-	GetProductReviews(ctx context.Context, in *ProductIdRequest, opts ...grpc.CallOption) (*Response, error)
+	SearchProducts(ctx context.Context, in *SearchProductsRequest, opts ...grpc.CallOption) (*SearchProductsResponse, error)
+	SuggestQueries(ctx context.Context, in *SuggestQueriesRequest, opts ...grpc.CallOption) (*SuggestQueriesResponse, error)
+	GetTrendingSearches(ctx context.Context, in *GetTrendingSearchesRequest, opts ...grpc.CallOption) (*GetTrendingSearchesResponse, error)
+	GetProductReviews(ctx context.Context, in *GetProductReviewsRequest, opts ...grpc.CallOption) (*GetProductReviewsResponse, error)
+	CreateReviewPhotoUpload(ctx context.Context, in *CreateReviewPhotoUploadRequest, opts ...grpc.CallOption) (*CreateUploadSessionResponse, error)
+	EditProductReview(ctx context.Context, in *EditReviewRequest, opts ...grpc.CallOption) (*Response, error)
+	DeleteProductReview(ctx context.Context, in *ReviewIdRequest, opts ...grpc.CallOption) (*Response, error)
+	VoteReview(ctx context.Context, in *VoteReviewRequest, opts ...grpc.CallOption) (*Response, error)
+	ReplyToReview(ctx context.Context, in *ReplyReviewRequest, opts ...grpc.CallOption) (*Response, error)
+	ReportReview(ctx context.Context, in *ReportReviewRequest, opts ...grpc.CallOption) (*Response, error)
+	GetRelatedProducts(ctx context.Context, in *GetRelatedProductsRequest, opts ...grpc.CallOption) (*GetProductsResponse, error)
+	GetRecommendedForUser(ctx context.Context, in *GetRecommendedForUserRequest, opts ...grpc.CallOption) (*GetProductsResponse, error)
+	GetRecentlyViewed(ctx context.Context, in *GetRecentlyViewedRequest, opts ...grpc.CallOption) (*GetRecentlyViewedResponse, error)
+	ClearRecentlyViewed(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	GetPriceHistory(ctx context.Context, in *GetPriceHistoryRequest, opts ...grpc.CallOption) (*GetPriceHistoryResponse, error)
+	NotifyWhenAvailable(ctx context.Context, in *NotifyWhenAvailableRequest, opts ...grpc.CallOption) (*Response, error)
+	AskQuestion(ctx context.Context, in *AskQuestionRequest, opts ...grpc.CallOption) (*Response, error)
+	GetProductQuestions(ctx context.Context, in *GetProductQuestionsRequest, opts ...grpc.CallOption) (*GetProductQuestionsResponse, error)
+	AnswerQuestion(ctx context.Context, in *AnswerQuestionRequest, opts ...grpc.CallOption) (*Response, error)
+	UpvoteAnswer(ctx context.Context, in *UpvoteAnswerRequest, opts ...grpc.CallOption) (*Response, error)
 }
 
 type productServiceClient struct {
@@ -70,8 +108,35 @@ func (c *productServiceClient) GetProductById(ctx context.Context, in *ProductId
 	return out, nil
 }
 
-func (c *productServiceClient) GetProductReviews(ctx context.Context, in *ProductIdRequest, opts ...grpc.CallOption) (*Response, error) {
-	out := new(Response)
+func (c *productServiceClient) SearchProducts(ctx context.Context, in *SearchProductsRequest, opts ...grpc.CallOption) (*SearchProductsResponse, error) {
+	out := new(SearchProductsResponse)
+	err := c.cc.Invoke(ctx, ProductService_SearchProducts_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) SuggestQueries(ctx context.Context, in *SuggestQueriesRequest, opts ...grpc.CallOption) (*SuggestQueriesResponse, error) {
+	out := new(SuggestQueriesResponse)
+	err := c.cc.Invoke(ctx, ProductService_SuggestQueries_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) GetTrendingSearches(ctx context.Context, in *GetTrendingSearchesRequest, opts ...grpc.CallOption) (*GetTrendingSearchesResponse, error) {
+	out := new(GetTrendingSearchesResponse)
+	err := c.cc.Invoke(ctx, ProductService_GetTrendingSearches_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) GetProductReviews(ctx context.Context, in *GetProductReviewsRequest, opts ...grpc.CallOption) (*GetProductReviewsResponse, error) {
+	out := new(GetProductReviewsResponse)
 	err := c.cc.Invoke(ctx, ProductService_GetProductReviews_FullMethodName, in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -79,6 +144,150 @@ func (c *productServiceClient) GetProductReviews(ctx context.Context, in *Produc
 	return out, nil
 }
 
+func (c *productServiceClient) CreateReviewPhotoUpload(ctx context.Context, in *CreateReviewPhotoUploadRequest, opts ...grpc.CallOption) (*CreateUploadSessionResponse, error) {
+	out := new(CreateUploadSessionResponse)
+	err := c.cc.Invoke(ctx, ProductService_CreateReviewPhotoUpload_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) EditProductReview(ctx context.Context, in *EditReviewRequest, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ProductService_EditProductReview_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) DeleteProductReview(ctx context.Context, in *ReviewIdRequest, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ProductService_DeleteProductReview_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) VoteReview(ctx context.Context, in *VoteReviewRequest, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ProductService_VoteReview_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) ReplyToReview(ctx context.Context, in *ReplyReviewRequest, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ProductService_ReplyToReview_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) ReportReview(ctx context.Context, in *ReportReviewRequest, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ProductService_ReportReview_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) GetRelatedProducts(ctx context.Context, in *GetRelatedProductsRequest, opts ...grpc.CallOption) (*GetProductsResponse, error) {
+	out := new(GetProductsResponse)
+	err := c.cc.Invoke(ctx, ProductService_GetRelatedProducts_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) GetRecommendedForUser(ctx context.Context, in *GetRecommendedForUserRequest, opts ...grpc.CallOption) (*GetProductsResponse, error) {
+	out := new(GetProductsResponse)
+	err := c.cc.Invoke(ctx, ProductService_GetRecommendedForUser_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) GetRecentlyViewed(ctx context.Context, in *GetRecentlyViewedRequest, opts ...grpc.CallOption) (*GetRecentlyViewedResponse, error) {
+	out := new(GetRecentlyViewedResponse)
+	err := c.cc.Invoke(ctx, ProductService_GetRecentlyViewed_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) ClearRecentlyViewed(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ProductService_ClearRecentlyViewed_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) GetPriceHistory(ctx context.Context, in *GetPriceHistoryRequest, opts ...grpc.CallOption) (*GetPriceHistoryResponse, error) {
+	out := new(GetPriceHistoryResponse)
+	err := c.cc.Invoke(ctx, ProductService_GetPriceHistory_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) NotifyWhenAvailable(ctx context.Context, in *NotifyWhenAvailableRequest, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ProductService_NotifyWhenAvailable_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) AskQuestion(ctx context.Context, in *AskQuestionRequest, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ProductService_AskQuestion_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) GetProductQuestions(ctx context.Context, in *GetProductQuestionsRequest, opts ...grpc.CallOption) (*GetProductQuestionsResponse, error) {
+	out := new(GetProductQuestionsResponse)
+	err := c.cc.Invoke(ctx, ProductService_GetProductQuestions_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) AnswerQuestion(ctx context.Context, in *AnswerQuestionRequest, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ProductService_AnswerQuestion_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) UpvoteAnswer(ctx context.Context, in *UpvoteAnswerRequest, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ProductService_UpvoteAnswer_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ProductServiceServer is the server API for ProductService service.
 // All implementations must embed UnimplementedProductServiceServer
 // for forward compatibility
@@ -86,7 +295,26 @@ type ProductServiceServer interface {
 	GetProducts(context.Context, *GetProductRequest) (*GetProductsResponse, error)
 	AddProductReview(context.Context, *AddReviewRequest) (*Response, error)
 	GetProductById(context.Context, *ProductIdRequest) (*GetProductByIdResponse, error)
-	GetProductReviews(context.Context, *ProductIdRequest) (*Response, error)
+	SearchProducts(context.Context, *SearchProductsRequest) (*SearchProductsResponse, error)
+	SuggestQueries(context.Context, *SuggestQueriesRequest) (*SuggestQueriesResponse, error)
+	GetTrendingSearches(context.Context, *GetTrendingSearchesRequest) (*GetTrendingSearchesResponse, error)
+	GetProductReviews(context.Context, *GetProductReviewsRequest) (*GetProductReviewsResponse, error)
+	CreateReviewPhotoUpload(context.Context, *CreateReviewPhotoUploadRequest) (*CreateUploadSessionResponse, error)
+	EditProductReview(context.Context, *EditReviewRequest) (*Response, error)
+	DeleteProductReview(context.Context, *ReviewIdRequest) (*Response, error)
+	VoteReview(context.Context, *VoteReviewRequest) (*Response, error)
+	ReplyToReview(context.Context, *ReplyReviewRequest) (*Response, error)
+	ReportReview(context.Context, *ReportReviewRequest) (*Response, error)
+	GetRelatedProducts(context.Context, *GetRelatedProductsRequest) (*GetProductsResponse, error)
+	GetRecommendedForUser(context.Context, *GetRecommendedForUserRequest) (*GetProductsResponse, error)
+	GetRecentlyViewed(context.Context, *GetRecentlyViewedRequest) (*GetRecentlyViewedResponse, error)
+	ClearRecentlyViewed(context.Context, *Request) (*Response, error)
+	GetPriceHistory(context.Context, *GetPriceHistoryRequest) (*GetPriceHistoryResponse, error)
+	NotifyWhenAvailable(context.Context, *NotifyWhenAvailableRequest) (*Response, error)
+	AskQuestion(context.Context, *AskQuestionRequest) (*Response, error)
+	GetProductQuestions(context.Context, *GetProductQuestionsRequest) (*GetProductQuestionsResponse, error)
+	AnswerQuestion(context.Context, *AnswerQuestionRequest) (*Response, error)
+	UpvoteAnswer(context.Context, *UpvoteAnswerRequest) (*Response, error)
 	mustEmbedUnimplementedProductServiceServer()
 }
 
@@ -103,9 +331,66 @@ func (UnimplementedProductServiceServer) AddProductReview(context.Context, *AddR
 func (UnimplementedProductServiceServer) GetProductById(context.Context, *ProductIdRequest) (*GetProductByIdResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetProductById not implemented")
 }
-func (UnimplementedProductServiceServer) GetProductReviews(context.Context, *ProductIdRequest) (*Response, error) {
+func (UnimplementedProductServiceServer) SearchProducts(context.Context, *SearchProductsRequest) (*SearchProductsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchProducts not implemented")
+}
+func (UnimplementedProductServiceServer) SuggestQueries(context.Context, *SuggestQueriesRequest) (*SuggestQueriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SuggestQueries not implemented")
+}
+func (UnimplementedProductServiceServer) GetTrendingSearches(context.Context, *GetTrendingSearchesRequest) (*GetTrendingSearchesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTrendingSearches not implemented")
+}
+func (UnimplementedProductServiceServer) GetProductReviews(context.Context, *GetProductReviewsRequest) (*GetProductReviewsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetProductReviews not implemented")
 }
+func (UnimplementedProductServiceServer) CreateReviewPhotoUpload(context.Context, *CreateReviewPhotoUploadRequest) (*CreateUploadSessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateReviewPhotoUpload not implemented")
+}
+func (UnimplementedProductServiceServer) EditProductReview(context.Context, *EditReviewRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EditProductReview not implemented")
+}
+func (UnimplementedProductServiceServer) DeleteProductReview(context.Context, *ReviewIdRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteProductReview not implemented")
+}
+func (UnimplementedProductServiceServer) VoteReview(context.Context, *VoteReviewRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VoteReview not implemented")
+}
+func (UnimplementedProductServiceServer) ReplyToReview(context.Context, *ReplyReviewRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReplyToReview not implemented")
+}
+func (UnimplementedProductServiceServer) ReportReview(context.Context, *ReportReviewRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportReview not implemented")
+}
+func (UnimplementedProductServiceServer) GetRelatedProducts(context.Context, *GetRelatedProductsRequest) (*GetProductsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRelatedProducts not implemented")
+}
+func (UnimplementedProductServiceServer) GetRecommendedForUser(context.Context, *GetRecommendedForUserRequest) (*GetProductsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRecommendedForUser not implemented")
+}
+func (UnimplementedProductServiceServer) GetRecentlyViewed(context.Context, *GetRecentlyViewedRequest) (*GetRecentlyViewedResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRecentlyViewed not implemented")
+}
+func (UnimplementedProductServiceServer) ClearRecentlyViewed(context.Context, *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClearRecentlyViewed not implemented")
+}
+func (UnimplementedProductServiceServer) GetPriceHistory(context.Context, *GetPriceHistoryRequest) (*GetPriceHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPriceHistory not implemented")
+}
+func (UnimplementedProductServiceServer) NotifyWhenAvailable(context.Context, *NotifyWhenAvailableRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NotifyWhenAvailable not implemented")
+}
+func (UnimplementedProductServiceServer) AskQuestion(context.Context, *AskQuestionRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AskQuestion not implemented")
+}
+func (UnimplementedProductServiceServer) GetProductQuestions(context.Context, *GetProductQuestionsRequest) (*GetProductQuestionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProductQuestions not implemented")
+}
+func (UnimplementedProductServiceServer) AnswerQuestion(context.Context, *AnswerQuestionRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AnswerQuestion not implemented")
+}
+func (UnimplementedProductServiceServer) UpvoteAnswer(context.Context, *UpvoteAnswerRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpvoteAnswer not implemented")
+}
 func (UnimplementedProductServiceServer) mustEmbedUnimplementedProductServiceServer() {}
 
 // UnsafeProductServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -173,8 +458,62 @@ func _ProductService_GetProductById_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ProductService_SearchProducts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchProductsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).SearchProducts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_SearchProducts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).SearchProducts(ctx, req.(*SearchProductsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_SuggestQueries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SuggestQueriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).SuggestQueries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_SuggestQueries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).SuggestQueries(ctx, req.(*SuggestQueriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_GetTrendingSearches_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTrendingSearchesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).GetTrendingSearches(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_GetTrendingSearches_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).GetTrendingSearches(ctx, req.(*GetTrendingSearchesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ProductService_GetProductReviews_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ProductIdRequest)
+	in := new(GetProductReviewsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -186,7 +525,295 @@ func _ProductService_GetProductReviews_Handler(srv interface{}, ctx context.Cont
 		FullMethod: ProductService_GetProductReviews_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ProductServiceServer).GetProductReviews(ctx, req.(*ProductIdRequest))
+		return srv.(ProductServiceServer).GetProductReviews(ctx, req.(*GetProductReviewsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_CreateReviewPhotoUpload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateReviewPhotoUploadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).CreateReviewPhotoUpload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_CreateReviewPhotoUpload_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).CreateReviewPhotoUpload(ctx, req.(*CreateReviewPhotoUploadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_EditProductReview_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EditReviewRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).EditProductReview(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_EditProductReview_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).EditProductReview(ctx, req.(*EditReviewRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_DeleteProductReview_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReviewIdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).DeleteProductReview(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_DeleteProductReview_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).DeleteProductReview(ctx, req.(*ReviewIdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_VoteReview_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VoteReviewRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).VoteReview(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_VoteReview_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).VoteReview(ctx, req.(*VoteReviewRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_ReplyToReview_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplyReviewRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).ReplyToReview(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_ReplyToReview_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).ReplyToReview(ctx, req.(*ReplyReviewRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_ReportReview_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportReviewRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).ReportReview(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_ReportReview_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).ReportReview(ctx, req.(*ReportReviewRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_GetRelatedProducts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRelatedProductsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).GetRelatedProducts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_GetRelatedProducts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).GetRelatedProducts(ctx, req.(*GetRelatedProductsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_GetRecommendedForUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRecommendedForUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).GetRecommendedForUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_GetRecommendedForUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).GetRecommendedForUser(ctx, req.(*GetRecommendedForUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_GetRecentlyViewed_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRecentlyViewedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).GetRecentlyViewed(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_GetRecentlyViewed_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).GetRecentlyViewed(ctx, req.(*GetRecentlyViewedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_ClearRecentlyViewed_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).ClearRecentlyViewed(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_ClearRecentlyViewed_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).ClearRecentlyViewed(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_GetPriceHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPriceHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).GetPriceHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_GetPriceHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).GetPriceHistory(ctx, req.(*GetPriceHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_NotifyWhenAvailable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NotifyWhenAvailableRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).NotifyWhenAvailable(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_NotifyWhenAvailable_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).NotifyWhenAvailable(ctx, req.(*NotifyWhenAvailableRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_AskQuestion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AskQuestionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).AskQuestion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_AskQuestion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).AskQuestion(ctx, req.(*AskQuestionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_GetProductQuestions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProductQuestionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).GetProductQuestions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_GetProductQuestions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).GetProductQuestions(ctx, req.(*GetProductQuestionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_AnswerQuestion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnswerQuestionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).AnswerQuestion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_AnswerQuestion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).AnswerQuestion(ctx, req.(*AnswerQuestionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_UpvoteAnswer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpvoteAnswerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).UpvoteAnswer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_UpvoteAnswer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).UpvoteAnswer(ctx, req.(*UpvoteAnswerRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -210,10 +837,86 @@ var ProductService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetProductById",
 			Handler:    _ProductService_GetProductById_Handler,
 		},
+		{
+			MethodName: "SearchProducts",
+			Handler:    _ProductService_SearchProducts_Handler,
+		},
+		{
+			MethodName: "SuggestQueries",
+			Handler:    _ProductService_SuggestQueries_Handler,
+		},
+		{
+			MethodName: "GetTrendingSearches",
+			Handler:    _ProductService_GetTrendingSearches_Handler,
+		},
 		{
 			MethodName: "GetProductReviews",
 			Handler:    _ProductService_GetProductReviews_Handler,
 		},
+		{
+			MethodName: "CreateReviewPhotoUpload",
+			Handler:    _ProductService_CreateReviewPhotoUpload_Handler,
+		},
+		{
+			MethodName: "EditProductReview",
+			Handler:    _ProductService_EditProductReview_Handler,
+		},
+		{
+			MethodName: "DeleteProductReview",
+			Handler:    _ProductService_DeleteProductReview_Handler,
+		},
+		{
+			MethodName: "VoteReview",
+			Handler:    _ProductService_VoteReview_Handler,
+		},
+		{
+			MethodName: "ReplyToReview",
+			Handler:    _ProductService_ReplyToReview_Handler,
+		},
+		{
+			MethodName: "ReportReview",
+			Handler:    _ProductService_ReportReview_Handler,
+		},
+		{
+			MethodName: "GetRelatedProducts",
+			Handler:    _ProductService_GetRelatedProducts_Handler,
+		},
+		{
+			MethodName: "GetRecommendedForUser",
+			Handler:    _ProductService_GetRecommendedForUser_Handler,
+		},
+		{
+			MethodName: "GetRecentlyViewed",
+			Handler:    _ProductService_GetRecentlyViewed_Handler,
+		},
+		{
+			MethodName: "ClearRecentlyViewed",
+			Handler:    _ProductService_ClearRecentlyViewed_Handler,
+		},
+		{
+			MethodName: "GetPriceHistory",
+			Handler:    _ProductService_GetPriceHistory_Handler,
+		},
+		{
+			MethodName: "NotifyWhenAvailable",
+			Handler:    _ProductService_NotifyWhenAvailable_Handler,
+		},
+		{
+			MethodName: "AskQuestion",
+			Handler:    _ProductService_AskQuestion_Handler,
+		},
+		{
+			MethodName: "GetProductQuestions",
+			Handler:    _ProductService_GetProductQuestions_Handler,
+		},
+		{
+			MethodName: "AnswerQuestion",
+			Handler:    _ProductService_AnswerQuestion_Handler,
+		},
+		{
+			MethodName: "UpvoteAnswer",
+			Handler:    _ProductService_UpvoteAnswer_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/proto/product_service.proto",
@@ -19,21 +19,24 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	UserServie_GetHome_FullMethodName               = "/pb.UserServie/GetHome"
-	UserServie_AddRemoveWishlist_FullMethodName     = "/pb.UserServie/AddRemoveWishlist"
-	UserServie_GetWishlist_FullMethodName           = "/pb.UserServie/GetWishlist"
-	UserServie_AddProductToCart_FullMethodName      = "/pb.UserServie/AddProductToCart"
-	UserServie_GetCartItmes_FullMethodName          = "/pb.UserServie/GetCartItmes"
-	UserServie_CheckoutCartItems_FullMethodName     = "/pb.UserServie/CheckoutCartItems"
-	UserServie_RemoveProductFromCart_FullMethodName = "/pb.UserServie/RemoveProductFromCart"
-	UserServie_AddAddress_FullMethodName            = "/pb.UserServie/AddAddress"
-	UserServie_GetAddresses_FullMethodName          = "/pb.UserServie/GetAddresses"
-	UserServie_GetAddressByID_FullMethodName        = "/pb.UserServie/GetAddressByID"
-	UserServie_EditAddress_FullMethodName           = "/pb.UserServie/EditAddress"
-	UserServie_DeleteAddress_FullMethodName         = "/pb.UserServie/DeleteAddress"
-	UserServie_CreateOrder_FullMethodName           = "/pb.UserServie/CreateOrder"
-	UserServie_GetUserOrders_FullMethodName         = "/pb.UserServie/GetUserOrders"
-	UserServie_AddProductReview_FullMethodName      = "/pb.UserServie/AddProductReview"
+	UserServie_GetHome_FullMethodName                = "/pb.UserServie/GetHome"
+	UserServie_AddRemoveWishlist_FullMethodName      = "/pb.UserServie/AddRemoveWishlist"
+	UserServie_GetWishlist_FullMethodName            = "/pb.UserServie/GetWishlist"
+	UserServie_AddProductToCart_FullMethodName       = "/pb.UserServie/AddProductToCart"
+	UserServie_GetCartItmes_FullMethodName           = "/pb.UserServie/GetCartItmes"
+	UserServie_CheckoutCartItems_FullMethodName      = "/pb.UserServie/CheckoutCartItems"
+	UserServie_RemoveProductFromCart_FullMethodName  = "/pb.UserServie/RemoveProductFromCart"
+	UserServie_UpdateCartItemQuantity_FullMethodName = "/pb.UserServie/UpdateCartItemQuantity"
+	UserServie_AddAddress_FullMethodName             = "/pb.UserServie/AddAddress"
+	UserServie_GetAddresses_FullMethodName           = "/pb.UserServie/GetAddresses"
+	UserServie_GetAddressByID_FullMethodName         = "/pb.UserServie/GetAddressByID"
+	UserServie_EditAddress_FullMethodName            = "/pb.UserServie/EditAddress"
+	UserServie_DeleteAddress_FullMethodName          = "/pb.UserServie/DeleteAddress"
+	UserServie_CreateOrder_FullMethodName            = "/pb.UserServie/CreateOrder"
+	UserServie_GetUserOrders_FullMethodName          = "/pb.UserServie/GetUserOrders"
+	UserServie_AddProductReview_FullMethodName       = "/pb.UserServie/AddProductReview"
+	UserServie_GetNotifications_FullMethodName       = "/pb.UserServie/GetNotifications"
+	UserServie_MarkNotificationsRead_FullMethodName  = "/pb.UserServie/MarkNotificationsRead"
 )
 
 // UserServieClient is the client API for UserServie service.
@@ -48,6 +51,7 @@ type UserServieClient interface {
 	GetCartItmes(ctx context.Context, in *Request, opts ...grpc.CallOption) (*GetCartItemsResponse, error)
 	CheckoutCartItems(ctx context.Context, in *CheckoutCartItemsRequest, opts ...grpc.CallOption) (*Response, error)
 	RemoveProductFromCart(ctx context.Context, in *RemoveFromCartRequest, opts ...grpc.CallOption) (*Response, error)
+	UpdateCartItemQuantity(ctx context.Context, in *UpdateCartItemQuantityRequest, opts ...grpc.CallOption) (*Response, error)
 	// ------ Address Related------------
 	AddAddress(ctx context.Context, in *AddAddressRequest, opts ...grpc.CallOption) (*Response, error)
 	GetAddresses(ctx context.Context, in *Request, opts ...grpc.CallOption) (*GetAddressesResponse, error)
@@ -59,6 +63,9 @@ type UserServieClient interface {
 	GetUserOrders(ctx context.Context, in *GetOrdersRequest, opts ...grpc.CallOption) (*GetOrderResponse, error)
 	// ------- Product Review
 	AddProductReview(ctx context.Context, in *AddReviewRequest, opts ...grpc.CallOption) (*Response, error)
+	// ------- Notifications
+	GetNotifications(ctx context.Context, in *GetNotificationsRequest, opts ...grpc.CallOption) (*GetNotificationsResponse, error)
+	MarkNotificationsRead(ctx context.Context, in *MarkNotificationsReadRequest, opts ...grpc.CallOption) (*Response, error)
 }
 
 type userServieClient struct {
@@ -132,6 +139,15 @@ func (c *userServieClient) RemoveProductFromCart(ctx context.Context, in *Remove
 	return out, nil
 }
 
+func (c *userServieClient) UpdateCartItemQuantity(ctx context.Context, in *UpdateCartItemQuantityRequest, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, UserServie_UpdateCartItemQuantity_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *userServieClient) AddAddress(ctx context.Context, in *AddAddressRequest, opts ...grpc.CallOption) (*Response, error) {
 	out := new(Response)
 	err := c.cc.Invoke(ctx, UserServie_AddAddress_FullMethodName, in, out, opts...)
@@ -204,6 +220,24 @@ func (c *userServieClient) AddProductReview(ctx context.Context, in *AddReviewRe
 	return out, nil
 }
 
+func (c *userServieClient) GetNotifications(ctx context.Context, in *GetNotificationsRequest, opts ...grpc.CallOption) (*GetNotificationsResponse, error) {
+	out := new(GetNotificationsResponse)
+	err := c.cc.Invoke(ctx, UserServie_GetNotifications_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServieClient) MarkNotificationsRead(ctx context.Context, in *MarkNotificationsReadRequest, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, UserServie_MarkNotificationsRead_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // UserServieServer is the server API for UserServie service.
 // All implementations must embed UnimplementedUserServieServer
 // for forward compatibility
@@ -216,6 +250,7 @@ type UserServieServer interface {
 	GetCartItmes(context.Context, *Request) (*GetCartItemsResponse, error)
 	CheckoutCartItems(context.Context, *CheckoutCartItemsRequest) (*Response, error)
 	RemoveProductFromCart(context.Context, *RemoveFromCartRequest) (*Response, error)
+	UpdateCartItemQuantity(context.Context, *UpdateCartItemQuantityRequest) (*Response, error)
 	// ------ Address Related------------
 	AddAddress(context.Context, *AddAddressRequest) (*Response, error)
 	GetAddresses(context.Context, *Request) (*GetAddressesResponse, error)
@@ -227,6 +262,9 @@ type UserServieServer interface {
 	GetUserOrders(context.Context, *GetOrdersRequest) (*GetOrderResponse, error)
 	// ------- Product Review
 	AddProductReview(context.Context, *AddReviewRequest) (*Response, error)
+	// ------- Notifications
+	GetNotifications(context.Context, *GetNotificationsRequest) (*GetNotificationsResponse, error)
+	MarkNotificationsRead(context.Context, *MarkNotificationsReadRequest) (*Response, error)
 	mustEmbedUnimplementedUserServieServer()
 }
 
@@ -255,6 +293,9 @@ func (UnimplementedUserServieServer) CheckoutCartItems(context.Context, *Checkou
 func (UnimplementedUserServieServer) RemoveProductFromCart(context.Context, *RemoveFromCartRequest) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RemoveProductFromCart not implemented")
 }
+func (UnimplementedUserServieServer) UpdateCartItemQuantity(context.Context, *UpdateCartItemQuantityRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateCartItemQuantity not implemented")
+}
 func (UnimplementedUserServieServer) AddAddress(context.Context, *AddAddressRequest) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AddAddress not implemented")
 }
@@ -279,6 +320,12 @@ func (UnimplementedUserServieServer) GetUserOrders(context.Context, *GetOrdersRe
 func (UnimplementedUserServieServer) AddProductReview(context.Context, *AddReviewRequest) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AddProductReview not implemented")
 }
+func (UnimplementedUserServieServer) GetNotifications(context.Context, *GetNotificationsRequest) (*GetNotificationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNotifications not implemented")
+}
+func (UnimplementedUserServieServer) MarkNotificationsRead(context.Context, *MarkNotificationsReadRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MarkNotificationsRead not implemented")
+}
 func (UnimplementedUserServieServer) mustEmbedUnimplementedUserServieServer() {}
 
 // UnsafeUserServieServer may be embedded to opt out of forward compatibility for this service.
@@ -418,6 +465,24 @@ func _UserServie_RemoveProductFromCart_Handler(srv interface{}, ctx context.Cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _UserServie_UpdateCartItemQuantity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateCartItemQuantityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServieServer).UpdateCartItemQuantity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserServie_UpdateCartItemQuantity_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServieServer).UpdateCartItemQuantity(ctx, req.(*UpdateCartItemQuantityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _UserServie_AddAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(AddAddressRequest)
 	if err := dec(in); err != nil {
@@ -562,6 +627,42 @@ func _UserServie_AddProductReview_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _UserServie_GetNotifications_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetNotificationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServieServer).GetNotifications(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserServie_GetNotifications_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServieServer).GetNotifications(ctx, req.(*GetNotificationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserServie_MarkNotificationsRead_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MarkNotificationsReadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServieServer).MarkNotificationsRead(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserServie_MarkNotificationsRead_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServieServer).MarkNotificationsRead(ctx, req.(*MarkNotificationsReadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // UserServie_ServiceDesc is the grpc.ServiceDesc for UserServie service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -597,6 +698,10 @@ var UserServie_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RemoveProductFromCart",
 			Handler:    _UserServie_RemoveProductFromCart_Handler,
 		},
+		{
+			MethodName: "UpdateCartItemQuantity",
+			Handler:    _UserServie_UpdateCartItemQuantity_Handler,
+		},
 		{
 			MethodName: "AddAddress",
 			Handler:    _UserServie_AddAddress_Handler,
@@ -629,6 +734,14 @@ var UserServie_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "AddProductReview",
 			Handler:    _UserServie_AddProductReview_Handler,
 		},
+		{
+			MethodName: "GetNotifications",
+			Handler:    _UserServie_GetNotifications_Handler,
+		},
+		{
+			MethodName: "MarkNotificationsRead",
+			Handler:    _UserServie_MarkNotificationsRead_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/proto/user_service.proto",
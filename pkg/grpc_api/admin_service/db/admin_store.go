@@ -1,10 +1,10 @@
 package db
 
 import (
+	"context"
 	"time"
 
 	"github.com/akmal4410/gestapo/internal/database"
-	"github.com/akmal4410/gestapo/pkg/api/proto"
 	"github.com/akmal4410/gestapo/pkg/grpc_api/admin_service/db/entity"
 	"github.com/google/uuid"
 )
@@ -17,74 +17,7 @@ func NewAdminStore(storage *database.Storage) *AdminStore {
 	return &AdminStore{storage: storage}
 }
 
-func (store *AdminStore) CheckCategoryExist(category string) (bool, error) {
-	checkQuery := `SELECT * FROM categories WHERE category_name = $1;`
-	res, err := store.storage.DB.Exec(checkQuery, category)
-	if err != nil {
-		return false, err
-	}
-
-	result, err := res.RowsAffected()
-	if err != nil {
-		return false, err
-	}
-
-	return result != 0, nil
-}
-
-func (store AdminStore) AddCategory(req *proto.AddCategoryRequest) error {
-	createdAt := time.Now()
-	updatedAt := time.Now()
-
-	insertQuery := `
-	INSERT INTO categories (id, category_name, created_at, updated_at)
-	VALUES ($1, $2, $3, $4);
-	`
-
-	uuId, err := uuid.NewRandom()
-	if err != nil {
-		return err
-	}
-	_, err = store.storage.DB.Exec(insertQuery, uuId, req.GetCategoryName(), createdAt, updatedAt)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-func (store *AdminStore) GetCategories() ([]*proto.CategoryRes, error) {
-	var categories []*proto.CategoryRes
-
-	selectQuery := `
-	SELECT id, category_name 
-	FROM categories 
-	ORDER BY category_name ASC;
-	`
-
-	rows, err := store.storage.DB.Query(selectQuery)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var category proto.CategoryRes
-		err := rows.Scan(&category.Id, &category.Category)
-		if err != nil {
-			return nil, err
-		}
-		categories = append(categories, &category)
-	}
-
-	err = rows.Err()
-	if err != nil {
-		return nil, err
-	}
-
-	return categories, nil
-}
-
-func (store *AdminStore) GetUsers() ([]*entity.GetUserRes, error) {
+func (store *AdminStore) GetUsers(ctx context.Context) ([]*entity.GetUserRes, error) {
 	var users []*entity.GetUserRes
 
 	selectQuery := `
@@ -93,7 +26,7 @@ func (store *AdminStore) GetUsers() ([]*entity.GetUserRes, error) {
 	WHERE user_type != 'ADMIN';
 	`
 	//ORDER BY full_name ASC
-	rows, err := store.storage.DB.Query(selectQuery)
+	rows, err := store.storage.DB.QueryContext(ctx, selectQuery)
 	if err != nil {
 		return nil, err
 	}
@@ -125,9 +58,9 @@ func (store *AdminStore) GetUsers() ([]*entity.GetUserRes, error) {
 	return users, nil
 }
 
-func (store *AdminStore) CheckPromocodeExist(promocode string) (bool, error) {
+func (store *AdminStore) CheckPromocodeExist(ctx context.Context, promocode string) (bool, error) {
 	checkQuery := `SELECT * FROM promo_codes WHERE code = $1;`
-	res, err := store.storage.DB.Exec(checkQuery, promocode)
+	res, err := store.storage.DB.ExecContext(ctx, checkQuery, promocode)
 	if err != nil {
 		return false, err
 	}
@@ -140,7 +73,7 @@ func (store *AdminStore) CheckPromocodeExist(promocode string) (bool, error) {
 	return result != 0, nil
 }
 
-func (store AdminStore) AddPromocode(req *entity.AddPromocodeReq) error {
+func (store AdminStore) AddPromocode(ctx context.Context, req *entity.AddPromocodeReq) error {
 	createdAt := time.Now()
 	updatedAt := time.Now()
 
@@ -153,14 +86,14 @@ func (store AdminStore) AddPromocode(req *entity.AddPromocodeReq) error {
 	if err != nil {
 		return err
 	}
-	_, err = store.storage.DB.Exec(insertQuery, uuId, req.Code, req.Title, req.Description, req.Percentage, createdAt, updatedAt)
+	_, err = store.storage.DB.ExecContext(ctx, insertQuery, uuId, req.Code, req.Title, req.Description, req.Percentage, createdAt, updatedAt)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (store *AdminStore) GetPromocodes() ([]*entity.PromocodeRes, error) {
+func (store *AdminStore) GetPromocodes(ctx context.Context) ([]*entity.PromocodeRes, error) {
 	var promocodes []*entity.PromocodeRes
 
 	selectQuery := `
@@ -169,7 +102,7 @@ func (store *AdminStore) GetPromocodes() ([]*entity.PromocodeRes, error) {
 	ORDER BY created_at DESC;
 	`
 
-	rows, err := store.storage.DB.Query(selectQuery)
+	rows, err := store.storage.DB.QueryContext(ctx, selectQuery)
 	if err != nil {
 		return nil, err
 	}
@@ -191,3 +124,31 @@ func (store *AdminStore) GetPromocodes() ([]*entity.PromocodeRes, error) {
 
 	return promocodes, nil
 }
+
+// GetZeroResultQueries returns the queries searched since the given time that found
+// no product, the most searched first.
+func (store *AdminStore) GetZeroResultQueries(ctx context.Context, since time.Time, limit int) ([]*entity.ZeroResultQuery, error) {
+	selectQuery := `
+	SELECT query, COUNT(*) AS searches, MAX(created_at) AS last_searched_at
+	FROM search_queries
+	WHERE results = 0 AND created_at >= $1
+	GROUP BY query
+	ORDER BY searches DESC, last_searched_at DESC
+	LIMIT $2;
+	`
+	rows, err := store.storage.DB.QueryContext(ctx, selectQuery, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	queries := []*entity.ZeroResultQuery{}
+	for rows.Next() {
+		var query entity.ZeroResultQuery
+		if err := rows.Scan(&query.Query, &query.Count, &query.LastSearchedAt); err != nil {
+			return nil, err
+		}
+		queries = append(queries, &query)
+	}
+	return queries, rows.Err()
+}
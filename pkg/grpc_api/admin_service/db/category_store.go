@@ -0,0 +1,205 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/grpc_api/admin_service/db/entity"
+	"github.com/google/uuid"
+)
+
+// CheckCategoryExist tells whether the parent has a child of the name, the
+// names are compared regardless of the case.
+func (store *AdminStore) CheckCategoryExist(ctx context.Context, parentId *string, category string) (bool, error) {
+	checkQuery := `
+	SELECT EXISTS (
+		SELECT 1 FROM categories
+		WHERE parent_id IS NOT DISTINCT FROM $1 AND LOWER(category_name) = LOWER($2) AND deleted_at IS NULL
+	);
+	`
+	var exists bool
+	err := store.storage.DB.QueryRowContext(ctx, checkQuery, parentId, category).Scan(&exists)
+	return exists, err
+}
+
+// AddCategory adds the category as the last child of its parent.
+func (store *AdminStore) AddCategory(ctx context.Context, req *entity.AddCategoryReq) (string, error) {
+	createdAt := time.Now()
+	updatedAt := time.Now()
+
+	insertQuery := `
+	INSERT INTO categories (id, parent_id, path, position, category_name, attributes, created_at, updated_at)
+	SELECT $1::uuid, $2::uuid,
+	COALESCE((SELECT path || '/' FROM categories WHERE id = $2::uuid), '') || $1::text,
+	COALESCE((SELECT MAX(position) + 1 FROM categories WHERE parent_id IS NOT DISTINCT FROM $2::uuid), 0),
+	$3, $4::jsonb, $5::timestamptz, $6::timestamptz;
+	`
+
+	uuId, err := uuid.NewRandom()
+	if err != nil {
+		return "", err
+	}
+	_, err = store.storage.DB.ExecContext(ctx, insertQuery, uuId, req.ParentID, req.CategoryName, req.Attributes, createdAt, updatedAt)
+	if err != nil {
+		return "", err
+	}
+	return uuId.String(), nil
+}
+
+const selectCategoryQuery = `
+	SELECT id, parent_id, path, position, category_name, image, attributes, archived_at
+	FROM categories
+	`
+
+func (store *AdminStore) GetCategory(ctx context.Context, categoryId string) (*entity.Category, error) {
+	categories, err := store.categories(ctx, selectCategoryQuery+`WHERE id = $1 AND deleted_at IS NULL;`, categoryId)
+	if err != nil {
+		return nil, err
+	}
+	if len(categories) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return categories[0], nil
+}
+
+// GetCategories returns every category, the children of a parent in their order.
+func (store *AdminStore) GetCategories(ctx context.Context) ([]*entity.Category, error) {
+	return store.categories(ctx, selectCategoryQuery+`WHERE deleted_at IS NULL ORDER BY position ASC, category_name ASC;`)
+}
+
+func (store *AdminStore) categories(ctx context.Context, query string, args ...interface{}) ([]*entity.Category, error) {
+	rows, err := store.storage.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []*entity.Category{}
+	for rows.Next() {
+		var category entity.Category
+		err := rows.Scan(
+			&category.ID,
+			&category.ParentID,
+			&category.Path,
+			&category.Position,
+			&category.CategoryName,
+			&category.Image,
+			&category.Attributes,
+			&category.ArchivedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, &category)
+	}
+	return categories, rows.Err()
+}
+
+func (store *AdminStore) UpdateCategory(ctx context.Context, categoryId string, name *string, attributes *entity.CategoryAttributes) error {
+	updateQuery := `
+	UPDATE categories
+	SET category_name = COALESCE($2, category_name), attributes = COALESCE($3::jsonb, attributes), updated_at = $4
+	WHERE id = $1 AND deleted_at IS NULL;
+	`
+	var attributesValue interface{}
+	if attributes != nil {
+		attributesValue = *attributes
+	}
+	res, err := store.storage.DB.ExecContext(ctx, updateQuery, categoryId, name, attributesValue, time.Now())
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// MoveCategory moves the category with its subtree below the parent, or to the
+// roots without a parent. The category is put at the position among the
+// children of the parent, or after them without a position.
+func (store *AdminStore) MoveCategory(ctx context.Context, category *entity.Category, parent *entity.Category, position *int32) error {
+	tx, err := store.storage.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	var parentId *string
+	path := category.ID
+	if parent != nil {
+		parentId = &parent.ID
+		path = parent.Path + entity.PathSeparator + category.ID
+	}
+
+	var newPosition int32
+	if position == nil {
+		positionQuery := `SELECT COALESCE(MAX(position) + 1, 0) FROM categories WHERE parent_id IS NOT DISTINCT FROM $1 AND id != $2;`
+		err = tx.QueryRowContext(ctx, positionQuery, parentId, category.ID).Scan(&newPosition)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	} else {
+		newPosition = *position
+		shiftQuery := `UPDATE categories SET position = position + 1 WHERE parent_id IS NOT DISTINCT FROM $1 AND position >= $2 AND id != $3;`
+		_, err = tx.ExecContext(ctx, shiftQuery, parentId, newPosition, category.ID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	updateQuery := `UPDATE categories SET parent_id = $2, position = $3, updated_at = $4 WHERE id = $1;`
+	_, err = tx.ExecContext(ctx, updateQuery, category.ID, parentId, newPosition, time.Now())
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	pathQuery := `
+	UPDATE categories SET path = $2 || SUBSTRING(path FROM LENGTH($1) + 1)
+	WHERE path = $1 OR path LIKE $1 || '/%';
+	`
+	_, err = tx.ExecContext(ctx, pathQuery, category.Path, path)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ReorderCategories puts the categories at their index in the list.
+func (store *AdminStore) ReorderCategories(ctx context.Context, categoryIds []string) error {
+	tx, err := store.storage.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	updateQuery := `UPDATE categories SET position = $2, updated_at = $3 WHERE id = $1;`
+	updatedAt := time.Now()
+	for position, categoryId := range categoryIds {
+		_, err = tx.ExecContext(ctx, updateQuery, categoryId, position, updatedAt)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// SetCategoryArchived archives or restores the category with its subtree.
+func (store *AdminStore) SetCategoryArchived(ctx context.Context, category *entity.Category, archived bool) error {
+	updateQuery := `
+	UPDATE categories SET archived_at = CASE WHEN $2 THEN COALESCE(archived_at, $3) END, updated_at = $3
+	WHERE path = $1 OR path LIKE $1 || '/%';
+	`
+	_, err := store.storage.DB.ExecContext(ctx, updateQuery, category.Path, archived, time.Now())
+	return err
+}
@@ -2,15 +2,6 @@ package entity
 
 import "time"
 
-type AddCategoryReq struct {
-	Category_Name string `json:"category_name" validate:"required"`
-}
-
-type GetCategoriesRes struct {
-	ID       string `json:"id"`
-	Category string `json:"category"`
-}
-
 type GetUserRes struct {
 	ID           string     `json:"id"`
 	ProfileImage *string    `json:"profile_image,omitempty"`
@@ -37,3 +28,10 @@ type PromocodeRes struct {
 	Description string  `json:"description"`
 	Percentage  float64 `json:"percentage"`
 }
+
+// ZeroResultQuery is a search query that found no product.
+type ZeroResultQuery struct {
+	Query          string    `json:"query"`
+	Count          int64     `json:"count"`
+	LastSearchedAt time.Time `json:"last_searched_at"`
+}
@@ -0,0 +1,66 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PathSeparator separates the ids of the path of a category.
+const PathSeparator = "/"
+
+// CategoryAttribute is an attribute the products of a category can have,
+// Values are the values of an ENUM attribute.
+type CategoryAttribute struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Required bool     `json:"required,omitempty"`
+	Values   []string `json:"values,omitempty"`
+}
+
+// CategoryAttributes are stored as jsonb.
+type CategoryAttributes []CategoryAttribute
+
+func (attributes CategoryAttributes) Value() (driver.Value, error) {
+	if attributes == nil {
+		return "[]", nil
+	}
+	value, err := json.Marshal(attributes)
+	return string(value), err
+}
+
+func (attributes *CategoryAttributes) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return json.Unmarshal(src, attributes)
+	case string:
+		return json.Unmarshal([]byte(src), attributes)
+	}
+	return fmt.Errorf("cannot scan %T into CategoryAttributes", src)
+}
+
+type AddCategoryReq struct {
+	CategoryName string
+	ParentID     *string
+	Attributes   CategoryAttributes
+}
+
+// Category is a node of the category tree, Path holds the ids from the root
+// down to the category.
+type Category struct {
+	ID           string
+	ParentID     *string
+	Path         string
+	Position     int32
+	CategoryName string
+	Image        *string
+	Attributes   CategoryAttributes
+	ArchivedAt   *time.Time
+}
+
+// Contains tells whether the other category is the category or one of its descendants.
+func (category *Category) Contains(other *Category) bool {
+	return other.Path == category.Path || strings.HasPrefix(other.Path, category.Path+PathSeparator)
+}
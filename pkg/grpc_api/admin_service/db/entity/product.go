@@ -0,0 +1,36 @@
+package entity
+
+import "time"
+
+// The review actions of the admins on the products waiting to be published.
+const (
+	ProductApprove = "APPROVE"
+	ProductReject  = "REJECT"
+)
+
+// PendingProduct is a product its merchant sent for review.
+type PendingProduct struct {
+	ID           string     `json:"id"`
+	MerchantID   string     `json:"merchant_id"`
+	ProductName  string     `json:"product_name"`
+	Description  string     `json:"description"`
+	CategoryName *string    `json:"category_name,omitempty"`
+	Images       []string   `json:"images"`
+	Price        float64    `json:"price"`
+	Status       string     `json:"status"`
+	PublishAt    *time.Time `json:"publish_at,omitempty"`
+	UnpublishAt  *time.Time `json:"unpublish_at,omitempty"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+type ProductQueueRes struct {
+	Products []*PendingProduct `json:"products"`
+	Total    int64             `json:"total"`
+}
+
+// ReviewProductReq publishes the pending product, or sends it back to draft with the reason.
+type ReviewProductReq struct {
+	ProductID string
+	Status    string
+	Reason    *string
+}
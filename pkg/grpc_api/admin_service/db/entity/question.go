@@ -0,0 +1,59 @@
+package entity
+
+import "time"
+
+// The kinds of the posts in the moderation queue of the questions and the answers.
+const (
+	QnAQuestion = "QUESTION"
+	QnAAnswer   = "ANSWER"
+)
+
+// QnAQueueReq lists the questions and the answers with the status, the oldest first.
+type QnAQueueReq struct {
+	Status string
+	Limit  int
+	Offset int
+}
+
+// ModerationQnA is a question, or an answer with its question, as the moderators see it.
+type ModerationQnA struct {
+	ID               string     `json:"id"`
+	Kind             string     `json:"kind"`
+	QuestionID       string     `json:"question_id"`
+	ProductID        string     `json:"product_id"`
+	ProductName      string     `json:"product_name"`
+	UserID           string     `json:"user_id"`
+	AuthorName       string     `json:"author_name"`
+	Question         string     `json:"question"`
+	Answer           *string    `json:"answer,omitempty"`
+	Status           string     `json:"status"`
+	Flags            []string   `json:"flags"`
+	ModerationReason *string    `json:"moderation_reason,omitempty"`
+	ModeratedAt      *time.Time `json:"moderated_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+type QnAQueueRes struct {
+	Posts []*ModerationQnA `json:"posts"`
+	Total int64            `json:"total"`
+}
+
+// QuestionNotification is the notification of the merchant of the product when a
+// held question is approved. Body is formatted with the name of the product.
+type QuestionNotification struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+// ModerateQnAReq moves the question or the answer of the kind from the status From
+// to Status, and sends the Notification when it is set.
+type ModerateQnAReq struct {
+	Kind         string
+	ID           string
+	From         string
+	Status       string
+	Reason       *string
+	AdminID      string
+	Notification *QuestionNotification
+}
@@ -0,0 +1,49 @@
+package entity
+
+import "time"
+
+// The moderation actions of the admins on the reviews.
+const (
+	ModerationApprove = "APPROVE"
+	ModerationHide    = "HIDE"
+	ModerationRestore = "RESTORE"
+)
+
+// ReviewQueueReq lists the reviews with the status, the oldest first.
+type ReviewQueueReq struct {
+	Status string
+	Limit  int
+	Offset int
+}
+
+// ModerationReview is a review as the moderators see it, with the open reports by reason.
+type ModerationReview struct {
+	ID               string           `json:"id"`
+	ProductID        string           `json:"product_id"`
+	ProductName      string           `json:"product_name"`
+	UserID           string           `json:"user_id"`
+	AuthorName       string           `json:"author_name"`
+	Star             float32          `json:"star"`
+	Review           string           `json:"review"`
+	Images           []string         `json:"images"`
+	Status           string           `json:"status"`
+	Flags            []string         `json:"flags"`
+	Reports          map[string]int64 `json:"reports"`
+	ModerationReason *string          `json:"moderation_reason,omitempty"`
+	ModeratedAt      *time.Time       `json:"moderated_at,omitempty"`
+	CreatedAt        time.Time        `json:"created_at"`
+	UpdatedAt        time.Time        `json:"updated_at"`
+}
+
+type ReviewQueueRes struct {
+	Reviews []*ModerationReview `json:"reviews"`
+	Total   int64               `json:"total"`
+}
+
+// ModerateReviewReq sets the status of the review, resolving its open reports.
+type ModerateReviewReq struct {
+	ReviewID string
+	Status   string
+	Reason   *string
+	AdminID  string
+}
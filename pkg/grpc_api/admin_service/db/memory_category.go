@@ -0,0 +1,216 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/akmal4410/gestapo/internal/database/memory"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/admin_service/db/entity"
+	"github.com/google/uuid"
+)
+
+func (store *MemoryAdminStore) CheckCategoryExist(ctx context.Context, parentId *string, category string) (bool, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	for _, row := range store.db.Categories {
+		if sameParent(row.ParentID, parentId) && strings.EqualFold(row.CategoryName, category) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (store *MemoryAdminStore) AddCategory(ctx context.Context, req *entity.AddCategoryReq) (string, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	now := time.Now()
+	id := uuid.NewString()
+	category := &memory.Category{
+		ID:           id,
+		ParentID:     req.ParentID,
+		Path:         id,
+		Position:     store.nextPosition(req.ParentID, id),
+		CategoryName: req.CategoryName,
+		Attributes:   memoryAttributes(req.Attributes),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if req.ParentID != nil {
+		if parent, ok := store.db.Categories[*req.ParentID]; ok {
+			category.Path = parent.Path + entity.PathSeparator + id
+		}
+	}
+	store.db.Categories[id] = category
+	return id, nil
+}
+
+func (store *MemoryAdminStore) GetCategory(ctx context.Context, categoryId string) (*entity.Category, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	category, ok := store.db.Categories[categoryId]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return categoryEntity(category), nil
+}
+
+func (store *MemoryAdminStore) GetCategories(ctx context.Context) ([]*entity.Category, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	categories := []*entity.Category{}
+	for _, category := range store.db.Categories {
+		categories = append(categories, categoryEntity(category))
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].Position != categories[j].Position {
+			return categories[i].Position < categories[j].Position
+		}
+		return categories[i].CategoryName < categories[j].CategoryName
+	})
+	return categories, nil
+}
+
+func (store *MemoryAdminStore) UpdateCategory(ctx context.Context, categoryId string, name *string, attributes *entity.CategoryAttributes) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	category, ok := store.db.Categories[categoryId]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	if name != nil {
+		category.CategoryName = *name
+	}
+	if attributes != nil {
+		category.Attributes = memoryAttributes(*attributes)
+	}
+	category.UpdatedAt = time.Now()
+	return nil
+}
+
+func (store *MemoryAdminStore) MoveCategory(ctx context.Context, category *entity.Category, parent *entity.Category, position *int32) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	row, ok := store.db.Categories[category.ID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	var parentId *string
+	path := category.ID
+	if parent != nil {
+		parentId = &parent.ID
+		path = parent.Path + entity.PathSeparator + category.ID
+	}
+
+	if position == nil {
+		row.Position = store.nextPosition(parentId, category.ID)
+	} else {
+		for _, sibling := range store.db.Categories {
+			if sibling.ID != category.ID && sameParent(sibling.ParentID, parentId) && sibling.Position >= *position {
+				sibling.Position++
+			}
+		}
+		row.Position = *position
+	}
+	row.ParentID = parentId
+	row.UpdatedAt = time.Now()
+	for _, descendant := range store.subtree(category.Path) {
+		descendant.Path = path + strings.TrimPrefix(descendant.Path, category.Path)
+	}
+	return nil
+}
+
+func (store *MemoryAdminStore) ReorderCategories(ctx context.Context, categoryIds []string) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	now := time.Now()
+	for position, categoryId := range categoryIds {
+		if category, ok := store.db.Categories[categoryId]; ok {
+			category.Position = int32(position)
+			category.UpdatedAt = now
+		}
+	}
+	return nil
+}
+
+func (store *MemoryAdminStore) SetCategoryArchived(ctx context.Context, category *entity.Category, archived bool) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	now := time.Now()
+	for _, row := range store.subtree(category.Path) {
+		if !archived {
+			row.ArchivedAt = nil
+		} else if row.ArchivedAt == nil {
+			archivedAt := now
+			row.ArchivedAt = &archivedAt
+		}
+		row.UpdatedAt = now
+	}
+	return nil
+}
+
+// subtree returns the category of the path and its descendants.
+func (store *MemoryAdminStore) subtree(path string) []*memory.Category {
+	var categories []*memory.Category
+	for _, category := range store.db.Categories {
+		if category.Path == path || strings.HasPrefix(category.Path, path+entity.PathSeparator) {
+			categories = append(categories, category)
+		}
+	}
+	return categories
+}
+
+// nextPosition returns the position after the children of the parent but the category.
+func (store *MemoryAdminStore) nextPosition(parentId *string, categoryId string) int32 {
+	var position int32
+	for _, category := range store.db.Categories {
+		if category.ID != categoryId && sameParent(category.ParentID, parentId) && category.Position >= position {
+			position = category.Position + 1
+		}
+	}
+	return position
+}
+
+func sameParent(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func categoryEntity(category *memory.Category) *entity.Category {
+	attributes := entity.CategoryAttributes{}
+	for _, attribute := range category.Attributes {
+		attributes = append(attributes, entity.CategoryAttribute{
+			Name:     attribute.Name,
+			Type:     attribute.Type,
+			Required: attribute.Required,
+			Values:   append([]string(nil), attribute.Values...),
+		})
+	}
+	return &entity.Category{
+		ID:           category.ID,
+		ParentID:     category.ParentID,
+		Path:         category.Path,
+		Position:     category.Position,
+		CategoryName: category.CategoryName,
+		Image:        category.Image,
+		Attributes:   attributes,
+		ArchivedAt:   category.ArchivedAt,
+	}
+}
+
+func memoryAttributes(attributes entity.CategoryAttributes) []memory.CategoryAttribute {
+	var res []memory.CategoryAttribute
+	for _, attribute := range attributes {
+		res = append(res, memory.CategoryAttribute{
+			Name:     attribute.Name,
+			Type:     attribute.Type,
+			Required: attribute.Required,
+			Values:   append([]string(nil), attribute.Values...),
+		})
+	}
+	return res
+}
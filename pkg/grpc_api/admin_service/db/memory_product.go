@@ -0,0 +1,78 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/akmal4410/gestapo/internal/database/memory"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/admin_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/utils"
+)
+
+func (store *MemoryAdminStore) GetProductQueue(ctx context.Context, limit, offset int) (*entity.ProductQueueRes, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	var rows []*memory.Product
+	for _, product := range store.db.Products {
+		if product.Status == utils.ProductPendingReview && product.DeletedAt == nil {
+			rows = append(rows, product)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].UpdatedAt.Equal(rows[j].UpdatedAt) {
+			return rows[i].UpdatedAt.Before(rows[j].UpdatedAt)
+		}
+		return rows[i].ID < rows[j].ID
+	})
+
+	res := &entity.ProductQueueRes{Products: []*entity.PendingProduct{}, Total: int64(len(rows))}
+	if offset < len(rows) {
+		rows = rows[offset:]
+	} else {
+		rows = nil
+	}
+	if len(rows) > limit {
+		rows = rows[:limit]
+	}
+	for _, row := range rows {
+		res.Products = append(res.Products, &entity.PendingProduct{
+			ID:           row.ID,
+			MerchantID:   row.MerchantID,
+			ProductName:  row.ProductName,
+			Description:  row.Description,
+			CategoryName: store.db.CategoryName(row),
+			Images:       append([]string{}, row.Images...),
+			Price:        row.Price,
+			Status:       row.Status,
+			PublishAt:    row.PublishAt,
+			UnpublishAt:  row.UnpublishAt,
+			UpdatedAt:    row.UpdatedAt,
+		})
+	}
+	return res, nil
+}
+
+func (store *MemoryAdminStore) GetProductStatus(ctx context.Context, productId string) (string, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	product, ok := store.db.Products[productId]
+	if !ok || product.DeletedAt != nil {
+		return "", sql.ErrNoRows
+	}
+	return product.Status, nil
+}
+
+func (store *MemoryAdminStore) ReviewProduct(ctx context.Context, req *entity.ReviewProductReq) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	product, ok := store.db.Products[req.ProductID]
+	if !ok || product.DeletedAt != nil || product.Status != utils.ProductPendingReview {
+		return sql.ErrNoRows
+	}
+	product.Status = req.Status
+	product.StatusReason = req.Reason
+	product.UpdatedAt = time.Now()
+	return nil
+}
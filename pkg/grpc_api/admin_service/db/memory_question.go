@@ -0,0 +1,165 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/akmal4410/gestapo/internal/database/memory"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/admin_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+func (store *MemoryAdminStore) GetQnAQueue(ctx context.Context, req *entity.QnAQueueReq) (*entity.QnAQueueRes, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	posts := []*entity.ModerationQnA{}
+	for _, question := range store.db.Questions {
+		if question.Status != req.Status {
+			continue
+		}
+		post := store.qnaPost(question)
+		post.Kind = entity.QnAQuestion
+		post.ID = question.ID
+		post.UserID = question.UserID
+		post.Status = question.Status
+		post.Flags = append([]string{}, question.Flags...)
+		post.ModerationReason = question.ModerationReason
+		post.ModeratedAt = question.ModeratedAt
+		post.CreatedAt = question.CreatedAt
+		post.UpdatedAt = question.UpdatedAt
+		posts = append(posts, post)
+	}
+	for _, answer := range store.db.Answers {
+		question, ok := store.db.Questions[answer.QuestionID]
+		if answer.Status != req.Status || !ok {
+			continue
+		}
+		text := answer.Answer
+		post := store.qnaPost(question)
+		post.Kind = entity.QnAAnswer
+		post.ID = answer.ID
+		post.UserID = answer.UserID
+		post.Answer = &text
+		post.Status = answer.Status
+		post.Flags = append([]string{}, answer.Flags...)
+		post.ModerationReason = answer.ModerationReason
+		post.ModeratedAt = answer.ModeratedAt
+		post.CreatedAt = answer.CreatedAt
+		post.UpdatedAt = answer.UpdatedAt
+		posts = append(posts, post)
+	}
+	for _, post := range posts {
+		if user, ok := store.db.Users[post.UserID]; ok {
+			post.AuthorName = user.UserName
+			if user.FullName != nil && *user.FullName != "" {
+				post.AuthorName = *user.FullName
+			}
+		}
+	}
+	sort.Slice(posts, func(i, j int) bool {
+		if !posts[i].UpdatedAt.Equal(posts[j].UpdatedAt) {
+			return posts[i].UpdatedAt.Before(posts[j].UpdatedAt)
+		}
+		return posts[i].ID < posts[j].ID
+	})
+
+	res := &entity.QnAQueueRes{Total: int64(len(posts))}
+	if req.Offset < len(posts) {
+		posts = posts[req.Offset:]
+	} else {
+		posts = nil
+	}
+	if len(posts) > req.Limit {
+		posts = posts[:req.Limit]
+	}
+	res.Posts = append([]*entity.ModerationQnA{}, posts...)
+	return res, nil
+}
+
+// qnaPost returns a post with the question and its product. The caller must hold the lock.
+func (store *MemoryAdminStore) qnaPost(question *memory.Question) *entity.ModerationQnA {
+	post := &entity.ModerationQnA{
+		QuestionID: question.ID,
+		ProductID:  question.ProductID,
+		Question:   question.Question,
+	}
+	if product, ok := store.db.Products[question.ProductID]; ok {
+		post.ProductName = product.ProductName
+	}
+	return post
+}
+
+func (store *MemoryAdminStore) GetQnAStatus(ctx context.Context, kind, id string) (string, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	switch kind {
+	case entity.QnAQuestion:
+		if question, ok := store.db.Questions[id]; ok {
+			return question.Status, nil
+		}
+	case entity.QnAAnswer:
+		if answer, ok := store.db.Answers[id]; ok {
+			return answer.Status, nil
+		}
+	}
+	return "", sql.ErrNoRows
+}
+
+func (store *MemoryAdminStore) ModerateQnA(ctx context.Context, req *entity.ModerateQnAReq) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	now := time.Now()
+	adminId := req.AdminID
+	var productId string
+	switch req.Kind {
+	case entity.QnAQuestion:
+		question, ok := store.db.Questions[req.ID]
+		if !ok || question.Status != req.From {
+			return sql.ErrNoRows
+		}
+		question.Status = req.Status
+		question.ModerationReason = req.Reason
+		question.ModeratedBy = &adminId
+		question.ModeratedAt = &now
+		question.UpdatedAt = now
+		if req.Status == utils.ReviewPublished {
+			question.Flags = nil
+		}
+		productId = question.ProductID
+	case entity.QnAAnswer:
+		answer, ok := store.db.Answers[req.ID]
+		if !ok || answer.Status != req.From {
+			return sql.ErrNoRows
+		}
+		answer.Status = req.Status
+		answer.ModerationReason = req.Reason
+		answer.ModeratedBy = &adminId
+		answer.ModeratedAt = &now
+		answer.UpdatedAt = now
+		if req.Status == utils.ReviewPublished {
+			answer.Flags = nil
+		}
+	default:
+		return sql.ErrNoRows
+	}
+
+	product, ok := store.db.Products[productId]
+	if req.Notification == nil || !ok {
+		return nil
+	}
+	id := uuid.NewString()
+	store.db.Notifications[id] = &memory.Notification{
+		ID:        id,
+		UserID:    product.MerchantID,
+		Type:      utils.NotificationNewQuestion,
+		Title:     req.Notification.Title,
+		Body:      fmt.Sprintf(req.Notification.Body, product.ProductName),
+		ProductID: &productId,
+		CreatedAt: now,
+	}
+	return nil
+}
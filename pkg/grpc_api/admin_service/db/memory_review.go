@@ -0,0 +1,108 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/akmal4410/gestapo/internal/database/memory"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/admin_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/utils"
+)
+
+func (store *MemoryAdminStore) GetReviewQueue(ctx context.Context, req *entity.ReviewQueueReq) (*entity.ReviewQueueRes, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	var rows []*memory.Review
+	for _, review := range store.db.Reviews {
+		if review.Status == req.Status {
+			rows = append(rows, review)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].UpdatedAt.Equal(rows[j].UpdatedAt) {
+			return rows[i].UpdatedAt.Before(rows[j].UpdatedAt)
+		}
+		return rows[i].ID < rows[j].ID
+	})
+
+	res := &entity.ReviewQueueRes{Reviews: []*entity.ModerationReview{}, Total: int64(len(rows))}
+	if req.Offset < len(rows) {
+		rows = rows[req.Offset:]
+	} else {
+		rows = nil
+	}
+	if len(rows) > req.Limit {
+		rows = rows[:req.Limit]
+	}
+	for _, row := range rows {
+		review := &entity.ModerationReview{
+			ID:               row.ID,
+			ProductID:        row.ProductID,
+			UserID:           row.UserID,
+			Star:             row.Star,
+			Review:           row.Review,
+			Images:           append([]string{}, row.Images...),
+			Status:           row.Status,
+			Flags:            append([]string{}, row.Flags...),
+			Reports:          map[string]int64{},
+			ModerationReason: row.ModerationReason,
+			ModeratedAt:      row.ModeratedAt,
+			CreatedAt:        row.CreatedAt,
+			UpdatedAt:        row.UpdatedAt,
+		}
+		if product, ok := store.db.Products[row.ProductID]; ok {
+			review.ProductName = product.ProductName
+		}
+		if user, ok := store.db.Users[row.UserID]; ok {
+			review.AuthorName = user.UserName
+			if user.FullName != nil && *user.FullName != "" {
+				review.AuthorName = *user.FullName
+			}
+		}
+		for _, report := range store.db.ReviewReports {
+			if report.ReviewID == row.ID && report.ResolvedAt == nil {
+				review.Reports[report.Reason]++
+			}
+		}
+		res.Reviews = append(res.Reviews, review)
+	}
+	return res, nil
+}
+
+func (store *MemoryAdminStore) GetReviewStatus(ctx context.Context, reviewId string) (string, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	review, ok := store.db.Reviews[reviewId]
+	if !ok {
+		return "", sql.ErrNoRows
+	}
+	return review.Status, nil
+}
+
+func (store *MemoryAdminStore) ModerateReview(ctx context.Context, req *entity.ModerateReviewReq) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	review, ok := store.db.Reviews[req.ReviewID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	now := time.Now()
+	adminId := req.AdminID
+	review.Status = req.Status
+	review.ModerationReason = req.Reason
+	review.ModeratedBy = &adminId
+	review.ModeratedAt = &now
+	review.UpdatedAt = now
+	if req.Status == utils.ReviewPublished {
+		review.Flags = nil
+	}
+	for _, report := range store.db.ReviewReports {
+		if report.ReviewID == review.ID && report.ResolvedAt == nil {
+			report.ResolvedAt = &now
+			report.UpdatedAt = now
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/akmal4410/gestapo/internal/database/memory"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/admin_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+// MemoryAdminStore is the in-memory AdminRepository.
+type MemoryAdminStore struct {
+	db *memory.Database
+}
+
+func NewMemoryAdminStore(db *memory.Database) *MemoryAdminStore {
+	return &MemoryAdminStore{db: db}
+}
+
+func (store *MemoryAdminStore) GetUsers(ctx context.Context) ([]*entity.GetUserRes, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	var rows []*memory.User
+	for _, user := range store.db.Users {
+		if user.UserType != utils.ADMIN {
+			rows = append(rows, user)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].CreatedAt.Before(rows[j].CreatedAt)
+	})
+	var users []*entity.GetUserRes
+	for _, user := range rows {
+		users = append(users, &entity.GetUserRes{
+			ID:           user.ID,
+			ProfileImage: user.ProfileImage,
+			FullName:     user.FullName,
+			UserName:     user.UserName,
+			Phone:        user.Phone,
+			Email:        user.Email,
+			DOB:          user.DOB,
+			Gender:       user.Gender,
+			UserType:     user.UserType,
+		})
+	}
+	return users, nil
+}
+
+func (store *MemoryAdminStore) CheckPromocodeExist(ctx context.Context, promocode string) (bool, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	return store.db.Exists("promo_codes", "code", promocode)
+}
+
+func (store *MemoryAdminStore) AddPromocode(ctx context.Context, req *entity.AddPromocodeReq) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	now := time.Now()
+	id := uuid.NewString()
+	store.db.PromoCodes[id] = &memory.PromoCode{
+		ID:          id,
+		Code:        req.Code,
+		Title:       req.Title,
+		Description: req.Description,
+		Percent:     req.Percentage,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	return nil
+}
+
+func (store *MemoryAdminStore) GetPromocodes(ctx context.Context) ([]*entity.PromocodeRes, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	var rows []*memory.PromoCode
+	for _, promocode := range store.db.PromoCodes {
+		rows = append(rows, promocode)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].CreatedAt.After(rows[j].CreatedAt)
+	})
+	var promocodes []*entity.PromocodeRes
+	for _, promocode := range rows {
+		promocodes = append(promocodes, &entity.PromocodeRes{
+			ID:          promocode.ID,
+			Code:        promocode.Code,
+			Title:       promocode.Title,
+			Description: promocode.Description,
+			Percentage:  promocode.Percent,
+		})
+	}
+	return promocodes, nil
+}
+
+func (store *MemoryAdminStore) GetZeroResultQueries(ctx context.Context, since time.Time, limit int) ([]*entity.ZeroResultQuery, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	byQuery := map[string]*entity.ZeroResultQuery{}
+	for _, search := range store.db.SearchQueries {
+		if search.Results != 0 || search.CreatedAt.Before(since) {
+			continue
+		}
+		query, ok := byQuery[search.Query]
+		if !ok {
+			query = &entity.ZeroResultQuery{Query: search.Query}
+			byQuery[search.Query] = query
+		}
+		query.Count++
+		if search.CreatedAt.After(query.LastSearchedAt) {
+			query.LastSearchedAt = search.CreatedAt
+		}
+	}
+	queries := []*entity.ZeroResultQuery{}
+	for _, query := range byQuery {
+		queries = append(queries, query)
+	}
+	sort.Slice(queries, func(i, j int) bool {
+		if queries[i].Count != queries[j].Count {
+			return queries[i].Count > queries[j].Count
+		}
+		return queries[i].LastSearchedAt.After(queries[j].LastSearchedAt)
+	})
+	if len(queries) > limit {
+		queries = queries[:limit]
+	}
+	return queries, nil
+}
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/grpc_api/admin_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/lib/pq"
+)
+
+// GetProductQueue returns a page of the products waiting for a review, the ones waiting the longest first.
+func (store *AdminStore) GetProductQueue(ctx context.Context, limit, offset int) (*entity.ProductQueueRes, error) {
+	res := &entity.ProductQueueRes{Products: []*entity.PendingProduct{}}
+	countQuery := `SELECT COUNT(*) FROM products WHERE status = $1 AND deleted_at IS NULL;`
+	err := store.storage.DB.QueryRowContext(ctx, countQuery, utils.ProductPendingReview).Scan(&res.Total)
+	if err != nil {
+		return nil, err
+	}
+
+	selectQuery := `
+	SELECT p.id, p.merchent_id, p.product_name, p.description, c.category_name, p.images, p.price,
+	p.status, p.publish_at, p.unpublish_at, p.updated_at
+	FROM products p
+	LEFT JOIN categories c ON c.id = p.category_id
+	WHERE p.status = $1 AND p.deleted_at IS NULL
+	ORDER BY p.updated_at ASC, p.id
+	LIMIT $2 OFFSET $3;
+	`
+	rows, err := store.storage.DB.QueryContext(ctx, selectQuery, utils.ProductPendingReview, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var product entity.PendingProduct
+		var images pq.StringArray
+		err := rows.Scan(
+			&product.ID,
+			&product.MerchantID,
+			&product.ProductName,
+			&product.Description,
+			&product.CategoryName,
+			&images,
+			&product.Price,
+			&product.Status,
+			&product.PublishAt,
+			&product.UnpublishAt,
+			&product.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		product.Images = []string(images)
+		res.Products = append(res.Products, &product)
+	}
+	return res, rows.Err()
+}
+
+func (store *AdminStore) GetProductStatus(ctx context.Context, productId string) (string, error) {
+	var status string
+	selectQuery := `SELECT status FROM products WHERE id = $1 AND deleted_at IS NULL;`
+	err := store.storage.DB.QueryRowContext(ctx, selectQuery, productId).Scan(&status)
+	return status, err
+}
+
+// ReviewProduct sets the status of the pending product, it returns sql.ErrNoRows
+// when the product is not pending anymore.
+func (store *AdminStore) ReviewProduct(ctx context.Context, req *entity.ReviewProductReq) error {
+	updateQuery := `
+	UPDATE products SET status = $2, status_reason = $3, updated_at = $4
+	WHERE id = $1 AND status = $5 AND deleted_at IS NULL;
+	`
+	res, err := store.storage.DB.ExecContext(ctx, updateQuery, req.ProductID, req.Status, req.Reason, time.Now(), utils.ProductPendingReview)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
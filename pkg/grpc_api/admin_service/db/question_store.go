@@ -0,0 +1,141 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/grpc_api/admin_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// qnaTables are the tables of the questions and the answers, by kind.
+var qnaTables = map[string]string{
+	entity.QnAQuestion: "product_questions",
+	entity.QnAAnswer:   "product_answers",
+}
+
+// GetQnAQueue returns a page of the questions and the answers with the status, the
+// ones waiting the longest first.
+func (store *AdminStore) GetQnAQueue(ctx context.Context, req *entity.QnAQueueReq) (*entity.QnAQueueRes, error) {
+	res := &entity.QnAQueueRes{Posts: []*entity.ModerationQnA{}}
+	countQuery := `
+	SELECT (SELECT COUNT(*) FROM product_questions WHERE status = $1) + (SELECT COUNT(*) FROM product_answers WHERE status = $1);
+	`
+	err := store.storage.DB.QueryRowContext(ctx, countQuery, req.Status).Scan(&res.Total)
+	if err != nil {
+		return nil, err
+	}
+
+	selectQuery := `
+	SELECT * FROM (
+		SELECT 'QUESTION' AS kind, q.id, q.id AS question_id, q.product_id, p.product_name, q.user_id,
+		COALESCE(NULLIF(u.full_name, ''), u.user_name), q.question, NULL::text AS answer,
+		q.status, q.flags, q.moderation_reason, q.moderated_at, q.created_at, q.updated_at
+		FROM product_questions q
+		JOIN products p ON p.id = q.product_id
+		JOIN user_data u ON u.id = q.user_id
+		WHERE q.status = $1
+		UNION ALL
+		SELECT 'ANSWER', a.id, a.question_id, q.product_id, p.product_name, a.user_id,
+		COALESCE(NULLIF(u.full_name, ''), u.user_name), q.question, a.answer,
+		a.status, a.flags, a.moderation_reason, a.moderated_at, a.created_at, a.updated_at
+		FROM product_answers a
+		JOIN product_questions q ON q.id = a.question_id
+		JOIN products p ON p.id = q.product_id
+		JOIN user_data u ON u.id = a.user_id
+		WHERE a.status = $1
+	) posts
+	ORDER BY updated_at ASC, id
+	LIMIT $2 OFFSET $3;
+	`
+	rows, err := store.storage.DB.QueryContext(ctx, selectQuery, req.Status, req.Limit, req.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var post entity.ModerationQnA
+		var flags pq.StringArray
+		err := rows.Scan(
+			&post.Kind,
+			&post.ID,
+			&post.QuestionID,
+			&post.ProductID,
+			&post.ProductName,
+			&post.UserID,
+			&post.AuthorName,
+			&post.Question,
+			&post.Answer,
+			&post.Status,
+			&flags,
+			&post.ModerationReason,
+			&post.ModeratedAt,
+			&post.CreatedAt,
+			&post.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		post.Flags = []string(flags)
+		res.Posts = append(res.Posts, &post)
+	}
+	return res, rows.Err()
+}
+
+func (store *AdminStore) GetQnAStatus(ctx context.Context, kind, id string) (string, error) {
+	var status string
+	selectQuery := fmt.Sprintf(`SELECT status FROM %s WHERE id = $1;`, qnaTables[kind])
+	err := store.storage.DB.QueryRowContext(ctx, selectQuery, id).Scan(&status)
+	return status, err
+}
+
+// ModerateQnA sets the status of the question or the answer when it is still in the
+// status From, or returns sql.ErrNoRows. The flags are cleared when it is published.
+func (store *AdminStore) ModerateQnA(ctx context.Context, req *entity.ModerateQnAReq) error {
+	tx, err := store.storage.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	updateQuery := fmt.Sprintf(`
+	UPDATE %s
+	SET status = $2, moderation_reason = $3, moderated_by = $4, moderated_at = $5, updated_at = $5,
+	flags = CASE WHEN $2 = 'PUBLISHED' THEN '{}' ELSE flags END
+	WHERE id = $1 AND status = $6;
+	`, qnaTables[req.Kind])
+	res, err := tx.ExecContext(ctx, updateQuery, req.ID, req.Status, req.Reason, req.AdminID, now, req.From)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if n == 0 {
+		tx.Rollback()
+		return sql.ErrNoRows
+	}
+
+	if req.Notification != nil {
+		notifyQuery := `
+		INSERT INTO notifications (id, user_id, type, title, body, product_id, created_at)
+		SELECT $1, p.merchent_id, $2, $3, format($4, p.product_name), p.id, $5
+		FROM product_questions q JOIN products p ON p.id = q.product_id
+		WHERE q.id = $6;
+		`
+		_, err = tx.ExecContext(ctx, notifyQuery, uuid.New(), utils.NotificationNewQuestion, req.Notification.Title,
+			req.Notification.Body, now, req.ID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,42 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/grpc_api/admin_service/db/entity"
+)
+
+// AdminRepository is the storage used by the admin service,
+// implemented by AdminStore on Postgres and by MemoryAdminStore in memory.
+type AdminRepository interface {
+	CheckCategoryExist(ctx context.Context, parentId *string, category string) (bool, error)
+	AddCategory(ctx context.Context, req *entity.AddCategoryReq) (string, error)
+	GetCategory(ctx context.Context, categoryId string) (*entity.Category, error)
+	GetCategories(ctx context.Context) ([]*entity.Category, error)
+	UpdateCategory(ctx context.Context, categoryId string, name *string, attributes *entity.CategoryAttributes) error
+	MoveCategory(ctx context.Context, category *entity.Category, parent *entity.Category, position *int32) error
+	ReorderCategories(ctx context.Context, categoryIds []string) error
+	SetCategoryArchived(ctx context.Context, category *entity.Category, archived bool) error
+	GetUsers(ctx context.Context) ([]*entity.GetUserRes, error)
+	CheckPromocodeExist(ctx context.Context, promocode string) (bool, error)
+	AddPromocode(ctx context.Context, req *entity.AddPromocodeReq) error
+	GetPromocodes(ctx context.Context) ([]*entity.PromocodeRes, error)
+	GetZeroResultQueries(ctx context.Context, since time.Time, limit int) ([]*entity.ZeroResultQuery, error)
+	GetReviewQueue(ctx context.Context, req *entity.ReviewQueueReq) (*entity.ReviewQueueRes, error)
+	GetReviewStatus(ctx context.Context, reviewId string) (string, error)
+	ModerateReview(ctx context.Context, req *entity.ModerateReviewReq) error
+	GetProductQueue(ctx context.Context, limit, offset int) (*entity.ProductQueueRes, error)
+	// GetProductStatus returns the status of the product, unless it is deleted
+	GetProductStatus(ctx context.Context, productId string) (string, error)
+	ReviewProduct(ctx context.Context, req *entity.ReviewProductReq) error
+	GetQnAQueue(ctx context.Context, req *entity.QnAQueueReq) (*entity.QnAQueueRes, error)
+	// GetQnAStatus returns the status of the question or the answer, by kind
+	GetQnAStatus(ctx context.Context, kind, id string) (string, error)
+	ModerateQnA(ctx context.Context, req *entity.ModerateQnAReq) error
+}
+
+var (
+	_ AdminRepository = (*AdminStore)(nil)
+	_ AdminRepository = (*MemoryAdminStore)(nil)
+)
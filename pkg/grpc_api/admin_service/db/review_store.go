@@ -0,0 +1,140 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/grpc_api/admin_service/db/entity"
+	"github.com/lib/pq"
+)
+
+// GetReviewQueue returns a page of the reviews with the status, the ones waiting the longest first.
+func (store *AdminStore) GetReviewQueue(ctx context.Context, req *entity.ReviewQueueReq) (*entity.ReviewQueueRes, error) {
+	res := &entity.ReviewQueueRes{Reviews: []*entity.ModerationReview{}}
+	countQuery := `SELECT COUNT(*) FROM reviews WHERE status = $1;`
+	err := store.storage.DB.QueryRowContext(ctx, countQuery, req.Status).Scan(&res.Total)
+	if err != nil {
+		return nil, err
+	}
+
+	selectQuery := `
+	SELECT r.id, r.product_id, p.product_name, r.user_id, COALESCE(NULLIF(u.full_name, ''), u.user_name),
+	r.star, r.review, r.images, r.status, r.flags, r.moderation_reason, r.moderated_at, r.created_at, r.updated_at
+	FROM reviews r
+	JOIN products p ON p.id = r.product_id
+	JOIN user_data u ON u.id = r.user_id
+	WHERE r.status = $1
+	ORDER BY r.updated_at ASC, r.id
+	LIMIT $2 OFFSET $3;
+	`
+	rows, err := store.storage.DB.QueryContext(ctx, selectQuery, req.Status, req.Limit, req.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews := map[string]*entity.ModerationReview{}
+	var ids []string
+	for rows.Next() {
+		review := entity.ModerationReview{Reports: map[string]int64{}}
+		var images, flags pq.StringArray
+		err := rows.Scan(
+			&review.ID,
+			&review.ProductID,
+			&review.ProductName,
+			&review.UserID,
+			&review.AuthorName,
+			&review.Star,
+			&review.Review,
+			&images,
+			&review.Status,
+			&flags,
+			&review.ModerationReason,
+			&review.ModeratedAt,
+			&review.CreatedAt,
+			&review.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		review.Images = []string(images)
+		review.Flags = []string(flags)
+		res.Reviews = append(res.Reviews, &review)
+		reviews[review.ID] = &review
+		ids = append(ids, review.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return res, nil
+	}
+
+	reportQuery := `
+	SELECT review_id, reason, COUNT(*)
+	FROM review_reports
+	WHERE review_id::text = ANY($1) AND resolved_at IS NULL
+	GROUP BY review_id, reason;
+	`
+	reportRows, err := store.storage.DB.QueryContext(ctx, reportQuery, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer reportRows.Close()
+	for reportRows.Next() {
+		var reviewId, reason string
+		var count int64
+		if err := reportRows.Scan(&reviewId, &reason, &count); err != nil {
+			return nil, err
+		}
+		reviews[reviewId].Reports[reason] = count
+	}
+	return res, reportRows.Err()
+}
+
+func (store *AdminStore) GetReviewStatus(ctx context.Context, reviewId string) (string, error) {
+	var status string
+	selectQuery := `SELECT status FROM reviews WHERE id = $1;`
+	err := store.storage.DB.QueryRowContext(ctx, selectQuery, reviewId).Scan(&status)
+	return status, err
+}
+
+// ModerateReview sets the status of the review and resolves its open reports, the
+// flags are cleared when the review is published.
+func (store *AdminStore) ModerateReview(ctx context.Context, req *entity.ModerateReviewReq) error {
+	tx, err := store.storage.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	updateQuery := `
+	UPDATE reviews
+	SET status = $2, moderation_reason = $3, moderated_by = $4, moderated_at = $5, updated_at = $5,
+	flags = CASE WHEN $2 = 'PUBLISHED' THEN '{}' ELSE flags END
+	WHERE id = $1;
+	`
+	res, err := tx.ExecContext(ctx, updateQuery, req.ReviewID, req.Status, req.Reason, req.AdminID, now)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if n == 0 {
+		tx.Rollback()
+		return sql.ErrNoRows
+	}
+
+	resolveQuery := `UPDATE review_reports SET resolved_at = $2, updated_at = $2 WHERE review_id = $1 AND resolved_at IS NULL;`
+	_, err = tx.ExecContext(ctx, resolveQuery, req.ReviewID, now)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
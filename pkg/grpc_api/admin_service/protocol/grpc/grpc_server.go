@@ -20,23 +20,12 @@ import (
 
 func RunGRPCService(ctx context.Context, storage *database.Storage, config *config.Config, log logger.Logger) error {
 
-	service := service.NewAdminService(storage, log)
+	service := service.NewAdminService(storage, config, log)
 	tokenMaker, err := token.NewJWTMaker(config.TokenSymmetricKey)
 	if err != nil {
 		log.LogFatal("Error while Initializing NewJWTMaker %w", err)
 	}
-	interceptor := interceptor.NewInterceptor(tokenMaker, log)
-
-	grpcServer := grpc.NewServer(
-		grpc.ChainUnaryInterceptor(
-			interceptor.AccessMiddleware(),
-			interceptor.MerchantRoleMiddleware(),
-		),
-	)
-
-	proto.RegisterAdminServiceServer(grpcServer, service)
-	log.LogInfo("Registreing for reflection")
-	reflection.Register(grpcServer)
+	grpcServer := NewGRPCServer(service, tokenMaker, log)
 	port := ":" + config.ServerAddress.Admin.Port
 	lis, err := net.Listen("tcp", port)
 	if err != nil {
@@ -58,3 +47,21 @@ func RunGRPCService(ctx context.Context, storage *database.Storage, config *conf
 	log.LogInfo("Start gRPC server at ", lis.Addr().String())
 	return grpcServer.Serve(lis)
 }
+
+// NewGRPCServer creates the gRPC server of the admin service with its interceptors.
+func NewGRPCServer(service proto.AdminServiceServer, tokenMaker token.Maker, log logger.Logger) *grpc.Server {
+	interceptor := interceptor.NewInterceptor(tokenMaker, log)
+
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			interceptor.LoggingMiddleware(),
+			interceptor.AccessMiddleware(),
+			interceptor.MerchantRoleMiddleware(),
+		),
+	)
+
+	proto.RegisterAdminServiceServer(grpcServer, service)
+	log.LogInfo("Registreing for reflection")
+	reflection.Register(grpcServer)
+	return grpcServer
+}
@@ -0,0 +1,487 @@
+package grpc_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akmal4410/gestapo/internal/config"
+	"github.com/akmal4410/gestapo/internal/testenv"
+	"github.com/akmal4410/gestapo/pkg/api/proto"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func assertCode(t *testing.T, err error, code codes.Code) {
+	t.Helper()
+	if status.Code(err) != code {
+		t.Fatalf("expected %s, got %v", code, err)
+	}
+}
+
+func TestCategories(t *testing.T) {
+	env := testenv.New(t)
+	client := env.AdminClient(t)
+	_, adminToken := env.AddUser(t, "admin", utils.ADMIN)
+	ctx := testenv.WithToken(context.Background(), adminToken)
+
+	_, err := client.CreateCategory(context.Background(), &proto.AddCategoryRequest{CategoryName: "Shoes"})
+	assertCode(t, err, codes.Unauthenticated)
+
+	_, err = client.CreateCategory(ctx, &proto.AddCategoryRequest{})
+	assertCode(t, err, codes.InvalidArgument)
+
+	if _, err = client.CreateCategory(ctx, &proto.AddCategoryRequest{CategoryName: "Shoes"}); err != nil {
+		t.Fatalf("CreateCategory: %v", err)
+	}
+	_, err = client.CreateCategory(ctx, &proto.AddCategoryRequest{CategoryName: "Shoes"})
+	assertCode(t, err, codes.AlreadyExists)
+
+	res, err := client.GetCategories(ctx, &proto.GetCategoriesRequest{})
+	if err != nil {
+		t.Fatalf("GetCategories: %v", err)
+	}
+	if len(res.Data) != 1 || res.Data[0].Category != "Shoes" {
+		t.Fatalf("unexpected categories %v", res.Data)
+	}
+}
+
+func TestCategoryTree(t *testing.T) {
+	env := testenv.New(t)
+	client := env.AdminClient(t)
+	_, adminToken := env.AddUser(t, "admin", utils.ADMIN)
+	_, userToken := env.AddUser(t, "user", utils.USER)
+	ctx := testenv.WithToken(context.Background(), adminToken)
+	userCtx := testenv.WithToken(context.Background(), userToken)
+
+	_, err := client.CreateCategory(userCtx, &proto.AddCategoryRequest{CategoryName: "Men"})
+	assertCode(t, err, codes.PermissionDenied)
+	_, err = client.CreateCategory(ctx, &proto.AddCategoryRequest{CategoryName: "Men", Attributes: []*proto.CategoryAttribute{
+		{Name: "material", Type: "COLOR"},
+	}})
+	assertCode(t, err, codes.InvalidArgument)
+	_, err = client.CreateCategory(ctx, &proto.AddCategoryRequest{CategoryName: "Men", Attributes: []*proto.CategoryAttribute{
+		{Name: "fit", Type: utils.AttributeEnum},
+	}})
+	assertCode(t, err, codes.InvalidArgument)
+
+	create := func(name string, parent *proto.CategoryRes) {
+		t.Helper()
+		req := &proto.AddCategoryRequest{CategoryName: name}
+		if parent != nil {
+			req.ParentId = &parent.Id
+		}
+		if _, err := client.CreateCategory(ctx, req); err != nil {
+			t.Fatalf("CreateCategory %s: %v", name, err)
+		}
+	}
+	tree := func(req *proto.GetCategoriesRequest) []*proto.CategoryRes {
+		t.Helper()
+		res, err := client.GetCategories(ctx, req)
+		if err != nil {
+			t.Fatalf("GetCategories: %v", err)
+		}
+		return res.Data
+	}
+	names := func(categories []*proto.CategoryRes) string {
+		var names []string
+		for _, category := range categories {
+			names = append(names, category.Category)
+		}
+		return strings.Join(names, ",")
+	}
+
+	create("Men", nil)
+	create("Women", nil)
+	roots := tree(&proto.GetCategoriesRequest{})
+	men, women := roots[0], roots[1]
+	create("Shoes", men)
+	create("Shoes", women)
+	create("Shirts", men)
+	_, err = client.CreateCategory(ctx, &proto.AddCategoryRequest{CategoryName: "shoes", ParentId: &men.Id})
+	assertCode(t, err, codes.AlreadyExists)
+
+	roots = tree(&proto.GetCategoriesRequest{})
+	if names(roots) != "Men,Women" || names(roots[0].Children) != "Shoes,Shirts" || names(roots[1].Children) != "Shoes" {
+		t.Fatalf("unexpected tree %v", roots)
+	}
+	menShoes, shirts := roots[0].Children[0], roots[0].Children[1]
+	if menShoes.GetParentId() != men.Id || menShoes.Path != men.Id+"/"+menShoes.Id {
+		t.Fatalf("unexpected subcategory %v", menShoes)
+	}
+
+	_, err = client.UpdateCategory(ctx, &proto.UpdateCategoryRequest{Id: men.Id, Attributes: &proto.CategoryAttributes{
+		Attributes: []*proto.CategoryAttribute{{Name: "material", Type: "text", Required: true}},
+	}})
+	if err != nil {
+		t.Fatalf("UpdateCategory: %v", err)
+	}
+	name := "Shirts"
+	_, err = client.UpdateCategory(ctx, &proto.UpdateCategoryRequest{Id: menShoes.Id, CategoryName: &name})
+	assertCode(t, err, codes.AlreadyExists)
+	name = "Sneakers"
+	if _, err = client.UpdateCategory(ctx, &proto.UpdateCategoryRequest{Id: menShoes.Id, CategoryName: &name}); err != nil {
+		t.Fatalf("UpdateCategory: %v", err)
+	}
+
+	_, err = client.MoveCategory(ctx, &proto.MoveCategoryRequest{Id: men.Id, ParentId: &menShoes.Id})
+	assertCode(t, err, codes.FailedPrecondition)
+	if _, err = client.MoveCategory(ctx, &proto.MoveCategoryRequest{Id: shirts.Id, ParentId: &menShoes.Id}); err != nil {
+		t.Fatalf("MoveCategory: %v", err)
+	}
+	if _, err = client.MoveCategory(ctx, &proto.MoveCategoryRequest{Id: menShoes.Id, ParentId: &women.Id}); err != nil {
+		t.Fatalf("MoveCategory: %v", err)
+	}
+	subtree := tree(&proto.GetCategoriesRequest{CategoryId: &women.Id})
+	if len(subtree) != 1 || names(subtree[0].Children) != "Shoes,Sneakers" || names(subtree[0].Children[1].Children) != "Shirts" {
+		t.Fatalf("unexpected subtree %v", subtree)
+	}
+	moved := subtree[0].Children[1].Children[0]
+	if moved.Path != women.Id+"/"+menShoes.Id+"/"+shirts.Id {
+		t.Fatalf("expected the path of the subtree to follow the move, got %s", moved.Path)
+	}
+
+	_, err = client.ReorderCategories(ctx, &proto.ReorderCategoriesRequest{ParentId: &women.Id, CategoryIds: []string{menShoes.Id}})
+	assertCode(t, err, codes.InvalidArgument)
+	womenShoes := subtree[0].Children[0]
+	_, err = client.ReorderCategories(ctx, &proto.ReorderCategoriesRequest{ParentId: &women.Id, CategoryIds: []string{menShoes.Id, womenShoes.Id}})
+	if err != nil {
+		t.Fatalf("ReorderCategories: %v", err)
+	}
+	if subtree = tree(&proto.GetCategoriesRequest{CategoryId: &women.Id}); names(subtree[0].Children) != "Sneakers,Shoes" {
+		t.Fatalf("unexpected order %v", subtree[0].Children)
+	}
+
+	if _, err = client.ArchiveCategory(ctx, &proto.CategoryIdRequest{Id: menShoes.Id}); err != nil {
+		t.Fatalf("ArchiveCategory: %v", err)
+	}
+	if subtree = tree(&proto.GetCategoriesRequest{CategoryId: &women.Id}); names(subtree[0].Children) != "Shoes" {
+		t.Fatalf("expected the archived category to be hidden, got %v", subtree[0].Children)
+	}
+	_, err = client.GetCategories(userCtx, &proto.GetCategoriesRequest{IncludeArchived: true})
+	assertCode(t, err, codes.PermissionDenied)
+	subtree = tree(&proto.GetCategoriesRequest{CategoryId: &women.Id, IncludeArchived: true})
+	if sneakers := subtree[0].Children[0]; !sneakers.Archived || !sneakers.Children[0].Archived {
+		t.Fatalf("expected the subtree to be archived, got %v", sneakers)
+	}
+	_, err = client.CreateCategory(ctx, &proto.AddCategoryRequest{CategoryName: "Boots", ParentId: &menShoes.Id})
+	assertCode(t, err, codes.FailedPrecondition)
+	_, err = client.RestoreCategory(ctx, &proto.CategoryIdRequest{Id: shirts.Id})
+	assertCode(t, err, codes.FailedPrecondition)
+	if _, err = client.RestoreCategory(ctx, &proto.CategoryIdRequest{Id: menShoes.Id}); err != nil {
+		t.Fatalf("RestoreCategory: %v", err)
+	}
+	if subtree = tree(&proto.GetCategoriesRequest{CategoryId: &women.Id}); names(subtree[0].Children) != "Sneakers,Shoes" {
+		t.Fatalf("expected the category to be restored, got %v", subtree[0].Children)
+	}
+
+	roots = tree(&proto.GetCategoriesRequest{})
+	if len(roots[0].Attributes) != 1 || roots[0].Attributes[0].Type != utils.AttributeText || !roots[0].Attributes[0].Required {
+		t.Fatalf("unexpected attributes %v", roots[0].Attributes)
+	}
+}
+
+func TestPromocodes(t *testing.T) {
+	env := testenv.New(t)
+	client := env.AdminClient(t)
+	_, adminToken := env.AddUser(t, "admin", utils.ADMIN)
+	ctx := testenv.WithToken(context.Background(), adminToken)
+
+	_, err := client.CreatePromocode(ctx, &proto.CreatePromocodeRequest{Code: "SAVE10", Title: "Save"})
+	assertCode(t, err, codes.InvalidArgument)
+
+	req := &proto.CreatePromocodeRequest{Code: "SAVE10", Title: "Save", Description: "Save 10%", Percentage: 10}
+	if _, err = client.CreatePromocode(ctx, req); err != nil {
+		t.Fatalf("CreatePromocode: %v", err)
+	}
+	_, err = client.CreatePromocode(ctx, req)
+	assertCode(t, err, codes.AlreadyExists)
+
+	res, err := client.GetPromocodes(ctx, &proto.Request{})
+	if err != nil {
+		t.Fatalf("GetPromocodes: %v", err)
+	}
+	if len(res.Data) != 1 || res.Data[0].Code != "SAVE10" || res.Data[0].Percentage != 10 {
+		t.Fatalf("unexpected promocodes %v", res.Data)
+	}
+}
+
+func TestGetUsers(t *testing.T) {
+	env := testenv.New(t)
+	client := env.AdminClient(t)
+	_, adminToken := env.AddUser(t, "admin", utils.ADMIN)
+	env.AddUser(t, "buyer", utils.USER)
+	env.AddUser(t, "seller", utils.MERCHANT)
+
+	res, err := client.GetUsers(testenv.WithToken(context.Background(), adminToken), &proto.Request{})
+	if err != nil {
+		t.Fatalf("GetUsers: %v", err)
+	}
+	if len(res.Data) != 2 {
+		t.Fatalf("expected the two non admin users, got %v", res.Data)
+	}
+}
+
+func TestGetZeroResultQueries(t *testing.T) {
+	env := testenv.New(t)
+	client := env.AdminClient(t)
+	_, adminToken := env.AddUser(t, "admin", utils.ADMIN)
+	_, userToken := env.AddUser(t, "user", utils.USER)
+	products := env.ProductClient(t)
+	userCtx := testenv.WithToken(context.Background(), userToken)
+	for _, query := range []string{"unicorn", "Unicorn", "dragon", "unicorn"} {
+		if _, err := products.SearchProducts(userCtx, &proto.SearchProductsRequest{Query: query}); err != nil {
+			t.Fatalf("SearchProducts: %v", err)
+		}
+	}
+
+	_, err := client.GetZeroResultQueries(userCtx, &proto.GetZeroResultQueriesRequest{})
+	assertCode(t, err, codes.PermissionDenied)
+
+	ctx := testenv.WithToken(context.Background(), adminToken)
+	res, err := client.GetZeroResultQueries(ctx, &proto.GetZeroResultQueriesRequest{})
+	if err != nil {
+		t.Fatalf("GetZeroResultQueries: %v", err)
+	}
+	if len(res.Data) != 2 || res.Data[0].Query != "unicorn" || res.Data[0].Count != 3 || res.Data[1].Query != "dragon" {
+		t.Fatalf("unexpected queries %v", res.Data)
+	}
+
+	_, err = client.GetZeroResultQueries(ctx, &proto.GetZeroResultQueriesRequest{Days: 365})
+	assertCode(t, err, codes.InvalidArgument)
+}
+
+func TestReviewModeration(t *testing.T) {
+	env := testenv.New(t)
+	env.Config.Moderation = &config.Moderation{BannedWords: []string{"scam"}}
+	client := env.AdminClient(t)
+	products := env.ProductClient(t)
+	_, adminToken := env.AddUser(t, "admin", utils.ADMIN)
+	merchant, _ := env.AddUser(t, "merchant", utils.MERCHANT)
+	user, userToken := env.AddUser(t, "user", utils.USER)
+	category := env.AddCategory(t, "Shoes")
+	product := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5, 8)
+	ctx := testenv.WithToken(context.Background(), adminToken)
+	userCtx := testenv.WithToken(context.Background(), userToken)
+
+	item := env.AddOrderItem(t, user.ID, product, utils.OrderCompleted)
+	serviceCtx := env.WithServiceToken(t, context.Background(), user.ID, utils.USER, "product")
+	_, err := products.AddProductReview(serviceCtx, &proto.AddReviewRequest{
+		ProductId: product.ID, OrderItemId: item.ID, Start: 1, Review: "not a scam, just bad",
+	})
+	if err != nil {
+		t.Fatalf("AddProductReview: %v", err)
+	}
+	published := func() int64 {
+		t.Helper()
+		res, err := products.GetProductReviews(userCtx, &proto.GetProductReviewsRequest{ProductId: product.ID})
+		if err != nil {
+			t.Fatalf("GetProductReviews: %v", err)
+		}
+		return res.Data.Total
+	}
+
+	_, err = client.GetReviewQueue(userCtx, &proto.GetReviewQueueRequest{})
+	assertCode(t, err, codes.PermissionDenied)
+	_, err = client.GetReviewQueue(ctx, &proto.GetReviewQueueRequest{Status: "DELETED"})
+	assertCode(t, err, codes.InvalidArgument)
+	queue := func(reviewStatus string) *proto.ReviewQueueData {
+		t.Helper()
+		res, err := client.GetReviewQueue(ctx, &proto.GetReviewQueueRequest{Status: reviewStatus})
+		if err != nil {
+			t.Fatalf("GetReviewQueue: %v", err)
+		}
+		return res.Data
+	}
+	data := queue("")
+	if data.Total != 1 || data.Reviews[0].ProductName != "runner" || len(data.Reviews[0].Flags) != 1 || data.Reviews[0].Flags[0] != "BANNED_WORD" {
+		t.Fatalf("unexpected queue %v", data.Reviews)
+	}
+	reviewId := data.Reviews[0].Id
+
+	moderate := func(action, reason string) error {
+		_, err := client.ModerateReview(ctx, &proto.ModerateReviewRequest{ReviewId: reviewId, Action: action, Reason: reason})
+		return err
+	}
+	assertCode(t, moderate("DELETE", ""), codes.InvalidArgument)
+	assertCode(t, moderate("HIDE", ""), codes.InvalidArgument)
+	assertCode(t, moderate("RESTORE", ""), codes.FailedPrecondition)
+	if err := moderate("APPROVE", ""); err != nil {
+		t.Fatalf("ModerateReview: %v", err)
+	}
+	if published() != 1 || queue("").Total != 0 {
+		t.Fatalf("expected the approved review to be published")
+	}
+
+	if err := moderate("HIDE", "insults the merchant"); err != nil {
+		t.Fatalf("ModerateReview: %v", err)
+	}
+	if published() != 0 {
+		t.Fatalf("expected the hidden review out of the listing")
+	}
+	data = queue("HIDDEN")
+	if data.Total != 1 || data.Reviews[0].GetModerationReason() != "insults the merchant" || data.Reviews[0].ModeratedAt == nil {
+		t.Fatalf("unexpected hidden reviews %v", data.Reviews)
+	}
+	if err := moderate("RESTORE", ""); err != nil {
+		t.Fatalf("ModerateReview: %v", err)
+	}
+	if published() != 1 {
+		t.Fatalf("expected the restored review to be published")
+	}
+	_, err = client.ModerateReview(ctx, &proto.ModerateReviewRequest{ReviewId: "6f1d1a9e-3c56-4d0b-9a55-5a8a3f0f2b11", Action: "APPROVE"})
+	assertCode(t, err, codes.NotFound)
+}
+
+func TestProductQueue(t *testing.T) {
+	env := testenv.New(t)
+	client := env.AdminClient(t)
+	_, adminToken := env.AddUser(t, "admin", utils.ADMIN)
+	merchant, merchantToken := env.AddUser(t, "merchant", utils.MERCHANT)
+	category := env.AddCategory(t, "Shoes")
+	pending := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5, 8)
+	deleted := env.AddProduct(t, merchant.ID, category.ID, "walker", 80, 5, 8)
+	listed := env.AddProduct(t, merchant.ID, category.ID, "hiker", 120, 5, 8)
+	env.DB.Lock()
+	now := time.Now()
+	pending.Status, deleted.Status, deleted.DeletedAt = utils.ProductPendingReview, utils.ProductPendingReview, &now
+	env.DB.Unlock()
+	ctx := testenv.WithToken(context.Background(), adminToken)
+
+	_, err := client.GetProductQueue(testenv.WithToken(context.Background(), merchantToken), &proto.GetProductQueueRequest{})
+	assertCode(t, err, codes.PermissionDenied)
+	_, err = client.GetProductQueue(ctx, &proto.GetProductQueueRequest{PageSize: 1000})
+	assertCode(t, err, codes.InvalidArgument)
+
+	res, err := client.GetProductQueue(ctx, &proto.GetProductQueueRequest{})
+	if err != nil {
+		t.Fatalf("GetProductQueue: %v", err)
+	}
+	if res.Data.Total != 1 || res.Data.Products[0].Id != pending.ID || res.Data.Products[0].GetStatus() != utils.ProductPendingReview {
+		t.Fatalf("expected the pending product only, got %v", res.Data.Products)
+	}
+
+	review := func(productId, action, reason string) error {
+		_, err := client.ReviewProduct(ctx, &proto.ReviewProductRequest{ProductId: productId, Action: action, Reason: reason})
+		return err
+	}
+	assertCode(t, review(pending.ID, "PUBLISH", ""), codes.InvalidArgument)
+	assertCode(t, review(pending.ID, "REJECT", " "), codes.InvalidArgument)
+	assertCode(t, review(deleted.ID, "APPROVE", ""), codes.NotFound)
+	assertCode(t, review(listed.ID, "APPROVE", ""), codes.FailedPrecondition)
+	if err := review(pending.ID, "APPROVE", ""); err != nil {
+		t.Fatalf("ReviewProduct: %v", err)
+	}
+	env.DB.Lock()
+	productStatus := pending.Status
+	env.DB.Unlock()
+	if productStatus != utils.ProductPublished {
+		t.Fatalf("expected the approved product published, got %s", productStatus)
+	}
+	res, err = client.GetProductQueue(ctx, &proto.GetProductQueueRequest{})
+	if err != nil || res.Data.Total != 0 {
+		t.Fatalf("expected an empty queue, got %v %v", res, err)
+	}
+}
+
+func TestQnAModeration(t *testing.T) {
+	env := testenv.New(t)
+	env.Config.Moderation = &config.Moderation{BannedWords: []string{"scam"}}
+	client := env.AdminClient(t)
+	products := env.ProductClient(t)
+	_, adminToken := env.AddUser(t, "admin", utils.ADMIN)
+	merchant, merchantToken := env.AddUser(t, "merchant", utils.MERCHANT)
+	_, userToken := env.AddUser(t, "user", utils.USER)
+	category := env.AddCategory(t, "Shoes")
+	product := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5, 8)
+	ctx := testenv.WithToken(context.Background(), adminToken)
+	userCtx := testenv.WithToken(context.Background(), userToken)
+	merchantCtx := testenv.WithToken(context.Background(), merchantToken)
+
+	_, err := products.AskQuestion(userCtx, &proto.AskQuestionRequest{ProductId: product.ID, Question: "Is this a scam?"})
+	if err != nil {
+		t.Fatalf("AskQuestion: %v", err)
+	}
+	notifications := func() int {
+		env.DB.Lock()
+		defer env.DB.Unlock()
+		return len(env.DB.Notifications)
+	}
+	questions := func() *proto.ProductQuestionsData {
+		t.Helper()
+		res, err := products.GetProductQuestions(userCtx, &proto.GetProductQuestionsRequest{ProductId: product.ID})
+		if err != nil {
+			t.Fatalf("GetProductQuestions: %v", err)
+		}
+		return res.Data
+	}
+	queue := func(postStatus string) *proto.QnAQueueData {
+		t.Helper()
+		res, err := client.GetQnAQueue(ctx, &proto.GetQnAQueueRequest{Status: postStatus})
+		if err != nil {
+			t.Fatalf("GetQnAQueue: %v", err)
+		}
+		return res.Data
+	}
+
+	_, err = client.GetQnAQueue(userCtx, &proto.GetQnAQueueRequest{})
+	assertCode(t, err, codes.PermissionDenied)
+	data := queue("")
+	if data.Total != 1 || data.Posts[0].Kind != "QUESTION" || data.Posts[0].ProductName != "runner" || data.Posts[0].Flags[0] != "BANNED_WORD" {
+		t.Fatalf("unexpected queue %v", data.Posts)
+	}
+	questionId := data.Posts[0].Id
+	if notifications() != 0 || questions().Total != 0 {
+		t.Fatalf("expected the held question to be unlisted and the merchant not notified")
+	}
+
+	moderate := func(moderate func(context.Context, *proto.ModerateQnARequest, ...grpc.CallOption) (*proto.Response, error), id, action, reason string) error {
+		_, err := moderate(ctx, &proto.ModerateQnARequest{Id: id, Action: action, Reason: reason})
+		return err
+	}
+	assertCode(t, moderate(client.ModerateQuestion, questionId, "HIDE", ""), codes.InvalidArgument)
+	assertCode(t, moderate(client.ModerateQuestion, questionId, "RESTORE", ""), codes.FailedPrecondition)
+	assertCode(t, moderate(client.ModerateAnswer, questionId, "APPROVE", ""), codes.NotFound)
+	if err := moderate(client.ModerateQuestion, questionId, "APPROVE", ""); err != nil {
+		t.Fatalf("ModerateQuestion: %v", err)
+	}
+	if notifications() != 1 || questions().Total != 1 || queue("").Total != 0 {
+		t.Fatalf("expected the approved question to be published and the merchant notified")
+	}
+
+	_, err = products.AnswerQuestion(merchantCtx, &proto.AnswerQuestionRequest{QuestionId: questionId, Answer: "No scam here"})
+	if err != nil {
+		t.Fatalf("AnswerQuestion: %v", err)
+	}
+	data = queue("PENDING")
+	if data.Total != 1 || data.Posts[0].Kind != "ANSWER" || data.Posts[0].QuestionId != questionId ||
+		data.Posts[0].Question != "Is this a scam?" || data.Posts[0].GetAnswer() != "No scam here" {
+		t.Fatalf("unexpected queue %v", data.Posts)
+	}
+	answerId := data.Posts[0].Id
+	if err := moderate(client.ModerateAnswer, answerId, "HIDE", "rude"); err != nil {
+		t.Fatalf("ModerateAnswer: %v", err)
+	}
+	if len(questions().Questions[0].Answers) != 0 || queue("HIDDEN").Posts[0].GetModerationReason() != "rude" {
+		t.Fatalf("expected the hidden answer out of the listing")
+	}
+	if err := moderate(client.ModerateAnswer, answerId, "RESTORE", ""); err != nil {
+		t.Fatalf("ModerateAnswer: %v", err)
+	}
+	if len(questions().Questions[0].Answers) != 1 {
+		t.Fatalf("expected the restored answer to be listed")
+	}
+
+	if err := moderate(client.ModerateQuestion, questionId, "HIDE", "off topic"); err != nil {
+		t.Fatalf("ModerateQuestion: %v", err)
+	}
+	if err := moderate(client.ModerateQuestion, questionId, "RESTORE", ""); err != nil {
+		t.Fatalf("ModerateQuestion: %v", err)
+	}
+	if notifications() != 1 {
+		t.Fatalf("expected the merchant to be notified once")
+	}
+}
@@ -2,35 +2,65 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/akmal4410/gestapo/pkg/api/proto"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/admin_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/helpers/token"
+	"github.com/akmal4410/gestapo/pkg/service/images"
 	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
 func (handler *adminService) CreateCategory(ctx context.Context, req *proto.AddCategoryRequest) (*proto.Response, error) {
+	if _, err := handler.adminPayload(ctx); err != nil {
+		return nil, err
+	}
 	err := validateAddCategoryRequest(req)
 	if err != nil {
-		handler.log.LogError("Error while validateAddCategoryRequest", err)
+		handler.log.With(ctx).LogError("Error while validateAddCategoryRequest", err)
 		return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
 	}
+	attributes, err := validateCategoryAttributes(req.GetAttributes())
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while validateCategoryAttributes", err)
+		return nil, status.Errorf(codes.InvalidArgument, err.Error())
+	}
+
+	if req.ParentId != nil {
+		parent, err := handler.category(ctx, req.GetParentId())
+		if err != nil {
+			return nil, err
+		}
+		if parent.ArchivedAt != nil {
+			handler.log.With(ctx).LogError("Parent category is archived", parent.ID)
+			return nil, status.Errorf(codes.FailedPrecondition, "category cannot be added to an archived category")
+		}
+	}
 
-	res, err := handler.storage.CheckCategoryExist(req.GetCategoryName())
+	res, err := handler.storage.CheckCategoryExist(ctx, req.ParentId, req.GetCategoryName())
 	if err != nil {
-		handler.log.LogError("Error while CheckCategoryExist", err)
+		handler.log.With(ctx).LogError("Error while CheckCategoryExist", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 	if res {
 		err = fmt.Errorf("category already exist: %s", req.GetCategoryName())
-		handler.log.LogError("Error ", err)
+		handler.log.With(ctx).LogError("Error ", err)
 		return nil, status.Errorf(codes.AlreadyExists, err.Error())
 	}
 
-	err = handler.storage.AddCategory(req)
+	_, err = handler.storage.AddCategory(ctx, &entity.AddCategoryReq{
+		CategoryName: req.GetCategoryName(),
+		ParentID:     req.ParentId,
+		Attributes:   attributes,
+	})
 	if err != nil {
-		handler.log.LogError("Error while InsertCategory", err)
+		handler.log.With(ctx).LogError("Error while InsertCategory", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 	response := &proto.Response{
@@ -41,17 +71,351 @@ func (handler *adminService) CreateCategory(ctx context.Context, req *proto.AddC
 	return response, err
 }
 
-func (handler *adminService) GetCategories(ctx context.Context, in *proto.Request) (*proto.GetCategoryResponse, error) {
-	res, err := handler.storage.GetCategories()
+// GetCategories returns the category tree, the children of a category in their order.
+func (handler *adminService) GetCategories(ctx context.Context, in *proto.GetCategoriesRequest) (*proto.GetCategoryResponse, error) {
+	if in.GetIncludeArchived() {
+		if _, err := handler.adminPayload(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if in.CategoryId != nil {
+		if _, err := uuid.Parse(in.GetCategoryId()); err != nil {
+			handler.log.With(ctx).LogError("Invalid category id", err)
+			return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
+		}
+	}
+
+	categories, err := handler.storage.GetCategories(ctx)
 	if err != nil {
-		handler.log.LogError("Error while GetCategories", err)
+		handler.log.With(ctx).LogError("Error while GetCategories", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
+
+	var roots []*entity.Category
+	children := map[string][]*entity.Category{}
+	var imageKeys []string
+	for _, category := range categories {
+		if category.ArchivedAt != nil && !in.GetIncludeArchived() {
+			continue
+		}
+		switch {
+		case in.CategoryId != nil:
+			if category.ID == in.GetCategoryId() {
+				roots = append(roots, category)
+			}
+		case category.ParentID == nil:
+			roots = append(roots, category)
+		}
+		if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		}
+		if category.Image != nil {
+			imageKeys = append(imageKeys, *category.Image)
+		}
+	}
+	if in.CategoryId != nil && len(roots) == 0 {
+		handler.log.With(ctx).LogError("Category not found", in.GetCategoryId())
+		return nil, status.Errorf(codes.NotFound, "Category not found")
+	}
+
+	tree := &categoryTree{children: children, urls: handler.imageURLs(ctx, imageKeys)}
+	data := make([]*proto.CategoryRes, 0, len(roots))
+	for _, root := range roots {
+		data = append(data, tree.categoryRes(root))
+	}
 	response := &proto.GetCategoryResponse{
 		Code:    200,
 		Status:  true,
 		Message: "Categories fetched successfull",
-		Data:    res,
+		Data:    data,
+	}
+	return response, nil
+}
+
+func (handler *adminService) UpdateCategory(ctx context.Context, in *proto.UpdateCategoryRequest) (*proto.Response, error) {
+	if _, err := handler.adminPayload(ctx); err != nil {
+		return nil, err
+	}
+	if in.CategoryName == nil && in.Attributes == nil {
+		handler.log.With(ctx).LogError("Nothing to update")
+		return nil, status.Errorf(codes.InvalidArgument, "Category name or attributes is required")
+	}
+	category, err := handler.category(ctx, in.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if in.CategoryName != nil {
+		if err := validateAddCategoryRequest(&proto.AddCategoryRequest{CategoryName: in.GetCategoryName()}); err != nil {
+			handler.log.With(ctx).LogError("Error while validateAddCategoryRequest", err)
+			return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
+		}
+		if !strings.EqualFold(in.GetCategoryName(), category.CategoryName) {
+			if err := handler.checkSiblingName(ctx, category.ParentID, in.GetCategoryName()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	var attributes *entity.CategoryAttributes
+	if in.Attributes != nil {
+		validated, err := validateCategoryAttributes(in.Attributes.GetAttributes())
+		if err != nil {
+			handler.log.With(ctx).LogError("Error while validateCategoryAttributes", err)
+			return nil, status.Errorf(codes.InvalidArgument, err.Error())
+		}
+		attributes = &validated
+	}
+
+	err = handler.storage.UpdateCategory(ctx, category.ID, in.CategoryName, attributes)
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while UpdateCategory", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	response := &proto.Response{
+		Code:    200,
+		Status:  true,
+		Message: "Category updated successfully",
 	}
 	return response, nil
 }
+
+// MoveCategory moves the category with its subcategories below another parent.
+func (handler *adminService) MoveCategory(ctx context.Context, in *proto.MoveCategoryRequest) (*proto.Response, error) {
+	if _, err := handler.adminPayload(ctx); err != nil {
+		return nil, err
+	}
+	if in.Position != nil && in.GetPosition() < 0 {
+		handler.log.With(ctx).LogError("Invalid position", in.GetPosition())
+		return nil, status.Errorf(codes.InvalidArgument, "Position must not be below zero")
+	}
+	category, err := handler.category(ctx, in.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	var parent *entity.Category
+	if in.ParentId != nil {
+		parent, err = handler.category(ctx, in.GetParentId())
+		if err != nil {
+			return nil, err
+		}
+		if category.Contains(parent) {
+			handler.log.With(ctx).LogError("Category moved below itself", category.ID, parent.ID)
+			return nil, status.Errorf(codes.FailedPrecondition, "category cannot be moved below itself or its subcategories")
+		}
+		if parent.ArchivedAt != nil && category.ArchivedAt == nil {
+			handler.log.With(ctx).LogError("Parent category is archived", parent.ID)
+			return nil, status.Errorf(codes.FailedPrecondition, "category cannot be moved to an archived category")
+		}
+	}
+	if !sameCategory(category.ParentID, in.ParentId) {
+		if err := handler.checkSiblingName(ctx, in.ParentId, category.CategoryName); err != nil {
+			return nil, err
+		}
+	}
+
+	err = handler.storage.MoveCategory(ctx, category, parent, in.Position)
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while MoveCategory", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	response := &proto.Response{
+		Code:    200,
+		Status:  true,
+		Message: "Category moved successfully",
+	}
+	return response, nil
+}
+
+// ReorderCategories orders the children of the parent, or the roots without a parent.
+func (handler *adminService) ReorderCategories(ctx context.Context, in *proto.ReorderCategoriesRequest) (*proto.Response, error) {
+	if _, err := handler.adminPayload(ctx); err != nil {
+		return nil, err
+	}
+	if in.ParentId != nil {
+		if _, err := handler.category(ctx, in.GetParentId()); err != nil {
+			return nil, err
+		}
+	}
+
+	categories, err := handler.storage.GetCategories(ctx)
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while GetCategories", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	children := map[string]bool{}
+	for _, category := range categories {
+		if sameCategory(category.ParentID, in.ParentId) {
+			children[category.ID] = true
+		}
+	}
+	listed := map[string]bool{}
+	for _, categoryId := range in.GetCategoryIds() {
+		if !children[categoryId] || listed[categoryId] {
+			handler.log.With(ctx).LogError("Invalid category order", in.GetCategoryIds())
+			return nil, status.Errorf(codes.InvalidArgument, "Category ids must list every child of the parent once")
+		}
+		listed[categoryId] = true
+	}
+	if len(listed) != len(children) {
+		handler.log.With(ctx).LogError("Invalid category order", in.GetCategoryIds())
+		return nil, status.Errorf(codes.InvalidArgument, "Category ids must list every child of the parent once")
+	}
+
+	err = handler.storage.ReorderCategories(ctx, in.GetCategoryIds())
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while ReorderCategories", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	response := &proto.Response{
+		Code:    200,
+		Status:  true,
+		Message: "Categories reordered successfully",
+	}
+	return response, nil
+}
+
+// ArchiveCategory archives the category with its subcategories: they are hidden
+// from the tree and no product can be added to them, their products are kept.
+func (handler *adminService) ArchiveCategory(ctx context.Context, in *proto.CategoryIdRequest) (*proto.Response, error) {
+	return handler.setCategoryArchived(ctx, in.GetId(), true, "Category archived successfully")
+}
+
+// RestoreCategory restores an archived category with its subcategories.
+func (handler *adminService) RestoreCategory(ctx context.Context, in *proto.CategoryIdRequest) (*proto.Response, error) {
+	return handler.setCategoryArchived(ctx, in.GetId(), false, "Category restored successfully")
+}
+
+func (handler *adminService) setCategoryArchived(ctx context.Context, categoryId string, archived bool, message string) (*proto.Response, error) {
+	if _, err := handler.adminPayload(ctx); err != nil {
+		return nil, err
+	}
+	category, err := handler.category(ctx, categoryId)
+	if err != nil {
+		return nil, err
+	}
+	if !archived && category.ParentID != nil {
+		parent, err := handler.category(ctx, *category.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent.ArchivedAt != nil {
+			handler.log.With(ctx).LogError("Parent category is archived", parent.ID)
+			return nil, status.Errorf(codes.FailedPrecondition, "the parent category must be restored first")
+		}
+	}
+
+	err = handler.storage.SetCategoryArchived(ctx, category, archived)
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while SetCategoryArchived", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	response := &proto.Response{
+		Code:    200,
+		Status:  true,
+		Message: message,
+	}
+	return response, nil
+}
+
+// adminPayload returns the payload of the admin. The interceptors of the admin
+// service let every signed in user through, the changes are for the admins only.
+func (handler *adminService) adminPayload(ctx context.Context) (*token.AccessPayload, error) {
+	payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
+	if !ok {
+		err := errors.New("unable to retrieve payload from context")
+		handler.log.With(ctx).LogError("Error", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	if payload.UserType != utils.ADMIN {
+		handler.log.With(ctx).LogError("User is not an admin", payload.UserID)
+		return nil, status.Errorf(codes.PermissionDenied, "user does not have required role: %s", utils.ADMIN)
+	}
+	return payload, nil
+}
+
+func (handler *adminService) category(ctx context.Context, categoryId string) (*entity.Category, error) {
+	if _, err := uuid.Parse(categoryId); err != nil {
+		handler.log.With(ctx).LogError("Invalid category id", err)
+		return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
+	}
+	category, err := handler.storage.GetCategory(ctx, categoryId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			handler.log.With(ctx).LogError("Error while GetCategory Not found", err)
+			return nil, status.Errorf(codes.NotFound, "Category not found")
+		}
+		handler.log.With(ctx).LogError("Error while GetCategory", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	return category, nil
+}
+
+// checkSiblingName checks the parent has no child of the name yet.
+func (handler *adminService) checkSiblingName(ctx context.Context, parentId *string, name string) error {
+	exists, err := handler.storage.CheckCategoryExist(ctx, parentId, name)
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while CheckCategoryExist", err)
+		return status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	if exists {
+		err = fmt.Errorf("category already exist: %s", name)
+		handler.log.With(ctx).LogError("Error ", err)
+		return status.Errorf(codes.AlreadyExists, err.Error())
+	}
+	return nil
+}
+
+func (handler *adminService) imageURLs(ctx context.Context, keys []string) map[string]string {
+	if handler.urls == nil || len(keys) == 0 {
+		return map[string]string{}
+	}
+	return images.URLs(ctx, handler.urls, keys, images.Thumbnail)
+}
+
+func sameCategory(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// categoryTree builds the responses of the categories with their children.
+type categoryTree struct {
+	children map[string][]*entity.Category
+	urls     map[string]string
+}
+
+func (tree *categoryTree) categoryRes(category *entity.Category) *proto.CategoryRes {
+	res := &proto.CategoryRes{
+		Id:         category.ID,
+		Category:   category.CategoryName,
+		ParentId:   category.ParentID,
+		Path:       category.Path,
+		Position:   category.Position,
+		Attributes: categoryAttributes(category.Attributes),
+		Archived:   category.ArchivedAt != nil,
+		Children:   []*proto.CategoryRes{},
+	}
+	if category.Image != nil {
+		url := tree.urls[*category.Image]
+		res.Image = &url
+	}
+	for _, child := range tree.children[category.ID] {
+		res.Children = append(res.Children, tree.categoryRes(child))
+	}
+	return res
+}
+
+func categoryAttributes(attributes entity.CategoryAttributes) []*proto.CategoryAttribute {
+	res := make([]*proto.CategoryAttribute, 0, len(attributes))
+	for _, attribute := range attributes {
+		res = append(res, &proto.CategoryAttribute{
+			Name:     attribute.Name,
+			Type:     attribute.Type,
+			Required: attribute.Required,
+			Values:   attribute.Values,
+		})
+	}
+	return res
+}
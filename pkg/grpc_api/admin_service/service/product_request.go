@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/akmal4410/gestapo/pkg/api/proto"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/admin_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/service/images"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// productReviews are the statuses the pending products get, by action.
+var productReviews = map[string]string{
+	entity.ProductApprove: utils.ProductPublished,
+	entity.ProductReject:  utils.ProductDraft,
+}
+
+// GetProductQueue lists the products their merchants sent for review.
+func (handler *adminService) GetProductQueue(ctx context.Context, in *proto.GetProductQueueRequest) (*proto.GetProductQueueResponse, error) {
+	if _, err := handler.adminPayload(ctx); err != nil {
+		return nil, err
+	}
+
+	page, pageSize := in.GetPage(), in.GetPageSize()
+	if page == 0 {
+		page = 1
+	}
+	if pageSize == 0 {
+		pageSize = defaultQueuePageSize
+	}
+	if page < 0 || pageSize < 0 || pageSize > maxQueuePageSize {
+		handler.log.With(ctx).LogError("Invalid page or page_size", page, pageSize)
+		return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
+	}
+
+	res, err := handler.storage.GetProductQueue(ctx, int(pageSize), int(page-1)*int(pageSize))
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while GetProductQueue", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	var productImages []string
+	for _, product := range res.Products {
+		productImages = append(productImages, product.Images...)
+	}
+	urls := handler.imageURLs(ctx, productImages)
+
+	products := make([]*proto.ProductResponse, 0, len(res.Products))
+	for _, product := range res.Products {
+		merchantID, description, productStatus := product.MerchantID, product.Description, product.Status
+		productRes := &proto.ProductResponse{
+			Id:            product.ID,
+			MerchantId:    &merchantID,
+			ProductImages: images.Pick(product.Images, urls),
+			ProductName:   product.ProductName,
+			Description:   &description,
+			CategoryName:  product.CategoryName,
+			Price:         product.Price,
+			Status:        &productStatus,
+		}
+		if product.PublishAt != nil {
+			productRes.PublishAt = timestamppb.New(*product.PublishAt)
+		}
+		if product.UnpublishAt != nil {
+			productRes.UnpublishAt = timestamppb.New(*product.UnpublishAt)
+		}
+		products = append(products, productRes)
+	}
+	response := &proto.GetProductQueueResponse{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Product queue fetched successfully",
+		Data: &proto.ProductQueueData{
+			Products: products,
+			Total:    res.Total,
+			Page:     page,
+			PageSize: pageSize,
+		},
+	}
+	return response, nil
+}
+
+// ReviewProduct publishes a pending product, within its schedule, or sends it back
+// to draft with the reason for its merchant.
+func (handler *adminService) ReviewProduct(ctx context.Context, in *proto.ReviewProductRequest) (*proto.Response, error) {
+	if _, err := handler.adminPayload(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := uuid.Parse(in.GetProductId()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid product_id")
+	}
+	productStatus, ok := productReviews[in.GetAction()]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "action should be %s or %s", entity.ProductApprove, entity.ProductReject)
+	}
+	reason := strings.TrimSpace(in.GetReason())
+	if utf8.RuneCountInString(reason) > maxModerationReason {
+		return nil, status.Errorf(codes.InvalidArgument, "reason should have at most %d characters", maxModerationReason)
+	}
+	if reason == "" && in.GetAction() == entity.ProductReject {
+		return nil, status.Errorf(codes.InvalidArgument, "a reason is required to reject a product")
+	}
+
+	current, err := handler.storage.GetProductStatus(ctx, in.GetProductId())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "Product not found")
+		}
+		handler.log.With(ctx).LogError("Error while GetProductStatus", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	if current != utils.ProductPendingReview {
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot %s a %s product", strings.ToLower(in.GetAction()), current)
+	}
+
+	req := &entity.ReviewProductReq{
+		ProductID: in.GetProductId(),
+		Status:    productStatus,
+	}
+	if reason != "" {
+		req.Reason = &reason
+	}
+	err = handler.storage.ReviewProduct(ctx, req)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.Aborted, "the product is not pending anymore")
+		}
+		handler.log.With(ctx).LogError("Error while ReviewProduct", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	response := &proto.Response{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Product reviewed successfully",
+	}
+	return response, nil
+}
@@ -21,24 +21,24 @@ func (handler *adminService) CreatePromocode(ctx context.Context, in *proto.Crea
 	}
 	err := helpers.ValidateBody(nil, req)
 	if err != nil {
-		handler.log.LogError("Error while ValidateBody", err)
+		handler.log.With(ctx).LogError("Error while ValidateBody", err)
 		return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
 	}
 
-	res, err := handler.storage.CheckPromocodeExist(req.Code)
+	res, err := handler.storage.CheckPromocodeExist(ctx, req.Code)
 	if err != nil {
-		handler.log.LogError("Error while CheckPromocodeExist", err)
+		handler.log.With(ctx).LogError("Error while CheckPromocodeExist", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 	if res {
 		err = fmt.Errorf("category already exist: %s", req.Code)
-		handler.log.LogError("Error ", err)
+		handler.log.With(ctx).LogError("Error ", err)
 		return nil, status.Errorf(codes.AlreadyExists, err.Error())
 	}
 
-	err = handler.storage.AddPromocode(req)
+	err = handler.storage.AddPromocode(ctx, req)
 	if err != nil {
-		handler.log.LogError("Error while InsertCategory", err)
+		handler.log.With(ctx).LogError("Error while InsertCategory", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 	response := &proto.Response{
@@ -50,9 +50,9 @@ func (handler *adminService) CreatePromocode(ctx context.Context, in *proto.Crea
 }
 
 func (handler *adminService) GetPromocodes(ctx context.Context, in *proto.Request) (*proto.GetPromocodeResponse, error) {
-	promoEntities, err := handler.storage.GetPromocodes()
+	promoEntities, err := handler.storage.GetPromocodes(ctx)
 	if err != nil {
-		handler.log.LogError("Error while GetCategories", err)
+		handler.log.With(ctx).LogError("Error while GetCategories", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
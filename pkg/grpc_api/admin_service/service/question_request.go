@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/akmal4410/gestapo/pkg/api/proto"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/admin_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// questionNotification is the notification of the merchant when a held question on
+// one of its products is approved, like the one of the questions published at once.
+var questionNotification = entity.QuestionNotification{
+	Title: "New question",
+	Body:  "A shopper asked a question about %s",
+}
+
+// qnaNames are the names of the kinds in the messages.
+var qnaNames = map[string]string{
+	entity.QnAQuestion: "Question",
+	entity.QnAAnswer:   "Answer",
+}
+
+// GetQnAQueue lists the questions and the answers with the status, the pending ones
+// by default, with the flags of the automatic checks.
+func (handler *adminService) GetQnAQueue(ctx context.Context, in *proto.GetQnAQueueRequest) (*proto.GetQnAQueueResponse, error) {
+	if _, err := handler.adminPayload(ctx); err != nil {
+		return nil, err
+	}
+
+	postStatus := in.GetStatus()
+	if postStatus == "" {
+		postStatus = utils.ReviewPending
+	}
+	switch postStatus {
+	case utils.ReviewPending, utils.ReviewPublished, utils.ReviewHidden:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "invalid status %q", postStatus)
+	}
+	page, pageSize := in.GetPage(), in.GetPageSize()
+	if page == 0 {
+		page = 1
+	}
+	if pageSize == 0 {
+		pageSize = defaultQueuePageSize
+	}
+	if page < 0 || pageSize < 0 || pageSize > maxQueuePageSize {
+		handler.log.With(ctx).LogError("Invalid page or page_size", page, pageSize)
+		return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
+	}
+
+	res, err := handler.storage.GetQnAQueue(ctx, &entity.QnAQueueReq{
+		Status: postStatus,
+		Limit:  int(pageSize),
+		Offset: int(page-1) * int(pageSize),
+	})
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while GetQnAQueue", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	posts := make([]*proto.ModerationQnA, 0, len(res.Posts))
+	for _, post := range res.Posts {
+		moderationPost := &proto.ModerationQnA{
+			Id:               post.ID,
+			Kind:             post.Kind,
+			QuestionId:       post.QuestionID,
+			ProductId:        post.ProductID,
+			ProductName:      post.ProductName,
+			UserId:           post.UserID,
+			AuthorName:       post.AuthorName,
+			Question:         post.Question,
+			Answer:           post.Answer,
+			Status:           post.Status,
+			Flags:            post.Flags,
+			ModerationReason: post.ModerationReason,
+			CreatedAt:        timestamppb.New(post.CreatedAt),
+			UpdatedAt:        timestamppb.New(post.UpdatedAt),
+		}
+		if post.ModeratedAt != nil {
+			moderationPost.ModeratedAt = timestamppb.New(*post.ModeratedAt)
+		}
+		posts = append(posts, moderationPost)
+	}
+	response := &proto.GetQnAQueueResponse{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Q&A queue fetched successfully",
+		Data: &proto.QnAQueueData{
+			Posts:    posts,
+			Total:    res.Total,
+			Page:     page,
+			PageSize: pageSize,
+		},
+	}
+	return response, nil
+}
+
+// ModerateQuestion approves, hides or restores the question. The merchant is
+// notified when a held question is approved.
+func (handler *adminService) ModerateQuestion(ctx context.Context, in *proto.ModerateQnARequest) (*proto.Response, error) {
+	return handler.moderateQnA(ctx, entity.QnAQuestion, in)
+}
+
+// ModerateAnswer approves, hides or restores the answer.
+func (handler *adminService) ModerateAnswer(ctx context.Context, in *proto.ModerateQnARequest) (*proto.Response, error) {
+	return handler.moderateQnA(ctx, entity.QnAAnswer, in)
+}
+
+// moderateQnA moderates the question or the answer of the kind with the actions of the reviews.
+func (handler *adminService) moderateQnA(ctx context.Context, kind string, in *proto.ModerateQnARequest) (*proto.Response, error) {
+	payload, err := handler.adminPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+	name := qnaNames[kind]
+	if _, err := uuid.Parse(in.GetId()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid id")
+	}
+	moderation, ok := moderations[in.GetAction()]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "action should be %s, %s or %s",
+			entity.ModerationApprove, entity.ModerationHide, entity.ModerationRestore)
+	}
+	reason := strings.TrimSpace(in.GetReason())
+	if utf8.RuneCountInString(reason) > maxModerationReason {
+		return nil, status.Errorf(codes.InvalidArgument, "reason should have at most %d characters", maxModerationReason)
+	}
+	if reason == "" && in.GetAction() == entity.ModerationHide {
+		return nil, status.Errorf(codes.InvalidArgument, "a reason is required to hide a %s", strings.ToLower(name))
+	}
+
+	current, err := handler.storage.GetQnAStatus(ctx, kind, in.GetId())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "%s not found", name)
+		}
+		handler.log.With(ctx).LogError("Error while GetQnAStatus", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	allowed := false
+	for _, from := range moderation.from {
+		allowed = allowed || from == current
+	}
+	if !allowed {
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot %s a %s %s", strings.ToLower(in.GetAction()), current, strings.ToLower(name))
+	}
+
+	req := &entity.ModerateQnAReq{
+		Kind:    kind,
+		ID:      in.GetId(),
+		From:    current,
+		Status:  moderation.to,
+		AdminID: payload.UserID,
+	}
+	if reason != "" {
+		req.Reason = &reason
+	}
+	// the questions held at once were never published, their merchant learns about them now
+	if kind == entity.QnAQuestion && current == utils.ReviewPending && moderation.to == utils.ReviewPublished {
+		req.Notification = &questionNotification
+	}
+	err = handler.storage.ModerateQnA(ctx, req)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.Aborted, "the status of the %s changed, try again", strings.ToLower(name))
+		}
+		handler.log.With(ctx).LogError("Error while ModerateQnA", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	response := &proto.Response{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: name + " moderated successfully",
+	}
+	return response, nil
+}
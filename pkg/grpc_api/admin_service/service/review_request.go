@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/akmal4410/gestapo/pkg/api/proto"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/admin_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/service/images"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultQueuePageSize = 20
+	maxQueuePageSize     = 100
+	maxModerationReason  = 500
+)
+
+// moderations are the statuses a review can be moderated from and the status it gets, by action.
+var moderations = map[string]struct {
+	from []string
+	to   string
+}{
+	entity.ModerationApprove: {from: []string{utils.ReviewPending}, to: utils.ReviewPublished},
+	entity.ModerationHide:    {from: []string{utils.ReviewPending, utils.ReviewPublished}, to: utils.ReviewHidden},
+	entity.ModerationRestore: {from: []string{utils.ReviewHidden}, to: utils.ReviewPublished},
+}
+
+// GetReviewQueue lists the reviews with the status, the pending ones by default, with
+// the flags of the automatic checks and the open reports.
+func (handler *adminService) GetReviewQueue(ctx context.Context, in *proto.GetReviewQueueRequest) (*proto.GetReviewQueueResponse, error) {
+	if _, err := handler.adminPayload(ctx); err != nil {
+		return nil, err
+	}
+
+	reviewStatus := in.GetStatus()
+	if reviewStatus == "" {
+		reviewStatus = utils.ReviewPending
+	}
+	switch reviewStatus {
+	case utils.ReviewPending, utils.ReviewPublished, utils.ReviewHidden:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "invalid status %q", reviewStatus)
+	}
+	page, pageSize := in.GetPage(), in.GetPageSize()
+	if page == 0 {
+		page = 1
+	}
+	if pageSize == 0 {
+		pageSize = defaultQueuePageSize
+	}
+	if page < 0 || pageSize < 0 || pageSize > maxQueuePageSize {
+		handler.log.With(ctx).LogError("Invalid page or page_size", page, pageSize)
+		return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
+	}
+
+	res, err := handler.storage.GetReviewQueue(ctx, &entity.ReviewQueueReq{
+		Status: reviewStatus,
+		Limit:  int(pageSize),
+		Offset: int(page-1) * int(pageSize),
+	})
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while GetReviewQueue", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	var photos []string
+	for _, review := range res.Reviews {
+		photos = append(photos, review.Images...)
+	}
+	urls := handler.imageURLs(ctx, photos)
+
+	reviews := make([]*proto.ModerationReview, 0, len(res.Reviews))
+	for _, review := range res.Reviews {
+		reviews = append(reviews, moderationReview(review, urls))
+	}
+	response := &proto.GetReviewQueueResponse{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Review queue fetched successfully",
+		Data: &proto.ReviewQueueData{
+			Reviews:  reviews,
+			Total:    res.Total,
+			Page:     page,
+			PageSize: pageSize,
+		},
+	}
+	return response, nil
+}
+
+func moderationReview(review *entity.ModerationReview, urls map[string]string) *proto.ModerationReview {
+	res := &proto.ModerationReview{
+		Id:               review.ID,
+		ProductId:        review.ProductID,
+		ProductName:      review.ProductName,
+		UserId:           review.UserID,
+		AuthorName:       review.AuthorName,
+		Star:             review.Star,
+		Review:           review.Review,
+		Photos:           images.Pick(review.Images, urls),
+		Status:           review.Status,
+		Flags:            review.Flags,
+		ModerationReason: review.ModerationReason,
+		CreatedAt:        timestamppb.New(review.CreatedAt),
+		UpdatedAt:        timestamppb.New(review.UpdatedAt),
+	}
+	if review.ModeratedAt != nil {
+		res.ModeratedAt = timestamppb.New(*review.ModeratedAt)
+	}
+	reasons := make([]string, 0, len(review.Reports))
+	for reason := range review.Reports {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	for _, reason := range reasons {
+		res.Reports = append(res.Reports, &proto.ReportCount{Reason: reason, Count: review.Reports[reason]})
+	}
+	return res
+}
+
+// ModerateReview approves, hides or restores the review, resolving its open reports.
+func (handler *adminService) ModerateReview(ctx context.Context, in *proto.ModerateReviewRequest) (*proto.Response, error) {
+	payload, err := handler.adminPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := uuid.Parse(in.GetReviewId()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid review_id")
+	}
+	moderation, ok := moderations[in.GetAction()]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "action should be %s, %s or %s",
+			entity.ModerationApprove, entity.ModerationHide, entity.ModerationRestore)
+	}
+	reason := strings.TrimSpace(in.GetReason())
+	if utf8.RuneCountInString(reason) > maxModerationReason {
+		return nil, status.Errorf(codes.InvalidArgument, "reason should have at most %d characters", maxModerationReason)
+	}
+	if reason == "" && in.GetAction() == entity.ModerationHide {
+		return nil, status.Errorf(codes.InvalidArgument, "a reason is required to hide a review")
+	}
+
+	current, err := handler.storage.GetReviewStatus(ctx, in.GetReviewId())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "Review not found")
+		}
+		handler.log.With(ctx).LogError("Error while GetReviewStatus", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	allowed := false
+	for _, from := range moderation.from {
+		allowed = allowed || from == current
+	}
+	if !allowed {
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot %s a %s review", strings.ToLower(in.GetAction()), current)
+	}
+
+	req := &entity.ModerateReviewReq{
+		ReviewID: in.GetReviewId(),
+		Status:   moderation.to,
+		AdminID:  payload.UserID,
+	}
+	if reason != "" {
+		req.Reason = &reason
+	}
+	err = handler.storage.ModerateReview(ctx, req)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "Review not found")
+		}
+		handler.log.With(ctx).LogError("Error while ModerateReview", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	response := &proto.Response{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Review moderated successfully",
+	}
+	return response, nil
+}
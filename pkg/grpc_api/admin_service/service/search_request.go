@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/api/proto"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultZeroResultDays  = 7
+	maxZeroResultDays      = 90
+	defaultZeroResultLimit = 20
+	maxZeroResultLimit     = 100
+)
+
+// GetZeroResultQueries lists the searches finding nothing, the products the users look for and the catalog lacks.
+func (handler *adminService) GetZeroResultQueries(ctx context.Context, in *proto.GetZeroResultQueriesRequest) (*proto.GetZeroResultQueriesResponse, error) {
+	if _, err := handler.adminPayload(ctx); err != nil {
+		return nil, err
+	}
+
+	days, limit := int(in.GetDays()), int(in.GetLimit())
+	if days == 0 {
+		days = defaultZeroResultDays
+	}
+	if limit == 0 {
+		limit = defaultZeroResultLimit
+	}
+	if days < 0 || days > maxZeroResultDays || limit < 0 || limit > maxZeroResultLimit {
+		handler.log.With(ctx).LogError("Invalid days or limit", days, limit)
+		return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
+	}
+
+	since := time.Now().AddDate(0, 0, -days)
+	queryEntities, err := handler.storage.GetZeroResultQueries(ctx, since, limit)
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while GetZeroResultQueries", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	queries := make([]*proto.ZeroResultQuery, 0, len(queryEntities))
+	for _, query := range queryEntities {
+		queries = append(queries, &proto.ZeroResultQuery{
+			Query:          query.Query,
+			Count:          query.Count,
+			LastSearchedAt: timestamppb.New(query.LastSearchedAt),
+		})
+	}
+	response := &proto.GetZeroResultQueriesResponse{
+		Code:    200,
+		Status:  true,
+		Message: "Zero result queries fetched successfully",
+		Data:    queries,
+	}
+	return response, nil
+}
@@ -1,26 +1,50 @@
 package service
 
 import (
+	"github.com/akmal4410/gestapo/internal/config"
 	"github.com/akmal4410/gestapo/internal/database"
 	"github.com/akmal4410/gestapo/pkg/api/proto"
 	"github.com/akmal4410/gestapo/pkg/grpc_api/admin_service/db"
 	"github.com/akmal4410/gestapo/pkg/helpers/logger"
+	objectstore "github.com/akmal4410/gestapo/pkg/service/object_store"
+	urlsigner "github.com/akmal4410/gestapo/pkg/service/url_signer"
 )
 
 type adminService struct {
 	proto.UnimplementedAdminServiceServer
-	storage *db.AdminStore
+	storage db.AdminRepository
+	urls    *urlsigner.Signer
 	log     logger.Logger
 }
 
+// Dependencies are the stores and the clients used by the admin service.
+type Dependencies struct {
+	Store db.AdminRepository
+	URLs  *urlsigner.Signer
+}
+
 // NewAuthenticationService creates a new gRPC server.
-func NewAdminService(storage *database.Storage, log logger.Logger) *adminService {
-	server := &adminService{
-		log: log,
+func NewAdminService(storage *database.Storage, config *config.Config, log logger.Logger) *adminService {
+	files, err := objectstore.New(config)
+	if err != nil {
+		log.LogFatal("Error while Initializing object store ", err)
 	}
+	urls, err := urlsigner.New(config, files, log)
+	if err != nil {
+		log.LogFatal("Error while Initializing url signer ", err)
+	}
+	deps := Dependencies{
+		Store: db.NewAdminStore(storage),
+		URLs:  urls,
+	}
+	return NewAdminServiceWith(deps, log)
+}
 
-	authStore := db.NewAdminStore(storage)
-
-	server.storage = authStore
-	return server
+// NewAdminServiceWith creates a new gRPC server working with deps.
+func NewAdminServiceWith(deps Dependencies, log logger.Logger) *adminService {
+	return &adminService{
+		storage: deps.Store,
+		urls:    deps.URLs,
+		log:     log,
+	}
 }
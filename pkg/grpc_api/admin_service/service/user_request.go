@@ -11,9 +11,9 @@ import (
 )
 
 func (admin *adminService) GetUsers(ctx context.Context, in *proto.Request) (*proto.GetUsersResponse, error) {
-	userEntites, err := admin.storage.GetUsers()
+	userEntites, err := admin.storage.GetUsers(ctx)
 	if err != nil {
-		admin.log.LogError("Error while GetUsers", err)
+		admin.log.With(ctx).LogError("Error while GetUsers", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
@@ -1,8 +1,14 @@
 package service
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+
 	"github.com/akmal4410/gestapo/pkg/api/proto"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/admin_service/db/entity"
 	"github.com/akmal4410/gestapo/pkg/helpers"
+	"github.com/akmal4410/gestapo/pkg/utils"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -16,3 +22,47 @@ func validateAddCategoryRequest(req *proto.AddCategoryRequest) error {
 	return nil
 
 }
+
+// validateCategoryAttributes checks the attributes of a category: the names are
+// unique regardless of the case and only the ENUM attributes have values.
+func validateCategoryAttributes(attributes []*proto.CategoryAttribute) (entity.CategoryAttributes, error) {
+	res := entity.CategoryAttributes{}
+	names := map[string]bool{}
+	for _, attribute := range attributes {
+		name := strings.TrimSpace(attribute.GetName())
+		if name == "" {
+			return nil, errors.New("attribute name must not be empty")
+		}
+		if names[strings.ToLower(name)] {
+			return nil, fmt.Errorf("attribute %s is defined twice", name)
+		}
+		names[strings.ToLower(name)] = true
+
+		attributeType := strings.ToUpper(attribute.GetType())
+		switch attributeType {
+		case utils.AttributeText, utils.AttributeNumber, utils.AttributeBoolean:
+			if len(attribute.GetValues()) != 0 {
+				return nil, fmt.Errorf("attribute %s has values but is not an %s", name, utils.AttributeEnum)
+			}
+		case utils.AttributeEnum:
+			if len(attribute.GetValues()) == 0 {
+				return nil, fmt.Errorf("attribute %s must have values", name)
+			}
+			for _, value := range attribute.GetValues() {
+				if helpers.IsEmpty(value) {
+					return nil, fmt.Errorf("attribute %s has an empty value", name)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("attribute %s must be of type %s, %s, %s or %s", name,
+				utils.AttributeText, utils.AttributeNumber, utils.AttributeBoolean, utils.AttributeEnum)
+		}
+		res = append(res, entity.CategoryAttribute{
+			Name:     name,
+			Type:     attributeType,
+			Required: attribute.GetRequired(),
+			Values:   attribute.GetValues(),
+		})
+	}
+	return res, nil
+}
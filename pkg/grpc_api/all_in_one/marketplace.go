@@ -0,0 +1,219 @@
+// Package all_in_one runs every service and the gateway in one process.
+//
+// The services listen on in-memory bufconn listeners instead of TCP ports,
+// share one in-memory database and use fakes for S3, email, Twilio, Redis and
+// Google sign-in unless real clients are given. It backs cmd/gestapo and the
+// integration tests.
+package all_in_one
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/akmal4410/gestapo/internal/config"
+	"github.com/akmal4410/gestapo/internal/database/memory"
+	admin_db "github.com/akmal4410/gestapo/pkg/grpc_api/admin_service/db"
+	admin_grpc "github.com/akmal4410/gestapo/pkg/grpc_api/admin_service/protocol/grpc"
+	admin_service "github.com/akmal4410/gestapo/pkg/grpc_api/admin_service/service"
+	auth_db "github.com/akmal4410/gestapo/pkg/grpc_api/authentication_service/db"
+	auth_grpc "github.com/akmal4410/gestapo/pkg/grpc_api/authentication_service/protocol/grpc"
+	auth_service "github.com/akmal4410/gestapo/pkg/grpc_api/authentication_service/service"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/grpc_gateway"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/grpc_gateway/server"
+	merchant_db "github.com/akmal4410/gestapo/pkg/grpc_api/merchant_service/db"
+	merchant_grpc "github.com/akmal4410/gestapo/pkg/grpc_api/merchant_service/protocol/grpc"
+	merchant_service "github.com/akmal4410/gestapo/pkg/grpc_api/merchant_service/service"
+	order_db "github.com/akmal4410/gestapo/pkg/grpc_api/order_service/db"
+	order_grpc "github.com/akmal4410/gestapo/pkg/grpc_api/order_service/protocol/grpc"
+	order_service "github.com/akmal4410/gestapo/pkg/grpc_api/order_service/service"
+	product_db "github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db"
+	product_grpc "github.com/akmal4410/gestapo/pkg/grpc_api/product_service/protocol/grpc"
+	product_service "github.com/akmal4410/gestapo/pkg/grpc_api/product_service/service"
+	user_db "github.com/akmal4410/gestapo/pkg/grpc_api/user_service/db"
+	user_grpc "github.com/akmal4410/gestapo/pkg/grpc_api/user_service/protocol/grpc"
+	user_service "github.com/akmal4410/gestapo/pkg/grpc_api/user_service/service"
+	"github.com/akmal4410/gestapo/pkg/helpers/logger"
+	"github.com/akmal4410/gestapo/pkg/helpers/service_helper"
+	"github.com/akmal4410/gestapo/pkg/helpers/token"
+	"github.com/akmal4410/gestapo/pkg/service/cache"
+	"github.com/akmal4410/gestapo/pkg/service/mail"
+	objectstore "github.com/akmal4410/gestapo/pkg/service/object_store"
+	"github.com/akmal4410/gestapo/pkg/service/sso"
+	"github.com/akmal4410/gestapo/pkg/service/twilio"
+	urlsigner "github.com/akmal4410/gestapo/pkg/service/url_signer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Names of the services, also used as their in-memory addresses.
+const (
+	Authentication = "authentication"
+	Admin          = "admin"
+	User           = "user"
+	Merchant       = "merchant"
+	Product        = "product"
+	Order          = "order"
+)
+
+const bufSize = 1 << 20
+
+// Dependencies are the external clients shared by the services.
+// Nil fields are replaced by the in-memory fakes.
+type Dependencies struct {
+	DB     *memory.Database
+	Files  objectstore.ObjectStore
+	Email  mail.EmailService
+	Twilio twilio.TwilioService
+	Cache  cache.Cache
+	SSO    sso.Verifier
+	// ImageClient downloads the images of the imported catalogs, only from
+	// public addresses when nil.
+	ImageClient *http.Client
+}
+
+// Marketplace is every service running in the current process.
+type Marketplace struct {
+	Config *config.Config
+	Log    logger.Logger
+	Token  token.Maker
+	Dependencies
+
+	listeners map[string]*bufconn.Listener
+	servers   map[string]*grpc.Server
+	clients   *service_helper.ClientRegistry
+}
+
+// NewMarketplace starts every service with deps. The service addresses of appConfig
+// are replaced by the in-memory ones, the other settings are used as they are.
+func NewMarketplace(appConfig *config.Config, deps Dependencies, log logger.Logger, tokenMaker token.Maker) *Marketplace {
+	if deps.DB == nil {
+		deps.DB = memory.NewDatabase()
+	}
+	if deps.Files == nil {
+		deps.Files = objectstore.NewMemoryStore()
+	}
+	if deps.Email == nil {
+		deps.Email = mail.NewMemoryEmailService()
+	}
+	if deps.Twilio == nil {
+		deps.Twilio = twilio.NewMemoryOTPService()
+	}
+	if deps.Cache == nil {
+		deps.Cache = cache.NewMemoryCache()
+	}
+	if deps.SSO == nil {
+		deps.SSO = sso.NewGoogleVerifier(log)
+	}
+
+	var addresses config.ServerAddress
+	if appConfig.ServerAddress != nil {
+		addresses = *appConfig.ServerAddress
+	}
+	addresses.Authentication = &config.Address{Address: target(Authentication)}
+	addresses.Admin = &config.Address{Address: target(Admin)}
+	addresses.User = &config.Address{Address: target(User)}
+	addresses.Merchant = &config.Address{Address: target(Merchant)}
+	addresses.Product = &config.Address{Address: target(Product)}
+	addresses.Order = &config.Address{Address: target(Order)}
+	appConfig.ServerAddress = &addresses
+
+	marketplace := &Marketplace{
+		Config:       appConfig,
+		Log:          log,
+		Token:        tokenMaker,
+		Dependencies: deps,
+		listeners:    map[string]*bufconn.Listener{},
+	}
+	marketplace.clients = service_helper.NewClientRegistry(appConfig.ServerAddress, appConfig.GRPCClient, log, marketplace.DialOptions()...)
+	urls := urlsigner.NewWith(appConfig, deps.Files, deps.Cache, log)
+
+	marketplace.servers = map[string]*grpc.Server{
+		Authentication: auth_grpc.NewGRPCServer(auth_service.NewAuthenticationServiceWith(auth_service.Dependencies{
+			Store:  auth_db.NewMemoryAuthStore(deps.DB),
+			Files:  deps.Files,
+			Twilio: deps.Twilio,
+			Email:  deps.Email,
+			Cache:  deps.Cache,
+			SSO:    deps.SSO,
+		}, appConfig, log, tokenMaker), tokenMaker, log),
+		Admin: admin_grpc.NewGRPCServer(admin_service.NewAdminServiceWith(admin_service.Dependencies{
+			Store: admin_db.NewMemoryAdminStore(deps.DB),
+			URLs:  urls,
+		}, log), tokenMaker, log),
+		User: user_grpc.NewGRPCServer(user_service.NewUserServiceWith(user_service.Dependencies{
+			Store:   user_db.NewMemoryUserStore(deps.DB),
+			URLs:    urls,
+			Clients: marketplace.clients,
+		}, appConfig, log, tokenMaker), tokenMaker, log),
+		Merchant: merchant_grpc.NewGRPCServer(merchant_service.NewMerchantServiceWith(merchant_service.Dependencies{
+			Store:       merchant_db.NewMemoryMerchantStore(deps.DB),
+			Files:       deps.Files,
+			URLs:        urls,
+			Clients:     marketplace.clients,
+			ImageClient: deps.ImageClient,
+		}, appConfig, log, tokenMaker), tokenMaker, log),
+		Product: product_grpc.NewGRPCServer(product_service.NewProductServiceWith(product_service.Dependencies{
+			Store: product_db.NewMemoryProductStore(deps.DB),
+			Files: deps.Files,
+			URLs:  urls,
+		}, appConfig, log, tokenMaker), tokenMaker, log),
+		Order: order_grpc.NewGRPCServer(order_service.NewOrderServiceWith(order_service.Dependencies{
+			Store: order_db.NewMemoryOrderStore(deps.DB),
+			URLs:  urls,
+		}, appConfig, log, tokenMaker), tokenMaker, log),
+	}
+	for name := range marketplace.servers {
+		marketplace.listeners[name] = bufconn.Listen(bufSize)
+	}
+	for name, grpcServer := range marketplace.servers {
+		go func(name string, grpcServer *grpc.Server) {
+			if err := grpcServer.Serve(marketplace.listeners[name]); err != nil {
+				log.LogError("error while serving", name, "service :", err)
+			}
+		}(name, grpcServer)
+	}
+	return marketplace
+}
+
+func target(name string) string {
+	return "passthrough:///" + name
+}
+
+// DialOptions connect a gRPC client to the in-memory listeners of the services.
+func (marketplace *Marketplace) DialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+			lis, ok := marketplace.listeners[address]
+			if !ok {
+				return nil, fmt.Errorf("unknown service: %s", address)
+			}
+			return lis.DialContext(ctx)
+		}),
+	}
+}
+
+// Dial connects to the service with the given name.
+func (marketplace *Marketplace) Dial(name string) (*grpc.ClientConn, error) {
+	if _, ok := marketplace.listeners[name]; !ok {
+		return nil, fmt.Errorf("unknown service: %s", name)
+	}
+	return grpc.Dial(target(name), marketplace.DialOptions()...)
+}
+
+// Handler returns the http handler of the gateway in front of the services.
+func (marketplace *Marketplace) Handler(ctx context.Context) (http.Handler, error) {
+	restServer := server.NewRestServerWith(merchant_db.NewMemoryMerchantStore(marketplace.DB), marketplace.Files, marketplace.Log, marketplace.Token)
+	return grpc_gateway.NewHandler(ctx, marketplace.Log, *marketplace.Config, restServer, marketplace.DialOptions()...)
+}
+
+// Stop stops every service and closes the connections between them.
+func (marketplace *Marketplace) Stop() {
+	for _, grpcServer := range marketplace.servers {
+		grpcServer.Stop()
+	}
+	marketplace.clients.Close()
+}
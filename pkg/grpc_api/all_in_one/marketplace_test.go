@@ -0,0 +1,78 @@
+package all_in_one_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/akmal4410/gestapo/internal/config"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/all_in_one"
+	"github.com/akmal4410/gestapo/pkg/helpers/logger"
+	"github.com/akmal4410/gestapo/pkg/helpers/token"
+	"github.com/akmal4410/gestapo/pkg/service/mail"
+)
+
+func post(t *testing.T, handler http.Handler, path, body, sessionToken string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	if sessionToken != "" {
+		req.Header.Set("Authorization", "Bearer "+sessionToken)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestSignUpThroughGateway(t *testing.T) {
+	tokenMaker, err := token.NewJWTMaker("01234567890123456789012345678901")
+	if err != nil {
+		t.Fatalf("NewJWTMaker: %v", err)
+	}
+	marketplace := all_in_one.NewMarketplace(&config.Config{}, all_in_one.Dependencies{}, logger.NewWriterLogger("all_in_one", io.Discard), tokenMaker)
+	defer marketplace.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	handler, err := marketplace.Handler(ctx)
+	if err != nil {
+		t.Fatalf("Handler: %v", err)
+	}
+
+	email := "e2e@example.com"
+	rec := post(t, handler, "/api/auth/send-otp", `{"email": "`+email+`", "action": "sign-up"}`, "")
+	sessionToken := rec.Header().Get("Grpc-Metadata-Session-Token")
+	if rec.Code != http.StatusOK || sessionToken == "" {
+		t.Fatalf("send-otp: %d %s", rec.Code, rec.Body)
+	}
+	otp, ok := marketplace.Email.(*mail.MemoryEmailService).LastOTP(email)
+	if !ok {
+		t.Fatal("no OTP was mailed")
+	}
+
+	body := `{"email": "` + email + `", "full_name": "E2E User", "user_name": "e2e_user", "user_type": "USER", "code": "` + otp + `", "password": "password123"}`
+	rec = post(t, handler, "/api/auth/signup", body, sessionToken)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("signup: %d %s", rec.Code, rec.Body)
+	}
+
+	rec = post(t, handler, "/api/auth/login", `{"user_name": "e2e_user", "password": "password123"}`, "")
+	if rec.Code != http.StatusOK || rec.Header().Get("Grpc-Metadata-Access-Token") == "" {
+		t.Fatalf("login: %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestDialUnknownService(t *testing.T) {
+	tokenMaker, err := token.NewJWTMaker("01234567890123456789012345678901")
+	if err != nil {
+		t.Fatalf("NewJWTMaker: %v", err)
+	}
+	marketplace := all_in_one.NewMarketplace(&config.Config{}, all_in_one.Dependencies{}, logger.NewWriterLogger("all_in_one", io.Discard), tokenMaker)
+	defer marketplace.Stop()
+
+	if _, err := marketplace.Dial("inventory"); err == nil {
+		t.Fatal("expected an error for an unknown service")
+	}
+}
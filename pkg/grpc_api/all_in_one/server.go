@@ -0,0 +1,175 @@
+package all_in_one
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+
+	"github.com/akmal4410/gestapo/internal/config"
+	"github.com/akmal4410/gestapo/pkg/helpers/logger"
+	"github.com/akmal4410/gestapo/pkg/helpers/service_helper"
+	"github.com/akmal4410/gestapo/pkg/helpers/token"
+	"github.com/akmal4410/gestapo/pkg/service/cache"
+	"github.com/akmal4410/gestapo/pkg/service/mail"
+	objectstore "github.com/akmal4410/gestapo/pkg/service/object_store"
+	"github.com/akmal4410/gestapo/pkg/service/twilio"
+	"github.com/spf13/viper"
+)
+
+const (
+	serviceName    = "All In One"
+	logFileName    = "all_in_one"
+	defaultGateway = "8080"
+)
+
+// RunServer runs every service and the gateway in the current process.
+// configs/config.yaml is optional, without it everything runs on the fakes.
+func RunServer() error {
+	ctx, log := service_helper.InitializeService(serviceName, logFileName)
+
+	appConfig, err := config.LoadConfig("configs")
+	if err != nil {
+		if !errors.As(err, &viper.ConfigFileNotFoundError{}) {
+			log.LogFatal("Cannot load configuration:", err)
+		}
+		log.LogInfo("No config file found, running with the default configuration")
+	}
+	if appConfig.ServerAddress == nil {
+		appConfig.ServerAddress = &config.ServerAddress{}
+	}
+	if appConfig.ServerAddress.Gateway == "" {
+		appConfig.ServerAddress.Gateway = defaultGateway
+	}
+	if appConfig.TokenSymmetricKey == "" {
+		appConfig.TokenSymmetricKey, err = randomKey()
+		if err != nil {
+			log.LogFatal("Error while generating the token key", err)
+		}
+		log.LogInfo("No TOKEN_SYMMETRIC_KEY set, tokens are only valid until the server stops")
+	}
+
+	tokenMaker, err := token.NewJWTMaker(appConfig.TokenSymmetricKey)
+	if err != nil {
+		log.LogFatal("Error while Initializing NewJWTMaker %w", err)
+	}
+	deps, err := newDependencies(&appConfig, log)
+	if err != nil {
+		log.LogFatal("Error while creating the dependencies", err)
+	}
+
+	marketplace := NewMarketplace(&appConfig, deps, log, tokenMaker)
+	defer marketplace.Stop()
+
+	handler, err := marketplace.Handler(ctx)
+	if err != nil {
+		log.LogError("error in newGateway :", err)
+		return err
+	}
+	httpServer := &http.Server{Addr: ":" + appConfig.ServerAddress.Gateway, Handler: handler}
+	go func() {
+		<-ctx.Done()
+		httpServer.Shutdown(context.Background())
+	}()
+
+	log.LogInfo(serviceName, "is listening on port", appConfig.ServerAddress.Gateway)
+	err = httpServer.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		log.LogInfo(serviceName, "has stopped")
+		return nil
+	}
+	return err
+}
+
+// newDependencies picks the real clients selected in the ALL_IN_ONE and STORAGE
+// configs, the others are fakes logging what they would have sent.
+func newDependencies(appConfig *config.Config, log logger.Logger) (Dependencies, error) {
+	selected := config.AllInOne{}
+	if appConfig.AllInOne != nil {
+		selected = *appConfig.AllInOne
+	}
+	deps := Dependencies{
+		Email:  &loggedEmailService{MemoryEmailService: mail.NewMemoryEmailService(), log: log},
+		Twilio: &loggedOTPService{MemoryOTPService: twilio.NewMemoryOTPService(), log: log},
+		Cache:  cache.NewMemoryCache(),
+	}
+
+	// without a STORAGE config the files are kept in memory instead of on S3
+	if appConfig.Storage != nil && appConfig.Storage.Backend != "" {
+		files, err := objectstore.New(appConfig)
+		if err != nil {
+			return deps, err
+		}
+		deps.Files = files
+	}
+
+	switch selected.Email {
+	case "", "memory":
+	case "gmail":
+		if appConfig.Email == nil {
+			return deps, errors.New("EMAIL is gmail but EMAIL is not configured")
+		}
+		deps.Email = mail.NewGmailService(appConfig.Email)
+		// the OTP of gmail is kept in redis
+		if appConfig.Redis != nil {
+			redisCache, err := cache.NewRedisCache(appConfig.Redis)
+			if err != nil {
+				return deps, err
+			}
+			deps.Cache = redisCache
+		}
+	default:
+		return deps, errors.New("unknown EMAIL: " + selected.Email)
+	}
+
+	switch selected.SMS {
+	case "", "memory":
+	case "twilio":
+		if appConfig.Twilio == nil {
+			return deps, errors.New("SMS is twilio but TWILIO is not configured")
+		}
+		deps.Twilio = twilio.NewOTPService(appConfig.Twilio)
+	default:
+		return deps, errors.New("unknown SMS: " + selected.SMS)
+	}
+	return deps, nil
+}
+
+func randomKey() (string, error) {
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key), nil
+}
+
+// loggedEmailService logs the OTP of the fake, so it can be used to sign up.
+type loggedEmailService struct {
+	*mail.MemoryEmailService
+	log logger.Logger
+}
+
+func (sender *loggedEmailService) SendOTP(ctx context.Context, to, subject, content string, redisCache cache.Cache) error {
+	if err := sender.MemoryEmailService.SendOTP(ctx, to, subject, content, redisCache); err != nil {
+		return err
+	}
+	otp, _ := sender.LastOTP(to)
+	sender.log.With(ctx).LogInfo("OTP sent to", to, ":", otp)
+	return nil
+}
+
+// loggedOTPService logs the OTP of the fake, so it can be used to sign up.
+type loggedOTPService struct {
+	*twilio.MemoryOTPService
+	log logger.Logger
+}
+
+func (service *loggedOTPService) SendOTP(to string) error {
+	if err := service.MemoryOTPService.SendOTP(to); err != nil {
+		return err
+	}
+	otp, _ := service.LastOTP(to)
+	service.log.LogInfo("OTP sent to", to, ":", otp)
+	return nil
+}
@@ -1,6 +1,8 @@
 package db
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"time"
 
@@ -19,7 +21,7 @@ func NewAuthStore(storage *database.Storage) *AuthStore {
 
 }
 
-func (store *AuthStore) InsertUser(user *proto.SignupRequest) (id string, err error) {
+func (store *AuthStore) InsertUser(ctx context.Context, user *proto.SignupRequest) (id string, err error) {
 	var column string
 	var value string
 	if user.Email != "" {
@@ -47,14 +49,14 @@ func (store *AuthStore) InsertUser(user *proto.SignupRequest) (id string, err er
 		return "", err
 	}
 
-	_, err = store.storage.DB.Exec(insertQuery, uuId, user.GetFullName(), user.GetUserName(), value, user.GetUserType(), user.Password, createdAt, updatedAt)
+	_, err = store.storage.DB.ExecContext(ctx, insertQuery, uuId, user.GetFullName(), user.GetUserName(), value, user.GetUserType(), user.Password, createdAt, updatedAt)
 	if err != nil {
 		return "", err
 	}
 	return uuId.String(), nil
 }
 
-func (store *AuthStore) ChangePassword(req *proto.ForgotPasswordRequest) (err error) {
+func (store *AuthStore) ChangePassword(ctx context.Context, req *proto.ForgotPasswordRequest) (err error) {
 	var column string
 	var value string
 	if req.Email != "" {
@@ -72,7 +74,7 @@ func (store *AuthStore) ChangePassword(req *proto.ForgotPasswordRequest) (err er
 	}
 
 	updateQuery := fmt.Sprintf(`UPDATE user_data SET password = $1, updated_at = $2 WHERE %s = $3`, column)
-	_, err = store.storage.DB.Exec(updateQuery, req.Password, updatedAt, value)
+	_, err = store.storage.DB.ExecContext(ctx, updateQuery, req.Password, updatedAt, value)
 	if err != nil {
 		return err
 	}
@@ -85,9 +87,9 @@ type TokenPayload struct {
 	UserType string
 }
 
-func (store *AuthStore) GetTokenPayload(column, value string) (*TokenPayload, error) {
+func (store *AuthStore) GetTokenPayload(ctx context.Context, column, value string) (*TokenPayload, error) {
 	selectQuery := fmt.Sprintf(`SELECT id, user_name, user_type FROM user_data WHERE %s = $1;`, column)
-	rows := store.storage.DB.QueryRow(selectQuery, value)
+	rows := store.storage.DB.QueryRowContext(ctx, selectQuery, value)
 	if rows.Err() != nil {
 		return nil, rows.Err()
 	}
@@ -100,9 +102,9 @@ func (store *AuthStore) GetTokenPayload(column, value string) (*TokenPayload, er
 	return &tokenPayload, nil
 }
 
-func (store AuthStore) CheckDataExist(column, value string) (bool, error) {
+func (store AuthStore) CheckDataExist(ctx context.Context, column, value string) (bool, error) {
 	checkQuery := fmt.Sprintf(`SELECT * FROM user_data WHERE %s = $1;`, column)
-	res, err := store.storage.DB.Exec(checkQuery, value)
+	res, err := store.storage.DB.ExecContext(ctx, checkQuery, value)
 	if err != nil {
 		return false, err
 	}
@@ -115,10 +117,10 @@ func (store AuthStore) CheckDataExist(column, value string) (bool, error) {
 	return result != 0, nil
 }
 
-func (store AuthStore) CheckPassword(userName, pass string) (bool, error) {
+func (store AuthStore) CheckPassword(ctx context.Context, userName, pass string) (bool, error) {
 	var hashPassword string
 	checkQuery := `SELECT password FROM user_data WHERE user_name = $1`
-	rows := store.storage.DB.QueryRow(checkQuery, userName)
+	rows := store.storage.DB.QueryRowContext(ctx, checkQuery, userName)
 	if rows.Err() != nil {
 		return false, rows.Err()
 	}
@@ -131,3 +133,91 @@ func (store AuthStore) CheckPassword(userName, pass string) (bool, error) {
 	res := password.VerifyPassword(hashPassword, pass)
 	return res, nil
 }
+
+func (store *AuthStore) MergeGuestCart(ctx context.Context, deviceId, userId string) error {
+	tx, err := store.storage.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	var guestCartID string
+	selectGuestQuery := `SELECT id FROM carts WHERE device_id = $1 AND user_id IS NULL FOR UPDATE;`
+	err = tx.QueryRowContext(ctx, selectGuestQuery, deviceId).Scan(&guestCartID)
+	if err == sql.ErrNoRows {
+		return tx.Commit()
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	now := time.Now()
+	var cartID string
+	selectCartQuery := `SELECT id FROM carts WHERE user_id = $1 FOR UPDATE;`
+	err = tx.QueryRowContext(ctx, selectCartQuery, userId).Scan(&cartID)
+	if err == sql.ErrNoRows {
+		// the guest cart becomes the cart of the user
+		updateQuery := `UPDATE carts SET user_id = $2, device_id = NULL, updated_at = $3 WHERE id = $1;`
+		_, err = tx.ExecContext(ctx, updateQuery, guestCartID, userId, now)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		return tx.Commit()
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// the variants in both carts keep the quantity of the user, topped up with the
+	// guest one up to the stock
+	updateItemsQuery := `
+	UPDATE cart_items u
+	SET quantity = GREATEST(u.quantity, LEAST(u.quantity + g.quantity, i.quantity)), updated_at = $3
+	FROM cart_items g
+	JOIN inventories i ON i.id = g.inventory_id
+	WHERE g.cart_id = $1 AND u.cart_id = $2 AND u.inventory_id = g.inventory_id;
+	`
+	_, err = tx.ExecContext(ctx, updateItemsQuery, guestCartID, cartID, now)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	deleteItemsQuery := `
+	DELETE FROM cart_items g
+	USING cart_items u
+	WHERE g.cart_id = $1 AND u.cart_id = $2 AND u.inventory_id = g.inventory_id;
+	`
+	_, err = tx.ExecContext(ctx, deleteItemsQuery, guestCartID, cartID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	moveItemsQuery := `UPDATE cart_items SET cart_id = $2, updated_at = $3 WHERE cart_id = $1;`
+	_, err = tx.ExecContext(ctx, moveItemsQuery, guestCartID, cartID, now)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM carts WHERE id = $1;`, guestCartID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	updatePriceQuery := `
+	UPDATE carts
+	SET price = COALESCE((SELECT SUM(quantity * price) FROM cart_items WHERE cart_id = $1), 0), updated_at = $2
+	WHERE id = $1;
+	`
+	_, err = tx.ExecContext(ctx, updatePriceQuery, cartID, now)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
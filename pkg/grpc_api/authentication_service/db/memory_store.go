@@ -0,0 +1,203 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/akmal4410/gestapo/internal/database/memory"
+	"github.com/akmal4410/gestapo/pkg/api/proto"
+	"github.com/akmal4410/gestapo/pkg/service/password"
+	"github.com/google/uuid"
+)
+
+// MemoryAuthStore is the in-memory AuthRepository.
+type MemoryAuthStore struct {
+	db *memory.Database
+}
+
+func NewMemoryAuthStore(db *memory.Database) *MemoryAuthStore {
+	return &MemoryAuthStore{db: db}
+}
+
+func (store *MemoryAuthStore) InsertUser(ctx context.Context, user *proto.SignupRequest) (id string, err error) {
+	user.Password, err = password.HashPassword(user.GetPassword())
+	if err != nil {
+		return "", err
+	}
+
+	store.db.Lock()
+	defer store.db.Unlock()
+	for _, column := range []struct{ name, value string }{
+		{"user_name", user.GetUserName()},
+		{"email", user.GetEmail()},
+		{"phone", user.GetPhone()},
+	} {
+		if column.value == "" {
+			continue
+		}
+		exist, err := store.db.Exists("user_data", column.name, column.value)
+		if err != nil {
+			return "", err
+		}
+		if exist {
+			return "", fmt.Errorf("duplicate key value violates unique constraint on %s", column.name)
+		}
+	}
+
+	now := time.Now()
+	row := &memory.User{
+		ID:        uuid.NewString(),
+		UserName:  user.GetUserName(),
+		UserType:  user.GetUserType(),
+		Password:  user.Password,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	fullName := user.GetFullName()
+	row.FullName = &fullName
+	if user.Email != "" {
+		email := user.GetEmail()
+		row.Email = &email
+	} else if user.Phone != "" {
+		phone := user.GetPhone()
+		row.Phone = &phone
+	}
+	store.db.Users[row.ID] = row
+	return row.ID, nil
+}
+
+func (store *MemoryAuthStore) ChangePassword(ctx context.Context, req *proto.ForgotPasswordRequest) (err error) {
+	req.Password, err = password.HashPassword(req.GetPassword())
+	if err != nil {
+		return err
+	}
+
+	store.db.Lock()
+	defer store.db.Unlock()
+	for _, user := range store.db.Users {
+		if matchesContact(user, req.GetEmail(), req.GetPhone()) {
+			user.Password = req.Password
+			user.UpdatedAt = time.Now()
+		}
+	}
+	return nil
+}
+
+func (store *MemoryAuthStore) GetTokenPayload(ctx context.Context, column, value string) (*TokenPayload, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	user, err := store.findUser(column, value)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPayload{UserId: user.ID, UserName: user.UserName, UserType: user.UserType}, nil
+}
+
+func (store *MemoryAuthStore) CheckDataExist(ctx context.Context, column, value string) (bool, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	return store.db.Exists("user_data", column, value)
+}
+
+func (store *MemoryAuthStore) CheckPassword(ctx context.Context, userName, pass string) (bool, error) {
+	store.db.Lock()
+	user, err := store.findUser("user_name", userName)
+	store.db.Unlock()
+	if err != nil {
+		return false, err
+	}
+	return password.VerifyPassword(user.Password, pass), nil
+}
+
+func (store *MemoryAuthStore) MergeGuestCart(ctx context.Context, deviceId, userId string) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	var guestCart, cart *memory.Cart
+	for _, c := range store.db.Carts {
+		if c.UserID == "" && c.DeviceID != nil && *c.DeviceID == deviceId {
+			guestCart = c
+		} else if c.UserID == userId {
+			cart = c
+		}
+	}
+	if guestCart == nil {
+		return nil
+	}
+	now := time.Now()
+	if cart == nil {
+		// the guest cart becomes the cart of the user
+		guestCart.UserID = userId
+		guestCart.DeviceID = nil
+		guestCart.UpdatedAt = now
+		return nil
+	}
+
+	items := map[string]*memory.CartItem{}
+	for _, item := range store.db.CartItems {
+		if item.CartID == cart.ID {
+			items[item.InventoryID] = item
+		}
+	}
+	for _, item := range store.db.CartItems {
+		if item.CartID != guestCart.ID {
+			continue
+		}
+		userItem, ok := items[item.InventoryID]
+		if !ok {
+			item.CartID = cart.ID
+			item.UpdatedAt = now
+			continue
+		}
+		// the variants in both carts keep the quantity of the user, topped up with the
+		// guest one up to the stock
+		quantity := userItem.Quantity + item.Quantity
+		if inventory, ok := store.db.Inventories[item.InventoryID]; ok && quantity > inventory.Quantity {
+			quantity = max(userItem.Quantity, inventory.Quantity)
+		}
+		userItem.Quantity = quantity
+		userItem.UpdatedAt = now
+		delete(store.db.CartItems, item.ID)
+	}
+	delete(store.db.Carts, guestCart.ID)
+
+	cart.Price = 0
+	for _, item := range store.db.CartItems {
+		if item.CartID == cart.ID {
+			cart.Price += float64(item.Quantity) * item.Price
+		}
+	}
+	cart.UpdatedAt = now
+	return nil
+}
+
+// findUser returns the user having value in column, the caller must hold the lock.
+func (store *MemoryAuthStore) findUser(column, value string) (*memory.User, error) {
+	for _, user := range store.db.Users {
+		switch column {
+		case "user_name":
+			if user.UserName == value {
+				return user, nil
+			}
+		case "email":
+			if matchesContact(user, value, "") {
+				return user, nil
+			}
+		case "phone":
+			if matchesContact(user, "", value) {
+				return user, nil
+			}
+		default:
+			return nil, fmt.Errorf("column \"%s\" does not exist", column)
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func matchesContact(user *memory.User, email, phone string) bool {
+	if email != "" {
+		return user.Email != nil && *user.Email == email
+	}
+	return phone != "" && user.Phone != nil && *user.Phone == phone
+}
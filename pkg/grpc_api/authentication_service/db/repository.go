@@ -0,0 +1,25 @@
+package db
+
+import (
+	"context"
+
+	"github.com/akmal4410/gestapo/pkg/api/proto"
+)
+
+// AuthRepository is the storage used by the authentication service,
+// implemented by AuthStore on Postgres and by MemoryAuthStore in memory.
+type AuthRepository interface {
+	InsertUser(ctx context.Context, user *proto.SignupRequest) (id string, err error)
+	ChangePassword(ctx context.Context, req *proto.ForgotPasswordRequest) error
+	GetTokenPayload(ctx context.Context, column, value string) (*TokenPayload, error)
+	CheckDataExist(ctx context.Context, column, value string) (bool, error)
+	CheckPassword(ctx context.Context, userName, pass string) (bool, error)
+	// MergeGuestCart moves the guest cart of the device into the cart of the user,
+	// adding up the quantities of the variants in both up to their stock
+	MergeGuestCart(ctx context.Context, deviceId, userId string) error
+}
+
+var (
+	_ AuthRepository = (*AuthStore)(nil)
+	_ AuthRepository = (*MemoryAuthStore)(nil)
+)
@@ -1,8 +1,10 @@
 package interceptor
 
 import (
+	helper "github.com/akmal4410/gestapo/pkg/helpers/interceptor"
 	"github.com/akmal4410/gestapo/pkg/helpers/logger"
 	"github.com/akmal4410/gestapo/pkg/helpers/token"
+	"google.golang.org/grpc"
 )
 
 type AuthInterceptor struct {
@@ -16,3 +18,8 @@ func NewAuthInterceptor(token token.Maker, log logger.Logger) *AuthInterceptor {
 		log:   log,
 	}
 }
+
+// LoggingMiddleware is a gRPC unary server interceptor for request logging.
+func (interceptor *AuthInterceptor) LoggingMiddleware() grpc.UnaryServerInterceptor {
+	return helper.RequestLogger(interceptor.log)
+}
@@ -22,21 +22,21 @@ const (
 // AuthMiddleware is a gRPC unary server interceptor for authentication.
 func (interceptor *AuthInterceptor) AuthMiddleware() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		interceptor.log.LogInfo("Calling gRPC meathod :", info.FullMethod)
+		interceptor.log.With(ctx).LogDebug("Calling gRPC meathod :", info.FullMethod)
 		if ok := isAuthenticationNeeded(info.FullMethod); !ok {
 			return handler(ctx, req)
 		}
 		md, ok := metadata.FromIncomingContext(ctx)
 		if !ok {
 			err := errors.New("metadata is not provided")
-			interceptor.log.LogError("Error : ", err)
+			interceptor.log.With(ctx).LogError("Error : ", err)
 			return nil, status.Errorf(codes.Unauthenticated, err.Error())
 		}
 
 		authorizationHeaders := md.Get(utils.AuthorizationKey)
 		if len(authorizationHeaders) == 0 {
 			err := errors.New("authorization header is not provided")
-			interceptor.log.LogError("Error : ", err)
+			interceptor.log.With(ctx).LogError("Error : ", err)
 			return nil, status.Errorf(codes.Unauthenticated, err.Error())
 		}
 
@@ -44,14 +44,14 @@ func (interceptor *AuthInterceptor) AuthMiddleware() grpc.UnaryServerInterceptor
 		fields := strings.Fields(authorizationHeader)
 		if len(fields) < 2 {
 			err := errors.New("invalid authorization header format")
-			interceptor.log.LogError("Error : ", err)
+			interceptor.log.With(ctx).LogError("Error : ", err)
 			return nil, status.Errorf(codes.Unauthenticated, err.Error())
 		}
 
 		authorizationType := strings.ToLower(fields[0])
 		if authorizationType != utils.AuthorizationTypeBearer {
 			err := fmt.Errorf("unsupported authorization type: %s", authorizationType)
-			interceptor.log.LogError("Error : ", err)
+			interceptor.log.With(ctx).LogError("Error : ", err)
 			return nil, status.Errorf(codes.Unauthenticated, err.Error())
 		}
 
@@ -60,7 +60,7 @@ func (interceptor *AuthInterceptor) AuthMiddleware() grpc.UnaryServerInterceptor
 		payload, err := interceptor.token.VerifySessionToken(token)
 		if err != nil {
 			err := fmt.Errorf("error while VerifySessionToken: %s", err.Error())
-			interceptor.log.LogError("Error : ", err)
+			interceptor.log.With(ctx).LogError("Error : ", err)
 			return nil, status.Errorf(codes.Unauthenticated, err.Error())
 		}
 
@@ -74,19 +74,19 @@ func (interceptor *AuthInterceptor) AuthMiddleware() grpc.UnaryServerInterceptor
 func (interceptor *AuthInterceptor) AuthSsoMiddleware() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 		if ok := isSSONeeded(info.FullMethod); !ok {
-			interceptor.log.LogInfo("Calling gRPC meathod :", info.FullMethod)
+			interceptor.log.With(ctx).LogDebug("Calling gRPC meathod :", info.FullMethod)
 			return handler(ctx, req)
 		}
 		md, ok := metadata.FromIncomingContext(ctx)
 		if !ok {
 			err := errors.New("metadata is not provided")
-			interceptor.log.LogError("Error : ", err)
+			interceptor.log.With(ctx).LogError("Error : ", err)
 			return nil, status.Errorf(codes.Unauthenticated, err.Error())
 		}
 		authorizationHeaders := md.Get(utils.AuthorizationKey)
 		if len(authorizationHeaders) == 0 {
 			err := errors.New("authorization header is not provided")
-			interceptor.log.LogError("Error : ", err)
+			interceptor.log.With(ctx).LogError("Error : ", err)
 			return nil, status.Errorf(codes.Unauthenticated, err.Error())
 
 		}
@@ -94,7 +94,7 @@ func (interceptor *AuthInterceptor) AuthSsoMiddleware() grpc.UnaryServerIntercep
 		fields := strings.Fields(authorizationHeader)
 		if len(fields) < 2 {
 			err := errors.New("invalid authorization header format")
-			interceptor.log.LogError("Error : ", err)
+			interceptor.log.With(ctx).LogError("Error : ", err)
 			return nil, status.Errorf(codes.Unauthenticated, err.Error())
 
 		}
@@ -102,7 +102,7 @@ func (interceptor *AuthInterceptor) AuthSsoMiddleware() grpc.UnaryServerIntercep
 		authorizationType := strings.ToLower(fields[0])
 		if authorizationType != utils.AuthorizationTypeBearer {
 			err := fmt.Errorf("unsupported authorization type: %s", authorizationType)
-			interceptor.log.LogError("Error : ", err)
+			interceptor.log.With(ctx).LogError("Error : ", err)
 			return nil, status.Errorf(codes.Unauthenticated, err.Error())
 		}
 
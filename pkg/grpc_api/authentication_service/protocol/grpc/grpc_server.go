@@ -24,18 +24,7 @@ func RunGRPCService(ctx context.Context, storage *database.Storage, config *conf
 		log.LogFatal("Error while Initializing NewJWTMaker %w", err)
 	}
 	service := service.NewAuthenticationService(storage, config, log, tokenMaker)
-	authInterceptor := interceptor.NewAuthInterceptor(tokenMaker, log)
-
-	grpcServer := grpc.NewServer(
-		grpc.ChainUnaryInterceptor(
-			authInterceptor.AuthMiddleware(),
-			// authInterceptor.AuthValidator(),//TODO: fix validation
-		),
-	)
-
-	proto.RegisterAuthenticationServiceServer(grpcServer, service)
-	log.LogInfo("Registreing for reflection")
-	reflection.Register(grpcServer)
+	grpcServer := NewGRPCServer(service, tokenMaker, log)
 	port := ":" + config.ServerAddress.Authentication.Port
 
 	lis, err := net.Listen("tcp", port)
@@ -56,3 +45,22 @@ func RunGRPCService(ctx context.Context, storage *database.Storage, config *conf
 	log.LogInfo("Start gRPC server at ", lis.Addr().String())
 	return grpcServer.Serve(lis)
 }
+
+// NewGRPCServer creates the gRPC server of the authentication service with its interceptors.
+func NewGRPCServer(service proto.AuthenticationServiceServer, tokenMaker token.Maker, log logger.Logger) *grpc.Server {
+	authInterceptor := interceptor.NewAuthInterceptor(tokenMaker, log)
+
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			authInterceptor.LoggingMiddleware(),
+			authInterceptor.AuthMiddleware(),
+			authInterceptor.AuthSsoMiddleware(),
+			// authInterceptor.AuthValidator(),//TODO: fix validation
+		),
+	)
+
+	proto.RegisterAuthenticationServiceServer(grpcServer, service)
+	log.LogInfo("Registreing for reflection")
+	reflection.Register(grpcServer)
+	return grpcServer
+}
@@ -0,0 +1,305 @@
+package grpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/akmal4410/gestapo/internal/testenv"
+	"github.com/akmal4410/gestapo/pkg/api/proto"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func assertCode(t *testing.T, err error, code codes.Code) {
+	t.Helper()
+	if status.Code(err) != code {
+		t.Fatalf("expected %s, got %v", code, err)
+	}
+}
+
+func sendOTP(t *testing.T, client proto.AuthenticationServiceClient, req *proto.SendOTPRequest) string {
+	t.Helper()
+	var header metadata.MD
+	_, err := client.SendOTP(context.Background(), req, grpc.Header(&header))
+	if err != nil {
+		t.Fatalf("SendOTP: %v", err)
+	}
+	sessionToken := header.Get("session-token")
+	if len(sessionToken) == 0 {
+		t.Fatal("SendOTP did not return a session token")
+	}
+	return sessionToken[0]
+}
+
+func TestSignUpWithEmail(t *testing.T) {
+	env := testenv.New(t)
+	client := env.AuthClient(t)
+	email := "new.user@example.com"
+
+	sessionToken := sendOTP(t, client, &proto.SendOTPRequest{Email: email, Action: utils.SIGN_UP})
+	otp, ok := env.Email.LastOTP(email)
+	if !ok {
+		t.Fatal("no OTP was mailed")
+	}
+
+	req := &proto.SignupRequest{
+		Email:    email,
+		FullName: "New User",
+		UserName: "new_user",
+		UserType: utils.USER,
+		Code:     "000000",
+		Password: "password123",
+	}
+	ctx := testenv.WithToken(context.Background(), sessionToken)
+	_, err := client.SignUpUser(ctx, req)
+	assertCode(t, err, codes.PermissionDenied)
+
+	req.Code = otp
+	var header metadata.MD
+	res, err := client.SignUpUser(ctx, req, grpc.Header(&header))
+	if err != nil {
+		t.Fatalf("SignUpUser: %v", err)
+	}
+	if !res.Status || len(header.Get("access-token")) == 0 {
+		t.Fatalf("unexpected response %v, header %v", res, header)
+	}
+
+	_, err = client.SendOTP(context.Background(), &proto.SendOTPRequest{Email: email, Action: utils.SIGN_UP})
+	assertCode(t, err, codes.AlreadyExists)
+
+	_, err = client.LoginUser(context.Background(), &proto.LoginRequest{UserName: "new_user", Password: "password123"})
+	if err != nil {
+		t.Fatalf("LoginUser: %v", err)
+	}
+}
+
+func TestSignUpWithPhone(t *testing.T) {
+	env := testenv.New(t)
+	client := env.AuthClient(t)
+	phone := "9876543210"
+
+	sessionToken := sendOTP(t, client, &proto.SendOTPRequest{Phone: phone, Action: utils.SIGN_UP})
+	otp, ok := env.Twilio.LastOTP("+91" + phone)
+	if !ok {
+		t.Fatal("no OTP was sent")
+	}
+
+	ctx := testenv.WithToken(context.Background(), sessionToken)
+	req := &proto.SignupRequest{
+		Phone:    "9999999999",
+		UserName: "phone_user",
+		UserType: utils.MERCHANT,
+		Code:     otp,
+		Password: "password123",
+	}
+	_, err := client.SignUpUser(ctx, req)
+	assertCode(t, err, codes.PermissionDenied)
+
+	req.Phone = phone
+	if _, err = client.SignUpUser(ctx, req); err != nil {
+		t.Fatalf("SignUpUser: %v", err)
+	}
+}
+
+func TestSendOTPValidation(t *testing.T) {
+	env := testenv.New(t)
+	client := env.AuthClient(t)
+
+	tests := []*proto.SendOTPRequest{
+		{Action: utils.SIGN_UP},
+		{Email: "a@example.com", Phone: "9876543210", Action: utils.SIGN_UP},
+		{Email: "not-an-email", Action: utils.SIGN_UP},
+		{Email: "a@example.com", Action: "sign-in"},
+	}
+	for _, req := range tests {
+		_, err := client.SendOTP(context.Background(), req)
+		assertCode(t, err, codes.InvalidArgument)
+	}
+}
+
+func TestSignUpRequiresSessionToken(t *testing.T) {
+	env := testenv.New(t)
+	client := env.AuthClient(t)
+	req := &proto.SignupRequest{Email: "a@example.com", UserName: "a", UserType: utils.USER, Code: "123456", Password: "password123"}
+
+	_, err := client.SignUpUser(context.Background(), req)
+	assertCode(t, err, codes.Unauthenticated)
+
+	_, err = client.SignUpUser(testenv.WithToken(context.Background(), "invalid"), req)
+	assertCode(t, err, codes.Unauthenticated)
+}
+
+func TestLoginUser(t *testing.T) {
+	env := testenv.New(t)
+	client := env.AuthClient(t)
+	env.AddUser(t, "login_user", utils.USER)
+
+	_, err := client.LoginUser(context.Background(), &proto.LoginRequest{UserName: "missing", Password: testenv.Password})
+	assertCode(t, err, codes.NotFound)
+
+	_, err = client.LoginUser(context.Background(), &proto.LoginRequest{UserName: "login_user", Password: "wrong-password"})
+	assertCode(t, err, codes.PermissionDenied)
+
+	var header metadata.MD
+	_, err = client.LoginUser(context.Background(), &proto.LoginRequest{UserName: "login_user", Password: testenv.Password}, grpc.Header(&header))
+	if err != nil {
+		t.Fatalf("LoginUser: %v", err)
+	}
+	accessToken := header.Get("access-token")
+	if len(accessToken) == 0 {
+		t.Fatal("LoginUser did not return an access token")
+	}
+	payload, err := env.Token.VerifyAccessToken(accessToken[0])
+	if err != nil {
+		t.Fatalf("VerifyAccessToken: %v", err)
+	}
+	if payload.UserName != "login_user" || payload.UserType != utils.USER {
+		t.Fatalf("unexpected payload %+v", payload)
+	}
+}
+
+func TestLoginMergesGuestCart(t *testing.T) {
+	env := testenv.New(t)
+	client := env.AuthClient(t)
+	userClient := env.UserClient(t)
+	merchant, _ := env.AddUser(t, "merchant", utils.MERCHANT)
+	_, userToken := env.AddUser(t, "login_user", utils.USER)
+	category := env.AddCategory(t, "Shoes")
+	runner := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5, 8)
+	trail := env.AddProduct(t, merchant.ID, category.ID, "trail", 90, 5, 8)
+	userCtx := testenv.WithToken(context.Background(), userToken)
+	guestCtx := metadata.AppendToOutgoingContext(context.Background(), utils.DeviceIDKey, "device-1")
+
+	if _, err := userClient.AddProductToCart(userCtx, &proto.AddToCartRequest{ProductId: runner.ID, Size: 8, Quantity: 2}); err != nil {
+		t.Fatalf("AddProductToCart: %v", err)
+	}
+	for _, product := range []string{runner.ID, trail.ID} {
+		if _, err := userClient.AddProductToCart(guestCtx, &proto.AddToCartRequest{ProductId: product, Size: 8, Quantity: 4}); err != nil {
+			t.Fatalf("AddProductToCart: %v", err)
+		}
+	}
+
+	_, err := client.LoginUser(guestCtx, &proto.LoginRequest{UserName: "login_user", Password: testenv.Password})
+	if err != nil {
+		t.Fatalf("LoginUser: %v", err)
+	}
+	cart, err := userClient.GetCartItmes(userCtx, &proto.Request{})
+	if err != nil {
+		t.Fatalf("GetCartItmes: %v", err)
+	}
+	// the quantities of the variant in both carts are added up to the stock
+	quantities := map[string]int32{}
+	for _, item := range cart.Data {
+		quantities[item.Name] = item.Quantity
+	}
+	if len(cart.Data) != 2 || quantities["runner"] != 5 || quantities["trail"] != 4 {
+		t.Fatalf("unexpected merged cart %v", cart.Data)
+	}
+	guestCart, err := userClient.GetCartItmes(guestCtx, &proto.Request{})
+	if err != nil {
+		t.Fatalf("GetCartItmes: %v", err)
+	}
+	if len(guestCart.Data) != 0 {
+		t.Fatalf("expected the guest cart to be merged, got %v", guestCart.Data)
+	}
+}
+
+func TestSignUpTakesGuestCart(t *testing.T) {
+	env := testenv.New(t)
+	client := env.AuthClient(t)
+	userClient := env.UserClient(t)
+	merchant, _ := env.AddUser(t, "merchant", utils.MERCHANT)
+	category := env.AddCategory(t, "Shoes")
+	product := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5, 8)
+	email := "guest@example.com"
+	guestCtx := metadata.AppendToOutgoingContext(context.Background(), utils.DeviceIDKey, "device-1")
+
+	if _, err := userClient.AddProductToCart(guestCtx, &proto.AddToCartRequest{ProductId: product.ID, Size: 8, Quantity: 3}); err != nil {
+		t.Fatalf("AddProductToCart: %v", err)
+	}
+	sessionToken := sendOTP(t, client, &proto.SendOTPRequest{Email: email, Action: utils.SIGN_UP})
+	otp, ok := env.Email.LastOTP(email)
+	if !ok {
+		t.Fatal("no OTP was mailed")
+	}
+	req := &proto.SignupRequest{
+		Email:    email,
+		Code:     otp,
+		UserName: "guest_user",
+		FullName: "Guest User",
+		UserType: utils.USER,
+		Password: "password123",
+	}
+	var header metadata.MD
+	ctx := metadata.AppendToOutgoingContext(testenv.WithToken(context.Background(), sessionToken), utils.DeviceIDKey, "device-1")
+	if _, err := client.SignUpUser(ctx, req, grpc.Header(&header)); err != nil {
+		t.Fatalf("SignUpUser: %v", err)
+	}
+
+	cart, err := userClient.GetCartItmes(testenv.WithToken(context.Background(), header.Get("access-token")[0]), &proto.Request{})
+	if err != nil {
+		t.Fatalf("GetCartItmes: %v", err)
+	}
+	if len(cart.Data) != 1 || cart.Data[0].Quantity != 3 {
+		t.Fatalf("expected the guest cart, got %v", cart.Data)
+	}
+}
+
+func TestForgotPassword(t *testing.T) {
+	env := testenv.New(t)
+	client := env.AuthClient(t)
+	user, _ := env.AddUser(t, "forgetful", utils.USER)
+
+	sessionToken := sendOTP(t, client, &proto.SendOTPRequest{Email: *user.Email, Action: utils.FORGOT_PASSWORD})
+	otp, _ := env.Email.LastOTP(*user.Email)
+	ctx := testenv.WithToken(context.Background(), sessionToken)
+
+	_, err := client.ForgotPassword(ctx, &proto.ForgotPasswordRequest{Email: *user.Email, Code: otp, Password: "short"})
+	assertCode(t, err, codes.InvalidArgument)
+
+	_, err = client.ForgotPassword(ctx, &proto.ForgotPasswordRequest{Email: *user.Email, Code: otp, Password: "new-password"})
+	if err != nil {
+		t.Fatalf("ForgotPassword: %v", err)
+	}
+
+	_, err = client.LoginUser(context.Background(), &proto.LoginRequest{UserName: "forgetful", Password: testenv.Password})
+	assertCode(t, err, codes.PermissionDenied)
+	_, err = client.LoginUser(context.Background(), &proto.LoginRequest{UserName: "forgetful", Password: "new-password"})
+	if err != nil {
+		t.Fatalf("LoginUser: %v", err)
+	}
+}
+
+func TestSSOAuth(t *testing.T) {
+	env := testenv.New(t)
+	client := env.AuthClient(t)
+	env.SSO.AddToken("google-token", "sso.user@example.com", "sso_user")
+	ctx := testenv.WithToken(context.Background(), "google-token")
+
+	_, err := client.SSOAuth(ctx, &proto.SsoRequest{UserType: utils.USER, Action: "sso-web"})
+	assertCode(t, err, codes.InvalidArgument)
+
+	res, err := client.SSOAuth(ctx, &proto.SsoRequest{UserType: utils.USER, Action: utils.SSO_ANDROID})
+	if err != nil {
+		t.Fatalf("SSOAuth: %v", err)
+	}
+	if res.Message != "User Signup Successfully" {
+		t.Fatalf("expected a sign up, got %q", res.Message)
+	}
+
+	res, err = client.SSOAuth(ctx, &proto.SsoRequest{UserType: utils.USER, Action: utils.SSO_IOS})
+	if err != nil {
+		t.Fatalf("SSOAuth: %v", err)
+	}
+	if res.Message != "User loggedin Successfully" {
+		t.Fatalf("expected a login, got %q", res.Message)
+	}
+
+	_, err = client.SSOAuth(testenv.WithToken(context.Background(), "unknown-token"), &proto.SsoRequest{UserType: utils.USER, Action: utils.SSO_ANDROID})
+	if status.Code(err) == codes.OK {
+		t.Fatal("expected an unknown google token to fail")
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"github.com/akmal4410/gestapo/pkg/api/proto"
 	"github.com/akmal4410/gestapo/pkg/helpers"
 	"github.com/akmal4410/gestapo/pkg/helpers/token"
-	"github.com/akmal4410/gestapo/pkg/service/sso"
 	"github.com/akmal4410/gestapo/pkg/utils"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -18,45 +17,45 @@ import (
 func (auth *authenticationService) SendOTP(ctx context.Context, req *proto.SendOTPRequest) (*proto.Response, error) {
 	err := validateSendOTPRequest(req)
 	if err != nil {
-		auth.log.LogError("Error while validateSendOTPRequest", err)
+		auth.log.With(ctx).LogError("Error while validateSendOTPRequest", err)
 		return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
 	}
 
 	column, value := helpers.IdentifiesColumnValue(req.GetEmail(), req.GetPhone())
 	if req.Action == utils.SIGN_UP {
 		if len(column) == 0 {
-			auth.log.LogError("Error while IdentifiesColumnValue", column)
+			auth.log.With(ctx).LogError("Error while IdentifiesColumnValue", column)
 			return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 		}
-		res, err := auth.storage.CheckDataExist(column, value)
+		res, err := auth.storage.CheckDataExist(ctx, column, value)
 		if err != nil {
-			auth.log.LogError("Error while CheckDataExist", err)
+			auth.log.With(ctx).LogError("Error while CheckDataExist", err)
 			return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 		}
 		if res {
 			err = fmt.Errorf("account already exist using this %s", column)
-			auth.log.LogError(err)
+			auth.log.With(ctx).LogError(err)
 			return nil, status.Errorf(codes.AlreadyExists, "account already exist using this %s", column)
 		}
 	}
 
 	if !helpers.IsEmpty(req.Email) {
-		err = auth.emailService.SendOTP(req.Email, utils.EmailSubject, utils.EmailSubject, auth.redis)
+		err = auth.emailService.SendOTP(ctx, req.Email, utils.EmailSubject, utils.EmailSubject, auth.redis)
 		if err != nil {
-			auth.log.LogError("Error while SendOTP", err)
+			auth.log.With(ctx).LogError("Error while SendOTP", err)
 			return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 		}
 	} else {
 		phoneNumber := fmt.Sprintf("+91%s", req.Phone)
 		err = auth.twilioService.SendOTP(phoneNumber)
 		if err != nil {
-			auth.log.LogError("Error while SendOTP", err)
+			auth.log.With(ctx).LogError("Error while SendOTP", err)
 			return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 		}
 	}
 	sessionToken, err := auth.token.CreateSessionToken(value, req.Action)
 	if err != nil {
-		auth.log.LogError("Error while CreateSessionToken", err)
+		auth.log.With(ctx).LogError("Error while CreateSessionToken", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 	response := &proto.Response{
@@ -71,58 +70,58 @@ func (auth *authenticationService) SendOTP(ctx context.Context, req *proto.SendO
 	return response, grpc.SetHeader(ctx, mdOut)
 }
 
-func (auth *authenticationService) verifyOTP(payload *token.SessionPayload, email, phone, code, action string) (bool, error) {
+func (auth *authenticationService) verifyOTP(ctx context.Context, payload *token.SessionPayload, email, phone, code, action string) (bool, error) {
 	// auth.log.LogInfo(payload.TokenType)
 	// if payload.TokenType != action {
-	// 	auth.log.LogError("Payload doesnot match")
+	// 	auth.log.With(ctx).LogError("Payload doesnot match")
 	// 	return false, status.Errorf(codes.PermissionDenied, "Unauthorized: Payload doesnot match")
 	// }
 
 	column, value := helpers.IdentifiesColumnValue(email, phone)
 	if action == utils.SIGN_UP {
 		if len(column) == 0 {
-			auth.log.LogError("Error while IdentifiesColumnValue", column)
+			auth.log.With(ctx).LogError("Error while IdentifiesColumnValue", column)
 			return false, status.Errorf(codes.Internal, utils.InternalServerError)
 		}
-		res, err := auth.storage.CheckDataExist(column, value)
+		res, err := auth.storage.CheckDataExist(ctx, column, value)
 		if err != nil {
-			auth.log.LogError("Error while CheckDataExist", err)
+			auth.log.With(ctx).LogError("Error while CheckDataExist", err)
 			return false, status.Errorf(codes.Internal, utils.InternalServerError)
 		}
 		if res {
 			fmtError := fmt.Errorf("account already exist using this %s", column)
-			auth.log.LogError(fmtError)
+			auth.log.With(ctx).LogError(fmtError)
 			return false, status.Errorf(codes.AlreadyExists, "account already exist using this %s", column)
 		}
 	}
 
 	if !helpers.IsEmpty(email) {
 		if payload.Value != email {
-			auth.log.LogError("Forbidden")
+			auth.log.With(ctx).LogError("Forbidden")
 			return false, status.Errorf(codes.PermissionDenied, "Forbidden")
 		}
-		sts, err := auth.emailService.VerfiyOTP(email, code, auth.redis)
+		sts, err := auth.emailService.VerfiyOTP(ctx, email, code, auth.redis)
 		if err != nil {
-			auth.log.LogError("Error while VerfiyOTP", err)
+			auth.log.With(ctx).LogError("Error while VerfiyOTP", err)
 			return false, status.Errorf(codes.Internal, utils.InternalServerError)
 		}
 		if !sts {
-			auth.log.LogError("Invalid OTP")
+			auth.log.With(ctx).LogError("Invalid OTP")
 			return false, status.Errorf(codes.PermissionDenied, "Invalid OTP")
 		}
 	} else {
 		if payload.Value != phone {
-			auth.log.LogError("Forbidden")
+			auth.log.With(ctx).LogError("Forbidden")
 			return false, status.Errorf(codes.PermissionDenied, "Forbidden")
 		}
 		phoneNumber := fmt.Sprintf("+91%s", phone)
 		sts, err := auth.twilioService.VerfiyOTP(phoneNumber, code)
 		if err != nil {
-			auth.log.LogError("Error while VerfiyOTP", err)
+			auth.log.With(ctx).LogError("Error while VerfiyOTP", err)
 			return false, status.Errorf(codes.Internal, utils.InternalServerError)
 		}
 		if !sts {
-			auth.log.LogError("Invalid OTP")
+			auth.log.With(ctx).LogError("Invalid OTP")
 			return false, status.Errorf(codes.PermissionDenied, "Invalid OTP")
 		}
 	}
@@ -133,25 +132,26 @@ func (auth *authenticationService) SignUpUser(ctx context.Context, req *proto.Si
 
 	err := helpers.ValidateEmailOrPhone(req.GetEmail(), req.GetPhone())
 	if err != nil {
-		auth.log.LogError("Error while ValidateEmailOrPhone", err)
+		auth.log.With(ctx).LogError("Error while ValidateEmailOrPhone", err)
 		return nil, status.Errorf(codes.InvalidArgument, "Invalid Email or Phone")
 	}
 
 	payload := ctx.Value(utils.AuthorizationPayloadKey).(*token.SessionPayload)
-	verify, err := auth.verifyOTP(payload, req.Email, req.Phone, req.Code, utils.SIGN_UP)
+	verify, err := auth.verifyOTP(ctx, payload, req.Email, req.Phone, req.Code, utils.SIGN_UP)
 
 	if !verify {
 		return nil, err
 	}
-	id, err := auth.storage.InsertUser(req)
+	id, err := auth.storage.InsertUser(ctx, req)
 	if err != nil {
-		auth.log.LogError("Error while InsertUser", err)
+		auth.log.With(ctx).LogError("Error while InsertUser", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
+	auth.mergeGuestCart(ctx, id, req.GetUserType())
 
 	token, err := auth.token.CreateAccessToken(id, req.UserName, req.UserType)
 	if err != nil {
-		auth.log.LogError("Error while CreateAccessToken", err)
+		auth.log.With(ctx).LogError("Error while CreateAccessToken", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
@@ -168,34 +168,35 @@ func (auth *authenticationService) SignUpUser(ctx context.Context, req *proto.Si
 }
 
 func (auth *authenticationService) LoginUser(ctx context.Context, req *proto.LoginRequest) (*proto.Response, error) {
-	res, err := auth.storage.CheckDataExist("user_name", req.GetUserName())
+	res, err := auth.storage.CheckDataExist(ctx, "user_name", req.GetUserName())
 	if err != nil {
-		auth.log.LogError("Error while CheckDataExist", err)
+		auth.log.With(ctx).LogError("Error while CheckDataExist", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 	if !res {
-		auth.log.LogError("User doesn't exist", req.GetUserName())
+		auth.log.With(ctx).LogError("User doesn't exist", req.GetUserName())
 		return nil, status.Errorf(codes.NotFound, "User doesn't exist")
 	}
 
-	res, err = auth.storage.CheckPassword(req.UserName, req.Password)
+	res, err = auth.storage.CheckPassword(ctx, req.UserName, req.Password)
 	if err != nil {
-		auth.log.LogError("Error while CheckPassword", err)
+		auth.log.With(ctx).LogError("Error while CheckPassword", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 	if !res {
-		auth.log.LogError("Wrong password")
+		auth.log.With(ctx).LogError("Wrong password")
 		return nil, status.Errorf(codes.PermissionDenied, "User crediantials doesn't match")
 	}
 
-	payload, err := auth.storage.GetTokenPayload("user_name", req.UserName)
+	payload, err := auth.storage.GetTokenPayload(ctx, "user_name", req.UserName)
 	if err != nil {
-		auth.log.LogError("Error while GetTokenPayload", err)
+		auth.log.With(ctx).LogError("Error while GetTokenPayload", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
+	auth.mergeGuestCart(ctx, payload.UserId, payload.UserType)
 	token, err := auth.token.CreateAccessToken(payload.UserId, req.UserName, payload.UserType)
 	if err != nil {
-		auth.log.LogError("Error while CreateAccessToken", err)
+		auth.log.With(ctx).LogError("Error while CreateAccessToken", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
@@ -215,19 +216,19 @@ func (auth *authenticationService) ForgotPassword(ctx context.Context, req *prot
 
 	err := validateForgotPasswordRequest(req)
 	if err != nil {
-		auth.log.LogError("Error while ValidateBody", err)
+		auth.log.With(ctx).LogError("Error while ValidateBody", err)
 		return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
 	}
 
 	payload := ctx.Value(utils.AuthorizationPayloadKey).(*token.SessionPayload)
-	verify, err := auth.verifyOTP(payload, req.Email, req.Phone, req.Code, utils.FORGOT_PASSWORD)
+	verify, err := auth.verifyOTP(ctx, payload, req.Email, req.Phone, req.Code, utils.FORGOT_PASSWORD)
 	if !verify {
 		return nil, err
 	}
 
-	err = auth.storage.ChangePassword(req)
+	err = auth.storage.ChangePassword(ctx, req)
 	if err != nil {
-		auth.log.LogError("Error while ChangePassword", err)
+		auth.log.With(ctx).LogError("Error while ChangePassword", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
@@ -242,7 +243,7 @@ func (auth *authenticationService) ForgotPassword(ctx context.Context, req *prot
 func (auth *authenticationService) SSOAuth(ctx context.Context, req *proto.SsoRequest) (*proto.Response, error) {
 	err := validateSsoRequest(req)
 	if err != nil {
-		auth.log.LogError("Error while ValidateBody", err)
+		auth.log.With(ctx).LogError("Error while ValidateBody", err)
 		return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
 	}
 
@@ -252,47 +253,48 @@ func (auth *authenticationService) SSOAuth(ctx context.Context, req *proto.SsoRe
 
 	switch req.Action {
 	case utils.SSO_ANDROID:
-		email, fullname, err = sso.GoogleOauth(token, auth.config.OAuth.AndroidClientId, auth.log)
+		email, fullname, err = auth.sso.Verify(token, auth.config.OAuth.AndroidClientId)
 		if err != nil {
 			if err.Error() == "missing claims" {
-				auth.log.LogError("conflict occurs, missing claims :", err)
+				auth.log.With(ctx).LogError("conflict occurs, missing claims :", err)
 				return nil, status.Errorf(codes.NotFound, "conflict occurs, missing claims")
 			}
-			auth.log.LogError("Error while GoogleOauth in sso-android", err)
+			auth.log.With(ctx).LogError("Error while GoogleOauth in sso-android", err)
 			return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 		}
 	case utils.SSO_IOS:
-		email, fullname, err = sso.GoogleOauth(token, auth.config.OAuth.IOSClientId, auth.log)
+		email, fullname, err = auth.sso.Verify(token, auth.config.OAuth.IOSClientId)
 		if err != nil {
 			if err.Error() == "missing claims" {
-				auth.log.LogError("conflict occurs, missing claims :", err)
+				auth.log.With(ctx).LogError("conflict occurs, missing claims :", err)
 				return nil, status.Errorf(codes.NotFound, "conflict occurs, missing claims")
 			}
-			auth.log.LogError("Error while GoogleOauth in sso-ios", err)
+			auth.log.With(ctx).LogError("Error while GoogleOauth in sso-ios", err)
 			return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 		}
 	default:
-		auth.log.LogError("Bad Requst", req)
+		auth.log.With(ctx).LogError("Bad Requst", req)
 		return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
 	}
 
 	//checks if the user exist or not
-	exist, err := auth.storage.CheckDataExist("email", email)
+	exist, err := auth.storage.CheckDataExist(ctx, "email", email)
 	if err != nil {
-		auth.log.LogError("Error while CheckDataExist", err)
+		auth.log.With(ctx).LogError("Error while CheckDataExist", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 	//already exist so login
 	if exist {
-		payload, err := auth.storage.GetTokenPayload("email", email)
+		payload, err := auth.storage.GetTokenPayload(ctx, "email", email)
 		if err != nil {
-			auth.log.LogError("Error while GetTokenPayload", err)
+			auth.log.With(ctx).LogError("Error while GetTokenPayload", err)
 			return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 		}
 
+		auth.mergeGuestCart(ctx, payload.UserId, payload.UserType)
 		token, err := auth.token.CreateAccessToken(payload.UserId, payload.UserName, payload.UserType)
 		if err != nil {
-			auth.log.LogError("Error while CreateAccessToken", err)
+			auth.log.With(ctx).LogError("Error while CreateAccessToken", err)
 			return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 		}
 
@@ -313,15 +315,16 @@ func (auth *authenticationService) SSOAuth(ctx context.Context, req *proto.SsoRe
 			UserType: req.GetUserType(),
 			Password: email + fullname + req.UserType,
 		}
-		id, err := auth.storage.InsertUser(signupReq)
+		id, err := auth.storage.InsertUser(ctx, signupReq)
 		if err != nil {
-			auth.log.LogError("Error while InsertUser", err)
+			auth.log.With(ctx).LogError("Error while InsertUser", err)
 			return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 		}
+		auth.mergeGuestCart(ctx, id, req.GetUserType())
 
 		token, err := auth.token.CreateAccessToken(id, fullname, req.UserType)
 		if err != nil {
-			auth.log.LogError("Error while CreateAccessToken", err)
+			auth.log.With(ctx).LogError("Error while CreateAccessToken", err)
 			return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 		}
 
@@ -337,3 +340,16 @@ func (auth *authenticationService) SSOAuth(ctx context.Context, req *proto.SsoRe
 		return response, grpc.SetHeader(ctx, mdOut)
 	}
 }
+
+// mergeGuestCart moves the cart the user filled as a guest on the device of the
+// request into its cart. A failed merge leaves the guest cart and does not fail
+// the sign in.
+func (auth *authenticationService) mergeGuestCart(ctx context.Context, userId, userType string) {
+	values := metadata.ValueFromIncomingContext(ctx, utils.DeviceIDKey)
+	if userType != utils.USER || len(values) == 0 || values[0] == "" {
+		return
+	}
+	if err := auth.storage.MergeGuestCart(ctx, values[0], userId); err != nil {
+		auth.log.With(ctx).LogWarn("Error while MergeGuestCart", err)
+	}
+}
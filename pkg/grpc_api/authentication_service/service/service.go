@@ -9,7 +9,8 @@ import (
 	"github.com/akmal4410/gestapo/pkg/helpers/token"
 	"github.com/akmal4410/gestapo/pkg/service/cache"
 	"github.com/akmal4410/gestapo/pkg/service/mail"
-	s3 "github.com/akmal4410/gestapo/pkg/service/s3_service"
+	objectstore "github.com/akmal4410/gestapo/pkg/service/object_store"
+	"github.com/akmal4410/gestapo/pkg/service/sso"
 	"github.com/akmal4410/gestapo/pkg/service/twilio"
 )
 
@@ -18,40 +19,57 @@ type authenticationService struct {
 	proto.UnimplementedAuthenticationServiceServer
 	config        *config.Config
 	log           logger.Logger
-	s3            *s3.S3Service
+	files         objectstore.ObjectStore
 	twilioService twilio.TwilioService
 	emailService  mail.EmailService
-	storage       *db.AuthStore
+	storage       db.AuthRepository
 	token         token.Maker
 	redis         cache.Cache
+	sso           sso.Verifier
+}
+
+// Dependencies are the stores and the external clients used by the authentication service.
+type Dependencies struct {
+	Store  db.AuthRepository
+	Files  objectstore.ObjectStore
+	Twilio twilio.TwilioService
+	Email  mail.EmailService
+	Cache  cache.Cache
+	SSO    sso.Verifier
 }
 
 // NewAuthenticationService creates a new gRPC server.
 func NewAuthenticationService(storage *database.Storage, config *config.Config, log logger.Logger, tokenMaker token.Maker) *authenticationService {
-	server := &authenticationService{
-		config: config,
-		log:    log,
-		token:  tokenMaker,
+	redis, err := cache.NewRedisCache(config.Redis)
+	if err != nil {
+		log.LogFatal("Error while Initializing NewRedisCache ", err)
 	}
-	s3 := s3.NewS3Service(
-		config.AwsS3.BucketName,
-		config.AwsS3.Region,
-		config.AwsS3.AccessKey,
-		config.AwsS3.SecretKey,
-	)
-	twilio := twilio.NewOTPService(server.config.Twilio)
-	email := mail.NewGmailService(server.config.Email)
-	authStore := db.NewAuthStore(storage)
-
-	redis, err := cache.NewRedisCache(server.config.Redis)
+	files, err := objectstore.New(config)
 	if err != nil {
-		server.log.LogFatal("Error while Initializing NewRedisCache ", err)
+		log.LogFatal("Error while Initializing object store ", err)
 	}
+	deps := Dependencies{
+		Store:  db.NewAuthStore(storage),
+		Files:  files,
+		Twilio: twilio.NewOTPService(config.Twilio),
+		Email:  mail.NewGmailService(config.Email),
+		Cache:  redis,
+		SSO:    sso.NewGoogleVerifier(log),
+	}
+	return NewAuthenticationServiceWith(deps, config, log, tokenMaker)
+}
 
-	server.twilioService = twilio
-	server.s3 = s3
-	server.emailService = email
-	server.storage = authStore
-	server.redis = redis
-	return server
+// NewAuthenticationServiceWith creates a new gRPC server working with deps.
+func NewAuthenticationServiceWith(deps Dependencies, config *config.Config, log logger.Logger, tokenMaker token.Maker) *authenticationService {
+	return &authenticationService{
+		config:        config,
+		log:           log,
+		token:         tokenMaker,
+		files:         deps.Files,
+		twilioService: deps.Twilio,
+		emailService:  deps.Email,
+		storage:       deps.Store,
+		redis:         deps.Cache,
+		sso:           deps.SSO,
+	}
 }
@@ -2,17 +2,21 @@ package grpc_gateway
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/akmal4410/gestapo/internal/config"
 	"github.com/akmal4410/gestapo/pkg/api/proto"
 	"github.com/akmal4410/gestapo/pkg/helpers/logger"
+	"github.com/akmal4410/gestapo/pkg/service/images"
+	"github.com/akmal4410/gestapo/pkg/utils"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/encoding/protojson"
 )
 
-func newGateway(ctx context.Context, log logger.Logger, config config.Config, opts ...runtime.ServeMuxOption) (*runtime.ServeMux, error) {
+func newGateway(ctx context.Context, log logger.Logger, config config.Config, dialOpts ...grpc.DialOption) (*runtime.ServeMux, error) {
 	muxOption := runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
 		MarshalOptions: protojson.MarshalOptions{
 			UseProtoNames: true,
@@ -21,8 +25,20 @@ func newGateway(ctx context.Context, log logger.Logger, config config.Config, op
 			DiscardUnknown: true,
 		},
 	})
-	gMux := runtime.NewServeMux(muxOption)
-	dialOpts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	// forwards the request ID set by RequestIDMiddleware, the image format asked by the client
+	// and the device of the guests to the gRPC services
+	metadataOption := runtime.WithMetadata(func(ctx context.Context, r *http.Request) metadata.MD {
+		md := metadata.Pairs(
+			logger.RequestIDHeader, r.Header.Get(logger.RequestIDHeader),
+			images.FormatHeader, r.Header.Get(images.FormatHeader),
+		)
+		if deviceID := r.Header.Get(utils.DeviceIDKey); deviceID != "" {
+			md.Set(utils.DeviceIDKey, deviceID)
+		}
+		return md
+	})
+	gMux := runtime.NewServeMux(muxOption, metadataOption)
+	dialOpts = append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, dialOpts...)
 	//---------------Registering endpoints---------------------
 	errAuthentication := registerAuthServiceEndPoints(ctx, log, config, gMux, dialOpts)
 	if errAuthentication != nil {
@@ -30,7 +46,7 @@ func newGateway(ctx context.Context, log logger.Logger, config config.Config, op
 	}
 
 	errAdmin := registerAdminServiceEndPoints(ctx, log, config, gMux, dialOpts)
-	if errAuthentication != nil {
+	if errAdmin != nil {
 		return nil, errAdmin
 	}
 
@@ -0,0 +1,450 @@
+package grpc_gateway_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akmal4410/gestapo/internal/database/memory"
+	"github.com/akmal4410/gestapo/internal/testenv"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/grpc_gateway"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/grpc_gateway/server"
+	merchant_db "github.com/akmal4410/gestapo/pkg/grpc_api/merchant_service/db"
+	"github.com/akmal4410/gestapo/pkg/helpers/logger"
+	"github.com/akmal4410/gestapo/pkg/service/images"
+	objectstore "github.com/akmal4410/gestapo/pkg/service/object_store"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+func TestGatewayProxiesServices(t *testing.T) {
+	env := testenv.New(t)
+	handler := env.Gateway(t)
+	env.AddUser(t, "login_user", utils.USER)
+	_, adminToken := env.AddUser(t, "admin", utils.ADMIN)
+	env.AddCategory(t, "Shoes")
+
+	body := strings.NewReader(`{"user_name": "login_user", "password": "` + testenv.Password + `"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/login", body)
+	req.Header.Set(logger.RequestIDHeader, "request-1")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("login: expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if rec.Header().Get("Grpc-Metadata-Access-Token") == "" {
+		t.Fatalf("login: missing access token header in %v", rec.Header())
+	}
+	if rec.Header().Get(logger.RequestIDHeader) != "request-1" {
+		t.Fatalf("login: expected the request id to be echoed, got %v", rec.Header())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/admin/category", nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("categories without token: expected 401, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/admin/category", nil)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("categories: expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var categories struct {
+		Data []struct {
+			Category string `json:"category"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&categories); err != nil {
+		t.Fatalf("decode categories: %v", err)
+	}
+	if len(categories.Data) != 1 || categories.Data[0].Category != "Shoes" {
+		t.Fatalf("unexpected categories %+v", categories)
+	}
+}
+
+func TestGatewayGuestViews(t *testing.T) {
+	env := testenv.New(t)
+	handler := env.Gateway(t)
+	merchant, _ := env.AddUser(t, "merchant", utils.MERCHANT)
+	category := env.AddCategory(t, "Shoes")
+	product := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5, 8)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/product/"+product.ID, nil)
+	req.Header.Set(utils.DeviceIDKey, "device-1")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("product: expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/products/recently-viewed", nil)
+	req.Header.Set(utils.DeviceIDKey, "device-1")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("recently viewed: expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var viewed struct {
+		Data []struct {
+			Product struct {
+				ID string `json:"id"`
+			} `json:"product"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&viewed); err != nil {
+		t.Fatalf("decode recently viewed: %v", err)
+	}
+	if len(viewed.Data) != 1 || viewed.Data[0].Product.ID != product.ID {
+		t.Fatalf("unexpected recently viewed %+v", viewed)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/products/recently-viewed", nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("recently viewed without device: expected 401, got %d", rec.Code)
+	}
+}
+
+func TestGatewayInsertProduct(t *testing.T) {
+	env := testenv.New(t)
+	handler := env.Gateway(t)
+	merchant, merchantToken := env.AddUser(t, "merchant", utils.MERCHANT)
+	_, userToken := env.AddUser(t, "user", utils.USER)
+	category := env.AddCategory(t, "Shoes")
+
+	var pngImage bytes.Buffer
+	png.Encode(&pngImage, image.NewNRGBA(image.Rect(0, 0, 4, 4)))
+	newRequest := func(accessToken string, content []byte) *http.Request {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		form.WriteField("data", `{"product_name": "runner", "description": "light", "sizes": [8, 9], "price": 100, "category_id": "`+category.ID+`", "quantity": 5}`)
+		file, _ := form.CreateFormFile("files", "runner.png")
+		file.Write(content)
+		form.Close()
+		req := httptest.NewRequest(http.MethodPost, "/api/merchant/product", &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		return req
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, newRequest(userToken, pngImage.Bytes()))
+	if rec.Code == http.StatusOK {
+		t.Fatal("expected a user to be refused")
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, newRequest(merchantToken, []byte("image")))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected a file that is not an image to be refused, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, newRequest(merchantToken, pngImage.Bytes()))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+
+	env.DB.Lock()
+	defer env.DB.Unlock()
+	if len(env.DB.Products) != 1 || len(env.DB.Inventories) != 2 {
+		t.Fatalf("expected one product with two sizes, got %d products and %d inventories", len(env.DB.Products), len(env.DB.Inventories))
+	}
+	for _, product := range env.DB.Products {
+		if product.MerchantID != merchant.ID || len(product.Images) != 1 {
+			t.Fatalf("unexpected product %+v", product)
+		}
+		for _, key := range images.Keys(product.Images[0]) {
+			if _, ok := env.Files.GetObject(key); !ok {
+				t.Fatalf("the rendition %s was not stored", key)
+			}
+		}
+	}
+}
+
+func TestGatewayInsertProductVariants(t *testing.T) {
+	env := testenv.New(t)
+	handler := env.Gateway(t)
+	_, merchantToken := env.AddUser(t, "merchant", utils.MERCHANT)
+	category := env.AddCategory(t, "Shoes")
+
+	var pngImage bytes.Buffer
+	png.Encode(&pngImage, image.NewNRGBA(image.Rect(0, 0, 4, 4)))
+	insert := func(variants string) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		form.WriteField("data", `{"product_name": "runner", "description": "light", "price": 100, "category_id": "`+category.ID+`",
+			"option_types": ["color", "size"], "variants": `+variants+`}`)
+		for _, name := range []string{"runner.png", "red.png"} {
+			file, _ := form.CreateFormFile("files", name)
+			file.Write(pngImage.Bytes())
+		}
+		form.Close()
+		req := httptest.NewRequest(http.MethodPost, "/api/merchant/product", &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer "+merchantToken)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for name, variants := range map[string]string{
+		"missing option":  `[{"sku": "RUN-RED-8", "options": {"color": "red"}, "quantity": 5}]`,
+		"unknown option":  `[{"sku": "RUN-RED-8", "options": {"color": "red", "width": "wide"}, "quantity": 5}]`,
+		"repeated sku":    `[{"sku": "RUN-8", "options": {"color": "red", "size": "8"}}, {"sku": "RUN-8", "options": {"color": "blue", "size": "8"}}]`,
+		"repeated option": `[{"sku": "RUN-RED-8", "options": {"color": "red", "size": "8"}}, {"sku": "RUN-RED-8B", "options": {"color": "red", "size": "8"}}]`,
+		"missing image":   `[{"sku": "RUN-RED-8", "options": {"color": "red", "size": "8"}, "image_indexes": [2]}]`,
+		"invalid size":    `[{"sku": "RUN-RED-8", "options": {"color": "red", "size": "eight"}}]`,
+	} {
+		if rec := insert(variants); rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d: %s", name, rec.Code, rec.Body)
+		}
+	}
+
+	variants := `[
+		{"sku": "RUN-RED-8", "options": {"color": "red", "size": "8"}, "price": 120, "quantity": 3, "image_indexes": [1]},
+		{"sku": "RUN-RED-9", "options": {"color": "red", "size": "9"}, "price": 120, "quantity": 0, "image_indexes": [1]},
+		{"sku": "RUN-BLUE-8", "options": {"color": "blue", "size": "8"}, "quantity": 7}
+	]`
+	if rec := insert(variants); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if rec := insert(`[{"sku": "RUN-RED-8", "options": {"color": "green", "size": "8"}, "quantity": 1}]`); rec.Code != http.StatusConflict {
+		t.Fatalf("expected a used sku to conflict, got %d: %s", rec.Code, rec.Body)
+	}
+
+	env.DB.Lock()
+	defer env.DB.Unlock()
+	if len(env.DB.Products) != 1 || len(env.DB.Inventories) != 3 {
+		t.Fatalf("expected one product with three variants, got %d products and %d inventories", len(env.DB.Products), len(env.DB.Inventories))
+	}
+	for _, product := range env.DB.Products {
+		if len(product.Images) != 2 || len(product.Size) != 2 || product.Size[0] != 8 || product.Size[1] != 9 {
+			t.Fatalf("unexpected product %+v", product)
+		}
+		if len(product.OptionTypes) != 2 || product.OptionTypes[0] != "color" || product.OptionTypes[1] != "size" {
+			t.Fatalf("unexpected option types %v", product.OptionTypes)
+		}
+		for _, variant := range env.DB.Inventories {
+			switch *variant.SKU {
+			case "RUN-RED-8":
+				if *variant.Price != 120 || variant.Size != 8 || variant.Quantity != 3 || len(variant.Images) != 1 || variant.Images[0] != product.Images[1] {
+					t.Fatalf("unexpected variant %+v", variant)
+				}
+			case "RUN-BLUE-8":
+				if variant.Price != nil || variant.Options["color"] != "blue" || variant.Quantity != 7 || len(variant.Images) != 0 {
+					t.Fatalf("unexpected variant %+v", variant)
+				}
+			}
+		}
+	}
+}
+
+func TestGatewayInsertProductAttributes(t *testing.T) {
+	env := testenv.New(t)
+	handler := env.Gateway(t)
+	_, merchantToken := env.AddUser(t, "merchant", utils.MERCHANT)
+	men := env.AddCategory(t, "Men")
+	shoes := env.AddSubcategory(t, men, "Shoes")
+	env.DB.Lock()
+	men.Attributes = []memory.CategoryAttribute{{Name: "material", Type: utils.AttributeText, Required: true}}
+	shoes.Attributes = []memory.CategoryAttribute{
+		{Name: "fit", Type: utils.AttributeEnum, Values: []string{"narrow", "wide"}},
+		{Name: "waterproof", Type: utils.AttributeBoolean},
+	}
+	env.DB.Unlock()
+
+	var pngImage bytes.Buffer
+	png.Encode(&pngImage, image.NewNRGBA(image.Rect(0, 0, 4, 4)))
+	insert := func(categoryId, attributes string) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		form.WriteField("data", `{"product_name": "runner", "description": "light", "sizes": [8], "price": 100, "category_id": "`+categoryId+`",
+			"quantity": 5, "attributes": `+attributes+`}`)
+		file, _ := form.CreateFormFile("files", "runner.png")
+		file.Write(pngImage.Bytes())
+		form.Close()
+		req := httptest.NewRequest(http.MethodPost, "/api/merchant/product", &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer "+merchantToken)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for name, attributes := range map[string]string{
+		"missing required": `{"fit": "wide"}`,
+		"unknown":          `{"material": "mesh", "colour": "red"}`,
+		"invalid enum":     `{"material": "mesh", "fit": "loose"}`,
+		"invalid boolean":  `{"material": "mesh", "waterproof": "maybe"}`,
+	} {
+		if rec := insert(shoes.ID, attributes); rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d: %s", name, rec.Code, rec.Body)
+		}
+	}
+	if rec := insert(uuid.NewString(), `{}`); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected an unknown category to be refused, got %d: %s", rec.Code, rec.Body)
+	}
+	if rec := insert(shoes.ID, `{"material": "mesh", "fit": "wide", "waterproof": "true"}`); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+
+	env.DB.Lock()
+	shoes.ArchivedAt = &shoes.CreatedAt
+	env.DB.Unlock()
+	if rec := insert(shoes.ID, `{"material": "mesh"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected an archived category to be refused, got %d: %s", rec.Code, rec.Body)
+	}
+
+	env.DB.Lock()
+	defer env.DB.Unlock()
+	if len(env.DB.Products) != 1 {
+		t.Fatalf("expected one product, got %d", len(env.DB.Products))
+	}
+	for _, product := range env.DB.Products {
+		if len(product.Attributes) != 3 || product.Attributes["fit"] != "wide" {
+			t.Fatalf("unexpected attributes %v", product.Attributes)
+		}
+	}
+}
+
+func TestGatewaySetCategoryImage(t *testing.T) {
+	env := testenv.New(t)
+	handler := env.Gateway(t)
+	_, adminToken := env.AddUser(t, "admin", utils.ADMIN)
+	_, otherAdminToken := env.AddUser(t, "other_admin", utils.ADMIN)
+	_, merchantToken := env.AddUser(t, "merchant", utils.MERCHANT)
+	category := env.AddCategory(t, "Shoes")
+	other := env.AddCategory(t, "Bags")
+
+	var pngImage bytes.Buffer
+	png.Encode(&pngImage, image.NewNRGBA(image.Rect(0, 0, 4, 4)))
+	serve := func(method, target, accessToken, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+	// createUpload creates an upload session of the image of the category and returns its id and key
+	createUpload := func(categoryId string) (string, string) {
+		rec := serve(http.MethodPost, "/api/merchant/upload", adminToken,
+			`{"purpose": "CATEGORY_IMAGE", "category_id": "`+categoryId+`", "content_type": "image/png", "size": `+strconv.Itoa(pngImage.Len())+`}`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("upload session: expected 200, got %d: %s", rec.Code, rec.Body)
+		}
+		var res struct {
+			Data struct {
+				Id  string `json:"id"`
+				Key string `json:"key"`
+			} `json:"data"`
+		}
+		json.Unmarshal(rec.Body.Bytes(), &res)
+		return res.Data.Id, res.Data.Key
+	}
+	setImage := func(accessToken, categoryId, uploadId string) *httptest.ResponseRecorder {
+		return serve(http.MethodPut, "/api/admin/category/"+categoryId+"/image", accessToken, `{"upload_id": "`+uploadId+`"}`)
+	}
+
+	uploadId, key := createUpload(category.ID)
+	if rec := setImage(adminToken, category.ID, uploadId); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected a missing file to be refused, got %d: %s", rec.Code, rec.Body)
+	}
+	// the client uploads to the presigned URL
+	if err := env.Files.Put(context.Background(), key, bytes.NewReader(pngImage.Bytes()), "image/png"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if rec := setImage(merchantToken, category.ID, uploadId); rec.Code == http.StatusOK {
+		t.Fatal("expected a merchant to be refused")
+	}
+	if rec := setImage(otherAdminToken, category.ID, uploadId); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected the upload of another admin to be refused, got %d: %s", rec.Code, rec.Body)
+	}
+	if rec := setImage(adminToken, other.ID, uploadId); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected the upload of another category to be refused, got %d: %s", rec.Code, rec.Body)
+	}
+	if rec := setImage(adminToken, category.ID, uuid.NewString()); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d: %s", rec.Code, rec.Body)
+	}
+	if rec := setImage(adminToken, category.ID, uploadId); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if rec := setImage(adminToken, category.ID, uploadId); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected a completed upload to be refused, got %d: %s", rec.Code, rec.Body)
+	}
+	env.DB.Lock()
+	first := *category.Image
+	env.DB.Unlock()
+	if first != images.BaseKey(key) {
+		t.Fatalf("expected the image %s, got %s", images.BaseKey(key), first)
+	}
+
+	uploadId, key = createUpload(category.ID)
+	if err := env.Files.Put(context.Background(), key, bytes.NewReader(pngImage.Bytes()), "image/png"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if rec := setImage(adminToken, category.ID, uploadId); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+
+	env.DB.Lock()
+	defer env.DB.Unlock()
+	if *category.Image == first || !strings.HasPrefix(*category.Image, "categories/"+category.ID+"/") {
+		t.Fatalf("unexpected image %s", *category.Image)
+	}
+	if _, ok := env.Files.GetObject(images.Keys(first)[0]); ok {
+		t.Fatal("expected the replaced image to be deleted")
+	}
+	for _, key := range images.Keys(*category.Image) {
+		if _, ok := env.Files.GetObject(key); !ok {
+			t.Fatalf("the rendition %s was not stored", key)
+		}
+	}
+}
+
+func TestGatewayServesLocalFiles(t *testing.T) {
+	env := testenv.New(t)
+	files, err := objectstore.NewLocalStore(t.TempDir(), "http://gateway", "secret", time.Minute)
+	if err != nil {
+		t.Fatalf("NewLocalStore: %v", err)
+	}
+	restServer := server.NewRestServerWith(merchant_db.NewMemoryMerchantStore(env.DB), files, env.Log, env.Token)
+	handler, err := grpc_gateway.NewHandler(context.Background(), env.Log, *env.Config, restServer, env.DialOptions()...)
+	if err != nil {
+		t.Fatalf("NewHandler: %v", err)
+	}
+
+	if err := files.Put(context.Background(), "profile/merchant/me.png", strings.NewReader("image"), "image/png"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	signedURL, err := files.PresignGet(context.Background(), "profile/merchant/me.png")
+	if err != nil {
+		t.Fatalf("PresignGet: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, signedURL, nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "image" {
+		t.Fatalf("file: %d %s", rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/files/profile/merchant/me.png", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("unsigned file: expected 403, got %d", rec.Code)
+	}
+}
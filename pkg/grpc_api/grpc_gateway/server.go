@@ -7,9 +7,13 @@ import (
 	"github.com/akmal4410/gestapo/internal/config"
 	"github.com/akmal4410/gestapo/internal/database"
 	"github.com/akmal4410/gestapo/pkg/grpc_api/grpc_gateway/server"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/grpc_gateway/server/middleware"
 	"github.com/akmal4410/gestapo/pkg/helpers/logger"
 	"github.com/akmal4410/gestapo/pkg/helpers/token"
+	"github.com/akmal4410/gestapo/pkg/service/images"
+	"github.com/akmal4410/gestapo/pkg/utils"
 	"github.com/gorilla/handlers"
+	"google.golang.org/grpc"
 )
 
 const (
@@ -31,33 +35,42 @@ func RunGateway() error {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	gMux, err := newGateway(ctx, log, config)
-	if err != nil {
-		log.LogError("error in newGateway :", err)
-		return err
-	}
-	mux := http.NewServeMux()
-	mux.Handle("/api/", http.StripPrefix("/api", gMux))
-
-	//-----------------ONlY FOR REST API(Image handling)--------------------------
 	tokenMaker, err := token.NewJWTMaker(config.TokenSymmetricKey)
 	if err != nil {
 		log.LogFatal("Error while Initializing NewJWTMaker %w", err)
 	}
-	store, err := database.NewStorage(config.Database)
+	store, err := database.NewStorage(config.Database, logFileName)
 	if err != nil {
 		log.LogFatal("Cannot connect to Database", err)
 	}
 	log.LogInfo("Database connection successful")
-	server := server.NewRestServer(store, &config, log, tokenMaker)
-	server.SetupRouter(mux)
+	restServer := server.NewRestServer(store, &config, log, tokenMaker)
+
+	handler, err := NewHandler(ctx, log, config, restServer)
+	if err != nil {
+		log.LogError("error in newGateway :", err)
+		return err
+	}
+	return http.ListenAndServe(":"+config.ServerAddress.Gateway, handler)
+}
+
+// NewHandler returns the http handler of the gateway, serving the gRPC services
+// dialed with dialOpts under /api/ next to the REST endpoints of restServer.
+func NewHandler(ctx context.Context, log logger.Logger, config config.Config, restServer *server.RestServer, dialOpts ...grpc.DialOption) (http.Handler, error) {
+	gMux, err := newGateway(ctx, log, config, dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/api/", http.StripPrefix("/api", gMux))
+
+	//-----------------ONlY FOR REST API(Image handling)--------------------------
+	restServer.SetupRouter(mux)
 	//------------------------------------------------------------------------------
 
-	return http.ListenAndServe(":"+config.ServerAddress.Gateway,
-		handlers.CORS(handlers.AllowedHeaders([]string{"X-Requested-With", "Content-Type", "Authorization", "User-Agent"}),
-			handlers.ExposedHeaders([]string{"*"}),
-			handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE"}),
-			handlers.AllowedOrigins([]string{"*"}),
-		)(mux),
-	)
+	return handlers.CORS(handlers.AllowedHeaders([]string{"X-Requested-With", "Content-Type", "Authorization", "User-Agent", "X-Request-Id", images.FormatHeader, utils.DeviceIDKey}),
+		handlers.ExposedHeaders([]string{"*"}),
+		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE"}),
+		handlers.AllowedOrigins([]string{"*"}),
+	)(middleware.RequestIDMiddleware(log, mux)), nil
 }
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/grpc_api/merchant_service/db"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/merchant_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/helpers"
+	"github.com/akmal4410/gestapo/pkg/helpers/token"
+	"github.com/akmal4410/gestapo/pkg/service/images"
+	objectstore "github.com/akmal4410/gestapo/pkg/service/object_store"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+// SetCategoryImage attaches the image uploaded with a CATEGORY_IMAGE upload session of the
+// merchant service to a category, the image it replaces is deleted. Until it is attached,
+// the upload is deleted by the upload collector once its session expires.
+func (handler *RestServer) SetCategoryImage(w http.ResponseWriter, r *http.Request) {
+	categoryId := r.PathValue("id")
+	if _, err := uuid.Parse(categoryId); err != nil {
+		handler.log.With(r.Context()).LogError("Invalid category id", err)
+		helpers.ErrorJson(w, http.StatusBadRequest, utils.InvalidRequest)
+		return
+	}
+
+	req := new(entity.SetCategoryImageReq)
+	err := helpers.ValidateBody(r.Body, req)
+	if err != nil {
+		handler.log.With(r.Context()).LogError("Error while ValidateBody", err)
+		helpers.ErrorJson(w, http.StatusBadRequest, utils.InvalidRequest)
+		return
+	}
+
+	payload := r.Context().Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
+	session, err := handler.storage.GetUploadSession(r.Context(), req.UploadID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			handler.log.With(r.Context()).LogError("Error while GetUploadSession Not found", err)
+			helpers.ErrorJson(w, http.StatusNotFound, "Upload session not found")
+			return
+		}
+		handler.log.With(r.Context()).LogError("Error while GetUploadSession", err)
+		helpers.ErrorJson(w, http.StatusInternalServerError, utils.InternalServerError)
+		return
+	}
+	if session.UserID != payload.UserID {
+		handler.log.With(r.Context()).LogError("unauthorized: upload session does not belong to the user")
+		helpers.ErrorJson(w, http.StatusForbidden, "upload session does not belong to the user")
+		return
+	}
+	if session.Purpose != entity.CategoryImage || session.TargetID == nil || *session.TargetID != categoryId {
+		helpers.ErrorJson(w, http.StatusBadRequest, "upload session is not an image of the category")
+		return
+	}
+	if session.Status != entity.UploadPending {
+		helpers.ErrorJson(w, http.StatusBadRequest, "upload session is already completed")
+		return
+	}
+	if time.Now().After(session.ExpiresAt) {
+		helpers.ErrorJson(w, http.StatusBadRequest, "upload session is expired")
+		return
+	}
+
+	image, code, err := handler.processUpload(r.Context(), session)
+	if err != nil {
+		helpers.ErrorJson(w, code, err.Error())
+		return
+	}
+
+	previous, err := handler.storage.AttachCategoryImage(r.Context(), session.ID, categoryId, image)
+	if err != nil {
+		if errors.Is(err, db.ErrUploadNotPending) {
+			helpers.ErrorJson(w, http.StatusBadRequest, "upload session is already completed")
+			return
+		}
+		if err == sql.ErrNoRows {
+			handler.log.With(r.Context()).LogError("Error while AttachCategoryImage Not found", err)
+			helpers.ErrorJson(w, http.StatusNotFound, "Category not found")
+			return
+		}
+		handler.log.With(r.Context()).LogError("Error while AttachCategoryImage", err)
+		helpers.ErrorJson(w, http.StatusInternalServerError, utils.InternalServerError)
+		return
+	}
+	if previous != nil {
+		if err := images.Delete(r.Context(), handler.files, *previous); err != nil {
+			handler.log.With(r.Context()).LogWarn("Error deleting the replaced image", *previous, err)
+		}
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, "Category image updated successfully")
+}
+
+// processUpload verifies the uploaded image and replaces it by its renditions, so the stored
+// image has no EXIF or GPS metadata. It returns the HTTP status and the message of a failure.
+func (handler *RestServer) processUpload(ctx context.Context, session *entity.UploadSession) (string, int, error) {
+	err := objectstore.Verify(ctx, handler.files, session.Key, session.ContentType, session.Size)
+	if err != nil {
+		if errors.Is(err, objectstore.ErrNotFound) {
+			return "", http.StatusBadRequest, errors.New("the file is not uploaded yet")
+		}
+		if errors.Is(err, objectstore.ErrInvalidObject) {
+			handler.log.With(ctx).LogError("Invalid upload", session.Key, err)
+			handler.discardUpload(ctx, session)
+			return "", http.StatusBadRequest, errors.New("the uploaded file is not the declared image")
+		}
+		handler.log.With(ctx).LogError("Error while Verify", err)
+		return "", http.StatusInternalServerError, errors.New(utils.InternalServerError)
+	}
+
+	image, err := images.Process(ctx, handler.files, session.Key)
+	if err != nil {
+		if errors.Is(err, images.ErrUnsupportedImage) {
+			handler.log.With(ctx).LogError("Invalid image", session.Key, err)
+			handler.discardUpload(ctx, session)
+			return "", http.StatusBadRequest, errors.New("the uploaded file is not a valid image")
+		}
+		handler.log.With(ctx).LogError("Error while Process", err)
+		return "", http.StatusInternalServerError, errors.New(utils.InternalServerError)
+	}
+	return image, 0, nil
+}
+
+// discardUpload deletes an upload that can never be attached, its session is
+// deleted by the upload collector once it expires.
+func (handler *RestServer) discardUpload(ctx context.Context, session *entity.UploadSession) {
+	if err := handler.files.Delete(ctx, session.Key); err != nil {
+		handler.log.With(ctx).LogWarn("Error deleting the invalid upload", session.Key, err)
+	}
+}
@@ -10,9 +10,11 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/akmal4410/gestapo/pkg/grpc_api/merchant_service/db"
 	"github.com/akmal4410/gestapo/pkg/grpc_api/merchant_service/db/entity"
 	"github.com/akmal4410/gestapo/pkg/helpers"
 	"github.com/akmal4410/gestapo/pkg/helpers/token"
+	"github.com/akmal4410/gestapo/pkg/service/images"
 	"github.com/akmal4410/gestapo/pkg/utils"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
@@ -31,14 +33,14 @@ func (handler *RestServer) EditProfile(w http.ResponseWriter, r *http.Request) {
 	req := new(entity.EditMerchantReq)
 	err := helpers.ValidateBody(reader, req)
 	if err != nil {
-		handler.log.LogError("Error while ValidateBody", err)
+		handler.log.With(r.Context()).LogError("Error while ValidateBody", err)
 		helpers.ErrorJson(w, http.StatusBadRequest, utils.InvalidRequest)
 		return
 	}
 
 	err = r.ParseMultipartForm(thirtyTwoMB)
 	if err != nil {
-		handler.log.LogError("Unable to parse form", err.Error())
+		handler.log.With(r.Context()).LogError("Unable to parse form", err.Error())
 		helpers.ErrorJson(w, http.StatusBadRequest, utils.InvalidRequest)
 		return
 	}
@@ -47,7 +49,7 @@ func (handler *RestServer) EditProfile(w http.ResponseWriter, r *http.Request) {
 
 	files := r.MultipartForm.File["files"]
 	if len(files) > maxFileCount {
-		handler.log.LogError("Too many files uploaded", "Max allowed: %d", maxFileCount)
+		handler.log.With(r.Context()).LogError("Too many files uploaded", "Max allowed: %d", maxFileCount)
 		errMsg := fmt.Sprintf("too many files uploaded. Max allowed: %s", strconv.Itoa(maxFileCount))
 		helpers.ErrorJson(w, http.StatusBadRequest, errMsg)
 		return
@@ -57,30 +59,36 @@ func (handler *RestServer) EditProfile(w http.ResponseWriter, r *http.Request) {
 	for _, fileHeader := range files {
 		file, err := fileHeader.Open()
 		if err != nil {
-			handler.log.LogError("Unable to open file", err)
+			handler.log.With(r.Context()).LogError("Unable to open file", err)
 			helpers.ErrorJson(w, http.StatusInternalServerError, "Unable to open file")
 			return
 		}
 		defer file.Close()
 
 		folderPath := "profile/" + payload.UserID + "/"
-		fileURL, err := handler.s3.UploadFileToS3(file, folderPath, fileHeader.Filename)
+		imageKey := folderPath + uuid.NewString()
+		err = images.Save(r.Context(), handler.files, file, imageKey)
 		if err != nil {
-			handler.log.LogError("Error uploading file to S3", err)
+			if errors.Is(err, images.ErrUnsupportedImage) {
+				handler.log.With(r.Context()).LogError("Invalid image", fileHeader.Filename, err)
+				helpers.ErrorJson(w, http.StatusBadRequest, "only jpeg, png and webp images can be uploaded")
+				return
+			}
+			handler.log.With(r.Context()).LogError("Error uploading file to S3", err)
 			helpers.ErrorJson(w, http.StatusInternalServerError, "Error uploading file to S3")
 			return
 		}
 
-		handler.log.LogInfo("File uploaded to S3 successfully", "FileURL:", fileURL)
-		uploadedFileKeys = append(uploadedFileKeys, fileURL)
+		handler.log.With(r.Context()).LogInfo("File uploaded to S3 successfully", "Key:", imageKey)
+		uploadedFileKeys = append(uploadedFileKeys, imageKey)
 	}
 
 	if len(uploadedFileKeys) != 0 {
 		req.ProfileImage = uploadedFileKeys[0]
 	}
-	err = handler.storage.UpdateProfile(payload.UserID, req)
+	err = handler.storage.UpdateProfile(r.Context(), payload.UserID, req)
 	if err != nil {
-		handler.log.LogError("Error while UpdateProfile", err)
+		handler.log.With(r.Context()).LogError("Error while UpdateProfile", err)
 		helpers.ErrorJson(w, http.StatusInternalServerError, utils.InternalServerError)
 		return
 	}
@@ -100,55 +108,77 @@ func (handler *RestServer) InsertProduct(w http.ResponseWriter, r *http.Request)
 	req := new(entity.AddProductReq)
 	err := helpers.ValidateBody(reader, req)
 	if err != nil {
-		handler.log.LogError("Error while ValidateBody", err)
+		handler.log.With(r.Context()).LogError("Error while ValidateBody", err)
 		helpers.ErrorJson(w, http.StatusBadRequest, utils.InvalidRequest)
 		return
 	}
 
-	res, err := handler.storage.CheckDataExist("categories", "id", req.CategoryId)
+	if _, err := uuid.Parse(req.CategoryId); err != nil {
+		handler.log.With(r.Context()).LogError("Invalid category id", err)
+		helpers.ErrorJson(w, http.StatusBadRequest, utils.InvalidRequest)
+		return
+	}
+	category, err := handler.storage.GetProductCategory(r.Context(), req.CategoryId)
 	if err != nil {
-		handler.log.LogError("Error while CheckCategoryExist", err)
+		if err == sql.ErrNoRows {
+			err = fmt.Errorf("category doesnt exist: %s", req.CategoryId)
+			handler.log.With(r.Context()).LogError("Error ", err)
+			helpers.ErrorJson(w, http.StatusNotFound, err.Error())
+			return
+		}
+		handler.log.With(r.Context()).LogError("Error while GetProductCategory", err)
 		helpers.ErrorJson(w, http.StatusInternalServerError, utils.InternalServerError)
 		return
 	}
-	if !res {
-		err = fmt.Errorf("category doesnt exist: %s", req.CategoryId)
-		handler.log.LogError("Error ", err)
-		helpers.ErrorJson(w, http.StatusNotFound, err.Error())
+	if category.ArchivedAt != nil {
+		handler.log.With(r.Context()).LogError("Category is archived", req.CategoryId)
+		helpers.ErrorJson(w, http.StatusBadRequest, "products cannot be added to an archived category")
+		return
+	}
+	err = category.ValidateAttributes(req.Attributes)
+	if err != nil {
+		handler.log.With(r.Context()).LogError("Invalid attributes", err)
+		helpers.ErrorJson(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	err = r.ParseMultipartForm(thirtyTwoMB)
 	if err != nil {
-		handler.log.LogError("Unable to parse form", err.Error())
+		handler.log.With(r.Context()).LogError("Unable to parse form", err.Error())
 		helpers.ErrorJson(w, http.StatusBadRequest, utils.InvalidRequest)
 		return
 	}
 
 	files := r.MultipartForm.File["files"]
 	if len(files) == 0 {
-		handler.log.LogError("There should be atleast one image")
+		handler.log.With(r.Context()).LogError("There should be atleast one image")
 		errMsg := "There should be atleast one image"
 		helpers.ErrorJson(w, http.StatusBadRequest, errMsg)
 		return
 	}
 	if len(files) > maxFileCount {
-		handler.log.LogError("Too many files uploaded", "Max allowed: %d", maxFileCount)
+		handler.log.With(r.Context()).LogError("Too many files uploaded", "Max allowed: %d", maxFileCount)
 		errMsg := fmt.Sprintf("too many files uploaded. Max allowed: %s", strconv.Itoa(maxFileCount))
 		helpers.ErrorJson(w, http.StatusBadRequest, errMsg)
 		return
 	}
+	err = req.NormalizeVariants(len(files))
+	if err != nil {
+		handler.log.With(r.Context()).LogError("Invalid variants", err)
+		helpers.ErrorJson(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	payload, ok := r.Context().Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
 	if !ok {
 		err := errors.New("unable to retrieve user payload from context")
-		handler.log.LogError("Error", err)
+		handler.log.With(r.Context()).LogError("Error", err)
 		helpers.ErrorJson(w, http.StatusInternalServerError, utils.InternalServerError)
 		return
 	}
 	uuId, err := uuid.NewRandom()
 	if err != nil {
-		handler.log.LogError("error while uuid NewRandom", err.Error())
+		handler.log.With(r.Context()).LogError("error while uuid NewRandom", err.Error())
 		helpers.ErrorJson(w, http.StatusInternalServerError, utils.InternalServerError)
 		return
 	}
@@ -157,7 +187,7 @@ func (handler *RestServer) InsertProduct(w http.ResponseWriter, r *http.Request)
 	for _, fileHeader := range files {
 		file, err := fileHeader.Open()
 		if err != nil {
-			handler.log.LogError("Unable to open file", err)
+			handler.log.With(r.Context()).LogError("Unable to open file", err)
 			helpers.ErrorJson(w, http.StatusInternalServerError, "Unable to open file")
 			return
 		}
@@ -165,23 +195,39 @@ func (handler *RestServer) InsertProduct(w http.ResponseWriter, r *http.Request)
 
 		folderPath := filepath.Join("products", payload.UserID, uuId.String()) + "/"
 
-		fileURL, err := handler.s3.UploadFileToS3(file, folderPath, fileHeader.Filename)
+		imageKey := folderPath + uuid.NewString()
+		err = images.Save(r.Context(), handler.files, file, imageKey)
 		if err != nil {
-			handler.log.LogError("Error uploading file to S3", err)
+			if errors.Is(err, images.ErrUnsupportedImage) {
+				handler.log.With(r.Context()).LogError("Invalid image", fileHeader.Filename, err)
+				helpers.ErrorJson(w, http.StatusBadRequest, "only jpeg, png and webp images can be uploaded")
+				return
+			}
+			handler.log.With(r.Context()).LogError("Error uploading file to S3", err)
 			helpers.ErrorJson(w, http.StatusInternalServerError, "Error uploading file to S3")
 			return
 		}
 
-		handler.log.LogInfo("File uploaded to S3 successfully", "FileURL:", fileURL)
-		uploadedFileKeys = append(uploadedFileKeys, fileURL)
+		handler.log.With(r.Context()).LogInfo("File uploaded to S3 successfully", "Key:", imageKey)
+		uploadedFileKeys = append(uploadedFileKeys, imageKey)
 	}
 	if len(uploadedFileKeys) != 0 {
 		req.ProductImages = uploadedFileKeys
 	}
+	for _, variant := range req.Variants {
+		for _, index := range variant.ImageIndexes {
+			variant.Images = append(variant.Images, uploadedFileKeys[index])
+		}
+	}
 
-	err = handler.storage.InsertProduct(payload.UserID, uuId.String(), req)
+	err = handler.storage.InsertProduct(r.Context(), payload.UserID, uuId.String(), req)
 	if err != nil {
-		handler.log.LogError("Error while InsertProduct", err)
+		if errors.Is(err, db.ErrDuplicateSKU) {
+			handler.log.With(r.Context()).LogError("Error while InsertProduct", err)
+			helpers.ErrorJson(w, http.StatusConflict, "sku is already used by another variant")
+			return
+		}
+		handler.log.With(r.Context()).LogError("Error while InsertProduct", err)
 		helpers.ErrorJson(w, http.StatusInternalServerError, utils.InternalServerError)
 		return
 	}
@@ -201,7 +247,7 @@ func (handler *RestServer) EditProduct(w http.ResponseWriter, r *http.Request) {
 	req := new(entity.EditProductReq)
 	err := helpers.ValidateBody(reader, req)
 	if err != nil {
-		handler.log.LogError("Error while ValidateBody", err)
+		handler.log.With(r.Context()).LogError("Error while ValidateBody", err)
 		helpers.ErrorJson(w, http.StatusBadRequest, err.Error())
 		return
 	}
@@ -209,36 +255,36 @@ func (handler *RestServer) EditProduct(w http.ResponseWriter, r *http.Request) {
 	payload, ok := r.Context().Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
 	if !ok {
 		err := errors.New("unable to retrieve user payload from context")
-		handler.log.LogError("Error", err)
+		handler.log.With(r.Context()).LogError("Error", err)
 		helpers.ErrorJson(w, http.StatusInternalServerError, utils.InternalServerError)
 		return
 	}
 
 	id := mux.Vars(r)["id"]
-	product, err := handler.storage.GetProductById(id)
+	product, err := handler.storage.GetProductById(r.Context(), id)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			handler.log.LogError("Error while GetProductById Not fount", err)
+			handler.log.With(r.Context()).LogError("Error while GetProductById Not fount", err)
 			helpers.ErrorJson(w, http.StatusNotFound, "Product Not found")
 			return
 		}
-		handler.log.LogError("Error while retrieving product", err)
+		handler.log.With(r.Context()).LogError("Error while retrieving product", err)
 		helpers.ErrorJson(w, http.StatusInternalServerError, utils.InternalServerError)
 		return
 	}
 
 	if *product.MerchantID != payload.UserID {
 		err := errors.New("unauthorized: product does not belong to the authenticated merchant")
-		handler.log.LogError("Error", err)
+		handler.log.With(r.Context()).LogError("Error", err)
 		helpers.ErrorJson(w, http.StatusForbidden, err.Error())
 		return
 	}
 
 	if req.ClearImages {
 		for _, key := range product.ProductImages {
-			err := handler.s3.DeleteKey(key)
+			err := images.Delete(r.Context(), handler.files, key)
 			if err != nil {
-				handler.log.LogError("Error deleting file from S3", err)
+				handler.log.With(r.Context()).LogError("Error deleting file from S3", err)
 				helpers.ErrorJson(w, http.StatusInternalServerError, "Error deleting file from")
 				return
 			}
@@ -248,20 +294,20 @@ func (handler *RestServer) EditProduct(w http.ResponseWriter, r *http.Request) {
 
 	err = r.ParseMultipartForm(thirtyTwoMB)
 	if err != nil {
-		handler.log.LogError("Unable to parse form", err.Error())
+		handler.log.With(r.Context()).LogError("Unable to parse form", err.Error())
 		helpers.ErrorJson(w, http.StatusBadRequest, utils.InvalidRequest)
 		return
 	}
 
 	files := r.MultipartForm.File["files"]
 	if len(files) == 0 {
-		handler.log.LogError("There should be atleast one image")
+		handler.log.With(r.Context()).LogError("There should be atleast one image")
 		errMsg := "There should be atleast one image"
 		helpers.ErrorJson(w, http.StatusBadRequest, errMsg)
 		return
 	}
 	if len(files) > maxFileCount {
-		handler.log.LogError("Too many files uploaded", "Max allowed: %d", maxFileCount)
+		handler.log.With(r.Context()).LogError("Too many files uploaded", "Max allowed: %d", maxFileCount)
 		errMsg := fmt.Sprintf("too many files uploaded. Max allowed: %s", strconv.Itoa(maxFileCount))
 		helpers.ErrorJson(w, http.StatusBadRequest, errMsg)
 		return
@@ -271,7 +317,7 @@ func (handler *RestServer) EditProduct(w http.ResponseWriter, r *http.Request) {
 	for _, fileHeader := range files {
 		file, err := fileHeader.Open()
 		if err != nil {
-			handler.log.LogError("Unable to open file", err)
+			handler.log.With(r.Context()).LogError("Unable to open file", err)
 			helpers.ErrorJson(w, http.StatusInternalServerError, "Unable to open file")
 			return
 		}
@@ -279,24 +325,30 @@ func (handler *RestServer) EditProduct(w http.ResponseWriter, r *http.Request) {
 
 		folderPath := filepath.Join("products", payload.UserID, id) + "/"
 
-		fileURL, err := handler.s3.UploadFileToS3(file, folderPath, fileHeader.Filename)
+		imageKey := folderPath + uuid.NewString()
+		err = images.Save(r.Context(), handler.files, file, imageKey)
 		if err != nil {
-			handler.log.LogError("Error uploading file to S3", err)
+			if errors.Is(err, images.ErrUnsupportedImage) {
+				handler.log.With(r.Context()).LogError("Invalid image", fileHeader.Filename, err)
+				helpers.ErrorJson(w, http.StatusBadRequest, "only jpeg, png and webp images can be uploaded")
+				return
+			}
+			handler.log.With(r.Context()).LogError("Error uploading file to S3", err)
 			helpers.ErrorJson(w, http.StatusInternalServerError, "Error uploading file to S3")
 			return
 		}
 
-		handler.log.LogInfo("File uploaded to S3 successfully", "FileURL:", fileURL)
-		uploadedFileKeys = append(uploadedFileKeys, fileURL)
+		handler.log.With(r.Context()).LogInfo("File uploaded to S3 successfully", "Key:", imageKey)
+		uploadedFileKeys = append(uploadedFileKeys, imageKey)
 	}
 	uploadedFileKeys = append(uploadedFileKeys, product.ProductImages...)
 	if len(uploadedFileKeys) != 0 {
 		req.ProductImages = uploadedFileKeys
 	}
 
-	err = handler.storage.UpdateProduct(id, req)
+	err = handler.storage.UpdateProduct(r.Context(), id, req)
 	if err != nil {
-		handler.log.LogError("Error while UpdateProduct", err)
+		handler.log.With(r.Context()).LogError("Error while UpdateProduct", err)
 		helpers.ErrorJson(w, http.StatusInternalServerError, utils.InternalServerError)
 		return
 	}
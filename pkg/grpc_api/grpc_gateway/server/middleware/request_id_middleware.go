@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/helpers/logger"
+)
+
+// statusRecorder captures the status code written by the next handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (recorder *statusRecorder) WriteHeader(status int) {
+	recorder.status = status
+	recorder.ResponseWriter.WriteHeader(status)
+}
+
+// RequestIDMiddleware gives every request a request ID, reusing the one sent by the client if present.
+// The ID is returned in the X-Request-Id response header and forwarded to the gRPC services in the metadata.
+func RequestIDMiddleware(log logger.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(logger.RequestIDHeader)
+			if requestID == "" {
+				requestID = logger.NewRequestID()
+				r.Header.Set(logger.RequestIDHeader, requestID)
+			}
+			w.Header().Set(logger.RequestIDHeader, requestID)
+
+			ctx := logger.ContextWithRequestID(r.Context(), requestID)
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			start := time.Now()
+			next.ServeHTTP(recorder, r.WithContext(ctx))
+
+			log.With(ctx).WithFields(logger.Fields{
+				"method":      r.Method,
+				"path":        r.URL.Path,
+				"status":      recorder.status,
+				"duration_ms": time.Since(start).Milliseconds(),
+			}).LogInfo("Finished http request")
+		},
+	)
+}
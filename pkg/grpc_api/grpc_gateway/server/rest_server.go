@@ -6,32 +6,31 @@ import (
 	"github.com/akmal4410/gestapo/pkg/grpc_api/merchant_service/db"
 	"github.com/akmal4410/gestapo/pkg/helpers/logger"
 	"github.com/akmal4410/gestapo/pkg/helpers/token"
-	s3 "github.com/akmal4410/gestapo/pkg/service/s3_service"
+	objectstore "github.com/akmal4410/gestapo/pkg/service/object_store"
 )
 
 type RestServer struct {
 	log     logger.Logger
-	s3      *s3.S3Service
-	storage *db.MerchantStore
+	files   objectstore.ObjectStore
+	storage db.MerchantRepository
 	token   token.Maker
 }
 
 // NewRestServer creates a new server for handling http request.
 func NewRestServer(storage *database.Storage, config *config.Config, log logger.Logger, tokenMaker token.Maker) *RestServer {
-	server := &RestServer{
-		log:   log,
-		token: tokenMaker,
+	files, err := objectstore.New(config)
+	if err != nil {
+		log.LogFatal("Error while Initializing object store ", err)
 	}
-	s3 := s3.NewS3Service(
-		config.AwsS3.BucketName,
-		config.AwsS3.Region,
-		config.AwsS3.AccessKey,
-		config.AwsS3.SecretKey,
-	)
-
-	merchantStore := db.NewMerchantStore(storage)
+	return NewRestServerWith(db.NewMerchantStore(storage), files, log, tokenMaker)
+}
 
-	server.s3 = s3
-	server.storage = merchantStore
-	return server
+// NewRestServerWith creates a new server for handling http request working with the given store and files.
+func NewRestServerWith(store db.MerchantRepository, files objectstore.ObjectStore, log logger.Logger, tokenMaker token.Maker) *RestServer {
+	return &RestServer{
+		log:     log,
+		files:   files,
+		storage: store,
+		token:   tokenMaker,
+	}
 }
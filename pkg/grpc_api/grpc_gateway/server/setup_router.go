@@ -6,6 +6,7 @@ import (
 
 	"github.com/akmal4410/gestapo/pkg/grpc_api/grpc_gateway/server/middleware"
 	"github.com/akmal4410/gestapo/pkg/helpers"
+	objectstore "github.com/akmal4410/gestapo/pkg/service/object_store"
 	"github.com/akmal4410/gestapo/pkg/utils"
 )
 
@@ -21,6 +22,15 @@ func (server *RestServer) SetupRouter(mux *http.ServeMux) {
 	//EditProduct
 	editProduct := middleware.ApplyAccessRoleMiddleware(server.token, server.log, utils.MERCHANT, http.HandlerFunc(server.EditProduct))
 	mux.Handle("/api/merchant/product/{id}", MethodHandler{Method: "PATCH", Handler: editProduct})
+
+	//SetCategoryImage
+	setCategoryImage := middleware.ApplyAccessRoleMiddleware(server.token, server.log, utils.ADMIN, http.HandlerFunc(server.SetCategoryImage))
+	mux.Handle("/api/admin/category/{id}/image", MethodHandler{Method: "PUT", Handler: setCategoryImage})
+
+	//Files of the local object store, the URLs are signed so no token is needed
+	if files, ok := server.files.(http.Handler); ok {
+		mux.Handle(objectstore.FilesPath, files)
+	}
 }
 
 type MethodHandler struct {
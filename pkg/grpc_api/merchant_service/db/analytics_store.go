@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/grpc_api/merchant_service/db/entity"
+)
+
+func (store *MerchantStore) GetProductAnalytics(ctx context.Context, merchantId string, since time.Time) ([]*entity.ProductAnalytics, error) {
+	selectQuery := `
+	SELECT p.id, p.product_name,
+	COALESCE(ps.view_count, 0), COUNT(v.id), COUNT(DISTINCT COALESCE(v.user_id::text, v.device_id)),
+	COALESCE(ps.units_sold, 0), COALESCE(ps.wishlist_count, 0)
+	FROM products p
+	LEFT JOIN product_stats ps ON p.id = ps.product_id
+	LEFT JOIN product_views v ON p.id = v.product_id AND v.viewed_at >= $2
+	WHERE p.merchent_id = $1 AND p.deleted_at IS NULL
+	GROUP BY p.id, p.product_name, ps.view_count, ps.units_sold, ps.wishlist_count
+	ORDER BY COUNT(v.id) DESC, p.product_name, p.id;
+	`
+	rows, err := store.storage.DB.QueryContext(ctx, selectQuery, merchantId, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	analytics := []*entity.ProductAnalytics{}
+	for rows.Next() {
+		var product entity.ProductAnalytics
+		err := rows.Scan(
+			&product.ProductID,
+			&product.ProductName,
+			&product.ViewCount,
+			&product.RecentViews,
+			&product.RecentViewers,
+			&product.UnitsSold,
+			&product.WishlistCount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		analytics = append(analytics, &product)
+	}
+	return analytics, rows.Err()
+}
@@ -0,0 +1,105 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/grpc_api/merchant_service/db/entity"
+	product_entity "github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	"github.com/lib/pq"
+)
+
+func (store *MerchantStore) CreateImportJob(ctx context.Context, job *entity.ImportJob) error {
+	insertQuery := `
+	INSERT INTO import_jobs
+	(id, merchant_id, format, dry_run, status, total_rows, processed_rows, created_products, failed_rows, errors, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $11);
+	`
+	_, err := store.storage.DB.ExecContext(ctx, insertQuery, job.ID, job.MerchantID, job.Format, job.DryRun, job.Status,
+		job.TotalRows, job.ProcessedRows, job.CreatedProducts, job.FailedRows, job.Errors, job.CreatedAt)
+	return err
+}
+
+func (store *MerchantStore) UpdateImportJob(ctx context.Context, job *entity.ImportJob) error {
+	updateQuery := `
+	UPDATE import_jobs
+	SET status = $2, processed_rows = $3, created_products = $4, failed_rows = $5, errors = $6, finished_at = $7, updated_at = $8
+	WHERE id = $1;
+	`
+	_, err := store.storage.DB.ExecContext(ctx, updateQuery, job.ID, job.Status, job.ProcessedRows, job.CreatedProducts,
+		job.FailedRows, job.Errors, job.FinishedAt, time.Now())
+	return err
+}
+
+func (store *MerchantStore) GetImportJob(ctx context.Context, jobId string) (*entity.ImportJob, error) {
+	selectQuery := `
+	SELECT id, merchant_id, format, dry_run, status, total_rows, processed_rows, created_products, failed_rows, errors, finished_at, created_at
+	FROM import_jobs
+	WHERE id = $1;
+	`
+	var job entity.ImportJob
+	err := store.storage.DB.QueryRowContext(ctx, selectQuery, jobId).Scan(
+		&job.ID,
+		&job.MerchantID,
+		&job.Format,
+		&job.DryRun,
+		&job.Status,
+		&job.TotalRows,
+		&job.ProcessedRows,
+		&job.CreatedProducts,
+		&job.FailedRows,
+		&job.Errors,
+		&job.FinishedAt,
+		&job.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (store *MerchantStore) GetCatalog(ctx context.Context, merchantId string) ([]*entity.CatalogRow, error) {
+	selectQuery := `
+	SELECT p.product_name, p.description, p.category_id, p.price, COALESCE(i.sku, ''), i.options, i.price, i.quantity, p.images, i.images, p.attributes
+	FROM products p
+	JOIN inventories i ON i.product_id = p.id
+	WHERE p.merchent_id = $1 AND p.deleted_at IS NULL
+	ORDER BY p.product_name, p.id, i.size, i.sku;
+	`
+	rows, err := store.storage.DB.QueryContext(ctx, selectQuery, merchantId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	catalog := []*entity.CatalogRow{}
+	for rows.Next() {
+		var row entity.CatalogRow
+		var options, attributes product_entity.Options
+		var productImages, variantImages pq.StringArray
+		err := rows.Scan(
+			&row.ProductName,
+			&row.Description,
+			&row.CategoryID,
+			&row.Price,
+			&row.SKU,
+			&options,
+			&row.VariantPrice,
+			&row.Quantity,
+			&productImages,
+			&variantImages,
+			&attributes,
+		)
+		if err != nil {
+			return nil, err
+		}
+		row.Options = options
+		if len(attributes) != 0 {
+			row.Attributes = attributes
+		}
+		row.ImageURLs = productImages
+		row.VariantImageURLs = variantImages
+		catalog = append(catalog, &row)
+	}
+	return catalog, rows.Err()
+}
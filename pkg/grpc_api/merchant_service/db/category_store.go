@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/grpc_api/merchant_service/db/entity"
+)
+
+func (store *MerchantStore) GetProductCategory(ctx context.Context, categoryId string) (*entity.ProductCategory, error) {
+	// the ancestors are the categories whose path starts the path of the category, from the root
+	selectQuery := `
+	SELECT c.id, c.archived_at, a.attributes
+	FROM categories c
+	JOIN categories a ON c.path = a.path OR c.path LIKE a.path || '/%'
+	WHERE c.id = $1 AND c.deleted_at IS NULL
+	ORDER BY LENGTH(a.path);
+	`
+	rows, err := store.storage.DB.QueryContext(ctx, selectQuery, categoryId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var category *entity.ProductCategory
+	for rows.Next() {
+		var res entity.ProductCategory
+		var attributes entity.CategoryAttributes
+		err := rows.Scan(&res.ID, &res.ArchivedAt, &attributes)
+		if err != nil {
+			return nil, err
+		}
+		if category == nil {
+			category = &res
+		}
+		category.Attributes = append(category.Attributes, attributes...)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if category == nil {
+		return nil, sql.ErrNoRows
+	}
+	return category, nil
+}
+
+func (store *MerchantStore) AttachCategoryImage(ctx context.Context, sessionId, categoryId, key string) (*string, error) {
+	tx, err := store.storage.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := markUploadAttached(ctx, tx, sessionId); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	updateQuery := `
+	UPDATE categories c SET image = $2, updated_at = $3
+	FROM (SELECT id, image FROM categories WHERE id = $1 AND deleted_at IS NULL FOR UPDATE) old
+	WHERE c.id = old.id
+	RETURNING old.image;
+	`
+	var previous *string
+	err = tx.QueryRowContext(ctx, updateQuery, categoryId, key, time.Now()).Scan(&previous)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return previous, nil
+}
@@ -0,0 +1,14 @@
+package entity
+
+// ProductAnalytics are the views and the sales of a product of the merchant.
+// RecentViews and RecentViewers count the views since the start of the period,
+// the viewers who cleared their history are not counted as viewers.
+type ProductAnalytics struct {
+	ProductID     string `json:"product_id"`
+	ProductName   string `json:"product_name"`
+	ViewCount     int64  `json:"view_count"`
+	RecentViews   int64  `json:"recent_views"`
+	RecentViewers int64  `json:"recent_viewers"`
+	UnitsSold     int64  `json:"units_sold"`
+	WishlistCount int64  `json:"wishlist_count"`
+}
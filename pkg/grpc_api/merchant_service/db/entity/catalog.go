@@ -0,0 +1,308 @@
+package entity
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats of the catalog files.
+const (
+	CatalogCSV  = "CSV"
+	CatalogJSON = "JSON"
+)
+
+// Statuses of the import jobs.
+const (
+	ImportPending   = "PENDING"
+	ImportRunning   = "RUNNING"
+	ImportCompleted = "COMPLETED"
+	ImportFailed    = "FAILED"
+)
+
+// catalogColumns are the columns of a CSV catalog, in the order they are exported.
+var catalogColumns = []string{
+	"product_name", "description", "category_id", "price", "sku", "options",
+	"variant_price", "quantity", "image_urls", "variant_image_urls", "attributes",
+}
+
+// requiredColumns must be in the header of an imported CSV catalog.
+var requiredColumns = []string{"product_name", "price", "sku", "options", "quantity"}
+
+const (
+	// optionSeparator separates the options of a CSV cell, like "color=red;size=8".
+	optionSeparator = ";"
+	// urlSeparator separates the image URLs of a CSV cell.
+	urlSeparator = "|"
+)
+
+// CatalogRow is a variant of a product in an imported or exported catalog. The
+// rows of the same product share its name, the fields of the product, like its
+// attributes, are taken from its first row.
+type CatalogRow struct {
+	ProductName      string            `json:"product_name"`
+	Description      string            `json:"description,omitempty"`
+	CategoryID       string            `json:"category_id,omitempty"`
+	Price            float64           `json:"price"`
+	SKU              string            `json:"sku"`
+	Options          map[string]string `json:"options"`
+	VariantPrice     *float64          `json:"variant_price,omitempty"`
+	Quantity         int               `json:"quantity"`
+	ImageURLs        []string          `json:"image_urls,omitempty"`
+	VariantImageURLs []string          `json:"variant_image_urls,omitempty"`
+	Attributes       map[string]string `json:"attributes,omitempty"`
+}
+
+// ImportRowError is a row of the catalog that could not be imported. The first
+// row is 1, the header of a CSV catalog is not counted.
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportRowErrors are stored as jsonb.
+type ImportRowErrors []ImportRowError
+
+func (rowErrors ImportRowErrors) Value() (driver.Value, error) {
+	if rowErrors == nil {
+		return "[]", nil
+	}
+	value, err := json.Marshal(rowErrors)
+	return string(value), err
+}
+
+func (rowErrors *ImportRowErrors) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return json.Unmarshal(src, rowErrors)
+	case string:
+		return json.Unmarshal([]byte(src), rowErrors)
+	}
+	return fmt.Errorf("cannot scan %T into ImportRowErrors", src)
+}
+
+// ImportJob imports a catalog in the background, its counts tell the progress.
+type ImportJob struct {
+	ID              string          `json:"id"`
+	MerchantID      string          `json:"merchant_id"`
+	Format          string          `json:"format"`
+	DryRun          bool            `json:"dry_run"`
+	Status          string          `json:"status"`
+	TotalRows       int             `json:"total_rows"`
+	ProcessedRows   int             `json:"processed_rows"`
+	CreatedProducts int             `json:"created_products"`
+	FailedRows      int             `json:"failed_rows"`
+	Errors          ImportRowErrors `json:"errors"`
+	FinishedAt      *time.Time      `json:"finished_at,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+}
+
+// ParseCatalog reads the rows of a catalog. The rows that cannot be read are
+// returned as row errors, the error is for a catalog that cannot be read at all.
+func ParseCatalog(format, content string) ([]*CatalogRow, []ImportRowError, error) {
+	switch format {
+	case CatalogCSV:
+		return parseCSVCatalog(content)
+	case CatalogJSON:
+		return parseJSONCatalog(content)
+	}
+	return nil, nil, fmt.Errorf("format should be %s or %s", CatalogCSV, CatalogJSON)
+}
+
+func parseJSONCatalog(content string) ([]*CatalogRow, []ImportRowError, error) {
+	var raws []json.RawMessage
+	if err := json.Unmarshal([]byte(content), &raws); err != nil {
+		return nil, nil, errors.New("a JSON catalog should be an array of rows")
+	}
+	rows := make([]*CatalogRow, len(raws))
+	var rowErrors []ImportRowError
+	for i, raw := range raws {
+		row := new(CatalogRow)
+		if err := json.Unmarshal(raw, row); err != nil {
+			rowErrors = append(rowErrors, ImportRowError{Row: i + 1, Message: "invalid row"})
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) {
+				rowErrors[len(rowErrors)-1].Field = typeErr.Field
+			}
+		}
+		rows[i] = row
+	}
+	return rows, rowErrors, nil
+}
+
+func parseCSVCatalog(content string) ([]*CatalogRow, []ImportRowError, error) {
+	reader := csv.NewReader(strings.NewReader(content))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, errors.New("a CSV catalog should start with a header")
+	}
+	columns := map[string]int{}
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if !containsColumn(catalogColumns, column) {
+			return nil, nil, fmt.Errorf("unknown column %s", column)
+		}
+		columns[column] = i
+	}
+	for _, column := range requiredColumns {
+		if _, ok := columns[column]; !ok {
+			return nil, nil, fmt.Errorf("the column %s is required", column)
+		}
+	}
+
+	var rows []*CatalogRow
+	var rowErrors []ImportRowError
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		rowNumber := len(rows) + 1
+		row := new(CatalogRow)
+		rows = append(rows, row)
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, nil, err
+			}
+			rowErrors = append(rowErrors, ImportRowError{Row: rowNumber, Message: "invalid row"})
+			continue
+		}
+		if len(record) != len(header) {
+			rowErrors = append(rowErrors, ImportRowError{Row: rowNumber, Message: fmt.Sprintf("the row should have %d fields", len(header))})
+			continue
+		}
+		cell := func(column string) string {
+			if i, ok := columns[column]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		fail := func(field, message string) {
+			rowErrors = append(rowErrors, ImportRowError{Row: rowNumber, Field: field, Message: message})
+		}
+
+		row.ProductName = cell("product_name")
+		row.Description = cell("description")
+		row.CategoryID = cell("category_id")
+		row.SKU = cell("sku")
+		if value := cell("price"); value != "" {
+			if row.Price, err = strconv.ParseFloat(value, 64); err != nil {
+				fail("price", "price should be a number")
+			}
+		}
+		if value := cell("variant_price"); value != "" {
+			price, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				fail("variant_price", "variant_price should be a number")
+			}
+			row.VariantPrice = &price
+		}
+		if row.Quantity, err = strconv.Atoi(cell("quantity")); err != nil {
+			fail("quantity", "quantity should be a whole number")
+		}
+		if row.Options, err = parseOptions(cell("options")); err != nil {
+			fail("options", err.Error())
+		}
+		if row.Attributes, err = parseOptions(cell("attributes")); err != nil {
+			fail("attributes", err.Error())
+		}
+		row.ImageURLs = splitCell(cell("image_urls"), urlSeparator)
+		row.VariantImageURLs = splitCell(cell("variant_image_urls"), urlSeparator)
+	}
+	return rows, rowErrors, nil
+}
+
+// parseOptions reads options written like "color=red;size=8", the attributes are written the same way.
+func parseOptions(value string) (map[string]string, error) {
+	options := map[string]string{}
+	for _, option := range splitCell(value, optionSeparator) {
+		name, value, ok := strings.Cut(option, "=")
+		if !ok {
+			return nil, fmt.Errorf("option %s should be written as name=value", option)
+		}
+		options[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return options, nil
+}
+
+func splitCell(value, separator string) []string {
+	var values []string
+	for _, part := range strings.Split(value, separator) {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
+
+func containsColumn(columns []string, column string) bool {
+	for _, c := range columns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatCatalog writes the rows of a catalog in the format, a CSV catalog has all the columns.
+func FormatCatalog(format string, rows []*CatalogRow) (string, error) {
+	switch format {
+	case CatalogJSON:
+		if rows == nil {
+			rows = []*CatalogRow{}
+		}
+		content, err := json.MarshalIndent(rows, "", "  ")
+		return string(content), err
+	case CatalogCSV:
+		var buf bytes.Buffer
+		writer := csv.NewWriter(&buf)
+		writer.Write(catalogColumns)
+		for _, row := range rows {
+			variantPrice := ""
+			if row.VariantPrice != nil {
+				variantPrice = strconv.FormatFloat(*row.VariantPrice, 'f', -1, 64)
+			}
+			writer.Write([]string{
+				row.ProductName,
+				row.Description,
+				row.CategoryID,
+				strconv.FormatFloat(row.Price, 'f', -1, 64),
+				row.SKU,
+				formatOptions(row.Options),
+				variantPrice,
+				strconv.Itoa(row.Quantity),
+				strings.Join(row.ImageURLs, urlSeparator),
+				strings.Join(row.VariantImageURLs, urlSeparator),
+				formatOptions(row.Attributes),
+			})
+		}
+		writer.Flush()
+		return buf.String(), writer.Error()
+	}
+	return "", fmt.Errorf("format should be %s or %s", CatalogCSV, CatalogJSON)
+}
+
+func formatOptions(options map[string]string) string {
+	names := make([]string, 0, len(options))
+	for name := range options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	values := make([]string, 0, len(names))
+	for _, name := range names {
+		values = append(values, name+"="+options[name])
+	}
+	return strings.Join(values, optionSeparator)
+}
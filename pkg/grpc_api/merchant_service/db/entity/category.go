@@ -0,0 +1,96 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/utils"
+)
+
+// CategoryAttribute is an attribute the products of a category can have.
+type CategoryAttribute struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Required bool     `json:"required,omitempty"`
+	Values   []string `json:"values,omitempty"`
+}
+
+// CategoryAttributes are stored as jsonb.
+type CategoryAttributes []CategoryAttribute
+
+func (attributes CategoryAttributes) Value() (driver.Value, error) {
+	if attributes == nil {
+		return "[]", nil
+	}
+	value, err := json.Marshal(attributes)
+	return string(value), err
+}
+
+func (attributes *CategoryAttributes) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return json.Unmarshal(src, attributes)
+	case string:
+		return json.Unmarshal([]byte(src), attributes)
+	}
+	return fmt.Errorf("cannot scan %T into CategoryAttributes", src)
+}
+
+// ProductCategory is the category a product is added to, with the attributes of
+// the category and of its ancestors. An attribute of a category overrides the
+// one of the same name of its ancestors.
+type ProductCategory struct {
+	ID         string
+	ArchivedAt *time.Time
+	Attributes CategoryAttributes
+}
+
+// ValidateAttributes checks the attributes of a product against the ones of its
+// category: the required ones are set, the values have the type of the attribute
+// and the attributes of other categories are refused.
+func (category *ProductCategory) ValidateAttributes(attributes map[string]string) error {
+	schemas := map[string]CategoryAttribute{}
+	for _, attribute := range category.Attributes {
+		schemas[strings.ToLower(attribute.Name)] = attribute
+	}
+	for name, value := range attributes {
+		schema, ok := schemas[strings.ToLower(name)]
+		if !ok {
+			return fmt.Errorf("attribute %s is not an attribute of the category", name)
+		}
+		if schema.Name != name {
+			return fmt.Errorf("attribute %s should be written %s", name, schema.Name)
+		}
+		value = strings.TrimSpace(value)
+		switch schema.Type {
+		case utils.AttributeNumber:
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				return fmt.Errorf("attribute %s should be a number", name)
+			}
+		case utils.AttributeBoolean:
+			if _, err := strconv.ParseBool(value); err != nil {
+				return fmt.Errorf("attribute %s should be true or false", name)
+			}
+		case utils.AttributeEnum:
+			if !slices.Contains(schema.Values, value) {
+				return fmt.Errorf("attribute %s should be one of %s", name, strings.Join(schema.Values, ", "))
+			}
+		default:
+			if value == "" {
+				return fmt.Errorf("attribute %s must not be empty", name)
+			}
+		}
+	}
+	for _, attribute := range category.Attributes {
+		schema := schemas[strings.ToLower(attribute.Name)]
+		if _, ok := attributes[schema.Name]; schema.Required && !ok {
+			return fmt.Errorf("attribute %s is required", schema.Name)
+		}
+	}
+	return nil
+}
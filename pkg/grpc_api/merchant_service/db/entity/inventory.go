@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"time"
+
+	product_entity "github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+)
+
+// InventoryRes is the stock of a variant of a product of the merchant.
+type InventoryRes struct {
+	ID                string                 `json:"id"`
+	ProductID         string                 `json:"product_id"`
+	ProductName       string                 `json:"product_name"`
+	MerchantID        string                 `json:"merchant_id"`
+	SKU               *string                `json:"sku,omitempty"`
+	Options           product_entity.Options `json:"options"`
+	Quantity          int32                  `json:"quantity"`
+	LowStockThreshold *int32                 `json:"low_stock_threshold,omitempty"`
+}
+
+// LowStock reports whether the stock fell to the threshold of the variant.
+func (inventory *InventoryRes) LowStock() bool {
+	return inventory.LowStockThreshold != nil && inventory.Quantity <= *inventory.LowStockThreshold
+}
+
+// StockMovementReq changes the stock of a variant by Quantity, the type tells why.
+type StockMovementReq struct {
+	InventoryID string  `json:"inventory_id" validate:"required,uuid"`
+	Type        string  `json:"type" validate:"required"`
+	Quantity    int32   `json:"quantity" validate:"required"`
+	Reason      string  `json:"reason"`
+	OrderItemID *string `json:"order_item_id" validate:"omitempty,uuid"`
+	UserID      string  `json:"user_id" validate:"required"`
+}
+
+type StockMovement struct {
+	ID          string    `json:"id"`
+	InventoryID string    `json:"inventory_id"`
+	Type        string    `json:"type"`
+	Quantity    int32     `json:"quantity"`
+	Balance     int32     `json:"balance"`
+	Reason      string    `json:"reason"`
+	OrderItemID *string   `json:"order_item_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -23,14 +23,30 @@ type EditMerchantReq struct {
 	Gender       string `json:"gender" validate:"omitempty,gender"`
 }
 
+// AddProductReq adds a product with its variants, or with the same quantity in
+// stock for each of its sizes when it has no variants.
 type AddProductReq struct {
-	ProductName   string    `json:"product_name" validate:"required"`
-	Description   string    `json:"description" validate:"required"`
-	ProductImages []string  `json:"product_images" validate:"omitempty"`
-	Sizes         []float64 `json:"sizes" validate:"required"`
-	Price         float64   `json:"price" validate:"required"`
-	CategoryId    string    `json:"category_id" validate:"required"`
-	Quantity      int       `json:"quantity" validate:"required"`
+	ProductName   string           `json:"product_name" validate:"required"`
+	Description   string           `json:"description" validate:"required"`
+	ProductImages []string         `json:"product_images" validate:"omitempty"`
+	Sizes         []float64        `json:"sizes" validate:"required_without=Variants"`
+	Price         float64          `json:"price" validate:"required"`
+	CategoryId    string           `json:"category_id" validate:"required"`
+	Quantity      int              `json:"quantity" validate:"required_without=Variants"`
+	OptionTypes   []string         `json:"option_types" validate:"required_with=Variants,dive,required"`
+	Variants      []*AddVariantReq `json:"variants" validate:"omitempty,dive"`
+	// Attributes are the values of the attributes of the category
+	Attributes map[string]string `json:"attributes" validate:"omitempty"`
+}
+
+type AddVariantReq struct {
+	SKU      string            `json:"sku" validate:"required"`
+	Options  map[string]string `json:"options" validate:"required"`
+	Price    *float64          `json:"price" validate:"omitempty,gt=0"`
+	Quantity int               `json:"quantity" validate:"gte=0"`
+	// ImageIndexes are the indexes of the images of the variant in the uploaded files.
+	ImageIndexes []int    `json:"image_indexes" validate:"omitempty,dive,gte=0"`
+	Images       []string `json:"-"`
 }
 type EditProductReq struct {
 	ProductName   string   `json:"product_name" validate:"required"`
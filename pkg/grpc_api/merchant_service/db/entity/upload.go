@@ -0,0 +1,36 @@
+package entity
+
+import "time"
+
+// Purposes of the upload sessions, what the uploaded image is attached to.
+const (
+	ProductImage = "PRODUCT_IMAGE"
+	ProfileImage = "PROFILE_IMAGE"
+	// CategoryImage uploads are attached by the admins with SetCategoryImage of the gateway
+	CategoryImage = "CATEGORY_IMAGE"
+)
+
+// Statuses of the upload sessions.
+const (
+	UploadPending  = "PENDING"
+	UploadAttached = "ATTACHED"
+)
+
+// UploadSession is an image uploaded by the client straight to the object store.
+type UploadSession struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"user_id"`
+	Purpose     string    `json:"purpose"`
+	TargetID    *string   `json:"target_id,omitempty"`
+	Key         string    `json:"key"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Status      string    `json:"status"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// SetCategoryImageReq attaches the image of a CATEGORY_IMAGE upload session to a category.
+type SetCategoryImageReq struct {
+	UploadID string `json:"upload_id" validate:"required,uuid"`
+}
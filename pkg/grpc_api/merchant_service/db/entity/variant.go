@@ -0,0 +1,97 @@
+package entity
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	product_entity "github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+)
+
+// NormalizeVariants checks the variants of the product against its option types,
+// or makes one variant for each size of a product added without variants. The
+// sizes of the product are then the values of the size option of its variants.
+// imageCount is the number of uploaded files the variants may refer to.
+func (req *AddProductReq) NormalizeVariants(imageCount int) error {
+	if len(req.Variants) == 0 {
+		req.OptionTypes = []string{product_entity.SizeOption}
+		for _, size := range req.Sizes {
+			req.Variants = append(req.Variants, &AddVariantReq{
+				Options:  map[string]string{product_entity.SizeOption: product_entity.SizeValue(size)},
+				Quantity: req.Quantity,
+			})
+		}
+	}
+
+	optionTypes := map[string]bool{}
+	for _, optionType := range req.OptionTypes {
+		if optionTypes[optionType] {
+			return fmt.Errorf("option type %s is repeated", optionType)
+		}
+		optionTypes[optionType] = true
+	}
+
+	skus := map[string]bool{}
+	combinations := map[string]bool{}
+	var sizes []float64
+	for _, variant := range req.Variants {
+		if len(variant.Options) != len(optionTypes) {
+			return fmt.Errorf("variant %s must have a value for each option type", variant.SKU)
+		}
+		for optionType, value := range variant.Options {
+			if !optionTypes[optionType] {
+				return fmt.Errorf("variant %s has an unknown option type %s", variant.SKU, optionType)
+			}
+			if value == "" {
+				return fmt.Errorf("variant %s has no value for %s", variant.SKU, optionType)
+			}
+		}
+		size, err := product_entity.Options(variant.Options).Size()
+		if err != nil {
+			return fmt.Errorf("variant %s has an invalid size", variant.SKU)
+		}
+		if optionTypes[product_entity.SizeOption] && !containsSize(sizes, size) {
+			sizes = append(sizes, size)
+		}
+
+		if variant.SKU != "" {
+			if skus[variant.SKU] {
+				return fmt.Errorf("sku %s is repeated", variant.SKU)
+			}
+			skus[variant.SKU] = true
+		}
+		combination := combinationKey(req.OptionTypes, variant.Options)
+		if combinations[combination] {
+			return fmt.Errorf("options %s are repeated", combination)
+		}
+		combinations[combination] = true
+
+		for _, index := range variant.ImageIndexes {
+			if index >= imageCount {
+				return fmt.Errorf("variant %s refers to the missing image %d", variant.SKU, index)
+			}
+		}
+	}
+
+	sort.Float64s(sizes)
+	req.Sizes = sizes
+	return nil
+}
+
+// combinationKey writes the options in the order of the option types, like "color=red, size=8".
+func combinationKey(optionTypes []string, options map[string]string) string {
+	values := make([]string, 0, len(optionTypes))
+	for _, optionType := range optionTypes {
+		values = append(values, optionType+"="+options[optionType])
+	}
+	return strings.Join(values, ", ")
+}
+
+func containsSize(sizes []float64, size float64) bool {
+	for _, s := range sizes {
+		if s == size {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,198 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/grpc_api/merchant_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+const selectInventoryQuery = `
+	SELECT i.id, i.product_id, p.product_name, p.merchent_id, i.sku, i.options, i.quantity, i.low_stock_threshold
+	FROM inventories i
+	JOIN products p ON i.product_id = p.id
+	`
+
+func (store *MerchantStore) GetVariant(ctx context.Context, variantId string) (*entity.InventoryRes, error) {
+	inventories, err := store.inventories(ctx, selectInventoryQuery+`WHERE i.id = $1;`, variantId)
+	if err != nil {
+		return nil, err
+	}
+	if len(inventories) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return inventories[0], nil
+}
+
+func (store *MerchantStore) GetInventory(ctx context.Context, productId string) ([]*entity.InventoryRes, error) {
+	return store.inventories(ctx, selectInventoryQuery+`WHERE i.product_id = $1 ORDER BY i.size, i.sku;`, productId)
+}
+
+func (store *MerchantStore) GetLowStockVariants(ctx context.Context, merchantId string) ([]*entity.InventoryRes, error) {
+	return store.inventories(ctx, selectInventoryQuery+`
+	WHERE p.merchent_id = $1 AND p.deleted_at IS NULL AND i.quantity <= i.low_stock_threshold
+	ORDER BY i.quantity, p.product_name, i.size, i.sku;`, merchantId)
+}
+
+func (store *MerchantStore) inventories(ctx context.Context, query string, args ...interface{}) ([]*entity.InventoryRes, error) {
+	rows, err := store.storage.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	inventories := []*entity.InventoryRes{}
+	for rows.Next() {
+		var inventory entity.InventoryRes
+		err := rows.Scan(
+			&inventory.ID,
+			&inventory.ProductID,
+			&inventory.ProductName,
+			&inventory.MerchantID,
+			&inventory.SKU,
+			&inventory.Options,
+			&inventory.Quantity,
+			&inventory.LowStockThreshold,
+		)
+		if err != nil {
+			return nil, err
+		}
+		inventories = append(inventories, &inventory)
+	}
+	return inventories, rows.Err()
+}
+
+func (store *MerchantStore) MoveStock(ctx context.Context, req *entity.StockMovementReq) (*entity.StockMovement, error) {
+	tx, err := store.storage.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	movement := &entity.StockMovement{
+		ID:          uuid.NewString(),
+		InventoryID: req.InventoryID,
+		Type:        req.Type,
+		Quantity:    req.Quantity,
+		Reason:      req.Reason,
+		OrderItemID: req.OrderItemID,
+		CreatedAt:   time.Now(),
+	}
+	var productId string
+
+	if req.Type == utils.StockReturn {
+		err = checkReturnable(ctx, tx, req)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	// the condition on the quantity keeps the stock from going below zero under concurrent orders
+	updateQuery := `
+	UPDATE inventories
+	SET quantity = quantity + $2, updated_at = $3
+	WHERE id = $1 AND quantity + $2 >= 0
+	RETURNING quantity, product_id;
+	`
+	err = tx.QueryRowContext(ctx, updateQuery, req.InventoryID, req.Quantity, movement.CreatedAt).Scan(&movement.Balance, &productId)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return nil, ErrInsufficientStock
+		}
+		return nil, err
+	}
+
+	insertQuery := `
+	INSERT INTO stock_movements
+	(id, inventory_id, product_id, type, quantity, balance, reason, order_item_id, user_id, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+	`
+	_, err = tx.ExecContext(ctx, insertQuery, movement.ID, req.InventoryID, productId, req.Type, req.Quantity, movement.Balance, req.Reason, req.OrderItemID, req.UserID, movement.CreatedAt)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return movement, tx.Commit()
+}
+
+// checkReturnable checks the return is not above the quantity of the order item
+// not returned yet. The order item is locked first so concurrent returns of the
+// same item wait, and the returns are summed in a later statement to see the ones
+// committed meanwhile.
+func checkReturnable(ctx context.Context, tx *sql.Tx, req *entity.StockMovementReq) error {
+	var sold int32
+	lockQuery := `SELECT quantity FROM order_items WHERE id = $1 AND inventory_id = $2 FOR UPDATE;`
+	err := tx.QueryRowContext(ctx, lockQuery, req.OrderItemID, req.InventoryID).Scan(&sold)
+	if err != nil {
+		return err
+	}
+
+	var returned int32
+	sumQuery := `SELECT COALESCE(SUM(quantity), 0) FROM stock_movements WHERE order_item_id = $1 AND type = $2;`
+	err = tx.QueryRowContext(ctx, sumQuery, req.OrderItemID, utils.StockReturn).Scan(&returned)
+	if err != nil {
+		return err
+	}
+	if req.Quantity > sold-returned {
+		return ErrAboveReturnable
+	}
+	return nil
+}
+
+func (store *MerchantStore) SetLowStockThreshold(ctx context.Context, variantId string, threshold *int32) error {
+	updateQuery := `UPDATE inventories SET low_stock_threshold = $2, updated_at = $3 WHERE id = $1;`
+	res, err := store.storage.DB.ExecContext(ctx, updateQuery, variantId, threshold, time.Now())
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (store *MerchantStore) GetStockMovements(ctx context.Context, variantId string, limit, offset int) ([]*entity.StockMovement, int64, error) {
+	selectQuery := `
+	SELECT id, inventory_id, type, quantity, balance, COALESCE(reason, ''), order_item_id, created_at, COUNT(*) OVER ()
+	FROM stock_movements
+	WHERE inventory_id = $1
+	ORDER BY created_at DESC, id
+	LIMIT $2 OFFSET $3;
+	`
+	rows, err := store.storage.DB.QueryContext(ctx, selectQuery, variantId, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	movements := []*entity.StockMovement{}
+	var total int64
+	for rows.Next() {
+		var movement entity.StockMovement
+		err := rows.Scan(
+			&movement.ID,
+			&movement.InventoryID,
+			&movement.Type,
+			&movement.Quantity,
+			&movement.Balance,
+			&movement.Reason,
+			&movement.OrderItemID,
+			&movement.CreatedAt,
+			&total,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		movements = append(movements, &movement)
+	}
+	return movements, total, rows.Err()
+}
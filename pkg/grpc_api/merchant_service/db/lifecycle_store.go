@@ -0,0 +1,42 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	product_entity "github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/utils"
+)
+
+func (store *MerchantStore) SetProductState(ctx context.Context, from string, state *product_entity.ProductState) error {
+	updateQuery := `
+	UPDATE products
+	SET status = $3, publish_at = $4, unpublish_at = $5, status_reason = $6, updated_at = $7
+	WHERE id = $1 AND status = $2 AND deleted_at IS NULL;
+	`
+	res, err := store.storage.DB.ExecContext(ctx, updateQuery, state.ProductID, from, state.Status,
+		state.PublishAt, state.UnpublishAt, state.StatusReason, time.Now())
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrStatusChanged
+	}
+	return nil
+}
+
+func (store *MerchantStore) ArchiveExpiredProducts(ctx context.Context) (int64, error) {
+	updateQuery := `
+	UPDATE products SET status = $1, updated_at = NOW()
+	WHERE status = $2 AND unpublish_at <= NOW() AND deleted_at IS NULL;
+	`
+	res, err := store.storage.DB.ExecContext(ctx, updateQuery, utils.ProductArchived, utils.ProductPublished)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
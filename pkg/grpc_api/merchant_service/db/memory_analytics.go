@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/grpc_api/merchant_service/db/entity"
+)
+
+func (store *MemoryMerchantStore) GetProductAnalytics(ctx context.Context, merchantId string, since time.Time) ([]*entity.ProductAnalytics, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	analytics := []*entity.ProductAnalytics{}
+	for _, product := range store.db.Products {
+		if product.MerchantID != merchantId || product.DeletedAt != nil {
+			continue
+		}
+		stats := store.db.Stats(product.ID)
+		res := &entity.ProductAnalytics{
+			ProductID:     product.ID,
+			ProductName:   product.ProductName,
+			ViewCount:     stats.ViewCount,
+			UnitsSold:     stats.UnitsSold,
+			WishlistCount: stats.WishlistCount,
+		}
+		viewers := map[string]bool{}
+		for _, view := range store.db.ProductViews {
+			if view.ProductID != product.ID || view.ViewedAt.Before(since) {
+				continue
+			}
+			res.RecentViews++
+			if view.UserID != nil {
+				viewers[*view.UserID] = true
+			} else if view.DeviceID != nil {
+				viewers[*view.DeviceID] = true
+			}
+		}
+		res.RecentViewers = int64(len(viewers))
+		analytics = append(analytics, res)
+	}
+	sort.Slice(analytics, func(i, j int) bool {
+		if analytics[i].RecentViews != analytics[j].RecentViews {
+			return analytics[i].RecentViews > analytics[j].RecentViews
+		}
+		if analytics[i].ProductName != analytics[j].ProductName {
+			return analytics[i].ProductName < analytics[j].ProductName
+		}
+		return analytics[i].ProductID < analytics[j].ProductID
+	})
+	return analytics, nil
+}
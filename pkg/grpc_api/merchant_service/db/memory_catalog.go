@@ -0,0 +1,122 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/akmal4410/gestapo/internal/database/memory"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/merchant_service/db/entity"
+)
+
+func (store *MemoryMerchantStore) CreateImportJob(ctx context.Context, job *entity.ImportJob) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	store.db.ImportJobs[job.ID] = &memory.ImportJob{
+		ID:         job.ID,
+		MerchantID: job.MerchantID,
+		Format:     job.Format,
+		DryRun:     job.DryRun,
+		Status:     job.Status,
+		TotalRows:  int32(job.TotalRows),
+		CreatedAt:  job.CreatedAt,
+		UpdatedAt:  job.CreatedAt,
+	}
+	return nil
+}
+
+func (store *MemoryMerchantStore) UpdateImportJob(ctx context.Context, job *entity.ImportJob) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	row, ok := store.db.ImportJobs[job.ID]
+	if !ok {
+		return nil
+	}
+	row.Status = job.Status
+	row.ProcessedRows = int32(job.ProcessedRows)
+	row.CreatedProducts = int32(job.CreatedProducts)
+	row.FailedRows = int32(job.FailedRows)
+	row.Errors = nil
+	for _, rowError := range job.Errors {
+		row.Errors = append(row.Errors, memory.ImportRowError{Row: int32(rowError.Row), Field: rowError.Field, Message: rowError.Message})
+	}
+	row.FinishedAt = job.FinishedAt
+	row.UpdatedAt = time.Now()
+	return nil
+}
+
+func (store *MemoryMerchantStore) GetImportJob(ctx context.Context, jobId string) (*entity.ImportJob, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	row, ok := store.db.ImportJobs[jobId]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	job := &entity.ImportJob{
+		ID:              row.ID,
+		MerchantID:      row.MerchantID,
+		Format:          row.Format,
+		DryRun:          row.DryRun,
+		Status:          row.Status,
+		TotalRows:       int(row.TotalRows),
+		ProcessedRows:   int(row.ProcessedRows),
+		CreatedProducts: int(row.CreatedProducts),
+		FailedRows:      int(row.FailedRows),
+		Errors:          entity.ImportRowErrors{},
+		FinishedAt:      row.FinishedAt,
+		CreatedAt:       row.CreatedAt,
+	}
+	for _, rowError := range row.Errors {
+		job.Errors = append(job.Errors, entity.ImportRowError{Row: int(rowError.Row), Field: rowError.Field, Message: rowError.Message})
+	}
+	return job, nil
+}
+
+func (store *MemoryMerchantStore) GetCatalog(ctx context.Context, merchantId string) ([]*entity.CatalogRow, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	var products []*memory.Product
+	for _, product := range store.db.Products {
+		if product.MerchantID == merchantId && product.DeletedAt == nil {
+			products = append(products, product)
+		}
+	}
+	sort.Slice(products, func(i, j int) bool {
+		if products[i].ProductName != products[j].ProductName {
+			return products[i].ProductName < products[j].ProductName
+		}
+		return products[i].ID < products[j].ID
+	})
+
+	catalog := []*entity.CatalogRow{}
+	for _, product := range products {
+		for _, inventory := range store.db.Variants(product.ID) {
+			row := &entity.CatalogRow{
+				ProductName:      product.ProductName,
+				Description:      product.Description,
+				CategoryID:       product.CategoryID,
+				Price:            product.Price,
+				Options:          map[string]string{},
+				VariantPrice:     inventory.Price,
+				Quantity:         int(inventory.Quantity),
+				ImageURLs:        append([]string(nil), product.Images...),
+				VariantImageURLs: append([]string(nil), inventory.Images...),
+			}
+			if inventory.SKU != nil {
+				row.SKU = *inventory.SKU
+			}
+			for option, value := range inventory.Options {
+				row.Options[option] = value
+			}
+			if len(product.Attributes) != 0 {
+				row.Attributes = map[string]string{}
+				for name, value := range product.Attributes {
+					row.Attributes[name] = value
+				}
+			}
+			catalog = append(catalog, row)
+		}
+	}
+	return catalog, nil
+}
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/grpc_api/merchant_service/db/entity"
+)
+
+func (store *MemoryMerchantStore) GetProductCategory(ctx context.Context, categoryId string) (*entity.ProductCategory, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	category, ok := store.db.Categories[categoryId]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	var ancestors []string
+	for id := range store.db.Categories {
+		if store.db.InSubtree(categoryId, id) {
+			ancestors = append(ancestors, id)
+		}
+	}
+	sort.Slice(ancestors, func(i, j int) bool {
+		return len(store.db.Categories[ancestors[i]].Path) < len(store.db.Categories[ancestors[j]].Path)
+	})
+
+	res := &entity.ProductCategory{ID: category.ID, ArchivedAt: category.ArchivedAt}
+	for _, id := range ancestors {
+		for _, attribute := range store.db.Categories[id].Attributes {
+			res.Attributes = append(res.Attributes, entity.CategoryAttribute{
+				Name:     attribute.Name,
+				Type:     attribute.Type,
+				Required: attribute.Required,
+				Values:   append([]string(nil), attribute.Values...),
+			})
+		}
+	}
+	return res, nil
+}
+
+func (store *MemoryMerchantStore) AttachCategoryImage(ctx context.Context, sessionId, categoryId, key string) (*string, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	session, err := store.pendingUpload(sessionId)
+	if err != nil {
+		return nil, err
+	}
+	category, ok := store.db.Categories[categoryId]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	previous := category.Image
+	now := time.Now()
+	image := key
+	category.Image = &image
+	category.UpdatedAt = now
+	session.Status = entity.UploadAttached
+	session.UpdatedAt = now
+	return previous, nil
+}
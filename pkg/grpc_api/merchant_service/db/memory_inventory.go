@@ -0,0 +1,164 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/akmal4410/gestapo/internal/database/memory"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/merchant_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/utils"
+)
+
+func (store *MemoryMerchantStore) GetVariant(ctx context.Context, variantId string) (*entity.InventoryRes, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	inventory, ok := store.db.Inventories[variantId]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	product, ok := store.db.Products[inventory.ProductID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return inventoryEntity(product, inventory), nil
+}
+
+func (store *MemoryMerchantStore) GetInventory(ctx context.Context, productId string) ([]*entity.InventoryRes, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	product, ok := store.db.Products[productId]
+	if !ok {
+		return []*entity.InventoryRes{}, nil
+	}
+	inventories := []*entity.InventoryRes{}
+	for _, inventory := range store.db.Variants(productId) {
+		inventories = append(inventories, inventoryEntity(product, inventory))
+	}
+	return inventories, nil
+}
+
+func (store *MemoryMerchantStore) GetLowStockVariants(ctx context.Context, merchantId string) ([]*entity.InventoryRes, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	inventories := []*entity.InventoryRes{}
+	for _, product := range store.db.Products {
+		if product.MerchantID != merchantId || product.DeletedAt != nil {
+			continue
+		}
+		for _, inventory := range store.db.Variants(product.ID) {
+			if res := inventoryEntity(product, inventory); res.LowStock() {
+				inventories = append(inventories, res)
+			}
+		}
+	}
+	sort.SliceStable(inventories, func(i, j int) bool {
+		if inventories[i].Quantity != inventories[j].Quantity {
+			return inventories[i].Quantity < inventories[j].Quantity
+		}
+		return inventories[i].ProductName < inventories[j].ProductName
+	})
+	return inventories, nil
+}
+
+func inventoryEntity(product *memory.Product, inventory *memory.Inventory) *entity.InventoryRes {
+	options := map[string]string{}
+	for option, value := range inventory.Options {
+		options[option] = value
+	}
+	return &entity.InventoryRes{
+		ID:                inventory.ID,
+		ProductID:         product.ID,
+		ProductName:       product.ProductName,
+		MerchantID:        product.MerchantID,
+		SKU:               inventory.SKU,
+		Options:           options,
+		Quantity:          inventory.Quantity,
+		LowStockThreshold: inventory.LowStockThreshold,
+	}
+}
+
+func (store *MemoryMerchantStore) MoveStock(ctx context.Context, req *entity.StockMovementReq) (*entity.StockMovement, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	inventory, ok := store.db.Inventories[req.InventoryID]
+	if !ok || inventory.Quantity+req.Quantity < 0 {
+		return nil, ErrInsufficientStock
+	}
+	if req.Type == utils.StockReturn {
+		if err := store.checkReturnable(req); err != nil {
+			return nil, err
+		}
+	}
+	userId := req.UserID
+	movement := store.db.MoveStock(inventory, req.Type, req.Quantity, req.Reason, req.OrderItemID, &userId, time.Now())
+	return stockMovementEntity(movement), nil
+}
+
+func stockMovementEntity(movement *memory.StockMovement) *entity.StockMovement {
+	return &entity.StockMovement{
+		ID:          movement.ID,
+		InventoryID: movement.InventoryID,
+		Type:        movement.Type,
+		Quantity:    movement.Quantity,
+		Balance:     movement.Balance,
+		Reason:      movement.Reason,
+		OrderItemID: movement.OrderItemID,
+		CreatedAt:   movement.CreatedAt,
+	}
+}
+
+// checkReturnable checks the return is not above the quantity of the order item
+// not returned yet. The caller must hold the lock.
+func (store *MemoryMerchantStore) checkReturnable(req *entity.StockMovementReq) error {
+	item, ok := store.db.OrderItems[*req.OrderItemID]
+	if !ok || item.InventoryID == nil || *item.InventoryID != req.InventoryID {
+		return sql.ErrNoRows
+	}
+	quantity := item.Quantity
+	for _, movement := range store.db.StockMovements {
+		if movement.Type == utils.StockReturn && movement.OrderItemID != nil && *movement.OrderItemID == item.ID {
+			quantity -= movement.Quantity
+		}
+	}
+	if req.Quantity > quantity {
+		return ErrAboveReturnable
+	}
+	return nil
+}
+
+func (store *MemoryMerchantStore) SetLowStockThreshold(ctx context.Context, variantId string, threshold *int32) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	inventory, ok := store.db.Inventories[variantId]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	inventory.LowStockThreshold = threshold
+	inventory.UpdatedAt = time.Now()
+	return nil
+}
+
+func (store *MemoryMerchantStore) GetStockMovements(ctx context.Context, variantId string, limit, offset int) ([]*entity.StockMovement, int64, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	var movements []*memory.StockMovement
+	for _, movement := range store.db.StockMovements {
+		if movement.InventoryID == variantId {
+			movements = append(movements, movement)
+		}
+	}
+	sort.Slice(movements, func(i, j int) bool {
+		if !movements[i].CreatedAt.Equal(movements[j].CreatedAt) {
+			return movements[i].CreatedAt.After(movements[j].CreatedAt)
+		}
+		return movements[i].ID < movements[j].ID
+	})
+
+	res := []*entity.StockMovement{}
+	for i := offset; i < len(movements) && i < offset+limit; i++ {
+		res = append(res, stockMovementEntity(movements[i]))
+	}
+	return res, int64(len(movements)), nil
+}
@@ -0,0 +1,41 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	product_entity "github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/utils"
+)
+
+func (store *MemoryMerchantStore) SetProductState(ctx context.Context, from string, state *product_entity.ProductState) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	product, ok := store.db.Products[state.ProductID]
+	if !ok || product.DeletedAt != nil || product.Status != from {
+		return ErrStatusChanged
+	}
+	product.Status = state.Status
+	product.PublishAt = state.PublishAt
+	product.UnpublishAt = state.UnpublishAt
+	product.StatusReason = state.StatusReason
+	product.UpdatedAt = time.Now()
+	return nil
+}
+
+func (store *MemoryMerchantStore) ArchiveExpiredProducts(ctx context.Context) (int64, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	now := time.Now()
+	var archived int64
+	for _, product := range store.db.Products {
+		if product.Status != utils.ProductPublished || product.DeletedAt != nil ||
+			product.UnpublishAt == nil || product.UnpublishAt.After(now) {
+			continue
+		}
+		product.Status = utils.ProductArchived
+		product.UpdatedAt = now
+		archived++
+	}
+	return archived, nil
+}
@@ -0,0 +1,405 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/akmal4410/gestapo/internal/database/memory"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/merchant_service/db/entity"
+	product_entity "github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	user_entity "github.com/akmal4410/gestapo/pkg/grpc_api/user_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+// MemoryMerchantStore is the in-memory MerchantRepository.
+type MemoryMerchantStore struct {
+	db *memory.Database
+}
+
+func NewMemoryMerchantStore(db *memory.Database) *MemoryMerchantStore {
+	return &MemoryMerchantStore{db: db}
+}
+
+func (store *MemoryMerchantStore) CheckDataExist(ctx context.Context, table, column, value string) (bool, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	return store.db.Exists(table, column, value)
+}
+
+func (store *MemoryMerchantStore) GetProfile(ctx context.Context, userId string) (*entity.GetMerchantRes, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	user, ok := store.db.Users[userId]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &entity.GetMerchantRes{
+		ID:           user.ID,
+		ProfileImage: user.ProfileImage,
+		FullName:     user.FullName,
+		UserName:     user.UserName,
+		Phone:        user.Phone,
+		Email:        user.Email,
+		DOB:          user.DOB,
+		Gender:       user.Gender,
+		UserType:     user.UserType,
+	}, nil
+}
+
+func (store *MemoryMerchantStore) UpdateProfile(ctx context.Context, id string, req *entity.EditMerchantReq) error {
+	dob, err := utils.ParseDate(req.DOB)
+	if err != nil {
+		return err
+	}
+
+	store.db.Lock()
+	defer store.db.Unlock()
+	user, ok := store.db.Users[id]
+	if !ok {
+		return fmt.Errorf("couldnot update the user")
+	}
+	profileImage, fullName, gender := req.ProfileImage, req.FullName, req.Gender
+	user.ProfileImage = &profileImage
+	user.FullName = &fullName
+	user.DOB = dob
+	user.Gender = &gender
+	user.UpdatedAt = time.Now()
+	return nil
+}
+
+func (store *MemoryMerchantStore) InsertProduct(ctx context.Context, userId, productId string, req *entity.AddProductReq) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	if _, ok := store.db.Products[productId]; ok {
+		return fmt.Errorf("duplicate key value violates unique constraint on id")
+	}
+	for _, variant := range req.Variants {
+		for _, inventory := range store.db.Inventories {
+			if variant.SKU != "" && inventory.SKU != nil && *inventory.SKU == variant.SKU {
+				return ErrDuplicateSKU
+			}
+		}
+	}
+	now := time.Now()
+	store.db.Products[productId] = &memory.Product{
+		ID:          productId,
+		MerchantID:  userId,
+		CategoryID:  req.CategoryId,
+		ProductName: req.ProductName,
+		Description: req.Description,
+		Images:      append([]string(nil), req.ProductImages...),
+		Size:        append([]float64(nil), req.Sizes...),
+		OptionTypes: append([]string(nil), req.OptionTypes...),
+		Price:       req.Price,
+		Attributes:  map[string]string{},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Status:      utils.ProductDraft,
+	}
+	for name, value := range req.Attributes {
+		store.db.Products[productId].Attributes[name] = value
+	}
+	store.db.RecordPrices(productId, now)
+	for _, variant := range req.Variants {
+		size, err := product_entity.Options(variant.Options).Size()
+		if err != nil {
+			return err
+		}
+		options := map[string]string{}
+		for option, value := range variant.Options {
+			options[option] = value
+		}
+		id := uuid.NewString()
+		inventory := &memory.Inventory{
+			ID:        id,
+			ProductID: productId,
+			Options:   options,
+			Size:      size,
+			Price:     variant.Price,
+			Images:    append([]string(nil), variant.Images...),
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if variant.SKU != "" {
+			sku := variant.SKU
+			inventory.SKU = &sku
+		}
+		store.db.Inventories[id] = inventory
+		if variant.Quantity > 0 {
+			store.db.MoveStock(inventory, utils.StockRestock, int32(variant.Quantity), initialStockReason, nil, &userId, now)
+		}
+	}
+	return nil
+}
+
+func (store *MemoryMerchantStore) UpdateProduct(ctx context.Context, id string, req *entity.EditProductReq) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	product, ok := store.db.Products[id]
+	if !ok {
+		return fmt.Errorf("couldnot update the products")
+	}
+	product.ProductName = req.ProductName
+	product.Description = req.Description
+	product.Images = append([]string(nil), req.ProductImages...)
+	product.Price = req.Price
+	product.UpdatedAt = time.Now()
+	store.db.RecordPrices(id, product.UpdatedAt)
+	return nil
+}
+
+func (store *MemoryMerchantStore) GetProductById(ctx context.Context, productId string) (*product_entity.GetProductRes, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	product, ok := store.db.Products[productId]
+	if !ok || product.DeletedAt != nil {
+		return nil, sql.ErrNoRows
+	}
+	merchantID, description, productStatus := product.MerchantID, product.Description, product.Status
+	sizes := append([]float64(nil), product.Size...)
+	return &product_entity.GetProductRes{
+		ID:            product.ID,
+		MerchantID:    &merchantID,
+		ProductImages: append([]string(nil), product.Images...),
+		ProductName:   product.ProductName,
+		Description:   &description,
+		CategoryName:  store.db.CategoryName(product),
+		Size:          &sizes,
+		Price:         product.Price,
+		DiscountPrice: store.db.DiscountPrice(product),
+		Status:        &productStatus,
+		PublishAt:     product.PublishAt,
+		UnpublishAt:   product.UnpublishAt,
+		StatusReason:  product.StatusReason,
+	}, nil
+}
+
+func (store *MemoryMerchantStore) DeleteProduct(ctx context.Context, productId string) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	product, ok := store.db.Products[productId]
+	if !ok || product.DeletedAt != nil {
+		return fmt.Errorf("could not delete the product")
+	}
+	now := time.Now()
+	product.DeletedAt = &now
+	product.UpdatedAt = now
+	return nil
+}
+
+func (store *MemoryMerchantStore) AddProductDiscount(ctx context.Context, req *entity.AddDiscountReq) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	product, ok := store.db.Products[req.ProductId]
+	if !ok {
+		return fmt.Errorf("couldnot update the products")
+	}
+	if req.CardColor == "" {
+		req.CardColor = "0xFF808080"
+	}
+	now := time.Now()
+	id := uuid.NewString()
+	store.db.Discounts[id] = &memory.Discount{
+		ID:          id,
+		MerchantID:  req.MerchantId,
+		Name:        req.DiscountName,
+		Description: req.Description,
+		Percent:     req.Percentage,
+		CardColor:   req.CardColor,
+		StartTime:   req.StartTime,
+		EndTime:     req.EndTime,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	product.DiscountID = &id
+	product.UpdatedAt = now
+	store.db.RecordPrices(product.ID, now)
+	return nil
+}
+
+func (store *MemoryMerchantStore) EditProductDiscount(ctx context.Context, discountId string, req *entity.EditDiscountReq) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	discount, ok := store.db.Discounts[discountId]
+	if !ok {
+		return fmt.Errorf("couldnot update the discount")
+	}
+	if req.DiscountName != nil {
+		discount.Name = *req.DiscountName
+	}
+	if req.Description != nil {
+		discount.Description = *req.Description
+	}
+	discount.Percent = req.Percentage
+	if req.CardColor != nil {
+		discount.CardColor = *req.CardColor
+	}
+	if req.StartTime != nil {
+		discount.StartTime = *req.StartTime
+	}
+	if req.EndTime != nil {
+		discount.EndTime = *req.EndTime
+	}
+	discount.UpdatedAt = time.Now()
+	for _, product := range store.db.Products {
+		if product.DiscountID != nil && *product.DiscountID == discountId {
+			store.db.RecordPrices(product.ID, discount.UpdatedAt)
+		}
+	}
+	return nil
+}
+
+func (store *MemoryMerchantStore) GetAllDiscount(ctx context.Context, merchantId *string) ([]*user_entity.DiscountRes, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	var discounts []*user_entity.DiscountRes
+	for _, product := range store.db.Products {
+		if product.DiscountID == nil || !memory.Listed(product) {
+			continue
+		}
+		discount, ok := store.db.Discounts[*product.DiscountID]
+		if !ok || !discount.EndTime.After(time.Now()) {
+			continue
+		}
+		if merchantId != nil && discount.MerchantID != *merchantId {
+			continue
+		}
+		discounts = append(discounts, &user_entity.DiscountRes{
+			ProductID:    product.ID,
+			Name:         discount.Name,
+			Description:  discount.Description,
+			Percentage:   discount.Percent,
+			ProductImage: memory.FirstImage(product),
+		})
+	}
+	sort.SliceStable(discounts, func(i, j int) bool {
+		return discounts[i].Percentage > discounts[j].Percentage
+	})
+	return discounts, nil
+}
+
+func (store *MemoryMerchantStore) CreateUploadSession(ctx context.Context, session *entity.UploadSession) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	if _, ok := store.db.UploadSessions[session.ID]; ok {
+		return fmt.Errorf("duplicate key value violates unique constraint on id")
+	}
+	store.db.UploadSessions[session.ID] = &memory.UploadSession{
+		ID:          session.ID,
+		UserID:      session.UserID,
+		Purpose:     session.Purpose,
+		TargetID:    session.TargetID,
+		Key:         session.Key,
+		ContentType: session.ContentType,
+		Size:        session.Size,
+		Status:      session.Status,
+		ExpiresAt:   session.ExpiresAt,
+		CreatedAt:   session.CreatedAt,
+		UpdatedAt:   session.CreatedAt,
+	}
+	return nil
+}
+
+func uploadSessionEntity(session *memory.UploadSession) *entity.UploadSession {
+	return &entity.UploadSession{
+		ID:          session.ID,
+		UserID:      session.UserID,
+		Purpose:     session.Purpose,
+		TargetID:    session.TargetID,
+		Key:         session.Key,
+		ContentType: session.ContentType,
+		Size:        session.Size,
+		Status:      session.Status,
+		ExpiresAt:   session.ExpiresAt,
+		CreatedAt:   session.CreatedAt,
+	}
+}
+
+func (store *MemoryMerchantStore) GetUploadSession(ctx context.Context, sessionId string) (*entity.UploadSession, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	session, ok := store.db.UploadSessions[sessionId]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return uploadSessionEntity(session), nil
+}
+
+// pendingUpload returns the session if it can still be attached. The caller must hold the lock.
+func (store *MemoryMerchantStore) pendingUpload(sessionId string) (*memory.UploadSession, error) {
+	session, ok := store.db.UploadSessions[sessionId]
+	if !ok || session.Status != entity.UploadPending {
+		return nil, ErrUploadNotPending
+	}
+	return session, nil
+}
+
+func (store *MemoryMerchantStore) AttachProductImage(ctx context.Context, sessionId, productId, key string) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	session, err := store.pendingUpload(sessionId)
+	if err != nil {
+		return err
+	}
+	product, ok := store.db.Products[productId]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	now := time.Now()
+	product.Images = append(product.Images, key)
+	product.UpdatedAt = now
+	session.Status = entity.UploadAttached
+	session.UpdatedAt = now
+	return nil
+}
+
+func (store *MemoryMerchantStore) AttachProfileImage(ctx context.Context, sessionId, userId, key string) (*string, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	session, err := store.pendingUpload(sessionId)
+	if err != nil {
+		return nil, err
+	}
+	user, ok := store.db.Users[userId]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	previous := user.ProfileImage
+	if previous != nil && *previous == "" {
+		previous = nil
+	}
+	now := time.Now()
+	profileImage := key
+	user.ProfileImage = &profileImage
+	user.UpdatedAt = now
+	session.Status = entity.UploadAttached
+	session.UpdatedAt = now
+	return previous, nil
+}
+
+func (store *MemoryMerchantStore) GetExpiredUploadSessions(ctx context.Context, before time.Time, limit int) ([]*entity.UploadSession, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	var sessions []*entity.UploadSession
+	for _, session := range store.db.UploadSessions {
+		if session.Status == entity.UploadPending && session.ExpiresAt.Before(before) {
+			sessions = append(sessions, uploadSessionEntity(session))
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].ExpiresAt.Before(sessions[j].ExpiresAt) })
+	if len(sessions) > limit {
+		sessions = sessions[:limit]
+	}
+	return sessions, nil
+}
+
+func (store *MemoryMerchantStore) DeleteUploadSession(ctx context.Context, sessionId string) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	delete(store.db.UploadSessions, sessionId)
+	return nil
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"time"
 
@@ -9,10 +10,18 @@ import (
 	"github.com/akmal4410/gestapo/pkg/grpc_api/merchant_service/db/entity"
 	product_entity "github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
 	user_entity "github.com/akmal4410/gestapo/pkg/grpc_api/user_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/utils"
 	"github.com/google/uuid"
 	"github.com/lib/pq"
 )
 
+// uniqueViolation is the code of the Postgres errors breaking a unique constraint,
+// the SKU is the only unique column of inventories besides the id.
+const uniqueViolation = "23505"
+
+// initialStockReason is the reason of the movement recording the stock a variant is added with.
+const initialStockReason = "Initial stock"
+
 type MerchantStore struct {
 	storage *database.Storage
 }
@@ -22,9 +31,9 @@ func NewMerchantStore(storage *database.Storage) *MerchantStore {
 
 }
 
-func (store MerchantStore) CheckDataExist(table, column, value string) (bool, error) {
+func (store MerchantStore) CheckDataExist(ctx context.Context, table, column, value string) (bool, error) {
 	checkQuery := fmt.Sprintf(`SELECT * FROM %s WHERE %s = $1;`, table, column)
-	res, err := store.storage.DB.Exec(checkQuery, value)
+	res, err := store.storage.DB.ExecContext(ctx, checkQuery, value)
 	if err != nil {
 		return false, err
 	}
@@ -37,12 +46,12 @@ func (store MerchantStore) CheckDataExist(table, column, value string) (bool, er
 	return result != 0, nil
 }
 
-func (store *MerchantStore) GetProfile(userId string) (*entity.GetMerchantRes, error) {
+func (store *MerchantStore) GetProfile(ctx context.Context, userId string) (*entity.GetMerchantRes, error) {
 	selectQuery := `
 	SELECT id, profile_image, full_name, user_name, phone, email, dob, gender, user_type 
 	FROM user_data WHERE id = $1;
 	`
-	rows := store.storage.DB.QueryRow(selectQuery, userId)
+	rows := store.storage.DB.QueryRowContext(ctx, selectQuery, userId)
 	if rows.Err() != nil {
 		return nil, rows.Err()
 	}
@@ -64,14 +73,14 @@ func (store *MerchantStore) GetProfile(userId string) (*entity.GetMerchantRes, e
 	return &user, nil
 }
 
-func (store *MerchantStore) UpdateProfile(id string, req *entity.EditMerchantReq) error {
+func (store *MerchantStore) UpdateProfile(ctx context.Context, id string, req *entity.EditMerchantReq) error {
 	updatedAt := time.Now()
 
 	updateQuery := `UPDATE user_data
 	SET profile_image = $2, full_name = $3, dob = $4, gender = $5, updated_at = $6
 	WHERE id = $1;`
 
-	res, err := store.storage.DB.Exec(updateQuery, id, req.ProfileImage, req.FullName, req.DOB, req.Gender, updatedAt)
+	res, err := store.storage.DB.ExecContext(ctx, updateQuery, id, req.ProfileImage, req.FullName, req.DOB, req.Gender, updatedAt)
 	if err != nil {
 		return err
 	}
@@ -85,12 +94,10 @@ func (store *MerchantStore) UpdateProfile(id string, req *entity.EditMerchantReq
 	return nil
 }
 
-func (store *MerchantStore) InsertProduct(userId, productId string, req *entity.AddProductReq) error {
+func (store *MerchantStore) InsertProduct(ctx context.Context, userId, productId string, req *entity.AddProductReq) error {
 	createdAt := time.Now()
 	updatedAt := time.Now()
 
-	ctx := context.Background()
-
 	tx, err := store.storage.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
@@ -98,41 +105,67 @@ func (store *MerchantStore) InsertProduct(userId, productId string, req *entity.
 
 	insertProductQuery := `
 		INSERT INTO products
-		(id, merchent_id, category_id, product_name, description, images, size, price, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+		(id, merchent_id, category_id, product_name, description, images, size, option_types, price, attributes, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13);
 		`
 	productImages := pq.StringArray(req.ProductImages)
 	productSizes := pq.Float64Array(req.Sizes)
+	optionTypes := pq.StringArray(req.OptionTypes)
+	attributes := product_entity.Options(req.Attributes)
 
-	_, err = tx.Exec(insertProductQuery, productId, userId, req.CategoryId, req.ProductName, req.Description, productImages, productSizes, req.Price, createdAt, updatedAt)
+	_, err = tx.ExecContext(ctx, insertProductQuery, productId, userId, req.CategoryId, req.ProductName, req.Description, productImages, productSizes, optionTypes, req.Price, attributes, utils.ProductDraft, createdAt, updatedAt)
 	if err != nil {
 		tx.Rollback()
 		return err
 	}
 
-	for _, size := range req.Sizes {
+	for _, variant := range req.Variants {
 		inventoryId, err := uuid.NewRandom()
 		if err != nil {
 			tx.Rollback()
 			return err
 		}
+		options := product_entity.Options(variant.Options)
+		size, err := options.Size()
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		var sku *string
+		if variant.SKU != "" {
+			sku = &variant.SKU
+		}
 		insertInventoryQuery := `
 		INSERT INTO inventories
-		(id, product_id, size, quantity, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6);
+		(id, product_id, sku, options, size, price, images, quantity, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
 		`
-		_, err = tx.Exec(insertInventoryQuery, inventoryId.String(), productId, size, req.Quantity, createdAt, updatedAt)
+		_, err = tx.ExecContext(ctx, insertInventoryQuery, inventoryId.String(), productId, sku, options, size, variant.Price, pq.StringArray(variant.Images), variant.Quantity, createdAt, updatedAt)
 		if err != nil {
 			tx.Rollback()
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+				return ErrDuplicateSKU
+			}
 			return err
 		}
+		if variant.Quantity > 0 {
+			insertMovementQuery := `
+			INSERT INTO stock_movements
+			(id, inventory_id, product_id, type, quantity, balance, reason, user_id, created_at)
+			VALUES ($1, $2, $3, $4, $5, $5, $6, $7, $8);
+			`
+			_, err = tx.ExecContext(ctx, insertMovementQuery, uuid.NewString(), inventoryId.String(), productId, utils.StockRestock, variant.Quantity, initialStockReason, userId, createdAt)
+			if err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
 	}
 
-	tx.Commit()
-	return nil
+	return tx.Commit()
 }
 
-func (store *MerchantStore) UpdateProduct(id string, req *entity.EditProductReq) error {
+func (store *MerchantStore) UpdateProduct(ctx context.Context, id string, req *entity.EditProductReq) error {
 	updateQuery := `
 	UPDATE products
 	SET product_name = $2, description = $3, images = $4, price = $5, updated_at = $6
@@ -141,7 +174,7 @@ func (store *MerchantStore) UpdateProduct(id string, req *entity.EditProductReq)
 	updatedAt := time.Now()
 	productImages := pq.StringArray(req.ProductImages)
 
-	res, err := store.storage.DB.Exec(updateQuery, id, req.ProductName, req.Description, productImages, req.Price, updatedAt)
+	res, err := store.storage.DB.ExecContext(ctx, updateQuery, id, req.ProductName, req.Description, productImages, req.Price, updatedAt)
 	if err != nil {
 		return err
 	}
@@ -155,7 +188,7 @@ func (store *MerchantStore) UpdateProduct(id string, req *entity.EditProductReq)
 	return nil
 }
 
-func (store *MerchantStore) GetProductById(productId string) (*product_entity.GetProductRes, error) {
+func (store *MerchantStore) GetProductById(ctx context.Context, productId string) (*product_entity.GetProductRes, error) {
 	selectQuery := `
 	SELECT
     p.id AS id,
@@ -170,7 +203,8 @@ func (store *MerchantStore) GetProductById(productId string) (*product_entity.Ge
 		THEN p.price - (p.price * d.percent / 100) 
         ELSE NULL
     END AS discount_price,
-    p.images AS product_images
+    p.images AS product_images,
+    p.status, p.publish_at, p.unpublish_at, p.status_reason
 	FROM
     products p
 	LEFT JOIN
@@ -178,9 +212,9 @@ func (store *MerchantStore) GetProductById(productId string) (*product_entity.Ge
 	LEFT JOIN
     discounts d ON p.discount_id = d.id
 	WHERE 
-	p.id = $1;
+	p.id = $1 AND p.deleted_at IS NULL;
 	`
-	rows := store.storage.DB.QueryRow(selectQuery, productId)
+	rows := store.storage.DB.QueryRowContext(ctx, selectQuery, productId)
 	if rows.Err() != nil {
 		return nil, rows.Err()
 	}
@@ -200,6 +234,10 @@ func (store *MerchantStore) GetProductById(productId string) (*product_entity.Ge
 		&product.Price,
 		&product.DiscountPrice,
 		&images,
+		&product.Status,
+		&product.PublishAt,
+		&product.UnpublishAt,
+		&product.StatusReason,
 	)
 	product.ProductImages = []string(images)
 	// Convert pq.Float64Array to []float64
@@ -215,17 +253,11 @@ func (store *MerchantStore) GetProductById(productId string) (*product_entity.Ge
 	return &product, nil
 }
 
-func (store *MerchantStore) DeleteProduct(productId string) error {
-	deleteQuery := `
-        DELETE FROM products
-        USING inventories
-        WHERE 
-		products.id = $1
-        AND 
-		products.inventory_id = inventories.id;
-    `
-
-	res, err := store.storage.DB.Exec(deleteQuery, productId)
+// DeleteProduct soft deletes the product, its orders and its reviews keep it.
+func (store *MerchantStore) DeleteProduct(ctx context.Context, productId string) error {
+	deleteQuery := `UPDATE products SET deleted_at = $2, updated_at = $2 WHERE id = $1 AND deleted_at IS NULL;`
+
+	res, err := store.storage.DB.ExecContext(ctx, deleteQuery, productId, time.Now())
 	if err != nil {
 		return err
 	}
@@ -239,8 +271,7 @@ func (store *MerchantStore) DeleteProduct(productId string) error {
 	return nil
 }
 
-func (store *MerchantStore) AddProductDiscount(req *entity.AddDiscountReq) error {
-	ctx := context.Background()
+func (store *MerchantStore) AddProductDiscount(ctx context.Context, req *entity.AddDiscountReq) error {
 	tx, err := store.storage.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
@@ -263,7 +294,7 @@ func (store *MerchantStore) AddProductDiscount(req *entity.AddDiscountReq) error
 	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
 	`
 
-	res, err := tx.Exec(insertQuery, discountId.String(), req.MerchantId, req.DiscountName, req.Description, req.Percentage, req.CardColor, req.StartTime, req.EndTime, createdAt, updatedAt)
+	res, err := tx.ExecContext(ctx, insertQuery, discountId.String(), req.MerchantId, req.DiscountName, req.Description, req.Percentage, req.CardColor, req.StartTime, req.EndTime, createdAt, updatedAt)
 	if err != nil {
 		tx.Rollback()
 		return err
@@ -282,7 +313,7 @@ func (store *MerchantStore) AddProductDiscount(req *entity.AddDiscountReq) error
 	SET discount_id = $2, updated_at = $3 
 	WHERE id = $1;
 	`
-	res, err = tx.Exec(updateQuery, req.ProductId, discountId.String(), updatedAt)
+	res, err = tx.ExecContext(ctx, updateQuery, req.ProductId, discountId.String(), updatedAt)
 	if err != nil {
 		tx.Rollback()
 		return err
@@ -296,11 +327,10 @@ func (store *MerchantStore) AddProductDiscount(req *entity.AddDiscountReq) error
 	if n == 0 {
 		return fmt.Errorf("couldnot update the products")
 	}
-	tx.Commit()
-	return nil
+	return tx.Commit()
 }
 
-func (store *MerchantStore) EditProductDiscount(discountId string, req *entity.EditDiscountReq) error {
+func (store *MerchantStore) EditProductDiscount(ctx context.Context, discountId string, req *entity.EditDiscountReq) error {
 	updateQuery := `
 	UPDATE discounts
 	SET name = COALESCE($2, name),
@@ -313,7 +343,7 @@ func (store *MerchantStore) EditProductDiscount(discountId string, req *entity.E
 	WHERE id = $1;
 	`
 	updatedAt := time.Now()
-	res, err := store.storage.DB.Exec(updateQuery, discountId, req.DiscountName, req.Description, req.Percentage, req.CardColor, req.StartTime, req.EndTime, updatedAt)
+	res, err := store.storage.DB.ExecContext(ctx, updateQuery, discountId, req.DiscountName, req.Description, req.Percentage, req.CardColor, req.StartTime, req.EndTime, updatedAt)
 	if err != nil {
 		return err
 	}
@@ -327,7 +357,7 @@ func (store *MerchantStore) EditProductDiscount(discountId string, req *entity.E
 	return nil
 }
 
-func (store *MerchantStore) GetAllDiscount(merchantId *string) ([]*user_entity.DiscountRes, error) {
+func (store *MerchantStore) GetAllDiscount(ctx context.Context, merchantId *string) ([]*user_entity.DiscountRes, error) {
 	selectQuery := `
 	SELECT 
     p.id AS product_id,
@@ -337,11 +367,11 @@ func (store *MerchantStore) GetAllDiscount(merchantId *string) ([]*user_entity.D
     p.images[1] AS image
 	FROM products p
 	JOIN discounts d ON p.discount_id = d.id
-	WHERE  d.end_time > NOW() AND d.merchent_id = COALESCE($1, d.merchent_id)
+	WHERE  d.end_time > NOW() AND listed(p) AND d.merchent_id = COALESCE($1, d.merchent_id)
 	ORDER BY d.percent DESC;
 	`
 
-	rows, err := store.storage.DB.Query(selectQuery, merchantId)
+	rows, err := store.storage.DB.QueryContext(ctx, selectQuery, merchantId)
 	if err != nil {
 		return nil, err
 	}
@@ -367,3 +397,165 @@ func (store *MerchantStore) GetAllDiscount(merchantId *string) ([]*user_entity.D
 	}
 	return discounts, nil
 }
+
+func (store *MerchantStore) CreateUploadSession(ctx context.Context, session *entity.UploadSession) error {
+	insertQuery := `
+	INSERT INTO upload_sessions
+	(id, user_id, purpose, target_id, key, content_type, size, status, expires_at, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);
+	`
+	_, err := store.storage.DB.ExecContext(ctx, insertQuery, session.ID, session.UserID, session.Purpose, session.TargetID,
+		session.Key, session.ContentType, session.Size, session.Status, session.ExpiresAt, session.CreatedAt, session.CreatedAt)
+	return err
+}
+
+func (store *MerchantStore) GetUploadSession(ctx context.Context, sessionId string) (*entity.UploadSession, error) {
+	selectQuery := `
+	SELECT id, user_id, purpose, target_id, key, content_type, size, status, expires_at, created_at
+	FROM upload_sessions WHERE id = $1;
+	`
+	row := store.storage.DB.QueryRowContext(ctx, selectQuery, sessionId)
+	var session entity.UploadSession
+	err := row.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.Purpose,
+		&session.TargetID,
+		&session.Key,
+		&session.ContentType,
+		&session.Size,
+		&session.Status,
+		&session.ExpiresAt,
+		&session.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// markUploadAttached moves the session out of PENDING, so it is attached only once.
+func markUploadAttached(ctx context.Context, tx *sql.Tx, sessionId string) error {
+	updateQuery := `
+	UPDATE upload_sessions SET status = $2, updated_at = $3
+	WHERE id = $1 AND status = $4;
+	`
+	res, err := tx.ExecContext(ctx, updateQuery, sessionId, entity.UploadAttached, time.Now(), entity.UploadPending)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrUploadNotPending
+	}
+	return nil
+}
+
+func (store *MerchantStore) AttachProductImage(ctx context.Context, sessionId, productId, key string) error {
+	tx, err := store.storage.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := markUploadAttached(ctx, tx, sessionId); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	updateQuery := `
+	UPDATE products SET images = array_append(images, $2), updated_at = $3
+	WHERE id = $1;
+	`
+	res, err := tx.ExecContext(ctx, updateQuery, productId, key, time.Now())
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if n == 0 {
+		tx.Rollback()
+		return sql.ErrNoRows
+	}
+	return tx.Commit()
+}
+
+func (store *MerchantStore) AttachProfileImage(ctx context.Context, sessionId, userId, key string) (*string, error) {
+	tx, err := store.storage.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := markUploadAttached(ctx, tx, sessionId); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var previous sql.NullString
+	selectQuery := `SELECT profile_image FROM user_data WHERE id = $1 FOR UPDATE;`
+	if err := tx.QueryRowContext(ctx, selectQuery, userId).Scan(&previous); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	updateQuery := `UPDATE user_data SET profile_image = $2, updated_at = $3 WHERE id = $1;`
+	if _, err := tx.ExecContext(ctx, updateQuery, userId, key, time.Now()); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	if !previous.Valid || previous.String == "" {
+		return nil, nil
+	}
+	return &previous.String, nil
+}
+
+func (store *MerchantStore) GetExpiredUploadSessions(ctx context.Context, before time.Time, limit int) ([]*entity.UploadSession, error) {
+	selectQuery := `
+	SELECT id, user_id, purpose, target_id, key, content_type, size, status, expires_at, created_at
+	FROM upload_sessions
+	WHERE status = $1 AND expires_at < $2
+	ORDER BY expires_at
+	LIMIT $3;
+	`
+	rows, err := store.storage.DB.QueryContext(ctx, selectQuery, entity.UploadPending, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var sessions []*entity.UploadSession
+	for rows.Next() {
+		var session entity.UploadSession
+		err := rows.Scan(
+			&session.ID,
+			&session.UserID,
+			&session.Purpose,
+			&session.TargetID,
+			&session.Key,
+			&session.ContentType,
+			&session.Size,
+			&session.Status,
+			&session.ExpiresAt,
+			&session.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, &session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (store *MerchantStore) DeleteUploadSession(ctx context.Context, sessionId string) error {
+	_, err := store.storage.DB.ExecContext(ctx, `DELETE FROM upload_sessions WHERE id = $1;`, sessionId)
+	return err
+}
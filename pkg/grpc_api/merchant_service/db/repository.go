@@ -0,0 +1,88 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/grpc_api/merchant_service/db/entity"
+	product_entity "github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	user_entity "github.com/akmal4410/gestapo/pkg/grpc_api/user_service/db/entity"
+)
+
+// MerchantRepository is the storage used by the merchant service and the REST server of the gateway,
+// implemented by MerchantStore on Postgres and by MemoryMerchantStore in memory.
+type MerchantRepository interface {
+	CheckDataExist(ctx context.Context, table, column, value string) (bool, error)
+	// GetProductCategory returns the category with the attributes of its ancestors
+	GetProductCategory(ctx context.Context, categoryId string) (*entity.ProductCategory, error)
+	GetProfile(ctx context.Context, userId string) (*entity.GetMerchantRes, error)
+	UpdateProfile(ctx context.Context, id string, req *entity.EditMerchantReq) error
+	InsertProduct(ctx context.Context, userId, productId string, req *entity.AddProductReq) error
+	UpdateProduct(ctx context.Context, id string, req *entity.EditProductReq) error
+	// GetProductById returns the product with its lifecycle, unless it is deleted
+	GetProductById(ctx context.Context, productId string) (*product_entity.GetProductRes, error)
+	DeleteProduct(ctx context.Context, productId string) error
+	// SetProductState moves the product from the status to the state, it returns
+	// ErrStatusChanged when the product is not in that status anymore
+	SetProductState(ctx context.Context, from string, state *product_entity.ProductState) error
+	// ArchiveExpiredProducts archives the published products past their unpublish
+	// time and returns how many it archived
+	ArchiveExpiredProducts(ctx context.Context) (int64, error)
+	AddProductDiscount(ctx context.Context, req *entity.AddDiscountReq) error
+	EditProductDiscount(ctx context.Context, discountId string, req *entity.EditDiscountReq) error
+	GetAllDiscount(ctx context.Context, merchantId *string) ([]*user_entity.DiscountRes, error)
+
+	CreateUploadSession(ctx context.Context, session *entity.UploadSession) error
+	GetUploadSession(ctx context.Context, sessionId string) (*entity.UploadSession, error)
+	// AttachProductImage appends the key of the pending session to the images of the product
+	AttachProductImage(ctx context.Context, sessionId, productId, key string) error
+	// AttachProfileImage sets the key of the pending session as the profile image of
+	// the user and returns the key it replaced
+	AttachProfileImage(ctx context.Context, sessionId, userId, key string) (*string, error)
+	// AttachCategoryImage sets the key of the pending session as the image of the
+	// category and returns the key it replaced
+	AttachCategoryImage(ctx context.Context, sessionId, categoryId, key string) (*string, error)
+	GetExpiredUploadSessions(ctx context.Context, before time.Time, limit int) ([]*entity.UploadSession, error)
+	DeleteUploadSession(ctx context.Context, sessionId string) error
+
+	GetVariant(ctx context.Context, variantId string) (*entity.InventoryRes, error)
+	GetInventory(ctx context.Context, productId string) ([]*entity.InventoryRes, error)
+	// MoveStock changes the stock of the variant and appends the movement to the ledger,
+	// it returns ErrInsufficientStock instead of taking the stock below zero and
+	// ErrAboveReturnable instead of returning more of the order item than was sold
+	MoveStock(ctx context.Context, req *entity.StockMovementReq) (*entity.StockMovement, error)
+	SetLowStockThreshold(ctx context.Context, variantId string, threshold *int32) error
+	GetStockMovements(ctx context.Context, variantId string, limit, offset int) ([]*entity.StockMovement, int64, error)
+	// GetLowStockVariants returns the variants of the merchant at or below their threshold
+	GetLowStockVariants(ctx context.Context, merchantId string) ([]*entity.InventoryRes, error)
+
+	CreateImportJob(ctx context.Context, job *entity.ImportJob) error
+	// UpdateImportJob saves the status, the counts and the errors of the job
+	UpdateImportJob(ctx context.Context, job *entity.ImportJob) error
+	GetImportJob(ctx context.Context, jobId string) (*entity.ImportJob, error)
+	// GetCatalog returns a row for each variant of the merchant, with the keys of the images as URLs
+	GetCatalog(ctx context.Context, merchantId string) ([]*entity.CatalogRow, error)
+
+	// GetProductAnalytics returns the views and the sales of the products of the
+	// merchant, the most viewed since the time first
+	GetProductAnalytics(ctx context.Context, merchantId string, since time.Time) ([]*entity.ProductAnalytics, error)
+}
+
+var (
+	// ErrUploadNotPending is returned when attaching a session that is already attached.
+	ErrUploadNotPending = errors.New("upload session is not pending")
+	// ErrDuplicateSKU is returned when adding a variant with the SKU of another one.
+	ErrDuplicateSKU = errors.New("sku is already used")
+	// ErrInsufficientStock is returned when a movement would take the stock below zero.
+	ErrInsufficientStock = errors.New("insufficient stock")
+	// ErrAboveReturnable is returned when a return is above the quantity of the order item not returned yet.
+	ErrAboveReturnable = errors.New("return is above the returnable quantity")
+	// ErrStatusChanged is returned when the status of a product changed since it was read.
+	ErrStatusChanged = errors.New("the status of the product changed")
+)
+
+var (
+	_ MerchantRepository = (*MerchantStore)(nil)
+	_ MerchantRepository = (*MemoryMerchantStore)(nil)
+)
@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/akmal4410/gestapo/internal/config"
 	"github.com/akmal4410/gestapo/internal/database"
@@ -18,23 +19,22 @@ import (
 	"google.golang.org/grpc/reflection"
 )
 
+const (
+	// uploadCollectorInterval is how often the uploads never completed are deleted.
+	uploadCollectorInterval = 15 * time.Minute
+	// productSchedulerInterval is how often the products past their unpublish time are archived.
+	productSchedulerInterval = time.Minute
+)
+
 func RunGRPCService(ctx context.Context, storage *database.Storage, config *config.Config, log logger.Logger) error {
 	tokenMaker, err := token.NewJWTMaker(config.TokenSymmetricKey)
 	if err != nil {
 		log.LogFatal("Error while Initializing NewJWTMaker %w", err)
 	}
 	service := service.NewMerchantService(storage, config, log, tokenMaker)
-	interceptor := interceptor.NewInterceptor(tokenMaker, log)
-	grpcServer := grpc.NewServer(
-		grpc.ChainUnaryInterceptor(
-			interceptor.AccessMiddleware(),
-			interceptor.MerchantRoleMiddleware(),
-		),
-	)
-
-	proto.RegisterMerchantServiceServer(grpcServer, service)
-	log.LogInfo("Registreing for reflection")
-	reflection.Register(grpcServer)
+	grpcServer := NewGRPCServer(service, tokenMaker, log)
+	go service.RunUploadCollector(ctx, uploadCollectorInterval)
+	go service.RunProductScheduler(ctx, productSchedulerInterval)
 	port := ":" + config.ServerAddress.Merchant.Port
 
 	lis, err := net.Listen("tcp", port)
@@ -55,3 +55,20 @@ func RunGRPCService(ctx context.Context, storage *database.Storage, config *conf
 	log.LogInfo("Start gRPC server at ", lis.Addr().String())
 	return grpcServer.Serve(lis)
 }
+
+// NewGRPCServer creates the gRPC server of the merchant service with its interceptors.
+func NewGRPCServer(service proto.MerchantServiceServer, tokenMaker token.Maker, log logger.Logger) *grpc.Server {
+	interceptor := interceptor.NewInterceptor(tokenMaker, log)
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			interceptor.LoggingMiddleware(),
+			interceptor.AccessMiddleware(),
+			interceptor.MerchantRoleMiddleware(),
+		),
+	)
+
+	proto.RegisterMerchantServiceServer(grpcServer, service)
+	log.LogInfo("Registreing for reflection")
+	reflection.Register(grpcServer)
+	return grpcServer
+}
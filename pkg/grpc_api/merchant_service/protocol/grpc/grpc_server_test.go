@@ -0,0 +1,825 @@
+package grpc_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akmal4410/gestapo/internal/database/memory"
+	"github.com/akmal4410/gestapo/internal/testenv"
+	"github.com/akmal4410/gestapo/pkg/api/proto"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/merchant_service/db"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/merchant_service/service"
+	"github.com/akmal4410/gestapo/pkg/service/images"
+	objectstore "github.com/akmal4410/gestapo/pkg/service/object_store"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func assertCode(t *testing.T, err error, code codes.Code) {
+	t.Helper()
+	if status.Code(err) != code {
+		t.Fatalf("expected %s, got %v", code, err)
+	}
+}
+
+func TestGetProfile(t *testing.T) {
+	env := testenv.New(t)
+	client := env.MerchantClient(t)
+	merchant, merchantToken := env.AddUser(t, "merchant", utils.MERCHANT)
+	ctx := testenv.WithToken(context.Background(), merchantToken)
+
+	_, err := client.GetProfile(context.Background(), &proto.GetMerchantProfileRequest{UserId: merchant.ID})
+	assertCode(t, err, codes.Unauthenticated)
+
+	_, err = client.GetProfile(ctx, &proto.GetMerchantProfileRequest{})
+	assertCode(t, err, codes.InvalidArgument)
+
+	_, err = client.GetProfile(ctx, &proto.GetMerchantProfileRequest{UserId: "missing"})
+	assertCode(t, err, codes.NotFound)
+
+	res, err := client.GetProfile(ctx, &proto.GetMerchantProfileRequest{UserId: merchant.ID})
+	if err != nil {
+		t.Fatalf("GetProfile: %v", err)
+	}
+	if res.Data.UserName != "merchant" || res.Data.UserType != utils.MERCHANT {
+		t.Fatalf("unexpected profile %v", res.Data)
+	}
+}
+
+func TestProducts(t *testing.T) {
+	env := testenv.New(t)
+	client := env.MerchantClient(t)
+	merchant, merchantToken := env.AddUser(t, "merchant", utils.MERCHANT)
+	other, otherToken := env.AddUser(t, "other", utils.MERCHANT)
+	_, userToken := env.AddUser(t, "user", utils.USER)
+	category := env.AddCategory(t, "Shoes")
+	product := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5, 8)
+	env.AddProduct(t, other.ID, category.ID, "walker", 80, 5, 8)
+	ctx := testenv.WithToken(context.Background(), merchantToken)
+
+	res, err := client.GetProducts(ctx, &proto.GetProductRequest{MerchantId: &merchant.ID})
+	if err != nil {
+		t.Fatalf("GetProducts: %v", err)
+	}
+	if len(res.Data) != 1 || res.Data[0].Id != product.ID {
+		t.Fatalf("expected the products of the merchant, got %v", res.Data)
+	}
+
+	_, err = client.DeleteProduct(testenv.WithToken(context.Background(), userToken), &proto.DeleteProductRequest{ProductId: product.ID})
+	assertCode(t, err, codes.PermissionDenied)
+
+	_, err = client.DeleteProduct(testenv.WithToken(context.Background(), otherToken), &proto.DeleteProductRequest{ProductId: product.ID})
+	assertCode(t, err, codes.PermissionDenied)
+
+	if _, err = client.DeleteProduct(ctx, &proto.DeleteProductRequest{ProductId: product.ID}); err != nil {
+		t.Fatalf("DeleteProduct: %v", err)
+	}
+	// the product is soft deleted, its variants stay for the orders
+	env.DB.Lock()
+	deletedAt, variants := env.DB.Products[product.ID].DeletedAt, len(env.DB.Variants(product.ID))
+	env.DB.Unlock()
+	if deletedAt == nil || variants != 1 {
+		t.Fatalf("expected the product soft deleted with its variant, got %v and %d variants", deletedAt, variants)
+	}
+	res, err = client.GetProducts(ctx, &proto.GetProductRequest{MerchantId: &merchant.ID})
+	if err != nil {
+		t.Fatalf("GetProducts: %v", err)
+	}
+	if len(res.Data) != 0 {
+		t.Fatalf("expected the deleted product not listed, got %v", res.Data)
+	}
+	_, err = client.DeleteProduct(ctx, &proto.DeleteProductRequest{ProductId: product.ID})
+	assertCode(t, err, codes.NotFound)
+}
+
+func TestProductLifecycle(t *testing.T) {
+	env := testenv.New(t)
+	client := env.MerchantClient(t)
+	productClient := env.ProductClient(t)
+	adminClient := env.AdminClient(t)
+	merchant, merchantToken := env.AddUser(t, "merchant", utils.MERCHANT)
+	_, otherToken := env.AddUser(t, "other", utils.MERCHANT)
+	_, userToken := env.AddUser(t, "user", utils.USER)
+	_, adminToken := env.AddUser(t, "admin", utils.ADMIN)
+	category := env.AddCategory(t, "Shoes")
+	product := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5, 8)
+	env.DB.Lock()
+	product.Status = utils.ProductDraft
+	env.DB.Unlock()
+	ctx := testenv.WithToken(context.Background(), merchantToken)
+	userCtx := testenv.WithToken(context.Background(), userToken)
+	adminCtx := testenv.WithToken(context.Background(), adminToken)
+
+	// visible reports whether the users find the product
+	visible := func() bool {
+		t.Helper()
+		_, err := productClient.GetProductById(userCtx, &proto.ProductIdRequest{ProductId: product.ID})
+		if err != nil && status.Code(err) != codes.NotFound {
+			t.Fatalf("GetProductById: %v", err)
+		}
+		return err == nil
+	}
+	// ownProduct returns the product in the listing of its merchant
+	ownProduct := func() *proto.ProductResponse {
+		t.Helper()
+		res, err := client.GetProducts(ctx, &proto.GetProductRequest{MerchantId: &merchant.ID})
+		if err != nil || len(res.Data) != 1 {
+			t.Fatalf("GetProducts: %v %v", res, err)
+		}
+		return res.Data[0]
+	}
+
+	if visible() || ownProduct().GetStatus() != utils.ProductDraft {
+		t.Fatalf("expected a draft hidden from the users, got %v", ownProduct())
+	}
+	res, err := client.GetProducts(testenv.WithToken(context.Background(), otherToken), &proto.GetProductRequest{MerchantId: &merchant.ID})
+	if err != nil || len(res.Data) != 0 {
+		t.Fatalf("expected the draft hidden from the other merchants, got %v %v", res, err)
+	}
+
+	_, err = client.PublishProduct(testenv.WithToken(context.Background(), otherToken), &proto.PublishProductRequest{ProductId: product.ID})
+	assertCode(t, err, codes.PermissionDenied)
+	_, err = client.PublishProduct(ctx, &proto.PublishProductRequest{ProductId: product.ID, UnpublishAt: timestamppb.New(time.Now().Add(-time.Hour))})
+	assertCode(t, err, codes.InvalidArgument)
+	_, err = client.ArchiveProduct(ctx, &proto.ProductStatusRequest{ProductId: product.ID})
+	assertCode(t, err, codes.FailedPrecondition)
+
+	published, err := client.PublishProduct(ctx, &proto.PublishProductRequest{ProductId: product.ID})
+	if err != nil || published.Data.Status != utils.ProductPendingReview {
+		t.Fatalf("PublishProduct: %v %v", published, err)
+	}
+	if visible() {
+		t.Fatal("the pending product is listed")
+	}
+
+	_, err = adminClient.ReviewProduct(adminCtx, &proto.ReviewProductRequest{ProductId: product.ID, Action: "REJECT", Reason: "Blurry photos"})
+	if err != nil {
+		t.Fatalf("ReviewProduct: %v", err)
+	}
+	if own := ownProduct(); own.GetStatus() != utils.ProductDraft || own.GetStatusReason() != "Blurry photos" {
+		t.Fatalf("expected the rejected product back to draft with the reason, got %v", own)
+	}
+
+	unpublishAt := time.Now().Add(time.Hour)
+	published, err = client.PublishProduct(ctx, &proto.PublishProductRequest{ProductId: product.ID, UnpublishAt: timestamppb.New(unpublishAt)})
+	if err != nil || published.Data.StatusReason != nil || !published.Data.UnpublishAt.AsTime().Equal(unpublishAt.UTC()) {
+		t.Fatalf("PublishProduct: %v %v", published, err)
+	}
+	if _, err = adminClient.ReviewProduct(adminCtx, &proto.ReviewProductRequest{ProductId: product.ID, Action: "APPROVE"}); err != nil {
+		t.Fatalf("ReviewProduct: %v", err)
+	}
+	if !visible() {
+		t.Fatal("the approved product is not listed")
+	}
+	detail, err := productClient.GetProductById(userCtx, &proto.ProductIdRequest{ProductId: product.ID})
+	if err != nil || detail.Data.Status != nil {
+		t.Fatalf("expected the lifecycle hidden from the users, got %v %v", detail, err)
+	}
+
+	// a publish time to come hides the product until then
+	_, err = client.PublishProduct(ctx, &proto.PublishProductRequest{ProductId: product.ID, PublishAt: timestamppb.New(time.Now().Add(time.Minute))})
+	if err != nil {
+		t.Fatalf("PublishProduct: %v", err)
+	}
+	if visible() || ownProduct().GetStatus() != utils.ProductPublished {
+		t.Fatal("the product is listed before its publish time")
+	}
+	if _, err = client.PublishProduct(ctx, &proto.PublishProductRequest{ProductId: product.ID}); err != nil {
+		t.Fatalf("PublishProduct: %v", err)
+	}
+
+	archived, err := client.ArchiveProduct(ctx, &proto.ProductStatusRequest{ProductId: product.ID})
+	if err != nil || archived.Data.Status != utils.ProductArchived || visible() {
+		t.Fatalf("ArchiveProduct: %v %v", archived, err)
+	}
+	_, err = client.PublishProduct(ctx, &proto.PublishProductRequest{ProductId: product.ID})
+	assertCode(t, err, codes.FailedPrecondition)
+	restored, err := client.RestoreProduct(ctx, &proto.ProductStatusRequest{ProductId: product.ID})
+	if err != nil || restored.Data.Status != utils.ProductPublished || !visible() {
+		t.Fatalf("RestoreProduct: %v %v", restored, err)
+	}
+	_, err = client.RestoreProduct(ctx, &proto.ProductStatusRequest{ProductId: product.ID})
+	assertCode(t, err, codes.FailedPrecondition)
+
+	// the scheduler archives the product past its unpublish time, restoring it drops the time
+	env.DB.Lock()
+	past := time.Now().Add(-time.Minute)
+	product.UnpublishAt = &past
+	env.DB.Unlock()
+	if visible() {
+		t.Fatal("the product is listed after its unpublish time")
+	}
+	store := db.NewMemoryMerchantStore(env.DB)
+	if archived, err := service.ArchiveExpiredProducts(context.Background(), store, env.Log); err != nil || archived != 1 {
+		t.Fatalf("ArchiveExpiredProducts: %d %v", archived, err)
+	}
+	if own := ownProduct(); own.GetStatus() != utils.ProductArchived {
+		t.Fatalf("expected the expired product archived, got %v", own)
+	}
+	restored, err = client.RestoreProduct(ctx, &proto.ProductStatusRequest{ProductId: product.ID})
+	if err != nil || restored.Data.UnpublishAt != nil || !visible() {
+		t.Fatalf("RestoreProduct: %v %v", restored, err)
+	}
+}
+
+func TestDiscounts(t *testing.T) {
+	env := testenv.New(t)
+	client := env.MerchantClient(t)
+	merchant, merchantToken := env.AddUser(t, "merchant", utils.MERCHANT)
+	other, otherToken := env.AddUser(t, "other", utils.MERCHANT)
+	category := env.AddCategory(t, "Shoes")
+	product := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5, 8)
+	ctx := testenv.WithToken(context.Background(), merchantToken)
+
+	req := &proto.AddDiscountRequest{
+		ProductId:   product.ID,
+		Name:        "Summer sale",
+		Description: "Summer sale",
+		Percentage:  100,
+		StartTime:   timestamppb.Now(),
+		EndTime:     timestamppb.New(time.Now().Add(24 * time.Hour)),
+	}
+	_, err := client.AddProductDiscount(ctx, req)
+	assertCode(t, err, codes.InvalidArgument)
+
+	req.Percentage = 20
+	_, err = client.AddProductDiscount(testenv.WithToken(context.Background(), otherToken), req)
+	assertCode(t, err, codes.PermissionDenied)
+
+	if _, err = client.AddProductDiscount(ctx, req); err != nil {
+		t.Fatalf("AddProductDiscount: %v", err)
+	}
+
+	res, err := client.GetAllDiscounts(ctx, &proto.GetDiscountsRequest{MerchantId: &merchant.ID})
+	if err != nil {
+		t.Fatalf("GetAllDiscounts: %v", err)
+	}
+	if len(res.Data) != 1 || res.Data[0].ProductId != product.ID || res.Data[0].Percentage != 20 {
+		t.Fatalf("unexpected discounts %v", res.Data)
+	}
+	res, err = client.GetAllDiscounts(ctx, &proto.GetDiscountsRequest{MerchantId: &other.ID})
+	if err != nil {
+		t.Fatalf("GetAllDiscounts: %v", err)
+	}
+	if len(res.Data) != 0 {
+		t.Fatalf("expected no discounts for the other merchant, got %v", res.Data)
+	}
+
+	_, err = client.EditProductDiscount(ctx, &proto.EditDiscountRequest{DiscountId: "missing", Percentage: 30})
+	assertCode(t, err, codes.NotFound)
+
+	env.DB.Lock()
+	discountID := *env.DB.Products[product.ID].DiscountID
+	env.DB.Unlock()
+	_, err = client.EditProductDiscount(ctx, &proto.EditDiscountRequest{
+		DiscountId:  discountID,
+		Name:        "Winter sale",
+		Description: "Winter sale",
+		Percentage:  30,
+		StartTime:   timestamppb.Now(),
+		EndTime:     timestamppb.New(time.Now().Add(24 * time.Hour)),
+	})
+	if err != nil {
+		t.Fatalf("EditProductDiscount: %v", err)
+	}
+	res, err = client.GetAllDiscounts(ctx, &proto.GetDiscountsRequest{})
+	if err != nil {
+		t.Fatalf("GetAllDiscounts: %v", err)
+	}
+	if len(res.Data) != 1 || res.Data[0].Name != "Winter sale" || res.Data[0].Percentage != 30 {
+		t.Fatalf("unexpected discounts %v", res.Data)
+	}
+}
+
+func TestOrders(t *testing.T) {
+	env := testenv.New(t)
+	client := env.MerchantClient(t)
+	merchant, merchantToken := env.AddUser(t, "merchant", utils.MERCHANT)
+	_, otherToken := env.AddUser(t, "other", utils.MERCHANT)
+	user, _ := env.AddUser(t, "user", utils.USER)
+	category := env.AddCategory(t, "Shoes")
+	product := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5, 8)
+	item := env.AddOrderItem(t, user.ID, product, utils.OrderActive)
+	ctx := testenv.WithToken(context.Background(), merchantToken)
+
+	_, err := client.GetMerchantOrders(ctx, &proto.GetOrdersRequest{Type: "Shipped"})
+	assertCode(t, err, codes.InvalidArgument)
+
+	res, err := client.GetMerchantOrders(ctx, &proto.GetOrdersRequest{Type: utils.OrderActive})
+	if err != nil {
+		t.Fatalf("GetMerchantOrders: %v", err)
+	}
+	if len(res.Data) != 1 || res.Data[0].Id != item.ID {
+		t.Fatalf("unexpected orders %v", res.Data)
+	}
+
+	_, err = client.UpdateOrderStatus(testenv.WithToken(context.Background(), otherToken), &proto.UpdateOrderRequest{OrderItemId: item.ID})
+	assertCode(t, err, codes.NotFound)
+
+	for i := 0; i < 3; i++ {
+		if _, err = client.UpdateOrderStatus(ctx, &proto.UpdateOrderRequest{OrderItemId: item.ID}); err != nil {
+			t.Fatalf("UpdateOrderStatus: %v", err)
+		}
+	}
+	_, err = client.UpdateOrderStatus(ctx, &proto.UpdateOrderRequest{OrderItemId: item.ID})
+	assertCode(t, err, codes.PermissionDenied)
+
+	res, err = client.GetMerchantOrders(ctx, &proto.GetOrdersRequest{Type: utils.OrderCompleted})
+	if err != nil {
+		t.Fatalf("GetMerchantOrders: %v", err)
+	}
+	if len(res.Data) != 1 {
+		t.Fatalf("expected the completed order, got %v", res.Data)
+	}
+}
+
+var pngImage = func() string {
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 4, 4)))
+	return buf.String()
+}()
+
+func TestProductImageUpload(t *testing.T) {
+	env := testenv.New(t)
+	client := env.MerchantClient(t)
+	merchant, merchantToken := env.AddUser(t, "merchant", utils.MERCHANT)
+	_, otherToken := env.AddUser(t, "other", utils.MERCHANT)
+	category := env.AddCategory(t, "Shoes")
+	product := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5, 8)
+	ctx := testenv.WithToken(context.Background(), merchantToken)
+	request := &proto.CreateUploadSessionRequest{Purpose: "PRODUCT_IMAGE", ProductId: &product.ID, ContentType: "image/png", Size: int64(len(pngImage))}
+
+	_, err := client.CreateUploadSession(ctx, &proto.CreateUploadSessionRequest{Purpose: "PRODUCT_IMAGE", ProductId: &product.ID, ContentType: "image/gif", Size: 10})
+	assertCode(t, err, codes.InvalidArgument)
+	_, err = client.CreateUploadSession(ctx, &proto.CreateUploadSessionRequest{Purpose: "PRODUCT_IMAGE", ProductId: &product.ID, ContentType: "image/png", Size: 1 << 30})
+	assertCode(t, err, codes.InvalidArgument)
+	_, err = client.CreateUploadSession(testenv.WithToken(context.Background(), otherToken), request)
+	assertCode(t, err, codes.PermissionDenied)
+
+	res, err := client.CreateUploadSession(ctx, request)
+	if err != nil {
+		t.Fatalf("CreateUploadSession: %v", err)
+	}
+	session := res.Data
+	if session.Method != "PUT" || session.Headers["Content-Type"] != "image/png" || !strings.HasPrefix(session.Key, "products/"+merchant.ID+"/"+product.ID+"/") {
+		t.Fatalf("unexpected session %v", session)
+	}
+
+	_, err = client.CompleteUploadSession(ctx, &proto.CompleteUploadSessionRequest{SessionId: session.Id})
+	assertCode(t, err, codes.FailedPrecondition)
+
+	// the client uploads to the presigned URL
+	if err := env.Files.Put(ctx, session.Key, strings.NewReader(pngImage), "image/png"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	_, err = client.CompleteUploadSession(testenv.WithToken(context.Background(), otherToken), &proto.CompleteUploadSessionRequest{SessionId: session.Id})
+	assertCode(t, err, codes.PermissionDenied)
+	if _, err = client.CompleteUploadSession(ctx, &proto.CompleteUploadSessionRequest{SessionId: session.Id}); err != nil {
+		t.Fatalf("CompleteUploadSession: %v", err)
+	}
+	_, err = client.CompleteUploadSession(ctx, &proto.CompleteUploadSessionRequest{SessionId: session.Id})
+	assertCode(t, err, codes.FailedPrecondition)
+
+	env.DB.Lock()
+	productImages := env.DB.Products[product.ID].Images
+	env.DB.Unlock()
+	if len(productImages) != 2 || productImages[1] != images.BaseKey(session.Key) {
+		t.Fatalf("the image was not attached, got %v", productImages)
+	}
+	if _, err := env.Files.Stat(ctx, images.Key(productImages[1], images.Thumbnail, images.WebP)); err != nil {
+		t.Fatalf("the thumbnail was not stored: %v", err)
+	}
+}
+
+func TestInvalidUploadIsDeleted(t *testing.T) {
+	env := testenv.New(t)
+	client := env.MerchantClient(t)
+	_, merchantToken := env.AddUser(t, "merchant", utils.MERCHANT)
+	ctx := testenv.WithToken(context.Background(), merchantToken)
+
+	res, err := client.CreateUploadSession(ctx, &proto.CreateUploadSessionRequest{Purpose: "PROFILE_IMAGE", ContentType: "image/png", Size: int64(len(pngImage))})
+	if err != nil {
+		t.Fatalf("CreateUploadSession: %v", err)
+	}
+	if err := env.Files.Put(ctx, res.Data.Key, strings.NewReader("<html>not an image</html>"), "image/png"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	_, err = client.CompleteUploadSession(ctx, &proto.CompleteUploadSessionRequest{SessionId: res.Data.Id})
+	assertCode(t, err, codes.InvalidArgument)
+
+	if _, err := env.Files.Stat(ctx, res.Data.Key); !errors.Is(err, objectstore.ErrNotFound) {
+		t.Fatalf("the invalid upload was kept: %v", err)
+	}
+	_, err = client.CompleteUploadSession(ctx, &proto.CompleteUploadSessionRequest{SessionId: res.Data.Id})
+	assertCode(t, err, codes.NotFound)
+}
+
+func TestProfileImageUpload(t *testing.T) {
+	env := testenv.New(t)
+	client := env.MerchantClient(t)
+	merchant, merchantToken := env.AddUser(t, "merchant", utils.MERCHANT)
+	ctx := testenv.WithToken(context.Background(), merchantToken)
+
+	var keys []string
+	for i := 0; i < 2; i++ {
+		res, err := client.CreateUploadSession(ctx, &proto.CreateUploadSessionRequest{Purpose: "PROFILE_IMAGE", ContentType: "image/png", Size: int64(len(pngImage))})
+		if err != nil {
+			t.Fatalf("CreateUploadSession: %v", err)
+		}
+		if err := env.Files.Put(ctx, res.Data.Key, strings.NewReader(pngImage), "image/png"); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+		if _, err = client.CompleteUploadSession(ctx, &proto.CompleteUploadSessionRequest{SessionId: res.Data.Id}); err != nil {
+			t.Fatalf("CompleteUploadSession: %v", err)
+		}
+		keys = append(keys, res.Data.Key)
+	}
+
+	env.DB.Lock()
+	profileImage := env.DB.Users[merchant.ID].ProfileImage
+	env.DB.Unlock()
+	if profileImage == nil || *profileImage != images.BaseKey(keys[1]) {
+		t.Fatalf("expected the profile image %s, got %v", keys[1], profileImage)
+	}
+	if _, err := env.Files.Stat(ctx, images.Key(images.BaseKey(keys[0]), images.Medium, images.JPEG)); !errors.Is(err, objectstore.ErrNotFound) {
+		t.Fatalf("the previous profile image was kept: %v", err)
+	}
+}
+
+func TestCategoryImageUploadSession(t *testing.T) {
+	env := testenv.New(t)
+	client := env.MerchantClient(t)
+	_, adminToken := env.AddUser(t, "admin", utils.ADMIN)
+	_, merchantToken := env.AddUser(t, "merchant", utils.MERCHANT)
+	category := env.AddCategory(t, "Shoes")
+	ctx := testenv.WithToken(context.Background(), adminToken)
+	request := &proto.CreateUploadSessionRequest{Purpose: "CATEGORY_IMAGE", CategoryId: &category.ID, ContentType: "image/png", Size: int64(len(pngImage))}
+
+	_, err := client.CreateUploadSession(testenv.WithToken(context.Background(), merchantToken), request)
+	assertCode(t, err, codes.PermissionDenied)
+	_, err = client.CreateUploadSession(ctx, &proto.CreateUploadSessionRequest{Purpose: "CATEGORY_IMAGE", ContentType: "image/png", Size: 10})
+	assertCode(t, err, codes.InvalidArgument)
+	missing := uuid.NewString()
+	_, err = client.CreateUploadSession(ctx, &proto.CreateUploadSessionRequest{Purpose: "CATEGORY_IMAGE", CategoryId: &missing, ContentType: "image/png", Size: 10})
+	assertCode(t, err, codes.NotFound)
+
+	res, err := client.CreateUploadSession(ctx, request)
+	if err != nil {
+		t.Fatalf("CreateUploadSession: %v", err)
+	}
+	if !strings.HasPrefix(res.Data.Key, "categories/"+category.ID+"/") {
+		t.Fatalf("unexpected key %s", res.Data.Key)
+	}
+	if err := env.Files.Put(ctx, res.Data.Key, strings.NewReader(pngImage), "image/png"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	// the category image is attached by the gateway, with SetCategoryImage
+	_, err = client.CompleteUploadSession(ctx, &proto.CompleteUploadSessionRequest{SessionId: res.Data.Id})
+	assertCode(t, err, codes.FailedPrecondition)
+
+	// an image never attached to the category is an orphan
+	env.DB.Lock()
+	env.DB.UploadSessions[res.Data.Id].ExpiresAt = time.Now().Add(-time.Minute)
+	env.DB.Unlock()
+	collected, err := service.CollectOrphanUploads(ctx, db.NewMemoryMerchantStore(env.DB), env.Files, env.Log)
+	if err != nil || collected != 1 {
+		t.Fatalf("CollectOrphanUploads: %d %v", collected, err)
+	}
+	if _, err := env.Files.Stat(ctx, res.Data.Key); !errors.Is(err, objectstore.ErrNotFound) {
+		t.Fatalf("the orphan category image was kept: %v", err)
+	}
+}
+
+func TestCollectOrphanUploads(t *testing.T) {
+	env := testenv.New(t)
+	ctx := context.Background()
+	merchant, _ := env.AddUser(t, "merchant", utils.MERCHANT)
+
+	now := time.Now()
+	env.DB.Lock()
+	for _, id := range []string{"expired", "pending"} {
+		expiresAt := now.Add(time.Hour)
+		if id == "expired" {
+			expiresAt = now.Add(-time.Minute)
+		}
+		env.DB.UploadSessions[id] = &memory.UploadSession{
+			ID: id, UserID: merchant.ID, Purpose: "PROFILE_IMAGE", Key: "profile/" + id + ".png",
+			ContentType: "image/png", Size: int64(len(pngImage)), Status: "PENDING", ExpiresAt: expiresAt, CreatedAt: now, UpdatedAt: now,
+		}
+	}
+	env.DB.Unlock()
+	for _, key := range []string{"profile/expired.png", "profile/pending.png"} {
+		if err := env.Files.Put(ctx, key, strings.NewReader(pngImage), "image/png"); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+
+	collected, err := service.CollectOrphanUploads(ctx, db.NewMemoryMerchantStore(env.DB), env.Files, env.Log)
+	if err != nil || collected != 1 {
+		t.Fatalf("CollectOrphanUploads: %d %v", collected, err)
+	}
+	if _, err := env.Files.Stat(ctx, "profile/expired.png"); !errors.Is(err, objectstore.ErrNotFound) {
+		t.Fatalf("the orphan upload was kept: %v", err)
+	}
+	if _, err := env.Files.Stat(ctx, "profile/pending.png"); err != nil {
+		t.Fatalf("the pending upload was deleted: %v", err)
+	}
+	env.DB.Lock()
+	_, expired := env.DB.UploadSessions["expired"]
+	_, pending := env.DB.UploadSessions["pending"]
+	env.DB.Unlock()
+	if expired || !pending {
+		t.Fatalf("unexpected sessions, expired kept: %v, pending kept: %v", expired, pending)
+	}
+}
+
+func TestInventory(t *testing.T) {
+	env := testenv.New(t)
+	client := env.MerchantClient(t)
+	merchant, merchantToken := env.AddUser(t, "merchant", utils.MERCHANT)
+	_, otherToken := env.AddUser(t, "other", utils.MERCHANT)
+	user, userToken := env.AddUser(t, "user", utils.USER)
+	category := env.AddCategory(t, "Shoes")
+	product := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5, 8, 9)
+	item := env.AddOrderItem(t, user.ID, product, utils.OrderActive)
+	ctx := testenv.WithToken(context.Background(), merchantToken)
+
+	_, err := client.GetInventory(testenv.WithToken(context.Background(), otherToken), &proto.GetInventoryRequest{ProductId: product.ID})
+	assertCode(t, err, codes.PermissionDenied)
+	_, err = client.GetLowStockAlerts(testenv.WithToken(context.Background(), userToken), &proto.Request{})
+	assertCode(t, err, codes.PermissionDenied)
+
+	inventory, err := client.GetInventory(ctx, &proto.GetInventoryRequest{ProductId: product.ID})
+	if err != nil {
+		t.Fatalf("GetInventory: %v", err)
+	}
+	if len(inventory.Data) != 2 || inventory.Data[0].Options["size"] != "8" || inventory.Data[0].Quantity != 5 {
+		t.Fatalf("unexpected inventory %v", inventory.Data)
+	}
+	variantID := inventory.Data[0].VariantId
+
+	_, err = client.Restock(ctx, &proto.RestockRequest{VariantId: variantID, Quantity: -1})
+	assertCode(t, err, codes.InvalidArgument)
+	_, err = client.Restock(testenv.WithToken(context.Background(), otherToken), &proto.RestockRequest{VariantId: variantID, Quantity: 1})
+	assertCode(t, err, codes.PermissionDenied)
+
+	restock, err := client.Restock(ctx, &proto.RestockRequest{VariantId: variantID, Quantity: 10})
+	if err != nil {
+		t.Fatalf("Restock: %v", err)
+	}
+	if restock.Data.Type != utils.StockRestock || restock.Data.Balance != 15 {
+		t.Fatalf("unexpected movement %v", restock.Data)
+	}
+
+	_, err = client.AdjustStock(ctx, &proto.AdjustStockRequest{VariantId: variantID, Quantity: -2})
+	assertCode(t, err, codes.InvalidArgument)
+	_, err = client.AdjustStock(ctx, &proto.AdjustStockRequest{VariantId: variantID, Quantity: -20, Reason: "Damaged"})
+	assertCode(t, err, codes.FailedPrecondition)
+	adjust, err := client.AdjustStock(ctx, &proto.AdjustStockRequest{VariantId: variantID, Quantity: -13, Reason: "Damaged"})
+	if err != nil {
+		t.Fatalf("AdjustStock: %v", err)
+	}
+	if adjust.Data.Type != utils.StockAdjustment || adjust.Data.Balance != 2 {
+		t.Fatalf("unexpected movement %v", adjust.Data)
+	}
+
+	_, err = client.AdjustStock(ctx, &proto.AdjustStockRequest{VariantId: inventory.Data[1].VariantId, Quantity: 1, Reason: "Returned", OrderItemId: &item.ID})
+	assertCode(t, err, codes.NotFound)
+	_, err = client.AdjustStock(ctx, &proto.AdjustStockRequest{VariantId: variantID, Quantity: 2, Reason: "Returned", OrderItemId: &item.ID})
+	assertCode(t, err, codes.FailedPrecondition)
+	returned, err := client.AdjustStock(ctx, &proto.AdjustStockRequest{VariantId: variantID, Quantity: 1, Reason: "Returned", OrderItemId: &item.ID})
+	if err != nil {
+		t.Fatalf("AdjustStock: %v", err)
+	}
+	if returned.Data.Type != utils.StockReturn || returned.Data.Balance != 3 {
+		t.Fatalf("unexpected movement %v", returned.Data)
+	}
+	_, err = client.AdjustStock(ctx, &proto.AdjustStockRequest{VariantId: variantID, Quantity: 1, Reason: "Returned", OrderItemId: &item.ID})
+	assertCode(t, err, codes.FailedPrecondition)
+
+	threshold := int32(3)
+	if _, err = client.SetLowStockThreshold(ctx, &proto.SetLowStockThresholdRequest{VariantId: variantID, Threshold: &threshold}); err != nil {
+		t.Fatalf("SetLowStockThreshold: %v", err)
+	}
+	alerts, err := client.GetLowStockAlerts(ctx, &proto.Request{})
+	if err != nil {
+		t.Fatalf("GetLowStockAlerts: %v", err)
+	}
+	if len(alerts.Data) != 1 || alerts.Data[0].VariantId != variantID || !alerts.Data[0].LowStock {
+		t.Fatalf("unexpected alerts %v", alerts.Data)
+	}
+	if _, err = client.Restock(ctx, &proto.RestockRequest{VariantId: variantID, Quantity: 1}); err != nil {
+		t.Fatalf("Restock: %v", err)
+	}
+	alerts, err = client.GetLowStockAlerts(ctx, &proto.Request{})
+	if err != nil {
+		t.Fatalf("GetLowStockAlerts: %v", err)
+	}
+	if len(alerts.Data) != 0 {
+		t.Fatalf("expected no alert above the threshold, got %v", alerts.Data)
+	}
+
+	movements, err := client.GetStockMovements(ctx, &proto.GetStockMovementsRequest{VariantId: variantID, PageSize: 2})
+	if err != nil {
+		t.Fatalf("GetStockMovements: %v", err)
+	}
+	if movements.Total != 4 || len(movements.Data) != 2 || movements.Data[0].Type != utils.StockRestock || movements.Data[1].Type != utils.StockReturn {
+		t.Fatalf("unexpected movements %v", movements)
+	}
+}
+
+// waitImportJob polls the job until it is finished.
+func waitImportJob(t *testing.T, ctx context.Context, client proto.MerchantServiceClient, jobID string) *proto.ImportJob {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		res, err := client.GetImportJob(ctx, &proto.GetImportJobRequest{JobId: jobID})
+		if err != nil {
+			t.Fatalf("GetImportJob: %v", err)
+		}
+		if res.Data.Status == "COMPLETED" || res.Data.Status == "FAILED" {
+			return res.Data
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("the import did not finish, got %v", res.Data)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestProductAnalytics(t *testing.T) {
+	env := testenv.New(t)
+	client := env.MerchantClient(t)
+	productClient := env.ProductClient(t)
+	merchant, merchantToken := env.AddUser(t, "merchant", utils.MERCHANT)
+	other, _ := env.AddUser(t, "other", utils.MERCHANT)
+	_, userToken := env.AddUser(t, "user", utils.USER)
+	category := env.AddCategory(t, "Shoes")
+	runner := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5, 8)
+	walker := env.AddProduct(t, merchant.ID, category.ID, "walker", 90, 5, 8)
+	env.AddProduct(t, other.ID, category.ID, "boot", 120, 5, 8)
+	env.AddOrderItem(t, other.ID, walker, utils.OrderCompleted)
+
+	userCtx := testenv.WithToken(context.Background(), userToken)
+	guestCtx := metadata.AppendToOutgoingContext(context.Background(), utils.DeviceIDKey, "device-1")
+	for _, ctx := range []context.Context{userCtx, guestCtx} {
+		if _, err := productClient.GetProductById(ctx, &proto.ProductIdRequest{ProductId: runner.ID}); err != nil {
+			t.Fatalf("GetProductById: %v", err)
+		}
+	}
+	// a view older than the period is only counted in the total
+	env.DB.Lock()
+	id := uuid.NewString()
+	env.DB.ProductViews[id] = &memory.ProductView{ID: id, ProductID: walker.ID, ViewedAt: time.Now().AddDate(0, 0, -10)}
+	env.DB.Unlock()
+
+	ctx := testenv.WithToken(context.Background(), merchantToken)
+	res, err := client.GetProductAnalytics(ctx, &proto.GetProductAnalyticsRequest{Days: 7})
+	if err != nil {
+		t.Fatalf("GetProductAnalytics: %v", err)
+	}
+	if len(res.Data) != 2 {
+		t.Fatalf("expected the products of the merchant, got %v", res.Data)
+	}
+	first, second := res.Data[0], res.Data[1]
+	if first.ProductId != runner.ID || first.ViewCount != 2 || first.RecentViews != 2 || first.RecentViewers != 2 {
+		t.Fatalf("unexpected analytics of the runner %v", first)
+	}
+	if second.ProductId != walker.ID || second.ViewCount != 1 || second.RecentViews != 0 || second.UnitsSold != 1 {
+		t.Fatalf("unexpected analytics of the walker %v", second)
+	}
+
+	_, err = client.GetProductAnalytics(ctx, &proto.GetProductAnalyticsRequest{Days: 400})
+	assertCode(t, err, codes.InvalidArgument)
+}
+
+func TestCatalogImportExport(t *testing.T) {
+	env := testenv.New(t)
+	client := env.MerchantClient(t)
+	merchant, merchantToken := env.AddUser(t, "merchant", utils.MERCHANT)
+	_, otherToken := env.AddUser(t, "other", utils.MERCHANT)
+	_, userToken := env.AddUser(t, "user", utils.USER)
+	category := env.AddCategory(t, "Shoes")
+	ctx := testenv.WithToken(context.Background(), merchantToken)
+
+	imageServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/shoe.png" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(pngImage))
+	}))
+	defer imageServer.Close()
+
+	catalog := strings.Join([]string{
+		"product_name,description,category_id,price,sku,options,variant_price,quantity,image_urls",
+		"runner,Running shoe," + category.ID + ",100,RUN-8,size=8,,5," + imageServer.URL + "/shoe.png",
+		"runner,,,,RUN-9,size=9,120,3,",
+		"walker,Walking shoe," + category.ID + ",80,,size=8,,2," + imageServer.URL + "/shoe.png",
+		"trail,Trail shoe," + category.ID + ",90,TR-8,size=8,,1," + imageServer.URL + "/missing.png",
+		"hiker,Hiking shoe," + category.ID + ",70,HK-8,size=8,,many," + imageServer.URL + "/shoe.png",
+	}, "\n")
+
+	_, err := client.ImportCatalog(testenv.WithToken(context.Background(), userToken), &proto.ImportCatalogRequest{Format: "csv", Content: catalog})
+	assertCode(t, err, codes.PermissionDenied)
+	_, err = client.ImportCatalog(ctx, &proto.ImportCatalogRequest{Format: "XML", Content: catalog})
+	assertCode(t, err, codes.InvalidArgument)
+	_, err = client.ImportCatalog(ctx, &proto.ImportCatalogRequest{Format: "CSV", Content: "product_name,price\nrunner,100"})
+	assertCode(t, err, codes.InvalidArgument)
+
+	res, err := client.ImportCatalog(ctx, &proto.ImportCatalogRequest{Format: "csv", Content: catalog, DryRun: true})
+	if err != nil {
+		t.Fatalf("ImportCatalog: %v", err)
+	}
+	_, err = client.GetImportJob(testenv.WithToken(context.Background(), otherToken), &proto.GetImportJobRequest{JobId: res.Data.Id})
+	assertCode(t, err, codes.PermissionDenied)
+	job := waitImportJob(t, ctx, client, res.Data.Id)
+	if job.Status != "COMPLETED" || job.TotalRows != 5 || job.ProcessedRows != 5 || job.CreatedProducts != 2 || job.FailedRows != 2 {
+		t.Fatalf("unexpected dry run %v", job)
+	}
+	if len(job.Errors) != 2 || job.Errors[0].Row != 3 || job.Errors[0].Field != "sku" || job.Errors[1].Row != 5 || job.Errors[1].Field != "quantity" {
+		t.Fatalf("unexpected row errors %v", job.Errors)
+	}
+	env.DB.Lock()
+	products := len(env.DB.Products)
+	env.DB.Unlock()
+	if products != 0 {
+		t.Fatalf("the dry run added %d products", products)
+	}
+
+	res, err = client.ImportCatalog(ctx, &proto.ImportCatalogRequest{Format: "CSV", Content: catalog})
+	if err != nil {
+		t.Fatalf("ImportCatalog: %v", err)
+	}
+	job = waitImportJob(t, ctx, client, res.Data.Id)
+	if job.Status != "COMPLETED" || job.CreatedProducts != 1 || job.FailedRows != 3 || len(job.Errors) != 3 || job.Errors[1].Row != 4 || job.Errors[1].Field != "image_urls" {
+		t.Fatalf("unexpected import %v", job)
+	}
+	env.DB.Lock()
+	var runner *memory.Product
+	for _, product := range env.DB.Products {
+		runner = product
+	}
+	products = len(env.DB.Products)
+	variants := env.DB.Variants(runner.ID)
+	movements := len(env.DB.StockMovements)
+	env.DB.Unlock()
+	if products != 1 || runner.ProductName != "runner" || runner.MerchantID != merchant.ID || len(runner.Images) != 1 {
+		t.Fatalf("unexpected product %v", runner)
+	}
+	if len(variants) != 2 || *variants[1].SKU != "RUN-9" || *variants[1].Price != 120 || variants[1].Quantity != 3 || movements != 2 {
+		t.Fatalf("unexpected variants %v", variants)
+	}
+	if _, err := env.Files.Stat(ctx, images.Key(runner.Images[0], images.Large, images.WebP)); err != nil {
+		t.Fatalf("the image was not stored: %v", err)
+	}
+
+	export, err := client.ExportCatalog(ctx, &proto.ExportCatalogRequest{Format: "json"})
+	if err != nil {
+		t.Fatalf("ExportCatalog: %v", err)
+	}
+	var rows []map[string]interface{}
+	if err := json.Unmarshal([]byte(export.Content), &rows); err != nil {
+		t.Fatalf("the export is not JSON: %v", err)
+	}
+	if len(rows) != 2 || rows[0]["sku"] != "RUN-8" || rows[1]["variant_price"] != 120.0 || len(rows[0]["image_urls"].([]interface{})) != 1 {
+		t.Fatalf("unexpected export %v", rows)
+	}
+
+	// the exported catalog imports again, but its SKUs are taken
+	res, err = client.ImportCatalog(ctx, &proto.ImportCatalogRequest{Format: "JSON", Content: export.Content, DryRun: true})
+	if err != nil {
+		t.Fatalf("ImportCatalog: %v", err)
+	}
+	job = waitImportJob(t, ctx, client, res.Data.Id)
+	skuErrors := 0
+	for _, rowError := range job.Errors {
+		if rowError.Field == "sku" {
+			skuErrors++
+		}
+	}
+	if job.TotalRows != 2 || job.FailedRows != 2 || skuErrors != 2 {
+		t.Fatalf("unexpected import of the export %v", job)
+	}
+
+	export, err = client.ExportCatalog(ctx, &proto.ExportCatalogRequest{})
+	if err != nil {
+		t.Fatalf("ExportCatalog: %v", err)
+	}
+	if export.Format != "CSV" || !strings.HasPrefix(export.Content, "product_name,description,category_id,price,sku,options") {
+		t.Fatalf("unexpected CSV export %q", export.Content)
+	}
+}
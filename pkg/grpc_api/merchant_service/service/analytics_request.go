@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/api/proto"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultAnalyticsDays = 30
+	maxAnalyticsDays     = 365
+)
+
+func (handler *merchantService) GetProductAnalytics(ctx context.Context, in *proto.GetProductAnalyticsRequest) (*proto.GetProductAnalyticsResponse, error) {
+	payload, err := handler.merchantPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+	days := int(in.GetDays())
+	if days == 0 {
+		days = defaultAnalyticsDays
+	}
+	if days < 0 || days > maxAnalyticsDays {
+		handler.log.With(ctx).LogError("Invalid days", in.GetDays())
+		return nil, status.Errorf(codes.InvalidArgument, "Days must be between 1 and %d", maxAnalyticsDays)
+	}
+
+	since := time.Now().AddDate(0, 0, -days)
+	analytics, err := handler.storage.GetProductAnalytics(ctx, payload.UserID, since)
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while GetProductAnalytics", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	products := make([]*proto.ProductAnalytics, 0, len(analytics))
+	for _, product := range analytics {
+		products = append(products, &proto.ProductAnalytics{
+			ProductId:     product.ProductID,
+			ProductName:   product.ProductName,
+			ViewCount:     product.ViewCount,
+			RecentViews:   product.RecentViews,
+			RecentViewers: product.RecentViewers,
+			UnitsSold:     product.UnitsSold,
+			WishlistCount: product.WishlistCount,
+		})
+	}
+
+	response := &proto.GetProductAnalyticsResponse{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Product analytics fetched successfully",
+		Data:    products,
+	}
+	return response, nil
+}
@@ -0,0 +1,487 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/api/proto"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/merchant_service/db"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/merchant_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/helpers"
+	"github.com/akmal4410/gestapo/pkg/service/images"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	maxImportRows = 1000
+	// importTimeout bounds a whole import job, with the download of its images
+	importTimeout = 30 * time.Minute
+	// imageDownloadTimeout bounds the download of an image
+	imageDownloadTimeout = 30 * time.Second
+)
+
+func (handler *merchantService) ImportCatalog(ctx context.Context, in *proto.ImportCatalogRequest) (*proto.ImportJobResponse, error) {
+	payload, err := handler.merchantPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+	format := strings.ToUpper(in.GetFormat())
+	rows, rowErrors, err := entity.ParseCatalog(format, in.GetContent())
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while ParseCatalog", err)
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid catalog: %s", err.Error())
+	}
+	if len(rows) == 0 || len(rows) > maxImportRows {
+		handler.log.With(ctx).LogError("Invalid number of rows", len(rows))
+		return nil, status.Errorf(codes.InvalidArgument, "The catalog should have between 1 and %d rows", maxImportRows)
+	}
+
+	job := &entity.ImportJob{
+		ID:         uuid.NewString(),
+		MerchantID: payload.UserID,
+		Format:     format,
+		DryRun:     in.GetDryRun(),
+		Status:     entity.ImportPending,
+		TotalRows:  len(rows),
+		CreatedAt:  time.Now(),
+	}
+	err = handler.storage.CreateImportJob(ctx, job)
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while CreateImportJob", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	response := &proto.ImportJobResponse{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Import started successfully",
+		Data:    importJob(job),
+	}
+	// the job outlives the request, the client follows it with GetImportJob
+	go handler.runImport(context.WithoutCancel(ctx), job, rows, rowErrors)
+	return response, nil
+}
+
+func (handler *merchantService) GetImportJob(ctx context.Context, in *proto.GetImportJobRequest) (*proto.ImportJobResponse, error) {
+	payload, err := handler.merchantPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := uuid.Parse(in.GetJobId()); err != nil {
+		handler.log.With(ctx).LogError("Invalid job id", err)
+		return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
+	}
+
+	job, err := handler.storage.GetImportJob(ctx, in.GetJobId())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			handler.log.With(ctx).LogError("Error while GetImportJob Not found", err)
+			return nil, status.Errorf(codes.NotFound, "Import job not found")
+		}
+		handler.log.With(ctx).LogError("Error while GetImportJob", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	if job.MerchantID != payload.UserID {
+		handler.log.With(ctx).LogError("unauthorized: import job does not belong to the authenticated merchant")
+		return nil, status.Errorf(codes.PermissionDenied, "import job does not belong to the authenticated merchant")
+	}
+
+	response := &proto.ImportJobResponse{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Import job fetched successfully",
+		Data:    importJob(job),
+	}
+	return response, nil
+}
+
+func (handler *merchantService) ExportCatalog(ctx context.Context, in *proto.ExportCatalogRequest) (*proto.ExportCatalogResponse, error) {
+	payload, err := handler.merchantPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+	format := strings.ToUpper(in.GetFormat())
+	if format == "" {
+		format = entity.CatalogCSV
+	}
+	if format != entity.CatalogCSV && format != entity.CatalogJSON {
+		handler.log.With(ctx).LogError("Invalid format", in.GetFormat())
+		return nil, status.Errorf(codes.InvalidArgument, "Format should be %s or %s", entity.CatalogCSV, entity.CatalogJSON)
+	}
+
+	rows, err := handler.storage.GetCatalog(ctx, payload.UserID)
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while GetCatalog", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	var keys []string
+	for _, row := range rows {
+		keys = append(keys, row.ImageURLs...)
+		keys = append(keys, row.VariantImageURLs...)
+	}
+	urls := images.URLs(ctx, handler.urls, keys, images.Large)
+	for _, row := range rows {
+		row.ImageURLs = images.Pick(row.ImageURLs, urls)
+		row.VariantImageURLs = images.Pick(row.VariantImageURLs, urls)
+	}
+
+	content, err := entity.FormatCatalog(format, rows)
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while FormatCatalog", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	response := &proto.ExportCatalogResponse{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Catalog exported successfully",
+		Format:  format,
+		Content: content,
+	}
+	return response, nil
+}
+
+// catalogProduct is a product of an imported catalog, with the numbers of its rows.
+type catalogProduct struct {
+	rows []int
+	req  *entity.AddProductReq
+}
+
+// catalogImport holds what an import job checked so far.
+type catalogImport struct {
+	job        *entity.ImportJob
+	rows       []*entity.CatalogRow
+	failed     map[int]bool
+	skus       map[string]int
+	categories map[string]*entity.ProductCategory
+}
+
+func (in *catalogImport) fail(row int, field, message string) {
+	in.failed[row] = true
+	in.job.Errors = append(in.job.Errors, entity.ImportRowError{Row: row, Field: field, Message: message})
+}
+
+// runImport adds the products of the rows, or only checks them in a dry run. A product
+// is added with all its variants or not at all, the job is saved after each product.
+func (handler *merchantService) runImport(ctx context.Context, job *entity.ImportJob, rows []*entity.CatalogRow, rowErrors []entity.ImportRowError) {
+	ctx, cancel := context.WithTimeout(ctx, importTimeout)
+	defer cancel()
+
+	in := &catalogImport{
+		job:        job,
+		rows:       rows,
+		failed:     map[int]bool{},
+		skus:       map[string]int{},
+		categories: map[string]*entity.ProductCategory{},
+	}
+	job.Errors = entity.ImportRowErrors{}
+	for _, rowError := range rowErrors {
+		in.fail(rowError.Row, rowError.Field, rowError.Message)
+	}
+	job.Status = entity.ImportRunning
+	handler.saveImportJob(ctx, job)
+
+	for _, product := range groupCatalog(rows) {
+		err := handler.importProduct(ctx, in, product)
+		if err != nil {
+			handler.log.With(ctx).LogError("Error while importing the catalog", job.ID, err)
+			job.Status = entity.ImportFailed
+			break
+		}
+		for _, row := range product.rows {
+			if in.failed[row] {
+				job.FailedRows += len(product.rows)
+				break
+			}
+		}
+		job.ProcessedRows += len(product.rows)
+		handler.saveImportJob(ctx, job)
+	}
+
+	if job.Status != entity.ImportFailed {
+		job.Status = entity.ImportCompleted
+	}
+	sort.SliceStable(job.Errors, func(i, j int) bool { return job.Errors[i].Row < job.Errors[j].Row })
+	finishedAt := time.Now()
+	job.FinishedAt = &finishedAt
+	handler.saveImportJob(ctx, job)
+}
+
+func (handler *merchantService) saveImportJob(ctx context.Context, job *entity.ImportJob) {
+	if err := handler.storage.UpdateImportJob(ctx, job); err != nil {
+		handler.log.With(ctx).LogError("Error while UpdateImportJob", job.ID, err)
+	}
+}
+
+// groupCatalog groups the rows by the name of their product, in the order the products come.
+func groupCatalog(rows []*entity.CatalogRow) []*catalogProduct {
+	var products []*catalogProduct
+	byName := map[string]*catalogProduct{}
+	for i, row := range rows {
+		number := i + 1
+		name := strings.TrimSpace(row.ProductName)
+		product, ok := byName[name]
+		if !ok || name == "" {
+			product = &catalogProduct{}
+			products = append(products, product)
+			byName[name] = product
+		}
+		product.rows = append(product.rows, number)
+	}
+	return products
+}
+
+// importProduct checks the rows of the product and adds it unless a row failed or the job is a
+// dry run. The error is for the failures of the storage, which stop the job.
+func (handler *merchantService) importProduct(ctx context.Context, in *catalogImport, product *catalogProduct) error {
+	if err := handler.checkCatalogProduct(ctx, in, product); err != nil {
+		return err
+	}
+	for _, row := range product.rows {
+		if in.failed[row] {
+			return nil
+		}
+	}
+	if in.job.DryRun {
+		in.job.CreatedProducts++
+		return nil
+	}
+
+	productId := uuid.NewString()
+	saved := map[string]string{}
+	fetch := func(row int, field string, urls []string) []string {
+		var keys []string
+		for _, imageURL := range urls {
+			key, ok := saved[imageURL]
+			if !ok {
+				key = "products/" + in.job.MerchantID + "/" + productId + "/" + uuid.NewString()
+				if err := handler.fetchImage(ctx, imageURL, key); err != nil {
+					handler.log.With(ctx).LogWarn("Error while fetching the image", imageURL, err)
+					in.fail(row, field, fmt.Sprintf("the image %s could not be downloaded", imageURL))
+					continue
+				}
+				saved[imageURL] = key
+			}
+			keys = append(keys, key)
+		}
+		return keys
+	}
+	product.req.ProductImages = fetch(product.rows[0], "image_urls", in.rows[product.rows[0]-1].ImageURLs)
+	for i, row := range product.rows {
+		product.req.Variants[i].Images = fetch(row, "variant_image_urls", in.rows[row-1].VariantImageURLs)
+	}
+
+	deleteImages := func() {
+		for _, key := range saved {
+			if err := images.Delete(ctx, handler.files, key); err != nil {
+				handler.log.With(ctx).LogWarn("Error deleting the image of the failed product", key, err)
+			}
+		}
+	}
+	for _, row := range product.rows {
+		if in.failed[row] {
+			deleteImages()
+			return nil
+		}
+	}
+
+	err := handler.storage.InsertProduct(ctx, in.job.MerchantID, productId, product.req)
+	if err != nil {
+		deleteImages()
+		if errors.Is(err, db.ErrDuplicateSKU) {
+			in.fail(product.rows[0], "sku", "sku is already used by another variant")
+			return nil
+		}
+		handler.log.With(ctx).LogError("Error while InsertProduct", err)
+		in.fail(product.rows[0], "", "the product could not be added")
+		return nil
+	}
+	in.job.CreatedProducts++
+	return nil
+}
+
+// checkCatalogProduct validates the rows of the product and builds the request adding it.
+func (handler *merchantService) checkCatalogProduct(ctx context.Context, in *catalogImport, product *catalogProduct) error {
+	first := product.rows[0]
+	row := in.rows[first-1]
+	if in.failed[first] {
+		return nil
+	}
+	if row.ProductName == "" {
+		in.fail(first, "product_name", "product_name is required")
+		return nil
+	}
+
+	req := &entity.AddProductReq{
+		ProductName: strings.TrimSpace(row.ProductName),
+		Description: row.Description,
+		Price:       row.Price,
+		CategoryId:  row.CategoryID,
+		Attributes:  row.Attributes,
+	}
+	for option := range row.Options {
+		req.OptionTypes = append(req.OptionTypes, option)
+	}
+	sort.Strings(req.OptionTypes)
+
+	if req.Description == "" {
+		in.fail(first, "description", "description is required")
+	}
+	if req.Price <= 0 {
+		in.fail(first, "price", "price should be above zero")
+	}
+	if len(row.ImageURLs) == 0 {
+		in.fail(first, "image_urls", "the product should have at least one image")
+	}
+	if _, err := uuid.Parse(req.CategoryId); err != nil {
+		in.fail(first, "category_id", "category_id should be the id of a category")
+	} else {
+		category, ok := in.categories[req.CategoryId]
+		if !ok {
+			var err error
+			category, err = handler.storage.GetProductCategory(ctx, req.CategoryId)
+			if err != nil && err != sql.ErrNoRows {
+				return err
+			}
+			in.categories[req.CategoryId] = category
+		}
+		switch {
+		case category == nil:
+			in.fail(first, "category_id", "category doesnt exist")
+		case category.ArchivedAt != nil:
+			in.fail(first, "category_id", "category is archived")
+		default:
+			if err := category.ValidateAttributes(req.Attributes); err != nil {
+				in.fail(first, "attributes", err.Error())
+			}
+		}
+	}
+
+	for _, number := range product.rows {
+		row := in.rows[number-1]
+		if in.failed[number] && number != first {
+			continue
+		}
+		if err := checkImageURLs(row.ImageURLs); err != nil {
+			in.fail(number, "image_urls", err.Error())
+		}
+		if err := checkImageURLs(row.VariantImageURLs); err != nil {
+			in.fail(number, "variant_image_urls", err.Error())
+		}
+		if row.Quantity < 0 {
+			in.fail(number, "quantity", "quantity should not be below zero")
+		}
+		if row.VariantPrice != nil && *row.VariantPrice <= 0 {
+			in.fail(number, "variant_price", "variant_price should be above zero")
+		}
+		if len(row.Options) != len(req.OptionTypes) {
+			in.fail(number, "options", fmt.Sprintf("options should have a value for %s", strings.Join(req.OptionTypes, ", ")))
+		}
+		if len(row.Options) == 0 {
+			in.fail(number, "options", "options are required")
+		}
+
+		if row.SKU == "" {
+			in.fail(number, "sku", "sku is required")
+		} else if previous, ok := in.skus[row.SKU]; ok {
+			in.fail(number, "sku", fmt.Sprintf("sku is already used on row %d", previous))
+		} else {
+			in.skus[row.SKU] = number
+			exists, err := handler.storage.CheckDataExist(ctx, "inventories", "sku", row.SKU)
+			if err != nil {
+				return err
+			}
+			if exists {
+				in.fail(number, "sku", "sku is already used by another variant")
+			}
+		}
+
+		req.Variants = append(req.Variants, &entity.AddVariantReq{
+			SKU:      row.SKU,
+			Options:  row.Options,
+			Price:    row.VariantPrice,
+			Quantity: row.Quantity,
+		})
+	}
+	for _, number := range product.rows {
+		if in.failed[number] {
+			return nil
+		}
+	}
+
+	if err := helpers.ValidateBody(nil, req); err != nil {
+		in.fail(first, "", "invalid product")
+		return nil
+	}
+	if err := req.NormalizeVariants(0); err != nil {
+		in.fail(first, "options", err.Error())
+		return nil
+	}
+	product.req = req
+	return nil
+}
+
+// checkImageURLs checks the images can be downloaded over http.
+func checkImageURLs(urls []string) error {
+	for _, imageURL := range urls {
+		parsed, err := url.Parse(imageURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("%s is not an http or https URL", imageURL)
+		}
+	}
+	return nil
+}
+
+// fetchImage downloads the image and stores its renditions under key.
+func (handler *merchantService) fetchImage(ctx context.Context, imageURL, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return err
+	}
+	res, err := handler.imageClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", res.Status)
+	}
+	return images.Save(ctx, handler.files, res.Body, key)
+}
+
+func importJob(job *entity.ImportJob) *proto.ImportJob {
+	res := &proto.ImportJob{
+		Id:              job.ID,
+		Format:          job.Format,
+		DryRun:          job.DryRun,
+		Status:          job.Status,
+		TotalRows:       int32(job.TotalRows),
+		ProcessedRows:   int32(job.ProcessedRows),
+		CreatedProducts: int32(job.CreatedProducts),
+		FailedRows:      int32(job.FailedRows),
+		Errors:          make([]*proto.ImportRowError, 0, len(job.Errors)),
+		CreatedAt:       timestamppb.New(job.CreatedAt),
+	}
+	for _, rowError := range job.Errors {
+		res.Errors = append(res.Errors, &proto.ImportRowError{
+			Row:     int32(rowError.Row),
+			Field:   rowError.Field,
+			Message: rowError.Message,
+		})
+	}
+	if job.FinishedAt != nil {
+		res.FinishedAt = timestamppb.New(*job.FinishedAt)
+	}
+	return res
+}
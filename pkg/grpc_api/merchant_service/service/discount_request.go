@@ -5,16 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"time"
 
 	"github.com/akmal4410/gestapo/pkg/api/proto"
 	"github.com/akmal4410/gestapo/pkg/grpc_api/merchant_service/db/entity"
 	"github.com/akmal4410/gestapo/pkg/helpers"
-	"github.com/akmal4410/gestapo/pkg/helpers/service_helper"
 	"github.com/akmal4410/gestapo/pkg/helpers/token"
 	"github.com/akmal4410/gestapo/pkg/utils"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
@@ -22,7 +19,7 @@ func (handler *merchantService) AddProductDiscount(ctx context.Context, in *prot
 	payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
 	if !ok {
 		err := errors.New("unable to retrieve merchant payload from context")
-		handler.log.LogError("Error", err)
+		handler.log.With(ctx).LogError("Error", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 	req := &entity.AddDiscountReq{
@@ -37,28 +34,23 @@ func (handler *merchantService) AddProductDiscount(ctx context.Context, in *prot
 	}
 	err := helpers.ValidateBody(nil, req)
 	if err != nil {
-		handler.log.LogError("Error while ValidateBody", err)
+		handler.log.With(ctx).LogError("Error while ValidateBody", err)
 		return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
 	}
 
 	serviceToken, err := handler.token.CreateServiceToken(payload.UserID, payload.UserType, "product")
 	if err != nil {
-		handler.log.LogError("error while generating service token in DeleteProduct", err)
+		handler.log.With(ctx).LogError("error while generating service token in DeleteProduct", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
-	conn, err := service_helper.ConnectEndpoints(handler.config.ServerAddress.Product.Address, "product", handler.log)
+	productClient, err := handler.clients.ProductClient()
 	if err != nil {
-		handler.log.LogError("error while connecting product service :", err)
+		handler.log.With(ctx).LogError("error while connecting product service :", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
-	defer conn.Close()
 
-	productClient := proto.NewProductServiceClient(conn)
-	serviceCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	serviceCtx = metadata.NewOutgoingContext(serviceCtx, metadata.New(map[string]string{
-		token.ServiceToken: fmt.Sprint(utils.AuthorizationTypeBearer, " ", serviceToken),
-	}))
+	serviceCtx, cancel := handler.clients.NewServiceContext(ctx, serviceToken)
 	defer cancel()
 
 	productRes, err := productClient.GetProductById(serviceCtx, &proto.ProductIdRequest{
@@ -66,29 +58,29 @@ func (handler *merchantService) AddProductDiscount(ctx context.Context, in *prot
 	})
 	if err != nil {
 		if err.Error() == "rpc error: code = NotFound desc = No found" {
-			handler.log.LogError("Error while GetProductById Not found", err)
+			handler.log.With(ctx).LogError("Error while GetProductById Not found", err)
 			return nil, status.Errorf(codes.NotFound, "No found")
 		}
-		handler.log.LogError("Error while retrieving product", err)
+		handler.log.With(ctx).LogError("Error while retrieving product", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 	if !productRes.Status {
 		if productRes.Code == int32(codes.NotFound) {
-			handler.log.LogError("Error while GetProductById product Not found")
+			handler.log.With(ctx).LogError("Error while GetProductById product Not found")
 			return nil, status.Errorf(codes.NotFound, utils.NotFound)
 		}
-		handler.log.LogError("Error while GetProductById")
+		handler.log.With(ctx).LogError("Error while GetProductById")
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
 	if productRes.Data.MerchantId != nil && *productRes.Data.MerchantId != payload.UserID {
-		handler.log.LogError("unauthorized: product does not belong to the authenticated merchant")
+		handler.log.With(ctx).LogError("unauthorized: product does not belong to the authenticated merchant")
 		return nil, status.Errorf(codes.PermissionDenied, "product does not belong to the authenticated merchant")
 	}
 
-	err = handler.storage.AddProductDiscount(req)
+	err = handler.storage.AddProductDiscount(ctx, req)
 	if err != nil {
-		handler.log.LogError("Error while AddProductDiscount", err)
+		handler.log.With(ctx).LogError("Error while AddProductDiscount", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 
 	}
@@ -115,24 +107,24 @@ func (handler *merchantService) EditProductDiscount(ctx context.Context, in *pro
 	}
 	err := helpers.ValidateBody(nil, req)
 	if err != nil {
-		handler.log.LogError("Error while ValidateBody", err)
+		handler.log.With(ctx).LogError("Error while ValidateBody", err)
 		return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
 	}
 
-	res, err := handler.storage.CheckDataExist("discounts", "id", in.GetDiscountId())
+	res, err := handler.storage.CheckDataExist(ctx, "discounts", "id", in.GetDiscountId())
 	if err != nil {
-		handler.log.LogError("Error while CheckDataExist", err)
+		handler.log.With(ctx).LogError("Error while CheckDataExist", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 	if !res {
 		err = fmt.Errorf("discounts doesnt exist: %s", in.GetDiscountId())
-		handler.log.LogError("Error ", err)
+		handler.log.With(ctx).LogError("Error ", err)
 		return nil, status.Errorf(codes.NotFound, utils.NotFound)
 	}
 
-	err = handler.storage.EditProductDiscount(in.GetDiscountId(), req)
+	err = handler.storage.EditProductDiscount(ctx, in.GetDiscountId(), req)
 	if err != nil {
-		handler.log.LogError("Error while ApplyProductDiscount", err)
+		handler.log.With(ctx).LogError("Error while ApplyProductDiscount", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
@@ -146,9 +138,9 @@ func (handler *merchantService) EditProductDiscount(ctx context.Context, in *pro
 }
 
 func (handler *merchantService) GetAllDiscounts(ctx context.Context, req *proto.GetDiscountsRequest) (*proto.GetDiscountsResponse, error) {
-	discountEntities, err := handler.storage.GetAllDiscount(req.MerchantId)
+	discountEntities, err := handler.storage.GetAllDiscount(ctx, req.MerchantId)
 	if err != nil {
-		handler.log.LogError("Error while GetAllDiscount", err)
+		handler.log.With(ctx).LogError("Error while GetAllDiscount", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
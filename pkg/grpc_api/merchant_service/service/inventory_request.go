@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/akmal4410/gestapo/pkg/api/proto"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/merchant_service/db"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/merchant_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/helpers"
+	"github.com/akmal4410/gestapo/pkg/helpers/token"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultMovementsPageSize = 20
+	maxMovementsPageSize     = 50
+)
+
+func (handler *merchantService) GetInventory(ctx context.Context, in *proto.GetInventoryRequest) (*proto.GetInventoryResponse, error) {
+	payload, err := handler.merchantPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := uuid.Parse(in.GetProductId()); err != nil {
+		handler.log.With(ctx).LogError("Invalid product id", err)
+		return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
+	}
+
+	product, err := handler.storage.GetProductById(ctx, in.GetProductId())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			handler.log.With(ctx).LogError("Error while GetProductById Not found", err)
+			return nil, status.Errorf(codes.NotFound, utils.NotFound)
+		}
+		handler.log.With(ctx).LogError("Error while GetProductById", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	if product.MerchantID == nil || *product.MerchantID != payload.UserID {
+		handler.log.With(ctx).LogError("unauthorized: product does not belong to the authenticated merchant")
+		return nil, status.Errorf(codes.PermissionDenied, "product does not belong to the authenticated merchant")
+	}
+
+	inventories, err := handler.storage.GetInventory(ctx, in.GetProductId())
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while GetInventory", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	response := &proto.GetInventoryResponse{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Inventory fetched successfully",
+		Data:    inventoryVariants(inventories),
+	}
+	return response, nil
+}
+
+func (handler *merchantService) Restock(ctx context.Context, in *proto.RestockRequest) (*proto.StockMovementResponse, error) {
+	if in.GetQuantity() <= 0 {
+		handler.log.With(ctx).LogError("Invalid restock quantity", in.GetQuantity())
+		return nil, status.Errorf(codes.InvalidArgument, "Quantity must be above zero")
+	}
+	reason := in.GetReason()
+	if reason == "" {
+		reason = "Restock"
+	}
+	return handler.moveStock(ctx, in.GetVariantId(), &entity.StockMovementReq{
+		Type:     utils.StockRestock,
+		Quantity: in.GetQuantity(),
+		Reason:   reason,
+	}, "Variant restocked successfully")
+}
+
+func (handler *merchantService) AdjustStock(ctx context.Context, in *proto.AdjustStockRequest) (*proto.StockMovementResponse, error) {
+	if in.GetQuantity() == 0 || in.GetReason() == "" {
+		handler.log.With(ctx).LogError("Invalid stock adjustment", in.GetQuantity(), in.GetReason())
+		return nil, status.Errorf(codes.InvalidArgument, "Quantity must not be zero and the reason is required")
+	}
+	req := &entity.StockMovementReq{
+		Type:     utils.StockAdjustment,
+		Quantity: in.GetQuantity(),
+		Reason:   in.GetReason(),
+	}
+	if in.OrderItemId != nil {
+		if in.GetQuantity() < 0 {
+			handler.log.With(ctx).LogError("Invalid return quantity", in.GetQuantity())
+			return nil, status.Errorf(codes.InvalidArgument, "Returned quantity must be above zero")
+		}
+		req.Type = utils.StockReturn
+		req.OrderItemID = in.OrderItemId
+	}
+	return handler.moveStock(ctx, in.GetVariantId(), req, "Stock adjusted successfully")
+}
+
+// moveStock moves the stock of the variant of the merchant, checking a return
+// does not bring back more than the order item sold.
+func (handler *merchantService) moveStock(ctx context.Context, variantId string, req *entity.StockMovementReq, message string) (*proto.StockMovementResponse, error) {
+	payload, err := handler.merchantPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req.InventoryID = variantId
+	req.UserID = payload.UserID
+	err = helpers.ValidateBody(nil, req)
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while ValidateBody", err)
+		return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
+	}
+	if err := handler.ownVariant(ctx, payload, variantId); err != nil {
+		return nil, err
+	}
+
+	movement, err := handler.storage.MoveStock(ctx, req)
+	if err != nil {
+		if errors.Is(err, db.ErrInsufficientStock) {
+			handler.log.With(ctx).LogError("Error while MoveStock", err)
+			return nil, status.Errorf(codes.FailedPrecondition, "Stock cannot go below zero")
+		}
+		if errors.Is(err, db.ErrAboveReturnable) {
+			handler.log.With(ctx).LogError("Error while MoveStock", err)
+			return nil, status.Errorf(codes.FailedPrecondition, "Return is above the quantity of the order item not returned yet")
+		}
+		if err == sql.ErrNoRows && req.Type == utils.StockReturn {
+			handler.log.With(ctx).LogError("Error while MoveStock order item not found", err)
+			return nil, status.Errorf(codes.NotFound, "Order item of the variant not found")
+		}
+		handler.log.With(ctx).LogError("Error while MoveStock", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	response := &proto.StockMovementResponse{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: message,
+		Data:    stockMovement(movement),
+	}
+	return response, nil
+}
+
+func (handler *merchantService) SetLowStockThreshold(ctx context.Context, in *proto.SetLowStockThresholdRequest) (*proto.Response, error) {
+	payload, err := handler.merchantPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if in.Threshold != nil && in.GetThreshold() < 0 {
+		handler.log.With(ctx).LogError("Invalid threshold", in.GetThreshold())
+		return nil, status.Errorf(codes.InvalidArgument, "Threshold must not be below zero")
+	}
+	if err := handler.ownVariant(ctx, payload, in.GetVariantId()); err != nil {
+		return nil, err
+	}
+
+	err = handler.storage.SetLowStockThreshold(ctx, in.GetVariantId(), in.Threshold)
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while SetLowStockThreshold", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	response := &proto.Response{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Low stock threshold updated successfully",
+	}
+	return response, nil
+}
+
+func (handler *merchantService) GetStockMovements(ctx context.Context, in *proto.GetStockMovementsRequest) (*proto.GetStockMovementsResponse, error) {
+	payload, err := handler.merchantPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+	page, pageSize := in.GetPage(), in.GetPageSize()
+	if page == 0 {
+		page = 1
+	}
+	if pageSize == 0 {
+		pageSize = defaultMovementsPageSize
+	}
+	if page < 0 || pageSize < 0 || pageSize > maxMovementsPageSize {
+		handler.log.With(ctx).LogError("Invalid page", page, pageSize)
+		return nil, status.Errorf(codes.InvalidArgument, "Page size must be between 1 and %d", maxMovementsPageSize)
+	}
+	if err := handler.ownVariant(ctx, payload, in.GetVariantId()); err != nil {
+		return nil, err
+	}
+
+	movements, total, err := handler.storage.GetStockMovements(ctx, in.GetVariantId(), int(pageSize), int(page-1)*int(pageSize))
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while GetStockMovements", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	data := make([]*proto.StockMovement, 0, len(movements))
+	for _, movement := range movements {
+		data = append(data, stockMovement(movement))
+	}
+	response := &proto.GetStockMovementsResponse{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Stock movements fetched successfully",
+		Data:    data,
+		Total:   total,
+	}
+	return response, nil
+}
+
+func (handler *merchantService) GetLowStockAlerts(ctx context.Context, in *proto.Request) (*proto.GetInventoryResponse, error) {
+	payload, err := handler.merchantPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	inventories, err := handler.storage.GetLowStockVariants(ctx, payload.UserID)
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while GetLowStockVariants", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	response := &proto.GetInventoryResponse{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Low stock alerts fetched successfully",
+		Data:    inventoryVariants(inventories),
+	}
+	return response, nil
+}
+
+// merchantPayload returns the payload of the request, refusing the users who are
+// not merchants.
+func (handler *merchantService) merchantPayload(ctx context.Context) (*token.AccessPayload, error) {
+	payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
+	if !ok {
+		err := errors.New("unable to retrieve merchant payload from context")
+		handler.log.With(ctx).LogError("Error", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	if payload.UserType != utils.MERCHANT {
+		err := fmt.Errorf("user does not have required role: %s", utils.MERCHANT)
+		handler.log.With(ctx).LogError("Error", err)
+		return nil, status.Errorf(codes.PermissionDenied, utils.PermissionDenied)
+	}
+	return payload, nil
+}
+
+// ownVariant checks the variant belongs to a product of the merchant.
+func (handler *merchantService) ownVariant(ctx context.Context, payload *token.AccessPayload, variantId string) error {
+	if _, err := uuid.Parse(variantId); err != nil {
+		handler.log.With(ctx).LogError("Invalid variant id", err)
+		return status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
+	}
+	variant, err := handler.storage.GetVariant(ctx, variantId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			handler.log.With(ctx).LogError("Error while GetVariant Not found", err)
+			return status.Errorf(codes.NotFound, "Variant not found")
+		}
+		handler.log.With(ctx).LogError("Error while GetVariant", err)
+		return status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	if variant.MerchantID != payload.UserID {
+		handler.log.With(ctx).LogError("unauthorized: variant does not belong to the authenticated merchant")
+		return status.Errorf(codes.PermissionDenied, "variant does not belong to the authenticated merchant")
+	}
+	return nil
+}
+
+func inventoryVariants(inventories []*entity.InventoryRes) []*proto.InventoryVariant {
+	variants := make([]*proto.InventoryVariant, 0, len(inventories))
+	for _, inventory := range inventories {
+		variants = append(variants, &proto.InventoryVariant{
+			VariantId:         inventory.ID,
+			ProductId:         inventory.ProductID,
+			ProductName:       inventory.ProductName,
+			Sku:               inventory.SKU,
+			Options:           inventory.Options,
+			Quantity:          inventory.Quantity,
+			LowStockThreshold: inventory.LowStockThreshold,
+			LowStock:          inventory.LowStock(),
+		})
+	}
+	return variants
+}
+
+func stockMovement(movement *entity.StockMovement) *proto.StockMovement {
+	return &proto.StockMovement{
+		Id:          movement.ID,
+		VariantId:   movement.InventoryID,
+		Type:        movement.Type,
+		Quantity:    movement.Quantity,
+		Balance:     movement.Balance,
+		Reason:      movement.Reason,
+		OrderItemId: movement.OrderItemID,
+		CreatedAt:   timestamppb.New(movement.CreatedAt),
+	}
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/api/proto"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/merchant_service/db"
+	product_entity "github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/helpers/logger"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// PublishProduct sends a draft to the review with its schedule, the admins publish it
+// once approved. The schedule of a pending or a published product is changed in place.
+func (handler *merchantService) PublishProduct(ctx context.Context, in *proto.PublishProductRequest) (*proto.ProductStatusResponse, error) {
+	product, err := handler.ownProduct(ctx, in.GetProductId())
+	if err != nil {
+		return nil, err
+	}
+
+	state := &product_entity.ProductState{ProductID: in.GetProductId(), Status: *product.Status}
+	if in.PublishAt != nil {
+		publishAt := in.GetPublishAt().AsTime()
+		state.PublishAt = &publishAt
+	}
+	if in.UnpublishAt != nil {
+		unpublishAt := in.GetUnpublishAt().AsTime()
+		if !unpublishAt.After(time.Now()) || state.PublishAt != nil && !unpublishAt.After(*state.PublishAt) {
+			handler.log.With(ctx).LogError("Invalid unpublish_at", unpublishAt)
+			return nil, status.Errorf(codes.InvalidArgument, "unpublish_at should be in the future and after publish_at")
+		}
+		state.UnpublishAt = &unpublishAt
+	}
+
+	switch *product.Status {
+	case utils.ProductDraft:
+		state.Status = utils.ProductPendingReview
+	case utils.ProductPendingReview, utils.ProductPublished:
+		state.StatusReason = product.StatusReason
+	default:
+		return nil, status.Errorf(codes.FailedPrecondition, "an archived product should be restored instead")
+	}
+	return handler.setProductState(ctx, *product.Status, state, "Product sent for review successfully")
+}
+
+// ArchiveProduct takes a published product out of the listings, its merchant can restore it.
+func (handler *merchantService) ArchiveProduct(ctx context.Context, in *proto.ProductStatusRequest) (*proto.ProductStatusResponse, error) {
+	product, err := handler.ownProduct(ctx, in.GetProductId())
+	if err != nil {
+		return nil, err
+	}
+	if *product.Status != utils.ProductPublished {
+		return nil, status.Errorf(codes.FailedPrecondition, "only a published product can be archived")
+	}
+	state := &product_entity.ProductState{
+		ProductID:   in.GetProductId(),
+		Status:      utils.ProductArchived,
+		PublishAt:   product.PublishAt,
+		UnpublishAt: product.UnpublishAt,
+	}
+	return handler.setProductState(ctx, *product.Status, state, "Product archived successfully")
+}
+
+// RestoreProduct publishes an archived product again, dropping its unpublish time
+// when it is past.
+func (handler *merchantService) RestoreProduct(ctx context.Context, in *proto.ProductStatusRequest) (*proto.ProductStatusResponse, error) {
+	product, err := handler.ownProduct(ctx, in.GetProductId())
+	if err != nil {
+		return nil, err
+	}
+	if *product.Status != utils.ProductArchived {
+		return nil, status.Errorf(codes.FailedPrecondition, "only an archived product can be restored")
+	}
+	state := &product_entity.ProductState{
+		ProductID:   in.GetProductId(),
+		Status:      utils.ProductPublished,
+		PublishAt:   product.PublishAt,
+		UnpublishAt: product.UnpublishAt,
+	}
+	if state.UnpublishAt != nil && !state.UnpublishAt.After(time.Now()) {
+		state.UnpublishAt = nil
+	}
+	return handler.setProductState(ctx, *product.Status, state, "Product restored successfully")
+}
+
+// ownProduct returns the product of the merchant, with its lifecycle.
+func (handler *merchantService) ownProduct(ctx context.Context, productId string) (*product_entity.GetProductRes, error) {
+	payload, err := handler.merchantPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := uuid.Parse(productId); err != nil {
+		handler.log.With(ctx).LogError("Invalid product id", err)
+		return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
+	}
+	product, err := handler.storage.GetProductById(ctx, productId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			handler.log.With(ctx).LogError("Error while GetProductById Not found", err)
+			return nil, status.Errorf(codes.NotFound, "Product Not found")
+		}
+		handler.log.With(ctx).LogError("Error while GetProductById", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	if product.MerchantID == nil || *product.MerchantID != payload.UserID {
+		handler.log.With(ctx).LogError("unauthorized: product does not belong to the authenticated merchant")
+		return nil, status.Errorf(codes.PermissionDenied, "product does not belong to the authenticated merchant")
+	}
+	return product, nil
+}
+
+func (handler *merchantService) setProductState(ctx context.Context, from string, state *product_entity.ProductState, message string) (*proto.ProductStatusResponse, error) {
+	err := handler.storage.SetProductState(ctx, from, state)
+	if err != nil {
+		if err == db.ErrStatusChanged {
+			return nil, status.Errorf(codes.Aborted, "the status of the product changed, try again")
+		}
+		handler.log.With(ctx).LogError("Error while SetProductState", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	data := &proto.ProductStatus{
+		ProductId:    state.ProductID,
+		Status:       state.Status,
+		StatusReason: state.StatusReason,
+	}
+	if state.PublishAt != nil {
+		data.PublishAt = timestamppb.New(*state.PublishAt)
+	}
+	if state.UnpublishAt != nil {
+		data.UnpublishAt = timestamppb.New(*state.UnpublishAt)
+	}
+	response := &proto.ProductStatusResponse{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: message,
+		Data:    data,
+	}
+	return response, nil
+}
+
+// ArchiveExpiredProducts archives the published products past their unpublish time,
+// so their merchants see them archived and can restore them, and returns how many.
+func ArchiveExpiredProducts(ctx context.Context, store db.MerchantRepository, log logger.Logger) (int64, error) {
+	archived, err := store.ArchiveExpiredProducts(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if archived != 0 {
+		log.LogInfo("Archived", archived, "products past their unpublish time")
+	}
+	return archived, nil
+}
+
+// RunProductScheduler runs ArchiveExpiredProducts every interval until ctx is done.
+func (handler *merchantService) RunProductScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := ArchiveExpiredProducts(ctx, handler.storage, handler.log); err != nil {
+				handler.log.LogError("Error while ArchiveExpiredProducts", err)
+			}
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 
 	"github.com/akmal4410/gestapo/pkg/api/proto"
+	"github.com/akmal4410/gestapo/pkg/service/images"
 	"github.com/akmal4410/gestapo/pkg/utils"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -15,36 +16,32 @@ import (
 
 func (handler *merchantService) GetProfile(ctx context.Context, req *proto.GetMerchantProfileRequest) (*proto.GetMerchantProfileResponse, error) {
 	if req.GetUserId() == "" {
-		handler.log.LogError("Error while Getting user id")
+		handler.log.With(ctx).LogError("Error while Getting user id")
 		return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
 	}
-	res, err := handler.storage.CheckDataExist("user_data", "id", req.GetUserId())
+	res, err := handler.storage.CheckDataExist(ctx, "user_data", "id", req.GetUserId())
 	if err != nil {
-		handler.log.LogError("Error while CheckUserExist", err)
+		handler.log.With(ctx).LogError("Error while CheckUserExist", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 	if !res {
 		err = fmt.Errorf("account does'nt exist using %s", req.GetUserId())
-		handler.log.LogError(err)
+		handler.log.With(ctx).LogError(err)
 		return nil, status.Errorf(codes.NotFound, err.Error())
 	}
 
-	userData, err := handler.storage.GetProfile(req.UserId)
+	userData, err := handler.storage.GetProfile(ctx, req.UserId)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			handler.log.LogError("Error while GetProfile", err)
+			handler.log.With(ctx).LogError("Error while GetProfile", err)
 			return nil, status.Errorf(codes.NotFound, utils.NotFound)
 		}
-		handler.log.LogError("Error while GetProfile", err)
+		handler.log.With(ctx).LogError("Error while GetProfile", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
 	if userData.ProfileImage != nil && *userData.ProfileImage != "" {
-		url, err := handler.s3.GetPreSignedURL(*userData.ProfileImage)
-		if err != nil {
-			handler.log.LogError("Error while GetPreSignedURL", err)
-			return nil, status.Errorf(codes.Internal, utils.InternalServerError)
-		}
+		url := images.URL(ctx, handler.urls, *userData.ProfileImage, images.Medium)
 		userData.ProfileImage = &url
 	}
 
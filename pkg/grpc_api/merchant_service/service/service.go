@@ -1,13 +1,18 @@
 package service
 
 import (
+	"net/http"
+
 	"github.com/akmal4410/gestapo/internal/config"
 	"github.com/akmal4410/gestapo/internal/database"
 	"github.com/akmal4410/gestapo/pkg/api/proto"
 	"github.com/akmal4410/gestapo/pkg/grpc_api/merchant_service/db"
 	"github.com/akmal4410/gestapo/pkg/helpers/logger"
+	"github.com/akmal4410/gestapo/pkg/helpers/service_helper"
 	"github.com/akmal4410/gestapo/pkg/helpers/token"
-	s3 "github.com/akmal4410/gestapo/pkg/service/s3_service"
+	"github.com/akmal4410/gestapo/pkg/service/images"
+	objectstore "github.com/akmal4410/gestapo/pkg/service/object_store"
+	urlsigner "github.com/akmal4410/gestapo/pkg/service/url_signer"
 )
 
 // merchantService serves gRPC requests for our e-commerce service.
@@ -15,28 +20,59 @@ type merchantService struct {
 	proto.UnimplementedMerchantServiceServer
 	config  *config.Config
 	log     logger.Logger
-	s3      *s3.S3Service
-	storage *db.MerchantStore
+	files   objectstore.ObjectStore
+	urls    *urlsigner.Signer
+	storage db.MerchantRepository
 	token   token.Maker
+	clients *service_helper.ClientRegistry
+	// imageClient downloads the images of the imported products
+	imageClient *http.Client
+}
+
+// Dependencies are the stores and the clients used by the merchant service.
+type Dependencies struct {
+	Store   db.MerchantRepository
+	Files   objectstore.ObjectStore
+	URLs    *urlsigner.Signer
+	Clients *service_helper.ClientRegistry
+	// ImageClient downloads the images of the imported catalogs, only from public
+	// addresses when nil
+	ImageClient *http.Client
 }
 
 // NewMerchantService creates a new gRPC server.
 func NewMerchantService(storage *database.Storage, config *config.Config, log logger.Logger, tokenMaker token.Maker) *merchantService {
-	server := &merchantService{
-		config: config,
-		log:    log,
-		token:  tokenMaker,
+	files, err := objectstore.New(config)
+	if err != nil {
+		log.LogFatal("Error while Initializing object store ", err)
+	}
+	urls, err := urlsigner.New(config, files, log)
+	if err != nil {
+		log.LogFatal("Error while Initializing url signer ", err)
 	}
-	s3 := s3.NewS3Service(
-		config.AwsS3.BucketName,
-		config.AwsS3.Region,
-		config.AwsS3.AccessKey,
-		config.AwsS3.SecretKey,
-	)
+	deps := Dependencies{
+		Store:   db.NewMerchantStore(storage),
+		Files:   files,
+		URLs:    urls,
+		Clients: service_helper.NewClientRegistry(config.ServerAddress, config.GRPCClient, log),
+	}
+	return NewMerchantServiceWith(deps, config, log, tokenMaker)
+}
 
-	merchantStore := db.NewMerchantStore(storage)
+// NewMerchantServiceWith creates a new gRPC server working with deps.
+func NewMerchantServiceWith(deps Dependencies, config *config.Config, log logger.Logger, tokenMaker token.Maker) *merchantService {
+	if deps.ImageClient == nil {
+		deps.ImageClient = images.NewDownloadClient(imageDownloadTimeout)
+	}
+	return &merchantService{
+		config:  config,
+		log:     log,
+		token:   tokenMaker,
+		files:   deps.Files,
+		urls:    deps.URLs,
+		storage: deps.Store,
+		clients: deps.Clients,
 
-	server.s3 = s3
-	server.storage = merchantStore
-	return server
+		imageClient: deps.ImageClient,
+	}
 }
@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/api/proto"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/merchant_service/db"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/merchant_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/helpers/logger"
+	"github.com/akmal4410/gestapo/pkg/helpers/token"
+	"github.com/akmal4410/gestapo/pkg/service/images"
+	objectstore "github.com/akmal4410/gestapo/pkg/service/object_store"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// uploadCompleteWindow is how long an upload session can be completed,
+	// after that it is an orphan and CollectOrphanUploads deletes it
+	uploadCompleteWindow = time.Hour
+	defaultUploadMaxSize = 10 << 20
+	maxProductImages     = 5
+	orphanUploadBatch    = 100
+)
+
+func (handler *merchantService) uploadMaxSize() int64 {
+	if handler.config.Storage != nil && handler.config.Storage.UploadMaxSize > 0 {
+		return handler.config.Storage.UploadMaxSize
+	}
+	return defaultUploadMaxSize
+}
+
+// CreateUploadSession returns a presigned URL the client uploads the image to,
+// the image is attached once CompleteUploadSession verified it.
+func (handler *merchantService) CreateUploadSession(ctx context.Context, req *proto.CreateUploadSessionRequest) (*proto.CreateUploadSessionResponse, error) {
+	payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
+	if !ok {
+		err := errors.New("unable to retrieve user payload from context")
+		handler.log.With(ctx).LogError("Error", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	extension, ok := images.UploadExtensions[req.GetContentType()]
+	if !ok {
+		handler.log.With(ctx).LogError("Unsupported content type", req.GetContentType())
+		return nil, status.Errorf(codes.InvalidArgument, "only jpeg, png and webp images can be uploaded")
+	}
+	if req.GetSize() <= 0 || req.GetSize() > handler.uploadMaxSize() {
+		handler.log.With(ctx).LogError("Invalid upload size", req.GetSize())
+		return nil, status.Errorf(codes.InvalidArgument, "size should be between 1 and %d bytes", handler.uploadMaxSize())
+	}
+
+	sessionId, err := uuid.NewRandom()
+	if err != nil {
+		handler.log.With(ctx).LogError("error while uuid NewRandom", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	session := &entity.UploadSession{
+		ID:          sessionId.String(),
+		UserID:      payload.UserID,
+		Purpose:     req.GetPurpose(),
+		ContentType: req.GetContentType(),
+		Size:        req.GetSize(),
+		Status:      entity.UploadPending,
+		CreatedAt:   time.Now(),
+	}
+	session.ExpiresAt = session.CreatedAt.Add(uploadCompleteWindow)
+
+	switch req.GetPurpose() {
+	case entity.ProductImage:
+		if req.ProductId == nil {
+			return nil, status.Errorf(codes.InvalidArgument, "product_id is required for a product image")
+		}
+		if err := handler.checkProductImages(ctx, payload.UserID, req.GetProductId()); err != nil {
+			return nil, err
+		}
+		productId := req.GetProductId()
+		session.TargetID = &productId
+		session.Key = "products/" + payload.UserID + "/" + productId + "/" + session.ID + extension
+	case entity.ProfileImage:
+		session.Key = "profile/" + payload.UserID + "/" + session.ID + extension
+	case entity.CategoryImage:
+		if payload.UserType != utils.ADMIN {
+			handler.log.With(ctx).LogError("unauthorized: only the admins can upload a category image")
+			return nil, status.Errorf(codes.PermissionDenied, "user does not have required role: %s", utils.ADMIN)
+		}
+		categoryId := req.GetCategoryId()
+		if _, err := uuid.Parse(categoryId); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "category_id is required for a category image")
+		}
+		if _, err := handler.storage.GetProductCategory(ctx, categoryId); err != nil {
+			if err == sql.ErrNoRows {
+				handler.log.With(ctx).LogError("Error while GetProductCategory Not found", err)
+				return nil, status.Errorf(codes.NotFound, "Category not found")
+			}
+			handler.log.With(ctx).LogError("Error while GetProductCategory", err)
+			return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+		}
+		session.TargetID = &categoryId
+		session.Key = "categories/" + categoryId + "/" + session.ID + extension
+	default:
+		handler.log.With(ctx).LogError("Invalid upload purpose", req.GetPurpose())
+		return nil, status.Errorf(codes.InvalidArgument, "purpose should be %s, %s or %s", entity.ProductImage, entity.ProfileImage, entity.CategoryImage)
+	}
+
+	uploadURL, err := handler.files.PresignPut(ctx, session.Key, session.ContentType, session.Size)
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while PresignPut", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	err = handler.storage.CreateUploadSession(ctx, session)
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while CreateUploadSession", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	response := &proto.CreateUploadSessionResponse{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Upload session created successfully",
+		Data: &proto.UploadSessionResponse{
+			Id:        session.ID,
+			Key:       session.Key,
+			UploadUrl: uploadURL,
+			Method:    http.MethodPut,
+			Headers:   map[string]string{"Content-Type": session.ContentType},
+			ExpiresAt: timestamppb.New(session.ExpiresAt),
+		},
+	}
+	return response, nil
+}
+
+// checkProductImages checks that the product belongs to the merchant and can take one more image.
+func (handler *merchantService) checkProductImages(ctx context.Context, merchantId, productId string) error {
+	product, err := handler.storage.GetProductById(ctx, productId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			handler.log.With(ctx).LogError("Error while GetProductById Not found", err)
+			return status.Errorf(codes.NotFound, "Product Not found")
+		}
+		handler.log.With(ctx).LogError("Error while GetProductById", err)
+		return status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	if product.MerchantID == nil || *product.MerchantID != merchantId {
+		handler.log.With(ctx).LogError("unauthorized: product does not belong to the authenticated merchant")
+		return status.Errorf(codes.PermissionDenied, "product does not belong to the authenticated merchant")
+	}
+	if len(product.ProductImages) >= maxProductImages {
+		handler.log.With(ctx).LogError("Too many product images", productId)
+		return status.Errorf(codes.FailedPrecondition, "a product can have at most %d images", maxProductImages)
+	}
+	return nil
+}
+
+// CompleteUploadSession verifies the uploaded image and attaches it to the product or the profile.
+func (handler *merchantService) CompleteUploadSession(ctx context.Context, req *proto.CompleteUploadSessionRequest) (*proto.Response, error) {
+	payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
+	if !ok {
+		err := errors.New("unable to retrieve user payload from context")
+		handler.log.With(ctx).LogError("Error", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	session, err := handler.storage.GetUploadSession(ctx, req.GetSessionId())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			handler.log.With(ctx).LogError("Error while GetUploadSession Not found", err)
+			return nil, status.Errorf(codes.NotFound, "Upload session not found")
+		}
+		handler.log.With(ctx).LogError("Error while GetUploadSession", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	if session.UserID != payload.UserID {
+		handler.log.With(ctx).LogError("unauthorized: upload session does not belong to the user")
+		return nil, status.Errorf(codes.PermissionDenied, "upload session does not belong to the user")
+	}
+	if session.Purpose != entity.ProductImage && session.Purpose != entity.ProfileImage {
+		return nil, status.Errorf(codes.FailedPrecondition, "upload session is not for a product or a profile image")
+	}
+	if session.Status != entity.UploadPending {
+		return nil, status.Errorf(codes.FailedPrecondition, "upload session is already completed")
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return nil, status.Errorf(codes.FailedPrecondition, "upload session is expired")
+	}
+
+	err = objectstore.Verify(ctx, handler.files, session.Key, session.ContentType, session.Size)
+	if err != nil {
+		if errors.Is(err, objectstore.ErrNotFound) {
+			return nil, status.Errorf(codes.FailedPrecondition, "the file is not uploaded yet")
+		}
+		if errors.Is(err, objectstore.ErrInvalidObject) {
+			handler.log.With(ctx).LogError("Invalid upload", session.Key, err)
+			handler.discardUpload(ctx, session)
+			return nil, status.Errorf(codes.InvalidArgument, "the uploaded file is not the declared image")
+		}
+		handler.log.With(ctx).LogError("Error while Verify", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	if session.Purpose == entity.ProductImage {
+		if err := handler.checkProductImages(ctx, payload.UserID, *session.TargetID); err != nil {
+			return nil, err
+		}
+	}
+
+	// the renditions replace the upload, so the stored image has no EXIF or GPS metadata
+	image, err := images.Process(ctx, handler.files, session.Key)
+	if err != nil {
+		if errors.Is(err, images.ErrUnsupportedImage) {
+			handler.log.With(ctx).LogError("Invalid image", session.Key, err)
+			handler.discardUpload(ctx, session)
+			return nil, status.Errorf(codes.InvalidArgument, "the uploaded file is not a valid image")
+		}
+		handler.log.With(ctx).LogError("Error while Process", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	switch session.Purpose {
+	case entity.ProductImage:
+		err = handler.storage.AttachProductImage(ctx, session.ID, *session.TargetID, image)
+	case entity.ProfileImage:
+		var previous *string
+		previous, err = handler.storage.AttachProfileImage(ctx, session.ID, payload.UserID, image)
+		if err == nil && previous != nil {
+			if err := images.Delete(ctx, handler.files, *previous); err != nil {
+				handler.log.With(ctx).LogWarn("Error deleting the previous profile image", *previous, err)
+			}
+		}
+	}
+	if err != nil {
+		if errors.Is(err, db.ErrUploadNotPending) {
+			return nil, status.Errorf(codes.FailedPrecondition, "upload session is already completed")
+		}
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, utils.NotFound)
+		}
+		handler.log.With(ctx).LogError("Error while attaching the upload", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	response := &proto.Response{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Image uploaded successfully",
+	}
+	return response, nil
+}
+
+// discardUpload deletes an upload that can never be attached.
+func (handler *merchantService) discardUpload(ctx context.Context, session *entity.UploadSession) {
+	if err := handler.files.Delete(ctx, session.Key); err != nil {
+		handler.log.With(ctx).LogWarn("Error deleting the invalid upload", session.Key, err)
+		return
+	}
+	if err := handler.storage.DeleteUploadSession(ctx, session.ID); err != nil {
+		handler.log.With(ctx).LogWarn("Error while DeleteUploadSession", session.ID, err)
+	}
+}
+
+// CollectOrphanUploads deletes the files and the sessions of the uploads that were
+// never completed, and returns how many were collected.
+func CollectOrphanUploads(ctx context.Context, store db.MerchantRepository, files objectstore.ObjectStore, log logger.Logger) (int, error) {
+	collected := 0
+	for {
+		sessions, err := store.GetExpiredUploadSessions(ctx, time.Now(), orphanUploadBatch)
+		if err != nil {
+			return collected, err
+		}
+		for _, session := range sessions {
+			// the session is kept when the files cannot be deleted, to retry on the next run
+			if err := files.Delete(ctx, session.Key); err != nil {
+				return collected, err
+			}
+			// renditions are left when the upload was processed but could not be attached
+			if err := images.Delete(ctx, files, images.BaseKey(session.Key)); err != nil {
+				return collected, err
+			}
+			if err := store.DeleteUploadSession(ctx, session.ID); err != nil {
+				return collected, err
+			}
+			collected++
+		}
+		if len(sessions) < orphanUploadBatch {
+			if collected != 0 {
+				log.LogInfo("Collected", collected, "orphan uploads")
+			}
+			return collected, nil
+		}
+	}
+}
+
+// RunUploadCollector runs CollectOrphanUploads every interval until ctx is done.
+func (handler *merchantService) RunUploadCollector(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := CollectOrphanUploads(ctx, handler.storage, handler.files, handler.log); err != nil {
+				handler.log.LogError("Error while CollectOrphanUploads", err)
+			}
+		}
+	}
+}
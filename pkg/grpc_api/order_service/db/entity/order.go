@@ -1,6 +1,10 @@
 package entity
 
-import "time"
+import (
+	"time"
+
+	product_entity "github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+)
 
 type CreateOrderReq struct {
 	AddressID     string  `json:"address_id" validate:"required"`
@@ -13,13 +17,15 @@ type CreateOrderReq struct {
 }
 
 type UserOrderRes struct {
-	ID           string  `json:"id"`
-	ProductID    string  `json:"product_id"`
-	ProductName  string  `json:"product_name"`
-	ProductImage string  `json:"product_image"`
-	Size         float32 `json:"size"`
-	Price        float64 `json:"price"`
-	Status       string  `json:"status"`
+	ID           string                 `json:"id"`
+	ProductID    string                 `json:"product_id"`
+	ProductName  string                 `json:"product_name"`
+	ProductImage string                 `json:"product_image"`
+	Size         float32                `json:"size"`
+	SKU          *string                `json:"sku,omitempty"`
+	Options      product_entity.Options `json:"options"`
+	Price        float64                `json:"price"`
+	Status       string                 `json:"status"`
 }
 
 type GetTrackingDetailsReq struct {
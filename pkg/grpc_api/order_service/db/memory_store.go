@@ -0,0 +1,304 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/akmal4410/gestapo/internal/database/memory"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/order_service/db/entity"
+	product_entity "github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+// MemoryOrderStore is the in-memory OrderRepository.
+type MemoryOrderStore struct {
+	db *memory.Database
+}
+
+func NewMemoryOrderStore(db *memory.Database) *MemoryOrderStore {
+	return &MemoryOrderStore{db: db}
+}
+
+// returns true if the user has order more than two time
+func (store *MemoryOrderStore) CheckCODIsAvailable(ctx context.Context, UserID string) (bool, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	var count int
+	for _, order := range store.db.OrderDetails {
+		if order.UserID == UserID {
+			count++
+		}
+	}
+	return count > 2, nil
+}
+
+func (store *MemoryOrderStore) CreateOrder(ctx context.Context, req *entity.CreateOrderReq) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+
+	var cartItems []*memory.CartItem
+	for _, item := range store.db.CartItems {
+		if item.CartID == req.CartID {
+			cartItems = append(cartItems, item)
+		}
+	}
+	if len(cartItems) == 0 {
+		return fmt.Errorf("could not clear the cart items")
+	}
+	sort.Slice(cartItems, func(i, j int) bool {
+		return cartItems[i].CreatedAt.Before(cartItems[j].CreatedAt)
+	})
+
+	var discountedPercent *float64
+	if req.PromoID != nil {
+		promo, ok := store.db.PromoCodes[*req.PromoID]
+		if !ok {
+			return sql.ErrNoRows
+		}
+		discountedPercent = &promo.Percent
+	}
+	for _, item := range cartItems {
+		if product, ok := store.db.Products[item.ProductID]; !ok || !memory.Listed(product) {
+			return ErrUnlistedProduct
+		}
+		inventory, ok := store.db.Inventories[item.InventoryID]
+		if !ok {
+			return fmt.Errorf("could update inventories")
+		}
+		if inventory.Quantity < item.Quantity {
+			return ErrInsufficientStock
+		}
+	}
+
+	now := time.Now()
+	status := utils.PaymentCompleted
+	if req.PaymentMode == utils.COD {
+		status = utils.PaymentPending
+	}
+	payment := &memory.Payment{
+		ID:            uuid.NewString(),
+		Amount:        req.Amount,
+		Provider:      req.PaymentMode,
+		Status:        status,
+		TransactionID: req.TransactionID,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	store.db.Payments[payment.ID] = payment
+
+	order := &memory.OrderDetail{
+		ID:        uuid.NewString(),
+		UserID:    req.UserID,
+		PaymentID: payment.ID,
+		AddressID: req.AddressID,
+		PromoID:   req.PromoID,
+		Amount:    req.Amount,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	store.db.OrderDetails[order.ID] = order
+
+	for _, item := range cartItems {
+		amount := item.Price
+		if discountedPercent != nil {
+			amount = amount * (1 - *discountedPercent/100)
+		}
+		inventory := store.db.Inventories[item.InventoryID]
+		inventoryID := inventory.ID
+		options := map[string]string{}
+		for option, value := range inventory.Options {
+			options[option] = value
+		}
+
+		orderItem := &memory.OrderItem{
+			ID:          uuid.NewString(),
+			OrderID:     order.ID,
+			ProductID:   item.ProductID,
+			InventoryID: &inventoryID,
+			SKU:         inventory.SKU,
+			Options:     options,
+			Size:        inventory.Size,
+			Quantity:    item.Quantity,
+			Amount:      amount,
+			Status:      utils.OrderActive,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		store.db.OrderItems[orderItem.ID] = orderItem
+
+		tracking := &memory.TrackingDetail{
+			ID:          uuid.NewString(),
+			OrderItemID: orderItem.ID,
+			Status:      utils.TrackingStatus0,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		store.db.TrackingDetails[tracking.ID] = tracking
+		store.addTrackingItem(tracking, now)
+
+		orderItemID, userID := orderItem.ID, req.UserID
+		store.db.MoveStock(inventory, utils.StockSale, -item.Quantity, "", &orderItemID, &userID, now)
+
+		delete(store.db.CartItems, item.ID)
+	}
+	delete(store.db.Carts, req.CartID)
+	return nil
+}
+
+func (store *MemoryOrderStore) GetUserOrders(ctx context.Context, userID, status string) ([]*entity.UserOrderRes, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	return store.orders(status, func(item *memory.OrderItem, product *memory.Product) bool {
+		order, ok := store.db.OrderDetails[item.OrderID]
+		return ok && order.UserID == userID
+	}), nil
+}
+
+func (store *MemoryOrderStore) GetMerchantOrders(ctx context.Context, merchantID, status string) ([]*entity.UserOrderRes, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	orders := store.orders(status, func(item *memory.OrderItem, product *memory.Product) bool {
+		return product != nil && product.MerchantID == merchantID
+	})
+	for _, order := range orders {
+		// like the SQL query, the merchant orders don't carry the product id
+		order.ProductID = ""
+	}
+	return orders, nil
+}
+
+func (store *MemoryOrderStore) IsMerchantCanUpdate(ctx context.Context, orderItemID, merchantID string) (bool, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	item, ok := store.db.OrderItems[orderItemID]
+	if !ok {
+		return false, nil
+	}
+	product, ok := store.db.Products[item.ProductID]
+	return ok && product.MerchantID == merchantID, nil
+}
+
+func (store *MemoryOrderStore) GetMerchantTrackingStatus(ctx context.Context, orderItemID string) (int, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	tracking := store.tracking(orderItemID)
+	if tracking == nil {
+		return 0, sql.ErrNoRows
+	}
+	return tracking.Status, nil
+}
+
+func (store *MemoryOrderStore) UpdateOrderStatus(ctx context.Context, orderItemID string) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	tracking := store.tracking(orderItemID)
+	if tracking == nil {
+		return fmt.Errorf("couldnot update the tracking_details")
+	}
+	now := time.Now()
+	if tracking.Status < 3 {
+		tracking.Status++
+	}
+	tracking.UpdatedAt = now
+	store.addTrackingItem(tracking, now)
+
+	if tracking.Status >= 3 {
+		item, ok := store.db.OrderItems[orderItemID]
+		if !ok {
+			return fmt.Errorf("couldnot update the order_items")
+		}
+		item.Status = utils.OrderCompleted
+		item.UpdatedAt = now
+	}
+	return nil
+}
+
+func (store *MemoryOrderStore) GetOrderTrackingDetails(ctx context.Context, orderItemId string) ([]*entity.TrackingDetailsRes, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	tracking := store.tracking(orderItemId)
+	if tracking == nil {
+		return nil, nil
+	}
+	var items []*memory.TrackingItem
+	for _, item := range store.db.TrackingItems {
+		if item.TrackingID == tracking.ID {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].CreatedAt.Before(items[j].CreatedAt)
+	})
+	var details []*entity.TrackingDetailsRes
+	for _, item := range items {
+		details = append(details, &entity.TrackingDetailsRes{
+			Status:  int32(tracking.Status),
+			Title:   item.Title,
+			Summary: item.Summary,
+			Time:    item.UpdatedAt,
+		})
+	}
+	return details, nil
+}
+
+// orders returns the order items having status and accepted by filter, the caller must hold the lock.
+func (store *MemoryOrderStore) orders(status string, filter func(*memory.OrderItem, *memory.Product) bool) []*entity.UserOrderRes {
+	var items []*memory.OrderItem
+	for _, item := range store.db.OrderItems {
+		if item.Status == status && filter(item, store.db.Products[item.ProductID]) {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].CreatedAt.Before(items[j].CreatedAt)
+	})
+	var orders []*entity.UserOrderRes
+	for _, item := range items {
+		options := product_entity.Options{}
+		for option, value := range item.Options {
+			options[option] = value
+		}
+		order := &entity.UserOrderRes{
+			ID:        item.ID,
+			ProductID: item.ProductID,
+			Size:      float32(item.Size),
+			SKU:       item.SKU,
+			Options:   options,
+			Price:     item.Amount,
+			Status:    item.Status,
+		}
+		if product, ok := store.db.Products[item.ProductID]; ok {
+			order.ProductName = product.ProductName
+			order.ProductImage = memory.FirstImage(product)
+		}
+		orders = append(orders, order)
+	}
+	return orders
+}
+
+// tracking returns the tracking details of the order item, the caller must hold the lock.
+func (store *MemoryOrderStore) tracking(orderItemID string) *memory.TrackingDetail {
+	for _, tracking := range store.db.TrackingDetails {
+		if tracking.OrderItemID == orderItemID {
+			return tracking
+		}
+	}
+	return nil
+}
+
+// addTrackingItem records the current status of the tracking, the caller must hold the lock.
+func (store *MemoryOrderStore) addTrackingItem(tracking *memory.TrackingDetail, now time.Time) {
+	item := &memory.TrackingItem{
+		ID:         uuid.NewString(),
+		TrackingID: tracking.ID,
+		Title:      utils.TrackingTitles[tracking.Status],
+		Summary:    utils.TrackingSummeries[tracking.Status],
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	store.db.TrackingItems[item.ID] = item
+}
@@ -2,11 +2,13 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"time"
 
 	"github.com/akmal4410/gestapo/internal/database"
 	"github.com/akmal4410/gestapo/pkg/grpc_api/order_service/db/entity"
+	product_entity "github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
 	user_entity "github.com/akmal4410/gestapo/pkg/grpc_api/user_service/db/entity"
 	"github.com/lib/pq"
 
@@ -23,10 +25,10 @@ func NewOrderStore(storage *database.Storage) *OrderStore {
 }
 
 // returns true if the user has order more than two time
-func (store *OrderStore) CheckCODIsAvailable(UserID string) (bool, error) {
+func (store *OrderStore) CheckCODIsAvailable(ctx context.Context, UserID string) (bool, error) {
 	selectQuery := `SELECT COUNT(user_id) FROM order_details WHERE user_id = $1;`
 	var count int
-	err := store.storage.DB.QueryRow(selectQuery, UserID).Scan(&count)
+	err := store.storage.DB.QueryRowContext(ctx, selectQuery, UserID).Scan(&count)
 	if err != nil {
 		fmt.Println("Error executing query:", err)
 		return false, err
@@ -34,11 +36,10 @@ func (store *OrderStore) CheckCODIsAvailable(UserID string) (bool, error) {
 	return count > 2, nil
 }
 
-func (store *OrderStore) CreateOrder(req *entity.CreateOrderReq) error {
+func (store *OrderStore) CreateOrder(ctx context.Context, req *entity.CreateOrderReq) error {
 	createdAt := time.Now()
 	updatedAt := time.Now()
 
-	ctx := context.Background()
 	tx, err := store.storage.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
@@ -62,7 +63,7 @@ func (store *OrderStore) CreateOrder(req *entity.CreateOrderReq) error {
 	VALUES ($1, $2, $3, $4, $5, $6, $7);
 	`
 
-	_, err = tx.Exec(insertPaymentQuery, paymentID, req.Amount, req.PaymentMode, status, req.TransactionID, createdAt, updatedAt)
+	_, err = tx.ExecContext(ctx, insertPaymentQuery, paymentID, req.Amount, req.PaymentMode, status, req.TransactionID, createdAt, updatedAt)
 	if err != nil {
 		tx.Rollback()
 		return err
@@ -79,7 +80,7 @@ func (store *OrderStore) CreateOrder(req *entity.CreateOrderReq) error {
 	(id, user_id, payment_id, address_id, promo_id, amount, created_at, updated_at)
 	VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
 	`
-	_, err = tx.Exec(insertOrderDetailQuery, orderDetailID, req.UserID, paymentID, req.AddressID, req.PromoID, req.Amount, createdAt, updatedAt)
+	_, err = tx.ExecContext(ctx, insertOrderDetailQuery, orderDetailID, req.UserID, paymentID, req.AddressID, req.PromoID, req.Amount, createdAt, updatedAt)
 	if err != nil {
 		tx.Rollback()
 		return err
@@ -87,11 +88,12 @@ func (store *OrderStore) CreateOrder(req *entity.CreateOrderReq) error {
 
 	//select all the cart items
 	selectOrderItemsQuery := `
-	SELECT product_id, inventory_id, quantity, price 
-	FROM cart_items 
-	WHERE cart_id = $1;
+	SELECT ci.product_id, ci.inventory_id, ci.quantity, ci.price, listed(p)
+	FROM cart_items ci
+	JOIN products p ON ci.product_id = p.id
+	WHERE ci.cart_id = $1;
 	`
-	rows, err := store.storage.DB.Query(selectOrderItemsQuery, req.CartID)
+	rows, err := tx.QueryContext(ctx, selectOrderItemsQuery, req.CartID)
 	if err != nil {
 		tx.Rollback()
 		return err
@@ -101,18 +103,24 @@ func (store *OrderStore) CreateOrder(req *entity.CreateOrderReq) error {
 	var cartItems []*user_entity.CartItemRes
 	for rows.Next() {
 		var item user_entity.CartItemRes
+		var listed bool
 
 		err := rows.Scan(
 			&item.ProductID,
 			&item.InventoryID,
 			&item.Quantity,
 			&item.Price,
+			&listed,
 		)
 
 		if err != nil {
 			tx.Rollback()
 			return err
 		}
+		if !listed {
+			tx.Rollback()
+			return ErrUnlistedProduct
+		}
 		cartItems = append(cartItems, &item)
 	}
 
@@ -125,7 +133,7 @@ func (store *OrderStore) CreateOrder(req *entity.CreateOrderReq) error {
 	var discountedPercent *float64
 	if req.PromoID != nil {
 		selectQuery := `SELECT percent FROM promo_codes WHERE id = $1;`
-		err = tx.QueryRow(selectQuery, req.PromoID).Scan(&discountedPercent)
+		err = tx.QueryRowContext(ctx, selectQuery, req.PromoID).Scan(&discountedPercent)
 		if err != nil {
 			tx.Rollback()
 			return err
@@ -139,8 +147,10 @@ func (store *OrderStore) CreateOrder(req *entity.CreateOrderReq) error {
 		}
 
 		var size float32
-		selectSizeQuery := `SELECT size FROM inventories WHERE id = $1;`
-		err = tx.QueryRow(selectSizeQuery, item.InventoryID).Scan(&size)
+		var sku *string
+		var options product_entity.Options
+		selectVariantQuery := `SELECT size, sku, options FROM inventories WHERE id = $1;`
+		err = tx.QueryRowContext(ctx, selectVariantQuery, item.InventoryID).Scan(&size, &sku, &options)
 		if err != nil {
 			tx.Rollback()
 			return err
@@ -154,11 +164,11 @@ func (store *OrderStore) CreateOrder(req *entity.CreateOrderReq) error {
 
 		insertOrderItemQuery := `
 		INSERT INTO order_items
-		(id, order_id, product_id, size, quantity, amount, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+		(id, order_id, product_id, inventory_id, sku, options, size, quantity, amount, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12);
 		`
 
-		_, err = tx.Exec(insertOrderItemQuery, orderItemID, orderDetailID, item.ProductID, size, item.Quantity, amount, utils.OrderActive, createdAt, updatedAt)
+		_, err = tx.ExecContext(ctx, insertOrderItemQuery, orderItemID, orderDetailID, item.ProductID, item.InventoryID, sku, options, size, item.Quantity, amount, utils.OrderActive, createdAt, updatedAt)
 		if err != nil {
 			tx.Rollback()
 			return err
@@ -177,7 +187,7 @@ func (store *OrderStore) CreateOrder(req *entity.CreateOrderReq) error {
 		VALUES ($1, $2, $3, $4, $5);
 		`
 
-		_, err = tx.Exec(insertTrackingQuery, trackingID, orderItemID, utils.TrackingStatus0, createdAt, updatedAt)
+		_, err = tx.ExecContext(ctx, insertTrackingQuery, trackingID, orderItemID, utils.TrackingStatus0, createdAt, updatedAt)
 		if err != nil {
 			tx.Rollback()
 			return err
@@ -196,38 +206,47 @@ func (store *OrderStore) CreateOrder(req *entity.CreateOrderReq) error {
 		VALUES ($1, $2, $3, $4, $5, $6);
 		`
 
-		_, err = tx.Exec(insertTrackingItmeQuery, trackingItemID, trackingID, utils.TrackingTitles[utils.TrackingStatus0], utils.TrackingSummeries[utils.TrackingStatus0], createdAt, updatedAt)
+		_, err = tx.ExecContext(ctx, insertTrackingItmeQuery, trackingItemID, trackingID, utils.TrackingTitles[utils.TrackingStatus0], utils.TrackingSummeries[utils.TrackingStatus0], createdAt, updatedAt)
 		if err != nil {
 			tx.Rollback()
 			return err
 		}
 
-		// Update quantity in inventories in table
+		// Update quantity in inventories in table, the condition on the quantity
+		// keeps the stock from going below zero under concurrent orders
 		updateQuery := `
         UPDATE inventories
         SET quantity = quantity - $1, updated_at = $2
-        WHERE id = $3;
+        WHERE id = $3 AND quantity - $1 >= 0
+        RETURNING quantity;
     	`
-		res, err := tx.Exec(updateQuery, item.Quantity, updatedAt, item.InventoryID)
+		var balance int32
+		err = tx.QueryRowContext(ctx, updateQuery, item.Quantity, updatedAt, item.InventoryID).Scan(&balance)
 		if err != nil {
 			tx.Rollback()
+			if err == sql.ErrNoRows {
+				return ErrInsufficientStock
+			}
 			return err
 		}
-		n, err := res.RowsAffected()
+
+		// Recording the sale in the stock ledger
+		insertMovementQuery := `
+		INSERT INTO stock_movements
+		(id, inventory_id, product_id, type, quantity, balance, order_item_id, user_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+		`
+		_, err = tx.ExecContext(ctx, insertMovementQuery, uuid.NewString(), item.InventoryID, item.ProductID, utils.StockSale, -item.Quantity, balance, orderItemID, req.UserID, createdAt)
 		if err != nil {
 			tx.Rollback()
 			return err
 		}
-		if n == 0 {
-			tx.Rollback()
-			return fmt.Errorf("could update inventories")
-		}
 	}
 
 	//Deleting the cart_items
 	deleteCartItemsQuery := `DELETE FROM cart_items WHERE cart_id = $1;`
 
-	res, err := store.storage.DB.Exec(deleteCartItemsQuery, req.CartID)
+	res, err := tx.ExecContext(ctx, deleteCartItemsQuery, req.CartID)
 	if err != nil {
 		tx.Rollback()
 		return err
@@ -245,7 +264,7 @@ func (store *OrderStore) CreateOrder(req *entity.CreateOrderReq) error {
 	//Deleting the cart_items
 	deleteCartQuery := `DELETE FROM carts WHERE id = $1;`
 
-	res, err = store.storage.DB.Exec(deleteCartQuery, req.CartID)
+	res, err = tx.ExecContext(ctx, deleteCartQuery, req.CartID)
 	if err != nil {
 		tx.Rollback()
 		return err
@@ -260,11 +279,10 @@ func (store *OrderStore) CreateOrder(req *entity.CreateOrderReq) error {
 		return fmt.Errorf("could not clear the cart")
 	}
 
-	tx.Commit()
-	return nil
+	return tx.Commit()
 }
 
-func (store *OrderStore) GetUserOrders(userID, status string) ([]*entity.UserOrderRes, error) {
+func (store *OrderStore) GetUserOrders(ctx context.Context, userID, status string) ([]*entity.UserOrderRes, error) {
 	selectQuery := `
 	SELECT
     oi.id AS id,
@@ -272,6 +290,8 @@ func (store *OrderStore) GetUserOrders(userID, status string) ([]*entity.UserOrd
     p.product_name AS product_name,
 	p.images AS product_images,
     oi.size AS size,
+    oi.sku AS sku,
+    oi.options AS options,
     oi.amount AS price,
 	oi.status AS status
 	FROM
@@ -284,7 +304,7 @@ func (store *OrderStore) GetUserOrders(userID, status string) ([]*entity.UserOrd
 	oi.status = $2;
 	`
 
-	rows, err := store.storage.DB.Query(selectQuery, userID, status)
+	rows, err := store.storage.DB.QueryContext(ctx, selectQuery, userID, status)
 	if err != nil {
 		return nil, err
 	}
@@ -302,6 +322,8 @@ func (store *OrderStore) GetUserOrders(userID, status string) ([]*entity.UserOrd
 			&order.ProductName,
 			&images,
 			&order.Size,
+			&order.SKU,
+			&order.Options,
 			&order.Price,
 			&order.Status,
 		)
@@ -318,13 +340,15 @@ func (store *OrderStore) GetUserOrders(userID, status string) ([]*entity.UserOrd
 
 }
 
-func (store *OrderStore) GetMerchantOrders(merchantID, status string) ([]*entity.UserOrderRes, error) {
+func (store *OrderStore) GetMerchantOrders(ctx context.Context, merchantID, status string) ([]*entity.UserOrderRes, error) {
 	selectQuery := `
 	SELECT
     oi.id AS id,
     p.product_name AS product_name,
 	p.images AS product_images,
     oi.size AS size,
+    oi.sku AS sku,
+    oi.options AS options,
     oi.amount AS price,
 	oi.status AS status
 	FROM
@@ -335,7 +359,7 @@ func (store *OrderStore) GetMerchantOrders(merchantID, status string) ([]*entity
 	p.merchent_id = $1 AND oi.status = $2;
 	`
 
-	rows, err := store.storage.DB.Query(selectQuery, merchantID, status)
+	rows, err := store.storage.DB.QueryContext(ctx, selectQuery, merchantID, status)
 	if err != nil {
 		return nil, err
 	}
@@ -352,6 +376,8 @@ func (store *OrderStore) GetMerchantOrders(merchantID, status string) ([]*entity
 			&order.ProductName,
 			&images,
 			&order.Size,
+			&order.SKU,
+			&order.Options,
 			&order.Price,
 			&order.Status,
 		)
@@ -368,14 +394,14 @@ func (store *OrderStore) GetMerchantOrders(merchantID, status string) ([]*entity
 
 }
 
-func (store *OrderStore) IsMerchantCanUpdate(orderItemID, merchantID string) (bool, error) {
+func (store *OrderStore) IsMerchantCanUpdate(ctx context.Context, orderItemID, merchantID string) (bool, error) {
 	selectQuery := `SELECT COUNT(oi.id) 
 	FROM order_items oi
 	JOIN products p ON oi.product_id = p.id
 	WHERE oi.id = $1 AND p.merchent_id = $2;
 	`
 	var count int
-	err := store.storage.DB.QueryRow(selectQuery, orderItemID, merchantID).Scan(&count)
+	err := store.storage.DB.QueryRowContext(ctx, selectQuery, orderItemID, merchantID).Scan(&count)
 	if err != nil {
 		fmt.Println("Error executing query:", err)
 		return false, err
@@ -383,10 +409,10 @@ func (store *OrderStore) IsMerchantCanUpdate(orderItemID, merchantID string) (bo
 	return count > 0, nil
 }
 
-func (store *OrderStore) GetMerchantTrackingStatus(orderItemID string) (int, error) {
+func (store *OrderStore) GetMerchantTrackingStatus(ctx context.Context, orderItemID string) (int, error) {
 	selectQuery := `SELECT status FROM tracking_details WHERE order_item_id = $1;`
 	var status int
-	err := store.storage.DB.QueryRow(selectQuery, orderItemID).Scan(&status)
+	err := store.storage.DB.QueryRowContext(ctx, selectQuery, orderItemID).Scan(&status)
 	if err != nil {
 		fmt.Println("Error executing query:", err)
 		return 0, err
@@ -395,8 +421,7 @@ func (store *OrderStore) GetMerchantTrackingStatus(orderItemID string) (int, err
 	return status, nil
 }
 
-func (store *OrderStore) UpdateOrderStatus(orderItemID string) error {
-	ctx := context.Background()
+func (store *OrderStore) UpdateOrderStatus(ctx context.Context, orderItemID string) error {
 	tx, err := store.storage.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
@@ -410,7 +435,7 @@ func (store *OrderStore) UpdateOrderStatus(orderItemID string) error {
 	SET status = LEAST(status + 1, 3), updated_at = $2
 	WHERE order_item_id = $1;
 	`
-	res, err := tx.Exec(updateTrackingDetailsQuery, orderItemID, updatedAt)
+	res, err := tx.ExecContext(ctx, updateTrackingDetailsQuery, orderItemID, updatedAt)
 	if err != nil {
 		tx.Rollback()
 		return err
@@ -429,14 +454,12 @@ func (store *OrderStore) UpdateOrderStatus(orderItemID string) error {
 	selectQuery := `SELECT id, status FROM tracking_details WHERE order_item_id = $1;`
 	var trackingID string
 	var status int
-	err = store.storage.DB.QueryRow(selectQuery, orderItemID).Scan(&trackingID, &status)
+	err = tx.QueryRowContext(ctx, selectQuery, orderItemID).Scan(&trackingID, &status)
 	if err != nil {
 		tx.Rollback()
 		return err
 	}
 
-	status = status + 1 //beacuse only after transaction is completed it will update the status
-
 	// Inserting into tracking_items table
 	trackingItemID, err := uuid.NewRandom()
 	if err != nil {
@@ -450,7 +473,7 @@ func (store *OrderStore) UpdateOrderStatus(orderItemID string) error {
 	VALUES ($1, $2, $3, $4, $5, $6);
 	`
 
-	_, err = tx.Exec(insertTrackingItmeQuery, trackingItemID, trackingID, utils.TrackingTitles[status], utils.TrackingSummeries[status], createdAt, updatedAt)
+	_, err = tx.ExecContext(ctx, insertTrackingItmeQuery, trackingItemID, trackingID, utils.TrackingTitles[status], utils.TrackingSummeries[status], createdAt, updatedAt)
 	if err != nil {
 		tx.Rollback()
 		return err
@@ -472,7 +495,7 @@ func (store *OrderStore) UpdateOrderStatus(orderItemID string) error {
 		SET status = $2, updated_at = $3
 		WHERE id = $1;
 		`
-		res, err := tx.Exec(updateOrderItemQuery, orderItemID, utils.OrderCompleted, updatedAt)
+		res, err := tx.ExecContext(ctx, updateOrderItemQuery, orderItemID, utils.OrderCompleted, updatedAt)
 		if err != nil {
 			tx.Rollback()
 			return err
@@ -489,11 +512,10 @@ func (store *OrderStore) UpdateOrderStatus(orderItemID string) error {
 		}
 	}
 
-	tx.Commit()
-	return nil
+	return tx.Commit()
 }
 
-func (store *OrderStore) GetOrderTrackingDetails(orderItemId string) ([]*entity.TrackingDetailsRes, error) {
+func (store *OrderStore) GetOrderTrackingDetails(ctx context.Context, orderItemId string) ([]*entity.TrackingDetailsRes, error) {
 	var details []*entity.TrackingDetailsRes
 	selectQuery := `
 	SELECT 
@@ -506,7 +528,7 @@ func (store *OrderStore) GetOrderTrackingDetails(orderItemId string) ([]*entity.
 	tracking_items ti ON t.id = ti.tracking_id
 	WHERE t.order_item_id = $1;
 	`
-	rows, err := store.storage.DB.Query(selectQuery, orderItemId)
+	rows, err := store.storage.DB.QueryContext(ctx, selectQuery, orderItemId)
 	if err != nil {
 		return nil, err
 	}
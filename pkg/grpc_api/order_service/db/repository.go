@@ -0,0 +1,33 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/akmal4410/gestapo/pkg/grpc_api/order_service/db/entity"
+)
+
+// OrderRepository is the storage used by the order service,
+// implemented by OrderStore on Postgres and by MemoryOrderStore in memory.
+type OrderRepository interface {
+	CheckCODIsAvailable(ctx context.Context, UserID string) (bool, error)
+	CreateOrder(ctx context.Context, req *entity.CreateOrderReq) error
+	GetUserOrders(ctx context.Context, userID, status string) ([]*entity.UserOrderRes, error)
+	GetMerchantOrders(ctx context.Context, merchantID, status string) ([]*entity.UserOrderRes, error)
+	IsMerchantCanUpdate(ctx context.Context, orderItemID, merchantID string) (bool, error)
+	GetMerchantTrackingStatus(ctx context.Context, orderItemID string) (int, error)
+	UpdateOrderStatus(ctx context.Context, orderItemID string) error
+	GetOrderTrackingDetails(ctx context.Context, orderItemId string) ([]*entity.TrackingDetailsRes, error)
+}
+
+var (
+	// ErrUnlistedProduct is returned when the cart holds a product that is not listed anymore.
+	ErrUnlistedProduct = errors.New("the cart holds a product that is not listed")
+	// ErrInsufficientStock is returned when the cart holds more of a variant than its stock.
+	ErrInsufficientStock = errors.New("insufficient stock")
+)
+
+var (
+	_ OrderRepository = (*OrderStore)(nil)
+	_ OrderRepository = (*MemoryOrderStore)(nil)
+)
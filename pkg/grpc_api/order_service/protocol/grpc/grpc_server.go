@@ -24,16 +24,7 @@ func RunGRPCService(ctx context.Context, storage *database.Storage, config *conf
 		log.LogFatal("Error while Initializing NewJWTMaker %w", err)
 	}
 	service := service.NewOrderService(storage, config, log, tokenMaker)
-	interceptor := interceptor.NewInterceptor(tokenMaker, log)
-	grpcServer := grpc.NewServer(
-		grpc.UnaryInterceptor(
-			interceptor.AccessMiddleware(),
-		),
-	)
-
-	proto.RegisterOrderServiceServer(grpcServer, service)
-	log.LogInfo("Registreing for reflection")
-	reflection.Register(grpcServer)
+	grpcServer := NewGRPCServer(service, tokenMaker, log)
 	port := ":" + config.ServerAddress.Order.Port
 	lis, err := net.Listen("tcp", port)
 	if err != nil {
@@ -53,3 +44,19 @@ func RunGRPCService(ctx context.Context, storage *database.Storage, config *conf
 	log.LogInfo("Start gRPC server at ", lis.Addr().String())
 	return grpcServer.Serve(lis)
 }
+
+// NewGRPCServer creates the gRPC server of the order service with its interceptors.
+func NewGRPCServer(service proto.OrderServiceServer, tokenMaker token.Maker, log logger.Logger) *grpc.Server {
+	interceptor := interceptor.NewInterceptor(tokenMaker, log)
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			interceptor.LoggingMiddleware(),
+			interceptor.AccessMiddleware(),
+		),
+	)
+
+	proto.RegisterOrderServiceServer(grpcServer, service)
+	log.LogInfo("Registreing for reflection")
+	reflection.Register(grpcServer)
+	return grpcServer
+}
@@ -0,0 +1,194 @@
+package grpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/akmal4410/gestapo/internal/database/memory"
+	"github.com/akmal4410/gestapo/internal/testenv"
+	"github.com/akmal4410/gestapo/pkg/api/proto"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func assertCode(t *testing.T, err error, code codes.Code) {
+	t.Helper()
+	if status.Code(err) != code {
+		t.Fatalf("expected %s, got %v", code, err)
+	}
+}
+
+func TestRequiresServiceToken(t *testing.T) {
+	env := testenv.New(t)
+	client := env.OrderClient(t)
+	_, userToken := env.AddUser(t, "user", utils.USER)
+
+	// the access token of the user is not enough, the calls must come from the user and merchant services
+	ctx := testenv.WithToken(context.Background(), userToken)
+	_, err := client.GetUserOrders(ctx, &proto.GetOrdersRequest{Type: utils.OrderActive})
+	assertCode(t, err, codes.Unauthenticated)
+
+	_, err = client.GetOrderTrackingDetails(context.Background(), &proto.GetTrackingDetailsRequest{OrderItemId: "id"})
+	assertCode(t, err, codes.Unauthenticated)
+}
+
+func TestCreateOrder(t *testing.T) {
+	env := testenv.New(t)
+	client := env.OrderClient(t)
+	user, _ := env.AddUser(t, "user", utils.USER)
+	ctx := env.WithServiceToken(t, context.Background(), user.ID, utils.USER, "order")
+
+	_, err := client.CreateOrder(ctx, &proto.CreateOrderRequest{CartId: "cart", Amount: 100, PaymentMode: utils.OTHER})
+	assertCode(t, err, codes.InvalidArgument)
+
+	_, err = client.CreateOrder(ctx, &proto.CreateOrderRequest{AddressId: "address", CartId: "cart", Amount: 100, PaymentMode: "CARD"})
+	assertCode(t, err, codes.InvalidArgument)
+
+	res, err := client.CreateOrder(ctx, &proto.CreateOrderRequest{AddressId: "address", CartId: "cart", Amount: 100, PaymentMode: utils.COD})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	if res.Status {
+		t.Fatal("expected cash on delivery to be refused for a new user")
+	}
+
+	merchant, _ := env.AddUser(t, "merchant", utils.MERCHANT)
+	category := env.AddCategory(t, "Shoes")
+	product := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5, 8)
+	env.DB.Lock()
+	variant := env.DB.Variants(product.ID)[0]
+	env.DB.Unlock()
+	item := env.AddCartItem(t, user.ID, variant, 2)
+	res, err = client.CreateOrder(ctx, &proto.CreateOrderRequest{AddressId: "address", CartId: item.CartID, Amount: 200, PaymentMode: utils.OTHER})
+	if err != nil || !res.Status {
+		t.Fatalf("CreateOrder: %v %v", res, err)
+	}
+	env.DB.Lock()
+	defer env.DB.Unlock()
+	var orders []*memory.OrderDetail
+	for _, order := range env.DB.OrderDetails {
+		orders = append(orders, order)
+	}
+	if len(orders) != 1 || orders[0].UserID != user.ID || orders[0].Amount != 200 {
+		t.Fatalf("unexpected orders %v", orders)
+	}
+	var orderItems []*memory.OrderItem
+	for _, orderItem := range env.DB.OrderItems {
+		orderItems = append(orderItems, orderItem)
+	}
+	if len(orderItems) != 1 || orderItems[0].OrderID != orders[0].ID || orderItems[0].Quantity != 2 || *orderItems[0].InventoryID != variant.ID {
+		t.Fatalf("unexpected order items %v", orderItems)
+	}
+	if variant.Quantity != 3 || len(env.DB.StockMovements) != 1 || len(env.DB.CartItems) != 0 {
+		t.Fatalf("expected the stock to go down to 3 and the cart to be emptied, got a stock of %d", variant.Quantity)
+	}
+	for _, movement := range env.DB.StockMovements {
+		if movement.Type != utils.StockSale || movement.Quantity != -2 || movement.Balance != 3 || *movement.OrderItemID != orderItems[0].ID {
+			t.Fatalf("unexpected movement %v", movement)
+		}
+	}
+}
+
+func TestCreateOrderAboveStock(t *testing.T) {
+	env := testenv.New(t)
+	client := env.OrderClient(t)
+	merchant, _ := env.AddUser(t, "merchant", utils.MERCHANT)
+	user, _ := env.AddUser(t, "user", utils.USER)
+	category := env.AddCategory(t, "Shoes")
+	product := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5, 8)
+	env.DB.Lock()
+	variant := env.DB.Variants(product.ID)[0]
+	env.DB.Unlock()
+	item := env.AddCartItem(t, user.ID, variant, 6)
+	ctx := env.WithServiceToken(t, context.Background(), user.ID, utils.USER, "order")
+
+	_, err := client.CreateOrder(ctx, &proto.CreateOrderRequest{AddressId: "address", CartId: item.CartID, Amount: 600, PaymentMode: utils.OTHER})
+	assertCode(t, err, codes.FailedPrecondition)
+	env.DB.Lock()
+	defer env.DB.Unlock()
+	if variant.Quantity != 5 || len(env.DB.OrderDetails) != 0 || len(env.DB.StockMovements) != 0 {
+		t.Fatalf("expected nothing to change, got a stock of %d", variant.Quantity)
+	}
+}
+
+func TestCreateOrderLowStockAlert(t *testing.T) {
+	env := testenv.New(t)
+	client := env.OrderClient(t)
+	merchant, _ := env.AddUser(t, "merchant", utils.MERCHANT)
+	user, _ := env.AddUser(t, "user", utils.USER)
+	category := env.AddCategory(t, "Shoes")
+	product := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5, 8)
+	threshold := int32(3)
+	env.DB.Lock()
+	variant := env.DB.Variants(product.ID)[0]
+	variant.LowStockThreshold = &threshold
+	env.DB.Unlock()
+	ctx := env.WithServiceToken(t, context.Background(), user.ID, utils.USER, "order")
+
+	alerts := func() []*memory.Notification {
+		env.DB.Lock()
+		defer env.DB.Unlock()
+		var alerts []*memory.Notification
+		for _, notification := range env.DB.Notifications {
+			if notification.UserID == merchant.ID && notification.Type == utils.NotificationLowStock {
+				alerts = append(alerts, notification)
+			}
+		}
+		return alerts
+	}
+	// the alert is raised once, when the stock crosses the threshold
+	for _, quantity := range []int32{1, 2, 1} {
+		item := env.AddCartItem(t, user.ID, variant, quantity)
+		if _, err := client.CreateOrder(ctx, &proto.CreateOrderRequest{AddressId: "address", CartId: item.CartID, Amount: 100, PaymentMode: utils.OTHER}); err != nil {
+			t.Fatalf("CreateOrder: %v", err)
+		}
+	}
+	if alerts := alerts(); len(alerts) != 1 || alerts[0].Body != "runner is down to 2 in stock" {
+		t.Fatalf("expected one low stock alert, got %v", alerts)
+	}
+}
+
+func TestOrders(t *testing.T) {
+	env := testenv.New(t)
+	client := env.OrderClient(t)
+	merchant, _ := env.AddUser(t, "merchant", utils.MERCHANT)
+	user, userToken := env.AddUser(t, "user", utils.USER)
+	other, _ := env.AddUser(t, "other", utils.USER)
+	category := env.AddCategory(t, "Shoes")
+	product := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5, 8)
+	item := env.AddOrderItem(t, user.ID, product, utils.OrderActive)
+	env.AddOrderItem(t, other.ID, product, utils.OrderActive)
+
+	userCtx := env.WithServiceToken(t, context.Background(), user.ID, utils.USER, "order")
+	res, err := client.GetUserOrders(userCtx, &proto.GetOrdersRequest{Type: utils.OrderActive})
+	if err != nil {
+		t.Fatalf("GetUserOrders: %v", err)
+	}
+	if len(res.Data) != 1 || res.Data[0].Id != item.ID || res.Data[0].ProductId != product.ID {
+		t.Fatalf("unexpected orders %v", res.Data)
+	}
+
+	merchantCtx := env.WithServiceToken(t, context.Background(), merchant.ID, utils.MERCHANT, "order")
+	res, err = client.GetMerchantOrders(merchantCtx, &proto.GetOrdersRequest{Type: utils.OrderActive})
+	if err != nil {
+		t.Fatalf("GetMerchantOrders: %v", err)
+	}
+	if len(res.Data) != 2 {
+		t.Fatalf("expected the orders of both users, got %v", res.Data)
+	}
+
+	_, err = client.UpdateOrderStatus(userCtx, &proto.UpdateOrderRequest{OrderItemId: item.ID})
+	assertCode(t, err, codes.NotFound)
+	if _, err = client.UpdateOrderStatus(merchantCtx, &proto.UpdateOrderRequest{OrderItemId: item.ID}); err != nil {
+		t.Fatalf("UpdateOrderStatus: %v", err)
+	}
+
+	tracking, err := client.GetOrderTrackingDetails(testenv.WithToken(context.Background(), userToken), &proto.GetTrackingDetailsRequest{OrderItemId: item.ID})
+	if err != nil {
+		t.Fatalf("GetOrderTrackingDetails: %v", err)
+	}
+	if tracking.Data.Status != 1 || len(tracking.Data.Details) != 1 || tracking.Data.Details[0].Title != utils.TrackingTitles[1] {
+		t.Fatalf("unexpected tracking %v", tracking.Data)
+	}
+}
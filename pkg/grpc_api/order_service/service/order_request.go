@@ -6,9 +6,11 @@ import (
 	"net/http"
 
 	"github.com/akmal4410/gestapo/pkg/api/proto"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/order_service/db"
 	"github.com/akmal4410/gestapo/pkg/grpc_api/order_service/db/entity"
 	"github.com/akmal4410/gestapo/pkg/helpers"
 	"github.com/akmal4410/gestapo/pkg/helpers/service_helper"
+	"github.com/akmal4410/gestapo/pkg/service/images"
 	"github.com/akmal4410/gestapo/pkg/utils"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -17,8 +19,8 @@ import (
 func (handler *orderService) CreateOrder(ctx context.Context, in *proto.CreateOrderRequest) (*proto.Response, error) {
 	servicePayload, err := service_helper.ValidateServiceToken(ctx, handler.log, handler.token)
 	if err != nil {
-		handler.log.LogError("Error while ValidateServiceToken", err)
-		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+		handler.log.With(ctx).LogError("Error while ValidateServiceToken", err)
+		return nil, err
 	}
 
 	req := &entity.CreateOrderReq{
@@ -32,14 +34,14 @@ func (handler *orderService) CreateOrder(ctx context.Context, in *proto.CreateOr
 
 	err = helpers.ValidateBody(nil, req)
 	if err != nil {
-		handler.log.LogError("Error while ValidateBody", err)
+		handler.log.With(ctx).LogError("Error while ValidateBody", err)
 		return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
 	}
 
 	if req.PaymentMode == utils.COD {
-		res, err := handler.storage.CheckCODIsAvailable(servicePayload.UserID)
+		res, err := handler.storage.CheckCODIsAvailable(ctx, servicePayload.UserID)
 		if err != nil {
-			handler.log.LogError("Error while CheckCODIsAvailable", err)
+			handler.log.With(ctx).LogError("Error while CheckCODIsAvailable", err)
 			return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 		}
 		if !res {
@@ -54,9 +56,15 @@ func (handler *orderService) CreateOrder(ctx context.Context, in *proto.CreateOr
 
 	//assiging user id to create order request
 	req.UserID = servicePayload.UserID
-	err = handler.storage.CreateOrder(req)
+	err = handler.storage.CreateOrder(ctx, req)
 	if err != nil {
-		handler.log.LogError("Error while CreateOrder", err)
+		if err == db.ErrUnlistedProduct {
+			return nil, status.Errorf(codes.FailedPrecondition, "The cart holds products that are not available anymore")
+		}
+		if err == db.ErrInsufficientStock {
+			return nil, status.Errorf(codes.FailedPrecondition, "Not enough stock of the variants in the cart")
+		}
+		handler.log.With(ctx).LogError("Error while CreateOrder", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 	response := &proto.Response{
@@ -70,24 +78,22 @@ func (handler *orderService) CreateOrder(ctx context.Context, in *proto.CreateOr
 func (handler *orderService) GetUserOrders(ctx context.Context, in *proto.GetOrdersRequest) (*proto.GetOrderResponse, error) {
 	servicePayload, err := service_helper.ValidateServiceToken(ctx, handler.log, handler.token)
 	if err != nil {
-		handler.log.LogError("Error while ValidateServiceToken", err)
-		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+		handler.log.With(ctx).LogError("Error while ValidateServiceToken", err)
+		return nil, err
 	}
 
-	userOrdersEntities, err := handler.storage.GetUserOrders(servicePayload.UserID, in.Type)
+	userOrdersEntities, err := handler.storage.GetUserOrders(ctx, servicePayload.UserID, in.Type)
 	if err != nil {
-		handler.log.LogError("Error while GetUserOrders", err)
+		handler.log.With(ctx).LogError("Error while GetUserOrders", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
+	productImages := make([]string, len(userOrdersEntities))
+	for i, order := range userOrdersEntities {
+		productImages[i] = order.ProductImage
+	}
+	urls := images.URLs(ctx, handler.urls, productImages, images.Thumbnail)
 	for _, order := range userOrdersEntities {
-		if order.ProductImage != "" {
-			url, err := handler.s3.GetPreSignedURL(order.ProductImage)
-			if err != nil {
-				handler.log.LogError("Error while GetPreSignedURL", err)
-				return nil, status.Errorf(codes.Internal, utils.InternalServerError)
-			}
-			order.ProductImage = url
-		}
+		order.ProductImage = urls[order.ProductImage]
 	}
 
 	var orders []*proto.OrderResponse
@@ -100,6 +106,8 @@ func (handler *orderService) GetUserOrders(ctx context.Context, in *proto.GetOrd
 			Size:         float64(order.Size),
 			Price:        order.Price,
 			Status:       order.Status,
+			Sku:          order.SKU,
+			Options:      order.Options,
 		}
 		orders = append(orders, newProduct)
 	}
@@ -116,24 +124,22 @@ func (handler *orderService) GetUserOrders(ctx context.Context, in *proto.GetOrd
 func (handler *orderService) GetMerchantOrders(ctx context.Context, in *proto.GetOrdersRequest) (*proto.GetOrderResponse, error) {
 	servicePayload, err := service_helper.ValidateServiceToken(ctx, handler.log, handler.token)
 	if err != nil {
-		handler.log.LogError("Error while ValidateServiceToken", err)
-		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+		handler.log.With(ctx).LogError("Error while ValidateServiceToken", err)
+		return nil, err
 	}
 
-	userOrdersEntities, err := handler.storage.GetMerchantOrders(servicePayload.UserID, in.Type)
+	userOrdersEntities, err := handler.storage.GetMerchantOrders(ctx, servicePayload.UserID, in.Type)
 	if err != nil {
-		handler.log.LogError("Error while GetUserOrders", err)
+		handler.log.With(ctx).LogError("Error while GetUserOrders", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
+	productImages := make([]string, len(userOrdersEntities))
+	for i, order := range userOrdersEntities {
+		productImages[i] = order.ProductImage
+	}
+	urls := images.URLs(ctx, handler.urls, productImages, images.Thumbnail)
 	for _, order := range userOrdersEntities {
-		if order.ProductImage != "" {
-			url, err := handler.s3.GetPreSignedURL(order.ProductImage)
-			if err != nil {
-				handler.log.LogError("Error while GetPreSignedURL", err)
-				return nil, status.Errorf(codes.Internal, utils.InternalServerError)
-			}
-			order.ProductImage = url
-		}
+		order.ProductImage = urls[order.ProductImage]
 	}
 
 	var orders []*proto.OrderResponse
@@ -145,6 +151,8 @@ func (handler *orderService) GetMerchantOrders(ctx context.Context, in *proto.Ge
 			Size:         float64(order.Size),
 			Price:        order.Price,
 			Status:       order.Status,
+			Sku:          order.SKU,
+			Options:      order.Options,
 		}
 		orders = append(orders, newProduct)
 	}
@@ -161,35 +169,35 @@ func (handler *orderService) GetMerchantOrders(ctx context.Context, in *proto.Ge
 func (handler *orderService) UpdateOrderStatus(ctx context.Context, in *proto.UpdateOrderRequest) (*proto.Response, error) {
 	payload, err := service_helper.ValidateServiceToken(ctx, handler.log, handler.token)
 	if err != nil {
-		handler.log.LogError("Error while ValidateServiceToken", err)
-		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+		handler.log.With(ctx).LogError("Error while ValidateServiceToken", err)
+		return nil, err
 	}
 
-	res, err := handler.storage.IsMerchantCanUpdate(in.GetOrderItemId(), payload.UserID)
+	res, err := handler.storage.IsMerchantCanUpdate(ctx, in.GetOrderItemId(), payload.UserID)
 	if err != nil {
-		handler.log.LogError("Error while CanEditDeleteCartItem", err)
+		handler.log.With(ctx).LogError("Error while CanEditDeleteCartItem", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 	if !res {
 		err := errors.New("error while IsMerchantCanUpdate: Not found")
-		handler.log.LogError("Error", err)
+		handler.log.With(ctx).LogError("Error", err)
 		return nil, status.Errorf(codes.NotFound, utils.NotFound)
 	}
 
-	statusCount, err := handler.storage.GetMerchantTrackingStatus(in.GetOrderItemId())
+	statusCount, err := handler.storage.GetMerchantTrackingStatus(ctx, in.GetOrderItemId())
 	if err != nil {
-		handler.log.LogError("Error while GetMerchantTrackingStatus", err)
+		handler.log.With(ctx).LogError("Error while GetMerchantTrackingStatus", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 	if statusCount >= 3 {
 		err := errors.New("error while GetMerchantTrackingStatus: Oder is already completed")
-		handler.log.LogError("Error", err)
+		handler.log.With(ctx).LogError("Error", err)
 		return nil, status.Errorf(codes.PermissionDenied, "Order is already completed")
 	}
 
-	err = handler.storage.UpdateOrderStatus(in.GetOrderItemId())
+	err = handler.storage.UpdateOrderStatus(ctx, in.GetOrderItemId())
 	if err != nil {
-		handler.log.LogError("Error while UpdateOrderStatus", err)
+		handler.log.With(ctx).LogError("Error while UpdateOrderStatus", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
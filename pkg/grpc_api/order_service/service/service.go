@@ -7,7 +7,8 @@ import (
 	"github.com/akmal4410/gestapo/pkg/grpc_api/order_service/db"
 	"github.com/akmal4410/gestapo/pkg/helpers/logger"
 	"github.com/akmal4410/gestapo/pkg/helpers/token"
-	s3 "github.com/akmal4410/gestapo/pkg/service/s3_service"
+	objectstore "github.com/akmal4410/gestapo/pkg/service/object_store"
+	urlsigner "github.com/akmal4410/gestapo/pkg/service/url_signer"
 )
 
 // orderService serves gRPC requests for our e-commerce service.
@@ -15,29 +16,41 @@ type orderService struct {
 	proto.UnimplementedOrderServiceServer
 	config  *config.Config
 	log     logger.Logger
-	s3      *s3.S3Service
-	storage *db.OrderStore
+	urls    *urlsigner.Signer
+	storage db.OrderRepository
 	token   token.Maker
 }
 
+// Dependencies are the stores and the clients used by the order service.
+type Dependencies struct {
+	Store db.OrderRepository
+	URLs  *urlsigner.Signer
+}
+
 // NewOrderService creates a new gRPC server.
 func NewOrderService(storage *database.Storage, config *config.Config, log logger.Logger, tokenMaker token.Maker) *orderService {
-	server := &orderService{
-		config: config,
-		log:    log,
-		token:  tokenMaker,
+	files, err := objectstore.New(config)
+	if err != nil {
+		log.LogFatal("Error while Initializing object store ", err)
 	}
+	urls, err := urlsigner.New(config, files, log)
+	if err != nil {
+		log.LogFatal("Error while Initializing url signer ", err)
+	}
+	deps := Dependencies{
+		Store: db.NewOrderStore(storage),
+		URLs:  urls,
+	}
+	return NewOrderServiceWith(deps, config, log, tokenMaker)
+}
 
-	s3 := s3.NewS3Service(
-		config.AwsS3.BucketName,
-		config.AwsS3.Region,
-		config.AwsS3.AccessKey,
-		config.AwsS3.SecretKey,
-	)
-
-	orderStore := db.NewOrderStore(storage)
-
-	server.s3 = s3
-	server.storage = orderStore
-	return server
+// NewOrderServiceWith creates a new gRPC server working with deps.
+func NewOrderServiceWith(deps Dependencies, config *config.Config, log logger.Logger, tokenMaker token.Maker) *orderService {
+	return &orderService{
+		config:  config,
+		log:     log,
+		token:   tokenMaker,
+		urls:    deps.URLs,
+		storage: deps.Store,
+	}
 }
@@ -19,13 +19,13 @@ func (handler *orderService) GetOrderTrackingDetails(ctx context.Context, in *pr
 	}
 	err := helpers.ValidateBody(nil, req)
 	if err != nil {
-		handler.log.LogError("Error while ValidateBody", err)
+		handler.log.With(ctx).LogError("Error while ValidateBody", err)
 		return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
 	}
 
-	trackingEntities, err := handler.storage.GetOrderTrackingDetails(in.GetOrderItemId())
+	trackingEntities, err := handler.storage.GetOrderTrackingDetails(ctx, in.GetOrderItemId())
 	if err != nil {
-		handler.log.LogError("Error while GetOrderTrackingDetails", err)
+		handler.log.With(ctx).LogError("Error while GetOrderTrackingDetails", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
@@ -1,5 +1,7 @@
 package entity
 
+import "time"
+
 type GetProductRes struct {
 	ID            string     `json:"id"`
 	MerchantID    *string    `json:"merchant_id,omitempty"`
@@ -12,12 +14,34 @@ type GetProductRes struct {
 	DiscountPrice *float64   `json:"discount_price,omitempty"`
 	ReviewStar    *float64   `json:"review_star,omitempty"`
 	WishlistID    *string    `json:"wishlist_id,omitempty"`
+	OptionTypes   []string   `json:"option_types,omitempty"`
+	Variants      []*Variant `json:"variants,omitempty"`
+	Attributes    Options    `json:"attributes,omitempty"`
+	// ReviewStar, ReviewCount, UnitsSold and WishlistCount are read from the product stats
+	ReviewCount   int64 `json:"review_count,omitempty"`
+	UnitsSold     int64 `json:"units_sold,omitempty"`
+	WishlistCount int64 `json:"wishlist_count,omitempty"`
+	// RatingDistribution is set by GetProductById only
+	RatingDistribution map[int32]int64 `json:"rating_distribution,omitempty"`
+	// Status, PublishAt, UnpublishAt and StatusReason are the lifecycle of the
+	// product, set for its merchant and the admins only
+	Status       *string    `json:"status,omitempty"`
+	PublishAt    *time.Time `json:"publish_at,omitempty"`
+	UnpublishAt  *time.Time `json:"unpublish_at,omitempty"`
+	StatusReason *string    `json:"status_reason,omitempty"`
+	// Listed tells whether the users see the product, see listed(p)
+	Listed bool `json:"-"`
 }
 
 type AddReviewReq struct {
-	ProductID   string  `json:"product_id" validate:"required"`
-	OrderItemID string  `json:"cart_item_id" validate:"required"`
-	UserID      string  `json:"user_id" validate:"required"`
-	Star        float32 `json:"star" validate:"required"`
-	Review      string  `json:"review" validate:"required"`
+	ProductID   string   `json:"product_id" validate:"required"`
+	OrderItemID string   `json:"cart_item_id" validate:"required"`
+	UserID      string   `json:"user_id" validate:"required"`
+	Star        float32  `json:"star" validate:"required,min=1,max=5"`
+	Review      string   `json:"review" validate:"required"`
+	Images      []string `json:"images"`
+	// SessionIDs are the upload sessions of the images, attached with the review
+	SessionIDs []string `json:"session_ids"`
+	// Flags are the automatic checks the review failed, it is PENDING with flags
+	Flags []string `json:"flags"`
 }
@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+// ProductState is where a product is in its lifecycle. Only the PUBLISHED
+// products are listed to the users, from PublishAt until UnpublishAt.
+type ProductState struct {
+	ProductID   string     `json:"product_id"`
+	Status      string     `json:"status"`
+	PublishAt   *time.Time `json:"publish_at,omitempty"`
+	UnpublishAt *time.Time `json:"unpublish_at,omitempty"`
+	// StatusReason is why the review sent the product back to draft
+	StatusReason *string `json:"status_reason,omitempty"`
+}
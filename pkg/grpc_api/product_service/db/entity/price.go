@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+// PricePoint is the price of a product from ChangedAt until the next point.
+type PricePoint struct {
+	Price           float64   `json:"price"`
+	DiscountPercent *float64  `json:"discount_percent,omitempty"`
+	EffectivePrice  float64   `json:"effective_price"`
+	ChangedAt       time.Time `json:"changed_at"`
+}
+
+// PriceDropAlert is the notification of the users wishlisting a product whose
+// effective price dropped by Threshold percent or more. Body is formatted with
+// the name of the product, its price and its previous price.
+type PriceDropAlert struct {
+	Threshold float64 `json:"threshold"`
+	Title     string  `json:"title"`
+	Body      string  `json:"body"`
+}
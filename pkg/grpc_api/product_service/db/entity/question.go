@@ -0,0 +1,78 @@
+package entity
+
+import "time"
+
+// The authors of the answers.
+const (
+	AnswerByMerchant = "MERCHANT"
+	AnswerByBuyer    = "VERIFIED_BUYER"
+)
+
+// QuestionNotification is the notification of the merchant of the product when a
+// question is published. Body is formatted with the name of the product.
+type QuestionNotification struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+// AddQuestionReq adds a question, held for moderation when there are Flags.
+type AddQuestionReq struct {
+	ProductID string
+	UserID    string
+	Question  string
+	Flags     []string
+	// Notification is sent to the merchant once the question is published
+	Notification QuestionNotification
+}
+
+// QuestionsReq lists the published questions of a product, the newest first.
+// UserID is the reader whose upvotes are returned.
+type QuestionsReq struct {
+	ProductID string
+	UserID    string
+	Limit     int
+	Offset    int
+}
+
+type Question struct {
+	ID         string    `json:"id"`
+	ProductID  string    `json:"product_id"`
+	MerchantID string    `json:"merchant_id"`
+	UserID     string    `json:"user_id"`
+	AuthorName string    `json:"author_name"`
+	Question   string    `json:"question"`
+	Status     string    `json:"status"`
+	Answers    []*Answer `json:"answers"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type QuestionsRes struct {
+	Questions []*Question `json:"questions"`
+	Total     int64       `json:"total"`
+}
+
+// AddAnswerReq adds an answer to a question, held for moderation when there are Flags.
+type AddAnswerReq struct {
+	QuestionID string
+	UserID     string
+	Answer     string
+	ByMerchant bool
+	Flags      []string
+}
+
+type Answer struct {
+	ID         string `json:"id"`
+	QuestionID string `json:"question_id"`
+	UserID     string `json:"user_id"`
+	AuthorName string `json:"author_name"`
+	Answer     string `json:"answer"`
+	ByMerchant bool   `json:"by_merchant"`
+	Upvotes    int64  `json:"upvotes"`
+	Upvoted    bool   `json:"upvoted"`
+	Status     string `json:"status"`
+	// QuestionStatus is the status of the question answered
+	QuestionStatus string    `json:"question_status"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
@@ -0,0 +1,9 @@
+package entity
+
+// RecommendationWeights are the points a pair of products gets for each order,
+// cart and wishlist holding both of them.
+type RecommendationWeights struct {
+	Order    float64 `json:"order"`
+	Cart     float64 `json:"cart"`
+	Wishlist float64 `json:"wishlist"`
+}
@@ -0,0 +1,133 @@
+package entity
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// The orders of the reviews.
+const (
+	ReviewSortNewest  = "newest"
+	ReviewSortOldest  = "oldest"
+	ReviewSortHelpful = "helpful"
+	ReviewSortHighest = "highest"
+	ReviewSortLowest  = "lowest"
+)
+
+// The votes of the users on the reviews.
+const (
+	VoteHelpful    = "HELPFUL"
+	VoteNotHelpful = "NOT_HELPFUL"
+)
+
+// MaxReviewPhotos is how many photos a review can have.
+const MaxReviewPhotos = 5
+
+// The flags of the reviews held for moderation, by the automatic checks or the reports.
+const (
+	FlagBannedWord  = "BANNED_WORD"
+	FlagLinkSpam    = "LINK_SPAM"
+	FlagDuplicate   = "DUPLICATE_TEXT"
+	FlagRatingBurst = "RATING_BURST"
+	FlagReported    = "REPORTED"
+)
+
+// The reasons of the reports on the reviews.
+const (
+	ReportSpam      = "SPAM"
+	ReportOffensive = "OFFENSIVE"
+	ReportOffTopic  = "OFF_TOPIC"
+	ReportFake      = "FAKE"
+	ReportOther     = "OTHER"
+)
+
+// ReviewReport is the report of a user on a review.
+type ReviewReport struct {
+	ID       string `json:"id"`
+	ReviewID string `json:"review_id"`
+	UserID   string `json:"user_id"`
+	Reason   string `json:"reason"`
+	Details  string `json:"details"`
+}
+
+// ReviewsReq lists the published reviews of a product, UserID is the reader whose votes are returned.
+type ReviewsReq struct {
+	ProductID  string
+	UserID     string
+	Sort       string
+	Star       *int32
+	WithPhotos bool
+	Limit      int
+	Offset     int
+}
+
+type Review struct {
+	ID              string     `json:"id"`
+	ProductID       string     `json:"product_id"`
+	MerchantID      string     `json:"merchant_id"`
+	UserID          string     `json:"user_id"`
+	AuthorName      string     `json:"author_name"`
+	Star            float32    `json:"star"`
+	Review          string     `json:"review"`
+	Images          []string   `json:"images"`
+	HelpfulCount    int64      `json:"helpful_count"`
+	NotHelpfulCount int64      `json:"not_helpful_count"`
+	MyVote          *bool      `json:"my_vote,omitempty"`
+	Reply           *string    `json:"reply,omitempty"`
+	RepliedAt       *time.Time `json:"replied_at,omitempty"`
+	ReplyUpdatedAt  *time.Time `json:"reply_updated_at,omitempty"`
+	EditedAt        *time.Time `json:"edited_at,omitempty"`
+	Status          string     `json:"status"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+type ReviewsRes struct {
+	Reviews []*Review `json:"reviews"`
+	Total   int64     `json:"total"`
+}
+
+// EditReviewReq changes the fields that are set. The removed images are taken out
+// of the images of the review before the added ones are appended.
+type EditReviewReq struct {
+	ReviewID      string
+	Star          *float32
+	Review        *string
+	AddedImages   []string
+	RemovedImages []string
+	// SessionIDs are the upload sessions of the added images
+	SessionIDs []string
+	// Flags send the review back to the moderation queue when there are some
+	Flags []string
+}
+
+// NormalizeReviewText lowers the case of the text and collapses its spaces, the
+// duplicate reviews are found by their normalized text.
+func NormalizeReviewText(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// RatingStar is the star of the rating distribution a review counts for,
+// the star of the review rounded half away from zero.
+func RatingStar(star float32) int32 {
+	return int32(math.Round(float64(star)))
+}
+
+// EditImages returns the images once the removed ones are taken out and the added ones appended.
+func EditImages(images, removed, added []string) []string {
+	edited := make([]string, 0, len(images)+len(added))
+	for _, image := range images {
+		keep := true
+		for _, removedImage := range removed {
+			if image == removedImage {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			edited = append(edited, image)
+		}
+	}
+	return append(edited, added...)
+}
@@ -0,0 +1,137 @@
+package entity
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// The orders of the search results.
+const (
+	SortRelevance = "relevance"
+	SortPriceAsc  = "price_asc"
+	SortPriceDesc = "price_desc"
+	SortRating    = "rating"
+	SortNewest    = "newest"
+)
+
+// HighlightStart and HighlightStop wrap the matched words in the highlights.
+const (
+	HighlightStart = "<mark>"
+	HighlightStop  = "</mark>"
+)
+
+// SearchProductsReq filters the products matching Query, every product when it is empty.
+// The price filters and facets use the price after the running discount.
+type SearchProductsReq struct {
+	Query      string
+	CategoryID *string
+	MerchantID *string
+	MinPrice   *float64
+	MaxPrice   *float64
+	MinRating  *float64
+	Size       *float64
+	Sort       string
+	Limit      int
+	Offset     int
+}
+
+type SearchHit struct {
+	GetProductRes
+	CategoryID           string
+	Score                float64
+	NameHighlight        string
+	DescriptionHighlight string
+}
+
+// FacetCount is the number of matching products for one value of a facet.
+type FacetCount struct {
+	Value string
+	Label string
+	Count int64
+}
+
+// SearchFacets count the products for each value of a filter. A facet applies
+// every filter but its own, so the other values can be picked from it.
+type SearchFacets struct {
+	Categories  []FacetCount
+	PriceRanges []FacetCount
+	Ratings     []FacetCount
+	Merchants   []FacetCount
+	Sizes       []FacetCount
+}
+
+type SearchProductsRes struct {
+	Hits   []*SearchHit
+	Facets SearchFacets
+	Total  int64
+}
+
+// PriceRange is a bucket of the price facet, from Min included to Max excluded.
+type PriceRange struct {
+	Min float64
+	Max float64
+}
+
+// PriceRanges are the buckets of the price facet, the last one has no upper bound.
+var PriceRanges = []PriceRange{
+	{0, 25},
+	{25, 50},
+	{50, 100},
+	{100, 200},
+	{200, math.Inf(1)},
+}
+
+// Value is the value of the bucket in the facet, like "25-50" or "200-".
+func (r PriceRange) Value() string {
+	if math.IsInf(r.Max, 1) {
+		return fmt.Sprintf("%g-", r.Min)
+	}
+	return fmt.Sprintf("%g-%g", r.Min, r.Max)
+}
+
+func (r PriceRange) Label() string {
+	if math.IsInf(r.Max, 1) {
+		return fmt.Sprintf("%g and above", r.Min)
+	}
+	return fmt.Sprintf("%g to %g", r.Min, r.Max)
+}
+
+// RatingThresholds are the values of the rating facet, each one counts the
+// products rated at least that many stars.
+var RatingThresholds = []float64{4, 3, 2, 1}
+
+// SearchQueryLog is a search logged for the trending searches.
+type SearchQueryLog struct {
+	Query      string
+	UserID     string
+	CategoryID *string
+	Results    int64
+}
+
+// Suggestion is a completion of the prefix typed by the user.
+type Suggestion struct {
+	ID         string
+	Text       string
+	Popularity int64
+}
+
+type Suggestions struct {
+	Queries    []Suggestion
+	Products   []Suggestion
+	Categories []Suggestion
+	Merchants  []Suggestion
+}
+
+// TrendingSearchesReq asks for the queries searched the most from From to To, in a category when CategoryID is set.
+type TrendingSearchesReq struct {
+	CategoryID *string
+	From       time.Time
+	To         time.Time
+	Limit      int
+}
+
+type QueryCount struct {
+	Query string
+	Count int64
+}
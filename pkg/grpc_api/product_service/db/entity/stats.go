@@ -0,0 +1,18 @@
+package entity
+
+// ProductStats are the aggregates of a product kept in product_stats.
+type ProductStats struct {
+	RatingCount   int64    `json:"rating_count"`
+	RatingAverage *float64 `json:"rating_average,omitempty"`
+	UnitsSold     int64    `json:"units_sold"`
+	WishlistCount int64    `json:"wishlist_count"`
+	ViewCount     int64    `json:"view_count"`
+}
+
+// StatsDrift is a product whose stored stats differ from the ones computed from
+// the reviews, the order items, the wishlists and the views. Stored is nil without stats.
+type StatsDrift struct {
+	ProductID string        `json:"product_id"`
+	Stored    *ProductStats `json:"stored"`
+	Expected  ProductStats  `json:"expected"`
+}
@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// VariantStock is the stock of a variant of a product.
+type VariantStock struct {
+	InventoryID string `json:"inventory_id"`
+	Quantity    int32  `json:"quantity"`
+}
+
+// BackInStockAlert is the notification of the users waiting for a variant back in
+// stock. A batch notifies the oldest subscriptions of a variant, PerUnit for each
+// unit in stock and BatchSize at most, and the next batch waits BatchInterval.
+// Body is formatted with the name of the product.
+type BackInStockAlert struct {
+	BatchSize     int           `json:"batch_size"`
+	PerUnit       int           `json:"per_unit"`
+	BatchInterval time.Duration `json:"batch_interval"`
+	Title         string        `json:"title"`
+	Body          string        `json:"body"`
+}
@@ -0,0 +1,26 @@
+package entity
+
+import "time"
+
+// ReviewImage is the purpose of the upload sessions of the review photos.
+const ReviewImage = "REVIEW_IMAGE"
+
+// Statuses of the upload sessions.
+const (
+	UploadPending  = "PENDING"
+	UploadAttached = "ATTACHED"
+)
+
+// UploadSession is an image uploaded by the client straight to the object store.
+type UploadSession struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"user_id"`
+	Purpose     string    `json:"purpose"`
+	TargetID    *string   `json:"target_id,omitempty"`
+	Key         string    `json:"key"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Status      string    `json:"status"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,63 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// SizeOption is the option type of the sizes, the only one of the products
+// added before the variants.
+const SizeOption = "size"
+
+// Options are the values of the option types of a variant, like
+// {"color": "red", "size": "8"}. They are stored as jsonb.
+type Options map[string]string
+
+func (options Options) Value() (driver.Value, error) {
+	if options == nil {
+		return "{}", nil
+	}
+	value, err := json.Marshal(options)
+	return string(value), err
+}
+
+func (options *Options) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		*options = Options{}
+		return nil
+	case []byte:
+		return json.Unmarshal(src, options)
+	case string:
+		return json.Unmarshal([]byte(src), options)
+	}
+	return fmt.Errorf("cannot scan %T into Options", src)
+}
+
+// Size returns the value of the size option as stored in inventories.size, 0 without one.
+func (options Options) Size() (float64, error) {
+	value, ok := options[SizeOption]
+	if !ok {
+		return 0, nil
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// SizeValue formats a size as the value of the size option.
+func SizeValue(size float64) string {
+	return strconv.FormatFloat(size, 'f', -1, 64)
+}
+
+// Variant is a row of inventories, its price is the one of the product unless
+// the variant overrides it.
+type Variant struct {
+	ID            string   `json:"id"`
+	SKU           *string  `json:"sku,omitempty"`
+	Options       Options  `json:"options"`
+	Price         float64  `json:"price"`
+	DiscountPrice *float64 `json:"discount_price,omitempty"`
+	Images        []string `json:"images,omitempty"`
+	Quantity      int32    `json:"quantity"`
+}
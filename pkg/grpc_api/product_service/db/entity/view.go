@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+// Viewer is the user viewing the products or, for a guest, the device.
+// A user viewing from a device gets the history of its guest views too.
+type Viewer struct {
+	UserID   *string `json:"user_id,omitempty"`
+	DeviceID *string `json:"device_id,omitempty"`
+}
+
+// RecentlyViewed is a product with the last time the viewer viewed it.
+type RecentlyViewed struct {
+	Product  *GetProductRes `json:"product"`
+	ViewedAt time.Time      `json:"viewed_at"`
+}
@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/akmal4410/gestapo/internal/database/memory"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+func (store *MemoryProductStore) GetPriceHistory(ctx context.Context, productId string, since time.Time) ([]*entity.PricePoint, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	start := since
+	if last := store.db.LastPrice(productId, &since); last != nil {
+		start = last.ChangedAt
+	}
+	points := []*entity.PricePoint{}
+	for _, history := range store.db.PriceHistories {
+		if history.ProductID != productId || history.ChangedAt.Before(start) {
+			continue
+		}
+		points = append(points, &entity.PricePoint{
+			Price:           history.Price,
+			DiscountPercent: history.DiscountPercent,
+			EffectivePrice:  history.EffectivePrice,
+			ChangedAt:       history.ChangedAt,
+		})
+	}
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].ChangedAt.Before(points[j].ChangedAt)
+	})
+	return points, nil
+}
+
+func (store *MemoryProductStore) RecordPrices(ctx context.Context) (int64, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	return store.db.RecordPrices("", time.Now()), nil
+}
+
+func (store *MemoryProductStore) NotifyPriceDrops(ctx context.Context, alert entity.PriceDropAlert) (int64, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	now := time.Now()
+	// the last price of each product is compared with the one it had at the last
+	// check, so the changes between two checks notify once
+	first := map[string]*memory.PriceHistory{}
+	last := map[string]*memory.PriceHistory{}
+	for _, history := range store.db.PriceHistories {
+		if history.AlertCheckedAt != nil {
+			continue
+		}
+		history.AlertCheckedAt = &now
+		if f, ok := first[history.ProductID]; !ok || history.ChangedAt.Before(f.ChangedAt) {
+			first[history.ProductID] = history
+		}
+		if l, ok := last[history.ProductID]; !ok || history.ChangedAt.After(l.ChangedAt) {
+			last[history.ProductID] = history
+		}
+	}
+
+	var notified int64
+	for productID, latest := range last {
+		previous := store.db.LastPrice(productID, &first[productID].ChangedAt)
+		product, ok := store.db.Products[productID]
+		if previous == nil || !ok || !memory.Listed(product) || latest.EffectivePrice > previous.EffectivePrice*(1-alert.Threshold/100) {
+			continue
+		}
+		body := fmt.Sprintf(alert.Body, product.ProductName, strconv.FormatFloat(latest.EffectivePrice, 'f', 2, 64), strconv.FormatFloat(previous.EffectivePrice, 'f', 2, 64))
+		for _, wishlist := range store.db.Wishlists {
+			if wishlist.ProductID != productID {
+				continue
+			}
+			id, productID := uuid.NewString(), productID
+			store.db.Notifications[id] = &memory.Notification{
+				ID:        id,
+				UserID:    wishlist.UserID,
+				Type:      utils.NotificationPriceDrop,
+				Title:     alert.Title,
+				Body:      body,
+				ProductID: &productID,
+				CreatedAt: now,
+			}
+			notified++
+		}
+	}
+	return notified, nil
+}
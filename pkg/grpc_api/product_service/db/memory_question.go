@@ -0,0 +1,262 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/akmal4410/gestapo/internal/database/memory"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+func (store *MemoryProductStore) AddQuestion(ctx context.Context, req *entity.AddQuestionReq) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	product, ok := store.db.Products[req.ProductID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	now := time.Now()
+	id := uuid.NewString()
+	store.db.Questions[id] = &memory.Question{
+		ID:        id,
+		ProductID: req.ProductID,
+		UserID:    req.UserID,
+		Question:  req.Question,
+		Status:    reviewStatus(req.Flags),
+		Flags:     append([]string{}, req.Flags...),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if store.db.Questions[id].Status == utils.ReviewPublished {
+		notificationId := uuid.NewString()
+		productId := product.ID
+		store.db.Notifications[notificationId] = &memory.Notification{
+			ID:        notificationId,
+			UserID:    product.MerchantID,
+			Type:      utils.NotificationNewQuestion,
+			Title:     req.Notification.Title,
+			Body:      fmt.Sprintf(req.Notification.Body, product.ProductName),
+			ProductID: &productId,
+			CreatedAt: now,
+		}
+	}
+	return nil
+}
+
+// authorName returns the full name of the user, or its user name when it has none.
+// The caller must hold the lock.
+func (store *MemoryProductStore) authorName(userId string) string {
+	user, ok := store.db.Users[userId]
+	if !ok {
+		return ""
+	}
+	if user.FullName != nil && *user.FullName != "" {
+		return *user.FullName
+	}
+	return user.UserName
+}
+
+// question returns the question without its answers. The caller must hold the lock.
+func (store *MemoryProductStore) question(question *memory.Question) *entity.Question {
+	res := &entity.Question{
+		ID:         question.ID,
+		ProductID:  question.ProductID,
+		UserID:     question.UserID,
+		AuthorName: store.authorName(question.UserID),
+		Question:   question.Question,
+		Status:     question.Status,
+		Answers:    []*entity.Answer{},
+		CreatedAt:  question.CreatedAt,
+		UpdatedAt:  question.UpdatedAt,
+	}
+	if product, ok := store.db.Products[question.ProductID]; ok {
+		res.MerchantID = product.MerchantID
+	}
+	return res
+}
+
+// answer returns the answer with its upvotes, upvoted by userId. The caller must hold the lock.
+func (store *MemoryProductStore) answer(answer *memory.Answer, userId string) *entity.Answer {
+	res := &entity.Answer{
+		ID:         answer.ID,
+		QuestionID: answer.QuestionID,
+		UserID:     answer.UserID,
+		AuthorName: store.authorName(answer.UserID),
+		Answer:     answer.Answer,
+		ByMerchant: answer.ByMerchant,
+		Status:     answer.Status,
+		CreatedAt:  answer.CreatedAt,
+		UpdatedAt:  answer.UpdatedAt,
+	}
+	if question, ok := store.db.Questions[answer.QuestionID]; ok {
+		res.QuestionStatus = question.Status
+	}
+	for _, vote := range store.db.AnswerVotes {
+		if vote.AnswerID == answer.ID {
+			res.Upvotes++
+			res.Upvoted = res.Upvoted || vote.UserID == userId
+		}
+	}
+	return res
+}
+
+// listedQuestion returns the question when its product is listed. The caller must hold the lock.
+func (store *MemoryProductStore) listedQuestion(questionId string) (*memory.Question, bool) {
+	question, ok := store.db.Questions[questionId]
+	if !ok {
+		return nil, false
+	}
+	product, ok := store.db.Products[question.ProductID]
+	return question, ok && memory.Listed(product)
+}
+
+func (store *MemoryProductStore) GetQuestion(ctx context.Context, questionId string) (*entity.Question, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	question, ok := store.listedQuestion(questionId)
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return store.question(question), nil
+}
+
+func (store *MemoryProductStore) GetProductQuestions(ctx context.Context, req *entity.QuestionsReq) (*entity.QuestionsRes, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	questions := []*entity.Question{}
+	if product, ok := store.db.Products[req.ProductID]; !ok || !memory.Listed(product) {
+		return &entity.QuestionsRes{Questions: questions}, nil
+	}
+	for _, question := range store.db.Questions {
+		if question.ProductID == req.ProductID && question.Status == utils.ReviewPublished {
+			questions = append(questions, store.question(question))
+		}
+	}
+	sort.Slice(questions, func(i, j int) bool {
+		a, b := questions[i], questions[j]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+
+	res := &entity.QuestionsRes{Total: int64(len(questions))}
+	if req.Offset < len(questions) {
+		questions = questions[req.Offset:]
+	} else {
+		questions = nil
+	}
+	if len(questions) > req.Limit {
+		questions = questions[:req.Limit]
+	}
+	res.Questions = append([]*entity.Question{}, questions...)
+
+	for _, question := range res.Questions {
+		for _, answer := range store.db.Answers {
+			if answer.QuestionID == question.ID && answer.Status == utils.ReviewPublished {
+				question.Answers = append(question.Answers, store.answer(answer, req.UserID))
+			}
+		}
+		sortAnswers(question.Answers)
+	}
+	return res, nil
+}
+
+// sortAnswers orders the answers like the query of the store, the answer of the
+// merchant first, then the most upvoted and the oldest.
+func sortAnswers(answers []*entity.Answer) {
+	sort.Slice(answers, func(i, j int) bool {
+		a, b := answers[i], answers[j]
+		if a.ByMerchant != b.ByMerchant {
+			return a.ByMerchant
+		}
+		if a.Upvotes != b.Upvotes {
+			return a.Upvotes > b.Upvotes
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+}
+
+func (store *MemoryProductStore) HasBoughtProduct(ctx context.Context, productId, userId string) (bool, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	for _, item := range store.db.OrderItems {
+		if item.ProductID != productId || item.Status != utils.OrderCompleted {
+			continue
+		}
+		if order, ok := store.db.OrderDetails[item.OrderID]; ok && order.UserID == userId {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (store *MemoryProductStore) AddAnswer(ctx context.Context, req *entity.AddAnswerReq) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	if _, ok := store.db.Questions[req.QuestionID]; !ok {
+		return sql.ErrNoRows
+	}
+	now := time.Now()
+	id := uuid.NewString()
+	store.db.Answers[id] = &memory.Answer{
+		ID:         id,
+		QuestionID: req.QuestionID,
+		UserID:     req.UserID,
+		Answer:     req.Answer,
+		ByMerchant: req.ByMerchant,
+		Status:     reviewStatus(req.Flags),
+		Flags:      append([]string{}, req.Flags...),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	return nil
+}
+
+func (store *MemoryProductStore) GetAnswer(ctx context.Context, answerId string) (*entity.Answer, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	answer, ok := store.db.Answers[answerId]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	if _, ok := store.listedQuestion(answer.QuestionID); !ok {
+		return nil, sql.ErrNoRows
+	}
+	return store.answer(answer, ""), nil
+}
+
+func (store *MemoryProductStore) UpvoteAnswer(ctx context.Context, answerId, userId string, upvote bool) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	if _, ok := store.db.Answers[answerId]; !ok {
+		return sql.ErrNoRows
+	}
+	for id, vote := range store.db.AnswerVotes {
+		if vote.AnswerID == answerId && vote.UserID == userId {
+			if !upvote {
+				delete(store.db.AnswerVotes, id)
+			}
+			return nil
+		}
+	}
+	if !upvote {
+		return nil
+	}
+	id := uuid.NewString()
+	store.db.AnswerVotes[id] = &memory.AnswerVote{
+		ID:        id,
+		AnswerID:  answerId,
+		UserID:    userId,
+		CreatedAt: time.Now(),
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package db
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/akmal4410/gestapo/internal/database/memory"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/utils"
+)
+
+func (store *MemoryProductStore) ComputeRecommendations(ctx context.Context, weights entity.RecommendationWeights, perProduct int) (int64, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+
+	// the products of each order, cart and user, an order or a cart holding a product twice counts once
+	ordered := map[string]map[string]bool{}
+	for _, item := range store.db.OrderItems {
+		if item.Status != utils.OrderCancelled {
+			addToGroup(ordered, item.OrderID, item.ProductID)
+		}
+	}
+	carted := map[string]map[string]bool{}
+	for _, item := range store.db.CartItems {
+		addToGroup(carted, item.CartID, item.ProductID)
+	}
+	wished := map[string]map[string]bool{}
+	for _, wishlist := range store.db.Wishlists {
+		addToGroup(wished, wishlist.UserID, wishlist.ProductID)
+	}
+
+	scores := map[string]map[string]float64{}
+	addPairs := func(groups map[string]map[string]bool, weight float64) {
+		for _, group := range groups {
+			for productID := range group {
+				for relatedID := range group {
+					if productID == relatedID {
+						continue
+					}
+					if scores[productID] == nil {
+						scores[productID] = map[string]float64{}
+					}
+					scores[productID][relatedID] += weight
+				}
+			}
+		}
+	}
+	addPairs(ordered, weights.Order)
+	addPairs(carted, weights.Cart)
+	addPairs(wished, weights.Wishlist)
+
+	now := time.Now()
+	store.db.Recommendations = map[string]*memory.ProductRecommendation{}
+	for productID, related := range scores {
+		var ranked []*memory.ProductRecommendation
+		for relatedID, score := range related {
+			if score > 0 {
+				ranked = append(ranked, &memory.ProductRecommendation{ProductID: productID, RelatedID: relatedID, Score: score, UpdatedAt: now})
+			}
+		}
+		sort.Slice(ranked, func(i, j int) bool {
+			if ranked[i].Score != ranked[j].Score {
+				return ranked[i].Score > ranked[j].Score
+			}
+			return ranked[i].RelatedID < ranked[j].RelatedID
+		})
+		if len(ranked) > perProduct {
+			ranked = ranked[:perProduct]
+		}
+		for _, recommendation := range ranked {
+			store.db.Recommendations[memory.RecommendationKey(productID, recommendation.RelatedID)] = recommendation
+		}
+	}
+	return int64(len(store.db.Recommendations)), nil
+}
+
+func addToGroup(groups map[string]map[string]bool, groupID, productID string) {
+	if groups[groupID] == nil {
+		groups[groupID] = map[string]bool{}
+	}
+	groups[groupID][productID] = true
+}
+
+func (store *MemoryProductStore) GetRelatedProducts(ctx context.Context, productId, userId string, limit int) ([]*entity.GetProductRes, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	target, ok := store.db.Products[productId]
+	if !ok {
+		return []*entity.GetProductRes{}, nil
+	}
+
+	var candidates []*recommendationCandidate
+	for _, product := range store.db.Products {
+		if product.ID == target.ID || !memory.Listed(product) {
+			continue
+		}
+		recommendation, ok := store.db.Recommendations[memory.RecommendationKey(target.ID, product.ID)]
+		if !ok && product.CategoryID != target.CategoryID {
+			continue
+		}
+		candidate := &recommendationCandidate{
+			product:  product,
+			stats:    store.db.Stats(product.ID),
+			distance: math.Abs(product.Price - target.Price),
+		}
+		if ok {
+			candidate.score = &recommendation.Score
+		}
+		candidates = append(candidates, candidate)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if (a.score == nil) != (b.score == nil) {
+			return a.score != nil
+		}
+		if a.score != nil && *a.score != *b.score {
+			return *a.score > *b.score
+		}
+		if a.distance != b.distance {
+			return a.distance < b.distance
+		}
+		if a.stats.UnitsSold != b.stats.UnitsSold {
+			return a.stats.UnitsSold > b.stats.UnitsSold
+		}
+		return a.product.ID < b.product.ID
+	})
+	return store.recommendedProducts(candidates, userId, limit), nil
+}
+
+func (store *MemoryProductStore) GetRecommendedProducts(ctx context.Context, userId string, limit int) ([]*entity.GetProductRes, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+
+	seeds := map[string]bool{}
+	for _, item := range store.db.OrderItems {
+		order, ok := store.db.OrderDetails[item.OrderID]
+		if ok && order.UserID == userId && item.Status != utils.OrderCancelled {
+			seeds[item.ProductID] = true
+		}
+	}
+	for _, item := range store.db.CartItems {
+		cart, ok := store.db.Carts[item.CartID]
+		if ok && cart.UserID == userId {
+			seeds[item.ProductID] = true
+		}
+	}
+	for _, wishlist := range store.db.Wishlists {
+		if wishlist.UserID == userId {
+			seeds[wishlist.ProductID] = true
+		}
+	}
+	scores := map[string]float64{}
+	for _, recommendation := range store.db.Recommendations {
+		if seeds[recommendation.ProductID] {
+			scores[recommendation.RelatedID] += recommendation.Score
+		}
+	}
+	seedCategories := map[string]bool{}
+	for productID := range seeds {
+		if product, ok := store.db.Products[productID]; ok {
+			seedCategories[product.CategoryID] = true
+		}
+	}
+
+	var candidates []*recommendationCandidate
+	for _, product := range store.db.Products {
+		if seeds[product.ID] || !memory.Listed(product) {
+			continue
+		}
+		candidate := &recommendationCandidate{
+			product:      product,
+			stats:        store.db.Stats(product.ID),
+			seedCategory: seedCategories[product.CategoryID],
+		}
+		if score, ok := scores[product.ID]; ok {
+			candidate.score = &score
+		}
+		candidates = append(candidates, candidate)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if (a.score == nil) != (b.score == nil) {
+			return a.score != nil
+		}
+		if a.score != nil && *a.score != *b.score {
+			return *a.score > *b.score
+		}
+		if a.seedCategory != b.seedCategory {
+			return a.seedCategory
+		}
+		if a.stats.UnitsSold != b.stats.UnitsSold {
+			return a.stats.UnitsSold > b.stats.UnitsSold
+		}
+		if a.stats.WishlistCount != b.stats.WishlistCount {
+			return a.stats.WishlistCount > b.stats.WishlistCount
+		}
+		if (a.stats.RatingAverage == nil) != (b.stats.RatingAverage == nil) {
+			return a.stats.RatingAverage != nil
+		}
+		if a.stats.RatingAverage != nil && *a.stats.RatingAverage != *b.stats.RatingAverage {
+			return *a.stats.RatingAverage > *b.stats.RatingAverage
+		}
+		return a.product.ID < b.product.ID
+	})
+	return store.recommendedProducts(candidates, userId, limit), nil
+}
+
+// recommendationCandidate is a product ranked by GetRelatedProducts or GetRecommendedProducts.
+type recommendationCandidate struct {
+	product *memory.Product
+	stats   memory.ProductStats
+	// score is nil for the products not recommended with the seeds
+	score        *float64
+	distance     float64
+	seedCategory bool
+}
+
+func (store *MemoryProductStore) recommendedProducts(candidates []*recommendationCandidate, userId string, limit int) []*entity.GetProductRes {
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	products := []*entity.GetProductRes{}
+	for _, candidate := range candidates {
+		res := &entity.GetProductRes{
+			ID:            candidate.product.ID,
+			ProductName:   candidate.product.ProductName,
+			ProductImages: append([]string(nil), candidate.product.Images...),
+			Price:         candidate.product.Price,
+			WishlistID:    store.db.WishlistID(candidate.product.ID, userId),
+		}
+		store.setStats(res)
+		products = append(products, res)
+	}
+	return products
+}
@@ -0,0 +1,380 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/akmal4410/gestapo/internal/database/memory"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+func (store *MemoryProductStore) AddProductReview(ctx context.Context, req *entity.AddReviewReq) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	if err := store.attachUploads(req.SessionIDs); err != nil {
+		return err
+	}
+	now := time.Now()
+	id := uuid.NewString()
+	store.db.Reviews[id] = &memory.Review{
+		ID:        id,
+		ProductID: req.ProductID,
+		UserID:    req.UserID,
+		Star:      req.Star,
+		Review:    req.Review,
+		Images:    append([]string{}, req.Images...),
+		Status:    reviewStatus(req.Flags),
+		Flags:     append([]string{}, req.Flags...),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	return nil
+}
+
+// reviewStatus returns the status of a review failing the checks of the flags.
+func reviewStatus(flags []string) string {
+	if len(flags) != 0 {
+		return utils.ReviewPending
+	}
+	return utils.ReviewPublished
+}
+
+// attachUploads moves the sessions out of PENDING, none of them when one is not
+// pending. The caller must hold the lock.
+func (store *MemoryProductStore) attachUploads(sessionIds []string) error {
+	for _, sessionId := range sessionIds {
+		session, ok := store.db.UploadSessions[sessionId]
+		if !ok || session.Status != entity.UploadPending {
+			return ErrUploadNotPending
+		}
+	}
+	now := time.Now()
+	for _, sessionId := range sessionIds {
+		session := store.db.UploadSessions[sessionId]
+		session.Status = entity.UploadAttached
+		session.UpdatedAt = now
+	}
+	return nil
+}
+
+// review returns the review with its votes, vote is the one of userId. The caller must hold the lock.
+func (store *MemoryProductStore) review(review *memory.Review, userId string) *entity.Review {
+	res := &entity.Review{
+		ID:             review.ID,
+		ProductID:      review.ProductID,
+		UserID:         review.UserID,
+		AuthorName:     store.authorName(review.UserID),
+		Star:           review.Star,
+		Review:         review.Review,
+		Images:         append([]string{}, review.Images...),
+		Reply:          review.Reply,
+		RepliedAt:      review.RepliedAt,
+		ReplyUpdatedAt: review.ReplyUpdatedAt,
+		EditedAt:       review.EditedAt,
+		Status:         review.Status,
+		CreatedAt:      review.CreatedAt,
+		UpdatedAt:      review.UpdatedAt,
+	}
+	if product, ok := store.db.Products[review.ProductID]; ok {
+		res.MerchantID = product.MerchantID
+	}
+	for _, vote := range store.db.ReviewVotes {
+		if vote.ReviewID != review.ID {
+			continue
+		}
+		if vote.Helpful {
+			res.HelpfulCount++
+		} else {
+			res.NotHelpfulCount++
+		}
+		if vote.UserID == userId {
+			helpful := vote.Helpful
+			res.MyVote = &helpful
+		}
+	}
+	return res
+}
+
+func (store *MemoryProductStore) GetReview(ctx context.Context, reviewId string) (*entity.Review, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	review, ok := store.db.Reviews[reviewId]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return store.review(review, ""), nil
+}
+
+func (store *MemoryProductStore) GetProductReviews(ctx context.Context, req *entity.ReviewsReq) (*entity.ReviewsRes, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	reviews := []*entity.Review{}
+	for _, review := range store.db.Reviews {
+		if review.ProductID != req.ProductID || review.Status != utils.ReviewPublished {
+			continue
+		}
+		if req.Star != nil && entity.RatingStar(review.Star) != *req.Star {
+			continue
+		}
+		if req.WithPhotos && len(review.Images) == 0 {
+			continue
+		}
+		reviews = append(reviews, store.review(review, req.UserID))
+	}
+
+	sort.Slice(reviews, func(i, j int) bool {
+		a, b := reviews[i], reviews[j]
+		switch req.Sort {
+		case entity.ReviewSortOldest:
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+			return a.ID < b.ID
+		case entity.ReviewSortHelpful:
+			if a.HelpfulCount != b.HelpfulCount {
+				return a.HelpfulCount > b.HelpfulCount
+			}
+		case entity.ReviewSortHighest:
+			if a.Star != b.Star {
+				return a.Star > b.Star
+			}
+		case entity.ReviewSortLowest:
+			if a.Star != b.Star {
+				return a.Star < b.Star
+			}
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+
+	res := &entity.ReviewsRes{Total: int64(len(reviews))}
+	if req.Offset < len(reviews) {
+		reviews = reviews[req.Offset:]
+	} else {
+		reviews = nil
+	}
+	if len(reviews) > req.Limit {
+		reviews = reviews[:req.Limit]
+	}
+	res.Reviews = append([]*entity.Review{}, reviews...)
+	return res, nil
+}
+
+func (store *MemoryProductStore) GetRatingDistribution(ctx context.Context, productId string) (map[int32]int64, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	distribution := map[int32]int64{}
+	for _, review := range store.db.Reviews {
+		if review.ProductID == productId && review.Status == utils.ReviewPublished {
+			distribution[entity.RatingStar(review.Star)]++
+		}
+	}
+	return distribution, nil
+}
+
+func (store *MemoryProductStore) EditProductReview(ctx context.Context, req *entity.EditReviewReq) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	review, ok := store.db.Reviews[req.ReviewID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	if err := store.attachUploads(req.SessionIDs); err != nil {
+		return err
+	}
+	now := time.Now()
+	if req.Star != nil {
+		review.Star = *req.Star
+	}
+	if req.Review != nil {
+		review.Review = *req.Review
+	}
+	review.Images = entity.EditImages(review.Images, req.RemovedImages, req.AddedImages)
+	if len(req.Flags) != 0 {
+		review.Status = utils.ReviewPending
+		review.Flags = append([]string{}, req.Flags...)
+	}
+	review.EditedAt = &now
+	review.UpdatedAt = now
+	return nil
+}
+
+func (store *MemoryProductStore) DeleteProductReview(ctx context.Context, reviewId string) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	if _, ok := store.db.Reviews[reviewId]; !ok {
+		return sql.ErrNoRows
+	}
+	for id, vote := range store.db.ReviewVotes {
+		if vote.ReviewID == reviewId {
+			delete(store.db.ReviewVotes, id)
+		}
+	}
+	for id, report := range store.db.ReviewReports {
+		if report.ReviewID == reviewId {
+			delete(store.db.ReviewReports, id)
+		}
+	}
+	delete(store.db.Reviews, reviewId)
+	return nil
+}
+
+func (store *MemoryProductStore) VoteReview(ctx context.Context, reviewId, userId string, helpful *bool) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	if _, ok := store.db.Reviews[reviewId]; !ok {
+		return sql.ErrNoRows
+	}
+	now := time.Now()
+	for id, vote := range store.db.ReviewVotes {
+		if vote.ReviewID == reviewId && vote.UserID == userId {
+			if helpful == nil {
+				delete(store.db.ReviewVotes, id)
+				return nil
+			}
+			vote.Helpful = *helpful
+			vote.UpdatedAt = now
+			return nil
+		}
+	}
+	if helpful == nil {
+		return nil
+	}
+	id := uuid.NewString()
+	store.db.ReviewVotes[id] = &memory.ReviewVote{
+		ID:        id,
+		ReviewID:  reviewId,
+		UserID:    userId,
+		Helpful:   *helpful,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	return nil
+}
+
+func (store *MemoryProductStore) ReplyToReview(ctx context.Context, reviewId, reply string) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	review, ok := store.db.Reviews[reviewId]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	now := time.Now()
+	review.Reply = &reply
+	if review.RepliedAt == nil {
+		review.RepliedAt = &now
+	}
+	review.ReplyUpdatedAt = &now
+	review.UpdatedAt = now
+	return nil
+}
+
+func (store *MemoryProductStore) CountRecentReviews(ctx context.Context, productId string, since time.Time) (int, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	count := 0
+	for _, review := range store.db.Reviews {
+		if review.ProductID == productId && !review.CreatedAt.Before(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (store *MemoryProductStore) ReviewTextExists(ctx context.Context, text string, since time.Time, exceptId string) (bool, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	for _, review := range store.db.Reviews {
+		if review.ID != exceptId && !review.CreatedAt.Before(since) && entity.NormalizeReviewText(review.Review) == text {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (store *MemoryProductStore) ReportReview(ctx context.Context, report *entity.ReviewReport, threshold int) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	review, ok := store.db.Reviews[report.ReviewID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	open := 1
+	for _, other := range store.db.ReviewReports {
+		if other.ReviewID != report.ReviewID {
+			continue
+		}
+		if other.UserID == report.UserID {
+			return ErrAlreadyReported
+		}
+		if other.ResolvedAt == nil {
+			open++
+		}
+	}
+	now := time.Now()
+	store.db.ReviewReports[report.ID] = &memory.ReviewReport{
+		ID:        report.ID,
+		ReviewID:  report.ReviewID,
+		UserID:    report.UserID,
+		Reason:    report.Reason,
+		Details:   report.Details,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if open >= threshold && review.Status == utils.ReviewPublished {
+		review.Status = utils.ReviewPending
+		review.Flags = append(review.Flags, entity.FlagReported)
+		review.UpdatedAt = now
+	}
+	return nil
+}
+
+func (store *MemoryProductStore) CreateUploadSession(ctx context.Context, session *entity.UploadSession) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	if _, ok := store.db.UploadSessions[session.ID]; ok {
+		return fmt.Errorf("duplicate key value violates unique constraint on id")
+	}
+	store.db.UploadSessions[session.ID] = &memory.UploadSession{
+		ID:          session.ID,
+		UserID:      session.UserID,
+		Purpose:     session.Purpose,
+		TargetID:    session.TargetID,
+		Key:         session.Key,
+		ContentType: session.ContentType,
+		Size:        session.Size,
+		Status:      session.Status,
+		ExpiresAt:   session.ExpiresAt,
+		CreatedAt:   session.CreatedAt,
+		UpdatedAt:   session.CreatedAt,
+	}
+	return nil
+}
+
+func (store *MemoryProductStore) GetUploadSession(ctx context.Context, sessionId string) (*entity.UploadSession, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	session, ok := store.db.UploadSessions[sessionId]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &entity.UploadSession{
+		ID:          session.ID,
+		UserID:      session.UserID,
+		Purpose:     session.Purpose,
+		TargetID:    session.TargetID,
+		Key:         session.Key,
+		ContentType: session.ContentType,
+		Size:        session.Size,
+		Status:      session.Status,
+		ExpiresAt:   session.ExpiresAt,
+		CreatedAt:   session.CreatedAt,
+	}, nil
+}
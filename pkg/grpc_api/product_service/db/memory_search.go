@@ -0,0 +1,282 @@
+package db
+
+import (
+	"context"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/akmal4410/gestapo/internal/database/memory"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+)
+
+// The in-memory search approximates the Postgres one: the words of the query
+// match the words of the product with a naive stemming, and the words of the
+// name within a trigram similarity like pg_trgm.
+
+// typoSimilarity is the trigram similarity of a word of the name to a misspelt word of the query.
+const typoSimilarity = 0.5
+
+var searchWord = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// searchRow is a matched product with the values filtered, ranked and counted by the search.
+type searchRow struct {
+	product      *memory.Product
+	hit          *entity.SearchHit
+	categoryName string
+	merchantName string
+	inCategory   bool
+	finalPrice   float64
+	sizes        []float64
+}
+
+func (store *MemoryProductStore) SearchProducts(ctx context.Context, req *entity.SearchProductsReq) (*entity.SearchProductsRes, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+
+	query := searchWord.FindAllString(strings.ToLower(req.Query), -1)
+	var rows []*searchRow
+	for _, product := range store.products(nil) {
+		if !memory.Listed(product) {
+			continue
+		}
+		row := store.searchRow(product, query)
+		if row != nil {
+			row.inCategory = req.CategoryID == nil || store.db.InSubtree(product.CategoryID, *req.CategoryID)
+			rows = append(rows, row)
+		}
+	}
+
+	res := &entity.SearchProductsRes{Hits: []*entity.SearchHit{}}
+	var filtered []*searchRow
+	for _, row := range rows {
+		if row.matches(req, "") {
+			filtered = append(filtered, row)
+		}
+	}
+	sortSearchRows(filtered, req.Sort)
+	res.Total = int64(len(filtered))
+	for i := req.Offset; i < len(filtered) && i < req.Offset+req.Limit; i++ {
+		res.Hits = append(res.Hits, filtered[i].hit)
+	}
+
+	counts := map[string][]entity.FacetCount{}
+	count := func(facet, value, label string) {
+		for i := range counts[facet] {
+			if counts[facet][i].Value == value {
+				counts[facet][i].Count++
+				return
+			}
+		}
+		counts[facet] = append(counts[facet], entity.FacetCount{Value: value, Label: label, Count: 1})
+	}
+	for _, row := range rows {
+		if row.matches(req, "category") {
+			count("category", row.product.CategoryID, row.categoryName)
+		}
+		if row.matches(req, "merchant") {
+			count("merchant", row.product.MerchantID, row.merchantName)
+		}
+		if row.matches(req, "price") {
+			for _, priceRange := range entity.PriceRanges {
+				if row.finalPrice < priceRange.Max || math.IsInf(priceRange.Max, 1) {
+					count("price", priceRange.Value(), "")
+					break
+				}
+			}
+		}
+		if row.matches(req, "rating") && row.hit.ReviewStar != nil {
+			for _, threshold := range entity.RatingThresholds {
+				if *row.hit.ReviewStar >= threshold {
+					count("rating", strconv.FormatFloat(threshold, 'f', -1, 64), "")
+				}
+			}
+		}
+		if row.matches(req, "size") {
+			for _, size := range row.sizes {
+				count("size", strconv.FormatFloat(size, 'f', -1, 64), "")
+			}
+		}
+	}
+	res.Facets = *newSearchFacets(counts)
+	return res, nil
+}
+
+// searchRow returns the row of the product, or nil when it does not match every word of the query.
+func (store *MemoryProductStore) searchRow(product *memory.Product, query []string) *searchRow {
+	detail := store.productDetail(product)
+	row := &searchRow{
+		product:    product,
+		hit:        &entity.SearchHit{GetProductRes: *detail, CategoryID: product.CategoryID},
+		finalPrice: product.Price,
+	}
+	if detail.CategoryName != nil {
+		row.categoryName = *detail.CategoryName
+	}
+	if merchant, ok := store.db.Users[product.MerchantID]; ok {
+		row.merchantName = merchant.UserName
+	}
+	if detail.DiscountPrice != nil {
+		row.finalPrice = *detail.DiscountPrice
+	}
+	store.setStats(&row.hit.GetProductRes)
+	for _, inventory := range store.db.Inventories {
+		if _, ok := inventory.Options[entity.SizeOption]; ok && inventory.ProductID == product.ID && inventory.Quantity > 0 {
+			row.sizes = append(row.sizes, inventory.Size)
+		}
+	}
+
+	name := searchWord.FindAllString(strings.ToLower(product.ProductName), -1)
+	category := searchWord.FindAllString(strings.ToLower(row.categoryName), -1)
+	description := searchWord.FindAllString(strings.ToLower(product.Description), -1)
+	marked := map[string]bool{}
+	for _, word := range query {
+		matched := false
+		// the weights of the fields are the A, B and C of the search_vector
+		for _, field := range []struct {
+			words  []string
+			weight float64
+		}{{name, 1}, {category, 0.4}, {description, 0.2}} {
+			for _, candidate := range field.words {
+				if stem(candidate) == stem(word) {
+					row.hit.Score += field.weight
+					marked[candidate] = true
+					matched = true
+				}
+			}
+		}
+		if matched {
+			continue
+		}
+		for _, candidate := range name {
+			if similarity := trigramSimilarity(word, candidate); similarity >= typoSimilarity {
+				row.hit.Score += similarity / 2
+				marked[candidate] = true
+				matched = true
+			}
+		}
+		if !matched {
+			return nil
+		}
+	}
+
+	row.hit.NameHighlight = highlight(product.ProductName, marked)
+	row.hit.DescriptionHighlight = highlight(product.Description, marked)
+	return row
+}
+
+// matches reports whether the row passes the filters of req but the ones of the except facet.
+func (row *searchRow) matches(req *entity.SearchProductsReq, except string) bool {
+	if except != "category" && !row.inCategory {
+		return false
+	}
+	if except != "merchant" && req.MerchantID != nil && row.product.MerchantID != *req.MerchantID {
+		return false
+	}
+	if except != "price" && req.MinPrice != nil && row.finalPrice < *req.MinPrice {
+		return false
+	}
+	if except != "price" && req.MaxPrice != nil && row.finalPrice > *req.MaxPrice {
+		return false
+	}
+	if except != "rating" && req.MinRating != nil && (row.hit.ReviewStar == nil || *row.hit.ReviewStar < *req.MinRating) {
+		return false
+	}
+	if except != "size" && req.Size != nil {
+		for _, size := range row.sizes {
+			if size == *req.Size {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func sortSearchRows(rows []*searchRow, order string) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		switch order {
+		case entity.SortPriceAsc:
+			if a.finalPrice != b.finalPrice {
+				return a.finalPrice < b.finalPrice
+			}
+		case entity.SortPriceDesc:
+			if a.finalPrice != b.finalPrice {
+				return a.finalPrice > b.finalPrice
+			}
+		case entity.SortRating:
+			starA, starB := -1.0, -1.0
+			if a.hit.ReviewStar != nil {
+				starA = *a.hit.ReviewStar
+			}
+			if b.hit.ReviewStar != nil {
+				starB = *b.hit.ReviewStar
+			}
+			if starA != starB {
+				return starA > starB
+			}
+		case entity.SortNewest:
+			if !a.product.CreatedAt.Equal(b.product.CreatedAt) {
+				return a.product.CreatedAt.After(b.product.CreatedAt)
+			}
+		default:
+			if a.hit.Score != b.hit.Score {
+				return a.hit.Score > b.hit.Score
+			}
+			if !a.product.CreatedAt.Equal(b.product.CreatedAt) {
+				return a.product.CreatedAt.After(b.product.CreatedAt)
+			}
+		}
+		return a.product.ID < b.product.ID
+	})
+}
+
+// stem drops the plural of an english word, enough for "shoe" to match "shoes".
+func stem(word string) string {
+	switch {
+	case len(word) > 4 && strings.HasSuffix(word, "ies"):
+		return strings.TrimSuffix(word, "ies") + "y"
+	case len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss"):
+		return strings.TrimSuffix(word, "s")
+	}
+	return word
+}
+
+// trigramSimilarity is the similarity of pg_trgm: the shared trigrams of the
+// padded words over all their trigrams.
+func trigramSimilarity(a, b string) float64 {
+	trigramsA, trigramsB := trigrams(a), trigrams(b)
+	shared := 0
+	for trigram := range trigramsA {
+		if trigramsB[trigram] {
+			shared++
+		}
+	}
+	total := len(trigramsA) + len(trigramsB) - shared
+	if total == 0 {
+		return 0
+	}
+	return float64(shared) / float64(total)
+}
+
+func trigrams(word string) map[string]bool {
+	padded := []rune("  " + word + " ")
+	set := map[string]bool{}
+	for i := 0; i+3 <= len(padded); i++ {
+		set[string(padded[i:i+3])] = true
+	}
+	return set
+}
+
+// highlight wraps the marked words of text like ts_headline.
+func highlight(text string, marked map[string]bool) string {
+	return searchWord.ReplaceAllStringFunc(text, func(word string) string {
+		if marked[strings.ToLower(word)] {
+			return entity.HighlightStart + word + entity.HighlightStop
+		}
+		return word
+	})
+}
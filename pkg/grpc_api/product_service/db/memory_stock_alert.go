@@ -0,0 +1,114 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/akmal4410/gestapo/internal/database/memory"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+func (store *MemoryProductStore) GetVariantStock(ctx context.Context, productId string, variantId *string, size float64) (*entity.VariantStock, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	product, ok := store.db.Products[productId]
+	if !ok || !memory.Listed(product) {
+		return nil, sql.ErrNoRows
+	}
+	for _, inventory := range store.db.Inventories {
+		if inventory.ProductID != productId {
+			continue
+		}
+		if variantId != nil && inventory.ID == *variantId || variantId == nil && inventory.Size == size {
+			return &entity.VariantStock{InventoryID: inventory.ID, Quantity: inventory.Quantity}, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (store *MemoryProductStore) SubscribeToStock(ctx context.Context, userId, productId, inventoryId string) (bool, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	now := time.Now()
+	for _, subscription := range store.db.Subscriptions {
+		if subscription.InventoryID != inventoryId || subscription.UserID != userId {
+			continue
+		}
+		if subscription.NotifiedAt == nil {
+			return false, nil
+		}
+		// a user notified before goes back to the end of the queue
+		subscription.CreatedAt = now
+		subscription.NotifiedAt = nil
+		return true, nil
+	}
+	id := uuid.NewString()
+	store.db.Subscriptions[id] = &memory.StockSubscription{
+		ID:          id,
+		UserID:      userId,
+		ProductID:   productId,
+		InventoryID: inventoryId,
+		CreatedAt:   now,
+	}
+	return true, nil
+}
+
+func (store *MemoryProductStore) NotifyBackInStock(ctx context.Context, alert entity.BackInStockAlert) (int64, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	now := time.Now()
+	waiting := map[string][]*memory.StockSubscription{}
+	batched := map[string]bool{}
+	for _, subscription := range store.db.Subscriptions {
+		if subscription.NotifiedAt == nil {
+			waiting[subscription.InventoryID] = append(waiting[subscription.InventoryID], subscription)
+		} else if subscription.NotifiedAt.After(now.Add(-alert.BatchInterval)) {
+			batched[subscription.InventoryID] = true
+		}
+	}
+
+	var notified int64
+	for inventoryID, queue := range waiting {
+		inventory, ok := store.db.Inventories[inventoryID]
+		if !ok || inventory.Quantity <= 0 || batched[inventoryID] {
+			continue
+		}
+		if product, ok := store.db.Products[inventory.ProductID]; !ok || !memory.Listed(product) {
+			continue
+		}
+		sort.Slice(queue, func(i, j int) bool {
+			if !queue[i].CreatedAt.Equal(queue[j].CreatedAt) {
+				return queue[i].CreatedAt.Before(queue[j].CreatedAt)
+			}
+			return queue[i].ID < queue[j].ID
+		})
+		size := min(alert.BatchSize, int(inventory.Quantity)*alert.PerUnit)
+		if len(queue) > size {
+			queue = queue[:size]
+		}
+		for _, subscription := range queue {
+			subscription.NotifiedAt = &now
+			product, ok := store.db.Products[subscription.ProductID]
+			if !ok {
+				continue
+			}
+			id, productID := uuid.NewString(), subscription.ProductID
+			store.db.Notifications[id] = &memory.Notification{
+				ID:        id,
+				UserID:    subscription.UserID,
+				Type:      utils.NotificationBackInStock,
+				Title:     alert.Title,
+				Body:      fmt.Sprintf(alert.Body, product.ProductName),
+				ProductID: &productID,
+				CreatedAt: now,
+			}
+			notified++
+		}
+	}
+	return notified, nil
+}
@@ -0,0 +1,201 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+
+	"github.com/akmal4410/gestapo/internal/database/memory"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/utils"
+)
+
+// MemoryProductStore is the in-memory ProductRepository.
+type MemoryProductStore struct {
+	db *memory.Database
+}
+
+func NewMemoryProductStore(db *memory.Database) *MemoryProductStore {
+	return &MemoryProductStore{db: db}
+}
+
+func (store *MemoryProductStore) CheckDataExist(ctx context.Context, table, column, value string) (bool, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	return store.db.Exists(table, column, value)
+}
+
+func (store *MemoryProductStore) GetProductsForUser(ctx context.Context, merchantId, categoryId *string, userId string) ([]*entity.GetProductRes, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	products := []*entity.GetProductRes{}
+	for _, product := range store.products(merchantId) {
+		if !memory.Listed(product) || categoryId != nil && !store.db.InSubtree(product.CategoryID, *categoryId) {
+			continue
+		}
+		res := &entity.GetProductRes{
+			ID:            product.ID,
+			ProductName:   product.ProductName,
+			ProductImages: append([]string(nil), product.Images...),
+			Price:         product.Price,
+			WishlistID:    store.db.WishlistID(product.ID, userId),
+		}
+		store.setStats(res)
+		products = append(products, res)
+	}
+	return products, nil
+}
+
+func (store *MemoryProductStore) GetProductsForMerchants(ctx context.Context, merchantId, categoryId *string, unlisted bool) ([]*entity.GetProductRes, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	products := []*entity.GetProductRes{}
+	for _, product := range store.products(merchantId) {
+		if !unlisted && !memory.Listed(product) || categoryId != nil && !store.db.InSubtree(product.CategoryID, *categoryId) {
+			continue
+		}
+		res := &entity.GetProductRes{
+			ID:            product.ID,
+			ProductName:   product.ProductName,
+			ProductImages: append([]string(nil), product.Images...),
+			Price:         product.Price,
+		}
+		if unlisted {
+			setLifecycle(res, product)
+		}
+		store.setStats(res)
+		products = append(products, res)
+	}
+	return products, nil
+}
+
+func (store *MemoryProductStore) GetProductByIdForUser(ctx context.Context, productId, userId string) (*entity.GetProductRes, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	product, ok := store.db.Products[productId]
+	if !ok || !memory.Listed(product) {
+		return nil, sql.ErrNoRows
+	}
+	res := store.productDetail(product)
+	store.setStats(res)
+	res.WishlistID = store.db.WishlistID(product.ID, userId)
+	res.Listed = true
+	res.Variants = store.variants(product)
+	return res, nil
+}
+
+func (store *MemoryProductStore) GetProductByIdForMerchant(ctx context.Context, productId string) (*entity.GetProductRes, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	product, ok := store.db.Products[productId]
+	if !ok || product.DeletedAt != nil {
+		return nil, sql.ErrNoRows
+	}
+	res := store.productDetail(product)
+	store.setStats(res)
+	setLifecycle(res, product)
+	res.Listed = memory.Listed(product)
+	res.Variants = store.variants(product)
+	return res, nil
+}
+
+func (store *MemoryProductStore) IsUserCanAddReview(ctx context.Context, orderItemID, userID string) (bool, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	item, ok := store.db.OrderItems[orderItemID]
+	if !ok || item.Status != utils.OrderCompleted {
+		return false, nil
+	}
+	order, ok := store.db.OrderDetails[item.OrderID]
+	return ok && order.UserID == userID, nil
+}
+
+func (store *MemoryProductStore) IsUserAlreadyAddedReview(ctx context.Context, productID, userID string) (bool, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	for _, review := range store.db.Reviews {
+		if review.ProductID == productID && review.UserID == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// setStats sets the stats of the product, like the join of product_stats.
+func (store *MemoryProductStore) setStats(product *entity.GetProductRes) {
+	stats := store.db.Stats(product.ID)
+	product.ReviewStar = stats.RatingAverage
+	product.ReviewCount = stats.RatingCount
+	product.UnitsSold = stats.UnitsSold
+	product.WishlistCount = stats.WishlistCount
+}
+
+// setLifecycle sets the status of the product and its schedule.
+func setLifecycle(res *entity.GetProductRes, product *memory.Product) {
+	productStatus := product.Status
+	res.Status = &productStatus
+	res.PublishAt = product.PublishAt
+	res.UnpublishAt = product.UnpublishAt
+	res.StatusReason = product.StatusReason
+}
+
+// products returns the products of the merchant, or every product when merchantId
+// is nil, leaving out the deleted ones.
+func (store *MemoryProductStore) products(merchantId *string) []*memory.Product {
+	var products []*memory.Product
+	for _, product := range store.db.Products {
+		if product.DeletedAt != nil {
+			continue
+		}
+		if merchantId == nil || product.MerchantID == *merchantId {
+			products = append(products, product)
+		}
+	}
+	sort.Slice(products, func(i, j int) bool {
+		return products[i].CreatedAt.Before(products[j].CreatedAt)
+	})
+	return products
+}
+
+func (store *MemoryProductStore) productDetail(product *memory.Product) *entity.GetProductRes {
+	merchantID, description := product.MerchantID, product.Description
+	sizes := append([]float64(nil), product.Size...)
+	attributes := entity.Options{}
+	for name, value := range product.Attributes {
+		attributes[name] = value
+	}
+	return &entity.GetProductRes{
+		ID:            product.ID,
+		MerchantID:    &merchantID,
+		ProductImages: append([]string(nil), product.Images...),
+		ProductName:   product.ProductName,
+		Description:   &description,
+		CategoryName:  store.db.CategoryName(product),
+		Size:          &sizes,
+		Price:         product.Price,
+		DiscountPrice: store.db.DiscountPrice(product),
+		OptionTypes:   append([]string(nil), product.OptionTypes...),
+		Attributes:    attributes,
+	}
+}
+
+func (store *MemoryProductStore) variants(product *memory.Product) []*entity.Variant {
+	variants := []*entity.Variant{}
+	for _, inventory := range store.db.Variants(product.ID) {
+		price, discountPrice := store.db.VariantPrice(product, inventory)
+		options := entity.Options{}
+		for option, value := range inventory.Options {
+			options[option] = value
+		}
+		variants = append(variants, &entity.Variant{
+			ID:            inventory.ID,
+			SKU:           inventory.SKU,
+			Options:       options,
+			Price:         price,
+			DiscountPrice: discountPrice,
+			Images:        append([]string(nil), inventory.Images...),
+			Quantity:      inventory.Quantity,
+		})
+	}
+	return variants
+}
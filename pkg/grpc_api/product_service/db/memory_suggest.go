@@ -0,0 +1,158 @@
+package db
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/akmal4410/gestapo/internal/database/memory"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+func (store *MemoryProductStore) LogSearchQuery(ctx context.Context, req *entity.SearchQueryLog) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	var userID, categoryID *string
+	if req.UserID != "" {
+		id := req.UserID
+		userID = &id
+	}
+	if req.CategoryID != nil {
+		id := *req.CategoryID
+		categoryID = &id
+	}
+	id := uuid.NewString()
+	store.db.SearchQueries[id] = &memory.SearchQuery{
+		ID:         id,
+		Query:      req.Query,
+		UserID:     userID,
+		CategoryID: categoryID,
+		Results:    req.Results,
+		CreatedAt:  time.Now(),
+	}
+	return nil
+}
+
+func (store *MemoryProductStore) SuggestQueries(ctx context.Context, prefix string, limit int) (*entity.Suggestions, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	prefix = strings.ToLower(prefix)
+
+	var suggestions entity.Suggestions
+	since := time.Now().Add(-SuggestionWindow)
+	suggestions.Queries = rankSuggestions(store.queryCounts(func(query *memory.SearchQuery) bool {
+		return query.Results > 0 && query.CreatedAt.After(since) && strings.HasPrefix(query.Query, prefix)
+	}), limit)
+
+	orders := map[string]int64{}
+	for _, item := range store.db.OrderItems {
+		orders[item.ProductID]++
+	}
+	var products []entity.Suggestion
+	categories := map[string]*entity.Suggestion{}
+	merchants := map[string]*entity.Suggestion{}
+	for _, category := range store.db.Categories {
+		if hasWordPrefix(category.CategoryName, prefix) {
+			categories[category.ID] = &entity.Suggestion{ID: category.ID, Text: category.CategoryName}
+		}
+	}
+	for _, user := range store.db.Users {
+		if user.UserType == utils.MERCHANT && (hasWordPrefix(user.UserName, prefix) || user.FullName != nil && hasWordPrefix(*user.FullName, prefix)) {
+			merchants[user.ID] = &entity.Suggestion{ID: user.ID, Text: user.UserName}
+		}
+	}
+	for _, product := range store.db.Products {
+		if product.DeletedAt != nil {
+			continue
+		}
+		if category, ok := categories[product.CategoryID]; ok {
+			category.Popularity += orders[product.ID]
+		}
+		if merchant, ok := merchants[product.MerchantID]; ok {
+			merchant.Popularity += orders[product.ID]
+		}
+		if memory.Listed(product) && hasWordPrefix(product.ProductName, prefix) {
+			popularity := orders[product.ID]
+			for _, wishlist := range store.db.Wishlists {
+				if wishlist.ProductID == product.ID {
+					popularity++
+				}
+			}
+			products = append(products, entity.Suggestion{ID: product.ID, Text: product.ProductName, Popularity: popularity})
+		}
+	}
+	suggestions.Products = rankSuggestions(products, limit)
+	suggestions.Categories = rankSuggestions(values(categories), limit)
+	suggestions.Merchants = rankSuggestions(values(merchants), limit)
+	return &suggestions, nil
+}
+
+func (store *MemoryProductStore) GetTrendingSearches(ctx context.Context, req *entity.TrendingSearchesReq) ([]*entity.QueryCount, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	counts := store.queryCounts(func(query *memory.SearchQuery) bool {
+		return query.Results > 0 && !query.CreatedAt.Before(req.From) && query.CreatedAt.Before(req.To) &&
+			(req.CategoryID == nil || query.CategoryID != nil && *query.CategoryID == *req.CategoryID)
+	})
+	trending := []*entity.QueryCount{}
+	for _, suggestion := range rankSuggestions(counts, req.Limit) {
+		trending = append(trending, &entity.QueryCount{Query: suggestion.Text, Count: suggestion.Popularity})
+	}
+	return trending, nil
+}
+
+// queryCounts counts the users searching each query kept by filter, like
+// COUNT(DISTINCT COALESCE(user_id::text, id::text)).
+func (store *MemoryProductStore) queryCounts(filter func(query *memory.SearchQuery) bool) []entity.Suggestion {
+	users := map[string]map[string]bool{}
+	for _, query := range store.db.SearchQueries {
+		if !filter(query) {
+			continue
+		}
+		if users[query.Query] == nil {
+			users[query.Query] = map[string]bool{}
+		}
+		user := query.ID
+		if query.UserID != nil {
+			user = *query.UserID
+		}
+		users[query.Query][user] = true
+	}
+	counts := make([]entity.Suggestion, 0, len(users))
+	for query, searchedBy := range users {
+		counts = append(counts, entity.Suggestion{Text: query, Popularity: int64(len(searchedBy))})
+	}
+	return counts
+}
+
+// hasWordPrefix reports whether text or one of its words starts with prefix, like
+// `text ILIKE prefix || '%' OR text ILIKE '% ' || prefix || '%'`.
+func hasWordPrefix(text, prefix string) bool {
+	text = strings.ToLower(text)
+	return strings.HasPrefix(text, prefix) || strings.Contains(text, " "+prefix)
+}
+
+func values(suggestions map[string]*entity.Suggestion) []entity.Suggestion {
+	list := make([]entity.Suggestion, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		list = append(list, *suggestion)
+	}
+	return list
+}
+
+// rankSuggestions orders the suggestions from the most popular and keeps the first limit ones.
+func rankSuggestions(suggestions []entity.Suggestion, limit int) []entity.Suggestion {
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Popularity != suggestions[j].Popularity {
+			return suggestions[i].Popularity > suggestions[j].Popularity
+		}
+		return suggestions[i].Text < suggestions[j].Text
+	})
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return append([]entity.Suggestion{}, suggestions...)
+}
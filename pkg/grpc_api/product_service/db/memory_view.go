@@ -0,0 +1,116 @@
+package db
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/akmal4410/gestapo/internal/database/memory"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	"github.com/google/uuid"
+)
+
+func (store *MemoryProductStore) RecordProductView(ctx context.Context, productId string, viewer entity.Viewer, window time.Duration) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	now := time.Now()
+	var last *memory.ProductView
+	for _, view := range store.db.ProductViews {
+		if view.ProductID != productId || !view.ViewedAt.After(now.Add(-window)) {
+			continue
+		}
+		// a user is matched by its id only, a guest by its device
+		if viewer.UserID != nil {
+			if view.UserID == nil || *view.UserID != *viewer.UserID {
+				continue
+			}
+		} else if view.UserID != nil || !sameDevice(view.DeviceID, viewer.DeviceID) {
+			continue
+		}
+		if last == nil || view.ViewedAt.After(last.ViewedAt) {
+			last = view
+		}
+	}
+	if last != nil {
+		last.ViewedAt = now
+		return nil
+	}
+	id := uuid.NewString()
+	store.db.ProductViews[id] = &memory.ProductView{
+		ID:        id,
+		ProductID: productId,
+		UserID:    viewer.UserID,
+		DeviceID:  viewer.DeviceID,
+		ViewedAt:  now,
+	}
+	return nil
+}
+
+func (store *MemoryProductStore) GetRecentlyViewed(ctx context.Context, viewer entity.Viewer, limit int) ([]*entity.RecentlyViewed, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	lastViews := map[string]time.Time{}
+	for _, view := range store.db.ProductViews {
+		if !viewedBy(view, viewer) {
+			continue
+		}
+		if last, ok := lastViews[view.ProductID]; !ok || view.ViewedAt.After(last) {
+			lastViews[view.ProductID] = view.ViewedAt
+		}
+	}
+
+	viewed := []*entity.RecentlyViewed{}
+	for productID, viewedAt := range lastViews {
+		product, ok := store.db.Products[productID]
+		if !ok || !memory.Listed(product) {
+			continue
+		}
+		res := &entity.GetProductRes{
+			ID:            product.ID,
+			ProductName:   product.ProductName,
+			ProductImages: append([]string(nil), product.Images...),
+			Price:         product.Price,
+		}
+		if viewer.UserID != nil {
+			res.WishlistID = store.db.WishlistID(product.ID, *viewer.UserID)
+		}
+		store.setStats(res)
+		viewed = append(viewed, &entity.RecentlyViewed{Product: res, ViewedAt: viewedAt})
+	}
+	sort.Slice(viewed, func(i, j int) bool {
+		if !viewed[i].ViewedAt.Equal(viewed[j].ViewedAt) {
+			return viewed[i].ViewedAt.After(viewed[j].ViewedAt)
+		}
+		return viewed[i].Product.ID < viewed[j].Product.ID
+	})
+	if len(viewed) > limit {
+		viewed = viewed[:limit]
+	}
+	return viewed, nil
+}
+
+func (store *MemoryProductStore) ClearRecentlyViewed(ctx context.Context, viewer entity.Viewer) (int64, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	var cleared int64
+	for _, view := range store.db.ProductViews {
+		if viewedBy(view, viewer) {
+			view.UserID = nil
+			view.DeviceID = nil
+			cleared++
+		}
+	}
+	return cleared, nil
+}
+
+// viewedBy tells whether the view is one of the user or a guest view of the device.
+func viewedBy(view *memory.ProductView, viewer entity.Viewer) bool {
+	if viewer.UserID != nil && view.UserID != nil && *view.UserID == *viewer.UserID {
+		return true
+	}
+	return view.UserID == nil && sameDevice(view.DeviceID, viewer.DeviceID)
+}
+
+func sameDevice(a, b *string) bool {
+	return a != nil && b != nil && *a == *b
+}
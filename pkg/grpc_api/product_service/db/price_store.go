@@ -0,0 +1,83 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/utils"
+)
+
+func (store *ProductStore) GetPriceHistory(ctx context.Context, productId string, since time.Time) ([]*entity.PricePoint, error) {
+	selectQuery := `
+	SELECT h.price, h.discount_percent, h.effective_price, h.changed_at
+	FROM price_histories h
+	WHERE h.product_id = $1 AND h.changed_at >= COALESCE((
+		SELECT MAX(s.changed_at) FROM price_histories s
+		WHERE s.product_id = $1 AND s.changed_at < $2
+	), $2)
+	ORDER BY h.changed_at;
+	`
+	rows, err := store.storage.DB.QueryContext(ctx, selectQuery, productId, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := []*entity.PricePoint{}
+	for rows.Next() {
+		var point entity.PricePoint
+		err := rows.Scan(&point.Price, &point.DiscountPercent, &point.EffectivePrice, &point.ChangedAt)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, &point)
+	}
+	return points, rows.Err()
+}
+
+func (store *ProductStore) RecordPrices(ctx context.Context) (int64, error) {
+	var recorded int64
+	err := store.storage.DB.QueryRowContext(ctx, `SELECT record_product_prices(NULL);`).Scan(&recorded)
+	return recorded, err
+}
+
+func (store *ProductStore) NotifyPriceDrops(ctx context.Context, alert entity.PriceDropAlert) (int64, error) {
+	// the last price of each product is compared with the one it had at the last
+	// check, so the changes between two checks notify once
+	insertQuery := `
+	WITH pending AS (
+		UPDATE price_histories SET alert_checked_at = NOW()
+		WHERE alert_checked_at IS NULL
+		RETURNING product_id, effective_price, changed_at
+	),
+	changes AS (
+		SELECT DISTINCT ON (product_id) product_id, effective_price,
+		MIN(changed_at) OVER (PARTITION BY product_id) AS first_changed_at
+		FROM pending
+		ORDER BY product_id, changed_at DESC
+	),
+	drops AS (
+		SELECT c.product_id, c.effective_price, b.effective_price AS previous_price
+		FROM changes c
+		JOIN LATERAL (
+			SELECT h.effective_price FROM price_histories h
+			WHERE h.product_id = c.product_id AND h.changed_at < c.first_changed_at
+			ORDER BY h.changed_at DESC LIMIT 1
+		) b ON TRUE
+		WHERE c.effective_price <= b.effective_price * (1 - $1::float8 / 100)
+	)
+	INSERT INTO notifications (id, user_id, type, title, body, product_id, created_at)
+	SELECT gen_random_uuid(), w.user_id, $2, $3,
+	format($4, p.product_name, to_char(d.effective_price, 'FM999999990.00'), to_char(d.previous_price, 'FM999999990.00')),
+	d.product_id, NOW()
+	FROM drops d
+	JOIN products p ON p.id = d.product_id AND listed(p)
+	JOIN wishlists w ON w.product_id = d.product_id;
+	`
+	res, err := store.storage.DB.ExecContext(ctx, insertQuery, alert.Threshold, utils.NotificationPriceDrop, alert.Title, alert.Body)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,314 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	"github.com/lib/pq"
+)
+
+// searchMatches selects the products matching the query with the values
+// filtered, ranked and counted by the search. A product matches when its
+// search_vector matches the words of the query, or when its name is close to
+// the query so a typo still finds it. Every product matches an empty query.
+const searchMatches = `
+	SELECT
+	p.id,
+	p.merchent_id,
+	p.category_id,
+	p.product_name,
+	p.description,
+	p.images,
+	p.size,
+	p.price,
+	p.created_at,
+	c.category_name,
+	c.path AS category_path,
+	u.user_name AS merchant_name,
+	discount.price AS discount_price,
+	COALESCE(discount.price, p.price) AS final_price,
+	` + statsColumns + `,
+	ARRAY(SELECT DISTINCT i.size FROM inventories i WHERE i.product_id = p.id AND i.options ? 'size' AND i.quantity > 0) AS sizes,
+	CASE WHEN s.q = '' THEN 0
+		ELSE COALESCE(ts_rank_cd(p.search_vector, s.query), 0) + word_similarity(s.q, p.product_name)
+	END AS score,
+	s.q,
+	s.query
+	FROM
+	products p
+	CROSS JOIN (SELECT $1::text AS q, websearch_to_tsquery('english', $1::text) AS query) s
+	LEFT JOIN
+	categories c ON p.category_id = c.id
+	LEFT JOIN
+	user_data u ON p.merchent_id = u.id
+	LEFT JOIN
+	discounts d ON p.discount_id = d.id
+	LEFT JOIN
+	product_stats ps ON p.id = ps.product_id
+	CROSS JOIN LATERAL (
+		SELECT CASE
+			WHEN d.end_time IS NOT NULL AND d.end_time > NOW()
+			THEN p.price - (p.price * d.percent / 100)
+			ELSE NULL
+		END AS price
+	) discount
+	WHERE
+	listed(p) AND (s.q = '' OR p.search_vector @@ s.query OR s.q <% p.product_name)
+`
+
+const (
+	nameHeadline        = `StartSel=<mark>, StopSel=</mark>, HighlightAll=true`
+	descriptionHeadline = `StartSel=<mark>, StopSel=</mark>, MaxWords=30, MinWords=10, MaxFragments=2`
+)
+
+var searchOrders = map[string]string{
+	entity.SortRelevance: "score DESC, created_at DESC",
+	entity.SortPriceAsc:  "final_price ASC",
+	entity.SortPriceDesc: "final_price DESC",
+	entity.SortRating:    "star DESC NULLS LAST",
+	entity.SortNewest:    "created_at DESC",
+}
+
+// searchFilter is the condition of one filter of the search, named by its facet.
+type searchFilter struct {
+	facet     string
+	condition string
+}
+
+func searchFilters(req *entity.SearchProductsReq, args *[]interface{}) []searchFilter {
+	var filters []searchFilter
+	add := func(facet, condition string, value interface{}) {
+		*args = append(*args, value)
+		filters = append(filters, searchFilter{facet, fmt.Sprintf(condition, len(*args))})
+	}
+	if req.CategoryID != nil {
+		// the products of the category and of its subcategories
+		add("category", "EXISTS (SELECT 1 FROM categories root WHERE root.id::text = $%d AND (category_path = root.path OR category_path LIKE root.path || '/%%'))", *req.CategoryID)
+	}
+	if req.MerchantID != nil {
+		add("merchant", "merchent_id::text = $%d", *req.MerchantID)
+	}
+	if req.MinPrice != nil {
+		add("price", "final_price >= $%d", *req.MinPrice)
+	}
+	if req.MaxPrice != nil {
+		add("price", "final_price <= $%d", *req.MaxPrice)
+	}
+	if req.MinRating != nil {
+		add("rating", "star >= $%d", *req.MinRating)
+	}
+	if req.Size != nil {
+		add("size", "$%d = ANY(sizes)", *req.Size)
+	}
+	return filters
+}
+
+// searchWhere returns the WHERE clause applying the filters but the ones of the except facet.
+func searchWhere(filters []searchFilter, except string, conditions ...string) string {
+	for _, filter := range filters {
+		if filter.facet != except {
+			conditions = append(conditions, filter.condition)
+		}
+	}
+	if len(conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(conditions, " AND ")
+}
+
+func (store *ProductStore) SearchProducts(ctx context.Context, req *entity.SearchProductsReq) (*entity.SearchProductsRes, error) {
+	args := []interface{}{req.Query}
+	filters := searchFilters(req, &args)
+	order, ok := searchOrders[req.Sort]
+	if !ok {
+		order = searchOrders[entity.SortRelevance]
+	}
+
+	hitsQuery := fmt.Sprintf(`
+	WITH matched AS (%s)
+	SELECT
+	id, merchent_id, category_id::text, product_name, description, category_name, images, size, price, discount_price,
+	star, rating_count, units_sold, wishlist_count, score,
+	CASE WHEN q = '' THEN product_name ELSE ts_headline('english', product_name, query, '%s') END,
+	CASE WHEN q = '' THEN description ELSE ts_headline('english', description, query, '%s') END,
+	COUNT(*) OVER ()
+	FROM matched
+	%s
+	ORDER BY %s, id
+	LIMIT %d OFFSET %d;
+	`, searchMatches, nameHeadline, descriptionHeadline, searchWhere(filters, ""), order, req.Limit, req.Offset)
+
+	rows, err := store.storage.DB.QueryContext(ctx, hitsQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := &entity.SearchProductsRes{Hits: []*entity.SearchHit{}}
+	for rows.Next() {
+		var hit entity.SearchHit
+		var images pq.StringArray
+		var sizes pq.Float64Array
+		err := rows.Scan(
+			&hit.ID,
+			&hit.MerchantID,
+			&hit.CategoryID,
+			&hit.ProductName,
+			&hit.Description,
+			&hit.CategoryName,
+			&images,
+			&sizes,
+			&hit.Price,
+			&hit.DiscountPrice,
+			&hit.ReviewStar,
+			&hit.ReviewCount,
+			&hit.UnitsSold,
+			&hit.WishlistCount,
+			&hit.Score,
+			&hit.NameHighlight,
+			&hit.DescriptionHighlight,
+			&res.Total,
+		)
+		if err != nil {
+			return nil, err
+		}
+		hit.ProductImages = []string(images)
+		sizeList := []float64(sizes)
+		hit.Size = &sizeList
+		res.Hits = append(res.Hits, &hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(res.Hits) == 0 && req.Offset > 0 {
+		// the window count is lost past the last page
+		if err := store.storage.DB.QueryRowContext(ctx, fmt.Sprintf(`WITH matched AS (%s) SELECT COUNT(*) FROM matched %s;`,
+			searchMatches, searchWhere(filters, "")), args...).Scan(&res.Total); err != nil {
+			return nil, err
+		}
+	}
+
+	facets, err := store.searchFacets(ctx, filters, args)
+	if err != nil {
+		return nil, err
+	}
+	res.Facets = *facets
+	return res, nil
+}
+
+// searchFacets counts every facet in one query over the matched products.
+func (store *ProductStore) searchFacets(ctx context.Context, filters []searchFilter, args []interface{}) (*entity.SearchFacets, error) {
+	var buckets strings.Builder
+	for _, priceRange := range entity.PriceRanges {
+		if math.IsInf(priceRange.Max, 1) {
+			fmt.Fprintf(&buckets, " ELSE '%s'", priceRange.Value())
+		} else {
+			fmt.Fprintf(&buckets, " WHEN final_price < %g THEN '%s'", priceRange.Max, priceRange.Value())
+		}
+	}
+	thresholds := make([]string, len(entity.RatingThresholds))
+	for i, threshold := range entity.RatingThresholds {
+		thresholds[i] = strconv.FormatFloat(threshold, 'f', -1, 64)
+	}
+
+	facetsQuery := fmt.Sprintf(`
+	WITH matched AS MATERIALIZED (%s)
+	SELECT 'category', category_id::text, COALESCE(category_name, ''), COUNT(*)
+	FROM matched %s GROUP BY 2, 3
+	UNION ALL
+	SELECT 'merchant', merchent_id::text, COALESCE(merchant_name, ''), COUNT(*)
+	FROM matched %s GROUP BY 2, 3
+	UNION ALL
+	SELECT 'price', bucket, '', COUNT(*)
+	FROM (SELECT CASE%s END AS bucket FROM matched %s) prices GROUP BY 2
+	UNION ALL
+	SELECT 'rating', threshold::text, '', COUNT(*)
+	FROM matched CROSS JOIN unnest(ARRAY[%s]::float8[]) threshold %s GROUP BY 2
+	UNION ALL
+	SELECT 'size', available::text, '', COUNT(*)
+	FROM matched CROSS JOIN unnest(sizes) available %s GROUP BY 2;
+	`, searchMatches,
+		searchWhere(filters, "category"),
+		searchWhere(filters, "merchant"),
+		buckets.String(), searchWhere(filters, "price"),
+		strings.Join(thresholds, ", "), searchWhere(filters, "rating", "star >= threshold"),
+		searchWhere(filters, "size"))
+
+	rows, err := store.storage.DB.QueryContext(ctx, facetsQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string][]entity.FacetCount{}
+	for rows.Next() {
+		var facet string
+		var count entity.FacetCount
+		if err := rows.Scan(&facet, &count.Value, &count.Label, &count.Count); err != nil {
+			return nil, err
+		}
+		counts[facet] = append(counts[facet], count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return newSearchFacets(counts), nil
+}
+
+// newSearchFacets orders the counts of each facet and labels the price ranges, the
+// ratings and the sizes. The price ranges and the ratings are listed even without products.
+func newSearchFacets(counts map[string][]entity.FacetCount) *entity.SearchFacets {
+	facets := &entity.SearchFacets{
+		Categories: byCount(counts["category"]),
+		Merchants:  byCount(counts["merchant"]),
+		Sizes:      []entity.FacetCount{},
+	}
+
+	found := map[string]int64{}
+	for _, count := range append(counts["price"], counts["rating"]...) {
+		found[count.Value] = count.Count
+	}
+	for _, priceRange := range entity.PriceRanges {
+		facets.PriceRanges = append(facets.PriceRanges, entity.FacetCount{
+			Value: priceRange.Value(),
+			Label: priceRange.Label(),
+			Count: found[priceRange.Value()],
+		})
+	}
+	for _, threshold := range entity.RatingThresholds {
+		value := strconv.FormatFloat(threshold, 'f', -1, 64)
+		facets.Ratings = append(facets.Ratings, entity.FacetCount{
+			Value: value,
+			Label: value + " stars & up",
+			Count: found[value],
+		})
+	}
+
+	for _, count := range counts["size"] {
+		count.Label = count.Value
+		facets.Sizes = append(facets.Sizes, count)
+	}
+	sort.Slice(facets.Sizes, func(i, j int) bool {
+		a, _ := strconv.ParseFloat(facets.Sizes[i].Value, 64)
+		b, _ := strconv.ParseFloat(facets.Sizes[j].Value, 64)
+		return a < b
+	})
+	return facets
+}
+
+// byCount orders the values of a facet from the most products.
+func byCount(counts []entity.FacetCount) []entity.FacetCount {
+	sorted := append([]entity.FacetCount{}, counts...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		return sorted[i].Label < sorted[j].Label
+	})
+	return sorted
+}
@@ -1,13 +1,12 @@
 package db
 
 import (
+	"context"
 	"fmt"
-	"time"
 
 	"github.com/akmal4410/gestapo/internal/database"
 	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
 	"github.com/akmal4410/gestapo/pkg/utils"
-	"github.com/google/uuid"
 	"github.com/lib/pq"
 )
 
@@ -19,9 +18,9 @@ func NewProductStore(storage *database.Storage) *ProductStore {
 	return &ProductStore{storage: storage}
 
 }
-func (store ProductStore) CheckDataExist(table, column, value string) (bool, error) {
+func (store ProductStore) CheckDataExist(ctx context.Context, table, column, value string) (bool, error) {
 	checkQuery := fmt.Sprintf(`SELECT * FROM %s WHERE %s = $1;`, table, column)
-	res, err := store.storage.DB.Exec(checkQuery, value)
+	res, err := store.storage.DB.ExecContext(ctx, checkQuery, value)
 	if err != nil {
 		return false, err
 	}
@@ -34,7 +33,17 @@ func (store ProductStore) CheckDataExist(table, column, value string) (bool, err
 	return result != 0, nil
 }
 
-func (store *ProductStore) GetProductsForUser(merchantId *string, userId string) ([]*entity.GetProductRes, error) {
+// inCategoryCondition keeps the products of the subtree of the category $n, or every product without one.
+const inCategoryCondition = `($%[1]d::uuid IS NULL OR EXISTS (
+	SELECT 1 FROM categories c JOIN categories root ON root.id = $%[1]d::uuid
+	WHERE c.id = p.category_id AND (c.path = root.path OR c.path LIKE root.path || '/%%')))`
+
+// statsColumns selects the stats of the product p joined as ps, the products
+// without stats yet have none.
+const statsColumns = `ps.rating_average AS star, COALESCE(ps.rating_count, 0) AS rating_count,
+	COALESCE(ps.units_sold, 0) AS units_sold, COALESCE(ps.wishlist_count, 0) AS wishlist_count`
+
+func (store *ProductStore) GetProductsForUser(ctx context.Context, merchantId, categoryId *string, userId string) ([]*entity.GetProductRes, error) {
 	var products []*entity.GetProductRes
 	selectQuery := `
 	SELECT 
@@ -42,20 +51,18 @@ func (store *ProductStore) GetProductsForUser(merchantId *string, userId string)
     p.product_name AS product_name, 
     p.images AS product_images, 
     p.price AS product_price,
-    AVG(r.star) AS star,
+    ` + statsColumns + `,
     w.id AS wishlist_id
 	FROM 
     products p
 	LEFT JOIN 
-    reviews r ON p.id = r.product_id
+    product_stats ps ON p.id = ps.product_id
 	LEFT JOIN 
-    wishlists w ON p.id = w.product_id 
-    WHERE p.merchent_id = COALESCE($1, p.merchent_id) AND (w.user_id = $2 OR w.user_id IS NULL)
-	GROUP BY 
-    p.id, p.product_name, p.images, p.price, w.id;
+    wishlists w ON p.id = w.product_id AND w.user_id = $2
+    WHERE listed(p) AND p.merchent_id = COALESCE($1, p.merchent_id) AND ` + fmt.Sprintf(inCategoryCondition, 3) + `;
     `
 
-	rows, err := store.storage.DB.Query(selectQuery, merchantId, userId)
+	rows, err := store.storage.DB.QueryContext(ctx, selectQuery, merchantId, userId, categoryId)
 	if err != nil {
 		return nil, err
 	}
@@ -71,6 +78,9 @@ func (store *ProductStore) GetProductsForUser(merchantId *string, userId string)
 			&images,
 			&product.Price,
 			&product.ReviewStar,
+			&product.ReviewCount,
+			&product.UnitsSold,
+			&product.WishlistCount,
 			&product.WishlistID,
 		)
 		if err != nil {
@@ -92,15 +102,20 @@ func (store *ProductStore) GetProductsForUser(merchantId *string, userId string)
 	return products, nil
 }
 
-func (store *ProductStore) GetProductsForMerchants(merchantId *string) ([]*entity.GetProductRes, error) {
+// GetProductsForMerchants returns the listed products, and with unlisted the
+// drafts, the pending and the archived ones too with their lifecycle.
+func (store *ProductStore) GetProductsForMerchants(ctx context.Context, merchantId, categoryId *string, unlisted bool) ([]*entity.GetProductRes, error) {
 	var products []*entity.GetProductRes
 	selectQuery := `
-	SELECT id, product_name, images, price
-	FROM products
-	WHERE merchent_id = COALESCE($1, merchent_id);
+	SELECT p.id, p.product_name, p.images, p.price, ` + statsColumns + `,
+	p.status, p.publish_at, p.unpublish_at, p.status_reason
+	FROM products p
+	LEFT JOIN product_stats ps ON p.id = ps.product_id
+	WHERE p.deleted_at IS NULL AND ($3 OR listed(p))
+	AND p.merchent_id = COALESCE($1, p.merchent_id) AND ` + fmt.Sprintf(inCategoryCondition, 2) + `;
     `
 
-	rows, err := store.storage.DB.Query(selectQuery, merchantId)
+	rows, err := store.storage.DB.QueryContext(ctx, selectQuery, merchantId, categoryId, unlisted)
 	if err != nil {
 		return nil, err
 	}
@@ -115,10 +130,21 @@ func (store *ProductStore) GetProductsForMerchants(merchantId *string) ([]*entit
 			&product.ProductName,
 			&images,
 			&product.Price,
+			&product.ReviewStar,
+			&product.ReviewCount,
+			&product.UnitsSold,
+			&product.WishlistCount,
+			&product.Status,
+			&product.PublishAt,
+			&product.UnpublishAt,
+			&product.StatusReason,
 		)
 		if err != nil {
 			return nil, err
 		}
+		if !unlisted {
+			product.Status, product.PublishAt, product.UnpublishAt, product.StatusReason = nil, nil, nil, nil
+		}
 		product.ProductImages = []string(images)
 		products = append(products, &product)
 	}
@@ -135,7 +161,7 @@ func (store *ProductStore) GetProductsForMerchants(merchantId *string) ([]*entit
 	return products, nil
 }
 
-func (store *ProductStore) GetProductByIdForUser(productId, userId string) (*entity.GetProductRes, error) {
+func (store *ProductStore) GetProductByIdForUser(ctx context.Context, productId, userId string) (*entity.GetProductRes, error) {
 	selectQuery := `
 	SELECT
     p.id AS id,
@@ -144,6 +170,7 @@ func (store *ProductStore) GetProductByIdForUser(productId, userId string) (*ent
     p.description AS description,
     c.category_name AS category_name,
     p.size AS size,
+    p.option_types AS option_types,
     p.price AS price,
     CASE
         WHEN d.end_time IS NOT NULL AND d.end_time > NOW()
@@ -151,7 +178,8 @@ func (store *ProductStore) GetProductByIdForUser(productId, userId string) (*ent
         ELSE NULL
     END AS discount_price,
     p.images AS product_images,
-	AVG(r.star) AS star,
+    p.attributes AS attributes,
+	` + statsColumns + `,
     w.id AS wishlist_id
 	FROM
     products p
@@ -160,22 +188,20 @@ func (store *ProductStore) GetProductByIdForUser(productId, userId string) (*ent
 	LEFT JOIN
     discounts d ON p.discount_id = d.id
 	LEFT JOIN 
-    reviews r ON p.id = r.product_id
+    product_stats ps ON p.id = ps.product_id
 	LEFT JOIN 
-    wishlists w ON p.id = w.product_id 
+    wishlists w ON p.id = w.product_id AND w.user_id = $2
 	WHERE 
-	p.id = $1 AND (w.user_id = $2 OR w.user_id IS NULL)
-	GROUP BY 
-    p.id, p.merchent_id, p.product_name, p.description, c.category_name, p.size, p.price, p.images, w.id, d.end_time, d.percent;
+	p.id = $1 AND listed(p);
 	`
-	rows := store.storage.DB.QueryRow(selectQuery, productId, userId)
+	rows := store.storage.DB.QueryRowContext(ctx, selectQuery, productId, userId)
 	if rows.Err() != nil {
 		return nil, rows.Err()
 	}
 
 	var product entity.GetProductRes
 
-	var images pq.StringArray
+	var images, optionTypes pq.StringArray
 	var sizes pq.Float64Array
 
 	err := rows.Scan(
@@ -185,12 +211,18 @@ func (store *ProductStore) GetProductByIdForUser(productId, userId string) (*ent
 		&product.Description,
 		&product.CategoryName,
 		&sizes,
+		&optionTypes,
 		&product.Price,
 		&product.DiscountPrice,
 		&images,
+		&product.Attributes,
 		&product.ReviewStar,
+		&product.ReviewCount,
+		&product.UnitsSold,
+		&product.WishlistCount,
 		&product.WishlistID,
 	)
+	product.Listed = true
 	product.ProductImages = []string(images)
 	// Convert pq.Float64Array to []float64
 	var sizeList []float64
@@ -199,13 +231,20 @@ func (store *ProductStore) GetProductByIdForUser(productId, userId string) (*ent
 	}
 	product.Size = &sizeList
 
+	if err != nil {
+		return nil, err
+	}
+	product.OptionTypes = []string(optionTypes)
+	product.Variants, err = store.variants(ctx, productId)
 	if err != nil {
 		return nil, err
 	}
 	return &product, nil
 }
 
-func (store *ProductStore) GetProductByIdForMerchant(productId string) (*entity.GetProductRes, error) {
+// GetProductByIdForMerchant returns the product unless it is deleted, with its
+// lifecycle and whether it is listed.
+func (store *ProductStore) GetProductByIdForMerchant(ctx context.Context, productId string) (*entity.GetProductRes, error) {
 	selectQuery := `
 	SELECT
     p.id AS id,
@@ -214,30 +253,36 @@ func (store *ProductStore) GetProductByIdForMerchant(productId string) (*entity.
     p.description AS description,
     c.category_name AS category_name,
     p.size AS size,
+    p.option_types AS option_types,
     p.price AS price,
     CASE
         WHEN d.end_time IS NOT NULL AND d.end_time > NOW()
 		THEN p.price - (p.price * d.percent / 100) 
         ELSE NULL
     END AS discount_price,
-    p.images AS product_images
+    p.images AS product_images,
+    p.attributes AS attributes,
+	` + statsColumns + `,
+	listed(p), p.status, p.publish_at, p.unpublish_at, p.status_reason
 	FROM
     products p
 	LEFT JOIN
     categories c ON p.category_id = c.id
 	LEFT JOIN
     discounts d ON p.discount_id = d.id
+	LEFT JOIN 
+    product_stats ps ON p.id = ps.product_id
 	WHERE 
-	p.id = $1;
+	p.id = $1 AND p.deleted_at IS NULL;
 	`
-	rows := store.storage.DB.QueryRow(selectQuery, productId)
+	rows := store.storage.DB.QueryRowContext(ctx, selectQuery, productId)
 	if rows.Err() != nil {
 		return nil, rows.Err()
 	}
 
 	var product entity.GetProductRes
 
-	var images pq.StringArray
+	var images, optionTypes pq.StringArray
 	var sizes pq.Float64Array
 
 	err := rows.Scan(
@@ -247,9 +292,20 @@ func (store *ProductStore) GetProductByIdForMerchant(productId string) (*entity.
 		&product.Description,
 		&product.CategoryName,
 		&sizes,
+		&optionTypes,
 		&product.Price,
 		&product.DiscountPrice,
 		&images,
+		&product.Attributes,
+		&product.ReviewStar,
+		&product.ReviewCount,
+		&product.UnitsSold,
+		&product.WishlistCount,
+		&product.Listed,
+		&product.Status,
+		&product.PublishAt,
+		&product.UnpublishAt,
+		&product.StatusReason,
 	)
 	product.ProductImages = []string(images)
 	// Convert pq.Float64Array to []float64
@@ -259,13 +315,68 @@ func (store *ProductStore) GetProductByIdForMerchant(productId string) (*entity.
 	}
 	product.Size = &sizeList
 
+	if err != nil {
+		return nil, err
+	}
+	product.OptionTypes = []string(optionTypes)
+	product.Variants, err = store.variants(ctx, productId)
 	if err != nil {
 		return nil, err
 	}
 	return &product, nil
 }
 
-func (store *ProductStore) IsUserCanAddReview(orderItemID, userID string) (bool, error) {
+// variants returns the inventories of the product with their price, their own or
+// the one of the product, and the price after the running discount.
+func (store *ProductStore) variants(ctx context.Context, productId string) ([]*entity.Variant, error) {
+	selectQuery := `
+	SELECT
+	i.id,
+	i.sku,
+	i.options,
+	COALESCE(i.price, p.price) AS price,
+	CASE
+		WHEN d.end_time IS NOT NULL AND d.end_time > NOW()
+		THEN COALESCE(i.price, p.price) - (COALESCE(i.price, p.price) * d.percent / 100)
+		ELSE NULL
+	END AS discount_price,
+	i.images,
+	i.quantity
+	FROM inventories i
+	JOIN products p ON i.product_id = p.id
+	LEFT JOIN discounts d ON p.discount_id = d.id
+	WHERE i.product_id = $1
+	ORDER BY i.size, i.sku;
+	`
+	rows, err := store.storage.DB.QueryContext(ctx, selectQuery, productId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	variants := []*entity.Variant{}
+	for rows.Next() {
+		var variant entity.Variant
+		var images pq.StringArray
+		err := rows.Scan(
+			&variant.ID,
+			&variant.SKU,
+			&variant.Options,
+			&variant.Price,
+			&variant.DiscountPrice,
+			&images,
+			&variant.Quantity,
+		)
+		if err != nil {
+			return nil, err
+		}
+		variant.Images = []string(images)
+		variants = append(variants, &variant)
+	}
+	return variants, rows.Err()
+}
+
+func (store *ProductStore) IsUserCanAddReview(ctx context.Context, orderItemID, userID string) (bool, error) {
 	selectQuery := `SELECT COUNT(oi.id) 
 	FROM order_items oi
 	JOIN products p ON oi.product_id = p.id
@@ -273,7 +384,7 @@ func (store *ProductStore) IsUserCanAddReview(orderItemID, userID string) (bool,
 	WHERE oi.id = $1 AND od.user_id = $2 AND oi.status = $3;
 	`
 	var count int
-	err := store.storage.DB.QueryRow(selectQuery, orderItemID, userID, utils.OrderCompleted).Scan(&count)
+	err := store.storage.DB.QueryRowContext(ctx, selectQuery, orderItemID, userID, utils.OrderCompleted).Scan(&count)
 	if err != nil {
 		fmt.Println("Error executing query:", err)
 		return false, err
@@ -281,35 +392,16 @@ func (store *ProductStore) IsUserCanAddReview(orderItemID, userID string) (bool,
 	return count > 0, nil
 }
 
-func (store *ProductStore) IsUserAlreadyAddedReview(productID, userID string) (bool, error) {
+func (store *ProductStore) IsUserAlreadyAddedReview(ctx context.Context, productID, userID string) (bool, error) {
 	selectQuery := `SELECT COUNT(*) 
 	FROM reviews
 	WHERE product_id = $1 AND user_id = $2;
 	`
 	var count int
-	err := store.storage.DB.QueryRow(selectQuery, productID, userID).Scan(&count)
+	err := store.storage.DB.QueryRowContext(ctx, selectQuery, productID, userID).Scan(&count)
 	if err != nil {
 		fmt.Println("Error executing query:", err)
 		return false, err
 	}
 	return count > 0, nil
 }
-
-func (store *ProductStore) AddProductReview(req *entity.AddReviewReq) error {
-	createdAt := time.Now()
-	updatedAt := time.Now()
-
-	uuId, err := uuid.NewRandom()
-	if err != nil {
-		return err
-	}
-	insertQuery := `
-	INSERT INTO reviews (id, product_id, user_id, star, review, created_at, updated_at)
-	VALUES ($1, $2, $3, $4, $5, $6, $7);
-	`
-	_, err = store.storage.DB.Exec(insertQuery, uuId, req.ProductID, req.UserID, req.Star, req.Review, createdAt, updatedAt)
-	if err != nil {
-		return err
-	}
-	return nil
-}
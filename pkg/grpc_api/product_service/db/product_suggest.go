@@ -0,0 +1,141 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+// SuggestionWindow is how long the logged queries are suggested.
+const SuggestionWindow = 30 * 24 * time.Hour
+
+// likePrefix escapes the wildcards of LIKE in prefix.
+var likePrefix = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace
+
+func (store *ProductStore) LogSearchQuery(ctx context.Context, req *entity.SearchQueryLog) error {
+	insertQuery := `
+	INSERT INTO search_queries (id, query, user_id, category_id, results, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6);
+	`
+	var userID *string
+	if req.UserID != "" {
+		userID = &req.UserID
+	}
+	_, err := store.storage.DB.ExecContext(ctx, insertQuery, uuid.New(), req.Query, userID, req.CategoryID, req.Results, time.Now())
+	return err
+}
+
+// SuggestQueries returns the queries, products, categories and merchants starting
+// with prefix, or with a word starting with it. The queries are ranked by the number
+// of users searching them, the others by the number of orders.
+func (store *ProductStore) SuggestQueries(ctx context.Context, prefix string, limit int) (*entity.Suggestions, error) {
+	prefix = likePrefix(prefix)
+	queriesQuery := `
+	SELECT '', query, COUNT(DISTINCT COALESCE(user_id::text, id::text)) AS popularity
+	FROM search_queries
+	WHERE query LIKE $1 || '%' AND results > 0 AND created_at > $2
+	GROUP BY query
+	ORDER BY popularity DESC, query
+	LIMIT $3;
+	`
+	productsQuery := `
+	SELECT p.id, p.product_name,
+	(SELECT COUNT(*) FROM order_items oi WHERE oi.product_id = p.id) +
+	(SELECT COUNT(*) FROM wishlists w WHERE w.product_id = p.id) AS popularity
+	FROM products p
+	WHERE listed(p) AND (p.product_name ILIKE $1 || '%' OR p.product_name ILIKE '% ' || $1 || '%')
+	ORDER BY popularity DESC, p.product_name
+	LIMIT $2;
+	`
+	categoriesQuery := `
+	SELECT c.id, c.category_name, COUNT(oi.id) AS popularity
+	FROM categories c
+	LEFT JOIN products p ON p.category_id = c.id AND p.deleted_at IS NULL
+	LEFT JOIN order_items oi ON oi.product_id = p.id
+	WHERE c.category_name ILIKE $1 || '%' OR c.category_name ILIKE '% ' || $1 || '%'
+	GROUP BY c.id, c.category_name
+	ORDER BY popularity DESC, c.category_name
+	LIMIT $2;
+	`
+	merchantsQuery := `
+	SELECT u.id, u.user_name, COUNT(oi.id) AS popularity
+	FROM user_data u
+	LEFT JOIN products p ON p.merchent_id = u.id AND p.deleted_at IS NULL
+	LEFT JOIN order_items oi ON oi.product_id = p.id
+	WHERE u.user_type = $3 AND (u.user_name ILIKE $1 || '%' OR u.full_name ILIKE $1 || '%' OR u.full_name ILIKE '% ' || $1 || '%')
+	GROUP BY u.id, u.user_name
+	ORDER BY popularity DESC, u.user_name
+	LIMIT $2;
+	`
+
+	var suggestions entity.Suggestions
+	var err error
+	if suggestions.Queries, err = store.suggestions(ctx, queriesQuery, prefix, time.Now().Add(-SuggestionWindow), limit); err != nil {
+		return nil, err
+	}
+	if suggestions.Products, err = store.suggestions(ctx, productsQuery, prefix, limit); err != nil {
+		return nil, err
+	}
+	if suggestions.Categories, err = store.suggestions(ctx, categoriesQuery, prefix, limit); err != nil {
+		return nil, err
+	}
+	if suggestions.Merchants, err = store.suggestions(ctx, merchantsQuery, prefix, limit, utils.MERCHANT); err != nil {
+		return nil, err
+	}
+	return &suggestions, nil
+}
+
+func (store *ProductStore) suggestions(ctx context.Context, query string, args ...interface{}) ([]entity.Suggestion, error) {
+	rows, err := store.storage.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suggestions := []entity.Suggestion{}
+	for rows.Next() {
+		var suggestion entity.Suggestion
+		if err := rows.Scan(&suggestion.ID, &suggestion.Text, &suggestion.Popularity); err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	return suggestions, rows.Err()
+}
+
+// GetTrendingSearches counts each user once per query, so one user searching again
+// does not make a query trend. The queries finding nothing are left out.
+func (store *ProductStore) GetTrendingSearches(ctx context.Context, req *entity.TrendingSearchesReq) ([]*entity.QueryCount, error) {
+	selectQuery := `
+	SELECT query, COUNT(DISTINCT COALESCE(user_id::text, id::text)) AS searches
+	FROM search_queries
+	WHERE results > 0 AND created_at >= $1 AND created_at < $2 AND ($3::uuid IS NULL OR category_id = $3::uuid)
+	GROUP BY query
+	ORDER BY searches DESC, query
+	LIMIT $4;
+	`
+	var categoryID sql.NullString
+	if req.CategoryID != nil {
+		categoryID = sql.NullString{String: *req.CategoryID, Valid: true}
+	}
+	rows, err := store.storage.DB.QueryContext(ctx, selectQuery, req.From, req.To, categoryID, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	trending := []*entity.QueryCount{}
+	for rows.Next() {
+		var count entity.QueryCount
+		if err := rows.Scan(&count.Query, &count.Count); err != nil {
+			return nil, err
+		}
+		trending = append(trending, &count)
+	}
+	return trending, rows.Err()
+}
@@ -0,0 +1,248 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+func (store *ProductStore) AddQuestion(ctx context.Context, req *entity.AddQuestionReq) error {
+	tx, err := store.storage.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	insertQuery := `
+	INSERT INTO product_questions (id, product_id, user_id, question, status, flags, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $7);
+	`
+	questionStatus := reviewStatus(req.Flags)
+	_, err = tx.ExecContext(ctx, insertQuery, uuid.New(), req.ProductID, req.UserID, req.Question, questionStatus,
+		pq.StringArray(append([]string{}, req.Flags...)), now)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if questionStatus == utils.ReviewPublished {
+		notifyQuery := `
+		INSERT INTO notifications (id, user_id, type, title, body, product_id, created_at)
+		SELECT $1, p.merchent_id, $2, $3, format($4, p.product_name), p.id, $5
+		FROM products p WHERE p.id = $6;
+		`
+		_, err = tx.ExecContext(ctx, notifyQuery, uuid.New(), utils.NotificationNewQuestion, req.Notification.Title,
+			req.Notification.Body, now, req.ProductID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (store *ProductStore) GetQuestion(ctx context.Context, questionId string) (*entity.Question, error) {
+	selectQuery := `
+	SELECT q.id, q.product_id, p.merchent_id, q.user_id, COALESCE(NULLIF(u.full_name, ''), u.user_name),
+	q.question, q.status, q.created_at, q.updated_at
+	FROM product_questions q
+	JOIN products p ON p.id = q.product_id AND listed(p)
+	JOIN user_data u ON u.id = q.user_id
+	WHERE q.id = $1;
+	`
+	rows, err := store.storage.DB.QueryContext(ctx, selectQuery, questionId)
+	if err != nil {
+		return nil, err
+	}
+	questions, err := scanQuestions(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(questions) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return questions[0], nil
+}
+
+func (store *ProductStore) GetProductQuestions(ctx context.Context, req *entity.QuestionsReq) (*entity.QuestionsRes, error) {
+	res := &entity.QuestionsRes{}
+	countQuery := `
+	SELECT COUNT(*) FROM product_questions q
+	JOIN products p ON p.id = q.product_id AND listed(p)
+	WHERE q.product_id = $1 AND q.status = 'PUBLISHED';
+	`
+	err := store.storage.DB.QueryRowContext(ctx, countQuery, req.ProductID).Scan(&res.Total)
+	if err != nil {
+		return nil, err
+	}
+
+	selectQuery := `
+	SELECT q.id, q.product_id, p.merchent_id, q.user_id, COALESCE(NULLIF(u.full_name, ''), u.user_name),
+	q.question, q.status, q.created_at, q.updated_at
+	FROM product_questions q
+	JOIN products p ON p.id = q.product_id AND listed(p)
+	JOIN user_data u ON u.id = q.user_id
+	WHERE q.product_id = $1 AND q.status = 'PUBLISHED'
+	ORDER BY q.created_at DESC, q.id
+	LIMIT $2 OFFSET $3;
+	`
+	rows, err := store.storage.DB.QueryContext(ctx, selectQuery, req.ProductID, req.Limit, req.Offset)
+	if err != nil {
+		return nil, err
+	}
+	res.Questions, err = scanQuestions(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Questions) == 0 {
+		return res, nil
+	}
+
+	questions := map[string]*entity.Question{}
+	ids := make([]string, 0, len(res.Questions))
+	for _, question := range res.Questions {
+		questions[question.ID] = question
+		ids = append(ids, question.ID)
+	}
+	// the answer of the merchant first, then the most upvoted and the oldest
+	answerQuery := `
+	SELECT a.id, a.question_id, a.user_id, COALESCE(NULLIF(u.full_name, ''), u.user_name), a.answer, a.by_merchant,
+	COUNT(v.id) AS upvotes, COALESCE(BOOL_OR(v.user_id::text = $2), false), a.status, a.created_at, a.updated_at
+	FROM product_answers a
+	JOIN user_data u ON u.id = a.user_id
+	LEFT JOIN answer_votes v ON v.answer_id = a.id
+	WHERE a.question_id::text = ANY($1) AND a.status = 'PUBLISHED'
+	GROUP BY a.id, u.full_name, u.user_name
+	ORDER BY a.by_merchant DESC, upvotes DESC, a.created_at ASC, a.id;
+	`
+	answerRows, err := store.storage.DB.QueryContext(ctx, answerQuery, pq.Array(ids), req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer answerRows.Close()
+	for answerRows.Next() {
+		var answer entity.Answer
+		err := answerRows.Scan(
+			&answer.ID,
+			&answer.QuestionID,
+			&answer.UserID,
+			&answer.AuthorName,
+			&answer.Answer,
+			&answer.ByMerchant,
+			&answer.Upvotes,
+			&answer.Upvoted,
+			&answer.Status,
+			&answer.CreatedAt,
+			&answer.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		answer.QuestionStatus = utils.ReviewPublished
+		question := questions[answer.QuestionID]
+		question.Answers = append(question.Answers, &answer)
+	}
+	return res, answerRows.Err()
+}
+
+func scanQuestions(rows *sql.Rows) ([]*entity.Question, error) {
+	defer rows.Close()
+	questions := []*entity.Question{}
+	for rows.Next() {
+		question := entity.Question{Answers: []*entity.Answer{}}
+		err := rows.Scan(
+			&question.ID,
+			&question.ProductID,
+			&question.MerchantID,
+			&question.UserID,
+			&question.AuthorName,
+			&question.Question,
+			&question.Status,
+			&question.CreatedAt,
+			&question.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		questions = append(questions, &question)
+	}
+	return questions, rows.Err()
+}
+
+func (store *ProductStore) HasBoughtProduct(ctx context.Context, productId, userId string) (bool, error) {
+	selectQuery := `
+	SELECT EXISTS (
+		SELECT 1 FROM order_items oi
+		JOIN order_details od ON od.id = oi.order_id
+		WHERE oi.product_id = $1 AND od.user_id = $2 AND oi.status = $3
+	);
+	`
+	var bought bool
+	err := store.storage.DB.QueryRowContext(ctx, selectQuery, productId, userId, utils.OrderCompleted).Scan(&bought)
+	return bought, err
+}
+
+func (store *ProductStore) AddAnswer(ctx context.Context, req *entity.AddAnswerReq) error {
+	insertQuery := `
+	INSERT INTO product_answers (id, question_id, user_id, answer, by_merchant, status, flags, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8);
+	`
+	_, err := store.storage.DB.ExecContext(ctx, insertQuery, uuid.New(), req.QuestionID, req.UserID, req.Answer, req.ByMerchant,
+		reviewStatus(req.Flags), pq.StringArray(append([]string{}, req.Flags...)), time.Now())
+	if err, ok := err.(*pq.Error); ok && err.Code == "23503" {
+		return sql.ErrNoRows
+	}
+	return err
+}
+
+func (store *ProductStore) GetAnswer(ctx context.Context, answerId string) (*entity.Answer, error) {
+	selectQuery := `
+	SELECT a.id, a.question_id, a.user_id, COALESCE(NULLIF(u.full_name, ''), u.user_name), a.answer, a.by_merchant,
+	a.status, q.status, a.created_at, a.updated_at
+	FROM product_answers a
+	JOIN product_questions q ON q.id = a.question_id
+	JOIN products p ON p.id = q.product_id AND listed(p)
+	JOIN user_data u ON u.id = a.user_id
+	WHERE a.id = $1;
+	`
+	var answer entity.Answer
+	err := store.storage.DB.QueryRowContext(ctx, selectQuery, answerId).Scan(
+		&answer.ID,
+		&answer.QuestionID,
+		&answer.UserID,
+		&answer.AuthorName,
+		&answer.Answer,
+		&answer.ByMerchant,
+		&answer.Status,
+		&answer.QuestionStatus,
+		&answer.CreatedAt,
+		&answer.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &answer, nil
+}
+
+func (store *ProductStore) UpvoteAnswer(ctx context.Context, answerId, userId string, upvote bool) error {
+	if !upvote {
+		_, err := store.storage.DB.ExecContext(ctx, `DELETE FROM answer_votes WHERE answer_id = $1 AND user_id = $2;`, answerId, userId)
+		return err
+	}
+
+	insertQuery := `
+	INSERT INTO answer_votes (id, answer_id, user_id, created_at)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (answer_id, user_id) DO NOTHING;
+	`
+	_, err := store.storage.DB.ExecContext(ctx, insertQuery, uuid.New(), answerId, userId, time.Now())
+	if err, ok := err.(*pq.Error); ok && err.Code == "23503" {
+		return sql.ErrNoRows
+	}
+	return err
+}
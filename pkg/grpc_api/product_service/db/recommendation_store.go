@@ -0,0 +1,148 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	"github.com/lib/pq"
+)
+
+// ComputeRecommendations replaces the recommendations with the products found
+// together in the orders not cancelled, the carts and the wishlists of a user,
+// keeping the perProduct best ones of each product, and returns how many it wrote.
+// An order or a cart holding a product twice counts once.
+func (store *ProductStore) ComputeRecommendations(ctx context.Context, weights entity.RecommendationWeights, perProduct int) (int64, error) {
+	tx, err := store.storage.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM product_recommendations;`)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	insertQuery := `
+	WITH ordered AS (
+		SELECT DISTINCT order_id, product_id FROM order_items
+		WHERE status <> 'Cancelled' AND deleted_at IS NULL
+	), carted AS (
+		SELECT DISTINCT cart_id, product_id FROM cart_items
+	), wished AS (
+		SELECT DISTINCT user_id, product_id FROM wishlists
+	), pairs AS (
+		SELECT a.product_id, b.product_id AS related_id, $1::float8 AS weight
+		FROM ordered a JOIN ordered b ON b.order_id = a.order_id AND b.product_id <> a.product_id
+		UNION ALL
+		SELECT a.product_id, b.product_id, $2::float8
+		FROM carted a JOIN carted b ON b.cart_id = a.cart_id AND b.product_id <> a.product_id
+		UNION ALL
+		SELECT a.product_id, b.product_id, $3::float8
+		FROM wished a JOIN wished b ON b.user_id = a.user_id AND b.product_id <> a.product_id
+	), ranked AS (
+		SELECT product_id, related_id, SUM(weight) AS score,
+		ROW_NUMBER() OVER (PARTITION BY product_id ORDER BY SUM(weight) DESC, related_id) AS rank
+		FROM pairs GROUP BY product_id, related_id HAVING SUM(weight) > 0
+	)
+	INSERT INTO product_recommendations (product_id, related_id, score, updated_at)
+	SELECT product_id, related_id, score, NOW() FROM ranked
+	WHERE rank <= $4;
+	`
+	res, err := tx.ExecContext(ctx, insertQuery, weights.Order, weights.Cart, weights.Wishlist, perProduct)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	return n, tx.Commit()
+}
+
+func (store *ProductStore) GetRelatedProducts(ctx context.Context, productId, userId string, limit int) ([]*entity.GetProductRes, error) {
+	// the products without recommendations yet fall back to the products of
+	// the same category, the closest in price first
+	selectQuery := `
+	SELECT p.id, p.product_name, p.images, p.price, ` + statsColumns + `, w.id
+	FROM products t
+	JOIN products p ON p.id <> t.id
+	LEFT JOIN product_recommendations r ON r.product_id = t.id AND r.related_id = p.id
+	LEFT JOIN product_stats ps ON p.id = ps.product_id
+	LEFT JOIN wishlists w ON p.id = w.product_id AND w.user_id = $2
+	WHERE t.id = $1 AND listed(p) AND (r.score IS NOT NULL OR p.category_id = t.category_id)
+	ORDER BY r.score DESC NULLS LAST, ABS(p.price - t.price), COALESCE(ps.units_sold, 0) DESC, p.id
+	LIMIT $3;
+	`
+	rows, err := store.storage.DB.QueryContext(ctx, selectQuery, productId, userId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanRecommendedProducts(rows)
+}
+
+func (store *ProductStore) GetRecommendedProducts(ctx context.Context, userId string, limit int) ([]*entity.GetProductRes, error) {
+	// the products the user ordered, carted or wishlisted pick the products
+	// recommended with them, the products of their categories and then the
+	// best sellers fill the rest, for the users without history too
+	selectQuery := `
+	WITH seeds AS (
+		SELECT i.product_id FROM order_items i JOIN order_details o ON o.id = i.order_id
+		WHERE o.user_id = $1 AND i.status <> 'Cancelled' AND i.deleted_at IS NULL
+		UNION
+		SELECT i.product_id FROM cart_items i JOIN carts c ON c.id = i.cart_id WHERE c.user_id = $1
+		UNION
+		SELECT product_id FROM wishlists WHERE user_id = $1
+	), scores AS (
+		SELECT r.related_id AS product_id, SUM(r.score) AS score
+		FROM product_recommendations r JOIN seeds s ON s.product_id = r.product_id
+		GROUP BY r.related_id
+	), seed_categories AS (
+		SELECT DISTINCT p.category_id FROM products p JOIN seeds s ON s.product_id = p.id
+	)
+	SELECT p.id, p.product_name, p.images, p.price, ` + statsColumns + `, w.id
+	FROM products p
+	LEFT JOIN scores sc ON sc.product_id = p.id
+	LEFT JOIN product_stats ps ON p.id = ps.product_id
+	LEFT JOIN wishlists w ON p.id = w.product_id AND w.user_id = $1
+	WHERE listed(p) AND p.id NOT IN (SELECT product_id FROM seeds)
+	ORDER BY sc.score DESC NULLS LAST,
+	p.category_id IN (SELECT category_id FROM seed_categories) DESC,
+	COALESCE(ps.units_sold, 0) DESC, COALESCE(ps.wishlist_count, 0) DESC, ps.rating_average DESC NULLS LAST, p.id
+	LIMIT $2;
+	`
+	rows, err := store.storage.DB.QueryContext(ctx, selectQuery, userId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanRecommendedProducts(rows)
+}
+
+func scanRecommendedProducts(rows *sql.Rows) ([]*entity.GetProductRes, error) {
+	defer rows.Close()
+	products := []*entity.GetProductRes{}
+	for rows.Next() {
+		var product entity.GetProductRes
+		var images pq.StringArray
+		err := rows.Scan(
+			&product.ID,
+			&product.ProductName,
+			&images,
+			&product.Price,
+			&product.ReviewStar,
+			&product.ReviewCount,
+			&product.UnitsSold,
+			&product.WishlistCount,
+			&product.WishlistID,
+		)
+		if err != nil {
+			return nil, err
+		}
+		product.ProductImages = []string(images)
+		products = append(products, &product)
+	}
+	return products, rows.Err()
+}
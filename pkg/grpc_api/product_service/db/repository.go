@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+)
+
+// ProductRepository is the storage used by the product service,
+// implemented by ProductStore on Postgres and by MemoryProductStore in memory.
+type ProductRepository interface {
+	CheckDataExist(ctx context.Context, table, column, value string) (bool, error)
+	// GetProductsForUser and GetProductsForMerchants return the products of the merchant and
+	// of the subtree of the category, when they are given
+	GetProductsForUser(ctx context.Context, merchantId, categoryId *string, userId string) ([]*entity.GetProductRes, error)
+	GetProductsForMerchants(ctx context.Context, merchantId, categoryId *string, unlisted bool) ([]*entity.GetProductRes, error)
+	GetProductByIdForUser(ctx context.Context, productId, userId string) (*entity.GetProductRes, error)
+	GetProductByIdForMerchant(ctx context.Context, productId string) (*entity.GetProductRes, error)
+	IsUserCanAddReview(ctx context.Context, orderItemID, userID string) (bool, error)
+	IsUserAlreadyAddedReview(ctx context.Context, productID, userID string) (bool, error)
+	// AddProductReview adds the review and attaches the upload sessions of its images
+	AddProductReview(ctx context.Context, req *entity.AddReviewReq) error
+	GetReview(ctx context.Context, reviewId string) (*entity.Review, error)
+	GetProductReviews(ctx context.Context, req *entity.ReviewsReq) (*entity.ReviewsRes, error)
+	// GetRatingDistribution returns the number of reviews of the product per star
+	GetRatingDistribution(ctx context.Context, productId string) (map[int32]int64, error)
+	EditProductReview(ctx context.Context, req *entity.EditReviewReq) error
+	DeleteProductReview(ctx context.Context, reviewId string) error
+	// VoteReview sets the vote of the user on the review, a nil helpful removes it
+	VoteReview(ctx context.Context, reviewId, userId string, helpful *bool) error
+	// ReplyToReview sets the reply of the merchant, replacing the previous one
+	ReplyToReview(ctx context.Context, reviewId, reply string) error
+	// CountRecentReviews returns how many reviews the product got since the time
+	CountRecentReviews(ctx context.Context, productId string, since time.Time) (int, error)
+	// ReviewTextExists tells whether a review other than exceptId, added since the
+	// time, has the normalized text
+	ReviewTextExists(ctx context.Context, text string, since time.Time, exceptId string) (bool, error)
+	// ReportReview adds the report, and holds a published review for moderation
+	// once it has threshold open reports
+	ReportReview(ctx context.Context, report *entity.ReviewReport, threshold int) error
+	CreateUploadSession(ctx context.Context, session *entity.UploadSession) error
+	GetUploadSession(ctx context.Context, sessionId string) (*entity.UploadSession, error)
+	SearchProducts(ctx context.Context, req *entity.SearchProductsReq) (*entity.SearchProductsRes, error)
+	LogSearchQuery(ctx context.Context, req *entity.SearchQueryLog) error
+	SuggestQueries(ctx context.Context, prefix string, limit int) (*entity.Suggestions, error)
+	GetTrendingSearches(ctx context.Context, req *entity.TrendingSearchesReq) ([]*entity.QueryCount, error)
+	// ComputeRecommendations replaces the recommendations of every product from the
+	// orders, the carts and the wishlists holding it with other products
+	ComputeRecommendations(ctx context.Context, weights entity.RecommendationWeights, perProduct int) (int64, error)
+	// GetRelatedProducts returns the products recommended with the product, then the
+	// products of its category closest in price
+	GetRelatedProducts(ctx context.Context, productId, userId string, limit int) ([]*entity.GetProductRes, error)
+	// GetRecommendedProducts returns the products recommended with the ones the user
+	// ordered, carted or wishlisted, then the best sellers of their categories and of
+	// the others
+	GetRecommendedProducts(ctx context.Context, userId string, limit int) ([]*entity.GetProductRes, error)
+	// RecordProductView adds a view of the product, or moves the last view of the
+	// viewer to now when it viewed the product within the window
+	RecordProductView(ctx context.Context, productId string, viewer entity.Viewer, window time.Duration) error
+	// GetRecentlyViewed returns the products the viewer viewed, the last viewed first
+	GetRecentlyViewed(ctx context.Context, viewer entity.Viewer, limit int) ([]*entity.RecentlyViewed, error)
+	// ClearRecentlyViewed removes the viewer from its views and returns how many there were
+	ClearRecentlyViewed(ctx context.Context, viewer entity.Viewer) (int64, error)
+	// GetPriceHistory returns the prices of the product since the given time, starting
+	// with the price it had then
+	GetPriceHistory(ctx context.Context, productId string, since time.Time) ([]*entity.PricePoint, error)
+	// RecordPrices adds the prices which changed without a write, when a discount
+	// ended, to the price history and returns how many it added
+	RecordPrices(ctx context.Context) (int64, error)
+	// NotifyPriceDrops checks the prices added to the history since the last call and
+	// notifies the users wishlisting the products whose price dropped by the threshold.
+	// It returns how many notifications it added.
+	NotifyPriceDrops(ctx context.Context, alert entity.PriceDropAlert) (int64, error)
+	// GetVariantStock returns the variant of the product by its id, or by its size
+	// when variantId is nil, or sql.ErrNoRows
+	GetVariantStock(ctx context.Context, productId string, variantId *string, size float64) (*entity.VariantStock, error)
+	// SubscribeToStock adds the user to the queue of the variant and returns false
+	// when the user is already waiting for it
+	SubscribeToStock(ctx context.Context, userId, productId, inventoryId string) (bool, error)
+	// NotifyBackInStock notifies the next batch of subscribers of the variants back
+	// in stock and returns how many notifications it added
+	NotifyBackInStock(ctx context.Context, alert entity.BackInStockAlert) (int64, error)
+	// AddQuestion adds the question, and notifies the merchant of the product when
+	// it is published
+	AddQuestion(ctx context.Context, req *entity.AddQuestionReq) error
+	// GetQuestion returns the question of a listed product, without its answers
+	GetQuestion(ctx context.Context, questionId string) (*entity.Question, error)
+	// GetProductQuestions returns a page of the published questions of the product
+	// with their published answers, the answer of the merchant first
+	GetProductQuestions(ctx context.Context, req *entity.QuestionsReq) (*entity.QuestionsRes, error)
+	// HasBoughtProduct tells whether the user has a completed order of the product
+	HasBoughtProduct(ctx context.Context, productId, userId string) (bool, error)
+	AddAnswer(ctx context.Context, req *entity.AddAnswerReq) error
+	// GetAnswer returns the answer to a question of a listed product
+	GetAnswer(ctx context.Context, answerId string) (*entity.Answer, error)
+	// UpvoteAnswer adds the upvote of the user on the answer, or removes it
+	UpvoteAnswer(ctx context.Context, answerId, userId string, upvote bool) error
+}
+
+var (
+	// ErrUploadNotPending is returned when an upload session is attached twice.
+	ErrUploadNotPending = errors.New("upload session is not pending")
+	// ErrAlreadyReported is returned when a user reports a review twice.
+	ErrAlreadyReported = errors.New("review is already reported by the user")
+)
+
+var (
+	_ ProductRepository = (*ProductStore)(nil)
+	_ ProductRepository = (*MemoryProductStore)(nil)
+)
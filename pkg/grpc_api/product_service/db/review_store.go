@@ -0,0 +1,375 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+func (store *ProductStore) AddProductReview(ctx context.Context, req *entity.AddReviewReq) error {
+	createdAt := time.Now()
+	updatedAt := time.Now()
+
+	uuId, err := uuid.NewRandom()
+	if err != nil {
+		return err
+	}
+
+	tx, err := store.storage.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := attachUploads(ctx, tx, req.SessionIDs); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	insertQuery := `
+	INSERT INTO reviews (id, product_id, user_id, star, review, images, status, flags, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+	`
+	_, err = tx.ExecContext(ctx, insertQuery, uuId, req.ProductID, req.UserID, req.Star, req.Review,
+		pq.StringArray(append([]string{}, req.Images...)), reviewStatus(req.Flags),
+		pq.StringArray(append([]string{}, req.Flags...)), createdAt, updatedAt)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// attachUploads moves the sessions out of PENDING, so they are attached only once.
+func attachUploads(ctx context.Context, tx *sql.Tx, sessionIds []string) error {
+	if len(sessionIds) == 0 {
+		return nil
+	}
+	updateQuery := `
+	UPDATE upload_sessions SET status = $2, updated_at = $3
+	WHERE id = ANY($1) AND status = $4;
+	`
+	res, err := tx.ExecContext(ctx, updateQuery, pq.StringArray(sessionIds), entity.UploadAttached, time.Now(), entity.UploadPending)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n != int64(len(sessionIds)) {
+		return ErrUploadNotPending
+	}
+	return nil
+}
+
+// selectReviewQuery selects the reviews with their votes, the vote returned is the one of $1.
+const selectReviewQuery = `
+	SELECT r.id, r.product_id, p.merchent_id, r.user_id, COALESCE(NULLIF(u.full_name, ''), u.user_name),
+	r.star, r.review, r.images,
+	COUNT(v.id) FILTER (WHERE v.helpful) AS helpful_count,
+	COUNT(v.id) FILTER (WHERE NOT v.helpful) AS not_helpful_count,
+	BOOL_OR(v.helpful) FILTER (WHERE v.user_id::text = $1) AS my_vote,
+	r.reply, r.replied_at, r.reply_updated_at, r.edited_at, r.status, r.created_at, r.updated_at
+	FROM reviews r
+	JOIN products p ON p.id = r.product_id
+	JOIN user_data u ON u.id = r.user_id
+	LEFT JOIN review_votes v ON v.review_id = r.id
+	`
+
+const groupReviewQuery = `GROUP BY r.id, p.merchent_id, u.full_name, u.user_name`
+
+// reviewOrders are the ORDER BY clauses of the review sorts.
+var reviewOrders = map[string]string{
+	entity.ReviewSortNewest:  `r.created_at DESC, r.id`,
+	entity.ReviewSortOldest:  `r.created_at ASC, r.id`,
+	entity.ReviewSortHelpful: `helpful_count DESC, r.created_at DESC, r.id`,
+	entity.ReviewSortHighest: `r.star DESC, r.created_at DESC, r.id`,
+	entity.ReviewSortLowest:  `r.star ASC, r.created_at DESC, r.id`,
+}
+
+func (store *ProductStore) GetReview(ctx context.Context, reviewId string) (*entity.Review, error) {
+	query := selectReviewQuery + `WHERE r.id = $2 ` + groupReviewQuery + `;`
+	reviews, err := store.reviews(ctx, query, "", reviewId)
+	if err != nil {
+		return nil, err
+	}
+	if len(reviews) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return reviews[0], nil
+}
+
+func (store *ProductStore) GetProductReviews(ctx context.Context, req *entity.ReviewsReq) (*entity.ReviewsRes, error) {
+	var total int64
+	countQuery := `
+	SELECT COUNT(*) FROM reviews r
+	WHERE r.product_id = $1 AND r.status = 'PUBLISHED' AND ($2::int IS NULL OR ROUND(r.star::numeric) = $2) AND (NOT $3 OR cardinality(r.images) > 0);
+	`
+	err := store.storage.DB.QueryRowContext(ctx, countQuery, req.ProductID, req.Star, req.WithPhotos).Scan(&total)
+	if err != nil {
+		return nil, err
+	}
+
+	order, ok := reviewOrders[req.Sort]
+	if !ok {
+		order = reviewOrders[entity.ReviewSortNewest]
+	}
+	filter := `WHERE r.product_id = $2 AND r.status = 'PUBLISHED' AND ($3::int IS NULL OR ROUND(r.star::numeric) = $3) AND (NOT $4 OR cardinality(r.images) > 0) `
+	query := selectReviewQuery + filter + groupReviewQuery + fmt.Sprintf(` ORDER BY %s LIMIT $5 OFFSET $6;`, order)
+	reviews, err := store.reviews(ctx, query, req.UserID, req.ProductID, req.Star, req.WithPhotos, req.Limit, req.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.ReviewsRes{Reviews: reviews, Total: total}, nil
+}
+
+func (store *ProductStore) reviews(ctx context.Context, query string, args ...interface{}) ([]*entity.Review, error) {
+	rows, err := store.storage.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews := []*entity.Review{}
+	for rows.Next() {
+		var review entity.Review
+		var images pq.StringArray
+		err := rows.Scan(
+			&review.ID,
+			&review.ProductID,
+			&review.MerchantID,
+			&review.UserID,
+			&review.AuthorName,
+			&review.Star,
+			&review.Review,
+			&images,
+			&review.HelpfulCount,
+			&review.NotHelpfulCount,
+			&review.MyVote,
+			&review.Reply,
+			&review.RepliedAt,
+			&review.ReplyUpdatedAt,
+			&review.EditedAt,
+			&review.Status,
+			&review.CreatedAt,
+			&review.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		review.Images = []string(images)
+		reviews = append(reviews, &review)
+	}
+	return reviews, rows.Err()
+}
+
+func (store *ProductStore) GetRatingDistribution(ctx context.Context, productId string) (map[int32]int64, error) {
+	selectQuery := `
+	SELECT ROUND(star::numeric)::int AS rating, COUNT(*)
+	FROM reviews WHERE product_id = $1 AND status = 'PUBLISHED'
+	GROUP BY rating;
+	`
+	rows, err := store.storage.DB.QueryContext(ctx, selectQuery, productId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	distribution := map[int32]int64{}
+	for rows.Next() {
+		var star int32
+		var count int64
+		if err := rows.Scan(&star, &count); err != nil {
+			return nil, err
+		}
+		distribution[star] = count
+	}
+	return distribution, rows.Err()
+}
+
+func (store *ProductStore) EditProductReview(ctx context.Context, req *entity.EditReviewReq) error {
+	tx, err := store.storage.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	var images pq.StringArray
+	selectQuery := `SELECT images FROM reviews WHERE id = $1 FOR UPDATE;`
+	if err := tx.QueryRowContext(ctx, selectQuery, req.ReviewID).Scan(&images); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := attachUploads(ctx, tx, req.SessionIDs); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	now := time.Now()
+	updateQuery := `
+	UPDATE reviews
+	SET star = COALESCE($2, star), review = COALESCE($3, review), images = $4, edited_at = $5, updated_at = $5,
+	status = CASE WHEN cardinality($6::text[]) > 0 THEN 'PENDING' ELSE status END,
+	flags = CASE WHEN cardinality($6::text[]) > 0 THEN $6::text[] ELSE flags END
+	WHERE id = $1;
+	`
+	edited := entity.EditImages(images, req.RemovedImages, req.AddedImages)
+	_, err = tx.ExecContext(ctx, updateQuery, req.ReviewID, req.Star, req.Review, pq.StringArray(edited), now,
+		pq.StringArray(append([]string{}, req.Flags...)))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteProductReview deletes the review, its votes are deleted with it.
+func (store *ProductStore) DeleteProductReview(ctx context.Context, reviewId string) error {
+	res, err := store.storage.DB.ExecContext(ctx, `DELETE FROM reviews WHERE id = $1;`, reviewId)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (store *ProductStore) VoteReview(ctx context.Context, reviewId, userId string, helpful *bool) error {
+	if helpful == nil {
+		_, err := store.storage.DB.ExecContext(ctx, `DELETE FROM review_votes WHERE review_id = $1 AND user_id = $2;`, reviewId, userId)
+		return err
+	}
+
+	now := time.Now()
+	upsertQuery := `
+	INSERT INTO review_votes (id, review_id, user_id, helpful, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $5)
+	ON CONFLICT (review_id, user_id) DO UPDATE SET helpful = EXCLUDED.helpful, updated_at = EXCLUDED.updated_at;
+	`
+	_, err := store.storage.DB.ExecContext(ctx, upsertQuery, uuid.New(), reviewId, userId, *helpful, now)
+	if err, ok := err.(*pq.Error); ok && err.Code == "23503" {
+		return sql.ErrNoRows
+	}
+	return err
+}
+
+func (store *ProductStore) ReplyToReview(ctx context.Context, reviewId, reply string) error {
+	updateQuery := `
+	UPDATE reviews
+	SET reply = $2, replied_at = COALESCE(replied_at, $3), reply_updated_at = $3, updated_at = $3
+	WHERE id = $1;
+	`
+	res, err := store.storage.DB.ExecContext(ctx, updateQuery, reviewId, reply, time.Now())
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (store *ProductStore) CountRecentReviews(ctx context.Context, productId string, since time.Time) (int, error) {
+	selectQuery := `SELECT COUNT(*) FROM reviews WHERE product_id = $1 AND created_at >= $2;`
+	var count int
+	err := store.storage.DB.QueryRowContext(ctx, selectQuery, productId, since).Scan(&count)
+	return count, err
+}
+
+func (store *ProductStore) ReviewTextExists(ctx context.Context, text string, since time.Time, exceptId string) (bool, error) {
+	selectQuery := `
+	SELECT EXISTS (
+		SELECT 1 FROM reviews
+		WHERE id::text != $3 AND created_at >= $2 AND LOWER(regexp_replace(TRIM(review), '\s+', ' ', 'g')) = $1
+	);
+	`
+	var exists bool
+	err := store.storage.DB.QueryRowContext(ctx, selectQuery, text, since, exceptId).Scan(&exists)
+	return exists, err
+}
+
+func (store *ProductStore) ReportReview(ctx context.Context, report *entity.ReviewReport, threshold int) error {
+	tx, err := store.storage.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	insertQuery := `
+	INSERT INTO review_reports (id, review_id, user_id, reason, details, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $6);
+	`
+	_, err = tx.ExecContext(ctx, insertQuery, report.ID, report.ReviewID, report.UserID, report.Reason, report.Details, now)
+	if err != nil {
+		tx.Rollback()
+		if err, ok := err.(*pq.Error); ok {
+			switch err.Code {
+			case "23505":
+				return ErrAlreadyReported
+			case "23503":
+				return sql.ErrNoRows
+			}
+		}
+		return err
+	}
+
+	holdQuery := `
+	UPDATE reviews SET status = 'PENDING', flags = array_append(flags, $2), updated_at = $3
+	WHERE id = $1 AND status = 'PUBLISHED'
+	AND (SELECT COUNT(*) FROM review_reports WHERE review_id = $1 AND resolved_at IS NULL) >= $4;
+	`
+	_, err = tx.ExecContext(ctx, holdQuery, report.ReviewID, entity.FlagReported, now, threshold)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (store *ProductStore) CreateUploadSession(ctx context.Context, session *entity.UploadSession) error {
+	insertQuery := `
+	INSERT INTO upload_sessions
+	(id, user_id, purpose, target_id, key, content_type, size, status, expires_at, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);
+	`
+	_, err := store.storage.DB.ExecContext(ctx, insertQuery, session.ID, session.UserID, session.Purpose, session.TargetID,
+		session.Key, session.ContentType, session.Size, session.Status, session.ExpiresAt, session.CreatedAt, session.CreatedAt)
+	return err
+}
+
+func (store *ProductStore) GetUploadSession(ctx context.Context, sessionId string) (*entity.UploadSession, error) {
+	selectQuery := `
+	SELECT id, user_id, purpose, target_id, key, content_type, size, status, expires_at, created_at
+	FROM upload_sessions WHERE id = $1;
+	`
+	row := store.storage.DB.QueryRowContext(ctx, selectQuery, sessionId)
+	var session entity.UploadSession
+	err := row.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.Purpose,
+		&session.TargetID,
+		&session.Key,
+		&session.ContentType,
+		&session.Size,
+		&session.Status,
+		&session.ExpiresAt,
+		&session.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
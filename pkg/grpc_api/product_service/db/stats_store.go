@@ -0,0 +1,104 @@
+package db
+
+import (
+	"context"
+
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+)
+
+// BackfillStats recomputes the stats of every product from the tables and
+// returns how many products it wrote. The stats are locked meanwhile, the
+// changes committed during the backfill wait for it and add up after it.
+func (store *ProductStore) BackfillStats(ctx context.Context) (int64, error) {
+	tx, err := store.storage.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(ctx, `LOCK TABLE product_stats IN SHARE ROW EXCLUSIVE MODE;`)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	upsertQuery := `
+	INSERT INTO product_stats (product_id, rating_count, rating_sum, rating_average, units_sold, wishlist_count, view_count, updated_at)
+	SELECT product_id, rating_count, rating_sum, rating_average, units_sold, wishlist_count, view_count, NOW()
+	FROM product_stats_expected
+	ON CONFLICT (product_id) DO UPDATE SET
+	rating_count = EXCLUDED.rating_count,
+	rating_sum = EXCLUDED.rating_sum,
+	rating_average = EXCLUDED.rating_average,
+	units_sold = EXCLUDED.units_sold,
+	wishlist_count = EXCLUDED.wishlist_count,
+	view_count = EXCLUDED.view_count,
+	updated_at = EXCLUDED.updated_at;
+	`
+	res, err := tx.ExecContext(ctx, upsertQuery)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	return n, tx.Commit()
+}
+
+// CheckStats returns the products whose stats are missing or differ from the
+// ones computed from the tables. The averages are compared to 1e-6, the stored
+// one adds and subtracts the stars one at a time.
+func (store *ProductStore) CheckStats(ctx context.Context) ([]*entity.StatsDrift, error) {
+	selectQuery := `
+	SELECT
+	e.product_id, s.product_id IS NOT NULL,
+	COALESCE(s.rating_count, 0), s.rating_average, COALESCE(s.units_sold, 0), COALESCE(s.wishlist_count, 0), COALESCE(s.view_count, 0),
+	e.rating_count, e.rating_average, e.units_sold, e.wishlist_count, e.view_count
+	FROM product_stats_expected e
+	LEFT JOIN product_stats s ON s.product_id = e.product_id
+	WHERE s.product_id IS NULL
+	OR s.rating_count <> e.rating_count
+	OR s.units_sold <> e.units_sold
+	OR s.wishlist_count <> e.wishlist_count
+	OR s.view_count <> e.view_count
+	OR (s.rating_average IS NULL) <> (e.rating_average IS NULL)
+	OR ABS(s.rating_average - e.rating_average) > 1e-6
+	ORDER BY e.product_id;
+	`
+	rows, err := store.storage.DB.QueryContext(ctx, selectQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	drifts := []*entity.StatsDrift{}
+	for rows.Next() {
+		var drift entity.StatsDrift
+		var stored entity.ProductStats
+		var exists bool
+		err := rows.Scan(
+			&drift.ProductID,
+			&exists,
+			&stored.RatingCount,
+			&stored.RatingAverage,
+			&stored.UnitsSold,
+			&stored.WishlistCount,
+			&stored.ViewCount,
+			&drift.Expected.RatingCount,
+			&drift.Expected.RatingAverage,
+			&drift.Expected.UnitsSold,
+			&drift.Expected.WishlistCount,
+			&drift.Expected.ViewCount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			drift.Stored = &stored
+		}
+		drifts = append(drifts, &drift)
+	}
+	return drifts, rows.Err()
+}
@@ -0,0 +1,83 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+func (store *ProductStore) GetVariantStock(ctx context.Context, productId string, variantId *string, size float64) (*entity.VariantStock, error) {
+	selectQuery := `
+	SELECT i.id, i.quantity FROM inventories i
+	JOIN products p ON p.id = i.product_id AND listed(p)
+	WHERE i.product_id = $1 AND (i.id = $2::uuid OR ($2::uuid IS NULL AND i.size = $3))
+	LIMIT 1;
+	`
+	var stock entity.VariantStock
+	err := store.storage.DB.QueryRowContext(ctx, selectQuery, productId, variantId, size).Scan(&stock.InventoryID, &stock.Quantity)
+	if err != nil {
+		return nil, err
+	}
+	return &stock, nil
+}
+
+func (store *ProductStore) SubscribeToStock(ctx context.Context, userId, productId, inventoryId string) (bool, error) {
+	// a user notified before goes back to the end of the queue
+	insertQuery := `
+	INSERT INTO stock_subscriptions (id, user_id, product_id, inventory_id, created_at)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (inventory_id, user_id) DO UPDATE SET created_at = EXCLUDED.created_at, notified_at = NULL
+	WHERE stock_subscriptions.notified_at IS NOT NULL;
+	`
+	res, err := store.storage.DB.ExecContext(ctx, insertQuery, uuid.NewString(), userId, productId, inventoryId, time.Now())
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n != 0, nil
+}
+
+func (store *ProductStore) NotifyBackInStock(ctx context.Context, alert entity.BackInStockAlert) (int64, error) {
+	// the variants in stock of the listed products whose last batch is older than
+	// the interval notify their oldest subscribers, as many as the stock allows
+	insertQuery := `
+	WITH ready AS (
+		SELECT i.id, i.quantity FROM inventories i
+		JOIN products p ON p.id = i.product_id AND listed(p)
+		WHERE i.quantity > 0
+		AND EXISTS (SELECT 1 FROM stock_subscriptions s WHERE s.inventory_id = i.id AND s.notified_at IS NULL)
+		AND NOT EXISTS (SELECT 1 FROM stock_subscriptions s WHERE s.inventory_id = i.id AND s.notified_at > $1)
+	),
+	batch AS (
+		SELECT q.id FROM (
+			SELECT s.id, r.quantity, ROW_NUMBER() OVER (PARTITION BY s.inventory_id ORDER BY s.created_at, s.id) AS position
+			FROM stock_subscriptions s
+			JOIN ready r ON r.id = s.inventory_id
+			WHERE s.notified_at IS NULL
+		) q
+		WHERE q.position <= LEAST($2::bigint, q.quantity::bigint * $3::bigint)
+	),
+	notified AS (
+		UPDATE stock_subscriptions s SET notified_at = NOW()
+		FROM batch b
+		WHERE s.id = b.id AND s.notified_at IS NULL
+		RETURNING s.user_id, s.product_id
+	)
+	INSERT INTO notifications (id, user_id, type, title, body, product_id, created_at)
+	SELECT gen_random_uuid(), n.user_id, $4, $5, format($6, p.product_name), n.product_id, NOW()
+	FROM notified n
+	JOIN products p ON p.id = n.product_id;
+	`
+	res, err := store.storage.DB.ExecContext(ctx, insertQuery, time.Now().Add(-alert.BatchInterval), alert.BatchSize, alert.PerUnit,
+		utils.NotificationBackInStock, alert.Title, alert.Body)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
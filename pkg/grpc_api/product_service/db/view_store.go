@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// viewerCondition keeps the views of the user $1 and the guest views of the device $2.
+const viewerCondition = `(($1::uuid IS NOT NULL AND v.user_id = $1::uuid) OR (v.user_id IS NULL AND v.device_id = $2))`
+
+func (store *ProductStore) RecordProductView(ctx context.Context, productId string, viewer entity.Viewer, window time.Duration) error {
+	// a user is matched by its id only, a guest by its device
+	insertQuery := `
+	WITH last AS (
+		UPDATE product_views SET viewed_at = NOW()
+		WHERE id = (
+			SELECT v.id FROM product_views v
+			WHERE v.product_id = $3 AND v.viewed_at > $4
+			AND CASE WHEN $1::uuid IS NULL THEN v.user_id IS NULL AND v.device_id = $2 ELSE v.user_id = $1::uuid END
+			ORDER BY v.viewed_at DESC LIMIT 1
+		)
+		RETURNING id
+	)
+	INSERT INTO product_views (id, product_id, user_id, device_id, viewed_at)
+	SELECT $5, $3, $1::uuid, $2, NOW()
+	WHERE NOT EXISTS (SELECT 1 FROM last);
+	`
+	_, err := store.storage.DB.ExecContext(ctx, insertQuery, viewer.UserID, viewer.DeviceID, productId, time.Now().Add(-window), uuid.NewString())
+	return err
+}
+
+func (store *ProductStore) GetRecentlyViewed(ctx context.Context, viewer entity.Viewer, limit int) ([]*entity.RecentlyViewed, error) {
+	selectQuery := `
+	SELECT p.id, p.product_name, p.images, p.price, ` + statsColumns + `, w.id, r.viewed_at
+	FROM (
+		SELECT v.product_id, MAX(v.viewed_at) AS viewed_at FROM product_views v
+		WHERE ` + viewerCondition + ` GROUP BY v.product_id
+	) r
+	JOIN products p ON p.id = r.product_id AND listed(p)
+	LEFT JOIN product_stats ps ON p.id = ps.product_id
+	LEFT JOIN wishlists w ON p.id = w.product_id AND w.user_id = $1::uuid
+	ORDER BY r.viewed_at DESC, p.id
+	LIMIT $3;
+	`
+	rows, err := store.storage.DB.QueryContext(ctx, selectQuery, viewer.UserID, viewer.DeviceID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	viewed := []*entity.RecentlyViewed{}
+	for rows.Next() {
+		var product entity.GetProductRes
+		var images pq.StringArray
+		var view entity.RecentlyViewed
+		err := rows.Scan(
+			&product.ID,
+			&product.ProductName,
+			&images,
+			&product.Price,
+			&product.ReviewStar,
+			&product.ReviewCount,
+			&product.UnitsSold,
+			&product.WishlistCount,
+			&product.WishlistID,
+			&view.ViewedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		product.ProductImages = []string(images)
+		view.Product = &product
+		viewed = append(viewed, &view)
+	}
+	return viewed, rows.Err()
+}
+
+func (store *ProductStore) ClearRecentlyViewed(ctx context.Context, viewer entity.Viewer) (int64, error) {
+	// the views are kept for the view counts, without the viewer
+	updateQuery := `UPDATE product_views v SET user_id = NULL, device_id = NULL WHERE ` + viewerCondition + `;`
+	res, err := store.storage.DB.ExecContext(ctx, updateQuery, viewer.UserID, viewer.DeviceID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
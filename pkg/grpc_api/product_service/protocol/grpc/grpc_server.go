@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/akmal4410/gestapo/internal/config"
 	"github.com/akmal4410/gestapo/internal/database"
@@ -18,23 +19,25 @@ import (
 	"google.golang.org/grpc/reflection"
 )
 
+const (
+	// recommenderInterval is how often the related products are recomputed.
+	recommenderInterval = time.Hour
+	// priceTrackerInterval is how often the price drops are notified.
+	priceTrackerInterval = 15 * time.Minute
+	// stockAlertInterval is how often the variants back in stock notify their subscribers.
+	stockAlertInterval = time.Minute
+)
+
 func RunGRPCService(ctx context.Context, storage *database.Storage, config *config.Config, log logger.Logger) error {
 	tokenMaker, err := token.NewJWTMaker(config.TokenSymmetricKey)
 	if err != nil {
 		log.LogFatal("Error while Initializing NewJWTMaker %w", err)
 	}
 	service := service.NewProductService(storage, config, log, tokenMaker)
-	interceptor := interceptor.NewInterceptor(tokenMaker, log)
-
-	grpcServer := grpc.NewServer(
-		grpc.ChainUnaryInterceptor(
-			interceptor.AccessMiddleware(),
-		),
-	)
-
-	proto.RegisterProductServiceServer(grpcServer, service)
-	log.LogInfo("Registreing for reflection")
-	reflection.Register(grpcServer)
+	grpcServer := NewGRPCServer(service, tokenMaker, log)
+	go service.RunRecommender(ctx, recommenderInterval)
+	go service.RunPriceTracker(ctx, priceTrackerInterval)
+	go service.RunStockAlerts(ctx, stockAlertInterval)
 	port := ":" + config.ServerAddress.Product.Port
 	lis, err := net.Listen("tcp", port)
 	if err != nil {
@@ -54,3 +57,20 @@ func RunGRPCService(ctx context.Context, storage *database.Storage, config *conf
 	log.LogInfo("Start gRPC server at ", lis.Addr().String())
 	return grpcServer.Serve(lis)
 }
+
+// NewGRPCServer creates the gRPC server of the product service with its interceptors.
+func NewGRPCServer(service proto.ProductServiceServer, tokenMaker token.Maker, log logger.Logger) *grpc.Server {
+	interceptor := interceptor.NewInterceptor(tokenMaker, log)
+
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			interceptor.LoggingMiddleware(),
+			interceptor.AccessMiddleware(),
+		),
+	)
+
+	proto.RegisterProductServiceServer(grpcServer, service)
+	log.LogInfo("Registreing for reflection")
+	reflection.Register(grpcServer)
+	return grpcServer
+}
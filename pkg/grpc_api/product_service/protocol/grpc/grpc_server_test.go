@@ -0,0 +1,1136 @@
+package grpc_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akmal4410/gestapo/internal/config"
+	"github.com/akmal4410/gestapo/internal/database/memory"
+	"github.com/akmal4410/gestapo/internal/testenv"
+	"github.com/akmal4410/gestapo/pkg/api/proto"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/service"
+	"github.com/akmal4410/gestapo/pkg/service/images"
+	objectstore "github.com/akmal4410/gestapo/pkg/service/object_store"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func assertCode(t *testing.T, err error, code codes.Code) {
+	t.Helper()
+	if status.Code(err) != code {
+		t.Fatalf("expected %s, got %v", code, err)
+	}
+}
+
+func TestGetProducts(t *testing.T) {
+	env := testenv.New(t)
+	client := env.ProductClient(t)
+	merchant, _ := env.AddUser(t, "merchant", utils.MERCHANT)
+	other, _ := env.AddUser(t, "other", utils.MERCHANT)
+	user, _ := env.AddUser(t, "user", utils.USER)
+	category := env.AddCategory(t, "Shoes")
+	product := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5, 8, 9)
+	env.AddProduct(t, other.ID, category.ID, "walker", 80, 5, 8)
+
+	_, err := client.GetProducts(context.Background(), &proto.GetProductRequest{})
+	assertCode(t, err, codes.Unauthenticated)
+
+	ctx := env.WithServiceToken(t, context.Background(), user.ID, utils.USER, "product")
+	res, err := client.GetProducts(ctx, &proto.GetProductRequest{})
+	if err != nil {
+		t.Fatalf("GetProducts: %v", err)
+	}
+	if len(res.Data) != 2 {
+		t.Fatalf("expected every product, got %v", res.Data)
+	}
+
+	ctx = env.WithServiceToken(t, context.Background(), merchant.ID, utils.MERCHANT, "product")
+	res, err = client.GetProducts(ctx, &proto.GetProductRequest{MerchantId: &merchant.ID})
+	if err != nil {
+		t.Fatalf("GetProducts: %v", err)
+	}
+	if len(res.Data) != 1 || res.Data[0].Id != product.ID {
+		t.Fatalf("expected the products of the merchant, got %v", res.Data)
+	}
+	if !strings.HasPrefix(res.Data[0].ProductImages[0], "memory://") {
+		t.Fatalf("expected a presigned image, got %s", res.Data[0].ProductImages[0])
+	}
+}
+
+func TestGetProductsInCategoryTree(t *testing.T) {
+	env := testenv.New(t)
+	client := env.ProductClient(t)
+	merchant, _ := env.AddUser(t, "merchant", utils.MERCHANT)
+	user, _ := env.AddUser(t, "user", utils.USER)
+	men := env.AddCategory(t, "Men")
+	shoes := env.AddSubcategory(t, men, "Shoes")
+	women := env.AddCategory(t, "Women")
+	runner := env.AddProduct(t, merchant.ID, shoes.ID, "Trail Runner", 100, 5, 8)
+	env.AddProduct(t, merchant.ID, women.ID, "City Walker", 80, 5, 8)
+
+	ctx := env.WithServiceToken(t, context.Background(), user.ID, utils.USER, "product")
+	bad := "shoes"
+	_, err := client.GetProducts(ctx, &proto.GetProductRequest{CategoryId: &bad})
+	assertCode(t, err, codes.InvalidArgument)
+
+	res, err := client.GetProducts(ctx, &proto.GetProductRequest{CategoryId: &men.ID})
+	if err != nil {
+		t.Fatalf("GetProducts: %v", err)
+	}
+	if len(res.Data) != 1 || res.Data[0].Id != runner.ID {
+		t.Fatalf("expected the products of the subtree, got %v", res.Data)
+	}
+
+	search, err := client.SearchProducts(ctx, &proto.SearchProductsRequest{CategoryId: &men.ID})
+	if err != nil {
+		t.Fatalf("SearchProducts: %v", err)
+	}
+	if search.Data.Total != 1 || search.Data.Hits[0].Product.Id != runner.ID {
+		t.Fatalf("expected the search to match the subtree, got %v", search.Data.Hits)
+	}
+}
+
+func TestGetProductById(t *testing.T) {
+	env := testenv.New(t)
+	client := env.ProductClient(t)
+	merchant, merchantToken := env.AddUser(t, "merchant", utils.MERCHANT)
+	_, userToken := env.AddUser(t, "user", utils.USER)
+	category := env.AddCategory(t, "Shoes")
+	product := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5, 8, 9)
+
+	_, err := client.GetProductById(context.Background(), &proto.ProductIdRequest{ProductId: product.ID})
+	assertCode(t, err, codes.Unauthenticated)
+
+	for _, accessToken := range []string{merchantToken, userToken} {
+		res, err := client.GetProductById(testenv.WithToken(context.Background(), accessToken), &proto.ProductIdRequest{ProductId: product.ID})
+		if err != nil {
+			t.Fatalf("GetProductById: %v", err)
+		}
+		if res.Data.ProductName != "runner" || len(res.Data.Size) != 2 || res.Data.GetCategoryName() != "Shoes" {
+			t.Fatalf("unexpected product %v", res.Data)
+		}
+	}
+
+	_, err = client.GetProductById(testenv.WithToken(context.Background(), userToken), &proto.ProductIdRequest{ProductId: "missing"})
+	assertCode(t, err, codes.NotFound)
+}
+
+func TestGetProductVariants(t *testing.T) {
+	env := testenv.New(t)
+	client := env.ProductClient(t)
+	merchant, _ := env.AddUser(t, "merchant", utils.MERCHANT)
+	_, userToken := env.AddUser(t, "user", utils.USER)
+	category := env.AddCategory(t, "Shoes")
+	product := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5)
+	price := 120.0
+	env.AddVariant(t, product, "RUN-RED-9", map[string]string{"color": "red", "size": "9"}, &price, 0)
+	env.AddVariant(t, product, "RUN-RED-8", map[string]string{"color": "red", "size": "8"}, &price, 3)
+	env.AddVariant(t, product, "RUN-BLUE-8", map[string]string{"color": "blue", "size": "8"}, nil, 7)
+	discountID := uuid.NewString()
+	env.DB.Lock()
+	env.DB.Discounts[discountID] = &memory.Discount{ID: discountID, MerchantID: merchant.ID, Percent: 10, StartTime: time.Now(), EndTime: time.Now().Add(time.Hour)}
+	product.DiscountID = &discountID
+	env.DB.Unlock()
+
+	res, err := client.GetProductById(testenv.WithToken(context.Background(), userToken), &proto.ProductIdRequest{ProductId: product.ID})
+	if err != nil {
+		t.Fatalf("GetProductById: %v", err)
+	}
+	if len(res.Data.OptionTypes) != 2 || res.Data.OptionTypes[0] != "color" || res.Data.OptionTypes[1] != "size" {
+		t.Fatalf("unexpected option types %v", res.Data.OptionTypes)
+	}
+	var skus []string
+	for _, variant := range res.Data.Variants {
+		skus = append(skus, variant.GetSku())
+	}
+	if strings.Join(skus, ",") != "RUN-BLUE-8,RUN-RED-8,RUN-RED-9" {
+		t.Fatalf("expected the variants ordered by size and sku, got %v", skus)
+	}
+	blue, red := res.Data.Variants[0], res.Data.Variants[1]
+	if blue.Price != 100 || blue.GetDiscountPrice() != 90 || blue.Quantity != 7 || blue.Options["color"] != "blue" {
+		t.Fatalf("unexpected blue variant %v", blue)
+	}
+	if red.Price != 120 || red.GetDiscountPrice() != 108 || red.Options["size"] != "8" {
+		t.Fatalf("unexpected red variant %v", red)
+	}
+	if len(red.Images) != 1 || !strings.HasPrefix(red.Images[0], "memory://") {
+		t.Fatalf("expected a presigned variant image, got %v", red.Images)
+	}
+}
+
+func TestAddProductReview(t *testing.T) {
+	env := testenv.New(t)
+	client := env.ProductClient(t)
+	merchant, _ := env.AddUser(t, "merchant", utils.MERCHANT)
+	user, _ := env.AddUser(t, "user", utils.USER)
+	category := env.AddCategory(t, "Shoes")
+	product := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5, 8)
+	active := env.AddOrderItem(t, user.ID, product, utils.OrderActive)
+	completed := env.AddOrderItem(t, user.ID, product, utils.OrderCompleted)
+	ctx := env.WithServiceToken(t, context.Background(), user.ID, utils.USER, "product")
+
+	_, err := client.AddProductReview(ctx, &proto.AddReviewRequest{ProductId: product.ID, OrderItemId: active.ID, Start: 4, Review: "good"})
+	assertCode(t, err, codes.PermissionDenied)
+
+	req := &proto.AddReviewRequest{ProductId: product.ID, OrderItemId: completed.ID, Start: 4, Review: "good"}
+	if _, err = client.AddProductReview(ctx, req); err != nil {
+		t.Fatalf("AddProductReview: %v", err)
+	}
+	_, err = client.AddProductReview(ctx, req)
+	assertCode(t, err, codes.PermissionDenied)
+
+	res, err := client.GetProducts(ctx, &proto.GetProductRequest{})
+	if err != nil {
+		t.Fatalf("GetProducts: %v", err)
+	}
+	if res.Data[0].GetReviewStar() != 4 {
+		t.Fatalf("expected a review star of 4, got %v", res.Data[0].ReviewStar)
+	}
+}
+
+func TestProductStats(t *testing.T) {
+	env := testenv.New(t)
+	client := env.ProductClient(t)
+	userClient := env.UserClient(t)
+	merchant, _ := env.AddUser(t, "merchant", utils.MERCHANT)
+	user, userToken := env.AddUser(t, "user", utils.USER)
+	_, otherToken := env.AddUser(t, "other", utils.USER)
+	category := env.AddCategory(t, "Shoes")
+	product := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5, 8)
+	env.AddProduct(t, merchant.ID, category.ID, "walker", 80, 5, 8)
+	completed := env.AddOrderItem(t, user.ID, product, utils.OrderCompleted)
+	env.AddOrderItem(t, user.ID, product, utils.OrderActive)
+	env.AddOrderItem(t, user.ID, product, utils.OrderCancelled)
+
+	// a product wishlisted by another user is still listed, without a wishlist id
+	otherCtx := testenv.WithToken(context.Background(), otherToken)
+	if _, err := userClient.AddRemoveWishlist(otherCtx, &proto.AddRemoveWishlistRequest{Action: utils.ADD_WISHLIST, ProductId: product.ID}); err != nil {
+		t.Fatalf("AddRemoveWishlist: %v", err)
+	}
+	ctx := env.WithServiceToken(t, context.Background(), user.ID, utils.USER, "product")
+	_, err := client.AddProductReview(ctx, &proto.AddReviewRequest{ProductId: product.ID, OrderItemId: completed.ID, Start: 4, Review: "good"})
+	if err != nil {
+		t.Fatalf("AddProductReview: %v", err)
+	}
+
+	res, err := client.GetProducts(ctx, &proto.GetProductRequest{})
+	if err != nil {
+		t.Fatalf("GetProducts: %v", err)
+	}
+	if len(res.Data) != 2 {
+		t.Fatalf("expected every product, got %v", res.Data)
+	}
+	for _, listed := range res.Data {
+		if listed.Id != product.ID {
+			continue
+		}
+		if listed.WishlistId != nil || listed.WishlistCount != 1 {
+			t.Fatalf("expected the wishlist of the other user to be counted only, got %v", listed)
+		}
+		if listed.GetReviewStar() != 4 || listed.ReviewCount != 1 || listed.UnitsSold != 2 {
+			t.Fatalf("unexpected stats %v", listed)
+		}
+	}
+
+	detail, err := client.GetProductById(testenv.WithToken(context.Background(), userToken), &proto.ProductIdRequest{ProductId: product.ID})
+	if err != nil {
+		t.Fatalf("GetProductById: %v", err)
+	}
+	if detail.Data.ReviewCount != 1 || detail.Data.UnitsSold != 2 || detail.Data.WishlistCount != 1 {
+		t.Fatalf("unexpected stats %v", detail.Data)
+	}
+}
+
+func productIDs(products []*proto.ProductResponse) []string {
+	ids := make([]string, 0, len(products))
+	for _, product := range products {
+		ids = append(ids, product.Id)
+	}
+	return ids
+}
+
+func TestRecommendations(t *testing.T) {
+	env := testenv.New(t)
+	client := env.ProductClient(t)
+	userClient := env.UserClient(t)
+	merchant, _ := env.AddUser(t, "merchant", utils.MERCHANT)
+	buyer, _ := env.AddUser(t, "buyer", utils.USER)
+	_, shopperToken := env.AddUser(t, "shopper", utils.USER)
+	_, newcomerToken := env.AddUser(t, "newcomer", utils.USER)
+	shoes := env.AddCategory(t, "Shoes")
+	accessories := env.AddCategory(t, "Accessories")
+	runner := env.AddProduct(t, merchant.ID, shoes.ID, "runner", 100, 5, 8)
+	walker := env.AddProduct(t, merchant.ID, shoes.ID, "walker", 90, 5, 8)
+	trail := env.AddProduct(t, merchant.ID, shoes.ID, "trail", 200, 5, 8)
+	socks := env.AddProduct(t, merchant.ID, accessories.ID, "socks", 10, 5, 8)
+	hat := env.AddProduct(t, merchant.ID, accessories.ID, "hat", 20, 5, 8)
+
+	// the buyer bought the runner and the socks in one order
+	item := env.AddOrderItem(t, buyer.ID, runner, utils.OrderCompleted)
+	env.DB.Lock()
+	other := *item
+	other.ID = uuid.NewString()
+	other.ProductID = socks.ID
+	env.DB.OrderItems[other.ID] = &other
+	env.DB.Unlock()
+
+	shopperCtx := testenv.WithToken(context.Background(), shopperToken)
+	if _, err := userClient.AddRemoveWishlist(shopperCtx, &proto.AddRemoveWishlistRequest{Action: utils.ADD_WISHLIST, ProductId: runner.ID}); err != nil {
+		t.Fatalf("AddRemoveWishlist: %v", err)
+	}
+
+	// before the recommender runs, the products of the category closest in price are related
+	res, err := client.GetRelatedProducts(shopperCtx, &proto.GetRelatedProductsRequest{ProductId: runner.ID})
+	if err != nil {
+		t.Fatalf("GetRelatedProducts: %v", err)
+	}
+	if ids := productIDs(res.Data); !slices.Equal(ids, []string{walker.ID, trail.ID}) {
+		t.Fatalf("expected the products of the category, got %v", res.Data)
+	}
+
+	n, err := service.ComputeRecommendations(context.Background(), db.NewMemoryProductStore(env.DB), env.Log)
+	if err != nil || n != 2 {
+		t.Fatalf("ComputeRecommendations: %d %v", n, err)
+	}
+
+	res, err = client.GetRelatedProducts(shopperCtx, &proto.GetRelatedProductsRequest{ProductId: runner.ID})
+	if err != nil {
+		t.Fatalf("GetRelatedProducts: %v", err)
+	}
+	if ids := productIDs(res.Data); !slices.Equal(ids, []string{socks.ID, walker.ID, trail.ID}) {
+		t.Fatalf("expected the products bought together first, got %v", res.Data)
+	}
+	res, err = client.GetRelatedProducts(shopperCtx, &proto.GetRelatedProductsRequest{ProductId: runner.ID, Limit: 1})
+	if err != nil || len(res.Data) != 1 {
+		t.Fatalf("GetRelatedProducts: %v %v", res, err)
+	}
+
+	// the wishlisted runner picks the socks, then the shoes, and is not recommended again
+	res, err = client.GetRecommendedForUser(shopperCtx, &proto.GetRecommendedForUserRequest{})
+	if err != nil {
+		t.Fatalf("GetRecommendedForUser: %v", err)
+	}
+	ids := productIDs(res.Data)
+	if len(ids) != 4 || ids[0] != socks.ID || slices.Contains(ids, runner.ID) || ids[3] != hat.ID {
+		t.Fatalf("unexpected recommendations %v", res.Data)
+	}
+
+	// without history, the best sellers come first
+	res, err = client.GetRecommendedForUser(testenv.WithToken(context.Background(), newcomerToken), &proto.GetRecommendedForUserRequest{})
+	if err != nil {
+		t.Fatalf("GetRecommendedForUser: %v", err)
+	}
+	if len(res.Data) != 5 || res.Data[0].Id != runner.ID || res.Data[0].UnitsSold != 1 {
+		t.Fatalf("expected the best sellers, got %v", res.Data)
+	}
+
+	_, err = client.GetRelatedProducts(shopperCtx, &proto.GetRelatedProductsRequest{ProductId: uuid.NewString()})
+	assertCode(t, err, codes.NotFound)
+	_, err = client.GetRelatedProducts(shopperCtx, &proto.GetRelatedProductsRequest{ProductId: "bad"})
+	assertCode(t, err, codes.InvalidArgument)
+	_, err = client.GetRecommendedForUser(shopperCtx, &proto.GetRecommendedForUserRequest{Limit: 51})
+	assertCode(t, err, codes.InvalidArgument)
+}
+
+func TestPriceHistoryAndDropAlerts(t *testing.T) {
+	env := testenv.New(t)
+	client := env.ProductClient(t)
+	merchant, merchantToken := env.AddUser(t, "merchant", utils.MERCHANT)
+	_, shopperToken := env.AddUser(t, "shopper", utils.USER)
+	category := env.AddCategory(t, "Shoes")
+	runner := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5, 8)
+	merchantCtx := testenv.WithToken(context.Background(), merchantToken)
+	shopperCtx := testenv.WithToken(context.Background(), shopperToken)
+	store := db.NewMemoryProductStore(env.DB)
+	alert := entity.PriceDropAlert{Threshold: 10, Title: "Price drop", Body: "%s is now %s, down from %s"}
+
+	if _, err := env.UserClient(t).AddRemoveWishlist(shopperCtx, &proto.AddRemoveWishlistRequest{Action: utils.ADD_WISHLIST, ProductId: runner.ID}); err != nil {
+		t.Fatalf("AddRemoveWishlist: %v", err)
+	}
+	// the first price of a product does not notify
+	if n, err := service.TrackPrices(context.Background(), store, alert, env.Log); err != nil || n != 0 {
+		t.Fatalf("TrackPrices: %d %v", n, err)
+	}
+
+	_, err := env.MerchantClient(t).AddProductDiscount(merchantCtx, &proto.AddDiscountRequest{
+		ProductId:   runner.ID,
+		Name:        "Summer sale",
+		Description: "Summer sale",
+		Percentage:  20,
+		StartTime:   timestamppb.Now(),
+		EndTime:     timestamppb.New(time.Now().Add(24 * time.Hour)),
+	})
+	if err != nil {
+		t.Fatalf("AddProductDiscount: %v", err)
+	}
+	if n, err := service.TrackPrices(context.Background(), store, alert, env.Log); err != nil || n != 1 {
+		t.Fatalf("expected the shopper notified of the drop, got %d %v", n, err)
+	}
+	if n, err := service.TrackPrices(context.Background(), store, alert, env.Log); err != nil || n != 0 {
+		t.Fatalf("expected a drop notified once, got %d %v", n, err)
+	}
+
+	// a drop under the threshold does not notify
+	env.DB.Lock()
+	discountID := *env.DB.Products[runner.ID].DiscountID
+	env.DB.Unlock()
+	_, err = env.MerchantClient(t).EditProductDiscount(merchantCtx, &proto.EditDiscountRequest{
+		DiscountId: discountID,
+		Name:       "Summer sale",
+		Percentage: 25,
+		StartTime:  timestamppb.Now(),
+		EndTime:    timestamppb.New(time.Now().Add(24 * time.Hour)),
+	})
+	if err != nil {
+		t.Fatalf("EditProductDiscount: %v", err)
+	}
+	if n, err := service.TrackPrices(context.Background(), store, alert, env.Log); err != nil || n != 0 {
+		t.Fatalf("TrackPrices: %d %v", n, err)
+	}
+
+	// the end of the discount is recorded by the tracker, the first price is older than the period
+	env.DB.Lock()
+	env.DB.Discounts[discountID].EndTime = time.Now().Add(-time.Minute)
+	env.DB.LastPrice(runner.ID, &env.DB.Discounts[discountID].CreatedAt).ChangedAt = time.Now().AddDate(0, 0, -100)
+	env.DB.Unlock()
+	if n, err := service.TrackPrices(context.Background(), store, alert, env.Log); err != nil || n != 0 {
+		t.Fatalf("TrackPrices: %d %v", n, err)
+	}
+
+	res, err := client.GetPriceHistory(shopperCtx, &proto.GetPriceHistoryRequest{ProductId: runner.ID, Days: 30})
+	if err != nil {
+		t.Fatalf("GetPriceHistory: %v", err)
+	}
+	var prices []float64
+	for _, point := range res.Data {
+		prices = append(prices, point.EffectivePrice)
+	}
+	if !slices.Equal(prices, []float64{100, 80, 75, 100}) || res.Data[1].GetDiscountPercent() != 20 || res.Data[3].DiscountPercent != nil {
+		t.Fatalf("unexpected price history %v", res.Data)
+	}
+
+	notifications, err := env.UserClient(t).GetNotifications(shopperCtx, &proto.GetNotificationsRequest{})
+	if err != nil {
+		t.Fatalf("GetNotifications: %v", err)
+	}
+	if notifications.UnreadCount != 1 || len(notifications.Data) != 1 || notifications.Data[0].Type != utils.NotificationPriceDrop ||
+		notifications.Data[0].Body != "runner is now 80.00, down from 100.00" || notifications.Data[0].GetProductId() != runner.ID {
+		t.Fatalf("unexpected notifications %v", notifications)
+	}
+
+	_, err = client.GetPriceHistory(shopperCtx, &proto.GetPriceHistoryRequest{ProductId: uuid.NewString()})
+	assertCode(t, err, codes.NotFound)
+	_, err = client.GetPriceHistory(shopperCtx, &proto.GetPriceHistoryRequest{ProductId: runner.ID, Days: 366})
+	assertCode(t, err, codes.InvalidArgument)
+}
+
+func TestBackInStockAlerts(t *testing.T) {
+	env := testenv.New(t)
+	client := env.ProductClient(t)
+	merchant, merchantToken := env.AddUser(t, "merchant", utils.MERCHANT)
+	category := env.AddCategory(t, "Shoes")
+	runner := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 0, 8)
+	inStock := env.AddVariant(t, runner, "RUN-9", map[string]string{"size": "9"}, nil, 5)
+	store := db.NewMemoryProductStore(env.DB)
+	alert := entity.BackInStockAlert{BatchSize: 10, PerUnit: 2, BatchInterval: time.Hour, Title: "Back in stock", Body: "%s is back in stock"}
+
+	var subscribers []context.Context
+	for i := 0; i < 5; i++ {
+		_, userToken := env.AddUser(t, "shopper"+strconv.Itoa(i), utils.USER)
+		ctx := testenv.WithToken(context.Background(), userToken)
+		if _, err := client.NotifyWhenAvailable(ctx, &proto.NotifyWhenAvailableRequest{ProductId: runner.ID, Size: 8}); err != nil {
+			t.Fatalf("NotifyWhenAvailable: %v", err)
+		}
+		subscribers = append(subscribers, ctx)
+	}
+	_, err := client.NotifyWhenAvailable(subscribers[0], &proto.NotifyWhenAvailableRequest{ProductId: runner.ID, Size: 8})
+	assertCode(t, err, codes.AlreadyExists)
+	_, err = client.NotifyWhenAvailable(subscribers[0], &proto.NotifyWhenAvailableRequest{ProductId: runner.ID, VariantId: &inStock.ID})
+	assertCode(t, err, codes.FailedPrecondition)
+	_, err = client.NotifyWhenAvailable(subscribers[0], &proto.NotifyWhenAvailableRequest{ProductId: runner.ID, Size: 10})
+	assertCode(t, err, codes.NotFound)
+
+	if n, err := service.SendStockAlerts(context.Background(), store, alert, env.Log); err != nil || n != 0 {
+		t.Fatalf("expected no notifications while sold out, got %d %v", n, err)
+	}
+
+	var soldOut string
+	env.DB.Lock()
+	for _, inventory := range env.DB.Variants(runner.ID) {
+		if inventory.Size == 8 {
+			soldOut = inventory.ID
+		}
+	}
+	env.DB.Unlock()
+	_, err = env.MerchantClient(t).Restock(testenv.WithToken(context.Background(), merchantToken), &proto.RestockRequest{VariantId: soldOut, Quantity: 1})
+	if err != nil {
+		t.Fatalf("Restock: %v", err)
+	}
+
+	// one unit notifies the two oldest subscribers, the next batch waits for the interval
+	if n, err := service.SendStockAlerts(context.Background(), store, alert, env.Log); err != nil || n != 2 {
+		t.Fatalf("expected a batch of 2, got %d %v", n, err)
+	}
+	if n, err := service.SendStockAlerts(context.Background(), store, alert, env.Log); err != nil || n != 0 {
+		t.Fatalf("expected the next batch to wait, got %d %v", n, err)
+	}
+	unread := func(ctx context.Context) int64 {
+		res, err := env.UserClient(t).GetNotifications(ctx, &proto.GetNotificationsRequest{})
+		if err != nil {
+			t.Fatalf("GetNotifications: %v", err)
+		}
+		for _, notification := range res.Data {
+			if notification.Type != utils.NotificationBackInStock || notification.Body != "runner is back in stock" {
+				t.Fatalf("unexpected notification %v", notification)
+			}
+		}
+		return res.UnreadCount
+	}
+	if unread(subscribers[0]) != 1 || unread(subscribers[1]) != 1 || unread(subscribers[2]) != 0 {
+		t.Fatalf("expected the oldest subscribers notified first")
+	}
+
+	// the unit is sold out again and the first subscriber goes back to the end of the queue
+	env.DB.Lock()
+	env.DB.Inventories[soldOut].Quantity = 0
+	env.DB.Unlock()
+	if _, err := client.NotifyWhenAvailable(subscribers[0], &proto.NotifyWhenAvailableRequest{ProductId: runner.ID, Size: 8}); err != nil {
+		t.Fatalf("NotifyWhenAvailable: %v", err)
+	}
+	_, err = env.MerchantClient(t).Restock(testenv.WithToken(context.Background(), merchantToken), &proto.RestockRequest{VariantId: soldOut, Quantity: 1})
+	if err != nil {
+		t.Fatalf("Restock: %v", err)
+	}
+	env.DB.Lock()
+	for _, subscription := range env.DB.Subscriptions {
+		if subscription.NotifiedAt != nil {
+			old := subscription.NotifiedAt.Add(-2 * time.Hour)
+			subscription.NotifiedAt = &old
+		}
+	}
+	env.DB.Unlock()
+	if n, err := service.SendStockAlerts(context.Background(), store, alert, env.Log); err != nil || n != 2 {
+		t.Fatalf("expected the next batch, got %d %v", n, err)
+	}
+	if unread(subscribers[2]) != 1 || unread(subscribers[3]) != 1 || unread(subscribers[4]) != 0 || unread(subscribers[0]) != 1 {
+		t.Fatalf("expected the next subscribers in the queue notified")
+	}
+}
+
+func TestRecentlyViewed(t *testing.T) {
+	env := testenv.New(t)
+	client := env.ProductClient(t)
+	merchant, merchantToken := env.AddUser(t, "merchant", utils.MERCHANT)
+	_, userToken := env.AddUser(t, "user", utils.USER)
+	category := env.AddCategory(t, "Shoes")
+	runner := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5, 8)
+	walker := env.AddProduct(t, merchant.ID, category.ID, "walker", 90, 5, 8)
+	trail := env.AddProduct(t, merchant.ID, category.ID, "trail", 200, 5, 8)
+	userCtx := testenv.WithToken(context.Background(), userToken)
+	guestCtx := metadata.AppendToOutgoingContext(context.Background(), utils.DeviceIDKey, "device-1")
+
+	view := func(ctx context.Context, product *memory.Product) {
+		t.Helper()
+		if _, err := client.GetProductById(ctx, &proto.ProductIdRequest{ProductId: product.ID}); err != nil {
+			t.Fatalf("GetProductById: %v", err)
+		}
+	}
+	recentlyViewed := func(ctx context.Context) []string {
+		t.Helper()
+		res, err := client.GetRecentlyViewed(ctx, &proto.GetRecentlyViewedRequest{})
+		if err != nil {
+			t.Fatalf("GetRecentlyViewed: %v", err)
+		}
+		ids := make([]string, 0, len(res.Data))
+		for _, viewed := range res.Data {
+			if viewed.ViewedAt == nil {
+				t.Fatalf("expected the time of the view, got %v", viewed)
+			}
+			ids = append(ids, viewed.Product.Id)
+		}
+		return ids
+	}
+
+	// viewing the runner again moves it first without counting it twice
+	view(userCtx, runner)
+	view(userCtx, walker)
+	view(userCtx, runner)
+	view(testenv.WithToken(context.Background(), merchantToken), trail)
+	if ids := recentlyViewed(userCtx); !slices.Equal(ids, []string{runner.ID, walker.ID}) {
+		t.Fatalf("expected the runner then the walker, got %v", ids)
+	}
+	res, err := client.GetRecentlyViewed(userCtx, &proto.GetRecentlyViewedRequest{Limit: 1})
+	if err != nil || len(res.Data) != 1 {
+		t.Fatalf("GetRecentlyViewed: %v %v", res, err)
+	}
+	_, err = client.GetRecentlyViewed(userCtx, &proto.GetRecentlyViewedRequest{Limit: 51})
+	assertCode(t, err, codes.InvalidArgument)
+
+	// a guest browses with its device, and gets its history once signed in on it
+	detail, err := client.GetProductById(guestCtx, &proto.ProductIdRequest{ProductId: trail.ID})
+	if err != nil || detail.Data.WishlistId != nil {
+		t.Fatalf("GetProductById: %v %v", detail, err)
+	}
+	if ids := recentlyViewed(guestCtx); !slices.Equal(ids, []string{trail.ID}) {
+		t.Fatalf("expected the trail, got %v", ids)
+	}
+	_, err = client.GetProductById(context.Background(), &proto.ProductIdRequest{ProductId: trail.ID})
+	assertCode(t, err, codes.Unauthenticated)
+	signedInCtx := metadata.AppendToOutgoingContext(userCtx, utils.DeviceIDKey, "device-1")
+	if ids := recentlyViewed(signedInCtx); !slices.Equal(ids, []string{trail.ID, runner.ID, walker.ID}) {
+		t.Fatalf("expected the views of the user and of the device, got %v", ids)
+	}
+
+	env.DB.Lock()
+	runnerViews, trailViews := env.DB.Stats(runner.ID).ViewCount, env.DB.Stats(trail.ID).ViewCount
+	env.DB.Unlock()
+	if runnerViews != 1 || trailViews != 1 {
+		t.Fatalf("expected one view of the runner and of the trail, got %d %d", runnerViews, trailViews)
+	}
+
+	// clearing the history keeps the views counted
+	if _, err := client.ClearRecentlyViewed(signedInCtx, &proto.Request{}); err != nil {
+		t.Fatalf("ClearRecentlyViewed: %v", err)
+	}
+	if ids := recentlyViewed(signedInCtx); len(ids) != 0 {
+		t.Fatalf("expected an empty history, got %v", ids)
+	}
+	if ids := recentlyViewed(guestCtx); len(ids) != 0 {
+		t.Fatalf("expected an empty history for the device, got %v", ids)
+	}
+	env.DB.Lock()
+	runnerViews = env.DB.Stats(runner.ID).ViewCount
+	env.DB.Unlock()
+	if runnerViews != 1 {
+		t.Fatalf("expected the view to be kept, got %d", runnerViews)
+	}
+}
+
+var pngImage = func() string {
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 4, 4)))
+	return buf.String()
+}()
+
+func TestProductReviews(t *testing.T) {
+	env := testenv.New(t)
+	client := env.ProductClient(t)
+	merchant, merchantToken := env.AddUser(t, "merchant", utils.MERCHANT)
+	_, otherMerchantToken := env.AddUser(t, "other", utils.MERCHANT)
+	author, authorToken := env.AddUser(t, "author", utils.USER)
+	reader, readerToken := env.AddUser(t, "reader", utils.USER)
+	category := env.AddCategory(t, "Shoes")
+	product := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5, 8)
+	fullName := "Ada Author"
+	env.DB.Lock()
+	author.FullName = &fullName
+	env.DB.Unlock()
+	authorCtx := testenv.WithToken(context.Background(), authorToken)
+	readerCtx := testenv.WithToken(context.Background(), readerToken)
+	merchantCtx := testenv.WithToken(context.Background(), merchantToken)
+
+	upload := func(ctx context.Context, content string) string {
+		t.Helper()
+		res, err := client.CreateReviewPhotoUpload(ctx, &proto.CreateReviewPhotoUploadRequest{
+			ProductId: product.ID, ContentType: "image/png", Size: int64(len(content)),
+		})
+		if err != nil {
+			t.Fatalf("CreateReviewPhotoUpload: %v", err)
+		}
+		if err := env.Files.Put(ctx, res.Data.Key, strings.NewReader(content), "image/png"); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+		return res.Data.Id
+	}
+	_, err := client.CreateReviewPhotoUpload(merchantCtx, &proto.CreateReviewPhotoUploadRequest{ProductId: product.ID, ContentType: "image/png", Size: 10})
+	assertCode(t, err, codes.PermissionDenied)
+	_, err = client.CreateReviewPhotoUpload(authorCtx, &proto.CreateReviewPhotoUploadRequest{ProductId: product.ID, ContentType: "image/gif", Size: 10})
+	assertCode(t, err, codes.InvalidArgument)
+
+	addReview := func(user *memory.User, star float32, photoSessionIds ...string) error {
+		item := env.AddOrderItem(t, user.ID, product, utils.OrderCompleted)
+		ctx := env.WithServiceToken(t, context.Background(), user.ID, utils.USER, "product")
+		_, err := client.AddProductReview(ctx, &proto.AddReviewRequest{
+			ProductId: product.ID, OrderItemId: item.ID, Start: star, Review: "review", PhotoSessionIds: photoSessionIds,
+		})
+		return err
+	}
+	assertCode(t, addReview(author, 6), codes.InvalidArgument)
+	assertCode(t, addReview(author, 5, upload(readerCtx, pngImage)), codes.PermissionDenied)
+	assertCode(t, addReview(author, 5, upload(authorCtx, "not an image")), codes.InvalidArgument)
+	if err := addReview(author, 5, upload(authorCtx, pngImage)); err != nil {
+		t.Fatalf("AddProductReview: %v", err)
+	}
+	if err := addReview(reader, 2); err != nil {
+		t.Fatalf("AddProductReview: %v", err)
+	}
+
+	list := func(ctx context.Context, req *proto.GetProductReviewsRequest) *proto.ProductReviewsData {
+		t.Helper()
+		req.ProductId = product.ID
+		res, err := client.GetProductReviews(ctx, req)
+		if err != nil {
+			t.Fatalf("GetProductReviews: %v", err)
+		}
+		return res.Data
+	}
+	data := list(readerCtx, &proto.GetProductReviewsRequest{Sort: "highest"})
+	if data.Total != 2 || len(data.Reviews) != 2 || data.Reviews[0].Star != 5 {
+		t.Fatalf("unexpected reviews %v", data.Reviews)
+	}
+	review := data.Reviews[0]
+	if review.AuthorName != fullName || len(review.Photos) != 1 || !strings.HasPrefix(review.Photos[0], "memory://") {
+		t.Fatalf("unexpected review %v", review)
+	}
+	if data = list(readerCtx, &proto.GetProductReviewsRequest{WithPhotos: true}); data.Total != 1 || data.Reviews[0].Id != review.Id {
+		t.Fatalf("expected the review with photos, got %v", data.Reviews)
+	}
+	star := int32(2)
+	if data = list(readerCtx, &proto.GetProductReviewsRequest{Star: &star}); data.Total != 1 || data.Reviews[0].Star != 2 {
+		t.Fatalf("expected the review of 2 stars, got %v", data.Reviews)
+	}
+	if data = list(readerCtx, &proto.GetProductReviewsRequest{Sort: "lowest", Page: 2, PageSize: 1}); data.Total != 2 || len(data.Reviews) != 1 || data.Reviews[0].Star != 5 {
+		t.Fatalf("unexpected second page %v", data.Reviews)
+	}
+	_, err = client.GetProductReviews(readerCtx, &proto.GetProductReviewsRequest{ProductId: product.ID, Sort: "random"})
+	assertCode(t, err, codes.InvalidArgument)
+
+	_, err = client.VoteReview(authorCtx, &proto.VoteReviewRequest{ReviewId: review.Id, Vote: "HELPFUL"})
+	assertCode(t, err, codes.FailedPrecondition)
+	_, err = client.VoteReview(readerCtx, &proto.VoteReviewRequest{ReviewId: review.Id, Vote: "LIKE"})
+	assertCode(t, err, codes.InvalidArgument)
+	for _, vote := range []string{"NOT_HELPFUL", "HELPFUL"} {
+		if _, err = client.VoteReview(readerCtx, &proto.VoteReviewRequest{ReviewId: review.Id, Vote: vote}); err != nil {
+			t.Fatalf("VoteReview: %v", err)
+		}
+	}
+	data = list(readerCtx, &proto.GetProductReviewsRequest{Sort: "helpful"})
+	if voted := data.Reviews[0]; voted.Id != review.Id || voted.HelpfulCount != 1 || voted.NotHelpfulCount != 0 || voted.GetMyVote() != "HELPFUL" {
+		t.Fatalf("unexpected votes %v", voted)
+	}
+
+	_, err = client.ReplyToReview(testenv.WithToken(context.Background(), otherMerchantToken), &proto.ReplyReviewRequest{ReviewId: review.Id, Reply: "thanks"})
+	assertCode(t, err, codes.PermissionDenied)
+	_, err = client.ReplyToReview(authorCtx, &proto.ReplyReviewRequest{ReviewId: review.Id, Reply: "thanks"})
+	assertCode(t, err, codes.PermissionDenied)
+	for _, reply := range []string{"thanks", "thank you"} {
+		if _, err = client.ReplyToReview(merchantCtx, &proto.ReplyReviewRequest{ReviewId: review.Id, Reply: reply}); err != nil {
+			t.Fatalf("ReplyToReview: %v", err)
+		}
+	}
+
+	_, err = client.EditProductReview(readerCtx, &proto.EditReviewRequest{ReviewId: review.Id, Review: &fullName})
+	assertCode(t, err, codes.PermissionDenied)
+	env.DB.Lock()
+	photoKey := env.DB.Reviews[review.Id].Images[0]
+	env.DB.Unlock()
+	text := "even better"
+	_, err = client.EditProductReview(authorCtx, &proto.EditReviewRequest{
+		ReviewId: review.Id, Review: &text, RemovedPhotos: []string{photoKey}, PhotoSessionIds: []string{upload(authorCtx, pngImage)},
+	})
+	if err != nil {
+		t.Fatalf("EditProductReview: %v", err)
+	}
+	if _, err := env.Files.Stat(context.Background(), images.Key(photoKey, images.Thumbnail, images.WebP)); !errors.Is(err, objectstore.ErrNotFound) {
+		t.Fatalf("expected the removed photo to be deleted, got %v", err)
+	}
+	data = list(authorCtx, &proto.GetProductReviewsRequest{Sort: "highest"})
+	edited := data.Reviews[0]
+	if edited.Review != text || !edited.Edited || len(edited.Photos) != 1 || edited.GetMyVote() != "" || edited.HelpfulCount != 1 {
+		t.Fatalf("unexpected edited review %v", edited)
+	}
+	if edited.Reply.GetReply() != "thank you" || !edited.Reply.UpdatedAt.AsTime().After(edited.Reply.CreatedAt.AsTime()) {
+		t.Fatalf("unexpected reply %v", edited.Reply)
+	}
+
+	productRes, err := client.GetProductById(readerCtx, &proto.ProductIdRequest{ProductId: product.ID})
+	if err != nil {
+		t.Fatalf("GetProductById: %v", err)
+	}
+	distribution := productRes.Data.RatingDistribution
+	if productRes.Data.ReviewCount != 2 || len(distribution) != 5 || distribution[5] != 1 || distribution[2] != 1 || distribution[1] != 0 {
+		t.Fatalf("unexpected rating distribution %d %v", productRes.Data.ReviewCount, distribution)
+	}
+
+	_, err = client.DeleteProductReview(readerCtx, &proto.ReviewIdRequest{ReviewId: review.Id})
+	assertCode(t, err, codes.PermissionDenied)
+	if _, err = client.DeleteProductReview(authorCtx, &proto.ReviewIdRequest{ReviewId: review.Id}); err != nil {
+		t.Fatalf("DeleteProductReview: %v", err)
+	}
+	if data = list(readerCtx, &proto.GetProductReviewsRequest{}); data.Total != 1 {
+		t.Fatalf("expected the review to be deleted, got %v", data.Reviews)
+	}
+	env.DB.Lock()
+	defer env.DB.Unlock()
+	if len(env.DB.ReviewVotes) != 0 {
+		t.Fatalf("expected the votes to be deleted with the review")
+	}
+}
+
+func facetCount(values []*proto.FacetValue, value string) int64 {
+	for _, facet := range values {
+		if facet.Value == value {
+			return facet.Count
+		}
+	}
+	return 0
+}
+
+func TestReviewModeration(t *testing.T) {
+	env := testenv.New(t)
+	env.Config.Moderation = &config.Moderation{BannedWords: []string{"scam", "rip off"}, ReportThreshold: 2}
+	client := env.ProductClient(t)
+	merchant, _ := env.AddUser(t, "merchant", utils.MERCHANT)
+	category := env.AddCategory(t, "Shoes")
+	product := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5, 8)
+
+	users := map[string]string{}
+	addReview := func(name string, star float32, text string) string {
+		t.Helper()
+		user, accessToken := env.AddUser(t, name, utils.USER)
+		users[name] = accessToken
+		item := env.AddOrderItem(t, user.ID, product, utils.OrderCompleted)
+		ctx := env.WithServiceToken(t, context.Background(), user.ID, utils.USER, "product")
+		res, err := client.AddProductReview(ctx, &proto.AddReviewRequest{
+			ProductId: product.ID, OrderItemId: item.ID, Start: star, Review: text,
+		})
+		if err != nil {
+			t.Fatalf("AddProductReview: %v", err)
+		}
+		return res.Message
+	}
+	const held = "Product Review submitted for moderation"
+	if message := addReview("honest", 4, "Great shoes, very comfortable for running"); message == held {
+		t.Fatalf("expected the review to be published")
+	}
+	for name, text := range map[string]string{
+		"banned":    "What a SCAM!",
+		"phrase":    "total rip  off",
+		"spammer":   "cheap copies at www.example.com",
+		"duplicate": "great shoes,  very comfortable for RUNNING",
+	} {
+		if message := addReview(name, 1, text); message != held {
+			t.Fatalf("expected the review of %s to be held, got %q", name, message)
+		}
+	}
+	if message := addReview("scampi", 2, "scampi lover, meh shoes"); message == held {
+		t.Fatalf("expected banned words to match whole words")
+	}
+
+	readerCtx := testenv.WithToken(context.Background(), users["honest"])
+	list := func() *proto.ProductReviewsData {
+		t.Helper()
+		res, err := client.GetProductReviews(readerCtx, &proto.GetProductReviewsRequest{ProductId: product.ID})
+		if err != nil {
+			t.Fatalf("GetProductReviews: %v", err)
+		}
+		return res.Data
+	}
+	data := list()
+	if data.Total != 2 {
+		t.Fatalf("expected the 2 published reviews, got %v", data.Reviews)
+	}
+	productRes, err := client.GetProductById(readerCtx, &proto.ProductIdRequest{ProductId: product.ID})
+	if err != nil {
+		t.Fatalf("GetProductById: %v", err)
+	}
+	if productRes.Data.ReviewCount != 2 || productRes.Data.RatingDistribution[1] != 0 {
+		t.Fatalf("expected the held reviews out of the rating, got %v", productRes.Data)
+	}
+	var review *proto.ReviewResponse
+	for _, published := range data.Reviews {
+		if published.Star == 4 {
+			review = published
+		}
+	}
+
+	report := func(name, reason string) error {
+		ctx := testenv.WithToken(context.Background(), users[name])
+		_, err := client.ReportReview(ctx, &proto.ReportReviewRequest{ReviewId: review.Id, Reason: reason})
+		return err
+	}
+	assertCode(t, report("banned", "RUDE"), codes.InvalidArgument)
+	assertCode(t, report("honest", "SPAM"), codes.FailedPrecondition)
+	if err := report("banned", "SPAM"); err != nil {
+		t.Fatalf("ReportReview: %v", err)
+	}
+	assertCode(t, report("banned", "FAKE"), codes.AlreadyExists)
+	if data = list(); data.Total != 2 {
+		t.Fatalf("expected the review to stay published under the threshold, got %v", data.Reviews)
+	}
+	if err := report("spammer", "FAKE"); err != nil {
+		t.Fatalf("ReportReview: %v", err)
+	}
+	if data = list(); data.Total != 1 {
+		t.Fatalf("expected the reported review to be held, got %v", data.Reviews)
+	}
+	assertCode(t, report("phrase", "SPAM"), codes.NotFound)
+	_, err = client.VoteReview(testenv.WithToken(context.Background(), users["phrase"]), &proto.VoteReviewRequest{ReviewId: review.Id, Vote: "HELPFUL"})
+	assertCode(t, err, codes.NotFound)
+}
+
+func TestProductQuestions(t *testing.T) {
+	env := testenv.New(t)
+	env.Config.Moderation = &config.Moderation{BannedWords: []string{"scam"}}
+	client := env.ProductClient(t)
+	merchant, merchantToken := env.AddUser(t, "merchant", utils.MERCHANT)
+	_, otherMerchantToken := env.AddUser(t, "other", utils.MERCHANT)
+	_, askerToken := env.AddUser(t, "asker", utils.USER)
+	buyer, buyerToken := env.AddUser(t, "buyer", utils.USER)
+	_, strangerToken := env.AddUser(t, "stranger", utils.USER)
+	category := env.AddCategory(t, "Shoes")
+	product := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5, 8)
+	env.AddOrderItem(t, buyer.ID, product, utils.OrderCompleted)
+	merchantCtx := testenv.WithToken(context.Background(), merchantToken)
+	askerCtx := testenv.WithToken(context.Background(), askerToken)
+	buyerCtx := testenv.WithToken(context.Background(), buyerToken)
+	strangerCtx := testenv.WithToken(context.Background(), strangerToken)
+
+	ask := func(ctx context.Context, question string) (string, error) {
+		res, err := client.AskQuestion(ctx, &proto.AskQuestionRequest{ProductId: product.ID, Question: question})
+		return res.GetMessage(), err
+	}
+	_, err := ask(merchantCtx, "does it fit?")
+	assertCode(t, err, codes.PermissionDenied)
+	_, err = ask(askerCtx, "  ")
+	assertCode(t, err, codes.InvalidArgument)
+	if message, err := ask(askerCtx, "Is the sole made of rubber?"); err != nil || message != "Question added successfully" {
+		t.Fatalf("AskQuestion: %q %v", message, err)
+	}
+	if message, err := ask(askerCtx, "Is this a scam?"); err != nil || message != "Question submitted for moderation" {
+		t.Fatalf("expected the question to be held, got %q %v", message, err)
+	}
+	env.DB.Lock()
+	var notified []*memory.Notification
+	for _, notification := range env.DB.Notifications {
+		notified = append(notified, notification)
+	}
+	env.DB.Unlock()
+	if len(notified) != 1 || notified[0].UserID != merchant.ID || notified[0].Type != utils.NotificationNewQuestion || !strings.Contains(notified[0].Body, "runner") {
+		t.Fatalf("expected the merchant to be notified of the published question only, got %v", notified)
+	}
+
+	list := func(ctx context.Context, page, pageSize int32) *proto.ProductQuestionsData {
+		t.Helper()
+		res, err := client.GetProductQuestions(ctx, &proto.GetProductQuestionsRequest{ProductId: product.ID, Page: page, PageSize: pageSize})
+		if err != nil {
+			t.Fatalf("GetProductQuestions: %v", err)
+		}
+		return res.Data
+	}
+	data := list(strangerCtx, 0, 0)
+	if data.Total != 1 || data.Questions[0].Question != "Is the sole made of rubber?" || len(data.Questions[0].Answers) != 0 {
+		t.Fatalf("unexpected questions %v", data.Questions)
+	}
+	questionId := data.Questions[0].Id
+	env.DB.Lock()
+	var heldId string
+	for id, question := range env.DB.Questions {
+		if question.Status == utils.ReviewPending {
+			heldId = id
+		}
+	}
+	env.DB.Unlock()
+
+	answer := func(ctx context.Context, questionId, text string) error {
+		_, err := client.AnswerQuestion(ctx, &proto.AnswerQuestionRequest{QuestionId: questionId, Answer: text})
+		return err
+	}
+	assertCode(t, answer(strangerCtx, questionId, "no idea"), codes.PermissionDenied)
+	assertCode(t, answer(testenv.WithToken(context.Background(), otherMerchantToken), questionId, "ours is"), codes.PermissionDenied)
+	assertCode(t, answer(buyerCtx, heldId, "no"), codes.NotFound)
+	assertCode(t, answer(buyerCtx, questionId, ""), codes.InvalidArgument)
+	if err := answer(buyerCtx, questionId, "Yes, and it grips well"); err != nil {
+		t.Fatalf("AnswerQuestion: %v", err)
+	}
+	if err := answer(merchantCtx, questionId, "Yes, natural rubber"); err != nil {
+		t.Fatalf("AnswerQuestion: %v", err)
+	}
+	answers := list(strangerCtx, 1, 10).Questions[0].Answers
+	if len(answers) != 2 || answers[0].AuthorType != "MERCHANT" || answers[1].AuthorType != "VERIFIED_BUYER" {
+		t.Fatalf("expected the answer of the merchant first, got %v", answers)
+	}
+	buyerAnswer := answers[1]
+
+	upvote := func(ctx context.Context, answerId string, up bool) error {
+		_, err := client.UpvoteAnswer(ctx, &proto.UpvoteAnswerRequest{AnswerId: answerId, Upvote: up})
+		return err
+	}
+	assertCode(t, upvote(buyerCtx, buyerAnswer.Id, true), codes.FailedPrecondition)
+	assertCode(t, upvote(askerCtx, "answer", true), codes.InvalidArgument)
+	assertCode(t, upvote(askerCtx, uuid.NewString(), true), codes.NotFound)
+	for _, ctx := range []context.Context{askerCtx, strangerCtx, strangerCtx} {
+		if err := upvote(ctx, buyerAnswer.Id, true); err != nil {
+			t.Fatalf("UpvoteAnswer: %v", err)
+		}
+	}
+	answers = list(strangerCtx, 1, 10).Questions[0].Answers
+	if voted := answers[1]; voted.Id != buyerAnswer.Id || voted.Upvotes != 2 || !voted.Upvoted || answers[0].Upvoted {
+		t.Fatalf("unexpected upvotes %v", answers)
+	}
+	if err := upvote(strangerCtx, buyerAnswer.Id, false); err != nil {
+		t.Fatalf("UpvoteAnswer: %v", err)
+	}
+	if voted := list(strangerCtx, 1, 10).Questions[0].Answers[1]; voted.Upvotes != 1 || voted.Upvoted {
+		t.Fatalf("expected the upvote to be removed, got %v", voted)
+	}
+
+	if _, err := ask(askerCtx, "Does it come in wide?"); err != nil {
+		t.Fatalf("AskQuestion: %v", err)
+	}
+	if data = list(askerCtx, 2, 1); data.Total != 2 || len(data.Questions) != 1 || data.Questions[0].Id != questionId {
+		t.Fatalf("expected the oldest question on the second page, got %v", data.Questions)
+	}
+	_, err = client.GetProductQuestions(askerCtx, &proto.GetProductQuestionsRequest{ProductId: product.ID, PageSize: 51})
+	assertCode(t, err, codes.InvalidArgument)
+
+	env.DB.Lock()
+	product.Status = utils.ProductArchived
+	env.DB.Unlock()
+	_, err = client.GetProductQuestions(askerCtx, &proto.GetProductQuestionsRequest{ProductId: product.ID})
+	assertCode(t, err, codes.NotFound)
+	_, err = ask(askerCtx, "Will it be back?")
+	assertCode(t, err, codes.NotFound)
+	assertCode(t, answer(merchantCtx, questionId, "Soon"), codes.NotFound)
+	assertCode(t, upvote(askerCtx, buyerAnswer.Id, true), codes.NotFound)
+}
+
+func TestSearchProducts(t *testing.T) {
+	env := testenv.New(t)
+	client := env.ProductClient(t)
+	merchant, userToken := env.AddUser(t, "merchant", utils.MERCHANT)
+	shoes := env.AddCategory(t, "Shoes")
+	shirts := env.AddCategory(t, "Shirts")
+	runner := env.AddProduct(t, merchant.ID, shoes.ID, "Trail Runner", 120, 5, 8, 9)
+	env.AddProduct(t, merchant.ID, shoes.ID, "City Walker", 40, 0, 8)
+	env.AddProduct(t, merchant.ID, shirts.ID, "Running Shirt", 30, 5, 2)
+	ctx := testenv.WithToken(context.Background(), userToken)
+
+	_, err := client.SearchProducts(context.Background(), &proto.SearchProductsRequest{Query: "runner"})
+	assertCode(t, err, codes.Unauthenticated)
+
+	res, err := client.SearchProducts(ctx, &proto.SearchProductsRequest{Query: "runer"})
+	if err != nil {
+		t.Fatalf("SearchProducts: %v", err)
+	}
+	if res.Data.Total != 1 || res.Data.Hits[0].Product.Id != runner.ID {
+		t.Fatalf("expected the typo to find the runner, got %v", res.Data.Hits)
+	}
+	if res.Data.Hits[0].NameHighlight != "Trail <mark>Runner</mark>" || !strings.HasPrefix(res.Data.Hits[0].Product.ProductImages[0], "memory://") {
+		t.Fatalf("unexpected hit %v", res.Data.Hits[0])
+	}
+
+	res, err = client.SearchProducts(ctx, &proto.SearchProductsRequest{Query: "shoes"})
+	if err != nil {
+		t.Fatalf("SearchProducts: %v", err)
+	}
+	if res.Data.Total != 2 {
+		t.Fatalf("expected the category name to match, got %v", res.Data.Hits)
+	}
+
+	// a facet counts the products without its own filter
+	res, err = client.SearchProducts(ctx, &proto.SearchProductsRequest{CategoryId: &shoes.ID, Sort: "price_asc"})
+	if err != nil {
+		t.Fatalf("SearchProducts: %v", err)
+	}
+	facets := res.Data.Facets
+	if res.Data.Total != 2 || res.Data.Hits[0].Product.Price != 40 {
+		t.Fatalf("unexpected hits %v", res.Data.Hits)
+	}
+	if facetCount(facets.Categories, shoes.ID) != 2 || facetCount(facets.Categories, shirts.ID) != 1 {
+		t.Fatalf("unexpected category facet %v", facets.Categories)
+	}
+	if facetCount(facets.PriceRanges, "25-50") != 1 || facetCount(facets.PriceRanges, "100-200") != 1 || len(facets.PriceRanges) != 5 {
+		t.Fatalf("unexpected price facet %v", facets.PriceRanges)
+	}
+	if facetCount(facets.Sizes, "8") != 1 || facetCount(facets.Sizes, "9") != 1 || facetCount(facets.Merchants, merchant.ID) != 2 {
+		t.Fatalf("expected the sizes in stock, got %v", facets.Sizes)
+	}
+
+	size := 9.0
+	res, err = client.SearchProducts(ctx, &proto.SearchProductsRequest{Query: "walker", Size: &size})
+	if err != nil {
+		t.Fatalf("SearchProducts: %v", err)
+	}
+	if res.Data.Total != 0 || len(res.Data.Hits) != 0 {
+		t.Fatalf("expected no walker in size 9, got %v", res.Data.Hits)
+	}
+
+	_, err = client.SearchProducts(ctx, &proto.SearchProductsRequest{PageSize: 100})
+	assertCode(t, err, codes.InvalidArgument)
+	_, err = client.SearchProducts(ctx, &proto.SearchProductsRequest{Sort: "cheapest"})
+	assertCode(t, err, codes.InvalidArgument)
+}
+
+func TestSuggestQueriesAndTrendingSearches(t *testing.T) {
+	env := testenv.New(t)
+	client := env.ProductClient(t)
+	merchant, _ := env.AddUser(t, "runners club", utils.MERCHANT)
+	user, _ := env.AddUser(t, "user", utils.USER)
+	shoes := env.AddCategory(t, "Running Shoes")
+	runner := env.AddProduct(t, merchant.ID, shoes.ID, "Trail Runner", 120, 5, 8)
+	road := env.AddProduct(t, merchant.ID, shoes.ID, "Road Runner", 100, 5, 8)
+	env.AddOrderItem(t, user.ID, road, utils.OrderCompleted)
+
+	for _, name := range []string{"first", "second"} {
+		_, accessToken := env.AddUser(t, name, utils.USER)
+		ctx := testenv.WithToken(context.Background(), accessToken)
+		for _, query := range []string{"Trail  Runner", "trail runner", "runner", "unicorn"} {
+			if _, err := client.SearchProducts(ctx, &proto.SearchProductsRequest{Query: query}); err != nil {
+				t.Fatalf("SearchProducts: %v", err)
+			}
+		}
+	}
+	_, userToken := env.AddUser(t, "third", utils.USER)
+	ctx := testenv.WithToken(context.Background(), userToken)
+	if _, err := client.SearchProducts(ctx, &proto.SearchProductsRequest{Query: "runner"}); err != nil {
+		t.Fatalf("SearchProducts: %v", err)
+	}
+
+	res, err := client.SuggestQueries(ctx, &proto.SuggestQueriesRequest{Prefix: "Run"})
+	if err != nil {
+		t.Fatalf("SuggestQueries: %v", err)
+	}
+	data := res.Data
+	if len(data.Queries) != 1 || data.Queries[0].Text != "runner" || data.Queries[0].Popularity != 3 {
+		t.Fatalf("unexpected queries %v", data.Queries)
+	}
+	if len(data.Products) != 2 || data.Products[0].Id != road.ID || data.Products[1].Id != runner.ID {
+		t.Fatalf("expected the ordered product first, got %v", data.Products)
+	}
+	if len(data.Categories) != 1 || data.Categories[0].Id != shoes.ID || len(data.Merchants) != 1 || data.Merchants[0].Id != merchant.ID {
+		t.Fatalf("unexpected suggestions %v", data)
+	}
+
+	_, err = client.SuggestQueries(ctx, &proto.SuggestQueriesRequest{Prefix: "  "})
+	assertCode(t, err, codes.InvalidArgument)
+
+	trending, err := client.GetTrendingSearches(ctx, &proto.GetTrendingSearchesRequest{CategoryId: &shoes.ID})
+	if err != nil {
+		t.Fatalf("GetTrendingSearches: %v", err)
+	}
+	if len(trending.Data) != 2 || trending.Data[0].Query != "runner" || trending.Data[0].Count != 3 || trending.Data[1].Query != "trail runner" || trending.Data[1].Count != 2 {
+		t.Fatalf("unexpected trending searches %v", trending.Data)
+	}
+
+	trending, err = client.GetTrendingSearches(ctx, &proto.GetTrendingSearchesRequest{Date: "2020-01-01"})
+	if err != nil || len(trending.Data) != 0 {
+		t.Fatalf("expected nothing trending in 2020, got %v %v", trending, err)
+	}
+	_, err = client.GetTrendingSearches(ctx, &proto.GetTrendingSearchesRequest{Date: "yesterday"})
+	assertCode(t, err, codes.InvalidArgument)
+}
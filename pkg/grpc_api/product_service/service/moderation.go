@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/akmal4410/gestapo/internal/config"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultDuplicateWindow = 30 * 24 * time.Hour
+	defaultBurstWindow     = time.Hour
+	defaultBurstSize       = 10
+	defaultReportThreshold = 3
+	// minDuplicateLength keeps the short reviews like "great product" out of the duplicate check
+	minDuplicateLength = 20
+)
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+|\b[a-z0-9-]+\.(?:com|net|org|io|co|info|biz|xyz|ru|shop)\b`)
+
+// moderation returns the settings of the automatic checks, with the defaults for the zero values.
+func (handler *productService) moderation() config.Moderation {
+	moderation := config.Moderation{
+		DuplicateWindow: defaultDuplicateWindow,
+		BurstWindow:     defaultBurstWindow,
+		BurstSize:       defaultBurstSize,
+		ReportThreshold: defaultReportThreshold,
+	}
+	settings := handler.config.Moderation
+	if settings == nil {
+		return moderation
+	}
+	moderation.BannedWords = settings.BannedWords
+	moderation.MaxLinks = settings.MaxLinks
+	if settings.DuplicateWindow > 0 {
+		moderation.DuplicateWindow = settings.DuplicateWindow
+	}
+	if settings.BurstWindow > 0 {
+		moderation.BurstWindow = settings.BurstWindow
+	}
+	if settings.BurstSize > 0 {
+		moderation.BurstSize = settings.BurstSize
+	}
+	if settings.ReportThreshold > 0 {
+		moderation.ReportThreshold = settings.ReportThreshold
+	}
+	return moderation
+}
+
+// reviewFlags runs the automatic checks on the text of a review of the product and returns
+// the ones failing. reviewId is the review edited, empty for a new review, which is
+// the only one counting in the rating burst of the product.
+func (handler *productService) reviewFlags(ctx context.Context, productId, reviewId, text string) ([]string, error) {
+	moderation := handler.moderation()
+	normalized := entity.NormalizeReviewText(text)
+	flags := textFlags(moderation, text)
+
+	now := time.Now()
+	if utf8.RuneCountInString(normalized) >= minDuplicateLength {
+		exists, err := handler.storage.ReviewTextExists(ctx, normalized, now.Add(-moderation.DuplicateWindow), reviewId)
+		if err != nil {
+			handler.log.With(ctx).LogError("Error while ReviewTextExists", err)
+			return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+		}
+		if exists {
+			flags = append(flags, entity.FlagDuplicate)
+		}
+	}
+
+	if reviewId == "" {
+		count, err := handler.storage.CountRecentReviews(ctx, productId, now.Add(-moderation.BurstWindow))
+		if err != nil {
+			handler.log.With(ctx).LogError("Error while CountRecentReviews", err)
+			return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+		}
+		if count+1 >= moderation.BurstSize {
+			flags = append(flags, entity.FlagRatingBurst)
+		}
+	}
+	return flags, nil
+}
+
+// textFlags runs the checks on the text alone, which are the ones of the questions
+// and the answers as well, and returns the ones failing.
+func textFlags(moderation config.Moderation, text string) []string {
+	var flags []string
+	if containsBannedWord(entity.NormalizeReviewText(text), moderation.BannedWords) {
+		flags = append(flags, entity.FlagBannedWord)
+	}
+	if len(linkPattern.FindAllString(text, -1)) > moderation.MaxLinks {
+		flags = append(flags, entity.FlagLinkSpam)
+	}
+	return flags
+}
+
+// containsBannedWord matches the banned words on whole words of the normalized text,
+// and the banned phrases anywhere in it.
+func containsBannedWord(normalized string, bannedWords []string) bool {
+	words := map[string]bool{}
+	for _, word := range strings.FieldsFunc(normalized, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		words[word] = true
+	}
+	for _, banned := range bannedWords {
+		banned = entity.NormalizeReviewText(banned)
+		if banned == "" {
+			continue
+		}
+		if strings.Contains(banned, " ") {
+			if strings.Contains(normalized, banned) {
+				return true
+			}
+		} else if words[banned] {
+			return true
+		}
+	}
+	return false
+}
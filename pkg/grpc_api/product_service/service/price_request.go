@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/api/proto"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/helpers/logger"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultPriceHistoryDays = 90
+	maxPriceHistoryDays     = 365
+	defaultPriceDropPercent = 10
+)
+
+func (handler *productService) GetPriceHistory(ctx context.Context, in *proto.GetPriceHistoryRequest) (*proto.GetPriceHistoryResponse, error) {
+	if _, err := uuid.Parse(in.GetProductId()); err != nil {
+		handler.log.With(ctx).LogError("Invalid product id", err)
+		return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
+	}
+	days := int(in.GetDays())
+	if days == 0 {
+		days = defaultPriceHistoryDays
+	}
+	if days < 0 || days > maxPriceHistoryDays {
+		handler.log.With(ctx).LogError("Invalid days", in.GetDays())
+		return nil, status.Errorf(codes.InvalidArgument, "Days must be between 1 and %d", maxPriceHistoryDays)
+	}
+
+	exists, err := handler.storage.CheckDataExist(ctx, "products", "id", in.GetProductId())
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while CheckDataExist", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, utils.NotFound)
+	}
+
+	points, err := handler.storage.GetPriceHistory(ctx, in.GetProductId(), time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while GetPriceHistory", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	data := make([]*proto.PricePoint, 0, len(points))
+	for _, point := range points {
+		data = append(data, &proto.PricePoint{
+			Price:           point.Price,
+			DiscountPercent: point.DiscountPercent,
+			EffectivePrice:  point.EffectivePrice,
+			ChangedAt:       timestamppb.New(point.ChangedAt),
+		})
+	}
+
+	response := &proto.GetPriceHistoryResponse{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Price history fetched successfully",
+		Data:    data,
+	}
+	return response, nil
+}
+
+// priceDropAlert returns the notification of the price drops, with the default
+// threshold when the config has none.
+func (handler *productService) priceDropAlert() entity.PriceDropAlert {
+	alert := entity.PriceDropAlert{
+		Threshold: defaultPriceDropPercent,
+		Title:     "Price drop on your wishlist",
+		Body:      "%s is now %s, down from %s",
+	}
+	if settings := handler.config.PriceAlerts; settings != nil && settings.DropPercent > 0 {
+		alert.Threshold = settings.DropPercent
+	}
+	return alert
+}
+
+// TrackPrices adds the prices changed by the discounts ending to the price history,
+// then notifies the users of the price drops. It returns how many notifications it added.
+func TrackPrices(ctx context.Context, store db.ProductRepository, alert entity.PriceDropAlert, log logger.Logger) (int64, error) {
+	recorded, err := store.RecordPrices(ctx)
+	if err != nil {
+		return 0, err
+	}
+	notified, err := store.NotifyPriceDrops(ctx, alert)
+	if err != nil {
+		return 0, err
+	}
+	log.LogInfo("Recorded", recorded, "prices and sent", notified, "price drop notifications")
+	return notified, nil
+}
+
+// RunPriceTracker runs TrackPrices once and then every interval until ctx is done.
+func (handler *productService) RunPriceTracker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := TrackPrices(ctx, handler.storage, handler.priceDropAlert(), handler.log); err != nil {
+			handler.log.LogError("Error while TrackPrices", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
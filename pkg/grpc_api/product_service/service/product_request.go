@@ -10,112 +10,122 @@ import (
 	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
 	"github.com/akmal4410/gestapo/pkg/helpers/service_helper"
 	"github.com/akmal4410/gestapo/pkg/helpers/token"
+	"github.com/akmal4410/gestapo/pkg/service/images"
 	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func (handler *productService) GetProducts(ctx context.Context, req *proto.GetProductRequest) (*proto.GetProductsResponse, error) {
 	payload, err := service_helper.ValidateServiceToken(ctx, handler.log, handler.token)
 	if err != nil {
-		handler.log.LogError("Error while ValidateServiceToken", err)
-		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+		handler.log.With(ctx).LogError("Error while ValidateServiceToken", err)
+		return nil, err
+	}
+	if req.CategoryId != nil {
+		if _, err := uuid.Parse(req.GetCategoryId()); err != nil {
+			handler.log.With(ctx).LogError("Invalid category id", err)
+			return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
+		}
 	}
 	var productRes []*entity.GetProductRes
 	if payload.UserType == utils.USER {
-		productRes, err = handler.storage.GetProductsForUser(req.MerchantId, payload.UserID)
+		productRes, err = handler.storage.GetProductsForUser(ctx, req.MerchantId, req.CategoryId, payload.UserID)
 		if err != nil {
-			handler.log.LogError("Error while GetProducts", err)
+			handler.log.With(ctx).LogError("Error while GetProducts", err)
 			return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 		}
 	} else {
-		productRes, err = handler.storage.GetProductsForMerchants(req.MerchantId)
+		// the merchants see their own products whatever their status, the admins every product
+		unlisted := payload.UserType == utils.ADMIN || req.MerchantId != nil && *req.MerchantId == payload.UserID
+		productRes, err = handler.storage.GetProductsForMerchants(ctx, req.MerchantId, req.CategoryId, unlisted)
 		if err != nil {
-			handler.log.LogError("Error while GetProducts", err)
+			handler.log.With(ctx).LogError("Error while GetProducts", err)
 			return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 		}
 	}
 
-	for _, product := range productRes {
-		if product.ProductImages != nil {
-			for i, image := range product.ProductImages {
-				url, err := handler.s3.GetPreSignedURL(image)
-				if err != nil {
-					handler.log.LogError("Error while GetPreSignedURL", err)
-					return nil, status.Errorf(codes.Internal, utils.InternalServerError)
-				}
-				product.ProductImages[i] = url
-			}
-		}
-	}
-	var products []*proto.ProductResponse
-	for _, product := range productRes {
-		newProduct := &proto.ProductResponse{
-			Id:            product.ID,
-			MerchantId:    product.MerchantID,
-			ProductImages: product.ProductImages,
-			ProductName:   product.ProductName,
-			Description:   product.Description,
-			CategoryName:  product.CategoryName,
-			// Size:          *product.Size,
-			Price:         product.Price,
-			DiscountPrice: product.DiscountPrice,
-			ReviewStar:    product.ReviewStar,
-			WishlistId:    product.WishlistID,
-		}
-		products = append(products, newProduct)
-	}
-
 	response := &proto.GetProductsResponse{
 		Code:    http.StatusOK,
 		Status:  true,
 		Message: "Products fetched successfully",
-		Data:    products,
+		Data:    handler.productList(ctx, productRes),
 	}
 	return response, nil
 }
 
 func (handler *productService) GetProductById(ctx context.Context, req *proto.ProductIdRequest) (*proto.GetProductByIdResponse, error) {
+	// the guests have no payload, they see the product without a wishlist
 	payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
-	if !ok {
+	if !ok && len(metadata.ValueFromIncomingContext(ctx, utils.DeviceIDKey)) == 0 {
 		err := errors.New("unable to retrieve payload from context")
-		handler.log.LogError("Error", err)
+		handler.log.With(ctx).LogError("Error", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
 	var product *entity.GetProductRes
 	var err error
-	if payload.UserType == utils.USER {
-		product, err = handler.storage.GetProductByIdForUser(req.GetProductId(), payload.UserID)
+	if ok && payload.UserType == utils.USER {
+		product, err = handler.storage.GetProductByIdForUser(ctx, req.GetProductId(), payload.UserID)
 		if err != nil {
 			if err == sql.ErrNoRows {
-				handler.log.LogError("Error while GetProductById Not found", err)
+				handler.log.With(ctx).LogError("Error while GetProductById Not found", err)
 				return nil, status.Errorf(codes.NotFound, "No found")
 			}
-			handler.log.LogError("Error while GetProductById", err)
+			handler.log.With(ctx).LogError("Error while GetProductById", err)
 			return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 		}
 	} else {
-		product, err = handler.storage.GetProductByIdForMerchant(req.GetProductId())
+		product, err = handler.storage.GetProductByIdForMerchant(ctx, req.GetProductId())
 		if err != nil {
 			if err == sql.ErrNoRows {
-				handler.log.LogError("Error while GetProductById Not found", err)
+				handler.log.With(ctx).LogError("Error while GetProductById Not found", err)
 				return nil, status.Errorf(codes.NotFound, "No found")
 			}
-			handler.log.LogError("Error while GetProductById", err)
+			handler.log.With(ctx).LogError("Error while GetProductById", err)
 			return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 		}
 	}
 
-	if product.ProductImages != nil {
-		for i, image := range product.ProductImages {
-			url, err := handler.s3.GetPreSignedURL(image)
-			if err != nil {
-				handler.log.LogError("Error while GetPreSignedURL", err)
-				return nil, status.Errorf(codes.Internal, utils.InternalServerError)
-			}
-			product.ProductImages[i] = url
-		}
+	// the products not listed are found by their merchant and the admins only
+	owner := ok && (payload.UserType == utils.ADMIN || product.MerchantID != nil && *product.MerchantID == payload.UserID)
+	if !product.Listed && !owner {
+		handler.log.With(ctx).LogError("Error while GetProductById product not listed", product.ID)
+		return nil, status.Errorf(codes.NotFound, "No found")
+	}
+	if !owner {
+		product.Status, product.PublishAt, product.UnpublishAt, product.StatusReason = nil, nil, nil, nil
+	}
+
+	handler.recordView(ctx, product.ID)
+
+	product.RatingDistribution, err = handler.storage.GetRatingDistribution(ctx, product.ID)
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while GetRatingDistribution", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	productImages := product.ProductImages
+	for _, variant := range product.Variants {
+		productImages = append(productImages, variant.Images...)
+	}
+	urls := images.URLs(ctx, handler.urls, productImages, images.Large)
+	product.ProductImages = images.Pick(product.ProductImages, urls)
+
+	variants := make([]*proto.ProductVariant, 0, len(product.Variants))
+	for _, variant := range product.Variants {
+		variants = append(variants, &proto.ProductVariant{
+			Id:            variant.ID,
+			Sku:           variant.SKU,
+			Options:       variant.Options,
+			Price:         variant.Price,
+			DiscountPrice: variant.DiscountPrice,
+			Images:        images.Pick(variant.Images, urls),
+			Quantity:      variant.Quantity,
+		})
 	}
 
 	productRes := &proto.ProductResponse{
@@ -130,6 +140,18 @@ func (handler *productService) GetProductById(ctx context.Context, req *proto.Pr
 		DiscountPrice: product.DiscountPrice,
 		ReviewStar:    product.ReviewStar,
 		WishlistId:    product.WishlistID,
+		OptionTypes:   product.OptionTypes,
+		Variants:      variants,
+		Attributes:    product.Attributes,
+		ReviewCount:   product.ReviewCount,
+		UnitsSold:     product.UnitsSold,
+		WishlistCount: product.WishlistCount,
+		// every star is listed, with the stars without reviews at 0
+		RatingDistribution: map[int32]int64{1: 0, 2: 0, 3: 0, 4: 0, 5: 0},
+	}
+	setLifecycle(productRes, product)
+	for star, count := range product.RatingDistribution {
+		productRes.RatingDistribution[star] = count
 	}
 
 	response := &proto.GetProductByIdResponse{
@@ -141,3 +163,49 @@ func (handler *productService) GetProductById(ctx context.Context, req *proto.Pr
 
 	return response, nil
 }
+
+// productList returns the products of a listing with the signed URLs of their images.
+func (handler *productService) productList(ctx context.Context, productRes []*entity.GetProductRes) []*proto.ProductResponse {
+	var productImages []string
+	for _, product := range productRes {
+		productImages = append(productImages, product.ProductImages...)
+	}
+	urls := images.URLs(ctx, handler.urls, productImages, images.Medium)
+	for _, product := range productRes {
+		product.ProductImages = images.Pick(product.ProductImages, urls)
+	}
+	var products []*proto.ProductResponse
+	for _, product := range productRes {
+		newProduct := &proto.ProductResponse{
+			Id:            product.ID,
+			MerchantId:    product.MerchantID,
+			ProductImages: product.ProductImages,
+			ProductName:   product.ProductName,
+			Description:   product.Description,
+			CategoryName:  product.CategoryName,
+			// Size:          *product.Size,
+			Price:         product.Price,
+			DiscountPrice: product.DiscountPrice,
+			ReviewStar:    product.ReviewStar,
+			WishlistId:    product.WishlistID,
+			ReviewCount:   product.ReviewCount,
+			UnitsSold:     product.UnitsSold,
+			WishlistCount: product.WishlistCount,
+		}
+		setLifecycle(newProduct, product)
+		products = append(products, newProduct)
+	}
+	return products
+}
+
+// setLifecycle sets the status and the schedule of the product, when the store returned them.
+func setLifecycle(res *proto.ProductResponse, product *entity.GetProductRes) {
+	res.Status = product.Status
+	res.StatusReason = product.StatusReason
+	if product.PublishAt != nil {
+		res.PublishAt = timestamppb.New(*product.PublishAt)
+	}
+	if product.UnpublishAt != nil {
+		res.UnpublishAt = timestamppb.New(*product.UnpublishAt)
+	}
+}
@@ -0,0 +1,295 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/akmal4410/gestapo/pkg/api/proto"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultQuestionPageSize = 10
+	maxQuestionPageSize     = 50
+	maxQuestionLength       = 500
+	maxAnswerLength         = 1000
+)
+
+// questionNotification is the notification of the merchant when a question on
+// one of its products is published.
+var questionNotification = entity.QuestionNotification{
+	Title: "New question",
+	Body:  "A shopper asked a question about %s",
+}
+
+// AskQuestion adds a question of the user on the product, held for moderation when
+// it fails the automatic checks. The merchant is notified once it is published.
+func (handler *productService) AskQuestion(ctx context.Context, in *proto.AskQuestionRequest) (*proto.Response, error) {
+	payload, err := handler.payload(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if payload.UserType != utils.USER {
+		return nil, status.Errorf(codes.PermissionDenied, "only the users can ask questions")
+	}
+	if _, err := uuid.Parse(in.GetProductId()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid product_id")
+	}
+	question := strings.TrimSpace(in.GetQuestion())
+	if question == "" || utf8.RuneCountInString(question) > maxQuestionLength {
+		return nil, status.Errorf(codes.InvalidArgument, "question should have between 1 and %d characters", maxQuestionLength)
+	}
+
+	_, err = handler.storage.GetProductByIdForUser(ctx, in.GetProductId(), payload.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "Product Not found")
+		}
+		handler.log.With(ctx).LogError("Error while GetProductByIdForUser", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	req := &entity.AddQuestionReq{
+		ProductID:    in.GetProductId(),
+		UserID:       payload.UserID,
+		Question:     question,
+		Flags:        textFlags(handler.moderation(), question),
+		Notification: questionNotification,
+	}
+	err = handler.storage.AddQuestion(ctx, req)
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while AddQuestion", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	response := &proto.Response{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Question added successfully",
+	}
+	if len(req.Flags) != 0 {
+		response.Message = "Question submitted for moderation"
+	}
+	return response, nil
+}
+
+// GetProductQuestions returns a page of the questions of the product, the newest
+// first, with their answers.
+func (handler *productService) GetProductQuestions(ctx context.Context, in *proto.GetProductQuestionsRequest) (*proto.GetProductQuestionsResponse, error) {
+	payload, err := handler.payload(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := uuid.Parse(in.GetProductId()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid product_id")
+	}
+	page := in.GetPage()
+	if page < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "page should be positive")
+	}
+	if page == 0 {
+		page = 1
+	}
+	pageSize := in.GetPageSize()
+	if pageSize < 0 || pageSize > maxQuestionPageSize {
+		return nil, status.Errorf(codes.InvalidArgument, "page_size should be between 1 and %d", maxQuestionPageSize)
+	}
+	if pageSize == 0 {
+		pageSize = defaultQuestionPageSize
+	}
+
+	_, err = handler.storage.GetProductByIdForUser(ctx, in.GetProductId(), payload.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "Product Not found")
+		}
+		handler.log.With(ctx).LogError("Error while GetProductByIdForUser", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	res, err := handler.storage.GetProductQuestions(ctx, &entity.QuestionsReq{
+		ProductID: in.GetProductId(),
+		UserID:    payload.UserID,
+		Limit:     int(pageSize),
+		Offset:    int(page-1) * int(pageSize),
+	})
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while GetProductQuestions", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	questions := make([]*proto.QuestionResponse, 0, len(res.Questions))
+	for _, question := range res.Questions {
+		questions = append(questions, questionResponse(question))
+	}
+	response := &proto.GetProductQuestionsResponse{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Product questions fetched successfully",
+		Data: &proto.ProductQuestionsData{
+			Questions: questions,
+			Total:     res.Total,
+			Page:      page,
+			PageSize:  pageSize,
+		},
+	}
+	return response, nil
+}
+
+func questionResponse(question *entity.Question) *proto.QuestionResponse {
+	res := &proto.QuestionResponse{
+		Id:         question.ID,
+		ProductId:  question.ProductID,
+		UserId:     question.UserID,
+		AuthorName: question.AuthorName,
+		Question:   question.Question,
+		Answers:    make([]*proto.AnswerResponse, 0, len(question.Answers)),
+		CreatedAt:  timestamppb.New(question.CreatedAt),
+	}
+	for _, answer := range question.Answers {
+		authorType := entity.AnswerByBuyer
+		if answer.ByMerchant {
+			authorType = entity.AnswerByMerchant
+		}
+		res.Answers = append(res.Answers, &proto.AnswerResponse{
+			Id:         answer.ID,
+			UserId:     answer.UserID,
+			AuthorName: answer.AuthorName,
+			AuthorType: authorType,
+			Answer:     answer.Answer,
+			Upvotes:    answer.Upvotes,
+			Upvoted:    answer.Upvoted,
+			CreatedAt:  timestamppb.New(answer.CreatedAt),
+		})
+	}
+	return res
+}
+
+// AnswerQuestion adds an answer to the question, by the merchant of the product or
+// by a user who bought it, held for moderation when it fails the automatic checks.
+func (handler *productService) AnswerQuestion(ctx context.Context, in *proto.AnswerQuestionRequest) (*proto.Response, error) {
+	payload, err := handler.payload(ctx)
+	if err != nil {
+		return nil, err
+	}
+	answer := strings.TrimSpace(in.GetAnswer())
+	if answer == "" || utf8.RuneCountInString(answer) > maxAnswerLength {
+		return nil, status.Errorf(codes.InvalidArgument, "answer should have between 1 and %d characters", maxAnswerLength)
+	}
+
+	question, err := handler.publishedQuestion(ctx, in.GetQuestionId())
+	if err != nil {
+		return nil, err
+	}
+	byMerchant := payload.UserType == utils.MERCHANT && question.MerchantID == payload.UserID
+	if !byMerchant {
+		bought := false
+		if payload.UserType == utils.USER {
+			bought, err = handler.storage.HasBoughtProduct(ctx, question.ProductID, payload.UserID)
+			if err != nil {
+				handler.log.With(ctx).LogError("Error while HasBoughtProduct", err)
+				return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+			}
+		}
+		if !bought {
+			return nil, status.Errorf(codes.PermissionDenied, "only the merchant of the product and the users who bought it can answer")
+		}
+	}
+
+	req := &entity.AddAnswerReq{
+		QuestionID: question.ID,
+		UserID:     payload.UserID,
+		Answer:     answer,
+		ByMerchant: byMerchant,
+		Flags:      textFlags(handler.moderation(), answer),
+	}
+	err = handler.storage.AddAnswer(ctx, req)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "Question not found")
+		}
+		handler.log.With(ctx).LogError("Error while AddAnswer", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	response := &proto.Response{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Answer added successfully",
+	}
+	if len(req.Flags) != 0 {
+		response.Message = "Answer submitted for moderation"
+	}
+	return response, nil
+}
+
+// UpvoteAnswer adds the upvote of the user on the answer, or removes it.
+func (handler *productService) UpvoteAnswer(ctx context.Context, in *proto.UpvoteAnswerRequest) (*proto.Response, error) {
+	payload, err := handler.payload(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := uuid.Parse(in.GetAnswerId()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid answer_id")
+	}
+
+	answer, err := handler.storage.GetAnswer(ctx, in.GetAnswerId())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "Answer not found")
+		}
+		handler.log.With(ctx).LogError("Error while GetAnswer", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	// the answers are read only once they and their question are published
+	if answer.Status != utils.ReviewPublished || answer.QuestionStatus != utils.ReviewPublished {
+		return nil, status.Errorf(codes.NotFound, "Answer not found")
+	}
+	if answer.UserID == payload.UserID {
+		return nil, status.Errorf(codes.FailedPrecondition, "users cannot upvote their own answer")
+	}
+
+	err = handler.storage.UpvoteAnswer(ctx, answer.ID, payload.UserID, in.GetUpvote())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "Answer not found")
+		}
+		handler.log.With(ctx).LogError("Error while UpvoteAnswer", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	response := &proto.Response{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Upvote saved successfully",
+	}
+	return response, nil
+}
+
+// publishedQuestion returns the question when it is published, the others are
+// NotFound like they are for the readers.
+func (handler *productService) publishedQuestion(ctx context.Context, questionId string) (*entity.Question, error) {
+	if _, err := uuid.Parse(questionId); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid question_id")
+	}
+	question, err := handler.storage.GetQuestion(ctx, questionId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "Question not found")
+		}
+		handler.log.With(ctx).LogError("Error while GetQuestion", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	if question.Status != utils.ReviewPublished {
+		return nil, status.Errorf(codes.NotFound, "Question not found")
+	}
+	return question, nil
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/api/proto"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/helpers/logger"
+	"github.com/akmal4410/gestapo/pkg/helpers/token"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultRecommendationLimit = 10
+	maxRecommendationLimit     = 50
+	// recommendationsPerProduct is how many related products the recommender keeps for each product
+	recommendationsPerProduct = 50
+)
+
+// recommendationWeights count a product bought with another more than one
+// carted with it, and one carted more than one wishlisted by the same user.
+var recommendationWeights = entity.RecommendationWeights{
+	Order:    3,
+	Cart:     2,
+	Wishlist: 1,
+}
+
+func (handler *productService) GetRelatedProducts(ctx context.Context, in *proto.GetRelatedProductsRequest) (*proto.GetProductsResponse, error) {
+	payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
+	if !ok {
+		err := errors.New("unable to retrieve payload from context")
+		handler.log.With(ctx).LogError("Error", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	if _, err := uuid.Parse(in.GetProductId()); err != nil {
+		handler.log.With(ctx).LogError("Invalid product id", err)
+		return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
+	}
+	limit, err := pageLimit(in.GetLimit(), defaultRecommendationLimit, maxRecommendationLimit)
+	if err != nil {
+		handler.log.With(ctx).LogError("Invalid limit", err)
+		return nil, err
+	}
+
+	exists, err := handler.storage.CheckDataExist(ctx, "products", "id", in.GetProductId())
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while CheckDataExist", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, utils.NotFound)
+	}
+
+	products, err := handler.storage.GetRelatedProducts(ctx, in.GetProductId(), payload.UserID, limit)
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while GetRelatedProducts", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	response := &proto.GetProductsResponse{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Related products fetched successfully",
+		Data:    handler.productList(ctx, products),
+	}
+	return response, nil
+}
+
+func (handler *productService) GetRecommendedForUser(ctx context.Context, in *proto.GetRecommendedForUserRequest) (*proto.GetProductsResponse, error) {
+	payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
+	if !ok {
+		err := errors.New("unable to retrieve payload from context")
+		handler.log.With(ctx).LogError("Error", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	limit, err := pageLimit(in.GetLimit(), defaultRecommendationLimit, maxRecommendationLimit)
+	if err != nil {
+		handler.log.With(ctx).LogError("Invalid limit", err)
+		return nil, err
+	}
+
+	products, err := handler.storage.GetRecommendedProducts(ctx, payload.UserID, limit)
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while GetRecommendedProducts", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	response := &proto.GetProductsResponse{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Recommended products fetched successfully",
+		Data:    handler.productList(ctx, products),
+	}
+	return response, nil
+}
+
+// ComputeRecommendations recomputes the related products of every product and
+// returns how many recommendations it kept.
+func ComputeRecommendations(ctx context.Context, store db.ProductRepository, log logger.Logger) (int64, error) {
+	n, err := store.ComputeRecommendations(ctx, recommendationWeights, recommendationsPerProduct)
+	if err != nil {
+		return 0, err
+	}
+	log.LogInfo("Computed", n, "product recommendations")
+	return n, nil
+}
+
+// RunRecommender runs ComputeRecommendations once and then every interval until ctx is done.
+func (handler *productService) RunRecommender(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := ComputeRecommendations(ctx, handler.storage, handler.log); err != nil {
+			handler.log.LogError("Error while ComputeRecommendations", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
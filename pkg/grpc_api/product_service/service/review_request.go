@@ -2,22 +2,55 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/akmal4410/gestapo/pkg/api/proto"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db"
 	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
 	"github.com/akmal4410/gestapo/pkg/helpers"
 	"github.com/akmal4410/gestapo/pkg/helpers/service_helper"
+	"github.com/akmal4410/gestapo/pkg/helpers/token"
+	"github.com/akmal4410/gestapo/pkg/service/images"
 	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+const (
+	defaultReviewPageSize = 10
+	maxReviewPageSize     = 50
+	maxReplyLength        = 1000
+	maxReportDetails      = 1000
+)
+
+var reviewSorts = map[string]bool{
+	entity.ReviewSortNewest:  true,
+	entity.ReviewSortOldest:  true,
+	entity.ReviewSortHelpful: true,
+	entity.ReviewSortHighest: true,
+	entity.ReviewSortLowest:  true,
+}
+
+var reportReasons = map[string]bool{
+	entity.ReportSpam:      true,
+	entity.ReportOffensive: true,
+	entity.ReportOffTopic:  true,
+	entity.ReportFake:      true,
+	entity.ReportOther:     true,
+}
+
 func (handler *productService) AddProductReview(ctx context.Context, in *proto.AddReviewRequest) (*proto.Response, error) {
 	paylaod, err := service_helper.ValidateServiceToken(ctx, handler.log, handler.token)
 	if err != nil {
-		handler.log.LogError("Error while ValidateServiceToken", err)
-		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+		handler.log.With(ctx).LogError("Error while ValidateServiceToken", err)
+		return nil, err
 	}
 
 	req := &entity.AddReviewReq{
@@ -26,36 +59,53 @@ func (handler *productService) AddProductReview(ctx context.Context, in *proto.A
 		UserID:      paylaod.UserID,
 		Star:        in.GetStart(),
 		Review:      in.GetReview(),
+		SessionIDs:  in.GetPhotoSessionIds(),
 	}
 	err = helpers.ValidateBody(nil, req)
 	if err != nil {
-		handler.log.LogError("Error while ValidateBody", err)
+		handler.log.With(ctx).LogError("Error while ValidateBody", err)
 		return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
 	}
+	if len(req.SessionIDs) > entity.MaxReviewPhotos {
+		return nil, status.Errorf(codes.InvalidArgument, "a review can have at most %d photos", entity.MaxReviewPhotos)
+	}
 
-	res, err := handler.storage.IsUserCanAddReview(req.OrderItemID, req.UserID)
+	res, err := handler.storage.IsUserCanAddReview(ctx, req.OrderItemID, req.UserID)
 	if err != nil {
-		handler.log.LogError("Error while IsUserCanAddReview", err)
+		handler.log.With(ctx).LogError("Error while IsUserCanAddReview", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 	if !res {
-		handler.log.LogError("User Cannot add review")
+		handler.log.With(ctx).LogError("User Cannot add review")
 		return nil, status.Errorf(codes.PermissionDenied, "User cannot add review")
 	}
 
-	res, err = handler.storage.IsUserAlreadyAddedReview(req.ProductID, req.UserID)
+	res, err = handler.storage.IsUserAlreadyAddedReview(ctx, req.ProductID, req.UserID)
 	if err != nil {
-		handler.log.LogError("Error while IsUserAlreadyAddedReview", err)
+		handler.log.With(ctx).LogError("Error while IsUserAlreadyAddedReview", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 	if res {
-		handler.log.LogError("User already added review")
+		handler.log.With(ctx).LogError("User already added review")
 		return nil, status.Errorf(codes.PermissionDenied, "User already added review")
 	}
 
-	err = handler.storage.AddProductReview(req)
+	req.Flags, err = handler.reviewFlags(ctx, req.ProductID, "", req.Review)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Images, err = handler.reviewPhotos(ctx, req.UserID, req.ProductID, req.SessionIDs)
 	if err != nil {
-		handler.log.LogError("Error while AddProductReview", err)
+		return nil, err
+	}
+
+	err = handler.storage.AddProductReview(ctx, req)
+	if err != nil {
+		if errors.Is(err, db.ErrUploadNotPending) {
+			return nil, status.Errorf(codes.FailedPrecondition, "upload session is already completed")
+		}
+		handler.log.With(ctx).LogError("Error while AddProductReview", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 	response := &proto.Response{
@@ -63,5 +113,475 @@ func (handler *productService) AddProductReview(ctx context.Context, in *proto.A
 		Status:  true,
 		Message: "Product Review added successfully",
 	}
+	if len(req.Flags) != 0 {
+		response.Message = "Product Review submitted for moderation"
+	}
+	return response, nil
+}
+
+// GetProductReviews returns a page of the reviews of the product with their photos,
+// votes and the reply of the merchant.
+func (handler *productService) GetProductReviews(ctx context.Context, in *proto.GetProductReviewsRequest) (*proto.GetProductReviewsResponse, error) {
+	payload, err := handler.payload(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := uuid.Parse(in.GetProductId()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid product_id")
+	}
+
+	sort := in.GetSort()
+	if sort == "" {
+		sort = entity.ReviewSortNewest
+	}
+	if !reviewSorts[sort] {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid sort %q", sort)
+	}
+	if in.Star != nil && (in.GetStar() < 1 || in.GetStar() > 5) {
+		return nil, status.Errorf(codes.InvalidArgument, "star should be between 1 and 5")
+	}
+	page := in.GetPage()
+	if page < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "page should be positive")
+	}
+	if page == 0 {
+		page = 1
+	}
+	pageSize := in.GetPageSize()
+	if pageSize < 0 || pageSize > maxReviewPageSize {
+		return nil, status.Errorf(codes.InvalidArgument, "page_size should be between 1 and %d", maxReviewPageSize)
+	}
+	if pageSize == 0 {
+		pageSize = defaultReviewPageSize
+	}
+
+	exists, err := handler.storage.CheckDataExist(ctx, "products", "id", in.GetProductId())
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while CheckDataExist", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "Product Not found")
+	}
+
+	res, err := handler.storage.GetProductReviews(ctx, &entity.ReviewsReq{
+		ProductID:  in.GetProductId(),
+		UserID:     payload.UserID,
+		Sort:       sort,
+		Star:       in.Star,
+		WithPhotos: in.GetWithPhotos(),
+		Limit:      int(pageSize),
+		Offset:     int(page-1) * int(pageSize),
+	})
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while GetProductReviews", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	var photos []string
+	for _, review := range res.Reviews {
+		photos = append(photos, review.Images...)
+	}
+	urls := images.URLs(ctx, handler.urls, photos, images.Medium)
+
+	reviews := make([]*proto.ReviewResponse, 0, len(res.Reviews))
+	for _, review := range res.Reviews {
+		reviews = append(reviews, reviewResponse(review, urls))
+	}
+
+	response := &proto.GetProductReviewsResponse{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Product reviews fetched successfully",
+		Data: &proto.ProductReviewsData{
+			Reviews:  reviews,
+			Total:    res.Total,
+			Page:     page,
+			PageSize: pageSize,
+		},
+	}
+	return response, nil
+}
+
+func reviewResponse(review *entity.Review, urls map[string]string) *proto.ReviewResponse {
+	res := &proto.ReviewResponse{
+		Id:              review.ID,
+		ProductId:       review.ProductID,
+		UserId:          review.UserID,
+		AuthorName:      review.AuthorName,
+		Star:            review.Star,
+		Review:          review.Review,
+		Photos:          images.Pick(review.Images, urls),
+		HelpfulCount:    review.HelpfulCount,
+		NotHelpfulCount: review.NotHelpfulCount,
+		Edited:          review.EditedAt != nil,
+		CreatedAt:       timestamppb.New(review.CreatedAt),
+		UpdatedAt:       timestamppb.New(review.UpdatedAt),
+	}
+	if review.MyVote != nil {
+		vote := entity.VoteNotHelpful
+		if *review.MyVote {
+			vote = entity.VoteHelpful
+		}
+		res.MyVote = &vote
+	}
+	if review.Reply != nil {
+		res.Reply = &proto.ReviewReply{Reply: *review.Reply}
+		if review.RepliedAt != nil {
+			res.Reply.CreatedAt = timestamppb.New(*review.RepliedAt)
+		}
+		if review.ReplyUpdatedAt != nil {
+			res.Reply.UpdatedAt = timestamppb.New(*review.ReplyUpdatedAt)
+		}
+	}
+	return res
+}
+
+// EditProductReview changes the star, the text and the photos of a review of the user.
+// A published review whose new text fails the automatic checks goes back to the queue.
+func (handler *productService) EditProductReview(ctx context.Context, in *proto.EditReviewRequest) (*proto.Response, error) {
+	payload, err := handler.payload(ctx)
+	if err != nil {
+		return nil, err
+	}
+	review, err := handler.authorReview(ctx, payload.UserID, in.GetReviewId())
+	if err != nil {
+		return nil, err
+	}
+
+	req := &entity.EditReviewReq{
+		ReviewID:      review.ID,
+		Star:          in.Star,
+		Review:        in.Review,
+		RemovedImages: in.GetRemovedPhotos(),
+		SessionIDs:    in.GetPhotoSessionIds(),
+	}
+	if req.Star != nil && (*req.Star < 1 || *req.Star > 5) {
+		return nil, status.Errorf(codes.InvalidArgument, "star should be between 1 and 5")
+	}
+	if req.Review != nil && strings.TrimSpace(*req.Review) == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "review should not be empty")
+	}
+	if req.Star == nil && req.Review == nil && len(req.RemovedImages) == 0 && len(req.SessionIDs) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
+	}
+	for _, removed := range req.RemovedImages {
+		if !containsImage(review.Images, removed) {
+			return nil, status.Errorf(codes.InvalidArgument, "the photo %s is not a photo of the review", removed)
+		}
+	}
+	if len(entity.EditImages(review.Images, req.RemovedImages, req.SessionIDs)) > entity.MaxReviewPhotos {
+		return nil, status.Errorf(codes.FailedPrecondition, "a review can have at most %d photos", entity.MaxReviewPhotos)
+	}
+
+	if req.Review != nil && review.Status == utils.ReviewPublished &&
+		entity.NormalizeReviewText(*req.Review) != entity.NormalizeReviewText(review.Review) {
+		req.Flags, err = handler.reviewFlags(ctx, review.ProductID, review.ID, *req.Review)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	req.AddedImages, err = handler.reviewPhotos(ctx, payload.UserID, review.ProductID, req.SessionIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	err = handler.storage.EditProductReview(ctx, req)
+	if err != nil {
+		if errors.Is(err, db.ErrUploadNotPending) {
+			return nil, status.Errorf(codes.FailedPrecondition, "upload session is already completed")
+		}
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "Review not found")
+		}
+		handler.log.With(ctx).LogError("Error while EditProductReview", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	handler.deleteReviewPhotos(ctx, req.RemovedImages)
+
+	response := &proto.Response{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Product Review updated successfully",
+	}
+	if len(req.Flags) != 0 {
+		response.Message = "Product Review submitted for moderation"
+	}
+	return response, nil
+}
+
+// DeleteProductReview deletes a review of the user with its photos and votes.
+func (handler *productService) DeleteProductReview(ctx context.Context, in *proto.ReviewIdRequest) (*proto.Response, error) {
+	payload, err := handler.payload(ctx)
+	if err != nil {
+		return nil, err
+	}
+	review, err := handler.authorReview(ctx, payload.UserID, in.GetReviewId())
+	if err != nil {
+		return nil, err
+	}
+
+	err = handler.storage.DeleteProductReview(ctx, review.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "Review not found")
+		}
+		handler.log.With(ctx).LogError("Error while DeleteProductReview", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	handler.deleteReviewPhotos(ctx, review.Images)
+
+	response := &proto.Response{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Product Review deleted successfully",
+	}
+	return response, nil
+}
+
+// VoteReview sets whether the review was helpful to the user, an empty vote removes it.
+func (handler *productService) VoteReview(ctx context.Context, in *proto.VoteReviewRequest) (*proto.Response, error) {
+	payload, err := handler.payload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var helpful *bool
+	switch in.GetVote() {
+	case entity.VoteHelpful, entity.VoteNotHelpful:
+		vote := in.GetVote() == entity.VoteHelpful
+		helpful = &vote
+	case "":
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "vote should be %s, %s or empty", entity.VoteHelpful, entity.VoteNotHelpful)
+	}
+
+	review, err := handler.publishedReview(ctx, in.GetReviewId())
+	if err != nil {
+		return nil, err
+	}
+	if review.UserID == payload.UserID {
+		return nil, status.Errorf(codes.FailedPrecondition, "users cannot vote on their own review")
+	}
+
+	err = handler.storage.VoteReview(ctx, review.ID, payload.UserID, helpful)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "Review not found")
+		}
+		handler.log.With(ctx).LogError("Error while VoteReview", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	response := &proto.Response{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Vote saved successfully",
+	}
+	return response, nil
+}
+
+// ReplyToReview sets the public reply of the merchant of the product to the review.
+func (handler *productService) ReplyToReview(ctx context.Context, in *proto.ReplyReviewRequest) (*proto.Response, error) {
+	payload, err := handler.payload(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if payload.UserType != utils.MERCHANT {
+		return nil, status.Errorf(codes.PermissionDenied, "only the merchant of the product can reply")
+	}
+	reply := strings.TrimSpace(in.GetReply())
+	if reply == "" || utf8.RuneCountInString(reply) > maxReplyLength {
+		return nil, status.Errorf(codes.InvalidArgument, "reply should have between 1 and %d characters", maxReplyLength)
+	}
+
+	review, err := handler.publishedReview(ctx, in.GetReviewId())
+	if err != nil {
+		return nil, err
+	}
+	if review.MerchantID != payload.UserID {
+		return nil, status.Errorf(codes.PermissionDenied, "only the merchant of the product can reply")
+	}
+
+	err = handler.storage.ReplyToReview(ctx, review.ID, reply)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "Review not found")
+		}
+		handler.log.With(ctx).LogError("Error while ReplyToReview", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	response := &proto.Response{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Reply saved successfully",
+	}
+	return response, nil
+}
+
+// ReportReview reports the review to the moderators, the review goes back to
+// the queue once enough users reported it.
+func (handler *productService) ReportReview(ctx context.Context, in *proto.ReportReviewRequest) (*proto.Response, error) {
+	payload, err := handler.payload(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !reportReasons[in.GetReason()] {
+		return nil, status.Errorf(codes.InvalidArgument, "reason should be %s, %s, %s, %s or %s", entity.ReportSpam,
+			entity.ReportOffensive, entity.ReportOffTopic, entity.ReportFake, entity.ReportOther)
+	}
+	details := strings.TrimSpace(in.GetDetails())
+	if utf8.RuneCountInString(details) > maxReportDetails {
+		return nil, status.Errorf(codes.InvalidArgument, "details should have at most %d characters", maxReportDetails)
+	}
+
+	review, err := handler.publishedReview(ctx, in.GetReviewId())
+	if err != nil {
+		return nil, err
+	}
+	if review.UserID == payload.UserID {
+		return nil, status.Errorf(codes.FailedPrecondition, "users cannot report their own review")
+	}
+
+	report := &entity.ReviewReport{
+		ID:       uuid.NewString(),
+		ReviewID: review.ID,
+		UserID:   payload.UserID,
+		Reason:   in.GetReason(),
+		Details:  details,
+	}
+	err = handler.storage.ReportReview(ctx, report, handler.moderation().ReportThreshold)
+	if err != nil {
+		if errors.Is(err, db.ErrAlreadyReported) {
+			return nil, status.Errorf(codes.AlreadyExists, "review is already reported by the user")
+		}
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "Review not found")
+		}
+		handler.log.With(ctx).LogError("Error while ReportReview", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	response := &proto.Response{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Review reported successfully",
+	}
 	return response, nil
 }
+
+func (handler *productService) payload(ctx context.Context) (*token.AccessPayload, error) {
+	payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
+	if !ok {
+		err := errors.New("unable to retrieve user payload from context")
+		handler.log.With(ctx).LogError("Error", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	return payload, nil
+}
+
+// review returns the review, with NotFound when there is none.
+func (handler *productService) review(ctx context.Context, reviewId string) (*entity.Review, error) {
+	if _, err := uuid.Parse(reviewId); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid review_id")
+	}
+	review, err := handler.storage.GetReview(ctx, reviewId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "Review not found")
+		}
+		handler.log.With(ctx).LogError("Error while GetReview", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	return review, nil
+}
+
+// publishedReview returns the review when it is published, the others are
+// NotFound like they are for the readers.
+func (handler *productService) publishedReview(ctx context.Context, reviewId string) (*entity.Review, error) {
+	review, err := handler.review(ctx, reviewId)
+	if err != nil {
+		return nil, err
+	}
+	if review.Status != utils.ReviewPublished {
+		return nil, status.Errorf(codes.NotFound, "Review not found")
+	}
+	return review, nil
+}
+
+// authorReview returns the review when the user wrote it.
+func (handler *productService) authorReview(ctx context.Context, userId, reviewId string) (*entity.Review, error) {
+	review, err := handler.review(ctx, reviewId)
+	if err != nil {
+		return nil, err
+	}
+	if review.UserID != userId {
+		return nil, status.Errorf(codes.PermissionDenied, "review does not belong to the user")
+	}
+	return review, nil
+}
+
+func (handler *productService) deleteReviewPhotos(ctx context.Context, photos []string) {
+	for _, photo := range photos {
+		if err := images.Delete(ctx, handler.files, photo); err != nil {
+			handler.log.With(ctx).LogWarn("Error deleting the review photo", photo, err)
+		}
+	}
+}
+
+func containsImage(images []string, image string) bool {
+	for _, other := range images {
+		if other == image {
+			return true
+		}
+	}
+	return false
+}
+
+// reviewPhotos verifies the uploads of the review photos and returns the images
+// processed from them, in the order of the sessions.
+func (handler *productService) reviewPhotos(ctx context.Context, userId, productId string, sessionIds []string) ([]string, error) {
+	photos := make([]string, 0, len(sessionIds))
+	seen := map[string]bool{}
+	for _, sessionId := range sessionIds {
+		if seen[sessionId] {
+			return nil, status.Errorf(codes.InvalidArgument, "the upload session %s is repeated", sessionId)
+		}
+		seen[sessionId] = true
+		photo, err := handler.reviewPhoto(ctx, userId, productId, sessionId)
+		if err != nil {
+			return nil, err
+		}
+		photos = append(photos, photo)
+	}
+	return photos, nil
+}
+
+func (handler *productService) reviewPhoto(ctx context.Context, userId, productId, sessionId string) (string, error) {
+	if _, err := uuid.Parse(sessionId); err != nil {
+		return "", status.Errorf(codes.InvalidArgument, "invalid upload session id")
+	}
+	session, err := handler.storage.GetUploadSession(ctx, sessionId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", status.Errorf(codes.NotFound, "Upload session not found")
+		}
+		handler.log.With(ctx).LogError("Error while GetUploadSession", err)
+		return "", status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	if session.UserID != userId {
+		return "", status.Errorf(codes.PermissionDenied, "upload session does not belong to the user")
+	}
+	if session.Purpose != entity.ReviewImage || session.TargetID == nil || *session.TargetID != productId {
+		return "", status.Errorf(codes.FailedPrecondition, "upload session is not a photo of a review of the product")
+	}
+	if session.Status != entity.UploadPending {
+		return "", status.Errorf(codes.FailedPrecondition, "upload session is already completed")
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return "", status.Errorf(codes.FailedPrecondition, "upload session is expired")
+	}
+	return handler.processUpload(ctx, session)
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/akmal4410/gestapo/pkg/api/proto"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/helpers/token"
+	"github.com/akmal4410/gestapo/pkg/service/images"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultSearchPageSize = 20
+	maxSearchPageSize     = 50
+	maxSearchQueryLength  = 200
+)
+
+var searchSorts = map[string]bool{
+	entity.SortRelevance: true,
+	entity.SortPriceAsc:  true,
+	entity.SortPriceDesc: true,
+	entity.SortRating:    true,
+	entity.SortNewest:    true,
+}
+
+func (handler *productService) SearchProducts(ctx context.Context, in *proto.SearchProductsRequest) (*proto.SearchProductsResponse, error) {
+	req, page, pageSize, err := newSearchProductsReq(in)
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while validating SearchProducts", err)
+		return nil, err
+	}
+
+	res, err := handler.storage.SearchProducts(ctx, req)
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while SearchProducts", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	if req.Query != "" && page == 1 {
+		handler.logSearchQuery(ctx, req, res)
+	}
+
+	var productImages []string
+	for _, hit := range res.Hits {
+		productImages = append(productImages, hit.ProductImages...)
+	}
+	urls := images.URLs(ctx, handler.urls, productImages, images.Medium)
+
+	hits := make([]*proto.SearchHit, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		product := &proto.ProductResponse{
+			Id:            hit.ID,
+			MerchantId:    hit.MerchantID,
+			ProductImages: images.Pick(hit.ProductImages, urls),
+			ProductName:   hit.ProductName,
+			Description:   hit.Description,
+			CategoryName:  hit.CategoryName,
+			Price:         hit.Price,
+			DiscountPrice: hit.DiscountPrice,
+			ReviewStar:    hit.ReviewStar,
+			ReviewCount:   hit.ReviewCount,
+			UnitsSold:     hit.UnitsSold,
+			WishlistCount: hit.WishlistCount,
+		}
+		if hit.Size != nil {
+			product.Size = *hit.Size
+		}
+		hits = append(hits, &proto.SearchHit{
+			Product:              product,
+			Score:                hit.Score,
+			NameHighlight:        hit.NameHighlight,
+			DescriptionHighlight: hit.DescriptionHighlight,
+		})
+	}
+
+	response := &proto.SearchProductsResponse{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Products fetched successfully",
+		Data: &proto.SearchProductsData{
+			Hits: hits,
+			Facets: &proto.SearchFacets{
+				Categories:  facetValues(res.Facets.Categories),
+				PriceRanges: facetValues(res.Facets.PriceRanges),
+				Ratings:     facetValues(res.Facets.Ratings),
+				Merchants:   facetValues(res.Facets.Merchants),
+				Sizes:       facetValues(res.Facets.Sizes),
+			},
+			Total:    res.Total,
+			Page:     page,
+			PageSize: pageSize,
+		},
+	}
+	return response, nil
+}
+
+// logSearchQuery logs the first page of a search for the trending searches. A search
+// without a category counts for the category of its best match. The search is served
+// even when it cannot be logged.
+func (handler *productService) logSearchQuery(ctx context.Context, req *entity.SearchProductsReq, res *entity.SearchProductsRes) {
+	queryLog := &entity.SearchQueryLog{
+		Query:      normalizeQuery(req.Query),
+		CategoryID: req.CategoryID,
+		Results:    res.Total,
+	}
+	if payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload); ok {
+		queryLog.UserID = payload.UserID
+	}
+	if queryLog.CategoryID == nil && len(res.Hits) != 0 {
+		queryLog.CategoryID = &res.Hits[0].CategoryID
+	}
+	if err := handler.storage.LogSearchQuery(ctx, queryLog); err != nil {
+		handler.log.With(ctx).LogWarn("Error while LogSearchQuery", err)
+	}
+}
+
+// normalizeQuery lowercases the query and collapses its spaces, so the same search is counted once.
+func normalizeQuery(query string) string {
+	return strings.ToLower(strings.Join(strings.Fields(query), " "))
+}
+
+// newSearchProductsReq validates the request and returns the search with the page it asks for.
+func newSearchProductsReq(in *proto.SearchProductsRequest) (*entity.SearchProductsReq, int32, int32, error) {
+	query := strings.TrimSpace(in.GetQuery())
+	if utf8.RuneCountInString(query) > maxSearchQueryLength {
+		return nil, 0, 0, status.Errorf(codes.InvalidArgument, "Query must not be longer than %d characters", maxSearchQueryLength)
+	}
+	sort := in.GetSort()
+	if sort == "" {
+		sort = entity.SortRelevance
+	}
+	if !searchSorts[sort] {
+		return nil, 0, 0, status.Errorf(codes.InvalidArgument, "Invalid sort %s", sort)
+	}
+	page, pageSize := in.GetPage(), in.GetPageSize()
+	if page == 0 {
+		page = 1
+	}
+	if pageSize == 0 {
+		pageSize = defaultSearchPageSize
+	}
+	if page < 0 || pageSize < 0 || pageSize > maxSearchPageSize {
+		return nil, 0, 0, status.Errorf(codes.InvalidArgument, "Page size must be between 1 and %d", maxSearchPageSize)
+	}
+	if in.MinPrice != nil && in.MaxPrice != nil && in.GetMinPrice() > in.GetMaxPrice() {
+		return nil, 0, 0, status.Errorf(codes.InvalidArgument, "Minimum price must not be above the maximum price")
+	}
+	if in.MinRating != nil && (in.GetMinRating() < 0 || in.GetMinRating() > 5) {
+		return nil, 0, 0, status.Errorf(codes.InvalidArgument, "Minimum rating must be between 0 and 5")
+	}
+
+	req := &entity.SearchProductsReq{
+		Query:      query,
+		CategoryID: in.CategoryId,
+		MerchantID: in.MerchantId,
+		MinPrice:   in.MinPrice,
+		MaxPrice:   in.MaxPrice,
+		MinRating:  in.MinRating,
+		Size:       in.Size,
+		Sort:       sort,
+		Limit:      int(pageSize),
+		Offset:     int(page-1) * int(pageSize),
+	}
+	return req, page, pageSize, nil
+}
+
+func facetValues(counts []entity.FacetCount) []*proto.FacetValue {
+	values := make([]*proto.FacetValue, 0, len(counts))
+	for _, count := range counts {
+		values = append(values, &proto.FacetValue{Value: count.Value, Label: count.Label, Count: count.Count})
+	}
+	return values
+}
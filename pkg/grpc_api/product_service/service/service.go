@@ -7,7 +7,8 @@ import (
 	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db"
 	"github.com/akmal4410/gestapo/pkg/helpers/logger"
 	"github.com/akmal4410/gestapo/pkg/helpers/token"
-	s3 "github.com/akmal4410/gestapo/pkg/service/s3_service"
+	objectstore "github.com/akmal4410/gestapo/pkg/service/object_store"
+	urlsigner "github.com/akmal4410/gestapo/pkg/service/url_signer"
 )
 
 // productService serves gRPC requests for our e-commerce service.
@@ -15,28 +16,45 @@ type productService struct {
 	proto.UnimplementedProductServiceServer
 	config  *config.Config
 	log     logger.Logger
-	s3      *s3.S3Service
-	storage *db.ProductStore
+	urls    *urlsigner.Signer
+	files   objectstore.ObjectStore
+	storage db.ProductRepository
 	token   token.Maker
 }
 
+// Dependencies are the stores and the clients used by the product service.
+type Dependencies struct {
+	Store db.ProductRepository
+	Files objectstore.ObjectStore
+	URLs  *urlsigner.Signer
+}
+
 // NewProductService creates a new gRPC server.
 func NewProductService(storage *database.Storage, config *config.Config, log logger.Logger, tokenMaker token.Maker) *productService {
-	server := &productService{
-		config: config,
-		log:    log,
-		token:  tokenMaker,
+	files, err := objectstore.New(config)
+	if err != nil {
+		log.LogFatal("Error while Initializing object store ", err)
 	}
-	s3 := s3.NewS3Service(
-		config.AwsS3.BucketName,
-		config.AwsS3.Region,
-		config.AwsS3.AccessKey,
-		config.AwsS3.SecretKey,
-	)
-
-	productStore := db.NewProductStore(storage)
+	urls, err := urlsigner.New(config, files, log)
+	if err != nil {
+		log.LogFatal("Error while Initializing url signer ", err)
+	}
+	deps := Dependencies{
+		Store: db.NewProductStore(storage),
+		Files: files,
+		URLs:  urls,
+	}
+	return NewProductServiceWith(deps, config, log, tokenMaker)
+}
 
-	server.s3 = s3
-	server.storage = productStore
-	return server
+// NewProductServiceWith creates a new gRPC server working with deps.
+func NewProductServiceWith(deps Dependencies, config *config.Config, log logger.Logger, tokenMaker token.Maker) *productService {
+	return &productService{
+		config:  config,
+		log:     log,
+		token:   tokenMaker,
+		urls:    deps.URLs,
+		files:   deps.Files,
+		storage: deps.Store,
+	}
 }
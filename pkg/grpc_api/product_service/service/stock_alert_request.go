@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/api/proto"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/helpers/logger"
+	"github.com/akmal4410/gestapo/pkg/helpers/token"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultStockAlertBatchSize     = 100
+	defaultStockAlertPerUnit       = 2
+	defaultStockAlertBatchInterval = 30 * time.Minute
+)
+
+func (handler *productService) NotifyWhenAvailable(ctx context.Context, in *proto.NotifyWhenAvailableRequest) (*proto.Response, error) {
+	payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
+	if !ok {
+		err := errors.New("unable to retrieve payload from context")
+		handler.log.With(ctx).LogError("Error", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	if _, err := uuid.Parse(in.GetProductId()); err != nil {
+		handler.log.With(ctx).LogError("Invalid product id", err)
+		return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
+	}
+	if in.VariantId != nil {
+		if _, err := uuid.Parse(in.GetVariantId()); err != nil {
+			handler.log.With(ctx).LogError("Invalid variant id", err)
+			return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
+		}
+	}
+
+	stock, err := handler.storage.GetVariantStock(ctx, in.GetProductId(), in.VariantId, float64(in.GetSize()))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, utils.NotFound)
+		}
+		handler.log.With(ctx).LogError("Error while GetVariantStock", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	if stock.Quantity > 0 {
+		return nil, status.Errorf(codes.FailedPrecondition, "The variant is in stock")
+	}
+
+	added, err := handler.storage.SubscribeToStock(ctx, payload.UserID, in.GetProductId(), stock.InventoryID)
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while SubscribeToStock", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	if !added {
+		return nil, status.Errorf(codes.AlreadyExists, utils.AlreadyExists)
+	}
+
+	response := &proto.Response{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "You will be notified when the product is back in stock",
+	}
+	return response, nil
+}
+
+// backInStockAlert returns the notification of the variants back in stock, with
+// the defaults for the zero values of the config.
+func (handler *productService) backInStockAlert() entity.BackInStockAlert {
+	alert := entity.BackInStockAlert{
+		BatchSize:     defaultStockAlertBatchSize,
+		PerUnit:       defaultStockAlertPerUnit,
+		BatchInterval: defaultStockAlertBatchInterval,
+		Title:         "Back in stock",
+		Body:          "%s is back in stock",
+	}
+	settings := handler.config.StockAlerts
+	if settings == nil {
+		return alert
+	}
+	if settings.BatchSize > 0 {
+		alert.BatchSize = settings.BatchSize
+	}
+	if settings.PerUnit > 0 {
+		alert.PerUnit = settings.PerUnit
+	}
+	if settings.BatchInterval > 0 {
+		alert.BatchInterval = settings.BatchInterval
+	}
+	return alert
+}
+
+// SendStockAlerts notifies the next batch of subscribers of the variants back in
+// stock and returns how many notifications it added.
+func SendStockAlerts(ctx context.Context, store db.ProductRepository, alert entity.BackInStockAlert, log logger.Logger) (int64, error) {
+	notified, err := store.NotifyBackInStock(ctx, alert)
+	if err != nil {
+		return 0, err
+	}
+	if notified != 0 {
+		log.LogInfo("Sent", notified, "back in stock notifications")
+	}
+	return notified, nil
+}
+
+// RunStockAlerts runs SendStockAlerts every interval until ctx is done.
+func (handler *productService) RunStockAlerts(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := SendStockAlerts(ctx, handler.storage, handler.backInStockAlert(), handler.log); err != nil {
+				handler.log.LogError("Error while SendStockAlerts", err)
+			}
+		}
+	}
+}
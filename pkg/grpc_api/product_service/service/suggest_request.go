@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/akmal4410/gestapo/pkg/api/proto"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultSuggestionLimit = 5
+	maxSuggestionLimit     = 10
+	maxPrefixLength        = 100
+	defaultTrendingLimit   = 10
+	maxTrendingLimit       = 50
+	trendingDateLayout     = "2006-01-02"
+)
+
+func (handler *productService) SuggestQueries(ctx context.Context, in *proto.SuggestQueriesRequest) (*proto.SuggestQueriesResponse, error) {
+	prefix := normalizeQuery(in.GetPrefix())
+	if prefix == "" || utf8.RuneCountInString(prefix) > maxPrefixLength {
+		handler.log.With(ctx).LogError("Invalid prefix", in.GetPrefix())
+		return nil, status.Errorf(codes.InvalidArgument, "Prefix must have between 1 and %d characters", maxPrefixLength)
+	}
+	limit, err := pageLimit(in.GetLimit(), defaultSuggestionLimit, maxSuggestionLimit)
+	if err != nil {
+		handler.log.With(ctx).LogError("Invalid limit", err)
+		return nil, err
+	}
+
+	suggestions, err := handler.storage.SuggestQueries(ctx, prefix, limit)
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while SuggestQueries", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	response := &proto.SuggestQueriesResponse{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Suggestions fetched successfully",
+		Data: &proto.SuggestQueriesData{
+			Queries:    suggestionValues(suggestions.Queries),
+			Products:   suggestionValues(suggestions.Products),
+			Categories: suggestionValues(suggestions.Categories),
+			Merchants:  suggestionValues(suggestions.Merchants),
+		},
+	}
+	return response, nil
+}
+
+func (handler *productService) GetTrendingSearches(ctx context.Context, in *proto.GetTrendingSearchesRequest) (*proto.GetTrendingSearchesResponse, error) {
+	day := time.Now().UTC().Truncate(24 * time.Hour)
+	if in.GetDate() != "" {
+		date, err := time.Parse(trendingDateLayout, in.GetDate())
+		if err != nil {
+			handler.log.With(ctx).LogError("Invalid date", err)
+			return nil, status.Errorf(codes.InvalidArgument, "Date must be formatted as YYYY-MM-DD")
+		}
+		day = date
+	}
+	if in.CategoryId != nil {
+		if _, err := uuid.Parse(in.GetCategoryId()); err != nil {
+			handler.log.With(ctx).LogError("Invalid category id", err)
+			return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
+		}
+	}
+	limit, err := pageLimit(in.GetLimit(), defaultTrendingLimit, maxTrendingLimit)
+	if err != nil {
+		handler.log.With(ctx).LogError("Invalid limit", err)
+		return nil, err
+	}
+
+	trending, err := handler.storage.GetTrendingSearches(ctx, &entity.TrendingSearchesReq{
+		CategoryID: in.CategoryId,
+		From:       day,
+		To:         day.Add(24 * time.Hour),
+		Limit:      limit,
+	})
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while GetTrendingSearches", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	queries := make([]*proto.QueryCount, 0, len(trending))
+	for _, query := range trending {
+		queries = append(queries, &proto.QueryCount{Query: query.Query, Count: query.Count})
+	}
+	response := &proto.GetTrendingSearchesResponse{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Trending searches fetched successfully",
+		Data:    queries,
+	}
+	return response, nil
+}
+
+// pageLimit returns the limit asked by the client, or the default one when it is not set.
+func pageLimit(limit int32, defaultLimit, maxLimit int) (int, error) {
+	if limit == 0 {
+		return defaultLimit, nil
+	}
+	if limit < 0 || int(limit) > maxLimit {
+		return 0, status.Errorf(codes.InvalidArgument, "Limit must be between 1 and %d", maxLimit)
+	}
+	return int(limit), nil
+}
+
+func suggestionValues(suggestions []entity.Suggestion) []*proto.Suggestion {
+	values := make([]*proto.Suggestion, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		values = append(values, &proto.Suggestion{
+			Id:         suggestion.ID,
+			Text:       suggestion.Text,
+			Popularity: suggestion.Popularity,
+		})
+	}
+	return values
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/api/proto"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/service/images"
+	objectstore "github.com/akmal4410/gestapo/pkg/service/object_store"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// uploadCompleteWindow is how long an upload can be attached to a review,
+	// after that it is an orphan and the upload collector deletes it
+	uploadCompleteWindow = time.Hour
+	defaultUploadMaxSize = 10 << 20
+)
+
+func (handler *productService) uploadMaxSize() int64 {
+	if handler.config.Storage != nil && handler.config.Storage.UploadMaxSize > 0 {
+		return handler.config.Storage.UploadMaxSize
+	}
+	return defaultUploadMaxSize
+}
+
+// CreateReviewPhotoUpload returns a presigned URL the user uploads a photo of a review
+// of the product to, the photo is attached when the review is added or edited.
+func (handler *productService) CreateReviewPhotoUpload(ctx context.Context, req *proto.CreateReviewPhotoUploadRequest) (*proto.CreateUploadSessionResponse, error) {
+	payload, err := handler.payload(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if payload.UserType != utils.USER {
+		return nil, status.Errorf(codes.PermissionDenied, "only users can upload review photos")
+	}
+
+	extension, ok := images.UploadExtensions[req.GetContentType()]
+	if !ok {
+		handler.log.With(ctx).LogError("Unsupported content type", req.GetContentType())
+		return nil, status.Errorf(codes.InvalidArgument, "only jpeg, png and webp images can be uploaded")
+	}
+	if req.GetSize() <= 0 || req.GetSize() > handler.uploadMaxSize() {
+		handler.log.With(ctx).LogError("Invalid upload size", req.GetSize())
+		return nil, status.Errorf(codes.InvalidArgument, "size should be between 1 and %d bytes", handler.uploadMaxSize())
+	}
+	if _, err := uuid.Parse(req.GetProductId()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid product_id")
+	}
+	exists, err := handler.storage.CheckDataExist(ctx, "products", "id", req.GetProductId())
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while CheckDataExist", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "Product Not found")
+	}
+
+	sessionId, err := uuid.NewRandom()
+	if err != nil {
+		handler.log.With(ctx).LogError("error while uuid NewRandom", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	productId := req.GetProductId()
+	session := &entity.UploadSession{
+		ID:          sessionId.String(),
+		UserID:      payload.UserID,
+		Purpose:     entity.ReviewImage,
+		TargetID:    &productId,
+		Key:         "reviews/" + payload.UserID + "/" + productId + "/" + sessionId.String() + extension,
+		ContentType: req.GetContentType(),
+		Size:        req.GetSize(),
+		Status:      entity.UploadPending,
+		CreatedAt:   time.Now(),
+	}
+	session.ExpiresAt = session.CreatedAt.Add(uploadCompleteWindow)
+
+	uploadURL, err := handler.files.PresignPut(ctx, session.Key, session.ContentType, session.Size)
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while PresignPut", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	err = handler.storage.CreateUploadSession(ctx, session)
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while CreateUploadSession", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	response := &proto.CreateUploadSessionResponse{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Upload session created successfully",
+		Data: &proto.UploadSessionResponse{
+			Id:        session.ID,
+			Key:       session.Key,
+			UploadUrl: uploadURL,
+			Method:    http.MethodPut,
+			Headers:   map[string]string{"Content-Type": session.ContentType},
+			ExpiresAt: timestamppb.New(session.ExpiresAt),
+		},
+	}
+	return response, nil
+}
+
+// processUpload verifies the uploaded image and replaces it by its renditions, so the
+// stored image has no EXIF or GPS metadata. An invalid upload is deleted.
+func (handler *productService) processUpload(ctx context.Context, session *entity.UploadSession) (string, error) {
+	err := objectstore.Verify(ctx, handler.files, session.Key, session.ContentType, session.Size)
+	if err != nil {
+		if errors.Is(err, objectstore.ErrNotFound) {
+			return "", status.Errorf(codes.FailedPrecondition, "the file is not uploaded yet")
+		}
+		if errors.Is(err, objectstore.ErrInvalidObject) {
+			handler.log.With(ctx).LogError("Invalid upload", session.Key, err)
+			handler.discardUpload(ctx, session)
+			return "", status.Errorf(codes.InvalidArgument, "the uploaded file is not the declared image")
+		}
+		handler.log.With(ctx).LogError("Error while Verify", err)
+		return "", status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	image, err := images.Process(ctx, handler.files, session.Key)
+	if err != nil {
+		if errors.Is(err, images.ErrUnsupportedImage) {
+			handler.log.With(ctx).LogError("Invalid image", session.Key, err)
+			handler.discardUpload(ctx, session)
+			return "", status.Errorf(codes.InvalidArgument, "the uploaded file is not a valid image")
+		}
+		if errors.Is(err, objectstore.ErrNotFound) {
+			return "", status.Errorf(codes.FailedPrecondition, "the file is not uploaded yet")
+		}
+		handler.log.With(ctx).LogError("Error while Process", err)
+		return "", status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	return image, nil
+}
+
+// discardUpload deletes an upload that can never be attached, its session is
+// deleted by the upload collector once it expires.
+func (handler *productService) discardUpload(ctx context.Context, session *entity.UploadSession) {
+	if err := handler.files.Delete(ctx, session.Key); err != nil {
+		handler.log.With(ctx).LogWarn("Error deleting the invalid upload", session.Key, err)
+	}
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/api/proto"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/helpers/token"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultRecentlyViewedLimit = 20
+	maxRecentlyViewedLimit     = 50
+	maxDeviceIDLength          = 128
+	// viewDedupeWindow is how long the views of a product by the same viewer count once
+	viewDedupeWindow = 30 * time.Minute
+)
+
+func (handler *productService) GetRecentlyViewed(ctx context.Context, in *proto.GetRecentlyViewedRequest) (*proto.GetRecentlyViewedResponse, error) {
+	viewer, err := handler.viewer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := pageLimit(in.GetLimit(), defaultRecentlyViewedLimit, maxRecentlyViewedLimit)
+	if err != nil {
+		handler.log.With(ctx).LogError("Invalid limit", err)
+		return nil, err
+	}
+
+	viewed, err := handler.storage.GetRecentlyViewed(ctx, viewer, limit)
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while GetRecentlyViewed", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	products := make([]*entity.GetProductRes, 0, len(viewed))
+	for _, view := range viewed {
+		products = append(products, view.Product)
+	}
+	productList := handler.productList(ctx, products)
+	data := make([]*proto.RecentlyViewedProduct, 0, len(viewed))
+	for i, view := range viewed {
+		data = append(data, &proto.RecentlyViewedProduct{
+			Product:  productList[i],
+			ViewedAt: timestamppb.New(view.ViewedAt),
+		})
+	}
+
+	response := &proto.GetRecentlyViewedResponse{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Recently viewed products fetched successfully",
+		Data:    data,
+	}
+	return response, nil
+}
+
+func (handler *productService) ClearRecentlyViewed(ctx context.Context, in *proto.Request) (*proto.Response, error) {
+	viewer, err := handler.viewer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cleared, err := handler.storage.ClearRecentlyViewed(ctx, viewer)
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while ClearRecentlyViewed", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	handler.log.With(ctx).LogInfo("Cleared", cleared, "product views")
+
+	response := &proto.Response{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Recently viewed products cleared successfully",
+	}
+	return response, nil
+}
+
+// recordView records the view of the product by a user or a guest, the views of
+// the merchants and the admins are not counted. The product is served even
+// when the view cannot be recorded.
+func (handler *productService) recordView(ctx context.Context, productId string) {
+	payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
+	if ok && payload.UserType != utils.USER {
+		return
+	}
+	viewer, err := handler.viewer(ctx)
+	if err != nil {
+		return
+	}
+	if err := handler.storage.RecordProductView(ctx, productId, viewer, viewDedupeWindow); err != nil {
+		handler.log.With(ctx).LogWarn("Error while RecordProductView", err)
+	}
+}
+
+// viewer returns the user of the request, with the device it browses from when
+// the client sends it, or the device of a guest.
+func (handler *productService) viewer(ctx context.Context) (entity.Viewer, error) {
+	var viewer entity.Viewer
+	if values := metadata.ValueFromIncomingContext(ctx, utils.DeviceIDKey); len(values) != 0 && values[0] != "" {
+		if len(values[0]) > maxDeviceIDLength {
+			handler.log.With(ctx).LogError("Invalid device id", values[0])
+			return viewer, status.Errorf(codes.InvalidArgument, "Device id must have at most %d characters", maxDeviceIDLength)
+		}
+		viewer.DeviceID = &values[0]
+	}
+	if payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload); ok {
+		viewer.UserID = &payload.UserID
+	}
+	if viewer.UserID == nil && viewer.DeviceID == nil {
+		handler.log.With(ctx).LogError("Neither a user nor a device")
+		return viewer, status.Errorf(codes.Unauthenticated, utils.Unauthorized)
+	}
+	return viewer, nil
+}
@@ -0,0 +1,66 @@
+package product_service
+
+import (
+	"fmt"
+
+	"github.com/akmal4410/gestapo/internal/config"
+	"github.com/akmal4410/gestapo/internal/database"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db"
+	"github.com/akmal4410/gestapo/pkg/helpers/logger"
+	"github.com/akmal4410/gestapo/pkg/helpers/service_helper"
+)
+
+const statsName = "Product Stats"
+
+// The commands of RunStats.
+const (
+	StatsBackfill = "backfill"
+	StatsCheck    = "check"
+)
+
+// RunStats runs a command on the product stats: backfill recomputes the stats of
+// every product from the tables, check lists the products whose stats drifted
+// from the tables and fails when there are some.
+func RunStats(command string) error {
+	ctx, log := service_helper.InitializeService(statsName, logFileName)
+	config, err := config.LoadConfig("configs")
+	if err != nil {
+		log.LogFatal("Cannot load configuration:", err)
+	}
+
+	storage, err := database.NewStorage(config.Database, logFileName)
+	if err != nil {
+		log.LogFatal("Cannot connect to Database", err)
+	}
+	store := db.NewProductStore(storage)
+
+	switch command {
+	case StatsBackfill:
+		n, err := store.BackfillStats(ctx)
+		if err != nil {
+			log.LogError("Error while BackfillStats", err)
+			return err
+		}
+		log.LogInfo("Stats backfilled for", n, "products")
+	case StatsCheck:
+		drifts, err := store.CheckStats(ctx)
+		if err != nil {
+			log.LogError("Error while CheckStats", err)
+			return err
+		}
+		for _, drift := range drifts {
+			log.WithFields(logger.Fields{
+				"product_id": drift.ProductID,
+				"stored":     drift.Stored,
+				"expected":   drift.Expected,
+			}).LogWarn("Product stats drifted")
+		}
+		if len(drifts) != 0 {
+			return fmt.Errorf("the stats of %d products drifted, run the backfill", len(drifts))
+		}
+		log.LogInfo("Product stats are consistent")
+	default:
+		return fmt.Errorf("unknown command %q, expected %s or %s", command, StatsBackfill, StatsCheck)
+	}
+	return nil
+}
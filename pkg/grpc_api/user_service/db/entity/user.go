@@ -29,12 +29,28 @@ type AddRemoveWishlistReq struct {
 	UserID    string `json:"user_id" validate:"required"`
 }
 
+// CartOwner is the user of a cart, or the device of a guest when UserID is nil.
+type CartOwner struct {
+	UserID   *string `json:"user_id,omitempty"`
+	DeviceID *string `json:"device_id,omitempty"`
+}
+
+// AddToCartReq adds the variant of the product, picked by its id or else by its
+// size. The price charged is the one of the variant, and adding a variant already
+// in the cart adds to its quantity.
 type AddToCartReq struct {
-	ProductID string  `json:"product_id" validate:"required"`
-	Size      float64 `json:"size" validate:"required"`
-	Quantity  int32   `json:"quantity" validate:"required"`
-	Price     float64 `json:"price" validate:"required"`
-	UserID    string  `json:"user_id" validate:"required"`
+	ProductID string    `json:"product_id" validate:"required"`
+	VariantID *string   `json:"variant_id" validate:"omitempty,uuid"`
+	Size      float64   `json:"size" validate:"required_without=VariantID"`
+	Quantity  int32     `json:"quantity" validate:"required,min=1"`
+	Owner     CartOwner `json:"owner"`
+}
+
+// UpdateCartItemQuantityReq sets the quantity of the cart item, 0 removes it.
+type UpdateCartItemQuantityReq struct {
+	CartItemID string    `json:"cart_item_id" validate:"required,uuid"`
+	Quantity   int32     `json:"quantity" validate:"min=0"`
+	Owner      CartOwner `json:"owner"`
 }
 
 type CartRes struct {
@@ -44,26 +60,30 @@ type CartRes struct {
 }
 
 type CartItemRes struct {
-	ProductID         string  `json:"product_id"`
-	CartID            string  `json:"cart_id"`
-	CartItemID        string  `json:"cart_item_id"`
-	ImageURL          string  `json:"image_url"`
-	Name              string  `json:"name"`
-	Size              float64 `json:"size"`
-	Price             float64 `json:"price"`
-	Quantity          int32   `json:"quantity"`
-	AvailableQuantity int32   `json:"available_quantity"`
-	InventoryID       string  `json:"inventory_id"`
+	ProductID         string         `json:"product_id"`
+	CartID            string         `json:"cart_id"`
+	CartItemID        string         `json:"cart_item_id"`
+	ImageURL          string         `json:"image_url"`
+	Name              string         `json:"name"`
+	Size              float64        `json:"size"`
+	Price             float64        `json:"price"`
+	Quantity          int32          `json:"quantity"`
+	AvailableQuantity int32          `json:"available_quantity"`
+	InventoryID       string         `json:"inventory_id"`
+	SKU               *string        `json:"sku,omitempty"`
+	Options           entity.Options `json:"options"`
+	// Unavailable is true when the product is not listed anymore.
+	Unavailable bool `json:"unavailable"`
 }
 
 type CheckoutCartItemsReq struct {
 	CartID string         `json:"cart_id" validate:"required"`
-	Data   []*CheckoutReq `json:"data" validate:"required"`
+	Data   []*CheckoutReq `json:"data" validate:"required,dive"`
 }
 
 type CheckoutReq struct {
-	CartItemID string `json:"cart_item_id" validate:"required"`
-	Quantity   int32  `json:"quantity" validate:"required"`
+	CartItemID string `json:"cart_item_id" validate:"required,uuid"`
+	Quantity   int32  `json:"quantity" validate:"required,min=1"`
 }
 
 type AddAddressReq struct {
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/grpc_api/user_service/db/entity"
+)
+
+func (store *MemoryUserStore) GetNotifications(ctx context.Context, userId string, unreadOnly bool, limit int) ([]*entity.NotificationRes, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	notifications := []*entity.NotificationRes{}
+	for _, notification := range store.db.Notifications {
+		if notification.UserID != userId || (unreadOnly && notification.ReadAt != nil) {
+			continue
+		}
+		notifications = append(notifications, &entity.NotificationRes{
+			ID:        notification.ID,
+			Type:      notification.Type,
+			Title:     notification.Title,
+			Body:      notification.Body,
+			ProductID: notification.ProductID,
+			ReadAt:    notification.ReadAt,
+			CreatedAt: notification.CreatedAt,
+		})
+	}
+	sort.Slice(notifications, func(i, j int) bool {
+		if !notifications[i].CreatedAt.Equal(notifications[j].CreatedAt) {
+			return notifications[i].CreatedAt.After(notifications[j].CreatedAt)
+		}
+		return notifications[i].ID < notifications[j].ID
+	})
+	if len(notifications) > limit {
+		notifications = notifications[:limit]
+	}
+	return notifications, nil
+}
+
+func (store *MemoryUserStore) CountUnreadNotifications(ctx context.Context, userId string) (int64, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	var count int64
+	for _, notification := range store.db.Notifications {
+		if notification.UserID == userId && notification.ReadAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (store *MemoryUserStore) MarkNotificationsRead(ctx context.Context, userId string, ids []string) (int64, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	marked := map[string]bool{}
+	for _, id := range ids {
+		marked[id] = true
+	}
+	now := time.Now()
+	var n int64
+	for _, notification := range store.db.Notifications {
+		if notification.UserID != userId || notification.ReadAt != nil || (len(ids) != 0 && !marked[notification.ID]) {
+			continue
+		}
+		notification.ReadAt = &now
+		n++
+	}
+	return n, nil
+}
@@ -0,0 +1,503 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/akmal4410/gestapo/internal/database/memory"
+	product_entity "github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/user_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+)
+
+// MemoryUserStore is the in-memory UserRepository.
+type MemoryUserStore struct {
+	db *memory.Database
+}
+
+func NewMemoryUserStore(db *memory.Database) *MemoryUserStore {
+	return &MemoryUserStore{db: db}
+}
+
+func (store *MemoryUserStore) CheckDataExist(ctx context.Context, table, column, value string) (bool, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	return store.db.Exists(table, column, value)
+}
+
+func (store *MemoryUserStore) GetDiscount(ctx context.Context) (*entity.DiscountRes, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	var best *entity.DiscountRes
+	for _, product := range store.db.Products {
+		if product.DiscountID == nil || !memory.Listed(product) {
+			continue
+		}
+		discount, ok := store.db.Discounts[*product.DiscountID]
+		if !ok || !discount.EndTime.After(time.Now()) {
+			continue
+		}
+		if best != nil && best.Percentage >= discount.Percent {
+			continue
+		}
+		best = &entity.DiscountRes{
+			ProductID:    product.ID,
+			Name:         discount.Name,
+			Description:  discount.Description,
+			Percentage:   discount.Percent,
+			ProductImage: memory.FirstImage(product),
+		}
+	}
+	if best == nil {
+		return nil, sql.ErrNoRows
+	}
+	return best, nil
+}
+
+func (store *MemoryUserStore) GetMerchants(ctx context.Context) ([]*entity.MerchantRes, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	var users []*memory.User
+	for _, user := range store.db.Users {
+		if user.UserType == utils.MERCHANT {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].CreatedAt.Before(users[j].CreatedAt)
+	})
+	if len(users) > 7 {
+		users = users[:7]
+	}
+	var merchants []*entity.MerchantRes
+	for _, user := range users {
+		name := user.UserName
+		if user.FullName != nil && *user.FullName != "" {
+			name = *user.FullName
+		}
+		merchants = append(merchants, &entity.MerchantRes{
+			MerchantID: user.ID,
+			Name:       name,
+			ImageURL:   user.ProfileImage,
+		})
+	}
+	return merchants, nil
+}
+
+func (store *MemoryUserStore) AlreadyInWishlist(ctx context.Context, req *entity.AddRemoveWishlistReq) (bool, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	return store.db.WishlistID(req.ProductID, req.UserID) != nil, nil
+}
+
+func (store *MemoryUserStore) AddToWishlist(ctx context.Context, req *entity.AddRemoveWishlistReq) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	now := time.Now()
+	wishlist := &memory.Wishlist{
+		ID:        uuid.NewString(),
+		UserID:    req.UserID,
+		ProductID: req.ProductID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	store.db.Wishlists[wishlist.ID] = wishlist
+	return nil
+}
+
+func (store *MemoryUserStore) RemoveFromWishlist(ctx context.Context, req *entity.AddRemoveWishlistReq) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	var n int
+	for id, wishlist := range store.db.Wishlists {
+		if wishlist.UserID == req.UserID && wishlist.ProductID == req.ProductID {
+			delete(store.db.Wishlists, id)
+			n++
+		}
+	}
+	if n == 0 {
+		return fmt.Errorf("could not delete the product from wishlist")
+	}
+	return nil
+}
+
+func (store *MemoryUserStore) GetWishlistProducts(ctx context.Context, userId string) ([]*product_entity.GetProductRes, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	var wishlists []*memory.Wishlist
+	for _, wishlist := range store.db.Wishlists {
+		if wishlist.UserID == userId {
+			wishlists = append(wishlists, wishlist)
+		}
+	}
+	sort.Slice(wishlists, func(i, j int) bool {
+		return wishlists[i].CreatedAt.Before(wishlists[j].CreatedAt)
+	})
+	products := []*product_entity.GetProductRes{}
+	for _, wishlist := range wishlists {
+		product, ok := store.db.Products[wishlist.ProductID]
+		if !ok || !memory.Listed(product) {
+			continue
+		}
+		products = append(products, &product_entity.GetProductRes{
+			ID:            product.ID,
+			ProductName:   product.ProductName,
+			ProductImages: append([]string(nil), product.Images...),
+			Price:         product.Price,
+		})
+	}
+	return products, nil
+}
+
+func (store *MemoryUserStore) AddToCard(ctx context.Context, req *entity.AddToCartReq) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	inventory := store.variant(req)
+	if inventory == nil {
+		return sql.ErrNoRows
+	}
+	price, discountPrice := store.db.VariantPrice(store.db.Products[req.ProductID], inventory)
+	if discountPrice != nil {
+		price = *discountPrice
+	}
+	cart := store.cart(req.Owner)
+	var item *memory.CartItem
+	if cart != nil {
+		for _, cartItem := range store.db.CartItems {
+			if cartItem.CartID == cart.ID && cartItem.InventoryID == inventory.ID {
+				item = cartItem
+			}
+		}
+	}
+	quantity := req.Quantity
+	if item != nil {
+		quantity += item.Quantity
+	}
+	if quantity > inventory.Quantity {
+		return ErrInsufficientStock
+	}
+
+	now := time.Now()
+	if cart == nil {
+		cart = &memory.Cart{
+			ID:        uuid.NewString(),
+			CreatedAt: now,
+		}
+		if req.Owner.UserID != nil {
+			cart.UserID = *req.Owner.UserID
+		} else {
+			cart.DeviceID = req.Owner.DeviceID
+		}
+		store.db.Carts[cart.ID] = cart
+	}
+	// a variant already in the cart gets the quantity added, at the current price
+	if item == nil {
+		item = &memory.CartItem{
+			ID:          uuid.NewString(),
+			CartID:      cart.ID,
+			ProductID:   req.ProductID,
+			InventoryID: inventory.ID,
+			CreatedAt:   now,
+		}
+		store.db.CartItems[item.ID] = item
+	}
+	item.Quantity = quantity
+	item.Price = price
+	item.UpdatedAt = now
+	store.updateCartPrice(cart, now)
+	return nil
+}
+
+// updateCartPrice sets the price of the cart to the total of its items, the caller
+// must hold the lock.
+func (store *MemoryUserStore) updateCartPrice(cart *memory.Cart, now time.Time) {
+	var totalPrice float64
+	for _, item := range store.db.CartItems {
+		if item.CartID == cart.ID {
+			totalPrice += float64(item.Quantity) * item.Price
+		}
+	}
+	cart.Price = totalPrice
+	cart.UpdatedAt = now
+}
+
+func (store *MemoryUserStore) GetCartItems(ctx context.Context, owner entity.CartOwner) ([]*entity.CartItemRes, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	var items []*memory.CartItem
+	if cart := store.cart(owner); cart != nil {
+		for _, item := range store.db.CartItems {
+			if item.CartID == cart.ID {
+				items = append(items, item)
+			}
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].CreatedAt.Before(items[j].CreatedAt)
+	})
+	var products []*entity.CartItemRes
+	for _, item := range items {
+		product, ok := store.db.Products[item.ProductID]
+		if !ok {
+			continue
+		}
+		inventory, ok := store.db.Inventories[item.InventoryID]
+		if !ok {
+			continue
+		}
+		options := product_entity.Options{}
+		for option, value := range inventory.Options {
+			options[option] = value
+		}
+		image := memory.FirstImage(product)
+		if len(inventory.Images) > 0 {
+			image = inventory.Images[0]
+		}
+		products = append(products, &entity.CartItemRes{
+			CartID:            item.CartID,
+			CartItemID:        item.ID,
+			ImageURL:          image,
+			Name:              product.ProductName,
+			Size:              inventory.Size,
+			Price:             item.Price,
+			Quantity:          item.Quantity,
+			AvailableQuantity: inventory.Quantity,
+			InventoryID:       inventory.ID,
+			SKU:               inventory.SKU,
+			Options:           options,
+			Unavailable:       !memory.Listed(product),
+		})
+	}
+	return products, nil
+}
+
+func (store *MemoryUserStore) GetCartById(ctx context.Context, cartID string) (*entity.CartRes, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	cart, ok := store.db.Carts[cartID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &entity.CartRes{
+		CartID: cart.ID,
+		UserID: cart.UserID,
+		Price:  cart.Price,
+	}, nil
+}
+
+func (store *MemoryUserStore) CheckoutCartItems(ctx context.Context, req *entity.CheckoutCartItemsReq) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	cart, ok := store.db.Carts[req.CartID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	// every item is checked before any changes, like the rolled back transaction
+	for _, cartItem := range req.Data {
+		item, ok := store.db.CartItems[cartItem.CartItemID]
+		if !ok || item.CartID != req.CartID {
+			return sql.ErrNoRows
+		}
+		inventory, ok := store.db.Inventories[item.InventoryID]
+		if !ok || cartItem.Quantity > inventory.Quantity {
+			return ErrInsufficientStock
+		}
+	}
+	now := time.Now()
+	for _, cartItem := range req.Data {
+		item := store.db.CartItems[cartItem.CartItemID]
+		item.Quantity = cartItem.Quantity
+		item.UpdatedAt = now
+	}
+	store.updateCartPrice(cart, now)
+	return nil
+}
+
+func (store *MemoryUserStore) UpdateCartItemQuantity(ctx context.Context, req *entity.UpdateCartItemQuantityReq) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	if !store.ownsCartItem(req.CartItemID, req.Owner) {
+		return sql.ErrNoRows
+	}
+	item := store.db.CartItems[req.CartItemID]
+	now := time.Now()
+	if req.Quantity == 0 {
+		delete(store.db.CartItems, item.ID)
+	} else {
+		inventory, ok := store.db.Inventories[item.InventoryID]
+		if !ok || req.Quantity > inventory.Quantity {
+			return ErrInsufficientStock
+		}
+		item.Quantity = req.Quantity
+		item.UpdatedAt = now
+	}
+	store.updateCartPrice(store.db.Carts[item.CartID], now)
+	return nil
+}
+
+func (store *MemoryUserStore) CanDeleteCartItem(ctx context.Context, cartItemId string, owner entity.CartOwner) (bool, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	return store.ownsCartItem(cartItemId, owner), nil
+}
+
+func (store *MemoryUserStore) RemoveFromCart(ctx context.Context, cartItemId string, owner entity.CartOwner) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	if !store.ownsCartItem(cartItemId, owner) {
+		return sql.ErrNoRows
+	}
+	item := store.db.CartItems[cartItemId]
+	delete(store.db.CartItems, cartItemId)
+	store.updateCartPrice(store.db.Carts[item.CartID], time.Now())
+	return nil
+}
+
+func (store *MemoryUserStore) AddAddress(ctx context.Context, req *entity.AddAddressReq) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	now := time.Now()
+	country, city := req.Country, req.City
+	address := &memory.Address{
+		ID:          uuid.NewString(),
+		UserID:      req.UserID,
+		Title:       req.Title,
+		AddressLine: req.AddressLine,
+		Country:     &country,
+		City:        &city,
+		PostalCode:  req.PostalCode,
+		Landmark:    req.Landmark,
+		IsDefault:   req.IsDefault,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	store.db.Addresses[address.ID] = address
+	return nil
+}
+
+func (store *MemoryUserStore) GetAddresses(ctx context.Context, userId string) ([]*entity.GetAddressRes, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	var rows []*memory.Address
+	for _, address := range store.db.Addresses {
+		if address.UserID == userId {
+			rows = append(rows, address)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].CreatedAt.Before(rows[j].CreatedAt)
+	})
+	var addresses []*entity.GetAddressRes
+	for _, address := range rows {
+		addresses = append(addresses, &entity.GetAddressRes{
+			AddressID:   address.ID,
+			Title:       address.Title,
+			AddressLine: address.AddressLine,
+		})
+	}
+	return addresses, nil
+}
+
+func (store *MemoryUserStore) GetAddressById(ctx context.Context, addressID string) (*entity.GetAddressRes, error) {
+	store.db.Lock()
+	defer store.db.Unlock()
+	address, ok := store.db.Addresses[addressID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &entity.GetAddressRes{
+		AddressID:   address.ID,
+		UserID:      address.UserID,
+		Title:       address.Title,
+		AddressLine: address.AddressLine,
+		Country:     address.Country,
+		City:        address.City,
+		PostalCode:  address.PostalCode,
+		Landmark:    address.Landmark,
+	}, nil
+}
+
+func (store *MemoryUserStore) EditAddress(ctx context.Context, addressID string, req *entity.EditAddressReq) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	address, ok := store.db.Addresses[addressID]
+	if !ok {
+		return fmt.Errorf("couldnot update the address")
+	}
+	if req.Title != nil {
+		address.Title = *req.Title
+	}
+	if req.AddressLine != nil {
+		address.AddressLine = *req.AddressLine
+	}
+	if req.Country != nil {
+		address.Country = req.Country
+	}
+	if req.City != nil {
+		address.City = req.City
+	}
+	if req.PostalCode != nil {
+		address.PostalCode = req.PostalCode
+	}
+	if req.Landmark != nil {
+		address.Landmark = req.Landmark
+	}
+	if req.IsDefault != nil {
+		address.IsDefault = req.IsDefault
+	}
+	address.UpdatedAt = time.Now()
+	return nil
+}
+
+func (store *MemoryUserStore) DeleteAddress(ctx context.Context, addressID string) error {
+	store.db.Lock()
+	defer store.db.Unlock()
+	if _, ok := store.db.Addresses[addressID]; !ok {
+		return fmt.Errorf("could not delete the address")
+	}
+	delete(store.db.Addresses, addressID)
+	return nil
+}
+
+// cart returns the cart of the owner: the cart of the user, or else the guest cart
+// of the device. The caller must hold the lock.
+func (store *MemoryUserStore) cart(owner entity.CartOwner) *memory.Cart {
+	for _, cart := range store.db.Carts {
+		if owner.UserID != nil && cart.UserID == *owner.UserID ||
+			owner.UserID == nil && cart.UserID == "" && owner.DeviceID != nil && cart.DeviceID != nil && *cart.DeviceID == *owner.DeviceID {
+			return cart
+		}
+	}
+	return nil
+}
+
+// variant returns the variant of the listed product added to the cart, by its id or else
+// by its size. The caller must hold the lock.
+func (store *MemoryUserStore) variant(req *entity.AddToCartReq) *memory.Inventory {
+	if product, ok := store.db.Products[req.ProductID]; !ok || !memory.Listed(product) {
+		return nil
+	}
+	for _, inventory := range store.db.Inventories {
+		if inventory.ProductID != req.ProductID {
+			continue
+		}
+		if req.VariantID != nil && inventory.ID == *req.VariantID || req.VariantID == nil && inventory.Size == req.Size {
+			return inventory
+		}
+	}
+	return nil
+}
+
+// ownsCartItem reports whether the cart item is in the cart of the owner, the caller must hold the lock.
+func (store *MemoryUserStore) ownsCartItem(cartItemId string, owner entity.CartOwner) bool {
+	item, ok := store.db.CartItems[cartItemId]
+	if !ok {
+		return false
+	}
+	cart := store.cart(owner)
+	return cart != nil && item.CartID == cart.ID
+}
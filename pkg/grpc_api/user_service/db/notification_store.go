@@ -0,0 +1,62 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/grpc_api/user_service/db/entity"
+	"github.com/lib/pq"
+)
+
+func (store *UserStore) GetNotifications(ctx context.Context, userId string, unreadOnly bool, limit int) ([]*entity.NotificationRes, error) {
+	selectQuery := `
+	SELECT id, type, title, body, product_id, read_at, created_at
+	FROM notifications
+	WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)
+	ORDER BY created_at DESC, id
+	LIMIT $3;
+	`
+	rows, err := store.storage.DB.QueryContext(ctx, selectQuery, userId, unreadOnly, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []*entity.NotificationRes{}
+	for rows.Next() {
+		var notification entity.NotificationRes
+		err := rows.Scan(
+			&notification.ID,
+			&notification.Type,
+			&notification.Title,
+			&notification.Body,
+			&notification.ProductID,
+			&notification.ReadAt,
+			&notification.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, &notification)
+	}
+	return notifications, rows.Err()
+}
+
+func (store *UserStore) CountUnreadNotifications(ctx context.Context, userId string) (int64, error) {
+	selectQuery := `SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL;`
+	var count int64
+	err := store.storage.DB.QueryRowContext(ctx, selectQuery, userId).Scan(&count)
+	return count, err
+}
+
+func (store *UserStore) MarkNotificationsRead(ctx context.Context, userId string, ids []string) (int64, error) {
+	updateQuery := `
+	UPDATE notifications SET read_at = $3
+	WHERE user_id = $1 AND read_at IS NULL AND (cardinality($2::uuid[]) = 0 OR id = ANY($2::uuid[]));
+	`
+	res, err := store.storage.DB.ExecContext(ctx, updateQuery, userId, pq.StringArray(ids), time.Now())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
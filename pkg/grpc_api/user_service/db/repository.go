@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	product_entity "github.com/akmal4410/gestapo/pkg/grpc_api/product_service/db/entity"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/user_service/db/entity"
+)
+
+// UserRepository is the storage used by the user service,
+// implemented by UserStore on Postgres and by MemoryUserStore in memory.
+type UserRepository interface {
+	CheckDataExist(ctx context.Context, table, column, value string) (bool, error)
+	GetDiscount(ctx context.Context) (*entity.DiscountRes, error)
+	GetMerchants(ctx context.Context) ([]*entity.MerchantRes, error)
+	AlreadyInWishlist(ctx context.Context, req *entity.AddRemoveWishlistReq) (bool, error)
+	AddToWishlist(ctx context.Context, req *entity.AddRemoveWishlistReq) error
+	RemoveFromWishlist(ctx context.Context, req *entity.AddRemoveWishlistReq) error
+	GetWishlistProducts(ctx context.Context, userId string) ([]*product_entity.GetProductRes, error)
+	// AddToCard adds the variant to the cart of the owner, created when it has none, or
+	// adds to the quantity of the variant already in it. It returns sql.ErrNoRows when
+	// the variant is not found and ErrInsufficientStock when the stock is not enough.
+	AddToCard(ctx context.Context, req *entity.AddToCartReq) error
+	GetCartItems(ctx context.Context, owner entity.CartOwner) ([]*entity.CartItemRes, error)
+	GetCartById(ctx context.Context, cartID string) (*entity.CartRes, error)
+	// CheckoutCartItems sets the quantities of the items of the cart. It returns
+	// sql.ErrNoRows when an item is not in the cart and ErrInsufficientStock when the
+	// stock is not enough, and then changes none of them.
+	CheckoutCartItems(ctx context.Context, req *entity.CheckoutCartItemsReq) error
+	// UpdateCartItemQuantity sets the quantity of the item of the cart of the owner, or
+	// removes it for 0. It returns sql.ErrNoRows when the owner has no such item and
+	// ErrInsufficientStock when the stock is not enough.
+	UpdateCartItemQuantity(ctx context.Context, req *entity.UpdateCartItemQuantityReq) error
+	CanDeleteCartItem(ctx context.Context, cartItemId string, owner entity.CartOwner) (bool, error)
+	RemoveFromCart(ctx context.Context, cartItemId string, owner entity.CartOwner) error
+	AddAddress(ctx context.Context, req *entity.AddAddressReq) error
+	GetAddresses(ctx context.Context, userId string) ([]*entity.GetAddressRes, error)
+	GetAddressById(ctx context.Context, addressID string) (*entity.GetAddressRes, error)
+	EditAddress(ctx context.Context, addressID string, req *entity.EditAddressReq) error
+	DeleteAddress(ctx context.Context, addressID string) error
+	// GetNotifications returns the notifications of the user, the newest first
+	GetNotifications(ctx context.Context, userId string, unreadOnly bool, limit int) ([]*entity.NotificationRes, error)
+	CountUnreadNotifications(ctx context.Context, userId string) (int64, error)
+	// MarkNotificationsRead marks the notifications of the user read, every unread one
+	// when ids is empty, and returns how many it marked
+	MarkNotificationsRead(ctx context.Context, userId string, ids []string) (int64, error)
+}
+
+var (
+	// ErrInsufficientStock is returned when a cart would hold more of a variant than its stock.
+	ErrInsufficientStock = errors.New("insufficient stock")
+)
+
+var (
+	_ UserRepository = (*UserStore)(nil)
+	_ UserRepository = (*MemoryUserStore)(nil)
+)
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"time"
 
@@ -23,9 +24,9 @@ func NewUserStore(storage *database.Storage) *UserStore {
 	}
 }
 
-func (store *UserStore) CheckDataExist(table, column, value string) (bool, error) {
+func (store *UserStore) CheckDataExist(ctx context.Context, table, column, value string) (bool, error) {
 	checkQuery := fmt.Sprintf(`SELECT * FROM %s WHERE %s = $1;`, table, column)
-	res, err := store.storage.DB.Exec(checkQuery, value)
+	res, err := store.storage.DB.ExecContext(ctx, checkQuery, value)
 	if err != nil {
 		return false, err
 	}
@@ -38,7 +39,7 @@ func (store *UserStore) CheckDataExist(table, column, value string) (bool, error
 	return result != 0, nil
 }
 
-func (store *UserStore) GetDiscount() (*entity.DiscountRes, error) {
+func (store *UserStore) GetDiscount(ctx context.Context) (*entity.DiscountRes, error) {
 	selectQuery := `
 	SELECT 
     p.id AS product_id,
@@ -48,12 +49,12 @@ func (store *UserStore) GetDiscount() (*entity.DiscountRes, error) {
     p.images[1] AS image
 	FROM products p
 	JOIN discounts d ON p.discount_id = d.id
-	WHERE d.end_time > NOW()
+	WHERE d.end_time > NOW() AND listed(p)
 	ORDER BY d.percent DESC
 	LIMIT 1;
 	`
 
-	rows := store.storage.DB.QueryRow(selectQuery)
+	rows := store.storage.DB.QueryRowContext(ctx, selectQuery)
 	if rows.Err() != nil {
 		return nil, rows.Err()
 	}
@@ -71,7 +72,7 @@ func (store *UserStore) GetDiscount() (*entity.DiscountRes, error) {
 	return &discount, nil
 }
 
-func (store *UserStore) GetMerchants() ([]*entity.MerchantRes, error) {
+func (store *UserStore) GetMerchants(ctx context.Context) ([]*entity.MerchantRes, error) {
 	selectQuery := `
 	SELECT 
 	id, 
@@ -81,7 +82,7 @@ func (store *UserStore) GetMerchants() ([]*entity.MerchantRes, error) {
 	WHERE user_type = $1
 	LIMIT 7;
 	`
-	rows, err := store.storage.DB.Query(selectQuery, utils.MERCHANT)
+	rows, err := store.storage.DB.QueryContext(ctx, selectQuery, utils.MERCHANT)
 	if err != nil {
 		return nil, err
 	}
@@ -106,12 +107,12 @@ func (store *UserStore) GetMerchants() ([]*entity.MerchantRes, error) {
 	return merchants, nil
 }
 
-func (store *UserStore) AlreadyInWishlist(req *entity.AddRemoveWishlistReq) (bool, error) {
+func (store *UserStore) AlreadyInWishlist(ctx context.Context, req *entity.AddRemoveWishlistReq) (bool, error) {
 	checkQuery := `
 	SELECT user_id, product_id FROM wishlists
 	WHERE user_id = $1 AND product_id = $2;
 	`
-	res, err := store.storage.DB.Exec(checkQuery, req.UserID, req.ProductID)
+	res, err := store.storage.DB.ExecContext(ctx, checkQuery, req.UserID, req.ProductID)
 	if err != nil {
 		return false, err
 	}
@@ -124,7 +125,7 @@ func (store *UserStore) AlreadyInWishlist(req *entity.AddRemoveWishlistReq) (boo
 	return result != 0, nil
 }
 
-func (store *UserStore) AddToWishlist(req *entity.AddRemoveWishlistReq) error {
+func (store *UserStore) AddToWishlist(ctx context.Context, req *entity.AddRemoveWishlistReq) error {
 	createdAt := time.Now()
 	updatedAt := time.Now()
 
@@ -136,20 +137,20 @@ func (store *UserStore) AddToWishlist(req *entity.AddRemoveWishlistReq) error {
 	INSERT INTO wishlists (id, user_id, product_id, created_at, updated_at)
 	VALUES ($1, $2, $3, $4, $5);
 	`
-	_, err = store.storage.DB.Exec(insertQuery, uuId, req.UserID, req.ProductID, createdAt, updatedAt)
+	_, err = store.storage.DB.ExecContext(ctx, insertQuery, uuId, req.UserID, req.ProductID, createdAt, updatedAt)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (store *UserStore) RemoveFromWishlist(req *entity.AddRemoveWishlistReq) error {
+func (store *UserStore) RemoveFromWishlist(ctx context.Context, req *entity.AddRemoveWishlistReq) error {
 	deleteQuery := `
         DELETE FROM wishlists
         WHERE user_id = $1 AND product_id = $2;
     `
 
-	res, err := store.storage.DB.Exec(deleteQuery, req.UserID, req.ProductID)
+	res, err := store.storage.DB.ExecContext(ctx, deleteQuery, req.UserID, req.ProductID)
 	if err != nil {
 		return err
 	}
@@ -163,7 +164,7 @@ func (store *UserStore) RemoveFromWishlist(req *entity.AddRemoveWishlistReq) err
 	return nil
 }
 
-func (store *UserStore) GetWishlistProducts(userId string) ([]*product_entity.GetProductRes, error) {
+func (store *UserStore) GetWishlistProducts(ctx context.Context, userId string) ([]*product_entity.GetProductRes, error) {
 	var products []*product_entity.GetProductRes
 
 	selectQuery := `
@@ -176,10 +177,10 @@ func (store *UserStore) GetWishlistProducts(userId string) ([]*product_entity.Ge
     products p
 	LEFT JOIN
     wishlists w ON p.id = w.product_id
-	WHERE w.user_id = $1;
+	WHERE w.user_id = $1 AND listed(p);
 	`
 
-	rows, err := store.storage.DB.Query(selectQuery, userId)
+	rows, err := store.storage.DB.QueryContext(ctx, selectQuery, userId)
 	if err != nil {
 		return nil, err
 	}
@@ -214,131 +215,133 @@ func (store *UserStore) GetWishlistProducts(userId string) ([]*product_entity.Ge
 	return products, nil
 }
 
-func (store *UserStore) CreateUserCart(req *entity.AddToCartReq) error {
+// cartOwnerCondition selects the cart of the owner in the queries taking the user
+// id as $1 and the device id as $2: the cart of the user, or else the guest cart of
+// the device.
+const cartOwnerCondition = `(c.user_id = $1 OR ($1::uuid IS NULL AND c.user_id IS NULL AND c.device_id = $2))`
+
+func (store *UserStore) AddToCard(ctx context.Context, req *entity.AddToCartReq) error {
 	createdAt := time.Now()
 	updatedAt := time.Now()
 
-	uuId, err := uuid.NewRandom()
-	if err != nil {
-		return err
-	}
-	insertQuery := `
-	INSERT INTO carts (id, user_id, price, created_at, updated_at)
-	VALUES ($1, $2, $3, $4, $5);
-	`
-	_, err = store.storage.DB.Exec(insertQuery, uuId, req.UserID, 0, createdAt, updatedAt)
+	tx, err := store.storage.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
-	return nil
-}
-
-func (store *UserStore) AddToCard(req *entity.AddToCartReq) error {
-	createdAt := time.Now()
-	updatedAt := time.Now()
 
-	ctx := context.Background()
-
-	tx, err := store.storage.DB.BeginTx(ctx, nil)
+	insertCartQuery := `
+	INSERT INTO carts (id, user_id, device_id, price, created_at, updated_at)
+	VALUES ($1, $2, CASE WHEN $2::uuid IS NULL THEN $3 END, 0, $4, $4)
+	ON CONFLICT DO NOTHING;
+	`
+	_, err = tx.ExecContext(ctx, insertCartQuery, uuid.New(), req.Owner.UserID, req.Owner.DeviceID, createdAt)
 	if err != nil {
+		tx.Rollback()
 		return err
 	}
 
 	var cartID string
-
-	selectCartIDQuery := `SELECT id FROM carts WHERE user_id = $1;`
-	rows := tx.QueryRow(selectCartIDQuery, req.UserID)
-	if rows.Err() != nil {
-		tx.Rollback()
-		return rows.Err()
-	}
-	err = rows.Scan(&cartID)
+	selectCartIDQuery := `SELECT c.id FROM carts c WHERE ` + cartOwnerCondition + ` FOR UPDATE;`
+	err = tx.QueryRowContext(ctx, selectCartIDQuery, req.Owner.UserID, req.Owner.DeviceID).Scan(&cartID)
 	if err != nil {
 		tx.Rollback()
 		return err
 	}
 
 	var inventoryID string
+	var price float64
+	var stock int32
 
-	selectInventoryIDQuery := `SELECT id FROM inventories WHERE product_id = $1 AND size = $2;`
-	rows = store.storage.DB.QueryRow(selectInventoryIDQuery, req.ProductID, req.Size)
-	if rows.Err() != nil {
-		tx.Rollback()
-		return rows.Err()
-	}
-	err = rows.Scan(&inventoryID)
+	// the variant is picked by its id, or by its size for the clients without variants
+	selectVariantQuery := `
+	SELECT
+	i.id,
+	CASE
+		WHEN d.end_time IS NOT NULL AND d.end_time > NOW()
+		THEN COALESCE(i.price, p.price) - (COALESCE(i.price, p.price) * d.percent / 100)
+		ELSE COALESCE(i.price, p.price)
+	END AS price,
+	i.quantity
+	FROM inventories i
+	JOIN products p ON i.product_id = p.id AND listed(p)
+	LEFT JOIN discounts d ON p.discount_id = d.id
+	WHERE i.product_id = $1 AND (i.id = $2::uuid OR ($2::uuid IS NULL AND i.size = $3));
+	`
+	err = tx.QueryRowContext(ctx, selectVariantQuery, req.ProductID, req.VariantID, req.Size).Scan(&inventoryID, &price, &stock)
 	if err != nil {
 		tx.Rollback()
 		return err
 	}
 
-	uuId, err := uuid.NewRandom()
-	if err != nil {
-		return err
-	}
-	insertProductQuery := `
-        INSERT INTO cart_items
-        (id, cart_id, product_id, inventory_id, quantity, price, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
-        `
-
-	_, err = tx.Exec(insertProductQuery, uuId, cartID, req.ProductID, inventoryID, req.Quantity, req.Price, createdAt, updatedAt)
+	// a variant already in the cart gets the quantity added, at the current price
+	var quantity int32
+	upsertItemQuery := `
+	INSERT INTO cart_items
+	(id, cart_id, product_id, inventory_id, quantity, price, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	ON CONFLICT (cart_id, inventory_id) DO UPDATE
+	SET quantity = cart_items.quantity + EXCLUDED.quantity, price = EXCLUDED.price, updated_at = EXCLUDED.updated_at
+	RETURNING quantity;
+	`
+	err = tx.QueryRowContext(ctx, upsertItemQuery, uuid.New(), cartID, req.ProductID, inventoryID, req.Quantity, price, createdAt, updatedAt).Scan(&quantity)
 	if err != nil {
 		tx.Rollback()
 		return err
 	}
-
-	// Calculate total price of items in the cart
-	totalPriceQuery := `
-        SELECT SUM(quantity * price) FROM cart_items WHERE cart_id = $1;
-    `
-	var totalPrice float64
-	err = tx.QueryRow(totalPriceQuery, cartID).Scan(&totalPrice)
-	if err != nil {
+	if quantity > stock {
 		tx.Rollback()
-		return err
+		return ErrInsufficientStock
 	}
 
-	// Update price in the carts table
-	updateQuery := `
-        UPDATE carts
-        SET price = $1, updated_at = $2
-        WHERE id = $3;
-    `
-	_, err = tx.Exec(updateQuery, totalPrice, updatedAt, cartID)
+	err = updateCartPrice(ctx, tx, cartID, updatedAt)
 	if err != nil {
 		tx.Rollback()
 		return err
 	}
+	return tx.Commit()
+}
 
-	tx.Commit()
-	return nil
+// updateCartPrice sets the price of the cart to the total of its items.
+func updateCartPrice(ctx context.Context, tx *sql.Tx, cartID string, updatedAt time.Time) error {
+	updateQuery := `
+	UPDATE carts
+	SET price = COALESCE((SELECT SUM(quantity * price) FROM cart_items WHERE cart_id = $1), 0), updated_at = $2
+	WHERE id = $1;
+	`
+	_, err := tx.ExecContext(ctx, updateQuery, cartID, updatedAt)
+	return err
 }
 
-func (store *UserStore) GetCartItems(userId string) ([]*entity.CartItemRes, error) {
+func (store *UserStore) GetCartItems(ctx context.Context, owner entity.CartOwner) ([]*entity.CartItemRes, error) {
 	var products []*entity.CartItemRes
 
 	selectQuery := `
 	SELECT
 	p.images AS product_images,
+	i.images AS variant_images,
 	p.product_name AS product_name,
+	i.id AS variant_id,
+	i.sku AS sku,
+	i.options AS options,
 	i.size AS size,
 	i.quantity AS available_quantity,
 	ci.quantity AS quantity,
 	ci.price AS price,
 	ci.id AS cart_item_id,
-	c.id AS cart_id
+	c.id AS cart_id,
+	NOT listed(p) AS unavailable
 	FROM
-    products p
-	LEFT JOIN
-    inventories i ON p.id = i.product_id
-	LEFT JOIN
-    cart_items ci ON p.id = ci.product_id
-	LEFT JOIN
+    cart_items ci
+	JOIN
     carts c ON ci.cart_id = c.id
-	WHERE c.user_id = $1 AND ci.inventory_id = i.id;
+	JOIN
+    products p ON ci.product_id = p.id
+	JOIN
+    inventories i ON ci.inventory_id = i.id
+	WHERE ` + cartOwnerCondition + `
+	ORDER BY ci.created_at;
 	`
-	rows, err := store.storage.DB.Query(selectQuery, userId)
+	rows, err := store.storage.DB.QueryContext(ctx, selectQuery, owner.UserID, owner.DeviceID)
 	if err != nil {
 		return nil, err
 	}
@@ -346,22 +349,30 @@ func (store *UserStore) GetCartItems(userId string) ([]*entity.CartItemRes, erro
 
 	for rows.Next() {
 		var product entity.CartItemRes
-		var images pq.StringArray
+		var images, variantImages pq.StringArray
 
 		err := rows.Scan(
 			&images,
+			&variantImages,
 			&product.Name,
+			&product.InventoryID,
+			&product.SKU,
+			&product.Options,
 			&product.Size,
 			&product.AvailableQuantity,
 			&product.Quantity,
 			&product.Price,
 			&product.CartItemID,
 			&product.CartID,
+			&product.Unavailable,
 		)
 		if err != nil {
 			return nil, err
 		}
-		if len(images) > 0 {
+		// the image of the variant, or the one of the product for the variants without images
+		if len(variantImages) > 0 {
+			product.ImageURL = variantImages[0]
+		} else if len(images) > 0 {
 			product.ImageURL = images[0]
 		}
 		products = append(products, &product)
@@ -375,13 +386,13 @@ func (store *UserStore) GetCartItems(userId string) ([]*entity.CartItemRes, erro
 	return products, nil
 }
 
-func (store *UserStore) GetCartById(cartID string) (*entity.CartRes, error) {
+func (store *UserStore) GetCartById(ctx context.Context, cartID string) (*entity.CartRes, error) {
 
 	selectQuery := `
-	SELECT id, user_id, price FROM carts WHERE id = $1;
+	SELECT id, COALESCE(user_id::text, ''), price FROM carts WHERE id = $1;
 	`
 
-	row := store.storage.DB.QueryRow(selectQuery, cartID)
+	row := store.storage.DB.QueryRowContext(ctx, selectQuery, cartID)
 	if row.Err() != nil {
 		return nil, row.Err()
 	}
@@ -397,35 +408,106 @@ func (store *UserStore) GetCartById(cartID string) (*entity.CartRes, error) {
 	return &cart, nil
 }
 
-func (store *UserStore) CheckoutCartItems(cartItems []*entity.CheckoutReq) error {
-	for _, cartItem := range cartItems {
-		updateQuery := `
-		UPDATE cart_items
-		SET quantity = $2, updated_at = $3
-		WHERE id = $1;
-		`
-		updatedAt := time.Now()
-		res, err := store.storage.DB.Exec(updateQuery, cartItem.CartItemID, cartItem.Quantity, updatedAt)
+func (store *UserStore) CheckoutCartItems(ctx context.Context, req *entity.CheckoutCartItemsReq) error {
+	updatedAt := time.Now()
+
+	tx, err := store.storage.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	selectQuery := `
+	SELECT i.quantity
+	FROM cart_items ci
+	JOIN inventories i ON ci.inventory_id = i.id
+	WHERE ci.id = $1 AND ci.cart_id = $2
+	FOR UPDATE OF ci;
+	`
+	for _, cartItem := range req.Data {
+		var stock int32
+		err = tx.QueryRowContext(ctx, selectQuery, cartItem.CartItemID, req.CartID).Scan(&stock)
 		if err != nil {
+			tx.Rollback()
 			return err
 		}
-		_, err = res.RowsAffected()
+		err = setCartItemQuantity(ctx, tx, req.CartID, cartItem.CartItemID, cartItem.Quantity, stock, updatedAt)
 		if err != nil {
+			tx.Rollback()
 			return err
 		}
 	}
-	return nil
+
+	err = updateCartPrice(ctx, tx, req.CartID, updatedAt)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (store *UserStore) UpdateCartItemQuantity(ctx context.Context, req *entity.UpdateCartItemQuantityReq) error {
+	updatedAt := time.Now()
+
+	tx, err := store.storage.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	var cartID string
+	var stock int32
+	selectQuery := `
+	SELECT c.id, i.quantity
+	FROM cart_items ci
+	JOIN carts c ON ci.cart_id = c.id
+	JOIN inventories i ON ci.inventory_id = i.id
+	WHERE ci.id = $3 AND ` + cartOwnerCondition + `
+	FOR UPDATE OF ci;
+	`
+	err = tx.QueryRowContext(ctx, selectQuery, req.Owner.UserID, req.Owner.DeviceID, req.CartItemID).Scan(&cartID, &stock)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = setCartItemQuantity(ctx, tx, cartID, req.CartItemID, req.Quantity, stock, updatedAt)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = updateCartPrice(ctx, tx, cartID, updatedAt)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
 }
 
-func (store *UserStore) CanDeleteCartItem(cartItemId, userId string) (bool, error) {
+// setCartItemQuantity sets the quantity of the item of the cart locked by the
+// caller, up to the stock of its variant, or removes the item for 0. The caller
+// updates the price of the cart.
+func setCartItemQuantity(ctx context.Context, tx *sql.Tx, cartID, cartItemID string, quantity, stock int32, updatedAt time.Time) error {
+	if quantity == 0 {
+		_, err := tx.ExecContext(ctx, `DELETE FROM cart_items WHERE id = $1 AND cart_id = $2;`, cartItemID, cartID)
+		return err
+	}
+	if quantity > stock {
+		return ErrInsufficientStock
+	}
+	updateQuery := `UPDATE cart_items SET quantity = $3, updated_at = $4 WHERE id = $1 AND cart_id = $2;`
+	_, err := tx.ExecContext(ctx, updateQuery, cartItemID, cartID, quantity, updatedAt)
+	return err
+}
+
+func (store *UserStore) CanDeleteCartItem(ctx context.Context, cartItemId string, owner entity.CartOwner) (bool, error) {
 	query := `
         SELECT COUNT(ci.id)
         FROM cart_items ci
         JOIN carts c ON ci.cart_id = c.id
-        WHERE ci.id = $1 AND c.user_id = $2
+        WHERE ci.id = $3 AND ` + cartOwnerCondition + `
     `
 	var count int
-	err := store.storage.DB.QueryRow(query, cartItemId, userId).Scan(&count)
+	err := store.storage.DB.QueryRowContext(ctx, query, owner.UserID, owner.DeviceID, cartItemId).Scan(&count)
 	if err != nil {
 		fmt.Println("Error executing query:", err)
 		return false, err
@@ -433,28 +515,34 @@ func (store *UserStore) CanDeleteCartItem(cartItemId, userId string) (bool, erro
 	return count > 0, nil
 }
 
-func (store *UserStore) RemoveFromCart(cartItemId, userId string) error {
+func (store *UserStore) RemoveFromCart(ctx context.Context, cartItemId string, owner entity.CartOwner) error {
+	tx, err := store.storage.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
 	deleteQuery := `
         DELETE FROM cart_items
-        WHERE id = $1
-		AND cart_id IN (SELECT id FROM carts WHERE user_id = $2);
+        WHERE id = $3
+		AND cart_id IN (SELECT c.id FROM carts c WHERE ` + cartOwnerCondition + `)
+		RETURNING cart_id;
     `
-
-	res, err := store.storage.DB.Exec(deleteQuery, cartItemId, userId)
+	var cartID string
+	err = tx.QueryRowContext(ctx, deleteQuery, owner.UserID, owner.DeviceID, cartItemId).Scan(&cartID)
 	if err != nil {
+		tx.Rollback()
 		return err
 	}
-	n, err := res.RowsAffected()
+
+	err = updateCartPrice(ctx, tx, cartID, time.Now())
 	if err != nil {
+		tx.Rollback()
 		return err
 	}
-	if n == 0 {
-		return fmt.Errorf("not deleted")
-	}
-	return nil
+	return tx.Commit()
 }
 
-func (store *UserStore) AddAddress(req *entity.AddAddressReq) error {
+func (store *UserStore) AddAddress(ctx context.Context, req *entity.AddAddressReq) error {
 	createdAt := time.Now()
 	updatedAt := time.Now()
 
@@ -467,14 +555,14 @@ func (store *UserStore) AddAddress(req *entity.AddAddressReq) error {
 	(id, user_id, title, address_line, country, city, postal_code, landmark, is_default, created_at, updated_at)
 	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);
 	`
-	_, err = store.storage.DB.Exec(insertQuery, uuId, req.UserID, req.Title, req.AddressLine, req.Country, req.City, req.PostalCode, req.Landmark, req.IsDefault, createdAt, updatedAt)
+	_, err = store.storage.DB.ExecContext(ctx, insertQuery, uuId, req.UserID, req.Title, req.AddressLine, req.Country, req.City, req.PostalCode, req.Landmark, req.IsDefault, createdAt, updatedAt)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (store *UserStore) GetAddresses(userId string) ([]*entity.GetAddressRes, error) {
+func (store *UserStore) GetAddresses(ctx context.Context, userId string) ([]*entity.GetAddressRes, error) {
 	var addresses []*entity.GetAddressRes
 	// , country, city, postal_code, landmark
 	selectQuery := `
@@ -482,7 +570,7 @@ func (store *UserStore) GetAddresses(userId string) ([]*entity.GetAddressRes, er
 	FROM addresses
 	WHERE user_id = $1;
 	`
-	rows, err := store.storage.DB.Query(selectQuery, userId)
+	rows, err := store.storage.DB.QueryContext(ctx, selectQuery, userId)
 	if err != nil {
 		return nil, err
 	}
@@ -509,7 +597,7 @@ func (store *UserStore) GetAddresses(userId string) ([]*entity.GetAddressRes, er
 	return addresses, nil
 }
 
-func (store *UserStore) GetAddressById(addressID string) (*entity.GetAddressRes, error) {
+func (store *UserStore) GetAddressById(ctx context.Context, addressID string) (*entity.GetAddressRes, error) {
 
 	selectQuery := `
 	SELECT id, user_id, title, address_line, country, city, postal_code, landmark
@@ -517,7 +605,7 @@ func (store *UserStore) GetAddressById(addressID string) (*entity.GetAddressRes,
 	WHERE id = $1;
 	`
 
-	rows := store.storage.DB.QueryRow(selectQuery, addressID)
+	rows := store.storage.DB.QueryRowContext(ctx, selectQuery, addressID)
 	if rows.Err() != nil {
 		return nil, rows.Err()
 	}
@@ -538,7 +626,7 @@ func (store *UserStore) GetAddressById(addressID string) (*entity.GetAddressRes,
 	return &address, nil
 }
 
-func (store *UserStore) EditAddress(addressID string, req *entity.EditAddressReq) error {
+func (store *UserStore) EditAddress(ctx context.Context, addressID string, req *entity.EditAddressReq) error {
 	updateQuery := `
 	UPDATE addresses
 	SET title = COALESCE($2, title),
@@ -552,7 +640,7 @@ func (store *UserStore) EditAddress(addressID string, req *entity.EditAddressReq
 	WHERE id = $1;
 	`
 	updatedAt := time.Now()
-	res, err := store.storage.DB.Exec(updateQuery, addressID, req.Title, req.AddressLine, req.Country, req.City, req.PostalCode, req.Landmark, req.IsDefault, updatedAt)
+	res, err := store.storage.DB.ExecContext(ctx, updateQuery, addressID, req.Title, req.AddressLine, req.Country, req.City, req.PostalCode, req.Landmark, req.IsDefault, updatedAt)
 	if err != nil {
 		return err
 	}
@@ -566,13 +654,13 @@ func (store *UserStore) EditAddress(addressID string, req *entity.EditAddressReq
 	return nil
 }
 
-func (store *UserStore) DeleteAddress(addressID string) error {
+func (store *UserStore) DeleteAddress(ctx context.Context, addressID string) error {
 	deleteQuery := `
         DELETE FROM addresses
         WHERE id = $1;
     `
 
-	res, err := store.storage.DB.Exec(deleteQuery, addressID)
+	res, err := store.storage.DB.ExecContext(ctx, deleteQuery, addressID)
 	if err != nil {
 		return err
 	}
@@ -25,17 +25,7 @@ func RunGRPCService(ctx context.Context, storage *database.Storage, config *conf
 	}
 
 	service := service.NewUserService(storage, config, log, tokenMaker)
-	interceptor := interceptor.NewInterceptor(tokenMaker, log)
-	grpcServer := grpc.NewServer(
-		grpc.ChainUnaryInterceptor(
-			interceptor.AccessMiddleware(),
-			interceptor.UserRoleMiddleware(),
-		),
-	)
-
-	proto.RegisterUserServieServer(grpcServer, service)
-	log.LogInfo("Registreing for reflection")
-	reflection.Register(grpcServer)
+	grpcServer := NewGRPCServer(service, tokenMaker, log)
 	port := ":" + config.ServerAddress.User.Port
 
 	lis, err := net.Listen("tcp", port)
@@ -58,3 +48,20 @@ func RunGRPCService(ctx context.Context, storage *database.Storage, config *conf
 	log.LogInfo("Start gRPC server at ", lis.Addr().String())
 	return grpcServer.Serve(lis)
 }
+
+// NewGRPCServer creates the gRPC server of the user service with its interceptors.
+func NewGRPCServer(service proto.UserServieServer, tokenMaker token.Maker, log logger.Logger) *grpc.Server {
+	interceptor := interceptor.NewInterceptor(tokenMaker, log)
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			interceptor.LoggingMiddleware(),
+			interceptor.AccessMiddleware(),
+			interceptor.UserRoleMiddleware(),
+		),
+	)
+
+	proto.RegisterUserServieServer(grpcServer, service)
+	log.LogInfo("Registreing for reflection")
+	reflection.Register(grpcServer)
+	return grpcServer
+}
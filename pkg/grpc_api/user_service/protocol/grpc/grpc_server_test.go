@@ -0,0 +1,622 @@
+package grpc_test
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akmal4410/gestapo/internal/database/memory"
+	"github.com/akmal4410/gestapo/internal/testenv"
+	"github.com/akmal4410/gestapo/pkg/api/proto"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func assertCode(t *testing.T, err error, code codes.Code) {
+	t.Helper()
+	if status.Code(err) != code {
+		t.Fatalf("expected %s, got %v", code, err)
+	}
+}
+
+func TestRequiresUserRole(t *testing.T) {
+	env := testenv.New(t)
+	client := env.UserClient(t)
+	_, merchantToken := env.AddUser(t, "merchant", utils.MERCHANT)
+
+	_, err := client.GetWishlist(context.Background(), &proto.Request{})
+	assertCode(t, err, codes.Unauthenticated)
+
+	_, err = client.GetWishlist(testenv.WithToken(context.Background(), merchantToken), &proto.Request{})
+	assertCode(t, err, codes.PermissionDenied)
+
+	// the addresses are readable by every user type
+	_, err = client.GetAddresses(testenv.WithToken(context.Background(), merchantToken), &proto.Request{})
+	if err != nil {
+		t.Fatalf("GetAddresses: %v", err)
+	}
+}
+
+func TestGetHome(t *testing.T) {
+	env := testenv.New(t)
+	client := env.UserClient(t)
+	merchant, _ := env.AddUser(t, "merchant", utils.MERCHANT)
+	_, userToken := env.AddUser(t, "user", utils.USER)
+	category := env.AddCategory(t, "Shoes")
+	env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5, 8)
+
+	res, err := client.GetHome(testenv.WithToken(context.Background(), userToken), &proto.Request{})
+	if err != nil {
+		t.Fatalf("GetHome: %v", err)
+	}
+	if res.Data.Discount != nil || len(res.Data.Merchants) != 1 || len(res.Data.Products) != 1 || len(res.Data.Recommended) != 1 {
+		t.Fatalf("unexpected home %v", res.Data)
+	}
+}
+
+func TestWishlist(t *testing.T) {
+	env := testenv.New(t)
+	client := env.UserClient(t)
+	merchant, _ := env.AddUser(t, "merchant", utils.MERCHANT)
+	_, userToken := env.AddUser(t, "user", utils.USER)
+	category := env.AddCategory(t, "Shoes")
+	product := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5, 8)
+	ctx := testenv.WithToken(context.Background(), userToken)
+
+	_, err := client.AddRemoveWishlist(ctx, &proto.AddRemoveWishlistRequest{Action: "add", ProductId: product.ID})
+	assertCode(t, err, codes.InvalidArgument)
+
+	_, err = client.AddRemoveWishlist(ctx, &proto.AddRemoveWishlistRequest{Action: utils.ADD_WISHLIST, ProductId: "missing"})
+	assertCode(t, err, codes.NotFound)
+
+	if _, err = client.AddRemoveWishlist(ctx, &proto.AddRemoveWishlistRequest{Action: utils.ADD_WISHLIST, ProductId: product.ID}); err != nil {
+		t.Fatalf("AddRemoveWishlist: %v", err)
+	}
+	_, err = client.AddRemoveWishlist(ctx, &proto.AddRemoveWishlistRequest{Action: utils.ADD_WISHLIST, ProductId: product.ID})
+	assertCode(t, err, codes.AlreadyExists)
+
+	res, err := client.GetWishlist(ctx, &proto.Request{})
+	if err != nil {
+		t.Fatalf("GetWishlist: %v", err)
+	}
+	if len(res.Data) != 1 || res.Data[0].Id != product.ID {
+		t.Fatalf("unexpected wishlist %v", res.Data)
+	}
+
+	if _, err = client.AddRemoveWishlist(ctx, &proto.AddRemoveWishlistRequest{Action: utils.REMOVE_WISHLIST, ProductId: product.ID}); err != nil {
+		t.Fatalf("AddRemoveWishlist: %v", err)
+	}
+	_, err = client.AddRemoveWishlist(ctx, &proto.AddRemoveWishlistRequest{Action: utils.REMOVE_WISHLIST, ProductId: product.ID})
+	assertCode(t, err, codes.NotFound)
+}
+
+func TestNotifications(t *testing.T) {
+	env := testenv.New(t)
+	client := env.UserClient(t)
+	user, userToken := env.AddUser(t, "user", utils.USER)
+	other, _ := env.AddUser(t, "other", utils.USER)
+	ctx := testenv.WithToken(context.Background(), userToken)
+
+	env.DB.Lock()
+	now := time.Now()
+	for i, userID := range []string{user.ID, user.ID, user.ID, other.ID} {
+		id := uuid.NewString()
+		env.DB.Notifications[id] = &memory.Notification{
+			ID:        id,
+			UserID:    userID,
+			Type:      utils.NotificationPriceDrop,
+			Title:     "Price drop",
+			Body:      strconv.Itoa(i),
+			CreatedAt: now.Add(time.Duration(i) * time.Minute),
+		}
+	}
+	env.DB.Unlock()
+
+	res, err := client.GetNotifications(ctx, &proto.GetNotificationsRequest{Limit: 2})
+	if err != nil {
+		t.Fatalf("GetNotifications: %v", err)
+	}
+	if len(res.Data) != 2 || res.Data[0].Body != "2" || res.Data[1].Body != "1" || res.UnreadCount != 3 {
+		t.Fatalf("expected the newest notifications of the user, got %v", res)
+	}
+
+	if _, err = client.MarkNotificationsRead(ctx, &proto.MarkNotificationsReadRequest{NotificationIds: []string{res.Data[0].Id}}); err != nil {
+		t.Fatalf("MarkNotificationsRead: %v", err)
+	}
+	res, err = client.GetNotifications(ctx, &proto.GetNotificationsRequest{UnreadOnly: true})
+	if err != nil {
+		t.Fatalf("GetNotifications: %v", err)
+	}
+	if len(res.Data) != 2 || res.Data[0].Body != "1" || res.UnreadCount != 2 {
+		t.Fatalf("expected the unread notifications, got %v", res)
+	}
+
+	if _, err = client.MarkNotificationsRead(ctx, &proto.MarkNotificationsReadRequest{}); err != nil {
+		t.Fatalf("MarkNotificationsRead: %v", err)
+	}
+	res, err = client.GetNotifications(ctx, &proto.GetNotificationsRequest{})
+	if err != nil {
+		t.Fatalf("GetNotifications: %v", err)
+	}
+	if len(res.Data) != 3 || res.UnreadCount != 0 || res.Data[2].ReadAt == nil {
+		t.Fatalf("expected every notification read, got %v", res)
+	}
+	env.DB.Lock()
+	for _, notification := range env.DB.Notifications {
+		if notification.UserID == other.ID && notification.ReadAt != nil {
+			t.Errorf("the notification of the other user was marked read")
+		}
+	}
+	env.DB.Unlock()
+
+	_, err = client.MarkNotificationsRead(ctx, &proto.MarkNotificationsReadRequest{NotificationIds: []string{"bad"}})
+	assertCode(t, err, codes.InvalidArgument)
+	_, err = client.GetNotifications(ctx, &proto.GetNotificationsRequest{Limit: 101})
+	assertCode(t, err, codes.InvalidArgument)
+}
+
+func TestAddresses(t *testing.T) {
+	env := testenv.New(t)
+	client := env.UserClient(t)
+	_, userToken := env.AddUser(t, "user", utils.USER)
+	_, otherToken := env.AddUser(t, "other", utils.USER)
+	ctx := testenv.WithToken(context.Background(), userToken)
+
+	_, err := client.AddAddress(ctx, &proto.AddAddressRequest{Title: "Home"})
+	assertCode(t, err, codes.InvalidArgument)
+
+	_, err = client.AddAddress(ctx, &proto.AddAddressRequest{Title: "Home", AddressLine: "1 Main Street", Country: "India", City: "Kochi"})
+	if err != nil {
+		t.Fatalf("AddAddress: %v", err)
+	}
+	addresses, err := client.GetAddresses(ctx, &proto.Request{})
+	if err != nil {
+		t.Fatalf("GetAddresses: %v", err)
+	}
+	if len(addresses.Data) != 1 {
+		t.Fatalf("unexpected addresses %v", addresses.Data)
+	}
+	addressID := addresses.Data[0].AddressId
+
+	title := "Office"
+	_, err = client.EditAddress(testenv.WithToken(context.Background(), otherToken), &proto.EditAddressRequest{AddressId: addressID, Title: &title})
+	assertCode(t, err, codes.PermissionDenied)
+	if _, err = client.EditAddress(ctx, &proto.EditAddressRequest{AddressId: addressID, Title: &title}); err != nil {
+		t.Fatalf("EditAddress: %v", err)
+	}
+	address, err := client.GetAddressByID(ctx, &proto.AddressIdRequest{AddressId: addressID})
+	if err != nil {
+		t.Fatalf("GetAddressByID: %v", err)
+	}
+	if address.Data.Title != title || address.Data.GetCity() != "Kochi" {
+		t.Fatalf("unexpected address %v", address.Data)
+	}
+
+	_, err = client.DeleteAddress(testenv.WithToken(context.Background(), otherToken), &proto.AddressIdRequest{AddressId: addressID})
+	assertCode(t, err, codes.PermissionDenied)
+	if _, err = client.DeleteAddress(ctx, &proto.AddressIdRequest{AddressId: addressID}); err != nil {
+		t.Fatalf("DeleteAddress: %v", err)
+	}
+	_, err = client.GetAddressByID(ctx, &proto.AddressIdRequest{AddressId: addressID})
+	assertCode(t, err, codes.NotFound)
+}
+
+func TestCart(t *testing.T) {
+	env := testenv.New(t)
+	client := env.UserClient(t)
+	merchant, _ := env.AddUser(t, "merchant", utils.MERCHANT)
+	_, userToken := env.AddUser(t, "user", utils.USER)
+	_, otherToken := env.AddUser(t, "other", utils.USER)
+	category := env.AddCategory(t, "Shoes")
+	product := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5, 8)
+	ctx := testenv.WithToken(context.Background(), userToken)
+
+	_, err := client.AddProductToCart(ctx, &proto.AddToCartRequest{ProductId: product.ID, Size: 8})
+	assertCode(t, err, codes.InvalidArgument)
+
+	if _, err = client.AddProductToCart(ctx, &proto.AddToCartRequest{ProductId: product.ID, Size: 8, Quantity: 2, Price: 100}); err != nil {
+		t.Fatalf("AddProductToCart: %v", err)
+	}
+	cart, err := client.GetCartItmes(ctx, &proto.Request{})
+	if err != nil {
+		t.Fatalf("GetCartItmes: %v", err)
+	}
+	if len(cart.Data) != 1 || cart.Data[0].Quantity != 2 || cart.Data[0].AvailableQuantity != 5 {
+		t.Fatalf("unexpected cart %v", cart.Data)
+	}
+	item := cart.Data[0]
+
+	checkout := &proto.CheckoutCartItemsRequest{
+		CartId: item.CartId,
+		Data:   []*proto.CheckoutRequest{{CartItemId: item.CartItemId, Quantity: 3}},
+	}
+	_, err = client.CheckoutCartItems(ctx, &proto.CheckoutCartItemsRequest{CartId: "missing", Data: checkout.Data})
+	assertCode(t, err, codes.NotFound)
+	_, err = client.CheckoutCartItems(testenv.WithToken(context.Background(), otherToken), checkout)
+	assertCode(t, err, codes.PermissionDenied)
+	_, err = client.CheckoutCartItems(ctx, &proto.CheckoutCartItemsRequest{
+		CartId: item.CartId,
+		Data:   []*proto.CheckoutRequest{{CartItemId: item.CartItemId, Quantity: 6}},
+	})
+	assertCode(t, err, codes.FailedPrecondition)
+
+	// the items of the cart of another user cannot be checked out through our cart
+	otherCtx := testenv.WithToken(context.Background(), otherToken)
+	if _, err = client.AddProductToCart(otherCtx, &proto.AddToCartRequest{ProductId: product.ID, Size: 8, Quantity: 1}); err != nil {
+		t.Fatalf("AddProductToCart: %v", err)
+	}
+	otherCart, err := client.GetCartItmes(otherCtx, &proto.Request{})
+	if err != nil {
+		t.Fatalf("GetCartItmes: %v", err)
+	}
+	otherItem := otherCart.Data[0]
+	_, err = client.CheckoutCartItems(ctx, &proto.CheckoutCartItemsRequest{
+		CartId: item.CartId,
+		Data:   []*proto.CheckoutRequest{{CartItemId: otherItem.CartItemId, Quantity: 3}},
+	})
+	assertCode(t, err, codes.NotFound)
+
+	if _, err = client.CheckoutCartItems(ctx, checkout); err != nil {
+		t.Fatalf("CheckoutCartItems: %v", err)
+	}
+	env.DB.Lock()
+	otherQuantity := env.DB.CartItems[otherItem.CartItemId].Quantity
+	checkoutPrice := env.DB.Carts[item.CartId].Price
+	env.DB.Unlock()
+	if otherQuantity != 1 || checkoutPrice != 300 {
+		t.Fatalf("expected the other item to keep 1 and the cart to cost 300, got %d and %v", otherQuantity, checkoutPrice)
+	}
+
+	_, err = client.RemoveProductFromCart(ctx, &proto.RemoveFromCartRequest{CartItemId: "missing"})
+	assertCode(t, err, codes.NotFound)
+	_, err = client.RemoveProductFromCart(testenv.WithToken(context.Background(), otherToken), &proto.RemoveFromCartRequest{CartItemId: item.CartItemId})
+	assertCode(t, err, codes.NotFound)
+	if _, err = client.RemoveProductFromCart(ctx, &proto.RemoveFromCartRequest{CartItemId: item.CartItemId}); err != nil {
+		t.Fatalf("RemoveProductFromCart: %v", err)
+	}
+	env.DB.Lock()
+	price := env.DB.Carts[item.CartId].Price
+	env.DB.Unlock()
+	if price != 0 {
+		t.Fatalf("expected the price of the emptied cart to be 0, got %v", price)
+	}
+	cart, err = client.GetCartItmes(ctx, &proto.Request{})
+	if err != nil {
+		t.Fatalf("GetCartItmes: %v", err)
+	}
+	if len(cart.Data) != 0 {
+		t.Fatalf("expected an empty cart, got %v", cart.Data)
+	}
+}
+
+func TestCartVariants(t *testing.T) {
+	env := testenv.New(t)
+	client := env.UserClient(t)
+	merchant, _ := env.AddUser(t, "merchant", utils.MERCHANT)
+	_, userToken := env.AddUser(t, "user", utils.USER)
+	category := env.AddCategory(t, "Shoes")
+	product := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5)
+	other := env.AddProduct(t, merchant.ID, category.ID, "trail", 90, 5, 8)
+	price := 120.0
+	red := env.AddVariant(t, product, "RUN-RED-8", map[string]string{"color": "red", "size": "8"}, &price, 3)
+	blue := env.AddVariant(t, product, "RUN-BLUE-8", map[string]string{"color": "blue", "size": "8"}, nil, 7)
+	ctx := testenv.WithToken(context.Background(), userToken)
+
+	otherVariant := env.AddVariant(t, other, "TRAIL-9", map[string]string{"size": "9"}, nil, 1)
+	_, err := client.AddProductToCart(ctx, &proto.AddToCartRequest{ProductId: product.ID, VariantId: &otherVariant.ID, Quantity: 1})
+	assertCode(t, err, codes.NotFound)
+
+	// the price sent by the client is ignored
+	for _, variant := range []*memory.Inventory{red, blue} {
+		if _, err = client.AddProductToCart(ctx, &proto.AddToCartRequest{ProductId: product.ID, VariantId: &variant.ID, Quantity: 2, Price: 1}); err != nil {
+			t.Fatalf("AddProductToCart: %v", err)
+		}
+	}
+	cart, err := client.GetCartItmes(ctx, &proto.Request{})
+	if err != nil {
+		t.Fatalf("GetCartItmes: %v", err)
+	}
+	if len(cart.Data) != 2 {
+		t.Fatalf("unexpected cart %v", cart.Data)
+	}
+	for _, item := range cart.Data {
+		switch item.VariantId {
+		case red.ID:
+			if item.GetSku() != "RUN-RED-8" || item.Options["color"] != "red" || item.Price != 120 || item.AvailableQuantity != 3 || !strings.Contains(item.ProductImage, "RUN-RED-8") {
+				t.Fatalf("unexpected red item %v", item)
+			}
+		case blue.ID:
+			if item.GetSku() != "RUN-BLUE-8" || item.Options["color"] != "blue" || item.Price != 100 || item.Size != 8 {
+				t.Fatalf("unexpected blue item %v", item)
+			}
+		default:
+			t.Fatalf("unexpected variant %s", item.VariantId)
+		}
+	}
+
+	_, err = client.AddAddress(ctx, &proto.AddAddressRequest{Title: "Home", AddressLine: "1 Main Street", Country: "India", City: "Kochi"})
+	if err != nil {
+		t.Fatalf("AddAddress: %v", err)
+	}
+	addresses, err := client.GetAddresses(ctx, &proto.Request{})
+	if err != nil {
+		t.Fatalf("GetAddresses: %v", err)
+	}
+	order := &proto.CreateOrderRequest{AddressId: addresses.Data[0].AddressId, CartId: cart.Data[0].CartId, Amount: 440, PaymentMode: utils.OTHER}
+	if _, err = client.CreateOrder(ctx, order); err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+
+	// the order keeps the variant ordered when it changes afterwards
+	env.DB.Lock()
+	red.SKU, red.Options = nil, nil
+	env.DB.Unlock()
+	orders, err := client.GetUserOrders(ctx, &proto.GetOrdersRequest{Type: utils.OrderActive})
+	if err != nil {
+		t.Fatalf("GetUserOrders: %v", err)
+	}
+	if len(orders.Data) != 2 {
+		t.Fatalf("unexpected orders %v", orders.Data)
+	}
+	for _, item := range orders.Data {
+		if item.GetSku() == "RUN-RED-8" && (item.Options["color"] != "red" || item.Price != 120) {
+			t.Fatalf("unexpected red order %v", item)
+		}
+	}
+	if orders.Data[0].GetSku() == "" || orders.Data[1].GetSku() == "" {
+		t.Fatalf("expected the SKUs to be kept, got %v", orders.Data)
+	}
+	if red.Quantity != 1 || blue.Quantity != 5 {
+		t.Fatalf("expected the stock of the variants to be taken, got %d and %d", red.Quantity, blue.Quantity)
+	}
+	env.DB.Lock()
+	defer env.DB.Unlock()
+	for _, movement := range env.DB.StockMovements {
+		if movement.Type != utils.StockSale || movement.OrderItemID == nil || movement.Balance != env.DB.Inventories[movement.InventoryID].Quantity {
+			t.Fatalf("unexpected stock movement %v", movement)
+		}
+	}
+	if len(env.DB.StockMovements) != 2 {
+		t.Fatalf("expected a sale for each variant, got %d movements", len(env.DB.StockMovements))
+	}
+}
+
+// TestOrderFlow follows an order from the cart to the review, through the user, order, merchant and product services.
+func TestCartQuantities(t *testing.T) {
+	env := testenv.New(t)
+	client := env.UserClient(t)
+	merchant, _ := env.AddUser(t, "merchant", utils.MERCHANT)
+	_, userToken := env.AddUser(t, "user", utils.USER)
+	_, otherToken := env.AddUser(t, "other", utils.USER)
+	category := env.AddCategory(t, "Shoes")
+	product := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5, 8)
+	ctx := testenv.WithToken(context.Background(), userToken)
+
+	// adding the variant again adds to its quantity, up to the stock
+	for i := 0; i < 2; i++ {
+		if _, err := client.AddProductToCart(ctx, &proto.AddToCartRequest{ProductId: product.ID, Size: 8, Quantity: 2}); err != nil {
+			t.Fatalf("AddProductToCart: %v", err)
+		}
+	}
+	_, err := client.AddProductToCart(ctx, &proto.AddToCartRequest{ProductId: product.ID, Size: 8, Quantity: 2})
+	assertCode(t, err, codes.FailedPrecondition)
+	cart, err := client.GetCartItmes(ctx, &proto.Request{})
+	if err != nil {
+		t.Fatalf("GetCartItmes: %v", err)
+	}
+	if len(cart.Data) != 1 || cart.Data[0].Quantity != 4 {
+		t.Fatalf("expected one item of 4, got %v", cart.Data)
+	}
+	item := cart.Data[0]
+
+	update := &proto.UpdateCartItemQuantityRequest{CartItemId: item.CartItemId, Quantity: 5}
+	_, err = client.UpdateCartItemQuantity(ctx, &proto.UpdateCartItemQuantityRequest{CartItemId: item.CartItemId, Quantity: 6})
+	assertCode(t, err, codes.FailedPrecondition)
+	_, err = client.UpdateCartItemQuantity(ctx, &proto.UpdateCartItemQuantityRequest{CartItemId: item.CartItemId, Quantity: -1})
+	assertCode(t, err, codes.InvalidArgument)
+	_, err = client.UpdateCartItemQuantity(ctx, &proto.UpdateCartItemQuantityRequest{CartItemId: "missing", Quantity: 1})
+	assertCode(t, err, codes.InvalidArgument)
+	_, err = client.UpdateCartItemQuantity(testenv.WithToken(context.Background(), otherToken), update)
+	assertCode(t, err, codes.NotFound)
+	if _, err = client.UpdateCartItemQuantity(ctx, update); err != nil {
+		t.Fatalf("UpdateCartItemQuantity: %v", err)
+	}
+	cart, err = client.GetCartItmes(ctx, &proto.Request{})
+	if err != nil {
+		t.Fatalf("GetCartItmes: %v", err)
+	}
+	if len(cart.Data) != 1 || cart.Data[0].Quantity != 5 {
+		t.Fatalf("expected one item of 5, got %v", cart.Data)
+	}
+
+	// 0 removes the item
+	if _, err = client.UpdateCartItemQuantity(ctx, &proto.UpdateCartItemQuantityRequest{CartItemId: item.CartItemId}); err != nil {
+		t.Fatalf("UpdateCartItemQuantity: %v", err)
+	}
+	cart, err = client.GetCartItmes(ctx, &proto.Request{})
+	if err != nil {
+		t.Fatalf("GetCartItmes: %v", err)
+	}
+	if len(cart.Data) != 0 {
+		t.Fatalf("expected an empty cart, got %v", cart.Data)
+	}
+}
+
+func TestGuestCart(t *testing.T) {
+	env := testenv.New(t)
+	client := env.UserClient(t)
+	merchant, _ := env.AddUser(t, "merchant", utils.MERCHANT)
+	_, userToken := env.AddUser(t, "user", utils.USER)
+	category := env.AddCategory(t, "Shoes")
+	product := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5, 8)
+	guestCtx := metadata.AppendToOutgoingContext(context.Background(), utils.DeviceIDKey, "device-1")
+	otherGuestCtx := metadata.AppendToOutgoingContext(context.Background(), utils.DeviceIDKey, "device-2")
+
+	_, err := client.GetCartItmes(context.Background(), &proto.Request{})
+	assertCode(t, err, codes.Unauthenticated)
+	if _, err = client.AddProductToCart(guestCtx, &proto.AddToCartRequest{ProductId: product.ID, Size: 8, Quantity: 2}); err != nil {
+		t.Fatalf("AddProductToCart: %v", err)
+	}
+	cart, err := client.GetCartItmes(guestCtx, &proto.Request{})
+	if err != nil {
+		t.Fatalf("GetCartItmes: %v", err)
+	}
+	if len(cart.Data) != 1 || cart.Data[0].Quantity != 2 {
+		t.Fatalf("unexpected guest cart %v", cart.Data)
+	}
+	item := cart.Data[0]
+
+	// the cart is the one of the device, and not the one of a user signed in on it
+	cart, err = client.GetCartItmes(otherGuestCtx, &proto.Request{})
+	if err != nil {
+		t.Fatalf("GetCartItmes: %v", err)
+	}
+	if len(cart.Data) != 0 {
+		t.Fatalf("expected an empty cart for another device, got %v", cart.Data)
+	}
+	userCtx := metadata.AppendToOutgoingContext(testenv.WithToken(context.Background(), userToken), utils.DeviceIDKey, "device-1")
+	cart, err = client.GetCartItmes(userCtx, &proto.Request{})
+	if err != nil {
+		t.Fatalf("GetCartItmes: %v", err)
+	}
+	if len(cart.Data) != 0 {
+		t.Fatalf("expected an empty cart for the user, got %v", cart.Data)
+	}
+
+	_, err = client.UpdateCartItemQuantity(otherGuestCtx, &proto.UpdateCartItemQuantityRequest{CartItemId: item.CartItemId, Quantity: 1})
+	assertCode(t, err, codes.NotFound)
+	if _, err = client.UpdateCartItemQuantity(guestCtx, &proto.UpdateCartItemQuantityRequest{CartItemId: item.CartItemId, Quantity: 3}); err != nil {
+		t.Fatalf("UpdateCartItemQuantity: %v", err)
+	}
+	// the guests sign in to check out
+	_, err = client.CheckoutCartItems(guestCtx, &proto.CheckoutCartItemsRequest{
+		CartId: item.CartId,
+		Data:   []*proto.CheckoutRequest{{CartItemId: item.CartItemId, Quantity: 1}},
+	})
+	assertCode(t, err, codes.Unauthenticated)
+	if _, err = client.RemoveProductFromCart(guestCtx, &proto.RemoveFromCartRequest{CartItemId: item.CartItemId}); err != nil {
+		t.Fatalf("RemoveProductFromCart: %v", err)
+	}
+}
+
+func TestOrderFlow(t *testing.T) {
+	env := testenv.New(t)
+	client := env.UserClient(t)
+	merchantClient := env.MerchantClient(t)
+	merchant, merchantToken := env.AddUser(t, "merchant", utils.MERCHANT)
+	_, userToken := env.AddUser(t, "user", utils.USER)
+	category := env.AddCategory(t, "Shoes")
+	product := env.AddProduct(t, merchant.ID, category.ID, "runner", 100, 5, 8)
+	promo := env.AddPromoCode(t, "SAVE10", 10)
+	ctx := testenv.WithToken(context.Background(), userToken)
+	merchantCtx := testenv.WithToken(context.Background(), merchantToken)
+
+	_, err := client.AddAddress(ctx, &proto.AddAddressRequest{Title: "Home", AddressLine: "1 Main Street", Country: "India", City: "Kochi"})
+	if err != nil {
+		t.Fatalf("AddAddress: %v", err)
+	}
+	addresses, err := client.GetAddresses(ctx, &proto.Request{})
+	if err != nil {
+		t.Fatalf("GetAddresses: %v", err)
+	}
+	if _, err = client.AddProductToCart(ctx, &proto.AddToCartRequest{ProductId: product.ID, Size: 8, Quantity: 2, Price: 100}); err != nil {
+		t.Fatalf("AddProductToCart: %v", err)
+	}
+	cart, err := client.GetCartItmes(ctx, &proto.Request{})
+	if err != nil {
+		t.Fatalf("GetCartItmes: %v", err)
+	}
+
+	order := &proto.CreateOrderRequest{
+		AddressId:   addresses.Data[0].AddressId,
+		CartId:      cart.Data[0].CartId,
+		PromoId:     &promo.ID,
+		Amount:      180,
+		PaymentMode: utils.COD,
+	}
+	_, err = client.CreateOrder(ctx, &proto.CreateOrderRequest{AddressId: "missing", CartId: order.CartId, Amount: 180, PaymentMode: utils.OTHER})
+	assertCode(t, err, codes.NotFound)
+
+	res, err := client.CreateOrder(ctx, order)
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	if res.Status {
+		t.Fatal("expected cash on delivery to be refused for a new user")
+	}
+
+	order.PaymentMode = utils.OTHER
+	env.DB.Lock()
+	product.Status = utils.ProductArchived
+	env.DB.Unlock()
+	cart, err = client.GetCartItmes(ctx, &proto.Request{})
+	if err != nil {
+		t.Fatalf("GetCartItmes: %v", err)
+	}
+	if len(cart.Data) != 1 || !cart.Data[0].Unavailable {
+		t.Fatalf("expected the archived product to be unavailable, got %v", cart.Data)
+	}
+	_, err = client.CreateOrder(ctx, order)
+	assertCode(t, err, codes.FailedPrecondition)
+	env.DB.Lock()
+	product.Status = utils.ProductPublished
+	env.DB.Unlock()
+
+	if _, err = client.CreateOrder(ctx, order); err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	orders, err := client.GetUserOrders(ctx, &proto.GetOrdersRequest{Type: utils.OrderActive})
+	if err != nil {
+		t.Fatalf("GetUserOrders: %v", err)
+	}
+	if len(orders.Data) != 1 || orders.Data[0].ProductId != product.ID {
+		t.Fatalf("unexpected orders %v", orders.Data)
+	}
+	orderItemID := orders.Data[0].Id
+
+	cart, err = client.GetCartItmes(ctx, &proto.Request{})
+	if err != nil {
+		t.Fatalf("GetCartItmes: %v", err)
+	}
+	if len(cart.Data) != 0 {
+		t.Fatalf("expected the cart to be emptied, got %v", cart.Data)
+	}
+
+	review := &proto.AddReviewRequest{ProductId: product.ID, OrderItemId: orderItemID, Start: 5, Review: "great"}
+	_, err = client.AddProductReview(ctx, review)
+	assertCode(t, err, codes.PermissionDenied)
+
+	for i := 0; i < 3; i++ {
+		if _, err = merchantClient.UpdateOrderStatus(merchantCtx, &proto.UpdateOrderRequest{OrderItemId: orderItemID}); err != nil {
+			t.Fatalf("UpdateOrderStatus: %v", err)
+		}
+	}
+	tracking, err := env.OrderClient(t).GetOrderTrackingDetails(ctx, &proto.GetTrackingDetailsRequest{OrderItemId: orderItemID})
+	if err != nil {
+		t.Fatalf("GetOrderTrackingDetails: %v", err)
+	}
+	if tracking.Data.Status != 3 || len(tracking.Data.Details) != 4 {
+		t.Fatalf("unexpected tracking %v", tracking.Data)
+	}
+
+	orders, err = client.GetUserOrders(ctx, &proto.GetOrdersRequest{Type: utils.OrderCompleted})
+	if err != nil {
+		t.Fatalf("GetUserOrders: %v", err)
+	}
+	if len(orders.Data) != 1 {
+		t.Fatalf("expected the completed order, got %v", orders.Data)
+	}
+
+	_, err = client.AddProductReview(ctx, &proto.AddReviewRequest{ProductId: "missing", OrderItemId: orderItemID, Start: 5, Review: "great"})
+	assertCode(t, err, codes.NotFound)
+	if _, err = client.AddProductReview(ctx, review); err != nil {
+		t.Fatalf("AddProductReview: %v", err)
+	}
+	_, err = client.AddProductReview(ctx, review)
+	assertCode(t, err, codes.PermissionDenied)
+}
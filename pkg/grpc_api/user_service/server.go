@@ -19,7 +19,7 @@ func RunServer() error {
 		log.LogFatal("Cannot load configuration:", err)
 	}
 	log.LogInfo("Config file loaded.")
-	store, err := database.NewStorage(config.Database)
+	store, err := database.NewStorage(config.Database, logFileName)
 	if err != nil {
 		log.LogFatal("Cannot connect to Database", err)
 	}
@@ -27,21 +27,21 @@ func (handler *userService) AddAddress(ctx context.Context, in *proto.AddAddress
 	}
 	err := helpers.ValidateBody(nil, req)
 	if err != nil {
-		handler.log.LogError("Error while ValidateBody", err)
+		handler.log.With(ctx).LogError("Error while ValidateBody", err)
 		return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
 	}
 
 	payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
 	if !ok {
 		err := errors.New("unable to retrieve user payload from context")
-		handler.log.LogError("Error", err)
+		handler.log.With(ctx).LogError("Error", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 	req.UserID = payload.UserID
 
-	err = handler.storage.AddAddress(req)
+	err = handler.storage.AddAddress(ctx, req)
 	if err != nil {
-		handler.log.LogError("Error while AddAddress", err)
+		handler.log.With(ctx).LogError("Error while AddAddress", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
@@ -57,12 +57,12 @@ func (handler *userService) GetAddresses(ctx context.Context, in *proto.Request)
 	payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
 	if !ok {
 		err := errors.New("unable to retrieve user payload from context")
-		handler.log.LogError("Error", err)
+		handler.log.With(ctx).LogError("Error", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
-	addressEntities, err := handler.storage.GetAddresses(payload.UserID)
+	addressEntities, err := handler.storage.GetAddresses(ctx, payload.UserID)
 	if err != nil {
-		handler.log.LogError("Error while GetAddresses", err)
+		handler.log.With(ctx).LogError("Error while GetAddresses", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
@@ -89,22 +89,22 @@ func (handler *userService) GetAddressByID(ctx context.Context, in *proto.Addres
 	// payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
 	// if !ok {
 	// 	err := errors.New("unable to retrieve user payload from context")
-	// 	handler.log.LogError("Error", err)
+	// 	handler.log.With(ctx).LogError("Error", err)
 	// 	return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	// }
 
-	address, err := handler.storage.GetAddressById(in.GetAddressId())
+	address, err := handler.storage.GetAddressById(ctx, in.GetAddressId())
 	if err != nil {
 		if err == sql.ErrNoRows {
-			handler.log.LogError("Error while GetAddressById")
+			handler.log.With(ctx).LogError("Error while GetAddressById")
 			return nil, status.Errorf(codes.NotFound, utils.NotFound)
 		}
-		handler.log.LogError("Error while GetAddressById", err)
+		handler.log.With(ctx).LogError("Error while GetAddressById", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 	// ------------ Removing this condition because other service may use this function to get the
 	// if payload.UserID != addressEntity.UserID {
-	// 	handler.log.LogError("Address doesn't belong the user")
+	// 	handler.log.With(ctx).LogError("Address doesn't belong the user")
 	// 	return nil, status.Errorf(codes.PermissionDenied, utils.PermissionDenied)
 	// }
 
@@ -140,35 +140,35 @@ func (handler *userService) EditAddress(ctx context.Context, in *proto.EditAddre
 	}
 	err := helpers.ValidateBody(nil, req)
 	if err != nil {
-		handler.log.LogError("Error while ValidateBody", err)
+		handler.log.With(ctx).LogError("Error while ValidateBody", err)
 		return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
 	}
 
 	payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
 	if !ok {
 		err := errors.New("unable to retrieve user payload from context")
-		handler.log.LogError("Error", err)
+		handler.log.With(ctx).LogError("Error", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
-	addressEntity, err := handler.storage.GetAddressById(in.GetAddressId())
+	addressEntity, err := handler.storage.GetAddressById(ctx, in.GetAddressId())
 	if err != nil {
 		if err == sql.ErrNoRows {
-			handler.log.LogError("Error while GetAddressById")
+			handler.log.With(ctx).LogError("Error while GetAddressById")
 			return nil, status.Errorf(codes.NotFound, utils.NotFound)
 		}
-		handler.log.LogError("Error while GetAddressById", err)
+		handler.log.With(ctx).LogError("Error while GetAddressById", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
 	if payload.UserID != addressEntity.UserID {
-		handler.log.LogError("Address doesn't belong the user")
+		handler.log.With(ctx).LogError("Address doesn't belong the user")
 		return nil, status.Errorf(codes.PermissionDenied, utils.PermissionDenied)
 	}
 
-	err = handler.storage.EditAddress(in.GetAddressId(), req)
+	err = handler.storage.EditAddress(ctx, in.GetAddressId(), req)
 	if err != nil {
-		handler.log.LogError("Error while EditAddress", err)
+		handler.log.With(ctx).LogError("Error while EditAddress", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
@@ -185,28 +185,28 @@ func (handler *userService) DeleteAddress(ctx context.Context, in *proto.Address
 	payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
 	if !ok {
 		err := errors.New("unable to retrieve user payload from context")
-		handler.log.LogError("Error", err)
+		handler.log.With(ctx).LogError("Error", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
-	addressEntity, err := handler.storage.GetAddressById(in.GetAddressId())
+	addressEntity, err := handler.storage.GetAddressById(ctx, in.GetAddressId())
 	if err != nil {
 		if err == sql.ErrNoRows {
-			handler.log.LogError("Error while GetAddressById")
+			handler.log.With(ctx).LogError("Error while GetAddressById")
 			return nil, status.Errorf(codes.NotFound, utils.NotFound)
 		}
-		handler.log.LogError("Error while GetAddressById", err)
+		handler.log.With(ctx).LogError("Error while GetAddressById", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
 	if payload.UserID != addressEntity.UserID {
-		handler.log.LogError("Address doesn't belong the user")
+		handler.log.With(ctx).LogError("Address doesn't belong the user")
 		return nil, status.Errorf(codes.PermissionDenied, utils.PermissionDenied)
 	}
 
-	err = handler.storage.DeleteAddress(in.GetAddressId())
+	err = handler.storage.DeleteAddress(ctx, in.GetAddressId())
 	if err != nil {
-		handler.log.LogError("Error while DeleteAddress", err)
+		handler.log.With(ctx).LogError("Error while DeleteAddress", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
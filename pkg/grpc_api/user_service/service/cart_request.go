@@ -7,51 +7,51 @@ import (
 	"net/http"
 
 	"github.com/akmal4410/gestapo/pkg/api/proto"
+	"github.com/akmal4410/gestapo/pkg/grpc_api/user_service/db"
 	"github.com/akmal4410/gestapo/pkg/grpc_api/user_service/db/entity"
 	"github.com/akmal4410/gestapo/pkg/helpers"
 	"github.com/akmal4410/gestapo/pkg/helpers/token"
+	"github.com/akmal4410/gestapo/pkg/service/images"
 	"github.com/akmal4410/gestapo/pkg/utils"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
+// maxDeviceIDLength is the longest device id of a guest cart.
+const maxDeviceIDLength = 128
+
+// AddProductToCart adds the variant to the cart of the user, or of the device of a
+// guest, or adds to its quantity when it is already in the cart.
 func (handler *userService) AddProductToCart(ctx context.Context, in *proto.AddToCartRequest) (*proto.Response, error) {
-	payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
-	if !ok {
-		err := errors.New("unable to retrieve user payload from context")
-		handler.log.LogError("Error", err)
-		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	owner, err := handler.cartOwner(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	req := &entity.AddToCartReq{
 		ProductID: in.GetProductId(),
+		VariantID: in.VariantId,
 		Size:      float64(in.GetSize()),
 		Quantity:  in.GetQuantity(),
-		Price:     float64(in.GetPrice()),
-		UserID:    payload.UserID,
+		Owner:     owner,
 	}
-	err := helpers.ValidateBody(nil, req)
+	err = helpers.ValidateBody(nil, req)
 	if err != nil {
-		handler.log.LogError("Error while ValidateBody", err)
+		handler.log.With(ctx).LogError("Error while ValidateBody", err)
 		return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
 	}
 
-	res, err := handler.storage.CheckDataExist("carts", "user_id", req.UserID)
+	err = handler.storage.AddToCard(ctx, req)
 	if err != nil {
-		handler.log.LogError("Error while CheckDataExist", err)
-		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
-	}
-	if !res {
-		err := handler.storage.CreateUserCart(req)
-		if err != nil {
-			handler.log.LogError("Error while CreateUserCart", err)
-			return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+		if err == sql.ErrNoRows {
+			handler.log.With(ctx).LogError("Error while AddToCard variant not found", err)
+			return nil, status.Errorf(codes.NotFound, "Variant not found")
 		}
-	}
-
-	err = handler.storage.AddToCard(req)
-	if err != nil {
-		handler.log.LogError("Error while AddToCard", err)
+		if err == db.ErrInsufficientStock {
+			return nil, status.Errorf(codes.FailedPrecondition, "Not enough stock of the variant")
+		}
+		handler.log.With(ctx).LogError("Error while AddToCard", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
@@ -64,28 +64,23 @@ func (handler *userService) AddProductToCart(ctx context.Context, in *proto.AddT
 }
 
 func (handler *userService) GetCartItmes(ctx context.Context, in *proto.Request) (*proto.GetCartItemsResponse, error) {
-	payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
-	if !ok {
-		err := errors.New("unable to retrieve user payload from context")
-		handler.log.LogError("Error", err)
-		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	owner, err := handler.cartOwner(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	cartItemEntities, err := handler.storage.GetCartItems(payload.UserID)
+	cartItemEntities, err := handler.storage.GetCartItems(ctx, owner)
 	if err != nil {
-		handler.log.LogError("Error while GetCartItems", err)
+		handler.log.With(ctx).LogError("Error while GetCartItems", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
+	productImages := make([]string, len(cartItemEntities))
+	for i, product := range cartItemEntities {
+		productImages[i] = product.ImageURL
+	}
+	urls := images.URLs(ctx, handler.urls, productImages, images.Thumbnail)
 	for _, product := range cartItemEntities {
-
-		if product.ImageURL != "" {
-			url, err := handler.s3.GetPreSignedURL(product.ImageURL)
-			if err != nil {
-				handler.log.LogError("Error while GetPreSignedURL", err)
-				return nil, status.Errorf(codes.Internal, utils.InternalServerError)
-			}
-			product.ImageURL = url
-		}
+		product.ImageURL = urls[product.ImageURL]
 	}
 
 	var cartItems []*proto.CartItemResponse
@@ -99,6 +94,10 @@ func (handler *userService) GetCartItmes(ctx context.Context, in *proto.Request)
 			Price:             float32(cartItem.Price),
 			Quantity:          cartItem.Quantity,
 			AvailableQuantity: cartItem.AvailableQuantity,
+			VariantId:         cartItem.InventoryID,
+			Sku:               cartItem.SKU,
+			Options:           cartItem.Options,
+			Unavailable:       cartItem.Unavailable,
 		}
 		cartItems = append(cartItems, item)
 	}
@@ -115,7 +114,7 @@ func (handler *userService) CheckoutCartItems(ctx context.Context, in *proto.Che
 	payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
 	if !ok {
 		err := errors.New("unable to retrieve user payload from context")
-		handler.log.LogError("Error", err)
+		handler.log.With(ctx).LogError("Error", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
@@ -134,28 +133,35 @@ func (handler *userService) CheckoutCartItems(ctx context.Context, in *proto.Che
 	}
 	err := helpers.ValidateBody(nil, req)
 	if err != nil {
-		handler.log.LogError("Error while ValidateBody", err)
+		handler.log.With(ctx).LogError("Error while ValidateBody", err)
 		return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
 	}
 
-	cartEntity, err := handler.storage.GetCartById(in.GetCartId())
+	cartEntity, err := handler.storage.GetCartById(ctx, in.GetCartId())
 	if err != nil {
 		if err == sql.ErrNoRows {
-			handler.log.LogError("Error while GetCartById Not Found")
+			handler.log.With(ctx).LogError("Error while GetCartById Not Found")
 			return nil, status.Errorf(codes.NotFound, utils.NotFound)
 		}
-		handler.log.LogError("Error while GetCartById", err)
+		handler.log.With(ctx).LogError("Error while GetCartById", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
 	if cartEntity.UserID != payload.UserID {
-		handler.log.LogError("Cart doesn't belong the user")
+		handler.log.With(ctx).LogError("Cart doesn't belong the user")
 		return nil, status.Errorf(codes.PermissionDenied, utils.PermissionDenied)
 	}
 
-	err = handler.storage.CheckoutCartItems(cartItems)
+	err = handler.storage.CheckoutCartItems(ctx, req)
 	if err != nil {
-		handler.log.LogError("Error while CheckoutCartItems", err)
+		if err == sql.ErrNoRows {
+			handler.log.With(ctx).LogError("Error while CheckoutCartItems cart item not found", err)
+			return nil, status.Errorf(codes.NotFound, "Cart item not found")
+		}
+		if err == db.ErrInsufficientStock {
+			return nil, status.Errorf(codes.FailedPrecondition, "Not enough stock of the variant")
+		}
+		handler.log.With(ctx).LogError("Error while CheckoutCartItems", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
@@ -168,52 +174,110 @@ func (handler *userService) CheckoutCartItems(ctx context.Context, in *proto.Che
 }
 
 func (handler *userService) RemoveProductFromCart(ctx context.Context, in *proto.RemoveFromCartRequest) (*proto.Response, error) {
-	payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
-	if !ok {
-		err := errors.New("unable to retrieve user payload from context")
-		handler.log.LogError("Error", err)
-		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	owner, err := handler.cartOwner(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	//check cart_item is present or not
-	res, err := handler.storage.CheckDataExist("cart_items", "id", in.GetCartItemId())
+	res, err := handler.storage.CheckDataExist(ctx, "cart_items", "id", in.GetCartItemId())
 	if err != nil {
-		handler.log.LogError("Error ", err)
+		handler.log.With(ctx).LogError("Error ", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 	if !res {
-		handler.log.LogError("Error CartItem not found")
+		handler.log.With(ctx).LogError("Error CartItem not found")
 		return nil, status.Errorf(codes.NotFound, utils.NotFound)
 	}
 
-	res, err = handler.storage.CanDeleteCartItem(in.GetCartItemId(), payload.UserID)
+	res, err = handler.storage.CanDeleteCartItem(ctx, in.GetCartItemId(), owner)
 	if err != nil {
-		handler.log.LogError("Error while CanEditDeleteCartItem", err)
+		handler.log.With(ctx).LogError("Error while CanEditDeleteCartItem", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 	if !res {
 		err := errors.New("error while CanEditDeleteCartItem: Not found")
-		handler.log.LogError("Error", err)
+		handler.log.With(ctx).LogError("Error", err)
 		return nil, status.Errorf(codes.NotFound, utils.NotFound)
 	}
 
-	err = handler.storage.RemoveFromCart(in.GetCartItemId(), payload.UserID)
+	err = handler.storage.RemoveFromCart(ctx, in.GetCartItemId(), owner)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			handler.log.LogError("Error while RemoveFromCart")
+			handler.log.With(ctx).LogError("Error while RemoveFromCart")
 			return nil, status.Errorf(codes.NotFound, utils.NotFound)
 		}
-		if err.Error() == "not deleted" {
-			handler.log.LogError("Error while RemoveFromCart")
+		handler.log.With(ctx).LogError("Error while RemoveFromCart", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	response := &proto.Response{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Item deleted from cart successfully",
+	}
+	return response, nil
+}
+
+// UpdateCartItemQuantity sets the quantity of an item of the cart, up to the stock of
+// its variant, or removes the item for 0.
+func (handler *userService) UpdateCartItemQuantity(ctx context.Context, in *proto.UpdateCartItemQuantityRequest) (*proto.Response, error) {
+	owner, err := handler.cartOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &entity.UpdateCartItemQuantityReq{
+		CartItemID: in.GetCartItemId(),
+		Quantity:   in.GetQuantity(),
+		Owner:      owner,
+	}
+	err = helpers.ValidateBody(nil, req)
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while ValidateBody", err)
+		return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
+	}
+
+	err = handler.storage.UpdateCartItemQuantity(ctx, req)
+	if err != nil {
+		if err == sql.ErrNoRows {
 			return nil, status.Errorf(codes.NotFound, utils.NotFound)
 		}
-		handler.log.LogError("Error while RemoveFromCart", err)
+		if err == db.ErrInsufficientStock {
+			return nil, status.Errorf(codes.FailedPrecondition, "Not enough stock of the variant")
+		}
+		handler.log.With(ctx).LogError("Error while UpdateCartItemQuantity", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
+
 	response := &proto.Response{
 		Code:    http.StatusOK,
 		Status:  true,
-		Message: "Item deleted from cart successfully",
+		Message: "Cart item updated successfully",
+	}
+	if req.Quantity == 0 {
+		response.Message = "Item deleted from cart successfully"
 	}
 	return response, nil
 }
+
+// cartOwner returns the user of the request, or the device of a guest, whose cart
+// is used.
+func (handler *userService) cartOwner(ctx context.Context) (entity.CartOwner, error) {
+	var owner entity.CartOwner
+	if payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload); ok {
+		owner.UserID = &payload.UserID
+		return owner, nil
+	}
+	values := metadata.ValueFromIncomingContext(ctx, utils.DeviceIDKey)
+	if len(values) == 0 || values[0] == "" {
+		err := errors.New("unable to retrieve user payload from context")
+		handler.log.With(ctx).LogError("Error", err)
+		return owner, status.Errorf(codes.Unauthenticated, utils.Unauthorized)
+	}
+	if len(values[0]) > maxDeviceIDLength {
+		handler.log.With(ctx).LogError("Invalid device id", values[0])
+		return owner, status.Errorf(codes.InvalidArgument, "Device id must have at most %d characters", maxDeviceIDLength)
+	}
+	owner.DeviceID = &values[0]
+	return owner, nil
+}
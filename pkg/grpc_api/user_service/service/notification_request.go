@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/akmal4410/gestapo/pkg/api/proto"
+	"github.com/akmal4410/gestapo/pkg/helpers/token"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultNotificationLimit = 20
+	maxNotificationLimit     = 100
+)
+
+func (handler *userService) GetNotifications(ctx context.Context, in *proto.GetNotificationsRequest) (*proto.GetNotificationsResponse, error) {
+	payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
+	if !ok {
+		err := errors.New("unable to retrieve user payload from context")
+		handler.log.With(ctx).LogError("Error", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	limit := int(in.GetLimit())
+	if limit == 0 {
+		limit = defaultNotificationLimit
+	}
+	if limit < 0 || limit > maxNotificationLimit {
+		handler.log.With(ctx).LogError("Invalid limit", in.GetLimit())
+		return nil, status.Errorf(codes.InvalidArgument, "Limit must be between 1 and %d", maxNotificationLimit)
+	}
+
+	notifications, err := handler.storage.GetNotifications(ctx, payload.UserID, in.GetUnreadOnly(), limit)
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while GetNotifications", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	unread, err := handler.storage.CountUnreadNotifications(ctx, payload.UserID)
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while CountUnreadNotifications", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+
+	data := make([]*proto.NotificationResponse, 0, len(notifications))
+	for _, notification := range notifications {
+		res := &proto.NotificationResponse{
+			Id:        notification.ID,
+			Type:      notification.Type,
+			Title:     notification.Title,
+			Body:      notification.Body,
+			ProductId: notification.ProductID,
+			CreatedAt: timestamppb.New(notification.CreatedAt),
+		}
+		if notification.ReadAt != nil {
+			res.ReadAt = timestamppb.New(*notification.ReadAt)
+		}
+		data = append(data, res)
+	}
+
+	response := &proto.GetNotificationsResponse{
+		Code:        http.StatusOK,
+		Status:      true,
+		Message:     "Notifications fetched successfully",
+		Data:        data,
+		UnreadCount: unread,
+	}
+	return response, nil
+}
+
+func (handler *userService) MarkNotificationsRead(ctx context.Context, in *proto.MarkNotificationsReadRequest) (*proto.Response, error) {
+	payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
+	if !ok {
+		err := errors.New("unable to retrieve user payload from context")
+		handler.log.With(ctx).LogError("Error", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	for _, id := range in.GetNotificationIds() {
+		if _, err := uuid.Parse(id); err != nil {
+			handler.log.With(ctx).LogError("Invalid notification id", err)
+			return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
+		}
+	}
+
+	marked, err := handler.storage.MarkNotificationsRead(ctx, payload.UserID, in.GetNotificationIds())
+	if err != nil {
+		handler.log.With(ctx).LogError("Error while MarkNotificationsRead", err)
+		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
+	}
+	handler.log.With(ctx).LogInfo("Marked", marked, "notifications read")
+
+	response := &proto.Response{
+		Code:    http.StatusOK,
+		Status:  true,
+		Message: "Notifications marked read successfully",
+	}
+	return response, nil
+}
@@ -3,52 +3,48 @@ package service
 import (
 	"context"
 	"errors"
-	"fmt"
-	"time"
 
 	"github.com/akmal4410/gestapo/pkg/api/proto"
 	"github.com/akmal4410/gestapo/pkg/grpc_api/user_service/db/entity"
 	"github.com/akmal4410/gestapo/pkg/helpers"
-	"github.com/akmal4410/gestapo/pkg/helpers/service_helper"
 	"github.com/akmal4410/gestapo/pkg/helpers/token"
 	"github.com/akmal4410/gestapo/pkg/utils"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
 func (handler *userService) CreateOrder(ctx context.Context, req *proto.CreateOrderRequest) (*proto.Response, error) {
 	//check address is present or not
-	res, err := handler.storage.CheckDataExist("addresses", "id", req.GetAddressId())
+	res, err := handler.storage.CheckDataExist(ctx, "addresses", "id", req.GetAddressId())
 	if err != nil {
-		handler.log.LogError("Error ", err)
+		handler.log.With(ctx).LogError("Error ", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 	if !res {
-		handler.log.LogError("Error Address not found")
+		handler.log.With(ctx).LogError("Error Address not found")
 		return nil, status.Errorf(codes.NotFound, utils.NotFound)
 	}
 
 	//check cart is present or not
-	res, err = handler.storage.CheckDataExist("carts", "id", req.GetCartId())
+	res, err = handler.storage.CheckDataExist(ctx, "carts", "id", req.GetCartId())
 	if err != nil {
-		handler.log.LogError("Error ", err)
+		handler.log.With(ctx).LogError("Error ", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 	if !res {
-		handler.log.LogError("Error Cart not found")
+		handler.log.With(ctx).LogError("Error Cart not found")
 		return nil, status.Errorf(codes.NotFound, utils.NotFound)
 	}
 
 	if req.PromoId != nil {
 		//check promoCode is present or not
-		res, err = handler.storage.CheckDataExist("promo_codes", "id", req.GetPromoId())
+		res, err = handler.storage.CheckDataExist(ctx, "promo_codes", "id", req.GetPromoId())
 		if err != nil {
-			handler.log.LogError("Error ", err)
+			handler.log.With(ctx).LogError("Error ", err)
 			return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 		}
 		if !res {
-			handler.log.LogError("Error Promo not found")
+			handler.log.With(ctx).LogError("Error Promo not found")
 			return nil, status.Errorf(codes.NotFound, utils.NotFound)
 		}
 	}
@@ -56,33 +52,28 @@ func (handler *userService) CreateOrder(ctx context.Context, req *proto.CreateOr
 	payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
 	if !ok {
 		err := errors.New("unable to retrieve user payload from context")
-		handler.log.LogError("Error", err)
+		handler.log.With(ctx).LogError("Error", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
 	serviceToken, err := handler.token.CreateServiceToken(payload.UserID, payload.UserType, "order")
 	if err != nil {
-		handler.log.LogError("error while generating service token in CreateOrder", err)
+		handler.log.With(ctx).LogError("error while generating service token in CreateOrder", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
-	conn, err := service_helper.ConnectEndpoints(handler.config.ServerAddress.Order.Address, "order", handler.log)
+	orderClient, err := handler.clients.OrderClient()
 	if err != nil {
-		handler.log.LogError("error while connecting order service :", err)
+		handler.log.With(ctx).LogError("error while connecting order service :", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
-	defer conn.Close()
 
-	orderClient := proto.NewOrderServiceClient(conn)
-	serviceCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	serviceCtx = metadata.NewOutgoingContext(serviceCtx, metadata.New(map[string]string{
-		token.ServiceToken: fmt.Sprint(utils.AuthorizationTypeBearer, " ", serviceToken),
-	}))
+	serviceCtx, cancel := handler.clients.NewServiceContext(ctx, serviceToken)
 	defer cancel()
 
 	response, err := orderClient.CreateOrder(serviceCtx, req)
 	if err != nil {
-		handler.log.LogError("error parsing order service context :", err)
+		handler.log.With(ctx).LogError("error parsing order service context :", err)
 		return nil, err
 	}
 	return response, nil
@@ -92,7 +83,7 @@ func (handler *userService) GetUserOrders(ctx context.Context, in *proto.GetOrde
 	payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
 	if !ok {
 		err := errors.New("unable to retrieve user payload from context")
-		handler.log.LogError("Error", err)
+		handler.log.With(ctx).LogError("Error", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
@@ -101,33 +92,28 @@ func (handler *userService) GetUserOrders(ctx context.Context, in *proto.GetOrde
 	}
 	err := helpers.ValidateBody(nil, req)
 	if err != nil {
-		handler.log.LogError("Error while ValidateBody", err)
+		handler.log.With(ctx).LogError("Error while ValidateBody", err)
 		return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
 	}
 
 	serviceToken, err := handler.token.CreateServiceToken(payload.UserID, payload.UserType, "order")
 	if err != nil {
-		handler.log.LogError("error while generating service token in CreateOrder", err)
+		handler.log.With(ctx).LogError("error while generating service token in CreateOrder", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
-	conn, err := service_helper.ConnectEndpoints(handler.config.ServerAddress.Order.Address, "order", handler.log)
+	orderClient, err := handler.clients.OrderClient()
 	if err != nil {
-		handler.log.LogError("error while connecting order service :", err)
+		handler.log.With(ctx).LogError("error while connecting order service :", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
-	defer conn.Close()
 
-	orderClient := proto.NewOrderServiceClient(conn)
-	serviceCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	serviceCtx = metadata.NewOutgoingContext(serviceCtx, metadata.New(map[string]string{
-		token.ServiceToken: fmt.Sprint(utils.AuthorizationTypeBearer, " ", serviceToken),
-	}))
+	serviceCtx, cancel := handler.clients.NewServiceContext(ctx, serviceToken)
 	defer cancel()
 
 	response, err := orderClient.GetUserOrders(serviceCtx, in)
 	if err != nil {
-		handler.log.LogError("error parsing order service context :", err)
+		handler.log.With(ctx).LogError("error parsing order service context :", err)
 		return nil, err
 	}
 	return response, nil
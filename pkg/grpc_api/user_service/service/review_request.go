@@ -3,15 +3,11 @@ package service
 import (
 	"context"
 	"errors"
-	"fmt"
-	"time"
 
 	"github.com/akmal4410/gestapo/pkg/api/proto"
-	"github.com/akmal4410/gestapo/pkg/helpers/service_helper"
 	"github.com/akmal4410/gestapo/pkg/helpers/token"
 	"github.com/akmal4410/gestapo/pkg/utils"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
@@ -19,53 +15,48 @@ func (handler *userService) AddProductReview(ctx context.Context, in *proto.AddR
 	payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
 	if !ok {
 		err := errors.New("unable to retrieve user payload from context")
-		handler.log.LogError("Error", err)
+		handler.log.With(ctx).LogError("Error", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
-	res, err := handler.storage.CheckDataExist("products", "id", in.GetProductId())
+	res, err := handler.storage.CheckDataExist(ctx, "products", "id", in.GetProductId())
 	if err != nil {
-		handler.log.LogError("Error while CheckDataExist", err)
+		handler.log.With(ctx).LogError("Error while CheckDataExist", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 	if !res {
-		handler.log.LogError("product not found")
+		handler.log.With(ctx).LogError("product not found")
 		return nil, status.Errorf(codes.NotFound, utils.NotFound)
 	}
 
-	res, err = handler.storage.CheckDataExist("order_items", "id", in.GetOrderItemId())
+	res, err = handler.storage.CheckDataExist(ctx, "order_items", "id", in.GetOrderItemId())
 	if err != nil {
-		handler.log.LogError("Error while CheckDataExist", err)
+		handler.log.With(ctx).LogError("Error while CheckDataExist", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 	if !res {
-		handler.log.LogError("order item not found")
+		handler.log.With(ctx).LogError("order item not found")
 		return nil, status.Errorf(codes.NotFound, utils.NotFound)
 	}
 
 	serviceToken, err := handler.token.CreateServiceToken(payload.UserID, payload.UserType, "product")
 	if err != nil {
-		handler.log.LogError("error while generating service token in AddProductReview", err)
+		handler.log.With(ctx).LogError("error while generating service token in AddProductReview", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
-	conn, err := service_helper.ConnectEndpoints(handler.config.ServerAddress.Product.Address, "product", handler.log)
+	productClient, err := handler.clients.ProductClient()
 	if err != nil {
-		handler.log.LogError("error while connecting order service :", err)
+		handler.log.With(ctx).LogError("error while connecting order service :", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
-	defer conn.Close()
 
-	productClient := proto.NewProductServiceClient(conn)
-	serviceCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	serviceCtx = metadata.NewOutgoingContext(serviceCtx, metadata.New(map[string]string{
-		token.ServiceToken: fmt.Sprint(utils.AuthorizationTypeBearer, " ", serviceToken),
-	}))
+	serviceCtx, cancel := handler.clients.NewServiceContext(ctx, serviceToken)
 	defer cancel()
 
 	response, err := productClient.AddProductReview(serviceCtx, in)
 	if err != nil {
-		handler.log.LogError("error parsing product service context :", err)
+		handler.log.With(ctx).LogError("error parsing product service context :", err)
 		return nil, err
 	}
 	return response, nil
@@ -6,34 +6,55 @@ import (
 	"github.com/akmal4410/gestapo/pkg/api/proto"
 	"github.com/akmal4410/gestapo/pkg/grpc_api/user_service/db"
 	"github.com/akmal4410/gestapo/pkg/helpers/logger"
+	"github.com/akmal4410/gestapo/pkg/helpers/service_helper"
 	"github.com/akmal4410/gestapo/pkg/helpers/token"
-	s3 "github.com/akmal4410/gestapo/pkg/service/s3_service"
+	objectstore "github.com/akmal4410/gestapo/pkg/service/object_store"
+	urlsigner "github.com/akmal4410/gestapo/pkg/service/url_signer"
 )
 
 type userService struct {
 	*proto.UnimplementedUserServieServer
 	config  *config.Config
 	log     logger.Logger
-	s3      *s3.S3Service
-	storage *db.UserStore
+	urls    *urlsigner.Signer
+	storage db.UserRepository
 	token   token.Maker
+	clients *service_helper.ClientRegistry
+}
+
+// Dependencies are the stores and the clients used by the user service.
+type Dependencies struct {
+	Store   db.UserRepository
+	URLs    *urlsigner.Signer
+	Clients *service_helper.ClientRegistry
 }
 
 // NewUserService creates a new gRPC server.
 func NewUserService(storage *database.Storage, config *config.Config, log logger.Logger, tokenMaker token.Maker) *userService {
-	server := &userService{
-		config: config,
-		log:    log,
-		token:  tokenMaker,
+	files, err := objectstore.New(config)
+	if err != nil {
+		log.LogFatal("Error while Initializing object store ", err)
+	}
+	urls, err := urlsigner.New(config, files, log)
+	if err != nil {
+		log.LogFatal("Error while Initializing url signer ", err)
+	}
+	deps := Dependencies{
+		Store:   db.NewUserStore(storage),
+		URLs:    urls,
+		Clients: service_helper.NewClientRegistry(config.ServerAddress, config.GRPCClient, log),
+	}
+	return NewUserServiceWith(deps, config, log, tokenMaker)
+}
+
+// NewUserServiceWith creates a new gRPC server working with deps.
+func NewUserServiceWith(deps Dependencies, config *config.Config, log logger.Logger, tokenMaker token.Maker) *userService {
+	return &userService{
+		config:  config,
+		log:     log,
+		token:   tokenMaker,
+		urls:    deps.URLs,
+		storage: deps.Store,
+		clients: deps.Clients,
 	}
-	s3 := s3.NewS3Service(
-		config.AwsS3.BucketName,
-		config.AwsS3.Region,
-		config.AwsS3.AccessKey,
-		config.AwsS3.SecretKey,
-	)
-	userStore := db.NewUserStore(storage)
-	server.s3 = s3
-	server.storage = userStore
-	return server
 }
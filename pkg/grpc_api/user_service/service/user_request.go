@@ -4,53 +4,48 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"fmt"
 	"net/http"
-	"time"
 
 	"github.com/akmal4410/gestapo/pkg/api/proto"
-	"github.com/akmal4410/gestapo/pkg/helpers/service_helper"
 	"github.com/akmal4410/gestapo/pkg/helpers/token"
+	"github.com/akmal4410/gestapo/pkg/service/images"
 	"github.com/akmal4410/gestapo/pkg/utils"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
 func (handler *userService) GetHome(ctx context.Context, req *proto.Request) (*proto.GetHomeResponse, error) {
-	discount, err := handler.storage.GetDiscount()
+	discount, err := handler.storage.GetDiscount(ctx)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			handler.log.LogError("Error while GetDiscount No discouts", err)
+			handler.log.With(ctx).LogError("Error while GetDiscount No discouts", err)
 			discount = nil
 		} else {
-			handler.log.LogError("Error while GetDiscount", err)
+			handler.log.With(ctx).LogError("Error while GetDiscount", err)
 			return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 		}
 	}
 	//Converting the key to presigned url
 
 	if discount != nil {
-		url, err := handler.s3.GetPreSignedURL(discount.ProductImage)
-		if err != nil {
-			handler.log.LogError("Error while GetPreSignedURL product image", err)
-			return nil, status.Errorf(codes.Internal, utils.InternalServerError)
-		}
-		discount.ProductImage = url
+		discount.ProductImage = images.URL(ctx, handler.urls, discount.ProductImage, images.Medium)
 	}
 
-	merchantEntities, err := handler.storage.GetMerchants()
+	merchantEntities, err := handler.storage.GetMerchants(ctx)
 	if err != nil {
-		handler.log.LogError("Error while GetMerchants", err)
+		handler.log.With(ctx).LogError("Error while GetMerchants", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
+	var merchantImages []string
 	for _, merchant := range merchantEntities {
 		if merchant.ImageURL != nil {
-			url, err := handler.s3.GetPreSignedURL(*merchant.ImageURL)
-			if err != nil {
-				handler.log.LogError("Error while GetPreSignedURL for merchant.ImageURL", err)
-				return nil, status.Errorf(codes.Internal, utils.InternalServerError)
-			}
+			merchantImages = append(merchantImages, *merchant.ImageURL)
+		}
+	}
+	urls := images.URLs(ctx, handler.urls, merchantImages, images.Thumbnail)
+	for _, merchant := range merchantEntities {
+		if merchant.ImageURL != nil {
+			url := urls[*merchant.ImageURL]
 			merchant.ImageURL = &url
 		}
 	}
@@ -58,46 +53,39 @@ func (handler *userService) GetHome(ctx context.Context, req *proto.Request) (*p
 	payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
 	if !ok {
 		err := errors.New("unable to retrieve merchant payload from context")
-		handler.log.LogError("Error", err)
+		handler.log.With(ctx).LogError("Error", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
 	serviceToken, err := handler.token.CreateServiceToken(payload.UserID, payload.UserType, "product")
 	if err != nil {
-		handler.log.LogError("error while generating service token in GetProducts", err)
+		handler.log.With(ctx).LogError("error while generating service token in GetProducts", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
-	conn, err := service_helper.ConnectEndpoints(handler.config.ServerAddress.Product.Address, "product", handler.log)
+	productClient, err := handler.clients.ProductClient()
 	if err != nil {
-		handler.log.LogError("error while connecting product service :", err)
+		handler.log.With(ctx).LogError("error while connecting product service :", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
-	defer conn.Close()
 
-	productClient := proto.NewProductServiceClient(conn)
-	serviceCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	serviceCtx = metadata.NewOutgoingContext(serviceCtx, metadata.New(map[string]string{
-		token.ServiceToken: fmt.Sprint(utils.AuthorizationTypeBearer, " ", serviceToken),
-	}))
+	serviceCtx, cancel := handler.clients.NewServiceContext(ctx, serviceToken)
 	defer cancel()
 
 	getProductsRes, err := productClient.GetProducts(serviceCtx, &proto.GetProductRequest{MerchantId: nil})
 	if err != nil {
-		handler.log.LogError("Error while GetProducts", err)
+		handler.log.With(ctx).LogError("Error while GetProducts", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
-	for _, product := range getProductsRes.Data {
-		for i, image := range product.ProductImages {
-			url, err := handler.s3.GetPreSignedURL(image)
-			if err != nil {
-				handler.log.LogError("Error while GetPreSignedURL", err)
-				return nil, status.Errorf(codes.Internal, utils.InternalServerError)
-			}
-			product.ProductImages[i] = url
-		}
-
+	// the home is still served without the recommendations when they fail
+	var recommended []*proto.ProductResponse
+	recommendedRes, err := productClient.GetRecommendedForUser(serviceCtx, &proto.GetRecommendedForUserRequest{})
+	if err != nil {
+		handler.log.With(ctx).LogWarn("Error while GetRecommendedForUser", err)
+	} else {
+		recommended = recommendedRes.Data
 	}
+	// the product service already returns the signed URLs of the images
 	var discountRes *proto.DiscountResponse
 	if discount != nil {
 		discountRes = &proto.DiscountResponse{
@@ -121,9 +109,10 @@ func (handler *userService) GetHome(ctx context.Context, req *proto.Request) (*p
 	}
 
 	home := &proto.HomeResponse{
-		Discount:  discountRes,
-		Merchants: merchants,
-		Products:  getProductsRes.Data,
+		Discount:    discountRes,
+		Merchants:   merchants,
+		Products:    getProductsRes.Data,
+		Recommended: recommended,
 	}
 	response := &proto.GetHomeResponse{
 		Code:    http.StatusOK,
@@ -10,6 +10,7 @@ import (
 	"github.com/akmal4410/gestapo/pkg/grpc_api/user_service/db/entity"
 	"github.com/akmal4410/gestapo/pkg/helpers"
 	"github.com/akmal4410/gestapo/pkg/helpers/token"
+	"github.com/akmal4410/gestapo/pkg/service/images"
 	"github.com/akmal4410/gestapo/pkg/utils"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -19,7 +20,7 @@ func (handler *userService) AddRemoveWishlist(ctx context.Context, in *proto.Add
 	payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
 	if !ok {
 		err := errors.New("unable to retrieve user payload from context")
-		handler.log.LogError("Error", err)
+		handler.log.With(ctx).LogError("Error", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
@@ -30,45 +31,45 @@ func (handler *userService) AddRemoveWishlist(ctx context.Context, in *proto.Add
 	}
 	err := helpers.ValidateBody(nil, req)
 	if err != nil {
-		handler.log.LogError("Error while ValidateBody", err)
+		handler.log.With(ctx).LogError("Error while ValidateBody", err)
 		return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
 	}
 
-	res, err := handler.storage.CheckDataExist("products", "id", req.ProductID)
+	res, err := handler.storage.CheckDataExist(ctx, "products", "id", req.ProductID)
 	if err != nil {
-		handler.log.LogError("Error while CheckUserExist", err)
+		handler.log.With(ctx).LogError("Error while CheckUserExist", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 	if !res {
 		err = fmt.Errorf("product does'nt exist using %s", req.ProductID)
-		handler.log.LogError(err)
+		handler.log.With(ctx).LogError(err)
 		return nil, status.Errorf(codes.NotFound, err.Error())
 	}
 
 	switch req.Action {
 	case utils.ADD_WISHLIST:
-		return handler.addToWishlist(req)
+		return handler.addToWishlist(ctx, req)
 	case utils.REMOVE_WISHLIST:
-		return handler.removeFromWishlist(req)
+		return handler.removeFromWishlist(ctx, req)
 	default:
 		return nil, status.Errorf(codes.InvalidArgument, utils.InvalidRequest)
 	}
 }
 
-func (handler *userService) addToWishlist(req *entity.AddRemoveWishlistReq) (*proto.Response, error) {
-	res, err := handler.storage.AlreadyInWishlist(req)
+func (handler *userService) addToWishlist(ctx context.Context, req *entity.AddRemoveWishlistReq) (*proto.Response, error) {
+	res, err := handler.storage.AlreadyInWishlist(ctx, req)
 	if err != nil {
-		handler.log.LogError("Error while AlreadyInWishlist", err)
+		handler.log.With(ctx).LogError("Error while AlreadyInWishlist", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 	if res {
-		handler.log.LogError("Error while AlreadyInWishlist", err)
+		handler.log.With(ctx).LogError("Error while AlreadyInWishlist", err)
 		return nil, status.Errorf(codes.AlreadyExists, utils.AlreadyExists)
 	}
 
-	err = handler.storage.AddToWishlist(req)
+	err = handler.storage.AddToWishlist(ctx, req)
 	if err != nil {
-		handler.log.LogError("Error while AddToWishlist", err)
+		handler.log.With(ctx).LogError("Error while AddToWishlist", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
@@ -80,20 +81,20 @@ func (handler *userService) addToWishlist(req *entity.AddRemoveWishlistReq) (*pr
 
 	return response, nil
 }
-func (handler *userService) removeFromWishlist(req *entity.AddRemoveWishlistReq) (*proto.Response, error) {
-	res, err := handler.storage.AlreadyInWishlist(req)
+func (handler *userService) removeFromWishlist(ctx context.Context, req *entity.AddRemoveWishlistReq) (*proto.Response, error) {
+	res, err := handler.storage.AlreadyInWishlist(ctx, req)
 	if err != nil {
-		handler.log.LogError("Error while AlreadyInWishlist", err)
+		handler.log.With(ctx).LogError("Error while AlreadyInWishlist", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 	if !res {
-		handler.log.LogError("Error while AlreadyInWishlist", err)
+		handler.log.With(ctx).LogError("Error while AlreadyInWishlist", err)
 		return nil, status.Errorf(codes.NotFound, utils.NotFound)
 	}
 
-	err = handler.storage.RemoveFromWishlist(req)
+	err = handler.storage.RemoveFromWishlist(ctx, req)
 	if err != nil {
-		handler.log.LogError("Error while RemoveFromWishlist", err)
+		handler.log.With(ctx).LogError("Error while RemoveFromWishlist", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
@@ -110,24 +111,22 @@ func (handler *userService) GetWishlist(ctx context.Context, in *proto.Request)
 	payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
 	if !ok {
 		err := errors.New("unable to retrieve user payload from context")
-		handler.log.LogError("Error", err)
+		handler.log.With(ctx).LogError("Error", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
 
-	productEntities, err := handler.storage.GetWishlistProducts(payload.UserID)
+	productEntities, err := handler.storage.GetWishlistProducts(ctx, payload.UserID)
 	if err != nil {
-		handler.log.LogError("Error while GetWishlistProducts", err)
+		handler.log.With(ctx).LogError("Error while GetWishlistProducts", err)
 		return nil, status.Errorf(codes.Internal, utils.InternalServerError)
 	}
+	var productImages []string
 	for _, product := range productEntities {
-		if product.ProductImages[0] != "" {
-			url, err := handler.s3.GetPreSignedURL(product.ProductImages[0])
-			if err != nil {
-				handler.log.LogError("Error while GetPreSignedURL for product.ProductImages[0]", err)
-				return nil, status.Errorf(codes.Internal, utils.InternalServerError)
-			}
-			product.ProductImages[0] = url
-		}
+		productImages = append(productImages, product.ProductImages...)
+	}
+	urls := images.URLs(ctx, handler.urls, productImages, images.Medium)
+	for _, product := range productEntities {
+		product.ProductImages = images.Pick(product.ProductImages, urls)
 	}
 
 	var products []*proto.ProductResponse
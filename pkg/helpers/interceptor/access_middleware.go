@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/akmal4410/gestapo/pkg/helpers/logger"
+	"github.com/akmal4410/gestapo/pkg/helpers/service_helper"
 	"github.com/akmal4410/gestapo/pkg/helpers/token"
 	"github.com/akmal4410/gestapo/pkg/utils"
 	"google.golang.org/grpc"
@@ -33,12 +35,27 @@ const (
 )
 const (
 	getAddresses string = "/pb.UserServie/GetAddresses"
+	// the merchants are notified of the questions on their products
+	getNotifications      string = "/pb.UserServie/GetNotifications"
+	markNotificationsRead string = "/pb.UserServie/MarkNotificationsRead"
+)
+
+// For the guests browsing without signing in, identified by their device
+const (
+	getProductByIdRPC      string = "/pb.ProductService/GetProductById"
+	getRecentlyViewedRPC   string = "/pb.ProductService/GetRecentlyViewed"
+	clearRecentlyViewedRPC string = "/pb.ProductService/ClearRecentlyViewed"
+	// the guest carts are merged into the cart of the user at sign in
+	addProductToCartRPC       string = "/pb.UserServie/AddProductToCart"
+	getCartItemsRPC           string = "/pb.UserServie/GetCartItmes"
+	updateCartItemQuantityRPC string = "/pb.UserServie/UpdateCartItemQuantity"
+	removeProductFromCartRPC  string = "/pb.UserServie/RemoveProductFromCart"
 )
 
 // AccessMiddleware is a gRPC unary server interceptor for access.
 func (interceptor *Interceptor) AccessMiddleware() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		interceptor.log.LogInfo("Calling gRPC meathod :", info.FullMethod)
+		interceptor.log.With(ctx).LogDebug("Calling gRPC meathod :", info.FullMethod)
 		//	skipping the authentication middlware because it don't have access token
 		//	so add method names that wanted to skip inside this
 		if ok := skipAuthenticationBetweenRPC(info.FullMethod); ok {
@@ -47,14 +64,38 @@ func (interceptor *Interceptor) AccessMiddleware() grpc.UnaryServerInterceptor {
 		md, ok := metadata.FromIncomingContext(ctx)
 		if !ok {
 			err := errors.New("metadata is not provided")
-			interceptor.log.LogError("Error : ", err)
+			interceptor.log.With(ctx).LogError("Error : ", err)
 			return nil, status.Errorf(codes.Unauthenticated, err.Error())
 		}
 
 		authorizationHeaders := md.Get(utils.AuthorizationKey)
+		if len(authorizationHeaders) == 0 && len(md.Get(token.ServiceToken)) != 0 {
+			// calls from other services carry the user of the original request in a service token
+			servicePayload, err := service_helper.ValidateServiceToken(ctx, interceptor.log, interceptor.token)
+			if err != nil {
+				return nil, err
+			}
+			if !isServiceTokenFor(info.FullMethod, servicePayload.ServiceName) {
+				err := fmt.Errorf("service token for %q cannot call %s", servicePayload.ServiceName, info.FullMethod)
+				interceptor.log.With(ctx).LogError("Error : ", err)
+				return nil, status.Errorf(codes.Unauthenticated, err.Error())
+			}
+			payload := &token.AccessPayload{
+				UserID:    servicePayload.UserID,
+				UserType:  servicePayload.UserType,
+				TokenType: "access-token",
+			}
+			ctx = context.WithValue(ctx, utils.AuthorizationPayloadKey, payload)
+			ctx = logger.ContextWithUserID(ctx, payload.UserID)
+			return handler(ctx, req)
+		}
+		if len(authorizationHeaders) == 0 && isGuestCanAccess(info.FullMethod) && len(md.Get(utils.DeviceIDKey)) != 0 {
+			// the handler reads the device of the guest from the metadata, there is no payload
+			return handler(ctx, req)
+		}
 		if len(authorizationHeaders) == 0 {
 			err := errors.New("authorization header is not provided")
-			interceptor.log.LogError("Error : ", err)
+			interceptor.log.With(ctx).LogError("Error : ", err)
 			return nil, status.Errorf(codes.Unauthenticated, err.Error())
 		}
 
@@ -62,14 +103,14 @@ func (interceptor *Interceptor) AccessMiddleware() grpc.UnaryServerInterceptor {
 		fields := strings.Fields(authorizationHeader)
 		if len(fields) < 2 {
 			err := errors.New("invalid authorization header format")
-			interceptor.log.LogError("Error : ", err)
+			interceptor.log.With(ctx).LogError("Error : ", err)
 			return nil, status.Errorf(codes.Unauthenticated, err.Error())
 		}
 
 		authorizationType := strings.ToLower(fields[0])
 		if authorizationType != utils.AuthorizationTypeBearer {
 			err := fmt.Errorf("unsupported authorization type: %s", authorizationType)
-			interceptor.log.LogError("Error : ", err)
+			interceptor.log.With(ctx).LogError("Error : ", err)
 			return nil, status.Errorf(codes.Unauthenticated, err.Error())
 		}
 
@@ -78,19 +119,20 @@ func (interceptor *Interceptor) AccessMiddleware() grpc.UnaryServerInterceptor {
 		payload, err := interceptor.token.VerifyAccessToken(token)
 		if err != nil {
 			err := fmt.Errorf("error while VerifyAccessToken: %s", err.Error())
-			interceptor.log.LogError("Error : ", err)
+			interceptor.log.With(ctx).LogError("Error : ", err)
 			return nil, status.Errorf(codes.Unauthenticated, "token is expired")
 		}
 
 		if payload.TokenType != "access-token" {
 			err := fmt.Errorf("invalid token type: %s", payload.TokenType)
-			interceptor.log.LogError("Error", err)
+			interceptor.log.With(ctx).LogError("Error", err)
 			return nil, status.Errorf(codes.Unauthenticated, err.Error())
 
 		}
 
 		// Add the payload to the context
 		ctx = context.WithValue(ctx, utils.AuthorizationPayloadKey, payload)
+		ctx = logger.ContextWithUserID(ctx, payload.UserID)
 
 		return handler(ctx, req)
 	}
@@ -105,12 +147,12 @@ func (interceptor *Interceptor) MerchantRoleMiddleware() grpc.UnaryServerInterce
 		payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
 		if !ok {
 			err := errors.New("unable to retrieve user payload from context")
-			interceptor.log.LogError("Error", err)
+			interceptor.log.With(ctx).LogError("Error", err)
 			return nil, status.Errorf(codes.Internal, err.Error())
 		}
 		if payload.UserType != utils.MERCHANT {
 			err := fmt.Errorf("user does not have required role: %s", utils.MERCHANT)
-			interceptor.log.LogError("Error", err)
+			interceptor.log.With(ctx).LogError("Error", err)
 			return nil, status.Errorf(codes.PermissionDenied, err.Error())
 		}
 		ctx = context.WithValue(ctx, utils.AuthorizationPayloadKey, payload)
@@ -123,12 +165,12 @@ func (interceptor *Interceptor) AdminRoleMiddleware() grpc.UnaryServerIntercepto
 		payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
 		if !ok {
 			err := errors.New("unable to retrieve user payload from context")
-			interceptor.log.LogError("Error", err)
+			interceptor.log.With(ctx).LogError("Error", err)
 			return nil, status.Errorf(codes.Internal, err.Error())
 		}
 		if payload.UserType != utils.ADMIN {
 			err := fmt.Errorf("user does not have required role: %s", utils.ADMIN)
-			interceptor.log.LogError("Error", err)
+			interceptor.log.With(ctx).LogError("Error", err)
 			return nil, status.Errorf(codes.PermissionDenied, err.Error())
 		}
 		ctx = context.WithValue(ctx, utils.AuthorizationPayloadKey, payload)
@@ -143,14 +185,18 @@ func (interceptor *Interceptor) UserRoleMiddleware() grpc.UnaryServerInterceptor
 			return handler(ctx, req)
 		}
 		payload, ok := ctx.Value(utils.AuthorizationPayloadKey).(*token.AccessPayload)
+		if !ok && isGuestCanAccess(info.FullMethod) {
+			// a guest let in by AccessMiddleware, identified by its device
+			return handler(ctx, req)
+		}
 		if !ok {
 			err := errors.New("unable to retrieve user payload from context")
-			interceptor.log.LogError("Error", err)
+			interceptor.log.With(ctx).LogError("Error", err)
 			return nil, status.Errorf(codes.Internal, err.Error())
 		}
 		if payload.UserType != utils.USER {
 			err := fmt.Errorf("user does not have required role: %s", utils.MERCHANT)
-			interceptor.log.LogError("Error", err)
+			interceptor.log.With(ctx).LogError("Error", err)
 			return nil, status.Errorf(codes.PermissionDenied, err.Error())
 		}
 		ctx = context.WithValue(ctx, utils.AuthorizationPayloadKey, payload)
@@ -179,9 +225,27 @@ func skipAuthenticationBetweenRPC(method string) bool {
 	return false
 }
 
+func isGuestCanAccess(method string) bool {
+	switch method {
+	case getProductByIdRPC, getRecentlyViewedRPC, clearRecentlyViewedRPC:
+		return true
+	case addProductToCartRPC, getCartItemsRPC, updateCartItemQuantityRPC, removeProductFromCartRPC:
+		return true
+	}
+	return false
+}
+
+// isServiceTokenFor reports whether a service token made for serviceName, like
+// "product" or "order", is for the service of the method, like
+// "/pb.ProductService/GetProducts".
+func isServiceTokenFor(method, serviceName string) bool {
+	service, _, _ := strings.Cut(strings.TrimPrefix(method, "/pb."), "/")
+	return serviceName != "" && strings.HasPrefix(strings.ToLower(service), serviceName)
+}
+
 func isUserServiceOtherCanAccess(method string) bool {
 	switch method {
-	case getAddresses:
+	case getAddresses, getNotifications, markNotificationsRead:
 		return true
 	}
 	return false
@@ -0,0 +1,127 @@
+package interceptor
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/akmal4410/gestapo/pkg/helpers/logger"
+	"github.com/akmal4410/gestapo/pkg/helpers/token"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func newTestInterceptor(t *testing.T) (*Interceptor, token.Maker) {
+	t.Helper()
+	tokenMaker, err := token.NewJWTMaker("01234567890123456789012345678901")
+	if err != nil {
+		t.Fatalf("NewJWTMaker: %v", err)
+	}
+	return NewInterceptor(tokenMaker, logger.NewWriterLogger("interceptor", io.Discard)), tokenMaker
+}
+
+// payloadHandler returns the access payload the interceptor put in the context.
+func payloadHandler(ctx context.Context, req interface{}) (interface{}, error) {
+	return ctx.Value(utils.AuthorizationPayloadKey), nil
+}
+
+func TestAccessMiddleware(t *testing.T) {
+	interceptor, tokenMaker := newTestInterceptor(t)
+	accessToken, _ := tokenMaker.CreateAccessToken("user-id", "user", utils.USER)
+	sessionToken, _ := tokenMaker.CreateSessionToken("a@example.com", utils.SIGN_UP)
+	serviceToken, _ := tokenMaker.CreateServiceToken("merchant-id", utils.MERCHANT, "product")
+	orderToken, _ := tokenMaker.CreateServiceToken("merchant-id", utils.MERCHANT, "order")
+	info := &grpc.UnaryServerInfo{FullMethod: "/pb.ProductService/GetProductById"}
+
+	tests := []struct {
+		name   string
+		md     metadata.MD
+		code   codes.Code
+		userID string
+	}{
+		{name: "no metadata", code: codes.Unauthenticated},
+		{name: "no authorization", md: metadata.Pairs("other", "value"), code: codes.Unauthenticated},
+		{name: "malformed", md: metadata.Pairs(utils.AuthorizationKey, accessToken), code: codes.Unauthenticated},
+		{name: "basic", md: metadata.Pairs(utils.AuthorizationKey, "basic "+accessToken), code: codes.Unauthenticated},
+		{name: "invalid token", md: metadata.Pairs(utils.AuthorizationKey, "bearer invalid"), code: codes.Unauthenticated},
+		{name: "session token", md: metadata.Pairs(utils.AuthorizationKey, "bearer "+sessionToken), code: codes.Unauthenticated},
+		{name: "access token", md: metadata.Pairs(utils.AuthorizationKey, "Bearer "+accessToken), userID: "user-id"},
+		{name: "service token", md: metadata.Pairs(token.ServiceToken, "bearer "+serviceToken), userID: "merchant-id"},
+		{name: "session token as service token", md: metadata.Pairs(token.ServiceToken, "bearer "+sessionToken), code: codes.Unauthenticated},
+		{name: "access token as service token", md: metadata.Pairs(token.ServiceToken, "bearer "+accessToken), code: codes.Unauthenticated},
+		{name: "service token of another service", md: metadata.Pairs(token.ServiceToken, "bearer "+orderToken), code: codes.Unauthenticated},
+		{name: "guest", md: metadata.Pairs(utils.DeviceIDKey, "device-id")},
+		{name: "guest with an invalid token", md: metadata.Pairs(utils.DeviceIDKey, "device-id", utils.AuthorizationKey, "bearer invalid"), code: codes.Unauthenticated},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			if test.md != nil {
+				ctx = metadata.NewIncomingContext(ctx, test.md)
+			}
+			res, err := interceptor.AccessMiddleware()(ctx, nil, info, payloadHandler)
+			if status.Code(err) != test.code {
+				t.Fatalf("expected %s, got %v", test.code, err)
+			}
+			if test.code != codes.OK {
+				return
+			}
+			payload, ok := res.(*token.AccessPayload)
+			if test.userID == "" && payload == nil {
+				return
+			}
+			if !ok || payload.UserID != test.userID {
+				t.Fatalf("unexpected payload %+v", res)
+			}
+		})
+	}
+}
+
+func TestAccessMiddlewareSkipsCallsBetweenServices(t *testing.T) {
+	interceptor, _ := newTestInterceptor(t)
+	info := &grpc.UnaryServerInfo{FullMethod: getProductRPC}
+
+	_, err := interceptor.AccessMiddleware()(context.Background(), nil, info, payloadHandler)
+	if err != nil {
+		t.Fatalf("expected %s to skip the authentication, got %v", getProductRPC, err)
+	}
+}
+
+func TestRoleMiddlewares(t *testing.T) {
+	interceptor, _ := newTestInterceptor(t)
+	userCtx := context.WithValue(context.Background(), utils.AuthorizationPayloadKey, &token.AccessPayload{UserID: "user-id", UserType: utils.USER})
+	merchantCtx := context.WithValue(context.Background(), utils.AuthorizationPayloadKey, &token.AccessPayload{UserID: "merchant-id", UserType: utils.MERCHANT})
+
+	deleteProduct := &grpc.UnaryServerInfo{FullMethod: deletProduct}
+	if _, err := interceptor.MerchantRoleMiddleware()(userCtx, nil, deleteProduct, payloadHandler); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected a user to be refused, got %v", err)
+	}
+	if _, err := interceptor.MerchantRoleMiddleware()(merchantCtx, nil, deleteProduct, payloadHandler); err != nil {
+		t.Fatalf("expected a merchant to be allowed, got %v", err)
+	}
+
+	getWishlist := &grpc.UnaryServerInfo{FullMethod: "/pb.UserServie/GetWishlist"}
+	if _, err := interceptor.UserRoleMiddleware()(merchantCtx, nil, getWishlist, payloadHandler); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected a merchant to be refused, got %v", err)
+	}
+	if _, err := interceptor.UserRoleMiddleware()(merchantCtx, nil, &grpc.UnaryServerInfo{FullMethod: getAddresses}, payloadHandler); err != nil {
+		t.Fatalf("expected a merchant to read the addresses, got %v", err)
+	}
+	if _, err := interceptor.UserRoleMiddleware()(merchantCtx, nil, &grpc.UnaryServerInfo{FullMethod: getNotifications}, payloadHandler); err != nil {
+		t.Fatalf("expected a merchant to read the notifications, got %v", err)
+	}
+
+	if _, err := interceptor.UserRoleMiddleware()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: getCartItemsRPC}, payloadHandler); err != nil {
+		t.Fatalf("expected a guest to read the cart, got %v", err)
+	}
+	if _, err := interceptor.UserRoleMiddleware()(context.Background(), nil, getWishlist, payloadHandler); status.Code(err) != codes.Internal {
+		t.Fatalf("expected a guest to be refused, got %v", err)
+	}
+
+	if _, err := interceptor.AdminRoleMiddleware()(merchantCtx, nil, getWishlist, payloadHandler); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected a merchant to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package interceptor
+
+import (
+	"context"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/helpers/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// LoggingMiddleware is a gRPC unary server interceptor for request logging.
+// It must be the first interceptor of the chain so every log line of the request carries the request ID.
+func (interceptor *Interceptor) LoggingMiddleware() grpc.UnaryServerInterceptor {
+	return RequestLogger(interceptor.log)
+}
+
+// RequestLogger reads the request ID from the incoming metadata, or generates one when the call
+// did not come through the gateway, and attaches it with the RPC name to the context.
+// Once the handler returns, a single entry with the status code and the duration is logged.
+func RequestLogger(log logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		requestID := ""
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if ids := md.Get(logger.RequestIDHeader); len(ids) > 0 {
+				requestID = ids[0]
+			}
+		}
+		if requestID == "" {
+			requestID = logger.NewRequestID()
+		}
+		ctx = logger.ContextWithRequestID(ctx, requestID)
+		ctx = logger.ContextWithRPC(ctx, info.FullMethod)
+		grpc.SetHeader(ctx, metadata.Pairs(logger.RequestIDHeader, requestID))
+
+		start := time.Now()
+		res, err := handler(ctx, req)
+
+		code := status.Code(err)
+		entry := log.With(ctx).WithFields(logger.Fields{
+			"grpc_code":   code.String(),
+			"duration_ms": time.Since(start).Milliseconds(),
+		})
+		switch code {
+		case codes.OK:
+			entry.LogInfo("Finished gRPC method")
+		case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+			entry.LogError("Finished gRPC method", err)
+		default:
+			entry.LogWarn("Finished gRPC method", err)
+		}
+		return res, err
+	}
+}
@@ -0,0 +1,71 @@
+package interceptor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/akmal4410/gestapo/pkg/helpers/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// logEntries decodes the JSON entries written to buf.
+func logEntries(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		entry := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Unmarshal %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestRequestLogger(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/pb.ProductService/GetProducts"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		ctx = logger.ContextWithUserID(ctx, "user-id")
+		return logger.RequestIDFromContext(ctx), nil
+	}
+
+	tests := []struct {
+		name      string
+		md        metadata.MD
+		requestID string
+	}{
+		{name: "from the gateway", md: metadata.Pairs(logger.RequestIDHeader, "request-id"), requestID: "request-id"},
+		{name: "generated"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			ctx := context.Background()
+			if test.md != nil {
+				ctx = metadata.NewIncomingContext(ctx, test.md)
+			}
+			res, err := RequestLogger(logger.NewWriterLogger("interceptor", &buf))(ctx, nil, info, handler)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			requestID := res.(string)
+			if requestID == "" || (test.requestID != "" && requestID != test.requestID) {
+				t.Fatalf("unexpected request id %q in the handler", requestID)
+			}
+
+			entries := logEntries(t, &buf)
+			if len(entries) != 1 {
+				t.Fatalf("expected one entry, got %v", entries)
+			}
+			entry := entries[0]
+			if entry[logger.RequestIDField] != requestID || entry[logger.RPCField] != info.FullMethod ||
+				entry[logger.UserIDField] != "user-id" || entry["grpc_code"] != "OK" {
+				t.Fatalf("unexpected entry %v", entry)
+			}
+		})
+	}
+}
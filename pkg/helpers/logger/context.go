@@ -0,0 +1,123 @@
+package logger
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader is the HTTP header and gRPC metadata key carrying the request ID.
+const RequestIDHeader = "x-request-id"
+
+type contextKey string
+
+const requestContextKey contextKey = "logger_request_context"
+
+// requestContext holds the per request values attached to every log entry.
+// It is never changed once stored, each ContextWith* stores an updated copy.
+type requestContext struct {
+	requestID string
+	rpc       string
+	userID    string
+	// request is shared by all the contexts of one request so that the user
+	// authenticated later in the chain is visible to the outer interceptors too.
+	request *requestState
+}
+
+// requestState holds the values discovered while the request is handled.
+type requestState struct {
+	mu     sync.Mutex
+	userID string
+}
+
+func (state *requestState) setUserID(userID string) {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	state.userID = userID
+}
+
+func (state *requestState) getUserID() string {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	return state.userID
+}
+
+// NewRequestID generates a new request ID.
+func NewRequestID() string {
+	return uuid.NewString()
+}
+
+func getRequestContext(ctx context.Context) (*requestContext, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	reqCtx, ok := ctx.Value(requestContextKey).(*requestContext)
+	return reqCtx, ok
+}
+
+func withRequestContext(ctx context.Context, update func(reqCtx *requestContext)) context.Context {
+	next := requestContext{}
+	if reqCtx, ok := getRequestContext(ctx); ok {
+		next = *reqCtx
+	}
+	update(&next)
+	return context.WithValue(ctx, requestContextKey, &next)
+}
+
+// ContextWithRequestID returns a copy of ctx holding the request ID.
+// It starts a new request, the user of the parent request is not carried over.
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return withRequestContext(ctx, func(reqCtx *requestContext) {
+		reqCtx.requestID = requestID
+		reqCtx.userID = ""
+		reqCtx.request = &requestState{}
+	})
+}
+
+// RequestIDFromContext returns the request ID stored in ctx, if any.
+func RequestIDFromContext(ctx context.Context) string {
+	if reqCtx, ok := getRequestContext(ctx); ok {
+		return reqCtx.requestID
+	}
+	return ""
+}
+
+// ContextWithRPC returns a copy of ctx holding the full RPC method name.
+func ContextWithRPC(ctx context.Context, rpc string) context.Context {
+	return withRequestContext(ctx, func(reqCtx *requestContext) { reqCtx.rpc = rpc })
+}
+
+// ContextWithUserID returns a copy of ctx holding the authenticated user ID.
+// The user is also reported to the contexts of the request above ctx.
+func ContextWithUserID(ctx context.Context, userID string) context.Context {
+	return withRequestContext(ctx, func(reqCtx *requestContext) {
+		reqCtx.userID = userID
+		if reqCtx.request != nil {
+			reqCtx.request.setUserID(userID)
+		}
+	})
+}
+
+// fieldsFromContext collects the logging fields stored in ctx.
+func fieldsFromContext(ctx context.Context) Fields {
+	fields := Fields{}
+	reqCtx, ok := getRequestContext(ctx)
+	if !ok {
+		return fields
+	}
+	if reqCtx.requestID != "" {
+		fields[RequestIDField] = reqCtx.requestID
+	}
+	if reqCtx.rpc != "" {
+		fields[RPCField] = reqCtx.rpc
+	}
+	userID := reqCtx.userID
+	if userID == "" && reqCtx.request != nil {
+		userID = reqCtx.request.getUserID()
+	}
+	if userID != "" {
+		fields[UserIDField] = userID
+	}
+	return fields
+}
@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestContextWithCopiesTheParent(t *testing.T) {
+	parent := ContextWithRequestID(context.Background(), "request")
+	first := ContextWithRPC(parent, "/pb.ProductService/GetProducts")
+	second := ContextWithRPC(parent, "/pb.OrderService/CreateOrder")
+
+	if fields := fieldsFromContext(parent); fields[RPCField] != nil {
+		t.Fatalf("expected the parent without an rpc, got %v", fields)
+	}
+	if fields := fieldsFromContext(first); fields[RPCField] != "/pb.ProductService/GetProducts" || fields[RequestIDField] != "request" {
+		t.Fatalf("unexpected fields of the first context %v", fields)
+	}
+	if fields := fieldsFromContext(second); fields[RPCField] != "/pb.OrderService/CreateOrder" || fields[RequestIDField] != "request" {
+		t.Fatalf("unexpected fields of the second context %v", fields)
+	}
+}
+
+func TestContextWithUserIDReachesTheRequest(t *testing.T) {
+	parent := ContextWithRequestID(context.Background(), "request")
+	ContextWithUserID(ContextWithRPC(parent, "/pb.UserService/GetUser"), "user")
+	if fields := fieldsFromContext(parent); fields[UserIDField] != "user" {
+		t.Fatalf("expected the outer context to see the user, got %v", fields)
+	}
+
+	// a new request does not carry the user of the previous one
+	next := ContextWithRequestID(parent, "next")
+	if fields := fieldsFromContext(next); fields[UserIDField] != nil {
+		t.Fatalf("expected the new request without a user, got %v", fields)
+	}
+	if fields := fieldsFromContext(ContextWithUserID(context.Background(), "user")); fields[UserIDField] != "user" {
+		t.Fatalf("expected the user without a request, got %v", fields)
+	}
+}
+
+func TestContextWithConcurrentRequests(t *testing.T) {
+	base := ContextWithRPC(context.Background(), "/pb.ProductService/GetProducts")
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			requestID := fmt.Sprintf("request-%d", i)
+			userID := fmt.Sprintf("user-%d", i)
+			ctx := ContextWithRequestID(base, requestID)
+			ctx = ContextWithUserID(ctx, userID)
+			fields := fieldsFromContext(ctx)
+			if fields[RequestIDField] != requestID || fields[UserIDField] != userID {
+				t.Errorf("expected %s of %s, got %v", userID, requestID, fields)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if fields := fieldsFromContext(base); fields[RequestIDField] != nil || fields[UserIDField] != nil {
+		t.Fatalf("expected the base context unchanged, got %v", fields)
+	}
+}
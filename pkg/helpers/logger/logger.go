@@ -1,8 +1,28 @@
 package logger
 
+import "context"
+
+// Fields are structured key/value pairs attached to every entry of a Logger.
+type Fields map[string]interface{}
+
+// Keys of the fields attached automatically from the request context.
+const (
+	RequestIDField = "request_id"
+	RPCField       = "rpc"
+	UserIDField    = "user_id"
+	ErrorField     = "error"
+)
+
 type Logger interface {
+	LogDebug(args ...interface{})
 	LogInfo(args ...interface{})
+	LogWarn(args ...interface{})
 	LogError(args ...interface{})
 	LogPanic(args ...interface{})
 	LogFatal(args ...interface{})
+
+	// WithFields returns a Logger which attaches the given fields to every entry.
+	WithFields(fields Fields) Logger
+	// With returns a Logger carrying the request ID, RPC name and user ID found in ctx.
+	With(ctx context.Context) Logger
 }
@@ -1,19 +1,25 @@
 package logger
 
 import (
+	"context"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 	lj "gopkg.in/natefinch/lumberjack.v2"
 )
 
+// LogLevelEnv is the environment variable used to set the minimum log level (debug, info, warn, error).
+const LogLevelEnv = "LOG_LEVEL"
+
 // Log LogrusLogger
 type LogrusLogger struct {
 	infoLogger  *logrus.Logger
 	errorLogger *logrus.Logger
 	panicLogger *logrus.Logger
 	fatalLogger *logrus.Logger
+	fields      Fields
 }
 
 // event stores messages to be logged
@@ -21,27 +27,54 @@ type event struct {
 	name   string
 	level  logrus.Level
 	logger *logrus.Logger
-	args   interface{}
+	args   []interface{}
 }
 
 /*
 Creates a new Logrus Logger pointer to be used for logging.
 This function will need a log ile name.
 The log file name should represent the service name.
+The minimum level is read from the LOG_LEVEL environment variable and defaults to info.
 */
 func NewLogrusLogger(logFileName string) Logger {
+	level, err := logrus.ParseLevel(strings.TrimSpace(os.Getenv(LogLevelEnv)))
+	if err != nil {
+		level = logrus.InfoLevel
+	}
+	return &LogrusLogger{
+		infoLogger:  createLogger("info_"+logFileName, level, false),
+		errorLogger: createLogger("error_"+logFileName, level, false),
+		panicLogger: createLogger("panic_"+logFileName, level, false),
+		fatalLogger: createLogger("fatal_"+logFileName, level, false),
+		fields:      Fields{"service": logFileName},
+	}
+}
+
+// NewWriterLogger creates a Logger writing every level to w only, without log files.
+// It is meant for the tests and the all-in-one mode.
+func NewWriterLogger(serviceName string, w io.Writer) Logger {
+	level, err := logrus.ParseLevel(strings.TrimSpace(os.Getenv(LogLevelEnv)))
+	if err != nil {
+		level = logrus.InfoLevel
+	}
+	logger := logrus.New()
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.SetLevel(level)
+	logger.SetOutput(w)
 	return &LogrusLogger{
-		infoLogger:  createLogger("info_"+logFileName, false),
-		errorLogger: createLogger("error_"+logFileName, false),
-		panicLogger: createLogger("panic_"+logFileName, false),
-		fatalLogger: createLogger("fatal_"+logFileName, false),
+		infoLogger:  logger,
+		errorLogger: logger,
+		panicLogger: logger,
+		fatalLogger: logger,
+		fields:      Fields{"service": serviceName},
 	}
 }
 
-func createLogger(logFileName string, setReportCaller bool) *logrus.Logger {
+func createLogger(logFileName string, level logrus.Level, setReportCaller bool) *logrus.Logger {
 	var logger = logrus.New()
 	logger.SetFormatter(&logrus.JSONFormatter{})
 	logger.SetReportCaller(setReportCaller)
+	logger.SetLevel(level)
 	var loggerRotate = &lj.Logger{
 		Filename:  getLogPath(logFileName),
 		MaxSize:   100,
@@ -68,6 +101,36 @@ func getLogPath(logFileName string) string {
 	return logDirPath + logFileName
 }
 
+// WithFields implements Logger.
+func (l *LogrusLogger) WithFields(fields Fields) Logger {
+	merged := make(Fields, len(l.fields)+len(fields))
+	for key, value := range l.fields {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+	child := *l
+	child.fields = merged
+	return &child
+}
+
+// With implements Logger.
+func (l *LogrusLogger) With(ctx context.Context) Logger {
+	return l.WithFields(fieldsFromContext(ctx))
+}
+
+// LogDebug implements Logger.
+func (l *LogrusLogger) LogDebug(args ...interface{}) {
+	e := &event{
+		name:   "Debug",
+		level:  logrus.DebugLevel,
+		logger: l.infoLogger,
+		args:   args,
+	}
+	l.log(e)
+}
+
 // LogInfo implements Logger.
 func (l *LogrusLogger) LogInfo(args ...interface{}) {
 	e := &event{
@@ -79,6 +142,17 @@ func (l *LogrusLogger) LogInfo(args ...interface{}) {
 	l.log(e)
 }
 
+// LogWarn implements Logger.
+func (l *LogrusLogger) LogWarn(args ...interface{}) {
+	e := &event{
+		name:   "Warn",
+		level:  logrus.WarnLevel,
+		logger: l.errorLogger,
+		args:   args,
+	}
+	l.log(e)
+}
+
 // LogError implements Logger.
 func (l *LogrusLogger) LogError(args ...interface{}) {
 	e := &event{
@@ -98,7 +172,8 @@ func (l *LogrusLogger) LogFatal(args ...interface{}) {
 		logger: l.fatalLogger,
 		args:   args,
 	}
-	event.logger.Fatal(event.level, event.args)
+	message, entry := l.entry(event)
+	entry.Fatal(message)
 }
 
 // LogPanic implements Logger.
@@ -109,10 +184,25 @@ func (l *LogrusLogger) LogPanic(args ...interface{}) {
 		logger: l.panicLogger,
 		args:   args,
 	}
-	event.logger.Panic(event.level, event.args)
+	message, entry := l.entry(event)
+	entry.Panic(message)
 }
 
 func (l *LogrusLogger) log(event *event) {
-	event.logger.Log(event.level, event.args)
+	if !event.logger.IsLevelEnabled(event.level) {
+		return
+	}
+	message, entry := l.entry(event)
+	entry.Log(event.level, message)
 	event = nil
 }
+
+// entry builds the redacted message and the logrus entry carrying the logger fields.
+func (l *LogrusLogger) entry(event *event) (string, *logrus.Entry) {
+	message, err := redactArgs(event.args)
+	fields := redactFields(l.fields)
+	if err != nil {
+		fields[ErrorField] = RedactString(err.Error())
+	}
+	return message, event.logger.WithFields(logrus.Fields(fields))
+}
@@ -0,0 +1,120 @@
+package logger
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@([A-Za-z0-9.\-]+\.[A-Za-z]{2,})`)
+	// phonePattern matches 10 to 15 digits, optionally after a + and separated by single dashes or spaces.
+	// The boundaries and the date shapes are checked by isPhone as Go regexps have no lookaround.
+	phonePattern = regexp.MustCompile(`\+?\d(?:[\- ]?\d){9,14}`)
+	datePattern  = regexp.MustCompile(`^\d{4}-\d{1,2}-\d{1,2}`)
+	otpPattern   = regexp.MustCompile(`(?i)\b(otp|code)(\W{1,3})\d{4,8}\b`)
+)
+
+// sensitiveKeys are field names whose values are never written to the logs.
+var sensitiveKeys = []string{"otp", "verification_code", "password", "token", "phone", "email"}
+
+// isSensitiveKey reports whether a field key holds personally identifiable or secret data.
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPhone reports whether the phonePattern match s[start:end] is a phone number on its own,
+// and not a date, a time or a part of a longer value like an ID, a decimal or a UUID.
+func isPhone(s string, start, end int) bool {
+	if datePattern.MatchString(s[start:end]) {
+		return false
+	}
+	if start > 0 && isPartOfValue(s[start-1]) {
+		return false
+	}
+	if end < len(s) {
+		next := s[end]
+		if isPartOfValue(next) && next != '.' {
+			return false
+		}
+		if next == '.' && end+1 < len(s) && s[end+1] >= '0' && s[end+1] <= '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isPartOfValue(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' ||
+		c == '_' || c == '-' || c == '+' || c == '.' || c == ':' || c == '/'
+}
+
+// RedactString masks email addresses, phone numbers and OTP codes found in s.
+// The domain of an email is kept so that delivery problems can still be diagnosed.
+func RedactString(s string) string {
+	s = otpPattern.ReplaceAllString(s, "$1$2"+redacted)
+	s = emailPattern.ReplaceAllString(s, "***@$1")
+	var result strings.Builder
+	last := 0
+	for _, match := range phonePattern.FindAllStringIndex(s, -1) {
+		start, end := match[0], match[1]
+		if !isPhone(s, start, end) {
+			continue
+		}
+		digits := strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return r
+			}
+			return -1
+		}, s[start:end])
+		result.WriteString(s[last:start])
+		result.WriteString("***" + digits[len(digits)-2:])
+		last = end
+	}
+	result.WriteString(s[last:])
+	return result.String()
+}
+
+// redactFields returns a copy of fields with sensitive values masked.
+func redactFields(fields Fields) Fields {
+	result := make(Fields, len(fields))
+	for key, value := range fields {
+		switch {
+		case key == RequestIDField || key == RPCField || key == UserIDField:
+			result[key] = value
+		case isSensitiveKey(key):
+			result[key] = redacted
+		default:
+			if str, ok := value.(string); ok {
+				value = RedactString(str)
+			} else if err, ok := value.(error); ok {
+				value = RedactString(err.Error())
+			}
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// redactArgs builds the log message from args and masks the PII inside it.
+// The first error found in args is returned separately so it can be logged as a field.
+func redactArgs(args []interface{}) (string, error) {
+	var firstErr error
+	parts := make([]string, 0, len(args))
+	for _, arg := range args {
+		if err, ok := arg.(error); ok && firstErr == nil {
+			firstErr = err
+			continue
+		}
+		parts = append(parts, fmt.Sprint(arg))
+	}
+	return RedactString(strings.Join(parts, " ")), firstErr
+}
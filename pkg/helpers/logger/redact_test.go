@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRedactString(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "email", in: "sent to jane.doe@example.com", want: "sent to ***@example.com"},
+		{name: "e164", in: "sms to +919876543210 failed", want: "sms to ***10 failed"},
+		{name: "e164 with spaces", in: "sms to +91 98765 43210", want: "sms to ***10"},
+		{name: "dashed", in: "call 555-123-4567.", want: "call ***67."},
+		{name: "ten digits", in: "phone=9876543210", want: "phone=***10"},
+		{name: "otp", in: "your otp: 123456", want: "your otp: [REDACTED]"},
+		{name: "code", in: "Code 4321 sent", want: "Code [REDACTED] sent"},
+		{name: "date and hour", in: "expired at 2026-10-19 09:30:00", want: "expired at 2026-10-19 09:30:00"},
+		{name: "date", in: "created 2026-10-19", want: "created 2026-10-19"},
+		{name: "timestamp", in: "at 2026-10-19T09:30:00Z", want: "at 2026-10-19T09:30:00Z"},
+		{name: "uuid", in: "product 550e8400-e29b-41d4-a716-446655440000 not found", want: "product 550e8400-e29b-41d4-a716-446655440000 not found"},
+		{name: "decimal", in: "total 12345678901.50", want: "total 12345678901.50"},
+		{name: "in a word", in: "sku ab1234567890", want: "sku ab1234567890"},
+		{name: "short number", in: "order of 123456789 items", want: "order of 123456789 items"},
+		{name: "too long", in: "id 12345678901234567890", want: "id 12345678901234567890"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := RedactString(test.in); got != test.want {
+				t.Errorf("RedactString(%q) = %q, want %q", test.in, got, test.want)
+			}
+		})
+	}
+}
+
+func TestRedactFields(t *testing.T) {
+	fields := Fields{
+		RequestIDField:  "9876543210",
+		UserIDField:     "user",
+		"otp":           "123456",
+		"user_email":    "jane@example.com",
+		"PhoneNumber":   "+919876543210",
+		"message":       "sent to jane@example.com",
+		"reason":        errors.New("sms to +919876543210 failed"),
+		"duration_ms":   int64(12),
+		"requested_day": "2026-10-19 09",
+	}
+	want := Fields{
+		RequestIDField:  "9876543210",
+		UserIDField:     "user",
+		"otp":           redacted,
+		"user_email":    redacted,
+		"PhoneNumber":   redacted,
+		"message":       "sent to ***@example.com",
+		"reason":        "sms to ***10 failed",
+		"duration_ms":   int64(12),
+		"requested_day": "2026-10-19 09",
+	}
+	if got := redactFields(fields); !reflect.DeepEqual(got, want) {
+		t.Fatalf("redactFields() = %v, want %v", got, want)
+	}
+	if fields["otp"] != "123456" {
+		t.Fatal("expected redactFields not to change its argument")
+	}
+}
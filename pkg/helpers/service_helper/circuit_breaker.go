@@ -0,0 +1,125 @@
+package service_helper
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/helpers/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// CircuitBreaker stops calling a dependency after too many consecutive failures.
+// Once the cooldown has passed a single trial call is let through: if it succeeds the
+// breaker closes again, otherwise it stays open for another cooldown.
+type CircuitBreaker struct {
+	name        string
+	maxFailures int
+	cooldown    time.Duration
+	log         logger.Logger
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+func NewCircuitBreaker(name string, maxFailures int, cooldown time.Duration, log logger.Logger) *CircuitBreaker {
+	return &CircuitBreaker{
+		name:        name,
+		maxFailures: maxFailures,
+		cooldown:    cooldown,
+		log:         log,
+	}
+}
+
+// allow reports whether a call can be made to the dependency.
+func (breaker *CircuitBreaker) allow() bool {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+	switch breaker.state {
+	case breakerOpen:
+		if time.Since(breaker.openedAt) < breaker.cooldown {
+			return false
+		}
+		breaker.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		// only the trial call is allowed until its result is known
+		return false
+	}
+	return true
+}
+
+// record updates the breaker with the result of a call made with ctx.
+func (breaker *CircuitBreaker) record(ctx context.Context, err error) {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+	if isCallerCancellation(ctx, err) {
+		// the deadline or the cancellation of the caller says nothing about the
+		// dependency, a trial call ending so is retried by the next call
+		if breaker.state == breakerHalfOpen {
+			breaker.state = breakerOpen
+		}
+		return
+	}
+	if !isDependencyFailure(err) {
+		if breaker.state != breakerClosed {
+			breaker.log.LogInfo("circuit breaker closed for", breaker.name, "service")
+		}
+		breaker.state = breakerClosed
+		breaker.failures = 0
+		return
+	}
+	breaker.failures++
+	if breaker.state == breakerHalfOpen || breaker.failures >= breaker.maxFailures {
+		if breaker.state != breakerOpen {
+			breaker.log.LogWarn("circuit breaker opened for", breaker.name, "service after", breaker.failures, "failures")
+		}
+		breaker.state = breakerOpen
+		breaker.openedAt = time.Now()
+	}
+}
+
+// UnaryClientInterceptor fails fast with codes.Unavailable while the breaker is open.
+func (breaker *CircuitBreaker) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if !breaker.allow() {
+			return status.Errorf(codes.Unavailable, "circuit breaker is open for %s service", breaker.name)
+		}
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		breaker.record(ctx, err)
+		return err
+	}
+}
+
+// isCallerCancellation reports whether the call failed because the context of
+// the caller was cancelled or its deadline, propagated from the inbound request,
+// passed.
+func isCallerCancellation(ctx context.Context, err error) bool {
+	switch status.Code(err) {
+	case codes.DeadlineExceeded, codes.Canceled:
+		return ctx.Err() != nil
+	}
+	return false
+}
+
+// isDependencyFailure tells apart the errors caused by an unhealthy dependency
+// from the ones caused by the request itself, like NotFound or InvalidArgument.
+func isDependencyFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Unknown:
+		return true
+	}
+	return false
+}
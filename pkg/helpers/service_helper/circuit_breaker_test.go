@@ -0,0 +1,108 @@
+package service_helper
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/akmal4410/gestapo/pkg/helpers/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var testLog = logger.NewWriterLogger("service_helper", io.Discard)
+
+// call makes a call through the interceptor of the breaker, returning result from
+// the dependency, and reports whether the dependency was called.
+func call(breaker *CircuitBreaker, ctx context.Context, result error) (bool, error) {
+	called := false
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		called = true
+		return result
+	}
+	err := breaker.UnaryClientInterceptor()(ctx, "/pb.ProductService/GetProducts", nil, nil, nil, invoker)
+	return called, err
+}
+
+// pastCooldown makes the cooldown of the open breaker over.
+func pastCooldown(breaker *CircuitBreaker) {
+	breaker.mu.Lock()
+	breaker.openedAt = time.Now().Add(-time.Hour)
+	breaker.mu.Unlock()
+}
+
+func TestCircuitBreakerStates(t *testing.T) {
+	breaker := NewCircuitBreaker("product", 2, time.Minute, testLog)
+	unavailable := status.Error(codes.Unavailable, "down")
+	ctx := context.Background()
+
+	// the request errors do not count
+	for i := 0; i < 3; i++ {
+		call(breaker, ctx, status.Error(codes.NotFound, "missing"))
+	}
+	if breaker.state != breakerClosed {
+		t.Fatalf("expected the breaker to stay closed, got %v", breaker.state)
+	}
+
+	call(breaker, ctx, unavailable)
+	if breaker.state != breakerClosed {
+		t.Fatalf("expected the breaker to stay closed after one failure, got %v", breaker.state)
+	}
+	call(breaker, ctx, unavailable)
+	if breaker.state != breakerOpen {
+		t.Fatalf("expected the breaker to open after two failures, got %v", breaker.state)
+	}
+	if called, err := call(breaker, ctx, nil); called || status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected the open breaker to fail fast, got %v %v", called, err)
+	}
+
+	// a failed trial call opens the breaker for another cooldown
+	pastCooldown(breaker)
+	if called, _ := call(breaker, ctx, unavailable); !called || breaker.state != breakerOpen {
+		t.Fatalf("expected the trial call to reopen the breaker, got %v %v", called, breaker.state)
+	}
+	if called, _ := call(breaker, ctx, nil); called {
+		t.Fatal("expected the reopened breaker to fail fast")
+	}
+
+	// only the trial call goes through while it runs, and its success closes the breaker
+	pastCooldown(breaker)
+	if !breaker.allow() || breaker.state != breakerHalfOpen {
+		t.Fatalf("expected a trial call, got %v", breaker.state)
+	}
+	if breaker.allow() {
+		t.Fatal("expected a single trial call in half-open")
+	}
+	breaker.record(ctx, nil)
+	if breaker.state != breakerClosed || breaker.failures != 0 {
+		t.Fatalf("expected the breaker to close, got %v after %d failures", breaker.state, breaker.failures)
+	}
+}
+
+func TestCircuitBreakerIgnoresCallerDeadline(t *testing.T) {
+	breaker := NewCircuitBreaker("product", 1, time.Minute, testLog)
+	deadlineExceeded := status.Error(codes.DeadlineExceeded, "deadline exceeded")
+
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	call(breaker, expired, deadlineExceeded)
+	if breaker.state != breakerClosed || breaker.failures != 0 {
+		t.Fatalf("expected the deadline of the caller not to count, got %v", breaker.state)
+	}
+
+	// a trial call ending with the deadline of its caller lets the next call try again
+	call(breaker, context.Background(), deadlineExceeded)
+	if breaker.state != breakerOpen {
+		t.Fatalf("expected the deadline of the dependency to count, got %v", breaker.state)
+	}
+	pastCooldown(breaker)
+	call(breaker, expired, deadlineExceeded)
+	if breaker.state != breakerOpen {
+		t.Fatalf("expected the breaker back to open, got %v", breaker.state)
+	}
+	if called, err := call(breaker, context.Background(), nil); !called || err != nil || breaker.state != breakerClosed {
+		t.Fatalf("expected the next trial call to close the breaker, got %v %v %v", called, err, breaker.state)
+	}
+}
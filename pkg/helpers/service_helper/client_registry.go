@@ -0,0 +1,203 @@
+package service_helper
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akmal4410/gestapo/internal/config"
+	"github.com/akmal4410/gestapo/pkg/api/proto"
+	"github.com/akmal4410/gestapo/pkg/helpers/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Names of the services that can be called through the ClientRegistry.
+const (
+	ProductService = "product"
+	OrderService   = "order"
+)
+
+const (
+	defaultCallTimeout     = 5 * time.Second
+	defaultMaxAttempts     = 3
+	defaultBreakerFailures = 5
+	defaultBreakerCooldown = 30 * time.Second
+)
+
+// idempotentMethods are retried by the gRPC client when the dependency is unavailable.
+// RPCs that create or change data must never be added here.
+var idempotentMethods = map[string][]string{
+	ProductService: {"GetProducts", "GetProductById", "GetProductReviews"},
+	OrderService:   {"GetUserOrders", "GetMerchantOrders"},
+}
+
+var grpcServiceNames = map[string]string{
+	ProductService: "pb.ProductService",
+	OrderService:   "pb.OrderService",
+}
+
+// ClientRegistry keeps one long-lived connection per dependency, shared by every request.
+// Connections resolve the service address through DNS and balance the calls round robin
+// over all the resolved instances. Each dependency has its own circuit breaker.
+type ClientRegistry struct {
+	addresses map[string]string
+	config    config.GRPCClient
+	log       logger.Logger
+	dialOpts  []grpc.DialOption
+
+	mu    sync.Mutex
+	conns map[string]*grpc.ClientConn
+}
+
+// NewClientRegistry creates a registry for the services found in address.
+// Connections are dialed lazily on first use, with opts added to the default dial options.
+func NewClientRegistry(address *config.ServerAddress, clientConfig *config.GRPCClient, log logger.Logger, opts ...grpc.DialOption) *ClientRegistry {
+	registry := &ClientRegistry{
+		addresses: map[string]string{},
+		log:       log,
+		dialOpts:  opts,
+		conns:     map[string]*grpc.ClientConn{},
+	}
+	if clientConfig != nil {
+		registry.config = *clientConfig
+	}
+	if registry.config.Timeout <= 0 {
+		registry.config.Timeout = defaultCallTimeout
+	}
+	if registry.config.MaxAttempts <= 0 {
+		registry.config.MaxAttempts = defaultMaxAttempts
+	}
+	if registry.config.BreakerFailures <= 0 {
+		registry.config.BreakerFailures = defaultBreakerFailures
+	}
+	if registry.config.BreakerCooldown <= 0 {
+		registry.config.BreakerCooldown = defaultBreakerCooldown
+	}
+	if address != nil {
+		if address.Product != nil {
+			registry.addresses[ProductService] = address.Product.Address
+		}
+		if address.Order != nil {
+			registry.addresses[OrderService] = address.Order.Address
+		}
+	}
+	return registry
+}
+
+// Timeout is the deadline given to a service call when the inbound request has none.
+func (registry *ClientRegistry) Timeout() time.Duration {
+	return registry.config.Timeout
+}
+
+// Conn returns the shared connection of the service, dialing it if needed.
+func (registry *ClientRegistry) Conn(serviceName string) (*grpc.ClientConn, error) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if conn, ok := registry.conns[serviceName]; ok {
+		return conn, nil
+	}
+	address, ok := registry.addresses[serviceName]
+	if !ok || address == "" {
+		return nil, fmt.Errorf("address of %s service is not configured", serviceName)
+	}
+
+	breaker := NewCircuitBreaker(serviceName, registry.config.BreakerFailures, registry.config.BreakerCooldown, registry.log)
+	dialOpts := append([]grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultServiceConfig(registry.serviceConfig(serviceName)),
+		grpc.WithChainUnaryInterceptor(breaker.UnaryClientInterceptor()),
+	}, registry.dialOpts...)
+	conn, err := grpc.Dial(dnsTarget(address), dialOpts...)
+	if err != nil {
+		registry.log.LogError("connection to", serviceName, "(", address, ") failed. Error details:", err)
+		return nil, err
+	}
+	registry.conns[serviceName] = conn
+	return conn, nil
+}
+
+// ProductClient returns a client of the product service using the shared connection.
+func (registry *ClientRegistry) ProductClient() (proto.ProductServiceClient, error) {
+	conn, err := registry.Conn(ProductService)
+	if err != nil {
+		return nil, err
+	}
+	return proto.NewProductServiceClient(conn), nil
+}
+
+// OrderClient returns a client of the order service using the shared connection.
+func (registry *ClientRegistry) OrderClient() (proto.OrderServiceClient, error) {
+	conn, err := registry.Conn(OrderService)
+	if err != nil {
+		return nil, err
+	}
+	return proto.NewOrderServiceClient(conn), nil
+}
+
+// Close closes every connection of the registry.
+func (registry *ClientRegistry) Close() {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	for name, conn := range registry.conns {
+		if err := conn.Close(); err != nil {
+			registry.log.LogError("error while closing connection to", name, "service", err)
+		}
+		delete(registry.conns, name)
+	}
+}
+
+// serviceConfig builds the gRPC service config enabling round robin load balancing
+// and the retry policy of the idempotent methods of the service.
+func (registry *ClientRegistry) serviceConfig(serviceName string) string {
+	type methodName struct {
+		Service string `json:"service"`
+		Method  string `json:"method"`
+	}
+	type retryPolicy struct {
+		MaxAttempts          int      `json:"maxAttempts"`
+		InitialBackoff       string   `json:"initialBackoff"`
+		MaxBackoff           string   `json:"maxBackoff"`
+		BackoffMultiplier    float64  `json:"backoffMultiplier"`
+		RetryableStatusCodes []string `json:"retryableStatusCodes"`
+	}
+	type methodConfig struct {
+		Name        []methodName `json:"name"`
+		RetryPolicy *retryPolicy `json:"retryPolicy"`
+	}
+	serviceConfig := struct {
+		LoadBalancingConfig []map[string]struct{} `json:"loadBalancingConfig"`
+		MethodConfig        []methodConfig        `json:"methodConfig,omitempty"`
+	}{
+		LoadBalancingConfig: []map[string]struct{}{{"round_robin": {}}},
+	}
+
+	var names []methodName
+	for _, method := range idempotentMethods[serviceName] {
+		names = append(names, methodName{Service: grpcServiceNames[serviceName], Method: method})
+	}
+	if len(names) > 0 && registry.config.MaxAttempts > 1 {
+		serviceConfig.MethodConfig = append(serviceConfig.MethodConfig, methodConfig{
+			Name: names,
+			RetryPolicy: &retryPolicy{
+				MaxAttempts:          registry.config.MaxAttempts,
+				InitialBackoff:       "0.1s",
+				MaxBackoff:           "1s",
+				BackoffMultiplier:    2,
+				RetryableStatusCodes: []string{"UNAVAILABLE"},
+			},
+		})
+	}
+	data, _ := json.Marshal(serviceConfig)
+	return string(data)
+}
+
+// dnsTarget makes the address resolve through DNS, so every instance behind it is used.
+func dnsTarget(address string) string {
+	if strings.Contains(address, "://") || strings.HasPrefix(address, "dns:") {
+		return address
+	}
+	return "dns:///" + address
+}
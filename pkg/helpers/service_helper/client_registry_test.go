@@ -0,0 +1,86 @@
+package service_helper
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/akmal4410/gestapo/internal/config"
+)
+
+type testServiceConfig struct {
+	LoadBalancingConfig []map[string]struct{} `json:"loadBalancingConfig"`
+	MethodConfig        []struct {
+		Name []struct {
+			Service string `json:"service"`
+			Method  string `json:"method"`
+		} `json:"name"`
+		RetryPolicy struct {
+			MaxAttempts          int      `json:"maxAttempts"`
+			InitialBackoff       string   `json:"initialBackoff"`
+			MaxBackoff           string   `json:"maxBackoff"`
+			BackoffMultiplier    float64  `json:"backoffMultiplier"`
+			RetryableStatusCodes []string `json:"retryableStatusCodes"`
+		} `json:"retryPolicy"`
+	} `json:"methodConfig"`
+}
+
+func serviceConfig(t *testing.T, registry *ClientRegistry, serviceName string) testServiceConfig {
+	t.Helper()
+	var serviceConfig testServiceConfig
+	if err := json.Unmarshal([]byte(registry.serviceConfig(serviceName)), &serviceConfig); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	return serviceConfig
+}
+
+func TestServiceConfig(t *testing.T) {
+	registry := NewClientRegistry(nil, &config.GRPCClient{MaxAttempts: 4}, testLog)
+
+	product := serviceConfig(t, registry, ProductService)
+	if len(product.LoadBalancingConfig) != 1 {
+		t.Fatalf("unexpected load balancing %v", product.LoadBalancingConfig)
+	}
+	if _, ok := product.LoadBalancingConfig[0]["round_robin"]; !ok {
+		t.Fatalf("expected round robin, got %v", product.LoadBalancingConfig)
+	}
+	if len(product.MethodConfig) != 1 {
+		t.Fatalf("expected one method config, got %v", product.MethodConfig)
+	}
+	methodConfig := product.MethodConfig[0]
+	if len(methodConfig.Name) != len(idempotentMethods[ProductService]) {
+		t.Fatalf("expected the idempotent methods, got %v", methodConfig.Name)
+	}
+	for i, name := range methodConfig.Name {
+		if name.Service != "pb.ProductService" || name.Method != idempotentMethods[ProductService][i] {
+			t.Fatalf("unexpected method %v", name)
+		}
+	}
+	policy := methodConfig.RetryPolicy
+	if policy.MaxAttempts != 4 || policy.InitialBackoff != "0.1s" || policy.MaxBackoff != "1s" || policy.BackoffMultiplier != 2 ||
+		len(policy.RetryableStatusCodes) != 1 || policy.RetryableStatusCodes[0] != "UNAVAILABLE" {
+		t.Fatalf("unexpected retry policy %+v", policy)
+	}
+
+	if order := serviceConfig(t, registry, OrderService); len(order.MethodConfig) != 1 || order.MethodConfig[0].Name[0].Service != "pb.OrderService" {
+		t.Fatalf("unexpected order service config %+v", order)
+	}
+
+	// a single attempt retries nothing
+	registry = NewClientRegistry(nil, &config.GRPCClient{MaxAttempts: 1}, testLog)
+	if product := serviceConfig(t, registry, ProductService); len(product.MethodConfig) != 0 || len(product.LoadBalancingConfig) != 1 {
+		t.Fatalf("expected round robin without retries, got %+v", product)
+	}
+}
+
+func TestDNSTarget(t *testing.T) {
+	tests := map[string]string{
+		"product:9000":          "dns:///product:9000",
+		"dns:///product:9000":   "dns:///product:9000",
+		"passthrough:///bufnet": "passthrough:///bufnet",
+	}
+	for address, want := range tests {
+		if got := dnsTarget(address); got != want {
+			t.Errorf("dnsTarget(%q) = %q, want %q", address, got, want)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package service_helper
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/akmal4410/gestapo/pkg/helpers/logger"
+	"github.com/akmal4410/gestapo/pkg/helpers/token"
+	"github.com/akmal4410/gestapo/pkg/service/images"
+	"github.com/akmal4410/gestapo/pkg/utils"
+	"google.golang.org/grpc/metadata"
+)
+
+// NewServiceContext creates the context used to call another service from the inbound request ctx.
+// The call is cancelled with the inbound request and keeps its deadline; when the inbound request
+// has no deadline the registry timeout is used. The service token and the request ID are sent
+// in the metadata, so the logs of both services can be correlated.
+func (registry *ClientRegistry) NewServiceContext(ctx context.Context, serviceToken string) (context.Context, context.CancelFunc) {
+	var serviceCtx context.Context
+	var cancel context.CancelFunc
+	if _, ok := ctx.Deadline(); ok {
+		serviceCtx, cancel = context.WithCancel(ctx)
+	} else {
+		serviceCtx, cancel = context.WithTimeout(ctx, registry.config.Timeout)
+	}
+	md := metadata.New(map[string]string{
+		token.ServiceToken: fmt.Sprint(utils.AuthorizationTypeBearer, " ", serviceToken),
+	})
+	if requestID := logger.RequestIDFromContext(ctx); requestID != "" {
+		md.Set(logger.RequestIDHeader, requestID)
+	}
+	// the other service returns the images in the format asked by the client
+	if format := metadata.ValueFromIncomingContext(ctx, images.FormatHeader); len(format) != 0 {
+		md.Set(images.FormatHeader, format[0])
+	}
+	return metadata.NewOutgoingContext(serviceCtx, md), cancel
+}
@@ -7,8 +7,6 @@ import (
 	"syscall"
 
 	"github.com/akmal4410/gestapo/pkg/helpers/logger"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 // InitializeService func is called to set the log service and also listen to interrupt signals
@@ -29,12 +27,3 @@ func InitializeService(serviceName string, logFileName string) (context.Context,
 	log.LogInfo(serviceName, "has started")
 	return ctx, log
 }
-
-func ConnectEndpoints(address, serviceName string, log logger.Logger) (*grpc.ClientConn, error) {
-	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	if err != nil {
-		log.LogError("connection to", serviceName, "(", address, ") failed. Error details:", err)
-		return nil, err
-	}
-	return conn, nil
-}
@@ -18,14 +18,14 @@ func ValidateServiceToken(ctx context.Context, log logger.Logger, tokenMaker tok
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
 		err := errors.New("metadata is not provided")
-		log.LogError(err)
+		log.With(ctx).LogError(err)
 		return nil, status.Errorf(codes.Unauthenticated, err.Error())
 	}
 
 	authorizationHeaders := md.Get(token.ServiceToken)
 	if len(authorizationHeaders) == 0 {
 		err := errors.New("authorization header is not provided")
-		log.LogError(err)
+		log.With(ctx).LogError(err)
 		return nil, status.Errorf(codes.Unauthenticated, err.Error())
 	}
 
@@ -33,23 +33,29 @@ func ValidateServiceToken(ctx context.Context, log logger.Logger, tokenMaker tok
 	fields := strings.Fields(authorizationHeader)
 	if len(fields) < 2 {
 		err := errors.New("invalid authorization header format")
-		log.LogError(err)
+		log.With(ctx).LogError(err)
 		return nil, status.Errorf(codes.Unauthenticated, err.Error())
 	}
 
 	authorizationType := strings.ToLower(fields[0])
 	if authorizationType != utils.AuthorizationTypeBearer {
 		err := fmt.Errorf("unsupported authorization type: %s", authorizationType)
-		log.LogError(err)
+		log.With(ctx).LogError(err)
 		return nil, status.Errorf(codes.Unauthenticated, err.Error())
 	}
 
-	token := fields[1]
+	serviceToken := fields[1]
 	// Verify and parse the token
-	payload, err := tokenMaker.VerifyServiceToken(token)
+	payload, err := tokenMaker.VerifyServiceToken(serviceToken)
 	if err != nil {
 		err := fmt.Errorf("error while VerifySessionToken: %s", err.Error())
-		log.LogError(err)
+		log.With(ctx).LogError(err)
+		return nil, status.Errorf(codes.Unauthenticated, err.Error())
+	}
+	// the session and the access tokens are signed with the same key
+	if payload.TokenType != token.ServiceToken {
+		err := fmt.Errorf("invalid token type: %s", payload.TokenType)
+		log.With(ctx).LogError(err)
 		return nil, status.Errorf(codes.Unauthenticated, err.Error())
 	}
 	return payload, nil
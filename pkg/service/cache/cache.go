@@ -1,7 +1,16 @@
 package cache
 
+import (
+	"context"
+	"time"
+)
+
 type Cache interface {
-	Set(key, otp string) error
-	Get(key string) (string, error)
-	Delete(id string) error
+	Set(ctx context.Context, key, otp string) error
+	Get(ctx context.Context, key string) (string, error)
+	Delete(ctx context.Context, id string) error
+	// GetMany returns the values of the keys found, in one round trip
+	GetMany(ctx context.Context, keys []string) (map[string]string, error)
+	// SetMany stores values until expiry, in one round trip
+	SetMany(ctx context.Context, values map[string]string, expiry time.Duration) error
 }
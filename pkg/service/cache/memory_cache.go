@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	value     string
+	expiresAt time.Time
+}
+
+// MemoryCache is an in-process Cache with the same expiry as RedisCache.
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+func NewMemoryCache() Cache {
+	return &MemoryCache{entries: map[string]memoryEntry{}}
+}
+
+func (cache *MemoryCache) Set(ctx context.Context, key, otp string) error {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.entries[key] = memoryEntry{value: otp, expiresAt: time.Now().Add(6 * time.Minute)}
+	return nil
+}
+
+func (cache *MemoryCache) Get(ctx context.Context, key string) (string, error) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	entry, ok := cache.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		delete(cache.entries, key)
+		return "", errors.New("nil")
+	}
+	return entry.value, nil
+}
+
+func (cache *MemoryCache) Delete(ctx context.Context, id string) error {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	delete(cache.entries, id)
+	return nil
+}
+
+func (cache *MemoryCache) GetMany(ctx context.Context, keys []string) (map[string]string, error) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	values := map[string]string{}
+	for _, key := range keys {
+		entry, ok := cache.entries[key]
+		if ok && time.Now().Before(entry.expiresAt) {
+			values[key] = entry.value
+		}
+	}
+	return values, nil
+}
+
+func (cache *MemoryCache) SetMany(ctx context.Context, values map[string]string, expiry time.Duration) error {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	for key, value := range values {
+		cache.entries[key] = memoryEntry{value: value, expiresAt: time.Now().Add(expiry)}
+	}
+	return nil
+}
@@ -31,16 +31,16 @@ func NewRedisCache(redisConfig *config.Redis) (Cache, error) {
 	return &RedisCache{redisClient: client}, nil
 }
 
-func (cache *RedisCache) Set(key, otp string) error {
-	err := cache.redisClient.Set(context.Background(), key, otp, 6*time.Minute).Err()
+func (cache *RedisCache) Set(ctx context.Context, key, otp string) error {
+	err := cache.redisClient.Set(ctx, key, otp, 6*time.Minute).Err()
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (cache *RedisCache) Get(key string) (string, error) {
-	val, err := cache.redisClient.Get(context.Background(), key).Result()
+func (cache *RedisCache) Get(ctx context.Context, key string) (string, error) {
+	val, err := cache.redisClient.Get(ctx, key).Result()
 	if err != nil {
 		if err == redis.Nil {
 			return "", errors.New("nil")
@@ -50,10 +50,41 @@ func (cache *RedisCache) Get(key string) (string, error) {
 	return val, nil
 }
 
-func (cache *RedisCache) Delete(id string) error {
-	err := cache.redisClient.Del(context.Background(), id).Err()
+func (cache *RedisCache) Delete(ctx context.Context, id string) error {
+	err := cache.redisClient.Del(ctx, id).Err()
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+func (cache *RedisCache) GetMany(ctx context.Context, keys []string) (map[string]string, error) {
+	values := map[string]string{}
+	if len(keys) == 0 {
+		return values, nil
+	}
+	results, err := cache.redisClient.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, result := range results {
+		// MGET returns nil for the missing keys
+		if value, ok := result.(string); ok {
+			values[keys[i]] = value
+		}
+	}
+	return values, nil
+}
+
+func (cache *RedisCache) SetMany(ctx context.Context, values map[string]string, expiry time.Duration) error {
+	if len(values) == 0 {
+		return nil
+	}
+	_, err := cache.redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for key, value := range values {
+			pipe.Set(ctx, key, value, expiry)
+		}
+		return nil
+	})
+	return err
+}
@@ -0,0 +1,282 @@
+// Package images validates the uploaded images and stores them as renditions:
+// every size in WebP and JPEG, re-encoded so no EXIF or GPS metadata is kept.
+//
+// An image processed here is saved in the database as its base key, like
+// "products/merchant/product/id", and its renditions are stored under it, like
+// "products/merchant/product/id/thumbnail.webp". Keys with an extension are
+// the raw uploads from before the pipeline, they are served as they are.
+package images
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/HugoSmits86/nativewebp"
+	objectstore "github.com/akmal4410/gestapo/pkg/service/object_store"
+	urlsigner "github.com/akmal4410/gestapo/pkg/service/url_signer"
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+	"google.golang.org/grpc/metadata"
+)
+
+// Size is the rendition returned to a surface of the API.
+type Size string
+
+const (
+	Thumbnail Size = "thumbnail"
+	Medium    Size = "medium"
+	Large     Size = "large"
+)
+
+// Sizes are the renditions generated for each image, from the largest.
+var Sizes = []Size{Large, Medium, Thumbnail}
+
+// maxEdge is the longest side of each rendition, smaller images are not upscaled.
+var maxEdge = map[Size]int{
+	Thumbnail: 200,
+	Medium:    600,
+	Large:     1200,
+}
+
+// Format is the encoding of a rendition.
+type Format string
+
+const (
+	WebP Format = "webp"
+	JPEG Format = "jpeg"
+)
+
+// Formats are the encodings generated for each size.
+var Formats = []Format{WebP, JPEG}
+
+var extensions = map[Format]string{
+	WebP: ".webp",
+	JPEG: ".jpg",
+}
+
+var contentTypes = map[Format]string{
+	WebP: "image/webp",
+	JPEG: "image/jpeg",
+}
+
+const (
+	// FormatHeader lets a client ask for the JPEG renditions, WebP is returned by default.
+	FormatHeader = "X-Image-Format"
+	// MaxSize is the largest file accepted, in bytes.
+	MaxSize = 32 << 20
+	// MaxPixels rejects the images that would take too much memory to decode.
+	MaxPixels   = 40_000_000
+	jpegQuality = 85
+)
+
+// UploadExtensions are the image types the clients can upload, with the extension of their keys.
+var UploadExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// ErrUnsupportedImage is returned for files that are not a valid JPEG, PNG or WebP image.
+var ErrUnsupportedImage = errors.New("unsupported image")
+
+// Key returns the key of a rendition of image.
+func Key(image string, size Size, format Format) string {
+	if !IsProcessed(image) {
+		return image
+	}
+	return image + "/" + string(size) + extensions[format]
+}
+
+// Keys returns every key stored for image.
+func Keys(image string) []string {
+	if !IsProcessed(image) {
+		return []string{image}
+	}
+	keys := make([]string, 0, len(Sizes)*len(Formats))
+	for _, size := range Sizes {
+		for _, format := range Formats {
+			keys = append(keys, Key(image, size, format))
+		}
+	}
+	return keys
+}
+
+// IsProcessed reports whether image is a base key with renditions, or a raw upload.
+func IsProcessed(image string) bool {
+	return path.Ext(image) == ""
+}
+
+// Pick returns the URLs of images in urls in the same order, the images without one are left out.
+func Pick(images []string, urls map[string]string) []string {
+	picked := make([]string, 0, len(images))
+	for _, image := range images {
+		if signedURL, ok := urls[image]; ok {
+			picked = append(picked, signedURL)
+		}
+	}
+	return picked
+}
+
+// BaseKey returns the base key of the renditions of an upload, its key without the extension.
+func BaseKey(key string) string {
+	return strings.TrimSuffix(key, path.Ext(key))
+}
+
+// FormatFromContext returns the format asked by the client in the FormatHeader.
+func FormatFromContext(ctx context.Context) Format {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return WebP
+	}
+	values := md.Get(FormatHeader)
+	if len(values) != 0 && strings.EqualFold(values[0], string(JPEG)) {
+		return JPEG
+	}
+	return WebP
+}
+
+// URL returns the URL of the size rendition of image in the format asked by the client,
+// or "" when it cannot be signed.
+func URL(ctx context.Context, signer *urlsigner.Signer, image string, size Size) string {
+	return URLs(ctx, signer, []string{image}, size)[image]
+}
+
+// URLs returns the URLs of the size rendition of each image in the format asked by
+// the client, signed in one batch. The images that cannot be signed are left out.
+func URLs(ctx context.Context, signer *urlsigner.Signer, images []string, size Size) map[string]string {
+	format := FormatFromContext(ctx)
+	keys := make([]string, 0, len(images))
+	for _, image := range images {
+		if image != "" {
+			keys = append(keys, Key(image, size, format))
+		}
+	}
+	signed := signer.URLs(ctx, keys)
+	urls := make(map[string]string, len(signed))
+	for _, image := range images {
+		if signedURL, ok := signed[Key(image, size, format)]; ok && image != "" {
+			urls[image] = signedURL
+		}
+	}
+	return urls
+}
+
+// Save decodes the image read from r and stores its renditions under base.
+func Save(ctx context.Context, store objectstore.ObjectStore, r io.Reader, base string) error {
+	data, err := io.ReadAll(io.LimitReader(r, MaxSize+1))
+	if err != nil {
+		return err
+	}
+	if len(data) > MaxSize {
+		return fmt.Errorf("%w: bigger than %d bytes", ErrUnsupportedImage, MaxSize)
+	}
+	img, err := decode(data)
+	if err != nil {
+		return err
+	}
+	orientation := jpegOrientation(data)
+
+	source := img
+	for _, size := range Sizes {
+		rendition := resize(source, maxEdge[size])
+		oriented := orient(rendition, orientation)
+		for _, format := range Formats {
+			var buf bytes.Buffer
+			if err := encode(&buf, oriented, format); err != nil {
+				return err
+			}
+			if err := store.Put(ctx, Key(base, size, format), &buf, contentTypes[format]); err != nil {
+				return err
+			}
+		}
+		// the smaller sizes are scaled from this one, which is faster than from the original
+		source = rendition
+	}
+	return nil
+}
+
+// Process replaces the raw upload stored at key by its renditions and returns their base key.
+func Process(ctx context.Context, store objectstore.ObjectStore, key string) (string, error) {
+	body, err := store.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	base := BaseKey(key)
+	if err := Save(ctx, store, body, base); err != nil {
+		return "", err
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		return "", err
+	}
+	return base, nil
+}
+
+// Delete deletes every key stored for image.
+func Delete(ctx context.Context, store objectstore.ObjectStore, image string) error {
+	for _, key := range Keys(image) {
+		if err := store.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decode(data []byte) (image.Image, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedImage, err)
+	}
+	if format != "jpeg" && format != "png" && format != "webp" {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedImage, format)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > MaxPixels {
+		return nil, fmt.Errorf("%w: %dx%d pixels", ErrUnsupportedImage, config.Width, config.Height)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedImage, err)
+	}
+	return img, nil
+}
+
+// resize scales img so its longest side is at most edge.
+func resize(img image.Image, edge int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= edge && height <= edge {
+		return img
+	}
+	if width >= height {
+		height = max(1, height*edge/width)
+		width = edge
+	} else {
+		width = max(1, width*edge/height)
+		height = edge
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+func encode(w io.Writer, img image.Image, format Format) error {
+	if format == WebP {
+		return nativewebp.Encode(w, img, nil)
+	}
+	// JPEG has no transparency, the transparent pixels become white
+	flat := image.NewRGBA(img.Bounds())
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, draw.Over)
+	return jpeg.Encode(w, flat, &jpeg.Options{Quality: jpegQuality})
+}
@@ -0,0 +1,213 @@
+package images_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akmal4410/gestapo/internal/config"
+	"github.com/akmal4410/gestapo/pkg/helpers/logger"
+	"github.com/akmal4410/gestapo/pkg/service/images"
+	objectstore "github.com/akmal4410/gestapo/pkg/service/object_store"
+	urlsigner "github.com/akmal4410/gestapo/pkg/service/url_signer"
+	_ "golang.org/x/image/webp"
+	"google.golang.org/grpc/metadata"
+)
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		img.Set(x, 0, color.NRGBA{R: 255, A: 255})
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// encodeJPEG returns a JPEG with an EXIF holding the orientation and a GPS IFD.
+func encodeJPEG(t *testing.T, width, height int, orientation uint16) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height)), nil); err != nil {
+		t.Fatalf("jpeg.Encode: %v", err)
+	}
+
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 2)
+	// orientation, SHORT
+	tiff = append(tiff, 0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0x00, 0x00)
+	// GPS IFD pointer, LONG
+	tiff = append(tiff, 0x88, 0x25, 0x00, 0x04, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00)
+	tiff = append(tiff, 0x00, 0x00, 0x00, 0x00)
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+
+	data := []byte{0xFF, 0xD8, 0xFF, 0xE1}
+	data = binary.BigEndian.AppendUint16(data, uint16(len(segment)+2))
+	data = append(data, segment...)
+	return append(data, buf.Bytes()[2:]...)
+}
+
+func decodeRendition(t *testing.T, store *objectstore.MemoryStore, key string) (image.Image, []byte) {
+	t.Helper()
+	body, err := store.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Get(%s): %v", key, err)
+	}
+	defer body.Close()
+	data, _ := io.ReadAll(body)
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decode %s: %v", key, err)
+	}
+	return img, data
+}
+
+func TestSave(t *testing.T) {
+	store := objectstore.NewMemoryStore()
+	if err := images.Save(context.Background(), store, bytes.NewReader(encodePNG(t, 1600, 800)), "products/m/p/id"); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	want := map[images.Size]image.Point{
+		images.Large:     {1200, 600},
+		images.Medium:    {600, 300},
+		images.Thumbnail: {200, 100},
+	}
+	for _, key := range images.Keys("products/m/p/id") {
+		img, _ := decodeRendition(t, store, key)
+		for size, point := range want {
+			if strings.Contains(key, "/"+string(size)+".") && img.Bounds().Size() != point {
+				t.Fatalf("%s is %v, want %v", key, img.Bounds().Size(), point)
+			}
+		}
+	}
+	if got := images.Key("products/m/p/id", images.Thumbnail, images.WebP); got != "products/m/p/id/thumbnail.webp" {
+		t.Fatalf("Key = %s", got)
+	}
+}
+
+func TestSaveDoesNotUpscale(t *testing.T) {
+	store := objectstore.NewMemoryStore()
+	if err := images.Save(context.Background(), store, bytes.NewReader(encodePNG(t, 100, 50)), "profile/u/id"); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	img, _ := decodeRendition(t, store, images.Key("profile/u/id", images.Large, images.JPEG))
+	if img.Bounds().Size() != image.Pt(100, 50) {
+		t.Fatalf("large rendition is %v", img.Bounds().Size())
+	}
+}
+
+func TestSaveAppliesOrientationAndStripsMetadata(t *testing.T) {
+	store := objectstore.NewMemoryStore()
+	data := encodeJPEG(t, 400, 200, 6)
+	if err := images.Save(context.Background(), store, bytes.NewReader(data), "profile/u/id"); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	for _, key := range images.Keys("profile/u/id") {
+		img, rendition := decodeRendition(t, store, key)
+		if size := img.Bounds().Size(); size.X >= size.Y {
+			t.Fatalf("%s was not rotated: %v", key, size)
+		}
+		if bytes.Contains(rendition, []byte("Exif")) {
+			t.Fatalf("%s kept the EXIF", key)
+		}
+	}
+}
+
+func TestSaveRejectsInvalidImages(t *testing.T) {
+	gif := "GIF89a\x01\x00\x01\x00\x00\x00\x00;"
+	truncated := string(encodePNG(t, 10, 10)[:40])
+	for _, data := range []string{"<html></html>", gif, truncated} {
+		err := images.Save(context.Background(), objectstore.NewMemoryStore(), strings.NewReader(data), "profile/u/id")
+		if !errors.Is(err, images.ErrUnsupportedImage) {
+			t.Fatalf("Save(%q) = %v", data, err)
+		}
+	}
+}
+
+func TestProcess(t *testing.T) {
+	ctx := context.Background()
+	store := objectstore.NewMemoryStore()
+	if err := store.Put(ctx, "profile/u/id.png", bytes.NewReader(encodePNG(t, 10, 10)), "image/png"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	image, err := images.Process(ctx, store, "profile/u/id.png")
+	if err != nil || image != "profile/u/id" {
+		t.Fatalf("Process: %s %v", image, err)
+	}
+	if _, err := store.Stat(ctx, "profile/u/id.png"); !errors.Is(err, objectstore.ErrNotFound) {
+		t.Fatalf("the raw upload was kept: %v", err)
+	}
+
+	if err := images.Delete(ctx, store, image); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	for _, key := range images.Keys(image) {
+		if _, err := store.Stat(ctx, key); !errors.Is(err, objectstore.ErrNotFound) {
+			t.Fatalf("%s was kept: %v", key, err)
+		}
+	}
+}
+
+func TestRawUploadsAreServedAsTheyAre(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(images.FormatHeader, "jpeg"))
+	if images.FormatFromContext(ctx) != images.JPEG || images.FormatFromContext(context.Background()) != images.WebP {
+		t.Fatal("unexpected format")
+	}
+	signer := urlsigner.NewWith(&config.Config{}, objectstore.NewMemoryStore(), nil, logger.NewWriterLogger("images", io.Discard))
+	urls := images.URLs(ctx, signer, []string{"products/runner.jpg", "products/m/p/id", ""}, images.Thumbnail)
+	if len(urls) != 2 || urls["products/runner.jpg"] != "memory://files/products/runner.jpg" || urls["products/m/p/id"] != "memory://files/products/m/p/id/thumbnail.jpg" {
+		t.Fatalf("URLs: %v", urls)
+	}
+}
+
+func TestIsPublicIP(t *testing.T) {
+	for address, public := range map[string]bool{
+		"93.184.216.34":    true,
+		"2606:4700::1111":  true,
+		"127.0.0.1":        false,
+		"10.1.2.3":         false,
+		"172.16.0.1":       false,
+		"192.168.1.1":      false,
+		"169.254.169.254":  false,
+		"100.64.0.1":       false,
+		"0.0.0.0":          false,
+		"::1":              false,
+		"fd00::1":          false,
+		"fe80::1":          false,
+		"::ffff:127.0.0.1": false,
+	} {
+		if images.IsPublicIP(net.ParseIP(address)) != public {
+			t.Errorf("IsPublicIP(%s) should be %v", address, public)
+		}
+	}
+}
+
+func TestDownloadClientRefusesPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(encodePNG(t, 10, 10))
+	}))
+	defer server.Close()
+
+	_, err := images.NewDownloadClient(time.Second).Get(server.URL)
+	if !errors.Is(err, images.ErrPrivateAddress) {
+		t.Fatalf("expected the loopback server to be refused, got %v", err)
+	}
+}
@@ -1,8 +1,12 @@
 package mail
 
-import "github.com/akmal4410/gestapo/pkg/service/cache"
+import (
+	"context"
+
+	"github.com/akmal4410/gestapo/pkg/service/cache"
+)
 
 type EmailService interface {
-	SendOTP(to, subject, content string, redis cache.Cache) error
-	VerfiyOTP(to, code string, redis cache.Cache) (bool, error)
+	SendOTP(ctx context.Context, to, subject, content string, redis cache.Cache) error
+	VerfiyOTP(ctx context.Context, to, code string, redis cache.Cache) (bool, error)
 }
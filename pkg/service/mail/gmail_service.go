@@ -2,6 +2,7 @@ package mail
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"html/template"
 	"math/rand"
@@ -47,7 +48,7 @@ func parseTemplate(email, templateType, content string) (int, *bytes.Buffer, err
 	return otp, &body, nil
 }
 
-func (sender *GmailService) SendOTP(to, subject, content string, redisCache cache.Cache) error {
+func (sender *GmailService) SendOTP(ctx context.Context, to, subject, content string, redisCache cache.Cache) error {
 	email := email.NewEmail()
 
 	otp, htmlContent, err := parseTemplate(to, "email", content)
@@ -69,21 +70,26 @@ func (sender *GmailService) SendOTP(to, subject, content string, redisCache cach
 	// 	}
 	// }
 
-	if err := redisCache.Delete(to); err != nil {
+	if err := redisCache.Delete(ctx, to); err != nil {
 		if err != redis.Nil {
 			return err
 		}
 	}
 
-	if err := redisCache.Set(to, strconv.Itoa(otp)); err != nil {
+	if err := redisCache.Set(ctx, to, strconv.Itoa(otp)); err != nil {
 		return err
 	}
 	smtpAuth := smtp.PlainAuth("", sender.senderEmailAdrress, sender.senderEmailPassword, smtpAuthAddress)
 	return email.Send(smtpServerAddress, smtpAuth)
 }
 
-func (sender *GmailService) VerfiyOTP(user, otp string, redis cache.Cache) (bool, error) {
-	cachedOtp, err := redis.Get(user)
+func (sender *GmailService) VerfiyOTP(ctx context.Context, user, otp string, redis cache.Cache) (bool, error) {
+	return verifyCachedOTP(ctx, user, otp, redis)
+}
+
+// verifyCachedOTP compares otp with the one cached for the user when it was sent.
+func verifyCachedOTP(ctx context.Context, user, otp string, redis cache.Cache) (bool, error) {
+	cachedOtp, err := redis.Get(ctx, user)
 	if err != nil {
 		if err.Error() == "nil" {
 			return false, nil
@@ -92,7 +98,7 @@ func (sender *GmailService) VerfiyOTP(user, otp string, redis cache.Cache) (bool
 	}
 	// if the otp is valid, then delete it from the redis
 	if cachedOtp == otp {
-		if err := redis.Delete(user); err != nil {
+		if err := redis.Delete(ctx, user); err != nil {
 			return false, err
 		}
 	}
@@ -0,0 +1,66 @@
+package mail
+
+import (
+	"context"
+	"math/rand"
+	"strconv"
+	"sync"
+
+	"github.com/akmal4410/gestapo/pkg/service/cache"
+)
+
+// Message is an email kept by MemoryEmailService instead of being sent.
+type Message struct {
+	To      string
+	Subject string
+	Content string
+	OTP     string
+}
+
+// MemoryEmailService is an EmailService that keeps the emails in memory,
+// used when no SMTP server is available.
+type MemoryEmailService struct {
+	mu   sync.Mutex
+	sent []Message
+}
+
+func NewMemoryEmailService() *MemoryEmailService {
+	return &MemoryEmailService{}
+}
+
+func (sender *MemoryEmailService) SendOTP(ctx context.Context, to, subject, content string, redisCache cache.Cache) error {
+	otp := strconv.Itoa(rand.Intn(900000) + 100000)
+	if err := redisCache.Delete(ctx, to); err != nil {
+		return err
+	}
+	if err := redisCache.Set(ctx, to, otp); err != nil {
+		return err
+	}
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+	sender.sent = append(sender.sent, Message{To: to, Subject: subject, Content: content, OTP: otp})
+	return nil
+}
+
+func (sender *MemoryEmailService) VerfiyOTP(ctx context.Context, user, otp string, redis cache.Cache) (bool, error) {
+	return verifyCachedOTP(ctx, user, otp, redis)
+}
+
+// LastOTP returns the OTP of the last email sent to the address.
+func (sender *MemoryEmailService) LastOTP(to string) (string, bool) {
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+	for i := len(sender.sent) - 1; i >= 0; i-- {
+		if sender.sent[i].To == to {
+			return sender.sent[i].OTP, true
+		}
+	}
+	return "", false
+}
+
+// Sent returns every email sent so far.
+func (sender *MemoryEmailService) Sent() []Message {
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+	return append([]Message(nil), sender.sent...)
+}
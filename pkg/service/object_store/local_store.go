@@ -0,0 +1,233 @@
+package objectstore
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FilesPath is where the gateway serves the files of a LocalStore.
+const FilesPath = "/files/"
+
+// LocalStore is an ObjectStore keeping the files on disk, used to run without AWS.
+// It signs its URLs itself and serves them as an http.Handler mounted at FilesPath.
+type LocalStore struct {
+	directory  string
+	publicURL  string
+	signingKey []byte
+	urlExpiry  time.Duration
+	now        func() time.Time
+}
+
+// NewLocalStore keeps the files under directory. The URLs start with publicURL,
+// the address of the gateway, and are signed with signingKey.
+func NewLocalStore(directory, publicURL, signingKey string, urlExpiry time.Duration) (*LocalStore, error) {
+	if directory == "" {
+		return nil, errors.New("STORAGE.DIRECTORY is required for local storage")
+	}
+	if signingKey == "" {
+		return nil, errors.New("STORAGE.SIGNING_KEY is required for local storage")
+	}
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return nil, fmt.Errorf("unable to create storage directory: %s", err)
+	}
+	return &LocalStore{
+		directory:  directory,
+		publicURL:  strings.TrimSuffix(publicURL, "/"),
+		signingKey: []byte(signingKey),
+		urlExpiry:  urlExpiry,
+		now:        time.Now,
+	}, nil
+}
+
+func (store *LocalStore) filePath(key string) (string, error) {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(store.directory, filepath.FromSlash(cleaned)), nil
+}
+
+func (store *LocalStore) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	filePath, err := store.filePath(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return fmt.Errorf("unable to create folder: %s", err)
+	}
+	// written next to the file and renamed, so readers never see a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("unable to create file: %s", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return fmt.Errorf("unable to write file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("unable to write file: %s", err)
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return fmt.Errorf("unable to write file: %s", err)
+	}
+	return nil
+}
+
+func (store *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	filePath, err := store.filePath(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+func (store *LocalStore) Delete(ctx context.Context, key string) error {
+	filePath, err := store.filePath(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(filePath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("unable to delete file: %s", err)
+	}
+	return nil
+}
+
+func (store *LocalStore) PresignGet(ctx context.Context, key string) (string, error) {
+	return store.presign(key, http.MethodGet, url.Values{})
+}
+
+func (store *LocalStore) PresignPut(ctx context.Context, key, contentType string, size int64) (string, error) {
+	return store.presign(key, http.MethodPut, url.Values{
+		"content_type": {contentType},
+		"size":         {strconv.FormatInt(size, 10)},
+	})
+}
+
+func (store *LocalStore) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	filePath, err := store.filePath(key)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ObjectInfo{Size: info.Size()}, nil
+}
+
+// presign signs the method, the key, the expiry and the constraints in query.
+func (store *LocalStore) presign(key, method string, query url.Values) (string, error) {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	query.Set("expires", strconv.FormatInt(store.now().Add(store.urlExpiry).Unix(), 10))
+	query.Set("signature", store.sign(method, cleaned, query))
+	fileURL := url.URL{Path: FilesPath + cleaned}
+	return store.publicURL + fileURL.EscapedPath() + "?" + query.Encode(), nil
+}
+
+func (store *LocalStore) sign(method, key string, query url.Values) string {
+	mac := hmac.New(sha256.New, store.signingKey)
+	mac.Write([]byte(strings.Join([]string{
+		method,
+		key,
+		query.Get("expires"),
+		query.Get("content_type"),
+		query.Get("size"),
+	}, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ServeHTTP serves the URLs of PresignGet and receives the uploads to the URLs of PresignPut.
+func (store *LocalStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	method := r.Method
+	if method == http.MethodHead {
+		method = http.MethodGet
+	}
+	if method != http.MethodGet && method != http.MethodPut {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	key, err := cleanKey(strings.TrimPrefix(r.URL.Path, FilesPath))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	query := r.URL.Query()
+	expiresAt, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	signature := store.sign(method, key, query)
+	if err != nil || !hmac.Equal([]byte(signature), []byte(query.Get("signature"))) {
+		http.Error(w, "invalid signature", http.StatusForbidden)
+		return
+	}
+	if store.now().Unix() > expiresAt {
+		http.Error(w, "url expired", http.StatusForbidden)
+		return
+	}
+
+	if method == http.MethodPut {
+		store.receive(w, r, key, query)
+		return
+	}
+	filePath, _ := store.filePath(key)
+	file, err := os.Open(filePath)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	http.ServeContent(w, r, info.Name(), info.ModTime(), file)
+}
+
+// receive stores the body of an upload, refusing the ones breaking the signed constraints.
+func (store *LocalStore) receive(w http.ResponseWriter, r *http.Request, key string, query url.Values) {
+	if r.Header.Get("Content-Type") != query.Get("content_type") {
+		http.Error(w, "content type does not match the signed one", http.StatusBadRequest)
+		return
+	}
+	size, err := strconv.ParseInt(query.Get("size"), 10, 64)
+	if err != nil || r.ContentLength > size {
+		http.Error(w, "file is bigger than the signed size", http.StatusRequestEntityTooLarge)
+		return
+	}
+	body := http.MaxBytesReader(w, r.Body, size)
+	if err := store.Put(r.Context(), key, body, query.Get("content_type")); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, "file is bigger than the signed size", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "unable to store file", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
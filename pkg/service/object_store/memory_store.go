@@ -0,0 +1,70 @@
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// MemoryStore is an ObjectStore keeping the files in memory, used when no storage is reachable.
+// Its URLs use the memory:// scheme and are only meaningful to GetObject, uploads go through Put.
+type MemoryStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{objects: map[string][]byte{}}
+}
+
+func (store *MemoryStore) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return fmt.Errorf("unable to read file: %s", err)
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.objects[key] = data
+	return nil
+}
+
+func (store *MemoryStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	data, ok := store.GetObject(key)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (store *MemoryStore) Delete(ctx context.Context, key string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	delete(store.objects, key)
+	return nil
+}
+
+func (store *MemoryStore) PresignGet(ctx context.Context, key string) (string, error) {
+	return "memory://files/" + key, nil
+}
+
+func (store *MemoryStore) PresignPut(ctx context.Context, key, contentType string, size int64) (string, error) {
+	return "memory://uploads/" + key, nil
+}
+
+func (store *MemoryStore) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	data, ok := store.GetObject(key)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &ObjectInfo{Size: int64(len(data))}, nil
+}
+
+// GetObject returns the content stored under key.
+func (store *MemoryStore) GetObject(key string) ([]byte, bool) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	data, ok := store.objects[key]
+	return data, ok
+}
@@ -0,0 +1,139 @@
+// Package objectstore keeps the uploaded files. Keys are stored in the database
+// and turned into short-lived URLs when they are sent to the clients.
+package objectstore
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/akmal4410/gestapo/internal/config"
+)
+
+// Backends selected by STORAGE.BACKEND.
+const (
+	BackendS3     = "s3"
+	BackendMinIO  = "minio"
+	BackendLocal  = "local"
+	BackendMemory = "memory"
+)
+
+// DefaultURLExpiry is how long the signed URLs stay valid when URL_EXPIRY is not set.
+const DefaultURLExpiry = 15 * time.Minute
+
+// ErrNotFound is returned when nothing is stored under the key.
+var ErrNotFound = errors.New("object not found")
+
+// ErrInvalidObject is returned by Verify when the object is not the declared one.
+var ErrInvalidObject = errors.New("invalid object")
+
+// ObjectInfo describes a stored object.
+type ObjectInfo struct {
+	Size int64
+}
+
+// ObjectStore stores files by key.
+type ObjectStore interface {
+	// Put streams body to key, replacing the object stored there
+	Put(ctx context.Context, key string, body io.Reader, contentType string) error
+
+	// Get returns the content of key, the caller closes it
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete deletes key, deleting a missing key is not an error
+	Delete(ctx context.Context, key string) error
+
+	// PresignGet returns a short-lived URL the client can download key from
+	PresignGet(ctx context.Context, key string) (string, error)
+
+	// PresignPut returns a short-lived URL the client can PUT the content of key to,
+	// sending contentType as its Content-Type. Backends enforcing the size refuse
+	// bigger bodies, the others rely on Verify once the upload is done.
+	PresignPut(ctx context.Context, key, contentType string, size int64) (string, error)
+
+	// Stat describes the object stored under key
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+}
+
+// New returns the backend selected in the STORAGE config, S3 when none is set.
+func New(appConfig *config.Config) (ObjectStore, error) {
+	storageConfig := config.Storage{}
+	if appConfig.Storage != nil {
+		storageConfig = *appConfig.Storage
+	}
+	if storageConfig.URLExpiry == 0 {
+		storageConfig.URLExpiry = DefaultURLExpiry
+	}
+
+	switch storageConfig.Backend {
+	case "", BackendS3, BackendMinIO:
+		if appConfig.AwsS3 == nil {
+			return nil, errors.New("AWSS3 is not configured")
+		}
+		if storageConfig.Backend == BackendMinIO && storageConfig.Endpoint == "" {
+			return nil, errors.New("STORAGE.ENDPOINT is required for minio")
+		}
+		return NewS3Store(appConfig.AwsS3, storageConfig.Endpoint, storageConfig.URLExpiry)
+	case BackendLocal:
+		return NewLocalStore(storageConfig.Directory, storageConfig.PublicURL, storageConfig.SigningKey, storageConfig.URLExpiry)
+	case BackendMemory:
+		return NewMemoryStore(), nil
+	}
+	return nil, fmt.Errorf("unknown storage backend: %s", storageConfig.Backend)
+}
+
+// Upload streams file to folderPath/filename and returns its key.
+// The content type is detected from the first bytes of the file.
+func Upload(ctx context.Context, store ObjectStore, file io.Reader, folderPath, filename string) (string, error) {
+	key := path.Join(folderPath, path.Base(filename))
+	reader := bufio.NewReaderSize(file, 512)
+	head, err := reader.Peek(512)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return "", fmt.Errorf("unable to read file: %s", err)
+	}
+	if err := store.Put(ctx, key, reader, http.DetectContentType(head)); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// Verify checks that the object uploaded to key has the declared size and that
+// its content really is of contentType, whatever the client labelled it.
+func Verify(ctx context.Context, store ObjectStore, key, contentType string, size int64) error {
+	info, err := store.Stat(ctx, key)
+	if err != nil {
+		return err
+	}
+	if info.Size != size {
+		return fmt.Errorf("%w: size is %d instead of %d", ErrInvalidObject, info.Size, size)
+	}
+	body, err := store.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	head := make([]byte, 512)
+	n, err := io.ReadFull(body, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return fmt.Errorf("unable to read object: %s", err)
+	}
+	if detected := http.DetectContentType(head[:n]); detected != contentType {
+		return fmt.Errorf("%w: content is %s instead of %s", ErrInvalidObject, detected, contentType)
+	}
+	return nil
+}
+
+// cleanKey rejects the keys escaping their folder, like "../config.yaml".
+func cleanKey(key string) (string, error) {
+	cleaned := path.Clean("/" + key)[1:]
+	if cleaned == "" || cleaned != strings.TrimPrefix(key, "/") {
+		return "", fmt.Errorf("invalid key: %q", key)
+	}
+	return cleaned, nil
+}
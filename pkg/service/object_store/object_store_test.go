@@ -0,0 +1,179 @@
+package objectstore_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akmal4410/gestapo/internal/config"
+	objectstore "github.com/akmal4410/gestapo/pkg/service/object_store"
+)
+
+func newLocalStore(t *testing.T, urlExpiry time.Duration) *objectstore.LocalStore {
+	t.Helper()
+	store, err := objectstore.NewLocalStore(t.TempDir(), "http://localhost:8080/", "secret", urlExpiry)
+	if err != nil {
+		t.Fatalf("NewLocalStore: %v", err)
+	}
+	return store
+}
+
+func get(store http.Handler, rawURL string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	store.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, rawURL, nil))
+	return rec
+}
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	store := newLocalStore(t, time.Minute)
+
+	key, err := objectstore.Upload(ctx, store, strings.NewReader("image"), "products/merchant/", "shoe.png")
+	if err != nil || key != "products/merchant/shoe.png" {
+		t.Fatalf("Upload: %q %v", key, err)
+	}
+
+	body, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if string(data) != "image" {
+		t.Fatalf("Get returned %q", data)
+	}
+
+	signedURL, err := store.PresignGet(ctx, key)
+	if err != nil || !strings.HasPrefix(signedURL, "http://localhost:8080/files/products/merchant/shoe.png?") {
+		t.Fatalf("PresignGet: %q %v", signedURL, err)
+	}
+	if rec := get(store, signedURL); rec.Code != http.StatusOK || rec.Body.String() != "image" {
+		t.Fatalf("signed URL: %d %s", rec.Code, rec.Body)
+	}
+
+	tampered, _ := url.Parse(signedURL)
+	tampered.Path = "/files/products/merchant/other.png"
+	if rec := get(store, tampered.String()); rec.Code != http.StatusForbidden {
+		t.Fatalf("tampered URL: %d", rec.Code)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Get(ctx, key); !errors.Is(err, objectstore.ErrNotFound) {
+		t.Fatalf("Get after Delete: %v", err)
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete of a missing key: %v", err)
+	}
+}
+
+func TestLocalStoreExpiredURL(t *testing.T) {
+	ctx := context.Background()
+	store := newLocalStore(t, -time.Minute)
+	if err := store.Put(ctx, "profile/user/me.png", strings.NewReader("image"), "image/png"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	signedURL, err := store.PresignGet(ctx, "profile/user/me.png")
+	if err != nil {
+		t.Fatalf("PresignGet: %v", err)
+	}
+	if rec := get(store, signedURL); rec.Code != http.StatusForbidden {
+		t.Fatalf("expired URL: %d", rec.Code)
+	}
+}
+
+func TestLocalStoreRejectsEscapingKeys(t *testing.T) {
+	store := newLocalStore(t, time.Minute)
+	for _, key := range []string{"../config.yaml", "products/../../config.yaml", ""} {
+		if err := store.Put(context.Background(), key, strings.NewReader("x"), "text/plain"); err == nil {
+			t.Fatalf("Put(%q) should fail", key)
+		}
+	}
+}
+
+func TestNewSelectsBackend(t *testing.T) {
+	tests := []struct {
+		storage *config.Storage
+		awsS3   *config.AWSS3
+		want    string
+	}{
+		{storage: &config.Storage{Backend: objectstore.BackendMemory}, want: "*objectstore.MemoryStore"},
+		{storage: &config.Storage{Backend: objectstore.BackendLocal, Directory: t.TempDir(), SigningKey: "secret"}, want: "*objectstore.LocalStore"},
+		{awsS3: &config.AWSS3{BucketName: "bucket", Region: "ap-south-1"}, want: "*objectstore.S3Store"},
+		{storage: &config.Storage{Backend: objectstore.BackendMinIO, Endpoint: "http://localhost:9000"}, awsS3: &config.AWSS3{BucketName: "bucket"}, want: "*objectstore.S3Store"},
+	}
+	for _, test := range tests {
+		store, err := objectstore.New(&config.Config{Storage: test.storage, AwsS3: test.awsS3})
+		if err != nil {
+			t.Fatalf("New(%+v): %v", test.storage, err)
+		}
+		if got := fmt.Sprintf("%T", store); got != test.want {
+			t.Fatalf("New(%+v) = %s, want %s", test.storage, got, test.want)
+		}
+	}
+
+	invalid := []*config.Config{
+		{},
+		{Storage: &config.Storage{Backend: objectstore.BackendMinIO}, AwsS3: &config.AWSS3{}},
+		{Storage: &config.Storage{Backend: objectstore.BackendLocal}},
+		{Storage: &config.Storage{Backend: "ftp"}},
+	}
+	for _, appConfig := range invalid {
+		if _, err := objectstore.New(appConfig); err == nil {
+			t.Fatalf("New(%+v) should fail", appConfig.Storage)
+		}
+	}
+}
+
+func put(store http.Handler, rawURL, contentType, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPut, rawURL, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	rec := httptest.NewRecorder()
+	store.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestLocalStorePresignPut(t *testing.T) {
+	ctx := context.Background()
+	store := newLocalStore(t, time.Minute)
+	png := "\x89PNG\r\n\x1a\nimage"
+
+	signedURL, err := store.PresignPut(ctx, "profile/user/me.png", "image/png", int64(len(png)))
+	if err != nil {
+		t.Fatalf("PresignPut: %v", err)
+	}
+	if rec := put(store, signedURL, "image/jpeg", png); rec.Code != http.StatusBadRequest {
+		t.Fatalf("other content type: %d", rec.Code)
+	}
+	if rec := put(store, signedURL, "image/png", png+"more"); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("bigger file: %d", rec.Code)
+	}
+	if rec := get(store, signedURL); rec.Code != http.StatusForbidden {
+		t.Fatalf("upload URL used to download: %d", rec.Code)
+	}
+	if rec := put(store, signedURL, "image/png", png); rec.Code != http.StatusOK {
+		t.Fatalf("upload: %d %s", rec.Code, rec.Body)
+	}
+
+	info, err := store.Stat(ctx, "profile/user/me.png")
+	if err != nil || info.Size != int64(len(png)) {
+		t.Fatalf("Stat: %+v %v", info, err)
+	}
+	if err := objectstore.Verify(ctx, store, "profile/user/me.png", "image/png", int64(len(png))); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if err := objectstore.Verify(ctx, store, "profile/user/me.png", "image/jpeg", int64(len(png))); !errors.Is(err, objectstore.ErrInvalidObject) {
+		t.Fatalf("Verify of another content type: %v", err)
+	}
+	if _, err := store.Stat(ctx, "profile/user/missing.png"); !errors.Is(err, objectstore.ErrNotFound) {
+		t.Fatalf("Stat of a missing key: %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/akmal4410/gestapo/internal/config"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// S3Store is an ObjectStore on Amazon S3 or on an S3 compatible server like MinIO.
+type S3Store struct {
+	bucketName string
+	urlExpiry  time.Duration
+	client     *s3.S3
+	uploader   *s3manager.Uploader
+}
+
+// NewS3Store connects to the bucket of awsConfig. A non empty endpoint is used
+// instead of AWS, with path-style URLs as MinIO expects.
+func NewS3Store(awsConfig *config.AWSS3, endpoint string, urlExpiry time.Duration) (*S3Store, error) {
+	sessionConfig := &aws.Config{
+		Region:      aws.String(awsConfig.Region),
+		Credentials: credentials.NewStaticCredentials(awsConfig.AccessKey, awsConfig.SecretKey, ""),
+	}
+	if endpoint != "" {
+		sessionConfig.Endpoint = aws.String(endpoint)
+		sessionConfig.S3ForcePathStyle = aws.Bool(true)
+	}
+	sess, err := session.NewSession(sessionConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error creating AWS session: %s", err)
+	}
+	return &S3Store{
+		bucketName: awsConfig.BucketName,
+		urlExpiry:  urlExpiry,
+		client:     s3.New(sess),
+		uploader:   s3manager.NewUploader(sess),
+	}, nil
+}
+
+func (store *S3Store) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	// the uploader sends body in parts, so the file is never fully in memory
+	_, err := store.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:      aws.String(store.bucketName),
+		Key:         aws.String(key),
+		ACL:         aws.String("private"),
+		Body:        body,
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to upload file to S3: %s", err)
+	}
+	return nil
+}
+
+func (store *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	out, err := store.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(store.bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == s3.ErrCodeNoSuchKey {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("unable to get file from S3: %s", err)
+	}
+	return out.Body, nil
+}
+
+func (store *S3Store) Delete(ctx context.Context, key string) error {
+	_, err := store.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(store.bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to delete file from S3: %s", err)
+	}
+	return nil
+}
+
+func (store *S3Store) PresignGet(ctx context.Context, key string) (string, error) {
+	req, _ := store.client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(store.bucketName),
+		Key:    aws.String(key),
+	})
+	req.SetContext(ctx)
+	urlStr, err := req.Presign(store.urlExpiry)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign request: %s", err)
+	}
+	return urlStr, nil
+}
+
+func (store *S3Store) PresignPut(ctx context.Context, key, contentType string, size int64) (string, error) {
+	// S3 does not sign the Content-Length of a presigned PUT, the size is checked by Verify
+	req, _ := store.client.PutObjectRequest(&s3.PutObjectInput{
+		Bucket:      aws.String(store.bucketName),
+		Key:         aws.String(key),
+		ACL:         aws.String("private"),
+		ContentType: aws.String(contentType),
+	})
+	req.SetContext(ctx)
+	urlStr, err := req.Presign(store.urlExpiry)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign request: %s", err)
+	}
+	return urlStr, nil
+}
+
+func (store *S3Store) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	out, err := store.client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(store.bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && (awsErr.Code() == s3.ErrCodeNoSuchKey || awsErr.Code() == "NotFound") {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("unable to stat file on S3: %s", err)
+	}
+	return &ObjectInfo{Size: aws.Int64Value(out.ContentLength)}, nil
+}
@@ -0,0 +1,61 @@
+package sso
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/akmal4410/gestapo/pkg/helpers/logger"
+)
+
+// Verifier validates the ID token issued to a client and returns the email and name of its owner.
+type Verifier interface {
+	Verify(token, clientID string) (string, string, error)
+}
+
+// GoogleVerifier validates Google ID tokens.
+type GoogleVerifier struct {
+	log logger.Logger
+}
+
+func NewGoogleVerifier(log logger.Logger) Verifier {
+	return &GoogleVerifier{log: log}
+}
+
+func (verifier *GoogleVerifier) Verify(token, clientID string) (string, string, error) {
+	return GoogleOauth(token, clientID, verifier.log)
+}
+
+type identity struct {
+	email string
+	name  string
+}
+
+// MemoryVerifier accepts the tokens registered with AddToken, for any client.
+type MemoryVerifier struct {
+	mu     sync.Mutex
+	tokens map[string]identity
+}
+
+func NewMemoryVerifier() *MemoryVerifier {
+	return &MemoryVerifier{tokens: map[string]identity{}}
+}
+
+// AddToken registers a token belonging to the user with email and name.
+func (verifier *MemoryVerifier) AddToken(token, email, name string) {
+	verifier.mu.Lock()
+	defer verifier.mu.Unlock()
+	verifier.tokens[token] = identity{email: email, name: name}
+}
+
+func (verifier *MemoryVerifier) Verify(token, clientID string) (string, string, error) {
+	verifier.mu.Lock()
+	defer verifier.mu.Unlock()
+	user, ok := verifier.tokens[token]
+	if !ok {
+		return "", "", fmt.Errorf("idtoken: invalid token")
+	}
+	if user.email == "" || user.name == "" {
+		return "", "", fmt.Errorf(missingClaims)
+	}
+	return user.email, user.name, nil
+}
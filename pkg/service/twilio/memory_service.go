@@ -0,0 +1,44 @@
+package twilio
+
+import (
+	"math/rand"
+	"strconv"
+	"sync"
+)
+
+// MemoryOTPService is a TwilioService that keeps the verification codes in memory,
+// used when Twilio is not reachable.
+type MemoryOTPService struct {
+	mu    sync.Mutex
+	codes map[string]string
+}
+
+func NewMemoryOTPService() *MemoryOTPService {
+	return &MemoryOTPService{codes: map[string]string{}}
+}
+
+func (service *MemoryOTPService) SendOTP(to string) error {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	service.codes[to] = strconv.Itoa(rand.Intn(900000) + 100000)
+	return nil
+}
+
+func (service *MemoryOTPService) VerfiyOTP(to, code string) (bool, error) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	if sent, ok := service.codes[to]; ok && sent == code {
+		// like Twilio, an approved verification cannot be checked again
+		delete(service.codes, to)
+		return true, nil
+	}
+	return false, nil
+}
+
+// LastOTP returns the pending verification code sent to the phone number.
+func (service *MemoryOTPService) LastOTP(to string) (string, bool) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	code, ok := service.codes[to]
+	return code, ok
+}
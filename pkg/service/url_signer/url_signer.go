@@ -0,0 +1,150 @@
+// Package urlsigner signs the download URLs of the files and caches them, so a
+// list of products does not sign every image again on every request.
+package urlsigner
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akmal4410/gestapo/internal/config"
+	"github.com/akmal4410/gestapo/pkg/helpers/logger"
+	"github.com/akmal4410/gestapo/pkg/service/cache"
+	objectstore "github.com/akmal4410/gestapo/pkg/service/object_store"
+)
+
+const cachePrefix = "signed-url:"
+
+// Signer returns the URLs of the files, from the object store or from the CDN.
+type Signer struct {
+	files  objectstore.ObjectStore
+	cache  cache.Cache
+	log    logger.Logger
+	expiry time.Duration
+	cdnURL string
+	cdnKey []byte
+}
+
+// New creates a Signer caching the URLs in redis when REDIS is configured.
+func New(appConfig *config.Config, files objectstore.ObjectStore, log logger.Logger) (*Signer, error) {
+	var urlCache cache.Cache
+	if appConfig.Redis != nil {
+		redisCache, err := cache.NewRedisCache(appConfig.Redis)
+		if err != nil {
+			return nil, err
+		}
+		urlCache = redisCache
+	}
+	return NewWith(appConfig, files, urlCache, log), nil
+}
+
+// NewWith creates a Signer caching the URLs in urlCache, nothing is cached when it is nil.
+func NewWith(appConfig *config.Config, files objectstore.ObjectStore, urlCache cache.Cache, log logger.Logger) *Signer {
+	signer := &Signer{
+		files:  files,
+		cache:  urlCache,
+		log:    log,
+		expiry: objectstore.DefaultURLExpiry,
+	}
+	if appConfig.Storage != nil {
+		if appConfig.Storage.URLExpiry != 0 {
+			signer.expiry = appConfig.Storage.URLExpiry
+		}
+		signer.cdnURL = strings.TrimSuffix(appConfig.Storage.CDNURL, "/")
+		signer.cdnKey = []byte(appConfig.Storage.CDNSigningKey)
+	}
+	return signer
+}
+
+// cacheExpiry keeps a URL for 80% of its life, so a cached URL is still valid
+// for a few minutes when the client downloads the file.
+func (signer *Signer) cacheExpiry() time.Duration {
+	return signer.expiry * 4 / 5
+}
+
+// URL returns the URL of key, or "" when it cannot be signed.
+func (signer *Signer) URL(ctx context.Context, key string) string {
+	return signer.URLs(ctx, []string{key})[key]
+}
+
+// URLs returns the URLs of keys, reading the cached ones in one round trip.
+// A key that cannot be signed is logged and left out, so one broken image
+// does not fail the whole response.
+func (signer *Signer) URLs(ctx context.Context, keys []string) map[string]string {
+	urls := make(map[string]string, len(keys))
+	var missing []string
+	seen := map[string]bool{}
+	for _, key := range keys {
+		if key != "" && !seen[key] {
+			seen[key] = true
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return urls
+	}
+
+	if signer.cache != nil {
+		cacheKeys := make([]string, len(missing))
+		for i, key := range missing {
+			cacheKeys[i] = cachePrefix + key
+		}
+		cached, err := signer.cache.GetMany(ctx, cacheKeys)
+		if err != nil {
+			// the URLs are signed again when redis is down
+			signer.log.With(ctx).LogWarn("Error while reading the cached URLs", err)
+		}
+		uncached := missing[:0]
+		for _, key := range missing {
+			if signedURL, ok := cached[cachePrefix+key]; ok {
+				urls[key] = signedURL
+			} else {
+				uncached = append(uncached, key)
+			}
+		}
+		missing = uncached
+	}
+
+	signed := make(map[string]string, len(missing))
+	for _, key := range missing {
+		signedURL, err := signer.sign(ctx, key)
+		if err != nil {
+			signer.log.With(ctx).LogError("Error while signing the URL of", key, err)
+			continue
+		}
+		urls[key] = signedURL
+		signed[cachePrefix+key] = signedURL
+	}
+	if signer.cache != nil && len(signed) != 0 {
+		if err := signer.cache.SetMany(ctx, signed, signer.cacheExpiry()); err != nil {
+			signer.log.With(ctx).LogWarn("Error while caching the URLs", err)
+		}
+	}
+	return urls
+}
+
+func (signer *Signer) sign(ctx context.Context, key string) (string, error) {
+	if signer.cdnURL == "" {
+		return signer.files.PresignGet(ctx, key)
+	}
+	escaped := (&url.URL{Path: "/" + key}).EscapedPath()
+	if len(signer.cdnKey) == 0 {
+		return signer.cdnURL + escaped, nil
+	}
+	expires := strconv.FormatInt(time.Now().Add(signer.expiry).Unix(), 10)
+	return signer.cdnURL + escaped + "?expires=" + expires + "&signature=" + CDNSignature(signer.cdnKey, escaped, expires), nil
+}
+
+// CDNSignature is the signature of a CDN URL: the base64url HMAC-SHA256 of its
+// escaped path and its expiry in unix seconds, joined by a new line. The CDN
+// checks it with the same key before serving the file.
+func CDNSignature(key []byte, escapedPath, expires string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(escapedPath + "\n" + expires))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,117 @@
+package urlsigner_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akmal4410/gestapo/internal/config"
+	"github.com/akmal4410/gestapo/pkg/helpers/logger"
+	"github.com/akmal4410/gestapo/pkg/service/cache"
+	objectstore "github.com/akmal4410/gestapo/pkg/service/object_store"
+	urlsigner "github.com/akmal4410/gestapo/pkg/service/url_signer"
+)
+
+// countingStore counts the signed URLs and fails to sign the broken keys.
+type countingStore struct {
+	*objectstore.MemoryStore
+	signed int
+	broken string
+}
+
+func (store *countingStore) PresignGet(ctx context.Context, key string) (string, error) {
+	if key == store.broken {
+		return "", errors.New("unable to sign")
+	}
+	store.signed++
+	return store.MemoryStore.PresignGet(ctx, key)
+}
+
+// brokenCache fails like redis when it is down.
+type brokenCache struct {
+	cache.Cache
+}
+
+func (brokenCache) GetMany(ctx context.Context, keys []string) (map[string]string, error) {
+	return nil, errors.New("connection refused")
+}
+
+func (brokenCache) SetMany(ctx context.Context, values map[string]string, expiry time.Duration) error {
+	return errors.New("connection refused")
+}
+
+var log = logger.NewWriterLogger("url_signer", io.Discard)
+
+func TestURLsAreCached(t *testing.T) {
+	ctx := context.Background()
+	store := &countingStore{MemoryStore: objectstore.NewMemoryStore(), broken: "broken.jpg"}
+	signer := urlsigner.NewWith(&config.Config{}, store, cache.NewMemoryCache(), log)
+
+	urls := signer.URLs(ctx, []string{"a.jpg", "b.jpg", "a.jpg", "broken.jpg", ""})
+	if len(urls) != 2 || urls["a.jpg"] != "memory://files/a.jpg" || urls["b.jpg"] != "memory://files/b.jpg" {
+		t.Fatalf("URLs: %v", urls)
+	}
+	if store.signed != 2 {
+		t.Fatalf("expected 2 signatures, got %d", store.signed)
+	}
+
+	if got := signer.URL(ctx, "a.jpg"); got != "memory://files/a.jpg" {
+		t.Fatalf("URL: %s", got)
+	}
+	signer.URLs(ctx, []string{"a.jpg", "b.jpg", "c.jpg"})
+	if store.signed != 3 {
+		t.Fatalf("expected only c.jpg to be signed again, got %d signatures", store.signed)
+	}
+	if got := signer.URL(ctx, "broken.jpg"); got != "" {
+		t.Fatalf("URL of a broken key: %s", got)
+	}
+}
+
+func TestURLsWithoutCache(t *testing.T) {
+	ctx := context.Background()
+	for _, urlCache := range []cache.Cache{nil, brokenCache{}} {
+		store := &countingStore{MemoryStore: objectstore.NewMemoryStore()}
+		signer := urlsigner.NewWith(&config.Config{}, store, urlCache, log)
+		for i := 0; i < 2; i++ {
+			if got := signer.URL(ctx, "a.jpg"); got != "memory://files/a.jpg" {
+				t.Fatalf("URL: %s", got)
+			}
+		}
+		if store.signed != 2 {
+			t.Fatalf("expected every URL to be signed, got %d signatures", store.signed)
+		}
+	}
+}
+
+func TestCDNURLs(t *testing.T) {
+	ctx := context.Background()
+	appConfig := &config.Config{Storage: &config.Storage{CDNURL: "https://cdn.example.com/", CDNSigningKey: "secret"}}
+	signer := urlsigner.NewWith(appConfig, objectstore.NewMemoryStore(), nil, log)
+
+	signedURL, err := url.Parse(signer.URL(ctx, "products/m/p/id/thumbnail.webp"))
+	if err != nil {
+		t.Fatalf("url.Parse: %v", err)
+	}
+	if signedURL.Host != "cdn.example.com" || signedURL.Path != "/products/m/p/id/thumbnail.webp" {
+		t.Fatalf("unexpected URL %s", signedURL)
+	}
+	expires := signedURL.Query().Get("expires")
+	unix, _ := strconv.ParseInt(expires, 10, 64)
+	if remaining := time.Until(time.Unix(unix, 0)); remaining < 14*time.Minute || remaining > objectstore.DefaultURLExpiry {
+		t.Fatalf("unexpected expiry %s", remaining)
+	}
+	if signedURL.Query().Get("signature") != urlsigner.CDNSignature([]byte("secret"), signedURL.EscapedPath(), expires) {
+		t.Fatalf("invalid signature in %s", signedURL)
+	}
+
+	appConfig.Storage.CDNSigningKey = ""
+	publicURL := urlsigner.NewWith(appConfig, objectstore.NewMemoryStore(), nil, log).URL(ctx, "profile/u/my image.jpg")
+	if publicURL != "https://cdn.example.com/profile/u/my%20image.jpg" || strings.Contains(publicURL, "signature") {
+		t.Fatalf("unexpected public URL %s", publicURL)
+	}
+}
@@ -11,6 +11,8 @@ const (
 	AuthorizationKey        string     = "Authorization"
 	AuthorizationTypeBearer string     = "bearer"
 	AuthorizationPayloadKey contextKey = "authorization_payload"
+	// DeviceIDKey identifies the device of a guest browsing without signing in
+	DeviceIDKey string = "X-Device-Id"
 
 	InternalServerError string = "Internal server error"
 	InvalidRequest      string = "Invalid Request"
@@ -25,6 +27,33 @@ const (
 	OrderCompleted string = "Completed"
 	OrderCancelled string = "Cancelled"
 
+	StockSale       string = "SALE"
+	StockRestock    string = "RESTOCK"
+	StockReturn     string = "RETURN"
+	StockAdjustment string = "ADJUSTMENT"
+
+	AttributeText    string = "TEXT"
+	AttributeNumber  string = "NUMBER"
+	AttributeBoolean string = "BOOLEAN"
+	AttributeEnum    string = "ENUM"
+
+	// only the published reviews are listed and rate the product, the questions
+	// and the answers of the products are moderated with the same statuses
+	ReviewPublished string = "PUBLISHED"
+	ReviewPending   string = "PENDING"
+	ReviewHidden    string = "HIDDEN"
+
+	// only the published products are listed, within their schedule
+	ProductDraft         string = "DRAFT"
+	ProductPendingReview string = "PENDING_REVIEW"
+	ProductPublished     string = "PUBLISHED"
+	ProductArchived      string = "ARCHIVED"
+
+	NotificationPriceDrop   string = "PRICE_DROP"
+	NotificationBackInStock string = "BACK_IN_STOCK"
+	NotificationNewQuestion string = "NEW_QUESTION"
+	NotificationLowStock    string = "LOW_STOCK"
+
 	TrackingStatus0 int = 0
 )
 